@@ -0,0 +1,329 @@
+// Package allure defines the Allure 2 test result format and includes
+// convenience methods to create these results from a gtr.Report.
+//
+// Allure results are a directory containing a result file for every test and
+// a container file for every package. Top-level tests are written as results,
+// and their subtests as nested steps of the result, so the subtest hierarchy
+// is preserved. Build and runtime errors are written as broken results.
+package allure
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/timefmt"
+)
+
+// Allure statuses.
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusBroken  = "broken"
+	StatusSkipped = "skipped"
+	StatusUnknown = "unknown"
+)
+
+// Result is the result of a single top-level test, written to a file named
+// {uuid}-result.json.
+type Result struct {
+	UUID          string         `json:"uuid"`
+	HistoryID     string         `json:"historyId"`
+	FullName      string         `json:"fullName"`
+	Name          string         `json:"name"`
+	Status        string         `json:"status"`
+	StatusDetails *StatusDetails `json:"statusDetails,omitempty"`
+	Stage         string         `json:"stage"`
+	Start         int64          `json:"start,omitempty"` // milliseconds since the Unix epoch
+	Stop          int64          `json:"stop,omitempty"`  // milliseconds since the Unix epoch
+	Labels        []Label        `json:"labels,omitempty"`
+	Steps         []Step         `json:"steps,omitempty"`
+	Attachments   []Attachment   `json:"attachments,omitempty"`
+}
+
+// StatusDetails contains the message and output of a test or step.
+type StatusDetails struct {
+	Message string `json:"message,omitempty"`
+	Trace   string `json:"trace,omitempty"`
+	Flaky   bool   `json:"flaky,omitempty"`
+}
+
+// Label is a name/value label of a Result.
+type Label struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Step is a subtest of a Result or of another step.
+type Step struct {
+	Name          string         `json:"name"`
+	Status        string         `json:"status"`
+	StatusDetails *StatusDetails `json:"statusDetails,omitempty"`
+	Stage         string         `json:"stage"`
+	Start         int64          `json:"start,omitempty"`
+	Stop          int64          `json:"stop,omitempty"`
+	Steps         []Step         `json:"steps,omitempty"`
+	Attachments   []Attachment   `json:"attachments,omitempty"`
+}
+
+// Attachment refers to a file in the results directory.
+type Attachment struct {
+	Name   string `json:"name"`
+	Source string `json:"source"` // file name relative to the results directory
+	Type   string `json:"type,omitempty"`
+}
+
+// Container groups the results of a package, written to a file named
+// {uuid}-container.json.
+type Container struct {
+	UUID     string   `json:"uuid"`
+	Name     string   `json:"name"`
+	Children []string `json:"children"`
+	Start    int64    `json:"start,omitempty"`
+	Stop     int64    `json:"stop,omitempty"`
+}
+
+// Results contains all results and containers of a report. Files maps the
+// Source of each attachment to the path of the original file, which needs to
+// be copied to the results directory.
+type Results struct {
+	Results    []Result
+	Containers []Container
+	Files      map[string]string
+}
+
+// CreateFromReport creates Allure results from the given report. The uuids of
+// results and containers are derived from package and test names, so they're
+// the same for every run of the same tests.
+func CreateFromReport(report gtr.Report) Results {
+	res := Results{Files: make(map[string]string)}
+	for _, pkg := range report.Packages {
+		container := Container{UUID: uuid(pkg.Name), Name: pkg.Name, Children: []string{}}
+		start := pkg.StartTime
+		if start.IsZero() {
+			start = pkg.Timestamp
+		}
+		container.Start = timefmt.UnixMillis(start)
+		container.Stop = timefmt.UnixMillis(stopTime(start, pkg.EndTime, pkg.Duration))
+
+		add := func(r Result) {
+			r.Labels = []Label{
+				{Name: "package", Value: pkg.Name},
+				{Name: "suite", Value: pkg.Name},
+				{Name: "framework", Value: "go test"},
+				{Name: "language", Value: "go"},
+			}
+			for _, prop := range pkg.Properties {
+				if prop.Name == "go.version" {
+					r.Labels = append(r.Labels, Label{Name: "host.go.version", Value: prop.Value})
+				}
+			}
+			res.Results = append(res.Results, r)
+			container.Children = append(container.Children, r.UUID)
+		}
+
+		seen := make(map[string]int)
+		for _, node := range pkg.TestTree() {
+			fullName := pkg.Name + "." + node.Name
+			id := fullName
+			if n := seen[fullName]; n > 0 {
+				// Tests that ran more than once need a unique uuid, but
+				// share their history id.
+				id = fmt.Sprintf("%s#%d", fullName, n)
+			}
+			seen[fullName]++
+
+			step := res.createStep(node, pkg.Timestamp)
+			add(Result{
+				UUID:          uuid(id),
+				HistoryID:     historyID(fullName),
+				FullName:      fullName,
+				Name:          node.Name,
+				Status:        step.Status,
+				StatusDetails: step.StatusDetails,
+				Stage:         step.Stage,
+				Start:         step.Start,
+				Stop:          step.Stop,
+				Steps:         step.Steps,
+				Attachments:   step.Attachments,
+			})
+		}
+
+		if pkg.BuildError.Name != "" {
+			add(errorResult(pkg.Name, "Build error", pkg.BuildError))
+		}
+		if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			add(errorResult(pkg.Name, "Runtime error", pkg.RunError))
+		}
+		res.Containers = append(res.Containers, container)
+	}
+	return res
+}
+
+// createStep returns the step for the given test and its subtests.
+func (res *Results) createStep(node *gtr.TestNode, timestamp time.Time) Step {
+	start := node.StartTime
+	if start.IsZero() {
+		start = timestamp
+	}
+	step := Step{
+		Name:   node.Name,
+		Status: status(node.Test),
+		Stage:  "finished",
+		Start:  timefmt.UnixMillis(start),
+		Stop:   timefmt.UnixMillis(stopTime(start, node.EndTime, node.Duration)),
+	}
+
+	details := &StatusDetails{Trace: strings.Join(node.Output, "\n"), Flaky: node.Result == gtr.Flaky}
+	switch {
+	case node.Panic != nil, node.Result.Base() == gtr.Fail:
+		details.Message = node.FailureReason()
+	case node.Result.Base() == gtr.Skip:
+		details.Message = node.SkipMessage
+	case node.Result == gtr.Unknown:
+		details.Message = "No test result found"
+	}
+	if *details != (StatusDetails{}) {
+		step.StatusDetails = details
+	}
+
+	for _, a := range node.Attachments {
+		step.Attachments = append(step.Attachments, res.addAttachment(a))
+	}
+	for _, child := range node.Children {
+		step.Steps = append(step.Steps, res.createStep(child, timestamp))
+	}
+	return step
+}
+
+// addAttachment returns the Allure attachment for a and records that its file
+// needs to be copied to the results directory.
+func (res *Results) addAttachment(a gtr.Attachment) Attachment {
+	source := uuid(a.Path) + "-attachment" + filepath.Ext(a.Path)
+	res.Files[source] = a.Path
+	name := a.Name
+	if name == "" {
+		name = filepath.Base(a.Path)
+	}
+	return Attachment{Name: name, Source: source, Type: a.MIME}
+}
+
+func errorResult(pkgName, name string, e gtr.Error) Result {
+	fullName := pkgName + "." + name
+	return Result{
+		UUID:          uuid(fullName),
+		HistoryID:     historyID(fullName),
+		FullName:      fullName,
+		Name:          name,
+		Status:        StatusBroken,
+		StatusDetails: &StatusDetails{Message: e.FailureReason(), Trace: strings.Join(e.Output, "\n")},
+		Stage:         "finished",
+	}
+}
+
+// status returns the Allure status of test t. Tests that failed because of a
+// panic are broken rather than failed, like tests that throw an unexpected
+// exception in other languages.
+func status(t gtr.Test) string {
+	switch t.Result.Base() {
+	case gtr.Pass, gtr.Flaky:
+		return StatusPassed
+	case gtr.Fail:
+		if t.Panic != nil {
+			return StatusBroken
+		}
+		return StatusFailed
+	case gtr.Skip:
+		return StatusSkipped
+	default:
+		return StatusUnknown
+	}
+}
+
+func stopTime(start, end time.Time, d time.Duration) time.Time {
+	if !end.IsZero() {
+		return end
+	}
+	if start.IsZero() {
+		return start
+	}
+	return start.Add(d)
+}
+
+// uuid returns a version 4 style uuid derived from the given name.
+func uuid(name string) string {
+	h := sha256.Sum256([]byte(name))
+	h[6] = h[6]&0x0f | 0x40
+	h[8] = h[8]&0x3f | 0x80
+	s := hex.EncodeToString(h[:16])
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+func historyID(fullName string) string {
+	h := sha256.Sum256([]byte(fullName))
+	return hex.EncodeToString(h[:16])
+}
+
+// WriteDir writes results r to directory dir, which is created if it doesn't
+// exist yet. Attachment files are copied to dir, attachments whose file
+// doesn't exist are skipped.
+func (r Results) WriteDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, result := range r.Results {
+		if err := writeJSON(filepath.Join(dir, result.UUID+"-result.json"), result); err != nil {
+			return err
+		}
+	}
+	for _, container := range r.Containers {
+		if err := writeJSON(filepath.Join(dir, container.UUID+"-container.json"), container); err != nil {
+			return err
+		}
+	}
+	for source, file := range r.Files {
+		if err := copyFile(filepath.Join(dir, source), file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(name string, v interface{}) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(v); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,210 @@
+package allure
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCreateFromReport(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{
+				Name:     "pkg",
+				Duration: 3 * time.Second,
+				Tests: []gtr.Test{
+					{
+						Name:        "TestParent",
+						Result:      gtr.Fail,
+						StartTime:   start,
+						Duration:    2 * time.Second,
+						Attachments: []gtr.Attachment{{Path: "out/screen.png", MIME: "image/png"}},
+					},
+					{Name: "TestParent/pass", Result: gtr.Pass, StartTime: start, Duration: time.Second},
+					{Name: "TestParent/fail", Result: gtr.Fail, FailureMessage: "boom", Output: []string{"x_test.go:1: boom"}},
+					{Name: "TestSkip", Result: gtr.Skip, SkipMessage: "later"},
+					{Name: "TestPanic", Result: gtr.Fail, Panic: &gtr.PanicInfo{Message: "oops"}},
+					{Name: "TestFlaky", Result: gtr.Flaky},
+				},
+			},
+			{
+				Name:       "broken",
+				BuildError: gtr.Error{Name: "broken", Cause: "[build failed]", Output: []string{"undefined: x"}},
+			},
+		},
+	}
+
+	labels := func(pkg string) []Label {
+		return []Label{
+			{Name: "package", Value: pkg},
+			{Name: "suite", Value: pkg},
+			{Name: "framework", Value: "go test"},
+			{Name: "language", Value: "go"},
+		}
+	}
+	ms := start.UnixNano() / int64(time.Millisecond)
+	attachmentSource := uuid("out/screen.png") + "-attachment.png"
+
+	want := Results{
+		Results: []Result{
+			{
+				UUID:      uuid("pkg.TestParent"),
+				HistoryID: historyID("pkg.TestParent"),
+				FullName:  "pkg.TestParent",
+				Name:      "TestParent",
+				Status:    StatusFailed,
+				Stage:     "finished",
+				Start:     ms,
+				Stop:      ms + 2000,
+				Labels:    labels("pkg"),
+				Steps: []Step{
+					{Name: "TestParent/pass", Status: StatusPassed, Stage: "finished", Start: ms, Stop: ms + 1000},
+					{
+						Name:          "TestParent/fail",
+						Status:        StatusFailed,
+						StatusDetails: &StatusDetails{Message: "boom", Trace: "x_test.go:1: boom"},
+						Stage:         "finished",
+					},
+				},
+				Attachments: []Attachment{{Name: "screen.png", Source: attachmentSource, Type: "image/png"}},
+			},
+			{
+				UUID:          uuid("pkg.TestSkip"),
+				HistoryID:     historyID("pkg.TestSkip"),
+				FullName:      "pkg.TestSkip",
+				Name:          "TestSkip",
+				Status:        StatusSkipped,
+				StatusDetails: &StatusDetails{Message: "later"},
+				Stage:         "finished",
+				Labels:        labels("pkg"),
+			},
+			{
+				UUID:          uuid("pkg.TestPanic"),
+				HistoryID:     historyID("pkg.TestPanic"),
+				FullName:      "pkg.TestPanic",
+				Name:          "TestPanic",
+				Status:        StatusBroken,
+				StatusDetails: &StatusDetails{Message: "Panic: oops"},
+				Stage:         "finished",
+				Labels:        labels("pkg"),
+			},
+			{
+				UUID:          uuid("pkg.TestFlaky"),
+				HistoryID:     historyID("pkg.TestFlaky"),
+				FullName:      "pkg.TestFlaky",
+				Name:          "TestFlaky",
+				Status:        StatusPassed,
+				StatusDetails: &StatusDetails{Flaky: true},
+				Stage:         "finished",
+				Labels:        labels("pkg"),
+			},
+			{
+				UUID:          uuid("broken.Build error"),
+				HistoryID:     historyID("broken.Build error"),
+				FullName:      "broken.Build error",
+				Name:          "Build error",
+				Status:        StatusBroken,
+				StatusDetails: &StatusDetails{Message: "[build failed]", Trace: "undefined: x"},
+				Stage:         "finished",
+				Labels:        labels("broken"),
+			},
+		},
+		Containers: []Container{
+			{
+				UUID:     uuid("pkg"),
+				Name:     "pkg",
+				Children: []string{uuid("pkg.TestParent"), uuid("pkg.TestSkip"), uuid("pkg.TestPanic"), uuid("pkg.TestFlaky")},
+			},
+			{UUID: uuid("broken"), Name: "broken", Children: []string{uuid("broken.Build error")}},
+		},
+		Files: map[string]string{attachmentSource: "out/screen.png"},
+	}
+
+	got := CreateFromReport(report)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CreateFromReport incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestCreateFromReportRepeatedTests(t *testing.T) {
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{Name: "pkg", Tests: []gtr.Test{{Name: "TestOne", Result: gtr.Fail}, {Name: "TestOne", Result: gtr.Pass}}},
+		},
+	}
+	got := CreateFromReport(report)
+	if len(got.Results) != 2 {
+		t.Fatalf("CreateFromReport returned %d results, want 2", len(got.Results))
+	}
+	first, second := got.Results[0], got.Results[1]
+	if first.UUID == second.UUID {
+		t.Errorf("CreateFromReport results of repeated test have the same uuid %q", first.UUID)
+	}
+	if first.HistoryID != second.HistoryID {
+		t.Errorf("CreateFromReport results of repeated test have different history ids %q and %q", first.HistoryID, second.HistoryID)
+	}
+}
+
+func TestWriteDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "allure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	attachment := filepath.Join(dir, "screen.png")
+	if err := ioutil.WriteFile(attachment, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{
+				Name: "pkg",
+				Tests: []gtr.Test{
+					{Name: "TestOne", Result: gtr.Pass, Attachments: []gtr.Attachment{{Path: attachment}, {Path: filepath.Join(dir, "missing.txt")}}},
+				},
+			},
+		},
+	}
+	results := CreateFromReport(report)
+	out := filepath.Join(dir, "results")
+	if err := results.WriteDir(out); err != nil {
+		t.Fatalf("WriteDir error: %v", err)
+	}
+
+	var result Result
+	readJSON(t, filepath.Join(out, uuid("pkg.TestOne")+"-result.json"), &result)
+	if diff := cmp.Diff(results.Results[0], result); diff != "" {
+		t.Errorf("result file incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	var container Container
+	readJSON(t, filepath.Join(out, uuid("pkg")+"-container.json"), &container)
+	if diff := cmp.Diff(results.Containers[0], container); diff != "" {
+		t.Errorf("container file incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(out, result.Attachments[0].Source))
+	if err != nil || string(data) != "png" {
+		t.Errorf("attachment file not copied, got %q, err=%v", data, err)
+	}
+}
+
+func readJSON(t *testing.T, file string, v interface{}) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("error reading %s: %v", file, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("error unmarshaling %s: %v", file, err)
+	}
+}
@@ -0,0 +1,144 @@
+// Package azuredevops creates the test results of the Azure DevOps Test
+// Results API from a gtr.Report. The results can be added to a test run by
+// posting them to the test results endpoint of the run.
+//
+// Every test, including subtests, is written as an automated test whose
+// storage is the name of its package. Build and runtime errors are written as
+// results with the Error outcome. See
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/test/results/add
+// for a description of the format.
+package azuredevops
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// Azure DevOps test outcomes.
+const (
+	OutcomePassed       = "Passed"
+	OutcomeFailed       = "Failed"
+	OutcomeNotExecuted  = "NotExecuted"
+	OutcomeError        = "Error"
+	OutcomeInconclusive = "Inconclusive"
+)
+
+// StateCompleted is the state of a test result that finished running.
+const StateCompleted = "Completed"
+
+// Result is the result of a single test.
+type Result struct {
+	TestCaseTitle        string  `json:"testCaseTitle"`
+	AutomatedTestName    string  `json:"automatedTestName"`
+	AutomatedTestStorage string  `json:"automatedTestStorage"`
+	AutomatedTestType    string  `json:"automatedTestType"`
+	Outcome              string  `json:"outcome"`
+	State                string  `json:"state"`
+	DurationInMs         float64 `json:"durationInMs"`
+	StartedDate          string  `json:"startedDate,omitempty"`   // RFC 3339
+	CompletedDate        string  `json:"completedDate,omitempty"` // RFC 3339
+	ErrorMessage         string  `json:"errorMessage,omitempty"`
+	StackTrace           string  `json:"stackTrace,omitempty"`
+	Comment              string  `json:"comment,omitempty"`
+	ComputerName         string  `json:"computerName,omitempty"`
+}
+
+// CreateFromReport creates the Azure DevOps test results of the given report.
+func CreateFromReport(report gtr.Report) []Result {
+	results := []Result{}
+	for _, pkg := range report.Packages {
+		for _, t := range pkg.Tests {
+			results = append(results, createResult(pkg, t))
+		}
+		if pkg.BuildError.Name != "" {
+			results = append(results, errorResult(pkg, "Build error", pkg.BuildError))
+		}
+		if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			results = append(results, errorResult(pkg, "Runtime error", pkg.RunError))
+		}
+	}
+	return results
+}
+
+// Write writes the indented JSON encoding of the test results of report r to
+// w.
+func Write(w io.Writer, r gtr.Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(CreateFromReport(r))
+}
+
+func newResult(pkg gtr.Package, name string) Result {
+	return Result{
+		TestCaseTitle:        name,
+		AutomatedTestName:    pkg.Name + "." + name,
+		AutomatedTestStorage: pkg.Name,
+		AutomatedTestType:    "UnitTest",
+		State:                StateCompleted,
+		ComputerName:         pkg.Hostname,
+	}
+}
+
+func createResult(pkg gtr.Package, t gtr.Test) Result {
+	r := newResult(pkg, t.Name)
+	r.DurationInMs = millis(t.Duration)
+	r.StartedDate, r.CompletedDate = date(t.StartTime), date(t.EndTime)
+
+	switch t.Result.Base() {
+	case gtr.Pass:
+		r.Outcome = OutcomePassed
+	case gtr.Flaky:
+		r.Outcome = OutcomePassed
+		r.Comment = "flaky"
+	case gtr.Fail:
+		r.Outcome = OutcomeFailed
+		r.ErrorMessage = t.FailureReason()
+		r.StackTrace = strings.Join(t.Output, "\n")
+		if t.Panic != nil {
+			r.StackTrace = strings.Join(t.Panic.Stack, "\n")
+		}
+		if r.ErrorMessage == "" {
+			r.ErrorMessage = "Failed"
+		}
+	case gtr.Skip:
+		r.Outcome = OutcomeNotExecuted
+		r.Comment = t.SkipMessage
+	default:
+		r.Outcome = OutcomeInconclusive
+		r.ErrorMessage = "No test result found"
+		r.StackTrace = strings.Join(t.Output, "\n")
+	}
+	return r
+}
+
+func errorResult(pkg gtr.Package, name string, e gtr.Error) Result {
+	r := newResult(pkg, name)
+	r.Outcome = OutcomeError
+	r.DurationInMs = millis(e.Duration)
+	r.ErrorMessage = e.FailureReason()
+	r.StackTrace = strings.Join(e.Output, "\n")
+	if e.Kind != gtr.ErrorKindInfra && e.Kind != gtr.ErrorKindSanitizer && e.Panic != nil {
+		r.StackTrace = strings.Join(e.Panic.Stack, "\n")
+	}
+	if r.ErrorMessage == "" {
+		r.ErrorMessage = strings.ToLower(name)
+	}
+	return r
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// date returns t formatted as an RFC 3339 date in UTC, or an empty string if
+// t is zero.
+func date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
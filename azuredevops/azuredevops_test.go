@@ -0,0 +1,69 @@
+package azuredevops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCreateFromReport(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := gtr.Report{Packages: []gtr.Package{
+		{
+			Name:     "package/one",
+			Hostname: "host",
+			Tests: []gtr.Test{
+				{Name: "TestOne", Result: gtr.Pass, Duration: 1500 * time.Microsecond, StartTime: start, EndTime: start.Add(1500 * time.Microsecond)},
+				{Name: "TestFail", Result: gtr.Fail, FailureMessage: "boom", Output: []string{"one_test.go:12: boom", "more"}},
+				{Name: "TestPanic", Result: gtr.Fail, Panic: &gtr.PanicInfo{Message: "nil map", Stack: []string{"main.go:1", "main.go:2"}}},
+				{Name: "TestSkip", Result: gtr.Skip, SkipMessage: "not today"},
+				{Name: "TestFlaky", Result: gtr.Flaky},
+				{Name: "TestUnknown", Result: gtr.Unknown},
+			},
+		},
+		{
+			Name:     "package/two",
+			RunError: gtr.Error{Name: "package/two", Duration: time.Second, Cause: "exit status 2", Output: []string{"panic"}},
+		},
+	}}
+
+	result := func(pkg, name, outcome string) Result {
+		r := Result{
+			TestCaseTitle:        name,
+			AutomatedTestName:    pkg + "." + name,
+			AutomatedTestStorage: pkg,
+			AutomatedTestType:    "UnitTest",
+			Outcome:              outcome,
+			State:                StateCompleted,
+		}
+		if pkg == "package/one" {
+			r.ComputerName = "host"
+		}
+		return r
+	}
+	want := []Result{
+		result("package/one", "TestOne", OutcomePassed),
+		result("package/one", "TestFail", OutcomeFailed),
+		result("package/one", "TestPanic", OutcomeFailed),
+		result("package/one", "TestSkip", OutcomeNotExecuted),
+		result("package/one", "TestFlaky", OutcomePassed),
+		result("package/one", "TestUnknown", OutcomeInconclusive),
+		result("package/two", "Runtime error", OutcomeError),
+	}
+	want[0].DurationInMs = 1.5
+	want[0].StartedDate, want[0].CompletedDate = "2022-01-01T00:00:00Z", "2022-01-01T00:00:00.0015Z"
+	want[1].ErrorMessage, want[1].StackTrace = "boom", "one_test.go:12: boom\nmore"
+	want[2].ErrorMessage, want[2].StackTrace = "Panic: nil map", "main.go:1\nmain.go:2"
+	want[3].Comment = "not today"
+	want[4].Comment = "flaky"
+	want[5].ErrorMessage = "No test result found"
+	want[6].DurationInMs, want[6].ErrorMessage, want[6].StackTrace = 1000, "exit status 2", "panic"
+
+	got := CreateFromReport(report)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CreateFromReport incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
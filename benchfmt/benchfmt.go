@@ -0,0 +1,168 @@
+// Package benchfmt writes the benchmarks in a report in the Go benchmark
+// format, so they can be analyzed with tools such as benchstat.
+//
+// The output uses the format printed by go test -bench. Each package starts
+// with a pkg configuration line, preceded by goos and goarch lines when the
+// package has go.os and go.arch properties, for example as added by
+// -capture-env. Each passed benchmark is written on a single result line:
+//
+//	goos: linux
+//	goarch: amd64
+//	pkg: example.com/mod/pkg
+//	BenchmarkParse   	    1000	      1591 ns/op	     512 B/op	       3 allocs/op
+//
+// Benchmarks that ran more than once, for example using -count, have been
+// combined by the parser, so their average is written as a single result.
+// Benchmark names don't include the GOMAXPROCS suffix, since it's not kept by
+// the parser.
+//
+// With the WithConfig option, all package properties are also written as
+// configuration lines, as described by the benchmark data format proposal at
+// https://go.googlesource.com/proposal/+/master/design/14313-benchmark-format.md.
+package benchfmt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/parser/gotest"
+)
+
+// Option configures how benchmarks are written.
+type Option func(*writer)
+
+// WithConfig writes the properties of each package as configuration lines,
+// in addition to the goos and goarch lines. Property names are converted to valid configuration keys by making them
+// lower case and replacing spaces with dashes.
+func WithConfig(enabled bool) Option {
+	return func(w *writer) {
+		w.config = enabled
+	}
+}
+
+type writer struct {
+	config bool
+	values map[string]string // configuration values written so far
+}
+
+// Write writes the benchmarks in report r to w. Packages without benchmarks
+// are skipped.
+func Write(w io.Writer, r gtr.Report, options ...Option) error {
+	bw := bufio.NewWriter(w)
+	wr := &writer{values: make(map[string]string)}
+	for _, opt := range options {
+		opt(wr)
+	}
+	for _, pkg := range r.Packages {
+		wr.writePackage(bw, pkg)
+	}
+	return bw.Flush()
+}
+
+func (wr *writer) writePackage(w *bufio.Writer, pkg gtr.Package) {
+	type result struct {
+		name  string
+		bench gotest.Benchmark
+	}
+	var results []result
+	for _, test := range pkg.Tests {
+		if test.Result != gtr.Pass {
+			continue
+		}
+		if bench, ok := gotest.GetBenchmarkData(test); ok {
+			results = append(results, result{test.Name, bench})
+		}
+	}
+	if len(results) == 0 {
+		return
+	}
+
+	props := make(map[string]string)
+	for _, prop := range pkg.Properties {
+		props[prop.Name] = prop.Value
+	}
+	if v, ok := props["go.os"]; ok {
+		wr.writeConfig(w, "goos", v)
+	}
+	if v, ok := props["go.arch"]; ok {
+		wr.writeConfig(w, "goarch", v)
+	}
+	if wr.config {
+		for _, prop := range pkg.Properties {
+			if prop.Name == "go.os" || prop.Name == "go.arch" {
+				continue
+			}
+			if key := configKey(prop.Name); key != "" && key != "pkg" && key != "goos" && key != "goarch" {
+				wr.writeConfig(w, key, prop.Value)
+			}
+		}
+	}
+	wr.writeConfig(w, "pkg", pkg.Name)
+
+	for _, res := range results {
+		fmt.Fprintf(w, "%s\t%d", res.name, res.bench.Iterations)
+		for _, m := range metrics(res.bench) {
+			fmt.Fprintf(w, "\t%s %s", strconv.FormatFloat(m.value, 'f', -1, 64), m.unit)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// writeConfig writes a configuration line for key, unless it already has the
+// given value. Configuration lines apply to all results that follow, so only
+// changes need to be written.
+func (wr *writer) writeConfig(w *bufio.Writer, key, value string) {
+	value = strings.Join(strings.Fields(value), " ")
+	if prev, ok := wr.values[key]; ok && prev == value {
+		return
+	}
+	wr.values[key] = value
+	fmt.Fprintf(w, "%s: %s\n", key, value)
+}
+
+// configKey returns name as a valid configuration key, which starts with a
+// lower case letter and doesn't contain spaces or upper case letters. An
+// empty string is returned if name doesn't start with a letter.
+func configKey(name string) string {
+	key := strings.Join(strings.Fields(strings.ToLower(name)), "-")
+	if r, _ := utf8.DecodeRuneInString(key); !unicode.IsLower(r) {
+		return ""
+	}
+	return key
+}
+
+type metric struct {
+	value float64
+	unit  string
+}
+
+// metrics returns the metrics of benchmark b in the order they're printed by
+// go test: ns/op, MB/s, custom metrics sorted by unit, B/op and allocs/op.
+func metrics(b gotest.Benchmark) []metric {
+	var ms []metric
+	if b.NsPerOp != 0 {
+		ms = append(ms, metric{b.NsPerOp, "ns/op"})
+	}
+	if b.MBPerSec != 0 {
+		ms = append(ms, metric{b.MBPerSec, "MB/s"})
+	}
+	units := make([]string, 0, len(b.Metrics))
+	for unit := range b.Metrics {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+	for _, unit := range units {
+		ms = append(ms, metric{b.Metrics[unit], unit})
+	}
+	if b.BytesPerOp != 0 || b.AllocsPerOp != 0 {
+		ms = append(ms, metric{float64(b.BytesPerOp), "B/op"}, metric{float64(b.AllocsPerOp), "allocs/op"})
+	}
+	return ms
+}
@@ -0,0 +1,118 @@
+package benchfmt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/parser/gotest"
+)
+
+func TestWrite(t *testing.T) {
+	in := `goos: linux
+goarch: amd64
+pkg: package/one
+BenchmarkParse-8   	    1000	      1591 ns/op	      4969 requests/s	     512 B/op	       3 allocs/op
+BenchmarkSize-8    	  200000	      5.25 ns/op	  95.76 MB/s
+BenchmarkFail-8
+--- FAIL: BenchmarkFail-8
+    bench_test.go:10: failed
+FAIL
+exit status 1
+FAIL	package/one	1.234s
+--- PASS: TestOther (0.00s)
+ok  	package/two	0.100s
+pkg: package/three
+BenchmarkRatio-8   	    1000	         0.75 hit-ratio
+ok  	package/three	0.200s
+`
+	report, err := gotest.NewParser().Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	report.Packages[0].AddProperty("go.os", "linux")
+	report.Packages[0].AddProperty("go.arch", "amd64")
+	report.Packages[0].AddProperty("Build ID", "1234")
+	report.Packages[2].AddProperty("go.os", "linux")
+	report.Packages[2].AddProperty("go.arch", "arm64")
+	report.Packages[2].AddProperty("Build ID", "1234")
+	report.Packages[2].AddProperty("1st", "ignored")
+
+	tests := []struct {
+		name    string
+		options []Option
+		want    string
+	}{
+		{
+			"default",
+			nil,
+			"goos: linux\n" +
+				"goarch: amd64\n" +
+				"pkg: package/one\n" +
+				"BenchmarkParse\t1000\t1591 ns/op\t4969 requests/s\t512 B/op\t3 allocs/op\n" +
+				"BenchmarkSize\t200000\t5.25 ns/op\t95.76 MB/s\n" +
+				"goarch: arm64\n" +
+				"pkg: package/three\n" +
+				"BenchmarkRatio\t1000\t0.75 hit-ratio\n",
+		},
+		{
+			"with config",
+			[]Option{WithConfig(true)},
+			"goos: linux\n" +
+				"goarch: amd64\n" +
+				"build-id: 1234\n" +
+				"pkg: package/one\n" +
+				"BenchmarkParse\t1000\t1591 ns/op\t4969 requests/s\t512 B/op\t3 allocs/op\n" +
+				"BenchmarkSize\t200000\t5.25 ns/op\t95.76 MB/s\n" +
+				"goarch: arm64\n" +
+				"pkg: package/three\n" +
+				"BenchmarkRatio\t1000\t0.75 hit-ratio\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, report, test.options...); err != nil {
+				t.Fatalf("Write error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, buf.String()); diff != "" {
+				t.Errorf("Write output incorrect, diff (-want +got):\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{{Name: "package/one"}}}
+	test := gtr.NewTest(1, "BenchmarkOne")
+	test.Result = gtr.Pass
+	want := gotest.Benchmark{
+		Iterations:  5000,
+		NsPerOp:     201234.5,
+		BytesPerOp:  512,
+		AllocsPerOp: 3,
+		Metrics:     map[string]float64{"p99-ns": 350000, "requests/s": 4969.25},
+	}
+	gotest.SetBenchmarkData(&test, want)
+	report.Packages[0].Tests = append(report.Packages[0].Tests, test)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	buf.WriteString("ok  \tpackage/one\t1.000s\n")
+
+	parsed, err := gotest.NewParser().Parse(&buf)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	got, ok := gotest.GetBenchmarkData(parsed.Packages[0].Tests[0])
+	if !ok {
+		t.Fatalf("parsed test has no benchmark data")
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parsed benchmark incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
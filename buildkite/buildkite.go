@@ -0,0 +1,309 @@
+// Package buildkite creates the JSON payload of Buildkite Test Analytics from
+// a gtr.Report, and uploads it using the Test Analytics API.
+//
+// Every test, including subtests, is written as a test whose scope is the name
+// of its package. The timing of each test is written as its history span, with
+// the start and end time in seconds relative to the start of the test run.
+// Build and runtime errors are written as failed tests. See
+// https://buildkite.com/docs/test-analytics/importing-json for a description
+// of the format.
+package buildkite
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// DefaultUploadURL is the URL of the Test Analytics upload API.
+const DefaultUploadURL = "https://analytics-api.buildkite.com/v1/uploads"
+
+// MaxUploadSize is the maximum number of tests the upload API accepts in a
+// single request. Upload splits larger payloads into multiple requests.
+const MaxUploadSize = 5000
+
+// Test Analytics test results.
+const (
+	ResultPassed  = "passed"
+	ResultFailed  = "failed"
+	ResultSkipped = "skipped"
+	ResultUnknown = "unknown"
+)
+
+// Payload is the data of a Test Analytics upload.
+type Payload struct {
+	Format string `json:"format"`
+	RunEnv RunEnv `json:"run_env"`
+	Data   []Test `json:"data"`
+}
+
+// RunEnv describes the build the tests ran in. Uploads with the same Key are
+// combined into a single run by Test Analytics.
+type RunEnv struct {
+	CI        string `json:"CI"`
+	Key       string `json:"key"`
+	Number    string `json:"number,omitempty"`
+	JobID     string `json:"job_id,omitempty"`
+	Branch    string `json:"branch,omitempty"`
+	CommitSHA string `json:"commit_sha,omitempty"`
+	Message   string `json:"message,omitempty"`
+	URL       string `json:"url,omitempty"`
+	Collector string `json:"collector,omitempty"`
+}
+
+// Test is the result of a single test.
+type Test struct {
+	ID              string            `json:"id"`
+	Scope           string            `json:"scope"`
+	Name            string            `json:"name"`
+	Identifier      string            `json:"identifier"`
+	Location        string            `json:"location,omitempty"`
+	FileName        string            `json:"file_name,omitempty"`
+	Result          string            `json:"result"`
+	FailureReason   string            `json:"failure_reason,omitempty"`
+	FailureExpanded []FailureExpanded `json:"failure_expanded,omitempty"`
+	History         Span              `json:"history"`
+}
+
+// FailureExpanded contains the details of a test failure.
+type FailureExpanded struct {
+	Expanded  []string `json:"expanded"`
+	Backtrace []string `json:"backtrace"`
+}
+
+// Span is the timing of a test. StartAt and EndAt are in seconds since the
+// start of the test run, Duration is in seconds.
+type Span struct {
+	Section  string  `json:"section"`
+	StartAt  float64 `json:"start_at"`
+	EndAt    float64 `json:"end_at"`
+	Duration float64 `json:"duration"`
+	Children []Span  `json:"children"`
+}
+
+// Options configures how a report is converted to a payload.
+type Options struct {
+	// RunEnv describes the build the tests ran in, see EnvFromEnviron.
+	RunEnv RunEnv
+
+	// Start is the start time of packages without a known start time or
+	// timestamp.
+	Start time.Time
+}
+
+// EnvFromEnviron returns the RunEnv of the Buildkite build the tests ran in,
+// using getenv to look up the environment variables set by the Buildkite
+// agent. Outside of Buildkite, a generic RunEnv is returned whose Key is
+// empty, see NewKey.
+func EnvFromEnviron(getenv func(string) string) RunEnv {
+	if getenv("BUILDKITE_BUILD_ID") == "" {
+		return RunEnv{CI: "generic", Collector: "go-junit-report"}
+	}
+	return RunEnv{
+		CI:        "buildkite",
+		Key:       getenv("BUILDKITE_BUILD_ID"),
+		Number:    getenv("BUILDKITE_BUILD_NUMBER"),
+		JobID:     getenv("BUILDKITE_JOB_ID"),
+		Branch:    getenv("BUILDKITE_BRANCH"),
+		CommitSHA: getenv("BUILDKITE_COMMIT"),
+		Message:   getenv("BUILDKITE_MESSAGE"),
+		URL:       getenv("BUILDKITE_BUILD_URL"),
+		Collector: "go-junit-report",
+	}
+}
+
+// NewKey returns a new random run key.
+func NewKey() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // variant 10
+	return uuid(b[:]), nil
+}
+
+// CreateFromReport converts report r into a payload using the given options.
+func CreateFromReport(r gtr.Report, opts Options) Payload {
+	var start time.Time
+	for _, pkg := range r.Packages {
+		if pkgStart, _ := pkg.Times(opts.Start); start.IsZero() || pkgStart.Before(start) {
+			start = pkgStart
+		}
+	}
+
+	b := builder{key: opts.RunEnv.Key, start: start, ids: make(map[string]int)}
+	for _, pkg := range r.Packages {
+		pkgStart, pkgEnd := pkg.Times(opts.Start)
+		for _, t := range pkg.Tests {
+			b.addTest(pkg.Name, t, pkgStart)
+		}
+		if pkg.BuildError.Name != "" {
+			b.addError(pkg.Name, "Build error", pkg.BuildError, pkgStart, pkgEnd)
+		}
+		if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			b.addError(pkg.Name, "Runtime error", pkg.RunError, pkgStart, pkgEnd)
+		}
+	}
+	if b.tests == nil {
+		b.tests = []Test{}
+	}
+	return Payload{Format: "json", RunEnv: opts.RunEnv, Data: b.tests}
+}
+
+// builder creates the tests of a single payload.
+type builder struct {
+	key   string
+	start time.Time
+	ids   map[string]int // number of tests created by identifier
+	tests []Test
+}
+
+// newTest returns a new test with the given package and name. Test ids are
+// derived from the run key and the test identifier, so converting the same
+// report again results in the same ids.
+func (b *builder) newTest(pkgName, name string) Test {
+	identifier := pkgName + " " + name
+	n := b.ids[identifier]
+	b.ids[identifier]++
+	sum := sha1.Sum([]byte(b.key + "\x00" + identifier + "\x00" + strconv.Itoa(n)))
+	sum[6] = sum[6]&0x0f | 0x50 // version 5
+	sum[8] = sum[8]&0x3f | 0x80 // variant 10
+	return Test{ID: uuid(sum[:16]), Scope: pkgName, Name: name, Identifier: identifier}
+}
+
+func (b *builder) addTest(pkgName string, t gtr.Test, pkgStart time.Time) {
+	bt := b.newTest(pkgName, t.Name)
+	if t.File != "" {
+		bt.FileName, bt.Location = t.File, t.File
+		if t.Line > 0 {
+			bt.Location += ":" + strconv.Itoa(t.Line)
+		}
+	}
+
+	start := t.StartTime
+	if start.IsZero() {
+		start = pkgStart
+	}
+	end := t.EndTime
+	if end.IsZero() {
+		end = start.Add(t.Duration)
+	}
+	bt.History = b.span(start, end)
+
+	switch t.Result.Base() {
+	case gtr.Pass, gtr.Flaky:
+		bt.Result = ResultPassed
+	case gtr.Fail:
+		bt.Result = ResultFailed
+		bt.FailureReason = t.FailureReason()
+		expanded := FailureExpanded{Expanded: t.Output, Backtrace: []string{}}
+		if t.Panic != nil {
+			expanded.Backtrace = t.Panic.Stack
+		}
+		if expanded.Expanded == nil {
+			expanded.Expanded = []string{}
+		}
+		bt.FailureExpanded = []FailureExpanded{expanded}
+	case gtr.Skip:
+		bt.Result = ResultSkipped
+	default:
+		bt.Result = ResultUnknown
+		bt.FailureReason = "No test result found"
+	}
+	b.tests = append(b.tests, bt)
+}
+
+func (b *builder) addError(pkgName, name string, e gtr.Error, start, end time.Time) {
+	t := b.newTest(pkgName, name)
+	t.Result = ResultFailed
+	t.FailureReason = e.FailureReason()
+	if t.FailureReason == "" {
+		t.FailureReason = strings.ToLower(name)
+	}
+	output := e.Output
+	if output == nil {
+		output = []string{}
+	}
+	t.FailureExpanded = []FailureExpanded{{Expanded: output, Backtrace: []string{}}}
+	t.History = b.span(start, end)
+	b.tests = append(b.tests, t)
+}
+
+// span returns the top level span of a test that ran from start to end.
+func (b *builder) span(start, end time.Time) Span {
+	return Span{
+		Section:  "top",
+		StartAt:  start.Sub(b.start).Seconds(),
+		EndAt:    end.Sub(b.start).Seconds(),
+		Duration: end.Sub(start).Seconds(),
+		Children: []Span{},
+	}
+}
+
+func uuid(b []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// WriteJSON writes the indented JSON encoding of payload p to w.
+func (p Payload) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(p)
+}
+
+// Upload sends payload p to the Test Analytics upload API at url, such as
+// DefaultUploadURL, authenticating with the API token of the test suite.
+// Payloads containing more than MaxUploadSize tests are sent in multiple
+// requests.
+func Upload(client *http.Client, url, token string, p Payload) error {
+	data := p.Data
+	for {
+		batch := p
+		batch.Data = data
+		if len(data) > MaxUploadSize {
+			batch.Data = data[:MaxUploadSize]
+		}
+		if err := upload(client, url, token, batch); err != nil {
+			return err
+		}
+		data = data[len(batch.Data):]
+		if len(data) == 0 {
+			return nil
+		}
+	}
+}
+
+func upload(client *http.Client, url, token string, p Payload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Token token=%q", token))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("test analytics returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, err = io.Copy(ioutil.Discard, resp.Body)
+	return err
+}
@@ -0,0 +1,153 @@
+package buildkite
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+var testStart = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestCreateFromReport(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{
+		{
+			Name:      "package/one",
+			StartTime: testStart,
+			Duration:  3 * time.Second,
+			Tests: []gtr.Test{
+				{Name: "TestOne", Result: gtr.Pass, Duration: time.Second, File: "one_test.go", Line: 10},
+				{Name: "TestOne/sub", Result: gtr.Fail, StartTime: testStart.Add(time.Second), Duration: 500 * time.Millisecond, FailureMessage: "boom", Output: []string{"one_test.go:12: boom"}},
+				{Name: "TestTwo", Result: gtr.Skip, SkipMessage: "not today"},
+			},
+		},
+		{
+			Name:       "package/two",
+			StartTime:  testStart.Add(2 * time.Second),
+			Duration:   time.Second,
+			BuildError: gtr.Error{Name: "package/two", Cause: "compilation failed", Output: []string{"two.go:1: undefined: x"}},
+		},
+	}}
+
+	want := Payload{
+		Format: "json",
+		RunEnv: RunEnv{CI: "buildkite", Key: "build-id"},
+		Data: []Test{
+			{
+				Scope:      "package/one",
+				Name:       "TestOne",
+				Identifier: "package/one TestOne",
+				Location:   "one_test.go:10",
+				FileName:   "one_test.go",
+				Result:     ResultPassed,
+				History:    Span{Section: "top", StartAt: 0, EndAt: 1, Duration: 1, Children: []Span{}},
+			},
+			{
+				Scope:           "package/one",
+				Name:            "TestOne/sub",
+				Identifier:      "package/one TestOne/sub",
+				Result:          ResultFailed,
+				FailureReason:   "boom",
+				FailureExpanded: []FailureExpanded{{Expanded: []string{"one_test.go:12: boom"}, Backtrace: []string{}}},
+				History:         Span{Section: "top", StartAt: 1, EndAt: 1.5, Duration: 0.5, Children: []Span{}},
+			},
+			{
+				Scope:      "package/one",
+				Name:       "TestTwo",
+				Identifier: "package/one TestTwo",
+				Result:     ResultSkipped,
+				History:    Span{Section: "top", StartAt: 0, EndAt: 0, Duration: 0, Children: []Span{}},
+			},
+			{
+				Scope:           "package/two",
+				Name:            "Build error",
+				Identifier:      "package/two Build error",
+				Result:          ResultFailed,
+				FailureReason:   "compilation failed",
+				FailureExpanded: []FailureExpanded{{Expanded: []string{"two.go:1: undefined: x"}, Backtrace: []string{}}},
+				History:         Span{Section: "top", StartAt: 2, EndAt: 3, Duration: 1, Children: []Span{}},
+			},
+		},
+	}
+
+	got := CreateFromReport(report, Options{RunEnv: RunEnv{CI: "buildkite", Key: "build-id"}, Start: testStart})
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Test{}, "ID")); diff != "" {
+		t.Errorf("CreateFromReport incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	ids := make(map[string]bool)
+	for _, test := range got.Data {
+		if len(test.ID) != 36 || ids[test.ID] {
+			t.Errorf("CreateFromReport test %q has invalid or duplicate id %q", test.Name, test.ID)
+		}
+		ids[test.ID] = true
+	}
+	if again := CreateFromReport(report, Options{RunEnv: RunEnv{CI: "buildkite", Key: "build-id"}, Start: testStart}); again.Data[0].ID != got.Data[0].ID {
+		t.Errorf("CreateFromReport test id changed from %q to %q", got.Data[0].ID, again.Data[0].ID)
+	}
+}
+
+func TestEnvFromEnviron(t *testing.T) {
+	env := map[string]string{
+		"BUILDKITE_BUILD_ID":     "build-id",
+		"BUILDKITE_BUILD_NUMBER": "42",
+		"BUILDKITE_BRANCH":       "main",
+		"BUILDKITE_COMMIT":       "abc123",
+	}
+	want := RunEnv{CI: "buildkite", Key: "build-id", Number: "42", Branch: "main", CommitSHA: "abc123", Collector: "go-junit-report"}
+	got := EnvFromEnviron(func(name string) string { return env[name] })
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("EnvFromEnviron incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	if got := EnvFromEnviron(func(string) string { return "" }); got.CI != "generic" || got.Key != "" {
+		t.Errorf("EnvFromEnviron outside Buildkite = %+v, want generic env without key", got)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	var gotAuth string
+	var sizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		data, _ := ioutil.ReadAll(r.Body)
+		var p Payload
+		if err := json.Unmarshal(data, &p); err != nil {
+			t.Errorf("error decoding request body: %v", err)
+		}
+		if p.RunEnv.Key != "build-id" {
+			t.Errorf("Upload sent run key %q, want %q", p.RunEnv.Key, "build-id")
+		}
+		sizes = append(sizes, len(p.Data))
+	}))
+	defer server.Close()
+
+	p := Payload{Format: "json", RunEnv: RunEnv{CI: "generic", Key: "build-id"}, Data: make([]Test, MaxUploadSize+1)}
+	if err := Upload(server.Client(), server.URL, "secret", p); err != nil {
+		t.Fatalf("Upload error: %v", err)
+	}
+	if want := `Token token="secret"`; gotAuth != want {
+		t.Errorf("Upload sent Authorization header %q, want %q", gotAuth, want)
+	}
+	if diff := cmp.Diff([]int{MaxUploadSize, 1}, sizes); diff != "" {
+		t.Errorf("Upload request sizes incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestUploadError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	if err := Upload(server.Client(), server.URL, "token", Payload{}); err == nil {
+		t.Errorf("Upload did not return an error for a failed request")
+	}
+}
@@ -0,0 +1,275 @@
+// Package bundle packages test reports, the raw test output and the files
+// attached to tests into a single zip or tar.gz archive, so that everything
+// belonging to a test run can be stored as a single CI artifact.
+//
+// Every bundle contains a manifest.json file listing the other files in the
+// bundle with their kind, size and SHA-256 checksum. Attachments are stored
+// in the attachments directory of the bundle, and their entry in the manifest
+// records the path they were found at and the test they're attached to.
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// Supported archive formats.
+const (
+	FormatZip   = "zip"
+	FormatTarGz = "tar.gz"
+)
+
+// Kinds of files in a bundle.
+const (
+	KindReport     = "report"
+	KindLog        = "log"
+	KindAttachment = "attachment"
+)
+
+// ManifestName is the name of the manifest in a bundle.
+const ManifestName = "manifest.json"
+
+// FormatFromName returns the archive format for a bundle written to the file
+// with the given name, based on its extension.
+func FormatFromName(name string) (string, error) {
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return FormatZip, nil
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return FormatTarGz, nil
+	default:
+		return "", fmt.Errorf("unknown archive format of %s, use a .zip, .tar.gz or .tgz extension", name)
+	}
+}
+
+// File is a report or log to add to a bundle.
+type File struct {
+	Name string // path in the bundle
+	Kind string // KindReport or KindLog
+	Data []byte
+}
+
+// Bundle describes the contents of a bundle.
+type Bundle struct {
+	Files []File
+
+	// Report is the report whose attachments are added to the bundle.
+	// Attachments whose path is a URL are not added.
+	Report gtr.Report
+
+	// Dir is the directory that relative attachment paths are relative to,
+	// the current directory if empty.
+	Dir string
+
+	// Created is the creation time of the bundle, used as the modification
+	// time of its files.
+	Created time.Time
+}
+
+// Manifest lists the files in a bundle.
+type Manifest struct {
+	Created time.Time `json:"created"`
+	Files   []Entry   `json:"files"`
+}
+
+// Entry describes a single file in a bundle. Attachments that could not be
+// found are listed as missing, and are not in the bundle.
+type Entry struct {
+	Path    string `json:"path"`
+	Kind    string `json:"kind"`
+	Size    int64  `json:"size"`
+	SHA256  string `json:"sha256,omitempty"`
+	Source  string `json:"source,omitempty"`  // path of an attachment
+	Package string `json:"package,omitempty"` // package of an attachment
+	Test    string `json:"test,omitempty"`    // test of an attachment, if any
+	Missing bool   `json:"missing,omitempty"`
+}
+
+// Write writes bundle b to w as an archive in the given format.
+func (b Bundle) Write(w io.Writer, format string) error {
+	var a archive
+	switch format {
+	case FormatZip:
+		a = zipArchive{zip.NewWriter(w)}
+	case FormatTarGz:
+		gw := gzip.NewWriter(w)
+		a = tarArchive{tar.NewWriter(gw), gw}
+	default:
+		return fmt.Errorf("unknown archive format: %s", format)
+	}
+
+	bw := writer{a: a, created: b.Created, names: make(map[string]bool)}
+	if err := bw.write(b); err != nil {
+		a.Close()
+		return err
+	}
+	return a.Close()
+}
+
+// writer writes the files of a bundle to an archive and records them in its
+// manifest.
+type writer struct {
+	a        archive
+	created  time.Time
+	names    map[string]bool // names of the files in the archive
+	manifest Manifest
+}
+
+func (w *writer) write(b Bundle) error {
+	w.manifest = Manifest{Created: b.Created, Files: []Entry{}}
+	for _, f := range b.Files {
+		entry := Entry{Path: w.uniqueName(f.Name), Kind: f.Kind}
+		if err := w.add(&entry, bytes.NewReader(f.Data), int64(len(f.Data))); err != nil {
+			return err
+		}
+	}
+
+	sources := make(map[string]bool)
+	for _, pkg := range b.Report.Packages {
+		for _, a := range pkg.Attachments {
+			if err := w.addAttachment(b.Dir, sources, pkg.Name, "", a); err != nil {
+				return err
+			}
+		}
+		for _, t := range pkg.Tests {
+			for _, a := range t.Attachments {
+				if err := w.addAttachment(b.Dir, sources, pkg.Name, t.Name, a); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(w.manifest, "", "\t")
+	if err != nil {
+		return err
+	}
+	out, err := w.a.Create(ManifestName, int64(len(data)+1), w.created)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(append(data, '\n'))
+	return err
+}
+
+// addAttachment adds the file of attachment a of the given package and test
+// to the archive. Attachments that have already been added from another test
+// or whose path is a URL are skipped.
+func (w *writer) addAttachment(dir string, sources map[string]bool, pkgName, testName string, a gtr.Attachment) error {
+	if a.Path == "" || strings.Contains(a.Path, "://") || sources[a.Path] {
+		return nil
+	}
+	sources[a.Path] = true
+
+	entry := Entry{Kind: KindAttachment, Source: a.Path, Package: pkgName, Test: testName}
+	file := a.Path
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		entry.Missing = true
+		w.manifest.Files = append(w.manifest.Files, entry)
+		return nil
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	entry.Path = w.uniqueName(path.Join("attachments", attachmentName(a.Path)))
+	return w.add(&entry, f, info.Size())
+}
+
+// add adds a file containing size bytes read from r to the archive, and
+// adds its entry to the manifest.
+func (w *writer) add(entry *Entry, r io.Reader, size int64) error {
+	out, err := w.a.Create(entry.Path, size, w.created)
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(out, h), io.LimitReader(r, size))
+	if err != nil {
+		return err
+	}
+	entry.Size, entry.SHA256 = n, hex.EncodeToString(h.Sum(nil))
+	w.manifest.Files = append(w.manifest.Files, *entry)
+	return nil
+}
+
+// uniqueName returns name, with a number added before its extension if the
+// archive already contains a file with that name.
+func (w *writer) uniqueName(name string) string {
+	unique := name
+	ext := path.Ext(name)
+	for i := 2; w.names[unique] || unique == ManifestName; i++ {
+		unique = strings.TrimSuffix(name, ext) + "-" + strconv.Itoa(i) + ext
+	}
+	w.names[unique] = true
+	return unique
+}
+
+// attachmentName returns the name in the attachments directory of the
+// attachment at path p. Relative paths are kept as long as they don't refer
+// to a parent directory, otherwise only the file name is used.
+func attachmentName(p string) string {
+	p = filepath.ToSlash(filepath.Clean(p))
+	if path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") || filepath.VolumeName(p) != "" {
+		return path.Base(p)
+	}
+	return p
+}
+
+// archive is an archive that files can be written to.
+type archive interface {
+	Create(name string, size int64, modTime time.Time) (io.Writer, error)
+	Close() error
+}
+
+type zipArchive struct {
+	w *zip.Writer
+}
+
+func (a zipArchive) Create(name string, size int64, modTime time.Time) (io.Writer, error) {
+	return a.w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modTime})
+}
+
+func (a zipArchive) Close() error {
+	return a.w.Close()
+}
+
+type tarArchive struct {
+	w  *tar.Writer
+	gw *gzip.Writer
+}
+
+func (a tarArchive) Create(name string, size int64, modTime time.Time) (io.Writer, error) {
+	hdr := &tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: size, ModTime: modTime}
+	if err := a.w.WriteHeader(hdr); err != nil {
+		return nil, err
+	}
+	return a.w, nil
+}
+
+func (a tarArchive) Close() error {
+	if err := a.w.Close(); err != nil {
+		return err
+	}
+	return a.gw.Close()
+}
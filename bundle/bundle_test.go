@@ -0,0 +1,181 @@
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// readZip returns the contents of the files in zip archive data by name.
+func readZip(t *testing.T, data []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("error reading zip archive: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("error opening %s: %v", f.Name, err)
+		}
+		content, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("error reading %s: %v", f.Name, err)
+		}
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+// readTarGz returns the contents of the files in tar.gz archive data by name.
+func readTarGz(t *testing.T, data []byte) map[string]string {
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("error reading gzip stream: %v", err)
+	}
+	tr := tar.NewReader(gr)
+	files := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("error reading tar archive: %v", err)
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("error reading %s: %v", hdr.Name, err)
+		}
+		files[hdr.Name] = string(content)
+	}
+	return files
+}
+
+func TestWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.MkdirAll(filepath.Join(dir, "testdata"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "testdata", "screenshot.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "cpu.prof"), []byte("profile"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := Bundle{
+		Files: []File{
+			{Name: "report.xml", Kind: KindReport, Data: []byte("<testsuites/>")},
+			{Name: "test.log", Kind: KindLog, Data: []byte("ok  \tpackage/name\t0.1s\n")},
+		},
+		Report: gtr.Report{Packages: []gtr.Package{{
+			Name:        "package/name",
+			Attachments: []gtr.Attachment{{Path: filepath.Join(dir, "cpu.prof")}, {Path: "https://example.com/log"}},
+			Tests: []gtr.Test{
+				{Name: "TestOne", Attachments: []gtr.Attachment{{Path: "testdata/screenshot.png"}, {Path: "missing.txt"}}},
+				{Name: "TestTwo", Attachments: []gtr.Attachment{{Path: "testdata/screenshot.png"}}},
+			},
+		}}},
+		Dir:     dir,
+		Created: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	wantManifest := Manifest{
+		Created: b.Created,
+		Files: []Entry{
+			{Path: "report.xml", Kind: KindReport, Size: 13},
+			{Path: "test.log", Kind: KindLog, Size: 23},
+			{Path: "attachments/cpu.prof", Kind: KindAttachment, Size: 7, Source: filepath.Join(dir, "cpu.prof"), Package: "package/name"},
+			{Path: "attachments/testdata/screenshot.png", Kind: KindAttachment, Size: 3, Source: "testdata/screenshot.png", Package: "package/name", Test: "TestOne"},
+			{Kind: KindAttachment, Source: "missing.txt", Package: "package/name", Test: "TestOne", Missing: true},
+		},
+	}
+	wantFiles := map[string]string{
+		"report.xml":                          "<testsuites/>",
+		"test.log":                            "ok  \tpackage/name\t0.1s\n",
+		"attachments/cpu.prof":                "profile",
+		"attachments/testdata/screenshot.png": "png",
+	}
+
+	for _, format := range []string{FormatZip, FormatTarGz} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := b.Write(&buf, format); err != nil {
+				t.Fatalf("Write error: %v", err)
+			}
+			var files map[string]string
+			if format == FormatZip {
+				files = readZip(t, buf.Bytes())
+			} else {
+				files = readTarGz(t, buf.Bytes())
+			}
+
+			var manifest Manifest
+			if err := json.Unmarshal([]byte(files[ManifestName]), &manifest); err != nil {
+				t.Fatalf("error decoding manifest: %v", err)
+			}
+			delete(files, ManifestName)
+			for i := range manifest.Files {
+				if manifest.Files[i].Path != "" && len(manifest.Files[i].SHA256) != 64 {
+					t.Errorf("manifest entry %s has invalid checksum %q", manifest.Files[i].Path, manifest.Files[i].SHA256)
+				}
+				manifest.Files[i].SHA256 = ""
+			}
+			if diff := cmp.Diff(wantFiles, files); diff != "" {
+				t.Errorf("Write archive files incorrect, diff (-want +got):\n%s\n", diff)
+			}
+			if diff := cmp.Diff(wantManifest, manifest); diff != "" {
+				t.Errorf("Write manifest incorrect, diff (-want +got):\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	w := writer{names: make(map[string]bool)}
+	var got []string
+	for _, name := range []string{"report.xml", "report.xml", "report.xml", "manifest.json"} {
+		got = append(got, w.uniqueName(name))
+	}
+	want := []string{"report.xml", "report-2.xml", "report-3.xml", "manifest-2.json"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("uniqueName incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestFormatFromName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"results.zip", FormatZip},
+		{"results.tar.gz", FormatTarGz},
+		{"results.tgz", FormatTarGz},
+	}
+	for _, test := range tests {
+		got, err := FormatFromName(test.name)
+		if err != nil || got != test.want {
+			t.Errorf("FormatFromName(%q) = %q, %v; want %q", test.name, got, err, test.want)
+		}
+	}
+	if _, err := FormatFromName("results.rar"); err == nil {
+		t.Errorf("FormatFromName(%q) did not return an error", "results.rar")
+	}
+}
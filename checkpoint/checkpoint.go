@@ -0,0 +1,108 @@
+// Package checkpoint saves the packages of a report as soon as they complete,
+// so that the results of finished packages are kept when go test or
+// go-junit-report is killed before the report has been written.
+//
+// A checkpoint contains one line for each completed package. Every line is a
+// report in the format of package gtrjson containing that package only. Since
+// a line is written at once and ends with a newline, a line that was cut short
+// by a crash can be recognized and is ignored by Read.
+//
+// Importing this package registers the checkpoint parser, which reads a
+// checkpoint using Read, so that an interrupted run can still be converted to
+// a report.
+package checkpoint
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/gtrjson"
+	"github.com/jstemmer/go-junit-report/v2/parser"
+)
+
+func init() {
+	parser.Register("checkpoint", func() parser.Parser { return NewParser() })
+}
+
+// Writer writes packages to a checkpoint.
+type Writer struct {
+	w   io.Writer
+	err error
+}
+
+// NewWriter returns a Writer that writes a checkpoint to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WritePackage writes pkg as a single line to the checkpoint. If the
+// underlying writer has a Sync method, such as *os.File, it's called after
+// each line so the line is kept even if the machine crashes.
+func (w *Writer) WritePackage(pkg gtr.Package) error {
+	data, err := gtrjson.Marshal(gtr.Report{Packages: []gtr.Package{pkg}})
+	if err != nil {
+		return err
+	}
+	if _, err := w.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if s, ok := w.w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// HandlePackage writes pkg to the checkpoint like WritePackage. Once writing
+// fails, HandlePackage does nothing and the error is returned by Err. It can
+// be used as the handler of the gotest.PackageHandler option.
+func (w *Writer) HandlePackage(pkg gtr.Package) {
+	if w.err == nil {
+		w.err = w.WritePackage(pkg)
+	}
+}
+
+// Err returns the first error that occurred in HandlePackage, if any.
+func (w *Writer) Err() error {
+	return w.err
+}
+
+// Read reads a checkpoint from r and returns a report containing all of its
+// packages, in the order they were written. A last line without a trailing
+// newline is ignored, as it was only partially written.
+func Read(r io.Reader) (gtr.Report, error) {
+	var report gtr.Report
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			return report, nil
+		} else if err != nil {
+			return report, err
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		rep, err := gtrjson.Unmarshal(line)
+		if err != nil {
+			return report, fmt.Errorf("checkpoint: line %d: %w", n, err)
+		}
+		report.Packages = append(report.Packages, rep.Packages...)
+	}
+}
+
+// Parser parses checkpoints.
+type Parser struct{}
+
+// NewParser returns a new checkpoint parser.
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// Parse reads a checkpoint from r, see Read.
+func (p *Parser) Parse(r io.Reader) (gtr.Report, error) {
+	return Read(r)
+}
@@ -0,0 +1,79 @@
+package checkpoint
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+func TestWriteRead(t *testing.T) {
+	packages := []gtr.Package{
+		{
+			Name:     "package/one",
+			Duration: time.Second,
+			Tests:    []gtr.Test{{Name: "TestOne", Result: gtr.Pass}},
+		},
+		{
+			Name:     "package/two",
+			Hostname: "runner-1",
+			Tests:    []gtr.Test{{Name: "TestTwo", Result: gtr.Fail, Output: []string{"boom"}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, pkg := range packages {
+		w.HandlePackage(pkg)
+	}
+	if err := w.Err(); err != nil {
+		t.Fatalf("HandlePackage error: %v", err)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != len(packages) {
+		t.Errorf("checkpoint contains %d lines, want %d", got, len(packages))
+	}
+
+	// A crash while writing the third package leaves a partial line.
+	buf.WriteString(`{"version":1,"packages":[{"name":"package/thr`)
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if diff := cmp.Diff(gtr.Report{Packages: packages}, got); diff != "" {
+		t.Errorf("Read result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	input := `{"version":1,"packages":[{"name":"package/one"}]}` + "\n" + "not json\n"
+	if _, err := Read(strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Read error = %v, want an error for line 2", err)
+	}
+}
+
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, errors.New("disk full")
+}
+
+func TestHandlePackageError(t *testing.T) {
+	fw := &failingWriter{}
+	w := NewWriter(fw)
+	w.HandlePackage(gtr.Package{Name: "package/one"})
+	w.HandlePackage(gtr.Package{Name: "package/two"})
+	if w.Err() == nil {
+		t.Errorf("Err() = nil, want an error")
+	}
+	if fw.writes != 1 {
+		t.Errorf("HandlePackage wrote %d times after an error, want 1", fw.writes)
+	}
+}
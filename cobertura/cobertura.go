@@ -0,0 +1,175 @@
+// Package cobertura defines a Cobertura XML coverage report and includes
+// convenience methods to create these reports from Go coverage profiles.
+package cobertura
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/coverage"
+)
+
+// Coverage is the root element of a Cobertura report.
+type Coverage struct {
+	XMLName xml.Name `xml:"coverage"`
+
+	LineRate        string `xml:"line-rate,attr"`
+	BranchRate      string `xml:"branch-rate,attr"`
+	LinesCovered    int    `xml:"lines-covered,attr"`
+	LinesValid      int    `xml:"lines-valid,attr"`
+	BranchesCovered int    `xml:"branches-covered,attr"`
+	BranchesValid   int    `xml:"branches-valid,attr"`
+	Complexity      string `xml:"complexity,attr"`
+	Version         string `xml:"version,attr"`
+	Timestamp       int64  `xml:"timestamp,attr"` // milliseconds since the Unix epoch
+
+	Sources  []string  `xml:"sources>source,omitempty"`
+	Packages []Package `xml:"packages>package"`
+}
+
+// Package contains the coverage of a single package.
+type Package struct {
+	Name       string  `xml:"name,attr"`
+	LineRate   string  `xml:"line-rate,attr"`
+	BranchRate string  `xml:"branch-rate,attr"`
+	Complexity string  `xml:"complexity,attr"`
+	Classes    []Class `xml:"classes>class"`
+}
+
+// Class contains the coverage of a single file.
+type Class struct {
+	Name       string  `xml:"name,attr"`
+	Filename   string  `xml:"filename,attr"`
+	LineRate   string  `xml:"line-rate,attr"`
+	BranchRate string  `xml:"branch-rate,attr"`
+	Complexity string  `xml:"complexity,attr"`
+	Methods    *string `xml:"methods"`
+	Lines      []Line  `xml:"lines>line"`
+}
+
+// Line contains the number of hits of a single line.
+type Line struct {
+	Number int `xml:"number,attr"`
+	Hits   int `xml:"hits,attr"`
+}
+
+// CreateFromProfile creates a Cobertura report from the given coverage
+// profile. Files are grouped into packages by their directory. Go coverage
+// profiles do not contain branch coverage, so all branch rates are 0.
+func CreateFromProfile(profile *coverage.Profile, timestamp time.Time) Coverage {
+	cov := Coverage{
+		BranchRate: formatRate(0, 0),
+		Complexity: "0",
+	}
+	if !timestamp.IsZero() {
+		cov.Timestamp = timestamp.UnixNano() / int64(time.Millisecond)
+	}
+
+	byPackage := make(map[string][]Class)
+	pkgTotals := make(map[string][2]int)
+	for _, file := range profile.Files() {
+		class, covered, valid := createClass(profile, file)
+		pkg := path.Dir(file)
+		byPackage[pkg] = append(byPackage[pkg], class)
+
+		totals := pkgTotals[pkg]
+		totals[0] += covered
+		totals[1] += valid
+		pkgTotals[pkg] = totals
+
+		cov.LinesCovered += covered
+		cov.LinesValid += valid
+	}
+
+	for _, name := range profile.Packages() {
+		totals := pkgTotals[name]
+		cov.Packages = append(cov.Packages, Package{
+			Name:       name,
+			LineRate:   formatRate(totals[0], totals[1]),
+			BranchRate: formatRate(0, 0),
+			Complexity: "0",
+			Classes:    byPackage[name],
+		})
+	}
+	cov.LineRate = formatRate(cov.LinesCovered, cov.LinesValid)
+	return cov
+}
+
+// SetSource makes the file names in report c relative to the module with the
+// given path, whose source code is in directory dir. Tools such as GitLab and
+// Jenkins look up each file by joining its name to the source directory, which
+// fails for the import paths used in Go coverage profiles. Files outside of
+// the module keep their import path.
+func (c *Coverage) SetSource(dir, modulePath string) {
+	c.Sources = []string{dir}
+	prefix := strings.TrimSuffix(modulePath, "/") + "/"
+	for i := range c.Packages {
+		for j := range c.Packages[i].Classes {
+			class := &c.Packages[i].Classes[j]
+			class.Filename = strings.TrimPrefix(class.Filename, prefix)
+		}
+	}
+}
+
+// createClass returns the Class for the given file, and the number of covered
+// and valid lines in this file.
+func createClass(profile *coverage.Profile, file string) (class Class, covered, valid int) {
+	hits := profile.Lines(file)
+	var numbers []int
+	for n := range hits {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	var methods string
+	class = Class{
+		Name:       path.Base(file),
+		Filename:   file,
+		BranchRate: formatRate(0, 0),
+		Complexity: "0",
+		Methods:    &methods,
+	}
+	for _, n := range numbers {
+		class.Lines = append(class.Lines, Line{Number: n, Hits: hits[n]})
+		if hits[n] > 0 {
+			covered++
+		}
+	}
+	valid = len(numbers)
+	class.LineRate = formatRate(covered, valid)
+	return class, covered, valid
+}
+
+// formatRate returns the string representation of covered/valid.
+func formatRate(covered, valid int) string {
+	if valid == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%.4f", float64(covered)/float64(valid))
+}
+
+// WriteXML writes the XML representation of Coverage c to writer w,
+// including the XML header and DOCTYPE.
+func (c *Coverage) WriteXML(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, `<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">`+"\n"); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n")
+	return err
+}
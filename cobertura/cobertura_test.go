@@ -0,0 +1,102 @@
+package cobertura
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/coverage"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testProfile = `mode: count
+example.com/pkg/a.go:3.14,5.2 1 2
+example.com/pkg/a.go:7.14,8.2 1 0
+example.com/pkg/sub/b.go:3.14,4.2 2 1
+`
+
+func TestWriteXML(t *testing.T) {
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">
+<coverage line-rate="0.7143" branch-rate="0" lines-covered="5" lines-valid="7" branches-covered="0" branches-valid="0" complexity="0" version="" timestamp="1640995200000">
+	<sources></sources>
+	<packages>
+		<package name="example.com/pkg" line-rate="0.6000" branch-rate="0" complexity="0">
+			<classes>
+				<class name="a.go" filename="example.com/pkg/a.go" line-rate="0.6000" branch-rate="0" complexity="0">
+					<methods></methods>
+					<lines>
+						<line number="3" hits="2"></line>
+						<line number="4" hits="2"></line>
+						<line number="5" hits="2"></line>
+						<line number="7" hits="0"></line>
+						<line number="8" hits="0"></line>
+					</lines>
+				</class>
+			</classes>
+		</package>
+		<package name="example.com/pkg/sub" line-rate="1.0000" branch-rate="0" complexity="0">
+			<classes>
+				<class name="b.go" filename="example.com/pkg/sub/b.go" line-rate="1.0000" branch-rate="0" complexity="0">
+					<methods></methods>
+					<lines>
+						<line number="3" hits="1"></line>
+						<line number="4" hits="1"></line>
+					</lines>
+				</class>
+			</classes>
+		</package>
+	</packages>
+</coverage>
+`
+
+	profile, err := coverage.Parse(strings.NewReader(testProfile))
+	if err != nil {
+		t.Fatalf("coverage.Parse failed: %v", err)
+	}
+
+	cov := CreateFromProfile(profile, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	var buf bytes.Buffer
+	if err := cov.WriteXML(&buf); err != nil {
+		t.Fatalf("WriteXML failed: %v", err)
+	}
+
+	got := buf.String()
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("WriteXML mismatch, diff (-want +got):\n%s\n", diff)
+	}
+
+	var parsed Coverage
+	if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("generated XML could not be parsed: %v", err)
+	}
+	if len(parsed.Packages) != 2 || parsed.LinesValid != 7 {
+		t.Errorf("parsed XML incorrect, got %d packages and %d valid lines", len(parsed.Packages), parsed.LinesValid)
+	}
+}
+
+func TestSetSource(t *testing.T) {
+	profile, err := coverage.Parse(strings.NewReader(testProfile + "example.com/other/c.go:1.1,2.2 1 1\n"))
+	if err != nil {
+		t.Fatalf("coverage.Parse failed: %v", err)
+	}
+	cov := CreateFromProfile(profile, time.Time{})
+	cov.SetSource("/src/pkg", "example.com/pkg")
+
+	if diff := cmp.Diff([]string{"/src/pkg"}, cov.Sources); diff != "" {
+		t.Errorf("Sources incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	var got []string
+	for _, pkg := range cov.Packages {
+		for _, class := range pkg.Classes {
+			got = append(got, pkg.Name+" "+class.Filename)
+		}
+	}
+	want := []string{"example.com/other example.com/other/c.go", "example.com/pkg a.go", "example.com/pkg/sub sub/b.go"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("file names incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
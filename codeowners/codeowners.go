@@ -0,0 +1,134 @@
+// Package codeowners reads CODEOWNERS files and uses them to add owner
+// properties to the packages and tests in a report.
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Rule assigns Owners to the files matching Pattern. A rule without owners
+// removes the ownership of the files it matches.
+type Rule struct {
+	Pattern string
+	Owners  []string
+
+	re *regexp.Regexp
+}
+
+// NewRule returns a rule for the given CODEOWNERS pattern. Patterns use the
+// gitignore syntax supported by CODEOWNERS files: a pattern starting with or
+// containing a slash is relative to the repository root, otherwise it matches
+// at any depth. * matches anything except a slash, ** matches anything and ?
+// matches a single character other than a slash. A pattern matching a
+// directory also matches everything in it.
+func NewRule(pattern string, owners ...string) (Rule, error) {
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return Rule{}, err
+	}
+	if len(owners) == 0 {
+		owners = nil
+	}
+	return Rule{Pattern: pattern, Owners: owners, re: re}, nil
+}
+
+// Matches returns true if rule r matches path, which is a slash-separated path
+// relative to the repository root.
+func (r Rule) Matches(path string) bool {
+	return r.re != nil && r.re.MatchString(strings.TrimPrefix(path, "./"))
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	p := strings.TrimSuffix(pattern, "/")
+	if p == "" {
+		return nil, fmt.Errorf("invalid pattern %q", pattern)
+	}
+
+	var expr strings.Builder
+	if strings.Contains(p, "/") {
+		expr.WriteString("^")
+		p = strings.TrimPrefix(p, "/")
+	} else {
+		expr.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; {
+		case strings.HasPrefix(p[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '\\' && i+1 < len(p):
+			i++
+			expr.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		default:
+			expr.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	expr.WriteString("(?:/.*)?$")
+	return regexp.Compile(expr.String())
+}
+
+// Ruleset is the list of rules in a CODEOWNERS file.
+type Ruleset []Rule
+
+// Owners returns the owners of the file or directory with the given path,
+// which is a slash-separated path relative to the repository root. As in
+// CODEOWNERS files, the last matching rule takes precedence. It returns nil
+// if no rule matches path.
+func (rs Ruleset) Owners(path string) []string {
+	for i := len(rs) - 1; i >= 0; i-- {
+		if rs[i].Matches(path) {
+			return rs[i].Owners
+		}
+	}
+	return nil
+}
+
+// Parse parses a CODEOWNERS file from the given io.Reader r. Each line
+// contains a pattern followed by the owners of the files matching it, all
+// separated by whitespace. Empty lines, comments starting with # and GitLab
+// section headings are ignored.
+func Parse(r io.Reader) (Ruleset, error) {
+	var rules Ruleset
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if idx := strings.Index(line, " #"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") ||
+			strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue
+		}
+		rule, err := NewRule(fields[0], fields[1:]...)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, s.Err()
+}
+
+// Root returns the repository root of the CODEOWNERS file at the given path,
+// relative to which its patterns are resolved. CODEOWNERS files may be
+// located in the root itself or in its .github, .gitlab or docs directory.
+func Root(file string) string {
+	dir := filepath.Dir(file)
+	switch filepath.Base(dir) {
+	case ".github", ".gitlab", "docs":
+		return filepath.Dir(dir)
+	}
+	return dir
+}
@@ -0,0 +1,121 @@
+package codeowners
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*", "main.go", true},
+		{"*", "parser/gotest/gotest.go", true},
+		{"*.go", "parser/gotest/gotest.go", true},
+		{"*.go", "README.md", false},
+		{"gotest", "parser/gotest", true},
+		{"gotest", "parser/gotest/gotest.go", true},
+		{"gotest", "parser/gotester", false},
+		{"/parser/", "parser/gotest/gotest.go", true},
+		{"/parser/", "internal/parser/parser.go", false},
+		{"parser/gotest", "parser/gotest/event.go", true},
+		{"parser/gotest", "internal/parser/gotest/event.go", false},
+		{"parser/*", "parser/files.go", true},
+		{"parser/*", "parser/gotest", true},
+		{"docs/*.md", "docs/intro.md", true},
+		{"docs/*.md", "docs/guide/intro.md", false},
+		{"**/testdata", "parser/gotest/testdata/in.txt", true},
+		{"**/testdata", "testdata/report.xml", true},
+		{"/parser/**/event.go", "parser/gotest/event.go", true},
+		{"/parser/**/event.go", "parser/event.go", true},
+		{"/gtr/**", "gtr/gtr.go", true},
+		{"file?.go", "file1.go", true},
+		{"file?.go", "file10.go", false},
+		{"main.go", "./main.go", true},
+	}
+	for _, test := range tests {
+		rule, err := NewRule(test.pattern)
+		if err != nil {
+			t.Fatalf("NewRule(%q) error: %v", test.pattern, err)
+		}
+		if got := rule.Matches(test.path); got != test.want {
+			t.Errorf("NewRule(%q).Matches(%q) = %v, want %v", test.pattern, test.path, got, test.want)
+		}
+	}
+
+	if _, err := NewRule("/"); err == nil {
+		t.Errorf("NewRule(\"/\") did not return an error")
+	}
+}
+
+func TestParse(t *testing.T) {
+	input := `# default owners
+*       @org/everyone
+
+[Parser]
+/parser/ @org/parser @alice # parser team
+/parser/gotest/testdata/
+/gtr/**/*.go user@example.com
+`
+	rules, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	type rule struct {
+		Pattern string
+		Owners  []string
+	}
+	want := []rule{
+		{"*", []string{"@org/everyone"}},
+		{"/parser/", []string{"@org/parser", "@alice"}},
+		{"/parser/gotest/testdata/", nil},
+		{"/gtr/**/*.go", []string{"user@example.com"}},
+	}
+	var got []rule
+	for _, r := range rules {
+		got = append(got, rule{r.Pattern, r.Owners})
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Parse result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	owners := []struct {
+		path string
+		want []string
+	}{
+		{"main.go", []string{"@org/everyone"}},
+		{"parser/gotest/gotest.go", []string{"@org/parser", "@alice"}},
+		{"parser/gotest/testdata/001-pass.txt", nil},
+		{"gtr/gtr.go", []string{"user@example.com"}},
+	}
+	for _, test := range owners {
+		if diff := cmp.Diff(test.want, rules.Owners(test.path)); diff != "" {
+			t.Errorf("Owners(%q) incorrect, diff (-want +got):\n%s\n", test.path, diff)
+		}
+	}
+}
+
+func TestRoot(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"CODEOWNERS", "."},
+		{filepath.Join("repo", "CODEOWNERS"), "repo"},
+		{filepath.Join("repo", ".github", "CODEOWNERS"), "repo"},
+		{filepath.Join("repo", ".gitlab", "CODEOWNERS"), "repo"},
+		{filepath.Join("repo", "docs", "CODEOWNERS"), "repo"},
+		{filepath.Join(".github", "CODEOWNERS"), "."},
+	}
+	for _, test := range tests {
+		if got := Root(test.file); got != test.want {
+			t.Errorf("Root(%q) = %q, want %q", test.file, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package codeowners
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/srcloc"
+)
+
+// Annotate returns a copy of report r in which every package and test owned
+// according to rules is given one "owner" property for each of its owners.
+// The owners of a package are those of its directory, and the owners of a
+// test are those of the file declaring it, or of its package if the file
+// isn't known. Subtests belong to the file of their top-level test. Paths
+// are matched relative to root, the root of the repository containing the
+// CODEOWNERS file. Packages missing from src are not annotated, see
+// srcloc.ListSources.
+func Annotate(r gtr.Report, rules Ruleset, root string, src srcloc.Sources) gtr.Report {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return r
+	}
+	relPath := func(path string) (string, bool) {
+		rel, err := filepath.Rel(absRoot, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", false
+		}
+		return filepath.ToSlash(rel), true
+	}
+
+	annotated := r
+	annotated.Packages = make([]gtr.Package, len(r.Packages))
+	for i, pkg := range r.Packages {
+		source, ok := src[pkg.Name]
+		if !ok {
+			annotated.Packages[i] = pkg
+			continue
+		}
+		var pkgOwners []string
+		if dir, ok := relPath(source.Dir); ok {
+			pkgOwners = rules.Owners(dir)
+		}
+		if len(pkgOwners) > 0 {
+			pkg.Properties = append([]gtr.Property(nil), pkg.Properties...)
+			for _, owner := range pkgOwners {
+				pkg.AddProperty("owner", owner)
+			}
+		}
+
+		funcs := srcloc.Funcs(source)
+		tests := make([]gtr.Test, len(pkg.Tests))
+		for j, test := range pkg.Tests {
+			owners := pkgOwners
+			if loc, ok := funcs[gtr.TopLevelName(test.Name)]; ok {
+				if rel, ok := relPath(loc.File); ok {
+					owners = rules.Owners(rel)
+				}
+			}
+			if len(owners) > 0 {
+				test.Properties = append([]gtr.Property(nil), test.Properties...)
+				for _, owner := range owners {
+					test.AddProperty("owner", owner)
+				}
+			}
+			tests[j] = test
+		}
+		if pkg.Tests != nil {
+			pkg.Tests = tests
+		}
+		annotated.Packages[i] = pkg
+	}
+	return annotated
+}
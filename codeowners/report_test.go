@@ -0,0 +1,78 @@
+package codeowners
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/srcloc"
+)
+
+func TestAnnotate(t *testing.T) {
+	root, err := ioutil.TempDir("", "codeowners")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	dir := filepath.Join(root, "pkg", "db")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"db_test.go":    "package db\n\nfunc TestQuery(t *testing.T) {}\n",
+		"slow_test.go":  "package db\n\nfunc TestSlow(t *testing.T) {}\nfunc BenchmarkSlow(b *testing.B) {}\n",
+		"other_test.go": "package db_test\n\n// func TestCommented(t *testing.T) {}\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rules, err := Parse(strings.NewReader("* @everyone\n/pkg/db/ @db-team @dba\nslow_test.go @perf-team\n"))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	src := srcloc.Sources{"example.com/pkg/db": {Dir: dir, TestFiles: []string{"db_test.go", "slow_test.go", "other_test.go"}}}
+
+	report := gtr.Report{Packages: []gtr.Package{
+		{
+			Name: "example.com/pkg/db",
+			Tests: []gtr.Test{
+				{Name: "TestQuery/select"},
+				{Name: "TestSlow"},
+				{Name: "BenchmarkSlow"},
+				{Name: "TestUnknown"},
+			},
+		},
+		{Name: "example.com/other", Tests: []gtr.Test{{Name: "TestOther"}}},
+	}}
+
+	dbOwners := []gtr.Property{{Name: "owner", Value: "@db-team"}, {Name: "owner", Value: "@dba"}}
+	perfOwners := []gtr.Property{{Name: "owner", Value: "@perf-team"}}
+	want := gtr.Report{Packages: []gtr.Package{
+		{
+			Name:       "example.com/pkg/db",
+			Properties: dbOwners,
+			Tests: []gtr.Test{
+				{Name: "TestQuery/select", Properties: dbOwners},
+				{Name: "TestSlow", Properties: perfOwners},
+				{Name: "BenchmarkSlow", Properties: perfOwners},
+				{Name: "TestUnknown", Properties: dbOwners},
+			},
+		},
+		{Name: "example.com/other", Tests: []gtr.Test{{Name: "TestOther"}}},
+	}}
+	got := Annotate(report, rules, root, src)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Annotate result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if report.Packages[0].Properties != nil {
+		t.Errorf("Annotate modified the original report")
+	}
+}
@@ -0,0 +1,141 @@
+// Package config loads the test report policies of a repository from a
+// configuration file, so that they don't need to be passed as flags in every
+// pipeline that creates a report.
+//
+// The configuration file is a JSON object, usually stored as DefaultName in
+// the root of the repository, for example:
+//
+//	{
+//	  "min_coverage": 60,
+//	  "packages": [
+//	    {"pattern": "example.com/mod/internal/db", "min_coverage": 85, "max_duration": "30s"},
+//	    {"pattern": "example.com/mod/e2e/.*", "min_coverage": 0, "max_duration": "10m"}
+//	  ],
+//	  "quarantine": [
+//	    {"pattern": "TestFlakyUpload", "reason": "https://example.com/issues/42"}
+//	  ]
+//	}
+//
+// Package patterns are regular expressions that must match the entire package
+// name, and the policies of a package are those of the first entry whose
+// pattern matches it. Quarantine patterns must match the entire test name, see
+// gtr.QuarantineRule.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// DefaultName is the name of the configuration file found by Find.
+const DefaultName = ".gotestreport.json"
+
+// Policy contains the policies defined in a configuration file.
+type Policy struct {
+	Coverage        gtr.CoveragePolicy
+	DurationBudgets []gtr.DurationBudget
+	Quarantine      []gtr.QuarantineRule
+}
+
+// file is the JSON representation of a configuration file.
+type file struct {
+	MinCoverage float64 `json:"min_coverage"`
+	Packages    []struct {
+		Pattern     string   `json:"pattern"`
+		MinCoverage *float64 `json:"min_coverage"`
+		MaxDuration string   `json:"max_duration"`
+	} `json:"packages"`
+	Quarantine []struct {
+		Pattern string `json:"pattern"`
+		Reason  string `json:"reason"`
+	} `json:"quarantine"`
+}
+
+// Parse parses the configuration file read from r.
+func Parse(r io.Reader) (Policy, error) {
+	var f file
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return Policy{}, err
+	}
+
+	if f.MinCoverage < 0 || f.MinCoverage > 100 {
+		return Policy{}, fmt.Errorf("invalid min_coverage: %v", f.MinCoverage)
+	}
+	p := Policy{Coverage: gtr.CoveragePolicy{Minimum: f.MinCoverage}}
+	for i, pkg := range f.Packages {
+		pattern, err := compile(pkg.Pattern)
+		if err != nil {
+			return Policy{}, fmt.Errorf("package %d: invalid pattern: %w", i+1, err)
+		}
+		if pkg.MinCoverage != nil {
+			if min := *pkg.MinCoverage; min < 0 || min > 100 {
+				return Policy{}, fmt.Errorf("package %d: invalid min_coverage: %v", i+1, min)
+			}
+			p.Coverage.Thresholds = append(p.Coverage.Thresholds, gtr.CoverageThreshold{Pattern: pattern, Minimum: *pkg.MinCoverage})
+		}
+		if pkg.MaxDuration != "" {
+			max, err := time.ParseDuration(pkg.MaxDuration)
+			if err != nil || max < 0 {
+				return Policy{}, fmt.Errorf("package %d: invalid max_duration: %q", i+1, pkg.MaxDuration)
+			}
+			p.DurationBudgets = append(p.DurationBudgets, gtr.DurationBudget{Pattern: pattern, Maximum: max})
+		}
+	}
+	for i, q := range f.Quarantine {
+		pattern, err := compile(q.Pattern)
+		if err != nil {
+			return Policy{}, fmt.Errorf("quarantine %d: invalid pattern: %w", i+1, err)
+		}
+		p.Quarantine = append(p.Quarantine, gtr.QuarantineRule{Pattern: pattern, Reason: q.Reason})
+	}
+	return p, nil
+}
+
+// compile compiles expr into a regular expression that must match the entire
+// name.
+func compile(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+	return regexp.Compile("^(?:" + expr + ")$")
+}
+
+// Load reads the configuration file with the given name.
+func Load(name string) (Policy, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return Policy{}, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Find returns the path of the DefaultName configuration file in dir or the
+// nearest of its parent directories, or an empty string if none of them
+// contain one.
+func Find(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		name := filepath.Join(dir, DefaultName)
+		if info, err := os.Stat(name); err == nil && !info.IsDir() {
+			return name
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
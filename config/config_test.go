@@ -0,0 +1,86 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	in := `{
+		"min_coverage": 60,
+		"packages": [
+			{"pattern": "example.com/mod/db", "min_coverage": 85, "max_duration": "30s"},
+			{"pattern": "example.com/mod/e2e/.*", "max_duration": "10m"}
+		],
+		"quarantine": [{"pattern": "TestFlaky", "reason": "issue 42"}]
+	}`
+	got, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	db := regexp.MustCompile(`^(?:example.com/mod/db)$`)
+	e2e := regexp.MustCompile(`^(?:example.com/mod/e2e/.*)$`)
+	want := Policy{
+		Coverage: gtr.CoveragePolicy{
+			Minimum:    60,
+			Thresholds: []gtr.CoverageThreshold{{Pattern: db, Minimum: 85}},
+		},
+		DurationBudgets: []gtr.DurationBudget{
+			{Pattern: db, Maximum: 30 * time.Second},
+			{Pattern: e2e, Maximum: 10 * time.Minute},
+		},
+		Quarantine: []gtr.QuarantineRule{{Pattern: regexp.MustCompile(`^(?:TestFlaky)$`), Reason: "issue 42"}},
+	}
+	if diff := cmp.Diff(want, got, cmp.Comparer(func(a, b *regexp.Regexp) bool { return a.String() == b.String() })); diff != "" {
+		t.Errorf("Parse result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		`{"min_coverage": 120}`,
+		`{"packages": [{"pattern": "("}]}`,
+		`{"packages": [{"pattern": "pkg", "min_coverage": -1}]}`,
+		`{"packages": [{"pattern": "pkg", "max_duration": "soon"}]}`,
+		`{"quarantine": [{"pattern": ""}]}`,
+		`{"unknown": true}`,
+	}
+	for _, in := range tests {
+		if _, err := Parse(strings.NewReader(in)); err == nil {
+			t.Errorf("Parse(%s) did not return an error", in)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sub := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := Find(sub); got != "" && strings.HasPrefix(got, dir) {
+		t.Errorf("Find(%q) = %q, want no configuration file", sub, got)
+	}
+	want := filepath.Join(dir, DefaultName)
+	if err := ioutil.WriteFile(want, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := Find(sub); got != want {
+		t.Errorf("Find(%q) = %q, want %q", sub, got, want)
+	}
+}
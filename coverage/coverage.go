@@ -0,0 +1,154 @@
+// Package coverage reads coverage profiles created by `go test
+// -coverprofile`.
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var regexBlock = regexp.MustCompile(`^(.+):(\d+)\.(\d+),(\d+)\.(\d+) (\d+) (\d+)$`)
+
+// Profile contains the coverage data of a coverage profile.
+type Profile struct {
+	Mode   string // set, count or atomic
+	Blocks []Block
+}
+
+// Block is a single block of code with its coverage count.
+type Block struct {
+	FileName  string // import path of the package followed by the file name
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+	NumStmt   int
+	Count     int
+}
+
+// Parse parses a coverage profile from the given io.Reader r. Profiles
+// containing the same block more than once, for example when using
+// -coverpkg, are merged into a single block.
+func Parse(r io.Reader) (*Profile, error) {
+	profile := &Profile{}
+	index := make(map[Block]int) // index of each block, without count
+	s := bufio.NewScanner(r)
+	for lineno := 1; s.Scan(); lineno++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "mode: ") {
+			profile.Mode = strings.TrimPrefix(line, "mode: ")
+			continue
+		}
+
+		block, err := parseBlock(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineno, err)
+		}
+
+		key := block
+		key.Count = 0
+		if i, ok := index[key]; ok {
+			if profile.Mode == "set" {
+				if block.Count > 0 {
+					profile.Blocks[i].Count = block.Count
+				}
+			} else {
+				profile.Blocks[i].Count += block.Count
+			}
+			continue
+		}
+		index[key] = len(profile.Blocks)
+		profile.Blocks = append(profile.Blocks, block)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+func parseBlock(line string) (Block, error) {
+	m := regexBlock.FindStringSubmatch(line)
+	if m == nil {
+		return Block{}, fmt.Errorf("invalid coverage block: %q", line)
+	}
+	var n [6]int
+	for i := range n {
+		v, err := strconv.Atoi(m[i+2])
+		if err != nil {
+			return Block{}, err
+		}
+		n[i] = v
+	}
+	return Block{
+		FileName:  m[1],
+		StartLine: n[0],
+		StartCol:  n[1],
+		EndLine:   n[2],
+		EndCol:    n[3],
+		NumStmt:   n[4],
+		Count:     n[5],
+	}, nil
+}
+
+// Files returns the names of all files in this profile, sorted by name.
+func (p *Profile) Files() []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, b := range p.Blocks {
+		if !seen[b.FileName] {
+			seen[b.FileName] = true
+			files = append(files, b.FileName)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Packages returns the import paths of all packages in this profile, sorted
+// by name.
+func (p *Profile) Packages() []string {
+	seen := make(map[string]bool)
+	var pkgs []string
+	for _, file := range p.Files() {
+		pkg := path.Dir(file)
+		if !seen[pkg] {
+			seen[pkg] = true
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs
+}
+
+// FileBlocks returns the blocks of the given file.
+func (p *Profile) FileBlocks(file string) []Block {
+	var blocks []Block
+	for _, b := range p.Blocks {
+		if b.FileName == file {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
+
+// Lines returns the number of hits of each line in the given file. A line
+// covered by multiple blocks is given the highest count of those blocks.
+func (p *Profile) Lines(file string) map[int]int {
+	lines := make(map[int]int)
+	for _, b := range p.FileBlocks(file) {
+		for l := b.StartLine; l <= b.EndLine; l++ {
+			if count, ok := lines[l]; !ok || b.Count > count {
+				lines[l] = b.Count
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,49 @@
+package coverage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testProfile = `mode: set
+example.com/pkg/a.go:3.14,5.2 1 1
+example.com/pkg/a.go:7.14,9.2 1 0
+example.com/pkg/a.go:7.14,9.2 1 1
+example.com/pkg/sub/b.go:3.14,4.2 2 0
+`
+
+func TestParse(t *testing.T) {
+	want := &Profile{
+		Mode: "set",
+		Blocks: []Block{
+			{FileName: "example.com/pkg/a.go", StartLine: 3, StartCol: 14, EndLine: 5, EndCol: 2, NumStmt: 1, Count: 1},
+			{FileName: "example.com/pkg/a.go", StartLine: 7, StartCol: 14, EndLine: 9, EndCol: 2, NumStmt: 1, Count: 1},
+			{FileName: "example.com/pkg/sub/b.go", StartLine: 3, StartCol: 14, EndLine: 4, EndCol: 2, NumStmt: 2, Count: 0},
+		},
+	}
+
+	got, err := Parse(strings.NewReader(testProfile))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Parse result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	if diff := cmp.Diff([]string{"example.com/pkg", "example.com/pkg/sub"}, got.Packages()); diff != "" {
+		t.Errorf("Packages result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	wantLines := map[int]int{3: 0, 4: 0}
+	if diff := cmp.Diff(wantLines, got.Lines("example.com/pkg/sub/b.go")); diff != "" {
+		t.Errorf("Lines result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse(strings.NewReader("mode: set\nnot a block\n")); err == nil {
+		t.Errorf("Parse did not return an error for invalid input")
+	}
+}
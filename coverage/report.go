@@ -0,0 +1,67 @@
+package coverage
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// FileCoverage returns the percentage of statements covered in the given
+// file.
+func (p *Profile) FileCoverage(file string) float64 {
+	return percent(p.FileBlocks(file))
+}
+
+// PackageCoverage returns the percentage of statements covered in the package
+// with the given import path. Files in subpackages are not included.
+func (p *Profile) PackageCoverage(pkg string) float64 {
+	var blocks []Block
+	for _, b := range p.Blocks {
+		if path.Dir(b.FileName) == pkg {
+			blocks = append(blocks, b)
+		}
+	}
+	return percent(blocks)
+}
+
+func percent(blocks []Block) float64 {
+	var total, covered int
+	for _, b := range blocks {
+		total += b.NumStmt
+		if b.Count > 0 {
+			covered += b.NumStmt
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) / float64(total) * 100
+}
+
+// AddToReport sets the coverage of each package in report r that appears in
+// profile p, replacing the coverage reported in the go test output. When
+// perFile is true, the coverage of each file in the package is also added as
+// a coverage.statements.pct.<file> property. Packages that are not in the
+// report are ignored.
+func AddToReport(r *gtr.Report, p *Profile, perFile bool) {
+	files := make(map[string][]string) // files by package
+	for _, file := range p.Files() {
+		pkg := path.Dir(file)
+		files[pkg] = append(files[pkg], file)
+	}
+
+	for i := range r.Packages {
+		pkg := &r.Packages[i]
+		if _, ok := files[pkg.Name]; !ok {
+			continue
+		}
+		pkg.Coverage = p.PackageCoverage(pkg.Name)
+		if !perFile {
+			continue
+		}
+		for _, file := range files[pkg.Name] {
+			pkg.SetProperty("coverage.statements.pct."+path.Base(file), fmt.Sprintf("%.2f", p.FileCoverage(file)))
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package coverage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAddToReport(t *testing.T) {
+	const profile = `mode: set
+example.com/pkg/a.go:3.14,5.2 3 1
+example.com/pkg/a.go:7.14,9.2 1 0
+example.com/pkg/b.go:3.14,4.2 4 0
+example.com/pkg/sub/c.go:3.14,4.2 2 1
+example.com/other/d.go:3.14,4.2 2 1
+`
+	p, err := Parse(strings.NewReader(profile))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	report := gtr.Report{Packages: []gtr.Package{
+		{Name: "example.com/pkg", Coverage: 10},
+		{Name: "example.com/pkg/sub"},
+		{Name: "example.com/none", Coverage: 20},
+	}}
+	AddToReport(&report, p, true)
+
+	want := gtr.Report{Packages: []gtr.Package{
+		{
+			Name:     "example.com/pkg",
+			Coverage: 37.5,
+			Properties: []gtr.Property{
+				{Name: "coverage.statements.pct.a.go", Value: "75.00"},
+				{Name: "coverage.statements.pct.b.go", Value: "0.00"},
+			},
+		},
+		{
+			Name:       "example.com/pkg/sub",
+			Coverage:   100,
+			Properties: []gtr.Property{{Name: "coverage.statements.pct.c.go", Value: "100.00"}},
+		},
+		{Name: "example.com/none", Coverage: 20},
+	}}
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Errorf("AddToReport result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,288 @@
+// Package ctrf defines the Common Test Report Format (CTRF) JSON schema and
+// includes convenience methods to create these reports from a gtr.Report.
+//
+// Every test, including subtests, is written as a test whose suite is the
+// name of its package. Build and runtime errors are written as failed tests
+// with the type "build" and "runtime", with a test for each compiler
+// diagnostic of a build error. Go specific details that have no
+// equivalent in CTRF, such as the subtest level and test properties, are
+// stored in the extra field of each test. See https://ctrf.io for the
+// specification.
+package ctrf
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/timefmt"
+)
+
+// Format and version of the reports created by this package.
+const (
+	ReportFormat = "CTRF"
+	SpecVersion  = "0.0.0"
+)
+
+// CTRF test statuses.
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+	StatusPending = "pending"
+	StatusOther   = "other"
+)
+
+// Report is the root of a CTRF report.
+type Report struct {
+	ReportFormat string  `json:"reportFormat"`
+	SpecVersion  string  `json:"specVersion"`
+	Results      Results `json:"results"`
+}
+
+// Results contains the tool that created the report, a summary and all tests.
+type Results struct {
+	Tool        Tool         `json:"tool"`
+	Summary     Summary      `json:"summary"`
+	Tests       []Test       `json:"tests"`
+	Environment *Environment `json:"environment,omitempty"`
+}
+
+// Tool describes the tool that created the report.
+type Tool struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// Summary contains the number of tests by status and the start and stop time
+// of the run.
+type Summary struct {
+	Tests   int   `json:"tests"`
+	Passed  int   `json:"passed"`
+	Failed  int   `json:"failed"`
+	Pending int   `json:"pending"`
+	Skipped int   `json:"skipped"`
+	Other   int   `json:"other"`
+	Start   int64 `json:"start"` // milliseconds since the Unix epoch, 0 if unknown
+	Stop    int64 `json:"stop"`  // milliseconds since the Unix epoch, 0 if unknown
+}
+
+// Test is the result of a single test.
+type Test struct {
+	Name        string       `json:"name"`
+	Status      string       `json:"status"`
+	Duration    int64        `json:"duration"` // milliseconds
+	Start       int64        `json:"start,omitempty"`
+	Stop        int64        `json:"stop,omitempty"`
+	Suite       string       `json:"suite,omitempty"`
+	Message     string       `json:"message,omitempty"`
+	Trace       string       `json:"trace,omitempty"`
+	Line        int          `json:"line,omitempty"`
+	RawStatus   string       `json:"rawStatus,omitempty"`
+	Type        string       `json:"type,omitempty"`
+	FilePath    string       `json:"filePath,omitempty"`
+	Flaky       bool         `json:"flaky,omitempty"`
+	Retries     int          `json:"retries,omitempty"`
+	Stdout      []string     `json:"stdout,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+	Extra       *Extra       `json:"extra,omitempty"`
+}
+
+// Attachment refers to a file attached to a test.
+type Attachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType,omitempty"`
+	Path        string `json:"path"`
+}
+
+// Extra contains the Go specific details of a test.
+type Extra struct {
+	Package     string            `json:"package"`
+	Level       int               `json:"level,omitempty"` // subtest level, 0 for top-level tests
+	FailureType string            `json:"failureType,omitempty"`
+	Properties  map[string]string `json:"properties,omitempty"`
+}
+
+// Environment describes the environment in which the tests ran. It's taken
+// from the properties added by gtr.EnvironmentProperties.
+type Environment struct {
+	OSPlatform string `json:"osPlatform,omitempty"`
+	BuildURL   string `json:"buildUrl,omitempty"`
+	Commit     string `json:"commit,omitempty"`
+}
+
+// CreateFromReport creates a CTRF report from the given report. The version
+// of the tool is left empty.
+func CreateFromReport(report gtr.Report) Report {
+	res := Results{Tool: Tool{Name: "go-junit-report"}, Tests: []Test{}}
+	var start, stop time.Time
+	for _, pkg := range report.Packages {
+		pkgStart := pkg.StartTime
+		if pkgStart.IsZero() {
+			pkgStart = pkg.Timestamp
+		}
+		pkgStop := pkg.EndTime
+		if pkgStop.IsZero() && !pkgStart.IsZero() {
+			pkgStop = pkgStart.Add(pkg.Duration)
+		}
+		if !pkgStart.IsZero() && (start.IsZero() || pkgStart.Before(start)) {
+			start = pkgStart
+		}
+		if pkgStop.After(stop) {
+			stop = pkgStop
+		}
+
+		for _, t := range pkg.Tests {
+			res.Tests = append(res.Tests, createTest(pkg.Name, t))
+		}
+		if pkg.BuildError.Name != "" {
+			res.Tests = append(res.Tests, buildErrorTests(pkg.Name, pkg.BuildError)...)
+		}
+		if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			res.Tests = append(res.Tests, errorTest(pkg.Name, "Runtime error", "runtime", pkg.RunError))
+		}
+		if res.Environment == nil {
+			res.Environment = environment(pkg.Properties)
+		}
+	}
+
+	for _, t := range res.Tests {
+		res.Summary.Tests++
+		switch t.Status {
+		case StatusPassed:
+			res.Summary.Passed++
+		case StatusFailed:
+			res.Summary.Failed++
+		case StatusSkipped:
+			res.Summary.Skipped++
+		case StatusPending:
+			res.Summary.Pending++
+		default:
+			res.Summary.Other++
+		}
+	}
+	res.Summary.Start, res.Summary.Stop = timefmt.UnixMillis(start), timefmt.UnixMillis(stop)
+	return Report{ReportFormat: ReportFormat, SpecVersion: SpecVersion, Results: res}
+}
+
+// Write writes the indented CTRF JSON encoding of report r to w.
+func Write(w io.Writer, r gtr.Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(CreateFromReport(r))
+}
+
+func createTest(pkgName string, t gtr.Test) Test {
+	ct := Test{
+		Name:      t.Name,
+		Status:    status(t.Result),
+		Duration:  timefmt.Millis(t.Duration),
+		Start:     timefmt.UnixMillis(t.StartTime),
+		Stop:      timefmt.UnixMillis(t.EndTime),
+		Suite:     pkgName,
+		RawStatus: t.Result.String(),
+		Type:      "unit",
+		Flaky:     t.Result == gtr.Flaky,
+		Stdout:    t.Output,
+		Extra:     &Extra{Package: pkgName, Level: t.Level, FailureType: t.FailureType},
+	}
+	if strings.HasPrefix(t.Name, "Benchmark") {
+		ct.Type = "benchmark"
+	}
+	if len(t.Attempts) > 1 {
+		ct.Retries = len(t.Attempts) - 1
+	}
+
+	switch t.Result.Base() {
+	case gtr.Fail:
+		ct.Message = t.FailureReason()
+		if t.Panic != nil {
+			ct.Trace = strings.Join(t.Panic.Stack, "\n")
+		}
+	case gtr.Skip:
+		ct.Message = t.SkipMessage
+	case gtr.Unknown:
+		ct.Message = "No test result found"
+	}
+
+	for _, a := range t.Attachments {
+		name := a.Name
+		if name == "" {
+			name = a.Path
+		}
+		ct.Attachments = append(ct.Attachments, Attachment{Name: name, ContentType: a.MIME, Path: a.Path})
+	}
+	for _, prop := range t.Properties {
+		if ct.Extra.Properties == nil {
+			ct.Extra.Properties = make(map[string]string)
+		}
+		ct.Extra.Properties[prop.Name] = prop.Value
+	}
+	return ct
+}
+
+// buildErrorTests returns a failed test for each compiler diagnostic of build
+// error e, or a single test containing its output if it has none.
+func buildErrorTests(pkgName string, e gtr.Error) []Test {
+	if len(e.Diagnostics) == 0 {
+		return []Test{errorTest(pkgName, "Build error", "build", e)}
+	}
+	var tests []Test
+	for _, d := range e.Diagnostics {
+		t := errorTest(pkgName, d.Location(), "build", e)
+		t.Message, t.Trace = d.Message, d.String()
+		t.FilePath, t.Line = d.File, d.Line
+		tests = append(tests, t)
+	}
+	return tests
+}
+
+func errorTest(pkgName, name, typ string, e gtr.Error) Test {
+	return Test{
+		Name:     name,
+		Status:   StatusFailed,
+		Duration: timefmt.Millis(e.Duration),
+		Suite:    pkgName,
+		Message:  e.FailureReason(),
+		Trace:    strings.Join(e.Output, "\n"),
+		Type:     typ,
+		Extra:    &Extra{Package: pkgName},
+	}
+}
+
+// status returns the CTRF status of result r. Flaky tests eventually passed,
+// so they're reported as passed and marked as flaky.
+func status(r gtr.Result) string {
+	switch r.Base() {
+	case gtr.Pass, gtr.Flaky:
+		return StatusPassed
+	case gtr.Fail:
+		return StatusFailed
+	case gtr.Skip:
+		return StatusSkipped
+	default:
+		return StatusOther
+	}
+}
+
+// environment returns the environment described by the given properties, or
+// nil if none of them describe the environment.
+func environment(props []gtr.Property) *Environment {
+	var env Environment
+	for _, prop := range props {
+		switch prop.Name {
+		case "go.os":
+			env.OSPlatform = prop.Value
+		case "ci.build.url":
+			env.BuildURL = prop.Value
+		case "ci.commit":
+			env.Commit = prop.Value
+		}
+	}
+	if env == (Environment{}) {
+		return nil
+	}
+	return &env
+}
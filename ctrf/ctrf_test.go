@@ -0,0 +1,190 @@
+package ctrf
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCreateFromReport(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{
+				Name:       "package/one",
+				Timestamp:  start,
+				Duration:   2 * time.Second,
+				Properties: []gtr.Property{{Name: "go.os", Value: "linux"}, {Name: "ci.commit", Value: "abc123"}},
+				Tests: []gtr.Test{
+					{
+						Name:       "TestPass",
+						Result:     gtr.Pass,
+						Duration:   10 * time.Millisecond,
+						StartTime:  start,
+						EndTime:    start.Add(10 * time.Millisecond),
+						Properties: []gtr.Property{{Name: "slow", Value: "false"}},
+					},
+					{
+						Name:           "TestFail",
+						Result:         gtr.Fail,
+						Output:         []string{"fail_test.go:10: got 1, want 2"},
+						FailureMessage: "got 1, want 2",
+						FailureType:    "testing",
+						Attachments:    []gtr.Attachment{{Path: "shot.png", MIME: "image/png"}},
+					},
+					{Name: "TestFail/sub", Result: gtr.Skip, Level: 1, SkipMessage: "not supported"},
+					{
+						Name:     "TestFlaky",
+						Result:   gtr.Flaky,
+						Attempts: []gtr.TestAttempt{{Result: gtr.Fail}, {Result: gtr.Pass}},
+					},
+					{Name: "TestPanic", Result: gtr.Fail, Panic: &gtr.PanicInfo{Message: "oops", Stack: []string{"goroutine 1"}}},
+					{Name: "TestUnknown", Result: gtr.Unknown},
+				},
+			},
+			{
+				Name:       "package/two",
+				BuildError: gtr.Error{Name: "package/two", Cause: "[build failed]", Output: []string{"undefined: x"}},
+			},
+			{
+				Name: "package/three",
+				BuildError: gtr.Error{
+					Name:        "package/three",
+					Cause:       "[build failed]",
+					Output:      []string{"three.go:3:5: undefined: y"},
+					Diagnostics: []gtr.Diagnostic{{File: "three.go", Line: 3, Column: 5, Message: "undefined: y"}},
+				},
+			},
+		},
+	}
+
+	want := Report{
+		ReportFormat: "CTRF",
+		SpecVersion:  "0.0.0",
+		Results: Results{
+			Tool:    Tool{Name: "go-junit-report"},
+			Summary: Summary{Tests: 8, Passed: 2, Failed: 4, Skipped: 1, Other: 1, Start: 1640995200000, Stop: 1640995202000},
+			Tests: []Test{
+				{
+					Name:      "TestPass",
+					Status:    "passed",
+					Duration:  10,
+					Start:     1640995200000,
+					Stop:      1640995200010,
+					Suite:     "package/one",
+					RawStatus: "PASS",
+					Type:      "unit",
+					Extra:     &Extra{Package: "package/one", Properties: map[string]string{"slow": "false"}},
+				},
+				{
+					Name:        "TestFail",
+					Status:      "failed",
+					Suite:       "package/one",
+					Message:     "got 1, want 2",
+					RawStatus:   "FAIL",
+					Type:        "unit",
+					Stdout:      []string{"fail_test.go:10: got 1, want 2"},
+					Attachments: []Attachment{{Name: "shot.png", ContentType: "image/png", Path: "shot.png"}},
+					Extra:       &Extra{Package: "package/one", FailureType: "testing"},
+				},
+				{
+					Name:      "TestFail/sub",
+					Status:    "skipped",
+					Suite:     "package/one",
+					Message:   "not supported",
+					RawStatus: "SKIP",
+					Type:      "unit",
+					Extra:     &Extra{Package: "package/one", Level: 1},
+				},
+				{
+					Name:      "TestFlaky",
+					Status:    "passed",
+					Suite:     "package/one",
+					RawStatus: "FLAKY",
+					Type:      "unit",
+					Flaky:     true,
+					Retries:   1,
+					Extra:     &Extra{Package: "package/one"},
+				},
+				{
+					Name:      "TestPanic",
+					Status:    "failed",
+					Suite:     "package/one",
+					Message:   "Panic: oops",
+					Trace:     "goroutine 1",
+					RawStatus: "FAIL",
+					Type:      "unit",
+					Extra:     &Extra{Package: "package/one"},
+				},
+				{
+					Name:      "TestUnknown",
+					Status:    "other",
+					Suite:     "package/one",
+					Message:   "No test result found",
+					RawStatus: "UNKNOWN",
+					Type:      "unit",
+					Extra:     &Extra{Package: "package/one"},
+				},
+				{
+					Name:    "Build error",
+					Status:  "failed",
+					Suite:   "package/two",
+					Message: "[build failed]",
+					Trace:   "undefined: x",
+					Type:    "build",
+					Extra:   &Extra{Package: "package/two"},
+				},
+				{
+					Name:     "three.go:3:5",
+					Status:   "failed",
+					Suite:    "package/three",
+					Message:  "undefined: y",
+					Trace:    "three.go:3:5: undefined: y",
+					Line:     3,
+					Type:     "build",
+					FilePath: "three.go",
+					Extra:    &Extra{Package: "package/three"},
+				},
+			},
+			Environment: &Environment{OSPlatform: "linux", Commit: "abc123"},
+		},
+	}
+
+	got := CreateFromReport(report)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CreateFromReport result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{{Name: "package/one"}}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("error unmarshaling output: %v", err)
+	}
+	want := map[string]interface{}{
+		"reportFormat": "CTRF",
+		"specVersion":  "0.0.0",
+		"results": map[string]interface{}{
+			"tool": map[string]interface{}{"name": "go-junit-report"},
+			"summary": map[string]interface{}{
+				"tests": 0.0, "passed": 0.0, "failed": 0.0, "pending": 0.0, "skipped": 0.0, "other": 0.0, "start": 0.0, "stop": 0.0,
+			},
+			"tests": []interface{}{},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Write output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,135 @@
+// Package evergreen creates the results.json file read by the attach.results
+// command of Evergreen CI from a gtr.Report.
+//
+// Every test, including subtests, is written as a result whose test file is
+// the name of its package followed by the name of the test. Build and runtime
+// errors are written as failed results. Start and end times are written in
+// seconds since the Unix epoch. See the documentation of the attach.results
+// command at https://github.com/evergreen-ci/evergreen for a description of
+// the format.
+package evergreen
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// Evergreen test statuses.
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// Results is the root of a results.json file.
+type Results struct {
+	Results []Result `json:"results"`
+}
+
+// Result is the result of a single test.
+type Result struct {
+	TestFile string  `json:"test_file"`
+	Status   string  `json:"status"`
+	ExitCode int     `json:"exit_code"`
+	Start    float64 `json:"start"` // seconds since the Unix epoch
+	End      float64 `json:"end"`   // seconds since the Unix epoch
+	LogRaw   string  `json:"log_raw,omitempty"`
+	LineNum  int     `json:"line_num,omitempty"`
+}
+
+// CreateFromReport creates the Evergreen results of the given report. Tests
+// without a start time are assumed to have started when their package
+// started. When neither is known, the start time is 0 and the end time is the
+// duration of the test.
+func CreateFromReport(report gtr.Report) Results {
+	res := Results{Results: []Result{}}
+	for _, pkg := range report.Packages {
+		pkgStart := pkg.StartTime
+		if pkgStart.IsZero() {
+			pkgStart = pkg.Timestamp
+		}
+		for _, t := range pkg.Tests {
+			res.Results = append(res.Results, createResult(pkg.Name, t, pkgStart))
+		}
+		pkgEnd := pkg.EndTime
+		if pkgEnd.IsZero() && !pkgStart.IsZero() {
+			pkgEnd = pkgStart.Add(pkg.Duration)
+		}
+		if pkg.BuildError.Name != "" {
+			res.Results = append(res.Results, errorResult(pkg.Name, "Build error", pkg.BuildError, pkgStart, pkgEnd))
+		}
+		if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			res.Results = append(res.Results, errorResult(pkg.Name, "Runtime error", pkg.RunError, pkgStart, pkgEnd))
+		}
+	}
+	return res
+}
+
+// Write writes the indented results.json encoding of report r to w.
+func Write(w io.Writer, r gtr.Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(CreateFromReport(r))
+}
+
+func createResult(pkgName string, t gtr.Test, pkgStart time.Time) Result {
+	start := t.StartTime
+	if start.IsZero() {
+		start = pkgStart
+	}
+	end := t.EndTime
+	if end.IsZero() && !start.IsZero() {
+		end = start.Add(t.Duration)
+	}
+
+	r := Result{
+		TestFile: pkgName + " " + t.Name,
+		Start:    seconds(start),
+		End:      seconds(end),
+		LogRaw:   strings.Join(t.Output, "\n"),
+		LineNum:  t.Line,
+	}
+	if end.IsZero() {
+		r.End = t.Duration.Seconds()
+	}
+
+	switch t.Result.Base() {
+	case gtr.Pass, gtr.Flaky:
+		r.Status = StatusPass
+	case gtr.Skip:
+		r.Status = StatusSkip
+	default:
+		r.Status, r.ExitCode = StatusFail, 1
+	}
+	return r
+}
+
+func errorResult(pkgName, name string, e gtr.Error, start, end time.Time) Result {
+	r := Result{
+		TestFile: pkgName + " " + name,
+		Status:   StatusFail,
+		ExitCode: 1,
+		Start:    seconds(start),
+		End:      seconds(end),
+		LogRaw:   strings.Join(e.Output, "\n"),
+	}
+	if end.IsZero() {
+		r.End = e.Duration.Seconds()
+	}
+	if r.LogRaw == "" {
+		r.LogRaw = e.Cause
+	}
+	return r
+}
+
+// seconds returns t in seconds since the Unix epoch, or 0 if t is zero.
+func seconds(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixNano()) / float64(time.Second)
+}
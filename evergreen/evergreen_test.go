@@ -0,0 +1,44 @@
+package evergreen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCreateFromReport(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := gtr.Report{Packages: []gtr.Package{
+		{
+			Name:      "package/one",
+			StartTime: start,
+			Duration:  3 * time.Second,
+			Tests: []gtr.Test{
+				{Name: "TestOne", Result: gtr.Pass, Duration: time.Second},
+				{Name: "TestOne/sub", Result: gtr.Fail, StartTime: start.Add(time.Second), Duration: 500 * time.Millisecond, Line: 12, Output: []string{"one_test.go:12: boom", "more"}},
+				{Name: "TestTwo", Result: gtr.Skip},
+				{Name: "TestFlaky", Result: gtr.Flaky},
+			},
+		},
+		{
+			Name:       "package/two",
+			Duration:   time.Second,
+			BuildError: gtr.Error{Name: "package/two", Duration: time.Second, Cause: "compilation failed"},
+		},
+	}}
+
+	want := Results{Results: []Result{
+		{TestFile: "package/one TestOne", Status: StatusPass, Start: 1640995200, End: 1640995201},
+		{TestFile: "package/one TestOne/sub", Status: StatusFail, ExitCode: 1, Start: 1640995201, End: 1640995201.5, LogRaw: "one_test.go:12: boom\nmore", LineNum: 12},
+		{TestFile: "package/one TestTwo", Status: StatusSkip, Start: 1640995200, End: 1640995200},
+		{TestFile: "package/one TestFlaky", Status: StatusPass, Start: 1640995200, End: 1640995200},
+		{TestFile: "package/two Build error", Status: StatusFail, ExitCode: 1, End: 1, LogRaw: "compilation failed"},
+	}}
+	got := CreateFromReport(report)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CreateFromReport incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
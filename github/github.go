@@ -0,0 +1,123 @@
+// Package github writes reports as GitHub Actions workflow commands.
+//
+// An error annotation is written for each failed test and for each build or
+// runtime error, which GitHub shows inline in the diff of a pull request when
+// the annotation refers to a changed file. The file and line of an annotation
+// are taken from the first file:line reference in the output of a test. Note
+// that go test prints file names relative to the package directory, so these
+// are only resolved to the correct file when the tests ran in the root of the
+// repository. Tests whose source file is known, see gtr.Test.File, are
+// annotated in that file instead, at the line of the first reference to it or
+// else at the line of the test function. Build errors produce an annotation
+// for each compiler diagnostic.
+package github
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/escape"
+)
+
+var regexFileLine = regexp.MustCompile(`^\s*([^\s:]+\.go):(\d+)(?::(\d+))?: (.*)$`)
+
+// Write writes an error workflow command for each failure in report r to
+// writer w.
+func Write(w io.Writer, r gtr.Report) error {
+	bw := bufio.NewWriter(w)
+	for _, pkg := range r.Packages {
+		for _, test := range pkg.Tests {
+			if test.Result.Base() != gtr.Fail {
+				continue
+			}
+			a := annotation{title: pkg.Name + "." + test.Name, message: strings.Join(test.Output, "\n")}
+			for _, line := range test.Output {
+				if matches := regexFileLine.FindStringSubmatch(line); matches != nil {
+					if test.File == "" || path.Base(matches[1]) == path.Base(test.File) {
+						a.file, a.line, a.col = matches[1], matches[2], matches[3]
+						break
+					}
+				}
+			}
+			if test.File != "" {
+				if a.file == "" && test.Line > 0 {
+					a.line = strconv.Itoa(test.Line)
+				}
+				a.file = test.File
+			}
+			if strings.TrimSpace(a.message) == "" {
+				a.message = test.Name + " failed"
+			}
+			a.write(bw)
+		}
+
+		if pkg.BuildError.Name != "" {
+			writeErrorAnnotations(bw, pkg.Name+": build error", pkg.BuildError)
+		}
+		if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			writeErrorAnnotations(bw, pkg.Name+": runtime error", pkg.RunError)
+		}
+	}
+	return bw.Flush()
+}
+
+// writeErrorAnnotations writes an annotation for each compiler diagnostic of
+// e, or if it has none, for each line of the output of e that refers to a
+// file. A single annotation containing all output is written if none of the
+// lines do.
+func writeErrorAnnotations(w io.Writer, title string, e gtr.Error) {
+	if len(e.Diagnostics) > 0 {
+		for _, d := range e.Diagnostics {
+			a := annotation{title: title, file: d.File, line: strconv.Itoa(d.Line), message: d.Message}
+			if d.Column > 0 {
+				a.col = strconv.Itoa(d.Column)
+			}
+			a.write(w)
+		}
+		return
+	}
+
+	found := false
+	for _, line := range e.Output {
+		if matches := regexFileLine.FindStringSubmatch(line); matches != nil {
+			annotation{title, matches[1], matches[2], matches[3], matches[4]}.write(w)
+			found = true
+		}
+	}
+	if !found {
+		message := strings.Join(e.Output, "\n")
+		if strings.TrimSpace(message) == "" {
+			message = e.Cause
+		}
+		annotation{title: title, message: message}.write(w)
+	}
+}
+
+type annotation struct {
+	title     string
+	file      string
+	line, col string
+	message   string
+}
+
+// write writes annotation a as an error workflow command.
+func (a annotation) write(w io.Writer) {
+	var props []string
+	if a.file != "" {
+		props = append(props, "file="+escape.GitHubProperty(a.file))
+		if a.line != "" {
+			props = append(props, "line="+a.line)
+		}
+		if a.col != "" {
+			props = append(props, "col="+a.col)
+		}
+	}
+	props = append(props, "title="+escape.GitHubProperty(a.title))
+	fmt.Fprintf(w, "::error %s::%s\n", strings.Join(props, ","), escape.GitHubData(a.message))
+}
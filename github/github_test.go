@@ -0,0 +1,65 @@
+package github
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrite(t *testing.T) {
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{
+				Name: "package/one",
+				Tests: []gtr.Test{
+					{Name: "TestPass", Result: gtr.Pass},
+					{Name: "TestFail", Result: gtr.Fail, Output: []string{"    one_test.go:10: got 1, want 2", "    100% wrong"}},
+					{Name: "TestNoOutput", Result: gtr.Fail},
+					{Name: "TestLocated", Result: gtr.Fail, File: "one/one_test.go", Line: 20, Output: []string{"    helper_test.go:5: setup", "    one_test.go:24: failed"}},
+					{Name: "TestLocatedNoOutput", Result: gtr.Fail, File: "one/one_test.go", Line: 30},
+				},
+			},
+			{
+				Name: "package/two",
+				BuildError: gtr.Error{
+					Name:   "package/two",
+					Cause:  "[build failed]",
+					Output: []string{"# package/two", "two/two.go:5:2: undefined: x", "two/two.go:6:2: undefined: y"},
+				},
+			},
+			{
+				Name: "package/four",
+				BuildError: gtr.Error{
+					Name:        "package/four",
+					Output:      []string{"four.go:3: error"},
+					Diagnostics: []gtr.Diagnostic{{File: "four/four.go", Line: 3, Message: "cannot use x\n\thave int"}},
+				},
+			},
+			{
+				Name:     "package/three",
+				RunError: gtr.Error{Name: "package/three", Output: []string{"panic: boom"}},
+			},
+		},
+	}
+
+	want := `::error file=one_test.go,line=10,title=package/one.TestFail::    one_test.go:10: got 1, want 2%0A    100%25 wrong
+::error title=package/one.TestNoOutput::TestNoOutput failed
+::error file=one/one_test.go,line=24,title=package/one.TestLocated::    helper_test.go:5: setup%0A    one_test.go:24: failed
+::error file=one/one_test.go,line=30,title=package/one.TestLocatedNoOutput::TestLocatedNoOutput failed
+::error file=two/two.go,line=5,col=2,title=package/two%3A build error::undefined: x
+::error file=two/two.go,line=6,col=2,title=package/two%3A build error::undefined: y
+::error file=four/four.go,line=3,title=package/four%3A build error::cannot use x%0A	have int
+::error title=package/three%3A runtime error::panic: boom
+`
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Write output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,156 @@
+package gtr
+
+import (
+	"sync"
+	"time"
+)
+
+// Aggregator incrementally merges reports, for example partial reports
+// streamed from multiple shards of a test run. Reports are merged as soon as
+// they are added, so only the merged result is kept in memory. An Aggregator
+// is safe for concurrent use. The zero value is ready to use.
+//
+// Reports are merged like Merge does, except that when a test with the same
+// name already exists in a package, it is considered to be a retry and the
+// test is replaced by the one that was added last.
+type Aggregator struct {
+	mu sync.Mutex
+	m  *merger
+}
+
+// Add merges report r into the aggregated report.
+func (a *Aggregator) Add(r Report) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.m == nil {
+		a.m = newMerger(replaceTest)
+	}
+	a.m.add(r)
+}
+
+// Result returns the aggregated report of all reports added so far.
+func (a *Aggregator) Result() Report {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.m == nil {
+		return Report{}
+	}
+	r := a.m.merged
+	r.Packages = nil
+	for _, pkg := range a.m.merged.Packages {
+		r.Packages = append(r.Packages, copyPackage(pkg))
+	}
+	if r.RunMeta != nil {
+		meta := *r.RunMeta
+		r.RunMeta = &meta
+	}
+	return r
+}
+
+// mergePackage merges package from into package into. Durations and output
+// are combined and tests from package from are merged into tests with the
+// same name in package into using mergeTest.
+func mergePackage(into *Package, from Package, mergeTest func(into *Test, from Test)) {
+	into.Timestamp = earliest(into.Timestamp, from.Timestamp)
+	into.StartTime = earliest(into.StartTime, from.StartTime)
+	into.EndTime = latest(into.EndTime, from.EndTime)
+	into.Duration += from.Duration
+	into.BuildDuration += from.BuildDuration
+	if from.MaxParallel > into.MaxParallel {
+		into.MaxParallel = from.MaxParallel
+	}
+	into.NoTestFiles = into.NoTestFiles && from.NoTestFiles
+	into.Cached = into.Cached && from.Cached
+	if from.Coverage > 0 {
+		into.Coverage = from.Coverage
+	}
+	into.Output = append(into.Output, from.Output...)
+	into.Stderr = append(into.Stderr, from.Stderr...)
+	for _, prop := range from.Properties {
+		into.SetProperty(prop.Name, prop.Value)
+	}
+	into.Attachments = append(into.Attachments, from.Attachments...)
+
+	for _, test := range from.Tests {
+		i := findTestByName(into.Tests, test.Name)
+		if i < 0 {
+			into.Tests = append(into.Tests, test)
+			continue
+		}
+		mergeTest(&into.Tests[i], test)
+	}
+
+	if from.BuildError.Name != "" {
+		into.BuildError = from.BuildError
+	}
+	if from.RunError.Name != "" || from.RunError.Kind != "" {
+		into.RunError = from.RunError
+	}
+}
+
+// earliest returns the earliest of the non-zero times a and b.
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+// latest returns the latest of the non-zero times a and b.
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// replaceTest replaces test into by test from.
+func replaceTest(into *Test, from Test) {
+	*into = from
+}
+
+// findTestByName returns the index of the last test with the given name, or
+// -1 if no such test exists.
+func findTestByName(tests []Test, name string) int {
+	for i := len(tests) - 1; i >= 0; i-- {
+		if tests[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// copyPackage returns a copy of pkg that doesn't share any of its slices with
+// pkg.
+func copyPackage(pkg Package) Package {
+	pkg.Output = copyStrings(pkg.Output)
+	pkg.Stderr = copyStrings(pkg.Stderr)
+	pkg.Properties = copyProperties(pkg.Properties)
+	pkg.Attachments = copyAttachments(pkg.Attachments)
+	if pkg.Tests != nil {
+		tests := make([]Test, len(pkg.Tests))
+		copy(tests, pkg.Tests)
+		pkg.Tests = tests
+	}
+	return pkg
+}
+
+func copyProperties(props []Property) []Property {
+	if props == nil {
+		return nil
+	}
+	c := make([]Property, len(props))
+	copy(c, props)
+	return c
+}
+
+func copyAttachments(attachments []Attachment) []Attachment {
+	if attachments == nil {
+		return nil
+	}
+	c := make([]Attachment, len(attachments))
+	copy(c, attachments)
+	return c
+}
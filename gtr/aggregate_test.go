@@ -0,0 +1,144 @@
+package gtr
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAggregator(t *testing.T) {
+	var a Aggregator
+	a.Add(Report{
+		Packages: []Package{
+			{
+				Name:     "package/one",
+				Duration: time.Second,
+				Tests: []Test{
+					{Name: "TestA", Result: Pass},
+					{Name: "TestB", Result: Fail},
+				},
+			},
+		},
+	})
+	a.Add(Report{
+		Packages: []Package{
+			{
+				Name:       "package/one",
+				Duration:   2 * time.Second,
+				Properties: []Property{{Name: "shard", Value: "2"}},
+				Tests:      []Test{{Name: "TestB", Result: Pass}},
+			},
+			{
+				Name:  "package/two",
+				Tests: []Test{{Name: "TestC", Result: Skip}},
+			},
+		},
+	})
+
+	want := Report{
+		Packages: []Package{
+			{
+				Name:       "package/one",
+				Duration:   3 * time.Second,
+				Properties: []Property{{Name: "shard", Value: "2"}},
+				Tests: []Test{
+					{Name: "TestA", Result: Pass},
+					{Name: "TestB", Result: Pass},
+				},
+			},
+			{
+				Name:  "package/two",
+				Tests: []Test{{Name: "TestC", Result: Skip}},
+			},
+		},
+	}
+
+	got := a.Result()
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Aggregator result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestAggregatorMatchesMerge(t *testing.T) {
+	reports := []Report{
+		{
+			Hostname:   "runner-1",
+			ShardIndex: 0,
+			ShardCount: 2,
+			RunMeta:    &RunMeta{ExitCode: 1},
+			Packages: []Package{
+				{Name: "package/one", Tests: []Test{{Name: "TestA", Result: Pass}}},
+			},
+		},
+		{
+			Hostname:   "runner-2",
+			ShardIndex: 1,
+			ShardCount: 2,
+			RunMeta:    &RunMeta{ExitCode: -1, Signal: "killed"},
+			Aborted:    true,
+			Packages: []Package{
+				{Name: "package/one", Tests: []Test{{Name: "TestB", Result: Fail}}},
+				{Name: "package/two", Tests: []Test{{Name: "TestC", Result: Pass}}},
+			},
+		},
+	}
+
+	var a Aggregator
+	for _, r := range reports {
+		a.Add(r)
+	}
+	if diff := cmp.Diff(Merge(reports...), a.Result()); diff != "" {
+		t.Errorf("Aggregator result differs from Merge, diff (-merge +aggregator):\n%s\n", diff)
+	}
+}
+
+func TestAggregatorConcurrent(t *testing.T) {
+	const shards, packages = 8, 4
+
+	var a Aggregator
+	var wg sync.WaitGroup
+	for shard := 0; shard < shards; shard++ {
+		wg.Add(1)
+		go func(shard int) {
+			defer wg.Done()
+			for p := 0; p < packages; p++ {
+				a.Add(Report{
+					Packages: []Package{
+						{
+							Name:     fmt.Sprintf("package/%d", p),
+							Duration: time.Second,
+							Tests:    []Test{{Name: fmt.Sprintf("Test%d", shard), Result: Pass}},
+						},
+					},
+				})
+			}
+		}(shard)
+	}
+	wg.Wait()
+
+	got := a.Result()
+	if len(got.Packages) != packages {
+		t.Fatalf("Aggregator result has %d packages, want %d", len(got.Packages), packages)
+	}
+	for _, pkg := range got.Packages {
+		if pkg.Duration != shards*time.Second {
+			t.Errorf("package %s has duration %v, want %v", pkg.Name, pkg.Duration, shards*time.Second)
+		}
+		var names []string
+		for _, test := range pkg.Tests {
+			names = append(names, test.Name)
+		}
+		sort.Strings(names)
+		var want []string
+		for shard := 0; shard < shards; shard++ {
+			want = append(want, fmt.Sprintf("Test%d", shard))
+		}
+		if diff := cmp.Diff(want, names); diff != "" {
+			t.Errorf("package %s has incorrect tests, diff (-want +got):\n%s\n", pkg.Name, diff)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package gtr
+
+// GroupAttempts returns a copy of report r in which tests that appear more
+// than once in the same package, for example when using `go test -count` or
+// when tests are retried, are combined into a single test. The Attempts field
+// of the combined test contains the results of each attempt in the order they
+// appeared. The result of the combined test is Flaky if at least one attempt
+// failed and another passed, otherwise it's the result of the last attempt.
+// Durations are summed and output is concatenated.
+func GroupAttempts(r Report) Report {
+	return Dedup(r, DedupAttempts)
+}
+
+// addAttempts adds test from to test t as one more attempt, or as all of its
+// attempts if it already has any.
+func addAttempts(t *Test, from Test) {
+	if len(t.Attempts) == 0 {
+		t.Attempts = []TestAttempt{newAttempt(*t)}
+	}
+	t.Attempts = t.Attempts[:len(t.Attempts):len(t.Attempts)]
+	if len(from.Attempts) > 0 {
+		t.Attempts = append(t.Attempts, from.Attempts...)
+	} else {
+		t.Attempts = append(t.Attempts, newAttempt(from))
+	}
+	t.Duration += from.Duration
+	t.RunDuration += from.RunDuration
+	t.WallDuration += from.WallDuration
+	t.StartTime = earliest(t.StartTime, from.StartTime)
+	t.EndTime = latest(t.EndTime, from.EndTime)
+	t.Output = append(copyStrings(t.Output), from.Output...)
+	t.Stderr = append(copyStrings(t.Stderr), from.Stderr...)
+	t.Attachments = append(copyAttachments(t.Attachments), from.Attachments...)
+	t.Result = attemptsResult(t.Attempts)
+	t.SkipMessage = from.SkipMessage
+	t.FailureMessage, t.FailureType = from.FailureMessage, from.FailureType
+}
+
+func newAttempt(t Test) TestAttempt {
+	return TestAttempt{Result: t.Result, Duration: t.Duration, Output: t.Output}
+}
+
+// attemptsResult returns the combined result of the given attempts.
+func attemptsResult(attempts []TestAttempt) Result {
+	var passed, failed bool
+	for _, a := range attempts {
+		passed = passed || a.Result.Base() == Pass
+		failed = failed || a.Result.Base() == Fail
+	}
+	if passed && failed {
+		return Flaky
+	}
+	return attempts[len(attempts)-1].Result
+}
@@ -0,0 +1,53 @@
+package gtr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGroupAttempts(t *testing.T) {
+	report := Report{Packages: []Package{{
+		Name: "package/name",
+		Tests: []Test{
+			{ID: 1, Name: "TestFlaky", Result: Fail, Duration: 1 * time.Second, Output: []string{"attempt 1"}},
+			{ID: 2, Name: "TestStable", Result: Pass},
+			{ID: 3, Name: "TestFlaky", Result: Pass, Duration: 2 * time.Second, Output: []string{"attempt 2"}},
+			{ID: 4, Name: "TestBroken", Result: Fail},
+			{ID: 5, Name: "TestBroken", Result: Fail},
+		},
+	}}}
+
+	want := Report{Packages: []Package{{
+		Name: "package/name",
+		Tests: []Test{
+			{
+				ID:       1,
+				Name:     "TestFlaky",
+				Result:   Flaky,
+				Duration: 3 * time.Second,
+				Output:   []string{"attempt 1", "attempt 2"},
+				Attempts: []TestAttempt{
+					{Result: Fail, Duration: 1 * time.Second, Output: []string{"attempt 1"}},
+					{Result: Pass, Duration: 2 * time.Second, Output: []string{"attempt 2"}},
+				},
+			},
+			{ID: 2, Name: "TestStable", Result: Pass},
+			{
+				ID:       4,
+				Name:     "TestBroken",
+				Result:   Fail,
+				Attempts: []TestAttempt{{Result: Fail}, {Result: Fail}},
+			},
+		},
+	}}}
+
+	got := GroupAttempts(report)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GroupAttempts result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if len(report.Packages[0].Tests) != 5 {
+		t.Errorf("GroupAttempts modified the original report")
+	}
+}
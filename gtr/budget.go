@@ -0,0 +1,69 @@
+package gtr
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// DurationBudgetTestName is the name of the test added by
+// EnforceDurationBudgets to packages that took longer than their budget.
+const DurationBudgetTestName = "[duration]"
+
+// DurationBudget is the maximum duration of the packages whose name matches
+// Pattern.
+type DurationBudget struct {
+	Pattern *regexp.Regexp
+	Maximum time.Duration
+}
+
+// EnforceDurationBudgets returns a copy of report r in which every package
+// that took longer than the maximum of the first budget whose pattern matches
+// its name is given a failed test named DurationBudgetTestName, so that the
+// report is no longer successful. The failure message of the test contains the
+// duration and the maximum, and every checked package is marked with a
+// "duration.budget" property. A maximum of 0 means the duration of the
+// matching packages isn't checked. Packages that failed to build are not
+// checked.
+func EnforceDurationBudgets(r Report, budgets []DurationBudget) Report {
+	if len(budgets) == 0 {
+		return r
+	}
+	enforced := r
+	enforced.Packages = make([]Package, len(r.Packages))
+	for i, pkg := range r.Packages {
+		max, ok := durationBudget(budgets, pkg.Name)
+		if ok && max > 0 && pkg.BuildError.Name == "" {
+			pkg.Properties = copyProperties(pkg.Properties)
+			pkg.SetProperty("duration.budget", max.String())
+			if pkg.Duration > max {
+				id := 0
+				for _, t := range pkg.Tests {
+					if t.ID > id {
+						id = t.ID
+					}
+				}
+				pkg.Tests = append(pkg.Tests[:len(pkg.Tests):len(pkg.Tests)], Test{
+					ID:             id + 1,
+					Name:           DurationBudgetTestName,
+					Result:         Fail,
+					FailureMessage: fmt.Sprintf("duration %v exceeds the budget of %v", pkg.Duration, max),
+					FailureType:    "duration",
+				})
+			}
+		}
+		enforced.Packages[i] = pkg
+	}
+	return enforced
+}
+
+// durationBudget returns the maximum duration of the package with the given
+// name, and false if none of the budgets match it.
+func durationBudget(budgets []DurationBudget, pkg string) (time.Duration, bool) {
+	for _, b := range budgets {
+		if b.Pattern.MatchString(pkg) {
+			return b.Maximum, true
+		}
+	}
+	return 0, false
+}
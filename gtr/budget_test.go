@@ -0,0 +1,47 @@
+package gtr
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEnforceDurationBudgets(t *testing.T) {
+	budgets := []DurationBudget{
+		{Pattern: regexp.MustCompile(`^package/slow$`), Maximum: 0},
+		{Pattern: regexp.MustCompile(`^package/.*$`), Maximum: time.Minute},
+	}
+
+	pass := []Test{{ID: 1, Name: "TestA", Result: Pass}}
+	report := Report{Packages: []Package{
+		{Name: "package/fast", Duration: time.Second, Tests: pass},
+		{Name: "package/over", Duration: 2 * time.Minute, Tests: pass},
+		{Name: "package/slow", Duration: time.Hour, Tests: pass},
+		{Name: "other/pkg", Duration: time.Hour, Tests: pass},
+		{Name: "package/build", Duration: 2 * time.Minute, BuildError: Error{Name: "package/build"}},
+	}}
+	got := EnforceDurationBudgets(report, budgets)
+
+	budget := []Property{{Name: "duration.budget", Value: "1m0s"}}
+	want := Report{Packages: []Package{
+		{Name: "package/fast", Duration: time.Second, Tests: pass, Properties: budget},
+		{Name: "package/over", Duration: 2 * time.Minute, Properties: budget, Tests: append(pass[:1:1], Test{
+			ID:             2,
+			Name:           DurationBudgetTestName,
+			Result:         Fail,
+			FailureMessage: "duration 2m0s exceeds the budget of 1m0s",
+			FailureType:    "duration",
+		})},
+		{Name: "package/slow", Duration: time.Hour, Tests: pass},
+		{Name: "other/pkg", Duration: time.Hour, Tests: pass},
+		{Name: "package/build", Duration: 2 * time.Minute, BuildError: Error{Name: "package/build"}},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("EnforceDurationBudgets result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if len(report.Packages[1].Tests) != 1 || report.Packages[0].Properties != nil {
+		t.Errorf("EnforceDurationBudgets modified its input report")
+	}
+}
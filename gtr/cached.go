@@ -0,0 +1,71 @@
+package gtr
+
+import "fmt"
+
+// CachedMode determines how the durations of packages whose results were
+// cached by go test, which it reports as "(cached)", are reported, see
+// HandleCached.
+type CachedMode string
+
+const (
+	// CachedKeep keeps the durations of cached packages and tests as they
+	// appear in the output, which is the default. For cached packages these
+	// are the durations of the run whose results were cached.
+	CachedKeep CachedMode = "keep"
+
+	// CachedZero sets the durations of cached packages and tests to zero.
+	CachedZero CachedMode = "zero"
+
+	// CachedAnnotate keeps the durations, and adds a "cached" property to
+	// cached packages and their tests.
+	CachedAnnotate CachedMode = "annotate"
+)
+
+// ParseCachedMode returns the CachedMode with the given name. An empty name
+// returns CachedKeep.
+func ParseCachedMode(name string) (CachedMode, error) {
+	switch m := CachedMode(name); m {
+	case "":
+		return CachedKeep, nil
+	case CachedKeep, CachedZero, CachedAnnotate:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown cached mode: %s", name)
+	}
+}
+
+// HandleCached returns a copy of report r in which the durations of cached
+// packages and their tests are handled according to mode.
+func HandleCached(r Report, mode CachedMode) Report {
+	if mode == "" || mode == CachedKeep {
+		return r
+	}
+
+	handled := r
+	handled.Packages = make([]Package, len(r.Packages))
+	for i, pkg := range r.Packages {
+		if !pkg.Cached {
+			handled.Packages[i] = pkg
+			continue
+		}
+		pkg.Tests = append([]Test(nil), pkg.Tests...)
+		switch mode {
+		case CachedZero:
+			pkg.Duration = 0
+			for j := range pkg.Tests {
+				pkg.Tests[j].Duration = 0
+				pkg.Tests[j].RunDuration = 0
+				pkg.Tests[j].WallDuration = 0
+			}
+		case CachedAnnotate:
+			pkg.Properties = copyProperties(pkg.Properties)
+			pkg.SetProperty("cached", "true")
+			for j := range pkg.Tests {
+				pkg.Tests[j].Properties = copyProperties(pkg.Tests[j].Properties)
+				pkg.Tests[j].SetProperty("cached", "true")
+			}
+		}
+		handled.Packages[i] = pkg
+	}
+	return handled
+}
@@ -0,0 +1,45 @@
+package gtr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHandleCached(t *testing.T) {
+	report := Report{Packages: []Package{
+		{Name: "package/cached", Cached: true, Duration: time.Second, Tests: []Test{{Name: "TestOne", Duration: time.Second}}},
+		{Name: "package/run", Duration: time.Second, Tests: []Test{{Name: "TestTwo", Duration: time.Second}}},
+	}}
+
+	tests := []struct {
+		mode CachedMode
+		want []Package
+	}{
+		{CachedKeep, report.Packages},
+		{CachedZero, []Package{
+			{Name: "package/cached", Cached: true, Tests: []Test{{Name: "TestOne"}}},
+			report.Packages[1],
+		}},
+		{CachedAnnotate, []Package{
+			{
+				Name:       "package/cached",
+				Cached:     true,
+				Duration:   time.Second,
+				Properties: []Property{{Name: "cached", Value: "true"}},
+				Tests:      []Test{{Name: "TestOne", Duration: time.Second, Properties: []Property{{Name: "cached", Value: "true"}}}},
+			},
+			report.Packages[1],
+		}},
+	}
+	for _, test := range tests {
+		got := HandleCached(report, test.mode)
+		if diff := cmp.Diff(test.want, got.Packages); diff != "" {
+			t.Errorf("HandleCached(%q) incorrect, diff (-want +got):\n%s\n", test.mode, diff)
+		}
+	}
+	if report.Packages[0].Tests[0].Duration != time.Second || report.Packages[0].Properties != nil {
+		t.Errorf("HandleCached modified its input report")
+	}
+}
@@ -0,0 +1,114 @@
+package gtr
+
+import "fmt"
+
+// DedupStrategy determines how tests that appear more than once in the same
+// package are combined, see Dedup and MergeOptions.
+type DedupStrategy string
+
+const (
+	// DedupCombine combines duplicate tests into a single test whose
+	// duration is the sum of all durations and whose result is the worst
+	// result, like Merge does by default.
+	DedupCombine DedupStrategy = "combine"
+
+	// DedupKeepFirst keeps the first of the duplicate tests.
+	DedupKeepFirst DedupStrategy = "keep-first"
+
+	// DedupKeepLast keeps the last of the duplicate tests, for example the
+	// result of a rerun.
+	DedupKeepLast DedupStrategy = "keep-last"
+
+	// DedupKeepWorst keeps the duplicate test with the worst result, the
+	// first of them if more than one has that result. Results are ordered
+	// like in Merge.
+	DedupKeepWorst DedupStrategy = "keep-worst"
+
+	// DedupAttempts combines duplicate tests into a single test with one
+	// attempt for each of them, like GroupAttempts does.
+	DedupAttempts DedupStrategy = "keep-all-as-attempts"
+)
+
+// ParseDedupStrategy returns the DedupStrategy with the given name. An empty
+// name returns an empty DedupStrategy, which keeps duplicate tests.
+func ParseDedupStrategy(name string) (DedupStrategy, error) {
+	switch s := DedupStrategy(name); s {
+	case "", DedupCombine, DedupKeepFirst, DedupKeepLast, DedupKeepWorst, DedupAttempts:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unknown dedup strategy: %s", name)
+	}
+}
+
+// mergeFunc returns the function that merges a duplicate test from into the
+// test into using strategy s.
+func (s DedupStrategy) mergeFunc() func(into *Test, from Test) {
+	switch s {
+	case DedupKeepFirst:
+		return func(into *Test, from Test) {}
+	case DedupKeepLast:
+		return func(into *Test, from Test) { *into = from }
+	case DedupKeepWorst:
+		return func(into *Test, from Test) {
+			if resultSeverity(from.Result) > resultSeverity(into.Result) {
+				*into = from
+			}
+		}
+	case DedupAttempts:
+		return addAttempts
+	default:
+		return combineTests
+	}
+}
+
+// Dedup returns a copy of report r in which tests that appear more than once
+// in the same package, for example when using `go test -count`, are combined
+// into a single test using strategy s. The combined test takes the place of
+// the first of the duplicate tests. An empty strategy returns r unchanged.
+func Dedup(r Report, s DedupStrategy) Report {
+	if s == "" {
+		return r
+	}
+	merge := s.mergeFunc()
+	deduped := r
+	deduped.Packages = make([]Package, len(r.Packages))
+	for i, pkg := range r.Packages {
+		var tests []Test
+		index := make(map[string]int) // index in tests by name
+		for _, test := range pkg.Tests {
+			j, ok := index[test.Name]
+			if !ok {
+				index[test.Name] = len(tests)
+				tests = append(tests, test)
+				continue
+			}
+			merge(&tests[j], test)
+		}
+		pkg.Tests = tests
+		deduped.Packages[i] = pkg
+	}
+	return deduped
+}
+
+// MergeOptions configures how Merge combines reports.
+type MergeOptions struct {
+	// Duplicates is the strategy used to combine tests that appear more
+	// than once. When it's empty, tests that appear in more than one report
+	// are combined using DedupCombine and tests that appear more than once
+	// in the same report are kept. Otherwise, duplicate tests are combined
+	// using Duplicates regardless of the report they appear in.
+	Duplicates DedupStrategy
+}
+
+// Merge combines the given reports into a single report like the Merge
+// function, but uses the strategy of o to combine duplicate tests.
+func (o MergeOptions) Merge(reports ...Report) Report {
+	if o.Duplicates == "" {
+		return merge(reports, combineTests)
+	}
+	deduped := make([]Report, len(reports))
+	for i, r := range reports {
+		deduped[i] = Dedup(r, o.Duplicates)
+	}
+	return merge(deduped, o.Duplicates.mergeFunc())
+}
@@ -0,0 +1,100 @@
+package gtr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDedupStrategy(t *testing.T) {
+	for _, name := range []string{"", "combine", "keep-first", "keep-last", "keep-worst", "keep-all-as-attempts"} {
+		got, err := ParseDedupStrategy(name)
+		if err != nil || string(got) != name {
+			t.Errorf("ParseDedupStrategy(%q) = %q, %v", name, got, err)
+		}
+	}
+	if _, err := ParseDedupStrategy("keep-best"); err == nil {
+		t.Errorf("ParseDedupStrategy did not return an error for an unknown strategy")
+	}
+}
+
+func TestDedup(t *testing.T) {
+	report := Report{Packages: []Package{{Name: "package/name", Tests: []Test{
+		{Name: "TestA", Result: Fail, Duration: 1 * time.Second, FailureMessage: "first"},
+		{Name: "TestB", Result: Pass, Duration: 5 * time.Second},
+		{Name: "TestA", Result: Pass, Duration: 2 * time.Second},
+		{Name: "TestA", Result: Skip, Duration: 3 * time.Second, SkipMessage: "last"},
+	}}}}
+	testB := Test{Name: "TestB", Result: Pass, Duration: 5 * time.Second}
+
+	tests := []struct {
+		strategy DedupStrategy
+		want     []Test
+	}{
+		{"", report.Packages[0].Tests},
+		{DedupCombine, []Test{{Name: "TestA", Result: Fail, Duration: 6 * time.Second, FailureMessage: "first", SkipMessage: "last"}, testB}},
+		{DedupKeepFirst, []Test{report.Packages[0].Tests[0], testB}},
+		{DedupKeepLast, []Test{report.Packages[0].Tests[3], testB}},
+		{DedupKeepWorst, []Test{report.Packages[0].Tests[0], testB}},
+		{DedupAttempts, []Test{{
+			Name:        "TestA",
+			Result:      Flaky,
+			Duration:    6 * time.Second,
+			SkipMessage: "last",
+			Attempts: []TestAttempt{
+				{Result: Fail, Duration: 1 * time.Second},
+				{Result: Pass, Duration: 2 * time.Second},
+				{Result: Skip, Duration: 3 * time.Second},
+			},
+		}, testB}},
+	}
+	for _, test := range tests {
+		t.Run(string(test.strategy), func(t *testing.T) {
+			got := Dedup(report, test.strategy)
+			if diff := cmp.Diff(test.want, got.Packages[0].Tests); diff != "" {
+				t.Errorf("Dedup result incorrect, diff (-want +got):\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestMergeOptions(t *testing.T) {
+	first := Report{Packages: []Package{{Name: "package/name", Tests: []Test{
+		{Name: "TestA", Result: Fail, Duration: time.Second},
+		{Name: "TestA", Result: Pass, Duration: time.Second},
+	}}}}
+	rerun := Report{Packages: []Package{{Name: "package/name", Tests: []Test{
+		{Name: "TestA", Result: Pass, Duration: 2 * time.Second},
+	}}}}
+
+	tests := []struct {
+		strategy DedupStrategy
+		want     []Test
+	}{
+		{"", []Test{
+			{Name: "TestA", Result: Fail, Duration: time.Second},
+			{Name: "TestA", Result: Pass, Duration: 3 * time.Second},
+		}},
+		{DedupKeepLast, []Test{{Name: "TestA", Result: Pass, Duration: 2 * time.Second}}},
+		{DedupKeepWorst, []Test{{Name: "TestA", Result: Fail, Duration: time.Second}}},
+		{DedupAttempts, []Test{{
+			Name:     "TestA",
+			Result:   Flaky,
+			Duration: 4 * time.Second,
+			Attempts: []TestAttempt{
+				{Result: Fail, Duration: time.Second},
+				{Result: Pass, Duration: time.Second},
+				{Result: Pass, Duration: 2 * time.Second},
+			},
+		}}},
+	}
+	for _, test := range tests {
+		t.Run(string(test.strategy), func(t *testing.T) {
+			got := MergeOptions{Duplicates: test.strategy}.Merge(first, rerun)
+			if diff := cmp.Diff(test.want, got.Packages[0].Tests); diff != "" {
+				t.Errorf("Merge result incorrect, diff (-want +got):\n%s\n", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package gtr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var regexDiagnostic = regexp.MustCompile(`^(?:vet: )?([^\s:]+\.go):(\d+)(?::(\d+))?: (.*)$`)
+
+// Diagnostic is a single error reported by the compiler or vet, such as
+// "./main.go:10:5: undefined: x".
+type Diagnostic struct {
+	File    string // file name as printed by the go command
+	Line    int
+	Column  int    // zero if unknown
+	Message string // the message, including any indented lines that follow it
+}
+
+// Location returns the location of the diagnostic in the file:line:column
+// format, or file:line if the column is unknown.
+func (d Diagnostic) Location() string {
+	if d.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+	}
+	return fmt.Sprintf("%s:%d", d.File, d.Line)
+}
+
+// String returns the diagnostic in the file:line:column: message format
+// printed by the go command.
+func (d Diagnostic) String() string {
+	return d.Location() + ": " + d.Message
+}
+
+// ParseDiagnostics returns the diagnostics in the given build output. Indented
+// lines following a diagnostic, such as the "have" and "want" lines of a type
+// error, are added to its message. All other lines are ignored.
+func ParseDiagnostics(output []string) []Diagnostic {
+	var diags []Diagnostic
+	cont := false
+	for _, line := range output {
+		if matches := regexDiagnostic.FindStringSubmatch(line); matches != nil {
+			d := Diagnostic{File: matches[1], Message: matches[4]}
+			d.Line, _ = strconv.Atoi(matches[2])
+			d.Column, _ = strconv.Atoi(matches[3])
+			diags = append(diags, d)
+			cont = true
+		} else if cont && (strings.HasPrefix(line, "\t") || strings.HasPrefix(line, " ")) {
+			diags[len(diags)-1].Message += "\n" + line
+		} else {
+			cont = false
+		}
+	}
+	return diags
+}
+
+// DiagnosticsByFile returns the files that the compiler diagnostics of error e
+// refer to, in the order in which they first appear, and the diagnostics of
+// each of these files.
+func (e Error) DiagnosticsByFile() ([]string, map[string][]Diagnostic) {
+	var files []string
+	byFile := make(map[string][]Diagnostic)
+	for _, d := range e.Diagnostics {
+		if _, ok := byFile[d.File]; !ok {
+			files = append(files, d.File)
+		}
+		byFile[d.File] = append(byFile[d.File], d)
+	}
+	return files, byFile
+}
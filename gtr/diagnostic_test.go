@@ -0,0 +1,57 @@
+package gtr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDiagnostics(t *testing.T) {
+	output := []string{
+		"# package/name",
+		"./main.go:10:5: undefined: x",
+		"./main.go:12:9: cannot use s (variable of type string) as int value in return statement",
+		"vet: ./util.go:3:2: unusedresult",
+		"failing_test.go:15: cannot find package \"other/package\" in any of:",
+		"\t/path/vendor (vendor tree)",
+		"\t/path/go/root (from $GOROOT)",
+		"too many errors",
+		"\tnot a continuation",
+	}
+	want := []Diagnostic{
+		{File: "./main.go", Line: 10, Column: 5, Message: "undefined: x"},
+		{File: "./main.go", Line: 12, Column: 9, Message: "cannot use s (variable of type string) as int value in return statement"},
+		{File: "./util.go", Line: 3, Column: 2, Message: "unusedresult"},
+		{File: "failing_test.go", Line: 15, Message: "cannot find package \"other/package\" in any of:\n\t/path/vendor (vendor tree)\n\t/path/go/root (from $GOROOT)"},
+	}
+	got := ParseDiagnostics(output)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseDiagnostics result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	if got, want := want[0].String(), "./main.go:10:5: undefined: x"; got != want {
+		t.Errorf("Diagnostic.String() = %q, want %q", got, want)
+	}
+	if got, want := want[3].Location(), "failing_test.go:15"; got != want {
+		t.Errorf("Diagnostic.Location() = %q, want %q", got, want)
+	}
+}
+
+func TestDiagnosticsByFile(t *testing.T) {
+	e := Error{Diagnostics: []Diagnostic{
+		{File: "b.go", Line: 1, Message: "one"},
+		{File: "a.go", Line: 2, Message: "two"},
+		{File: "b.go", Line: 3, Message: "three"},
+	}}
+	files, byFile := e.DiagnosticsByFile()
+	if diff := cmp.Diff([]string{"b.go", "a.go"}, files); diff != "" {
+		t.Errorf("DiagnosticsByFile files incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	want := map[string][]Diagnostic{
+		"a.go": {{File: "a.go", Line: 2, Message: "two"}},
+		"b.go": {{File: "b.go", Line: 1, Message: "one"}, {File: "b.go", Line: 3, Message: "three"}},
+	}
+	if diff := cmp.Diff(want, byFile); diff != "" {
+		t.Errorf("DiagnosticsByFile diagnostics incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,144 @@
+package gtr
+
+import "time"
+
+const (
+	// DiffDurationRatio is the fraction by which the duration of a test must
+	// change to be included in ReportDiff.Durations.
+	DiffDurationRatio = 0.5
+
+	// DiffMinDuration is the minimum absolute duration change of a test to
+	// be included in ReportDiff.Durations, so that tests whose duration is
+	// dominated by noise aren't reported.
+	DiffMinDuration = 100 * time.Millisecond
+)
+
+// ReportDiff contains the differences between two reports, see Diff.
+type ReportDiff struct {
+	Added     []TestRef        // tests that only appear in the new report
+	Removed   []TestRef        // tests that only appear in the old report
+	Results   []ResultChange   // tests whose result changed
+	Durations []DurationChange // tests whose duration changed significantly
+}
+
+// Empty returns true if d doesn't contain any differences.
+func (d ReportDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Results) == 0 && len(d.Durations) == 0
+}
+
+// Regressions returns the result changes in d of tests that failed in the new
+// report but not in the old report.
+func (d ReportDiff) Regressions() []ResultChange {
+	var changes []ResultChange
+	for _, c := range d.Results {
+		if c.Regressed() {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// ResultChange is a change in the result of a test.
+type ResultChange struct {
+	TestRef
+	Before, After Result
+}
+
+// Regressed returns true if the test failed after the change but not before.
+func (c ResultChange) Regressed() bool {
+	return isFailure(c.After) && !isFailure(c.Before)
+}
+
+// Fixed returns true if the test failed before the change but not after.
+func (c ResultChange) Fixed() bool {
+	return isFailure(c.Before) && !isFailure(c.After)
+}
+
+// DurationChange is a change in the duration of a test.
+type DurationChange struct {
+	TestRef
+	Before, After time.Duration
+}
+
+// Ratio returns the relative change in duration, e.g. 0.5 if a test became 50%
+// slower. It returns 0 if the duration before the change is unknown.
+func (c DurationChange) Ratio() float64 {
+	if c.Before <= 0 {
+		return 0
+	}
+	return float64(c.After-c.Before) / float64(c.Before)
+}
+
+// Diff compares report b to report a, for example a pull request build to a
+// baseline build of the main branch. Tests are identified by their package
+// and name. Flaky tests are considered to be passing, so a change between pass
+// and flaky is not reported. The duration of a test that ran in both reports
+// has changed significantly if it changed by more than DiffDurationRatio and
+// by at least DiffMinDuration. Tests are listed in the order they appear in b,
+// followed by tests that were removed in the order they appear in a.
+func Diff(a, b Report) ReportDiff {
+	old := make(map[TestRef]Test)
+	var oldRefs []TestRef
+	for _, pkg := range a.Packages {
+		for _, t := range pkg.Tests {
+			ref := TestRef{Package: pkg.Name, Test: t.Name}
+			if _, ok := old[ref]; !ok {
+				oldRefs = append(oldRefs, ref)
+			}
+			old[ref] = t
+		}
+	}
+
+	var d ReportDiff
+	seen := make(map[TestRef]bool)
+	for _, pkg := range b.Packages {
+		for _, t := range pkg.Tests {
+			ref := TestRef{Package: pkg.Name, Test: t.Name}
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+
+			prev, ok := old[ref]
+			if !ok {
+				d.Added = append(d.Added, ref)
+				continue
+			}
+			if diffResult(prev.Result) != diffResult(t.Result) {
+				d.Results = append(d.Results, ResultChange{ref, prev.Result, t.Result})
+			}
+			if significantChange(prev.Duration, t.Duration) {
+				d.Durations = append(d.Durations, DurationChange{ref, prev.Duration, t.Duration})
+			}
+		}
+	}
+	for _, ref := range oldRefs {
+		if !seen[ref] {
+			d.Removed = append(d.Removed, ref)
+		}
+	}
+	return d
+}
+
+// diffResult returns the result r is compared as in Diff.
+func diffResult(r Result) Result {
+	if r == Flaky {
+		return Pass
+	}
+	return r
+}
+
+func significantChange(before, after time.Duration) bool {
+	delta := after - before
+	if delta < 0 {
+		delta = -delta
+	}
+	if delta < DiffMinDuration {
+		return false
+	}
+	return before <= 0 || float64(delta) > DiffDurationRatio*float64(before)
+}
+
+func isFailure(r Result) bool {
+	return r == Fail || r == Unknown
+}
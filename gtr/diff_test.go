@@ -0,0 +1,63 @@
+package gtr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiff(t *testing.T) {
+	a := Report{Packages: []Package{
+		{Name: "package/one", Tests: []Test{
+			{Name: "TestPassFail", Result: Pass},
+			{Name: "TestFailPass", Result: Fail},
+			{Name: "TestFlaky", Result: Pass},
+			{Name: "TestSlower", Result: Pass, Duration: time.Second},
+			{Name: "TestNoise", Result: Pass, Duration: 10 * time.Millisecond},
+			{Name: "TestRemoved", Result: Pass},
+		}},
+		{Name: "package/two", Tests: []Test{
+			{Name: "TestRemoved", Result: Skip},
+		}},
+	}}
+	b := Report{Packages: []Package{
+		{Name: "package/one", Tests: []Test{
+			{Name: "TestAdded", Result: Pass},
+			{Name: "TestPassFail", Result: Fail},
+			{Name: "TestFailPass", Result: Pass},
+			{Name: "TestFlaky", Result: Flaky},
+			{Name: "TestSlower", Result: Pass, Duration: 2 * time.Second},
+			{Name: "TestNoise", Result: Pass, Duration: 50 * time.Millisecond},
+		}},
+	}}
+
+	want := ReportDiff{
+		Added:   []TestRef{{"package/one", "TestAdded"}},
+		Removed: []TestRef{{"package/one", "TestRemoved"}, {"package/two", "TestRemoved"}},
+		Results: []ResultChange{
+			{TestRef{"package/one", "TestPassFail"}, Pass, Fail},
+			{TestRef{"package/one", "TestFailPass"}, Fail, Pass},
+		},
+		Durations: []DurationChange{
+			{TestRef{"package/one", "TestSlower"}, time.Second, 2 * time.Second},
+		},
+	}
+	got := Diff(a, b)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Diff result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	if diff := cmp.Diff(want.Results[:1], got.Regressions()); diff != "" {
+		t.Errorf("Regressions result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if !got.Results[1].Fixed() {
+		t.Errorf("Fixed() = false for %v, want true", got.Results[1])
+	}
+	if ratio := got.Durations[0].Ratio(); ratio != 1 {
+		t.Errorf("Ratio() = %v, want 1", ratio)
+	}
+	if !Diff(a, a).Empty() {
+		t.Errorf("Diff(a, a).Empty() = false, want true")
+	}
+}
@@ -0,0 +1,127 @@
+package gtr
+
+import (
+	"runtime"
+	"strconv"
+)
+
+// EnvironmentProperties returns properties describing the environment the
+// tests ran in, using getenv to look up environment variables. The go.version
+// property is the Go version go-junit-report was built with, which is usually
+// but not necessarily the version used to run the tests. The GOOS and GOARCH
+// environment variables are used for the go.os and go.arch properties,
+// defaulting to the current platform, and CGO_ENABLED for the go.cgo property
+// if set. The ci.build.url and ci.commit properties are taken from the
+// variables set by common CI systems, such as GitHub Actions, GitLab CI and
+// Jenkins, and are omitted when unknown.
+func EnvironmentProperties(getenv func(string) string) []Property {
+	props := []Property{
+		{Name: "go.version", Value: runtime.Version()},
+		{Name: "go.os", Value: firstEnv(getenv, "GOOS")},
+		{Name: "go.arch", Value: firstEnv(getenv, "GOARCH")},
+	}
+	if props[1].Value == "" {
+		props[1].Value = runtime.GOOS
+	}
+	if props[2].Value == "" {
+		props[2].Value = runtime.GOARCH
+	}
+	if cgo := getenv("CGO_ENABLED"); cgo != "" {
+		props = append(props, Property{Name: "go.cgo", Value: cgo})
+	}
+
+	url := firstEnv(getenv, "BUILD_URL", "CI_JOB_URL", "CIRCLE_BUILD_URL", "BUILDKITE_BUILD_URL", "TRAVIS_BUILD_WEB_URL")
+	if url == "" && getenv("GITHUB_RUN_ID") != "" {
+		url = getenv("GITHUB_SERVER_URL") + "/" + getenv("GITHUB_REPOSITORY") + "/actions/runs/" + getenv("GITHUB_RUN_ID")
+	}
+	if url != "" {
+		props = append(props, Property{Name: "ci.build.url", Value: url})
+	}
+	if commit := firstEnv(getenv, "GITHUB_SHA", "CI_COMMIT_SHA", "GIT_COMMIT", "CIRCLE_SHA1", "BUILDKITE_COMMIT", "TRAVIS_COMMIT"); commit != "" {
+		props = append(props, Property{Name: "ci.commit", Value: commit})
+	}
+	return props
+}
+
+// EnvironmentVariables returns an env.NAME property for each of the named
+// environment variables that is set, using getenv to look them up. Only
+// variables that are explicitly named are recorded, to avoid leaking secrets
+// into reports.
+func EnvironmentVariables(getenv func(string) string, names ...string) []Property {
+	var props []Property
+	for _, name := range names {
+		if v := getenv(name); v != "" {
+			props = append(props, Property{Name: "env." + name, Value: v})
+		}
+	}
+	return props
+}
+
+// shardVariables are the environment variables that CI systems use for the
+// index and total number of parallel jobs, and whether their index is
+// one-based.
+var shardVariables = []struct {
+	index, count string
+	oneBased     bool
+}{
+	{"TEST_SHARD_INDEX", "TEST_TOTAL_SHARDS", false},                  // Bazel
+	{"CIRCLE_NODE_INDEX", "CIRCLE_NODE_TOTAL", false},                 // CircleCI
+	{"BUILDKITE_PARALLEL_JOB", "BUILDKITE_PARALLEL_JOB_COUNT", false}, // Buildkite
+	{"CI_NODE_INDEX", "CI_NODE_TOTAL", true},                          // GitLab CI
+	{"SEMAPHORE_JOB_INDEX", "SEMAPHORE_JOB_COUNT", true},              // Semaphore
+}
+
+// EnvironmentShard returns the zero-based index and the number of shards of
+// the current CI job, using getenv to look up the environment variables set
+// by CI systems that run jobs in parallel, such as CircleCI, Buildkite and
+// GitLab CI. It returns false if the job isn't one of several parallel jobs.
+func EnvironmentShard(getenv func(string) string) (index, count int, ok bool) {
+	for _, v := range shardVariables {
+		i, err1 := strconv.Atoi(getenv(v.index))
+		n, err2 := strconv.Atoi(getenv(v.count))
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if v.oneBased {
+			i--
+		}
+		if n > 0 && i >= 0 && i < n {
+			return i, n, true
+		}
+	}
+	return 0, 0, false
+}
+
+// WithEnvironment returns a copy of report r in which the given properties,
+// such as those returned by EnvironmentProperties, are added to every
+// package. Properties that a package already has are kept.
+func WithEnvironment(r Report, props ...Property) Report {
+	packages := make([]Package, len(r.Packages))
+	for i, pkg := range r.Packages {
+		have := make(map[string]bool)
+		for _, prop := range pkg.Properties {
+			have[prop.Name] = true
+		}
+		pkg.Properties = copyProperties(pkg.Properties)
+		for _, prop := range props {
+			if !have[prop.Name] {
+				pkg.Properties = append(pkg.Properties, prop)
+				have[prop.Name] = true
+			}
+		}
+		packages[i] = pkg
+	}
+	r.Packages = packages
+	return r
+}
+
+// firstEnv returns the value of the first of the given environment variables
+// that is set.
+func firstEnv(getenv func(string) string, names ...string) string {
+	for _, name := range names {
+		if v := getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
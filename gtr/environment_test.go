@@ -0,0 +1,111 @@
+package gtr
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEnvironmentProperties(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want []Property
+	}{
+		{
+			"empty",
+			nil,
+			[]Property{{"go.version", runtime.Version()}, {"go.os", runtime.GOOS}, {"go.arch", runtime.GOARCH}},
+		},
+		{
+			"github",
+			map[string]string{
+				"GOOS":              "plan9",
+				"CGO_ENABLED":       "0",
+				"GITHUB_SERVER_URL": "https://github.com",
+				"GITHUB_REPOSITORY": "owner/repo",
+				"GITHUB_RUN_ID":     "42",
+				"GITHUB_SHA":        "abc123",
+			},
+			[]Property{
+				{"go.version", runtime.Version()},
+				{"go.os", "plan9"},
+				{"go.arch", runtime.GOARCH},
+				{"go.cgo", "0"},
+				{"ci.build.url", "https://github.com/owner/repo/actions/runs/42"},
+				{"ci.commit", "abc123"},
+			},
+		},
+		{
+			"jenkins",
+			map[string]string{"GOARCH": "arm64", "BUILD_URL": "https://ci.example.com/job/1/", "GIT_COMMIT": "def456"},
+			[]Property{
+				{"go.version", runtime.Version()},
+				{"go.os", runtime.GOOS},
+				{"go.arch", "arm64"},
+				{"ci.build.url", "https://ci.example.com/job/1/"},
+				{"ci.commit", "def456"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := EnvironmentProperties(func(name string) string { return test.env[name] })
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("EnvironmentProperties result incorrect, diff (-want +got):\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestEnvironmentVariables(t *testing.T) {
+	env := map[string]string{"RUNNER_NAME": "runner-1", "SECRET_TOKEN": "hunter2"}
+	got := EnvironmentVariables(func(name string) string { return env[name] }, "RUNNER_NAME", "UNSET")
+	want := []Property{{"env.RUNNER_NAME", "runner-1"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("EnvironmentVariables result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWithEnvironment(t *testing.T) {
+	report := Report{Packages: []Package{
+		{Name: "package/one"},
+		{Name: "package/two", Properties: []Property{{"go.os", "plan9"}}},
+	}}
+	got := WithEnvironment(report, Property{"go.os", "linux"}, Property{"host.name", "ci"})
+	want := Report{Packages: []Package{
+		{Name: "package/one", Properties: []Property{{"go.os", "linux"}, {"host.name", "ci"}}},
+		{Name: "package/two", Properties: []Property{{"go.os", "plan9"}, {"host.name", "ci"}}},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("WithEnvironment result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if len(report.Packages[1].Properties) != 1 || report.Packages[0].Properties != nil {
+		t.Errorf("WithEnvironment modified its input: %v", report.Packages)
+	}
+}
+
+func TestEnvironmentShard(t *testing.T) {
+	tests := []struct {
+		name                 string
+		env                  map[string]string
+		wantIndex, wantCount int
+		wantOK               bool
+	}{
+		{"none", nil, 0, 0, false},
+		{"circleci", map[string]string{"CIRCLE_NODE_INDEX": "0", "CIRCLE_NODE_TOTAL": "4"}, 0, 4, true},
+		{"gitlab", map[string]string{"CI_NODE_INDEX": "2", "CI_NODE_TOTAL": "3"}, 1, 3, true},
+		{"invalid index", map[string]string{"CI_NODE_INDEX": "0", "CI_NODE_TOTAL": "3"}, 0, 0, false},
+		{"incomplete", map[string]string{"BUILDKITE_PARALLEL_JOB": "1"}, 0, 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			index, count, ok := EnvironmentShard(func(name string) string { return test.env[name] })
+			if index != test.wantIndex || count != test.wantCount || ok != test.wantOK {
+				t.Errorf("EnvironmentShard() = %d, %d, %t, want %d, %d, %t", index, count, ok, test.wantIndex, test.wantCount, test.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package gtr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NotRunTestName is the name of the test added by AddMissingTests to expected
+// packages that don't appear in a report, when no tests of the package are
+// expected by name.
+const NotRunTestName = "[not run]"
+
+// MissingTestMode determines how AddMissingTests reports the expected tests
+// that don't appear in a report.
+type MissingTestMode string
+
+const (
+	// MissingTestFail reports missing tests as failed tests, which is the
+	// default.
+	MissingTestFail MissingTestMode = "fail"
+
+	// MissingTestSkip reports missing tests as skipped tests.
+	MissingTestSkip MissingTestMode = "skip"
+)
+
+// ParseMissingTestMode returns the MissingTestMode with the given name. An
+// empty name returns MissingTestFail.
+func ParseMissingTestMode(name string) (MissingTestMode, error) {
+	switch m := MissingTestMode(name); m {
+	case "":
+		return MissingTestFail, nil
+	case MissingTestFail, MissingTestSkip:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown missing test mode: %s", name)
+	}
+}
+
+// ParseExpectedTests parses a manifest of expected tests from the given
+// io.Reader r. Each line contains a package name, optionally followed by
+// whitespace and the name of a test in the package, for example:
+//
+//	example.com/module/db TestQuery
+//	example.com/module/db TestQuery/empty
+//	example.com/module/cmd
+//
+// A line containing only a package name expects the package to appear in the
+// report, without expecting any of its tests by name. Empty lines and lines
+// starting with # are ignored. This format is the same as that of the failed
+// tests written by rerun.WriteFailures.
+func ParseExpectedTests(r io.Reader) ([]TestRef, error) {
+	var expected []TestRef
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("invalid expected test on line %d: want a package and an optional test name", n)
+		}
+		ref := TestRef{Package: fields[0]}
+		if len(fields) == 2 {
+			ref.Test = fields[1]
+		}
+		expected = append(expected, ref)
+	}
+	return expected, s.Err()
+}
+
+// AddMissingTests returns a copy of report r in which every expected test that
+// doesn't appear in the report is added as a failed or skipped test according
+// to mode, so that a build failure or -run filter that silently prevented
+// tests from running is noticed. Expected packages that don't appear in the
+// report are added as well, containing their missing tests or a single
+// NotRunTestName test. Every added test is marked with a "missing" property.
+func AddMissingTests(r Report, expected []TestRef, mode MissingTestMode) Report {
+	if len(expected) == 0 {
+		return r
+	}
+	if mode == "" {
+		mode = MissingTestFail
+	}
+
+	seen := make(map[TestRef]bool)
+	for _, pkg := range r.Packages {
+		seen[TestRef{Package: pkg.Name}] = true
+		for _, t := range pkg.Tests {
+			seen[TestRef{pkg.Name, t.Name}] = true
+		}
+	}
+
+	var pkgOrder []string
+	missing := make(map[string][]string) // names of the missing tests by package
+	for _, ref := range expected {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		if _, ok := missing[ref.Package]; !ok {
+			pkgOrder = append(pkgOrder, ref.Package)
+		}
+		name := ref.Test
+		if name == "" {
+			name = NotRunTestName
+		}
+		missing[ref.Package] = append(missing[ref.Package], name)
+	}
+	if len(missing) == 0 {
+		return r
+	}
+
+	added := r
+	added.Packages = make([]Package, len(r.Packages), len(r.Packages)+len(missing))
+	for i, pkg := range r.Packages {
+		if names := missing[pkg.Name]; len(names) > 0 {
+			pkg.Tests = appendMissingTests(pkg.Tests, names, mode)
+			delete(missing, pkg.Name)
+		}
+		added.Packages[i] = pkg
+	}
+	for _, name := range pkgOrder {
+		if names, ok := missing[name]; ok {
+			added.Packages = append(added.Packages, Package{Name: name, Tests: appendMissingTests(nil, names, mode)})
+		}
+	}
+	return added
+}
+
+// appendMissingTests returns a copy of tests with a test for each of the
+// given names appended to it. The NotRunTestName test is only added to
+// packages without other tests.
+func appendMissingTests(tests []Test, names []string, mode MissingTestMode) []Test {
+	id := 0
+	for _, t := range tests {
+		if t.ID > id {
+			id = t.ID
+		}
+	}
+	tests = tests[:len(tests):len(tests)]
+	for _, name := range names {
+		if name == NotRunTestName && (len(tests) > 0 || len(names) > 1) {
+			continue
+		}
+		id++
+		t := Test{ID: id, Name: name, Result: Fail, FailureMessage: "test did not run", FailureType: "missing"}
+		if mode == MissingTestSkip {
+			t = Test{ID: id, Name: name, Result: Skip, SkipMessage: "test did not run"}
+		}
+		t.SetProperty("missing", "true")
+		tests = append(tests, t)
+	}
+	return tests
+}
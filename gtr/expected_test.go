@@ -0,0 +1,69 @@
+package gtr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseExpectedTests(t *testing.T) {
+	in := "# expected tests\npackage/one TestOne\npackage/one TestOne/sub\n\npackage/two\n"
+	want := []TestRef{{"package/one", "TestOne"}, {"package/one", "TestOne/sub"}, {"package/two", ""}}
+	got, err := ParseExpectedTests(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("ParseExpectedTests error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseExpectedTests incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	if _, err := ParseExpectedTests(strings.NewReader("package/one TestOne extra\n")); err == nil {
+		t.Errorf("ParseExpectedTests did not return an error for a line with 3 fields")
+	}
+}
+
+func TestAddMissingTests(t *testing.T) {
+	report := Report{Packages: []Package{
+		{Name: "package/one", Tests: []Test{{ID: 1, Name: "TestOne", Result: Pass}}},
+		{Name: "package/build", BuildError: Error{Name: "package/build"}},
+	}}
+	expected := []TestRef{
+		{"package/one", "TestOne"},
+		{"package/one", "TestTwo"},
+		{"package/one", ""},
+		{"package/build", "TestBuild"},
+		{"package/gone", ""},
+		{"package/filtered", ""},
+		{"package/filtered", "TestFiltered"},
+	}
+	missing := func(id int, name string) Test {
+		return Test{
+			ID:             id,
+			Name:           name,
+			Result:         Fail,
+			FailureMessage: "test did not run",
+			FailureType:    "missing",
+			Properties:     []Property{{Name: "missing", Value: "true"}},
+		}
+	}
+
+	want := []Package{
+		{Name: "package/one", Tests: []Test{{ID: 1, Name: "TestOne", Result: Pass}, missing(2, "TestTwo")}},
+		{Name: "package/build", BuildError: Error{Name: "package/build"}, Tests: []Test{missing(1, "TestBuild")}},
+		{Name: "package/gone", Tests: []Test{missing(1, NotRunTestName)}},
+		{Name: "package/filtered", Tests: []Test{missing(1, "TestFiltered")}},
+	}
+	got := AddMissingTests(report, expected, MissingTestFail)
+	if diff := cmp.Diff(want, got.Packages); diff != "" {
+		t.Errorf("AddMissingTests incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if len(report.Packages[0].Tests) != 1 {
+		t.Errorf("AddMissingTests modified its input report")
+	}
+
+	skipped := AddMissingTests(report, []TestRef{{"package/one", "TestTwo"}}, MissingTestSkip)
+	if tests := skipped.Packages[0].Tests; len(tests) != 2 || tests[1].Result != Skip || tests[1].SkipMessage != "test did not run" {
+		t.Errorf("AddMissingTests with skip mode returned tests %+v, want a skipped TestTwo", tests)
+	}
+}
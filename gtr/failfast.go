@@ -0,0 +1,92 @@
+package gtr
+
+import "time"
+
+// NotRunProperty is the test property that marks the tests added by
+// MarkFailFast, which never ran because the run was aborted.
+const NotRunProperty = "not_run"
+
+// MarkFailFast returns a copy of report r marked as aborted after the first
+// failed test, as go test -failfast does. The report is marked as Aborted,
+// and every expected test that doesn't appear in the report is added as a
+// skipped test marked with the NotRunProperty, so that tests that never ran as
+// a result of the abort can be told apart from tests that passed. Expected
+// packages that don't appear in the report are added in the same way as by
+// AddMissingTests.
+func MarkFailFast(r Report, expected []TestRef) Report {
+	marked := AddMissingTests(r, expected, MissingTestSkip)
+	marked.Aborted = true
+
+	pkgs := make([]Package, len(marked.Packages))
+	for i, pkg := range marked.Packages {
+		added := 0 // index of the first added test
+		if i < len(r.Packages) {
+			added = len(r.Packages[i].Tests)
+		}
+		pkg.Tests = append([]Test(nil), pkg.Tests...)
+		for j := added; j < len(pkg.Tests); j++ {
+			pkg.Tests[j].SkipMessage = "test did not run: the run was aborted after the first failure"
+			pkg.Tests[j].SetProperty(NotRunProperty, "true")
+		}
+		pkgs[i] = pkg
+	}
+	marked.Packages = pkgs
+	return marked
+}
+
+// DetectFailFast reports whether report r appears to be the result of a run
+// that was aborted after the first failed test, given the tests that are
+// expected to appear in it. This is the case when expected tests are missing
+// from packages with a failed test, and only from those packages, since
+// go test -failfast doesn't start new tests in a package once one of its tests
+// failed. Reports without expected tests are never detected.
+func DetectFailFast(r Report, expected []TestRef) bool {
+	failed := make(map[string]bool)
+	seen := make(map[TestRef]bool)
+	for _, pkg := range r.Packages {
+		seen[TestRef{Package: pkg.Name}] = true
+		for _, t := range pkg.Tests {
+			seen[TestRef{pkg.Name, t.Name}] = true
+			if t.Result.Base() == Fail {
+				failed[pkg.Name] = true
+			}
+		}
+	}
+
+	detected := false
+	for _, ref := range expected {
+		if seen[ref] {
+			continue
+		}
+		if !failed[ref.Package] {
+			return false
+		}
+		detected = true
+	}
+	return detected
+}
+
+// timeToFirstFailure returns the time between the start of the run of report r
+// and the end of its first failed test, or 0 if r has no failed tests or their
+// times are unknown.
+func timeToFirstFailure(r Report) time.Duration {
+	var start, failed time.Time
+	for _, pkg := range r.Packages {
+		start = earliest(start, pkg.StartTime)
+		for _, t := range pkg.Tests {
+			start = earliest(start, t.StartTime)
+			if t.Result.Base() != Fail {
+				continue
+			}
+			end := t.EndTime
+			if end.IsZero() && !t.StartTime.IsZero() {
+				end = t.StartTime.Add(t.Duration)
+			}
+			failed = earliest(failed, end)
+		}
+	}
+	if start.IsZero() || failed.IsZero() || failed.Before(start) {
+		return 0
+	}
+	return failed.Sub(start)
+}
@@ -0,0 +1,104 @@
+package gtr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarkFailFast(t *testing.T) {
+	report := Report{Packages: []Package{
+		{Name: "package/one", Tests: []Test{
+			{ID: 1, Name: "TestOne", Result: Pass},
+			{ID: 2, Name: "TestTwo", Result: Fail},
+		}},
+		{Name: "package/two", Properties: []Property{{Name: "go.version", Value: "1.13"}}},
+	}}
+	expected := []TestRef{
+		{"package/one", "TestOne"},
+		{"package/one", "TestTwo"},
+		{"package/one", "TestThree"},
+		{"package/three", "TestFour"},
+	}
+	notRun := func(id int, name string) Test {
+		return Test{
+			ID:          id,
+			Name:        name,
+			Result:      Skip,
+			SkipMessage: "test did not run: the run was aborted after the first failure",
+			Properties:  []Property{{Name: "missing", Value: "true"}, {Name: NotRunProperty, Value: "true"}},
+		}
+	}
+
+	want := Report{Aborted: true, Packages: []Package{
+		{Name: "package/one", Tests: []Test{
+			{ID: 1, Name: "TestOne", Result: Pass},
+			{ID: 2, Name: "TestTwo", Result: Fail},
+			notRun(3, "TestThree"),
+		}},
+		{Name: "package/two", Properties: []Property{{Name: "go.version", Value: "1.13"}}},
+		{Name: "package/three", Tests: []Test{notRun(1, "TestFour")}},
+	}}
+	got := MarkFailFast(report, expected)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("MarkFailFast incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if len(report.Packages[1].Properties) != 1 {
+		t.Errorf("MarkFailFast modified the properties of the original report: %v", report.Packages[1].Properties)
+	}
+
+	summary := got.Summary()
+	if !summary.Aborted || summary.Skipped != 2 || summary.NotRun != 2 {
+		t.Errorf("Summary of marked report incorrect, got Aborted=%t Skipped=%d NotRun=%d, want Aborted=true Skipped=2 NotRun=2",
+			summary.Aborted, summary.Skipped, summary.NotRun)
+	}
+}
+
+func TestDetectFailFast(t *testing.T) {
+	report := Report{Packages: []Package{
+		{Name: "package/one", Tests: []Test{{Name: "TestOne", Result: Pass}, {Name: "TestTwo", Result: Fail}}},
+		{Name: "package/two", Tests: []Test{{Name: "TestOne", Result: Pass}}},
+	}}
+	tests := []struct {
+		name     string
+		expected []TestRef
+		want     bool
+	}{
+		{"no expected tests", nil, false},
+		{"all tests ran", []TestRef{{"package/one", "TestOne"}, {"package/two", ""}}, false},
+		{"missing after failure", []TestRef{{"package/one", "TestThree"}, {"package/two", "TestOne"}}, true},
+		{"missing from passed package", []TestRef{{"package/one", "TestThree"}, {"package/two", "TestTwo"}}, false},
+		{"missing package", []TestRef{{"package/one", "TestThree"}, {"package/three", ""}}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := DetectFailFast(report, test.expected); got != test.want {
+				t.Errorf("DetectFailFast(%v) = %t, want %t", test.expected, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTimeToFirstFailure(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		report Report
+		want   time.Duration
+	}{
+		{"no times", Report{Packages: []Package{{Tests: []Test{{Result: Fail, Duration: time.Second}}}}}, 0},
+		{"no failures", Report{Packages: []Package{{StartTime: start, Tests: []Test{{Result: Pass, StartTime: start, EndTime: start.Add(time.Second)}}}}}, 0},
+		{"end times", Report{Packages: []Package{
+			{StartTime: start.Add(time.Second), Tests: []Test{{Result: Fail, StartTime: start.Add(2 * time.Second), EndTime: start.Add(5 * time.Second)}}},
+			{StartTime: start, Tests: []Test{{Result: Fail, StartTime: start.Add(time.Second), Duration: 2 * time.Second}}},
+		}}, 3 * time.Second},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.report.Summary().TimeToFirstFailure; got != test.want {
+				t.Errorf("TimeToFirstFailure = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package gtr
+
+// Filter returns a copy of report r containing only the tests for which
+// predicate returns true. Packages whose tests were all removed are dropped
+// from the report. Packages without any tests are kept if predicate returns
+// true when called with the package and a zero Test, which makes it possible
+// to drop packages by name regardless of their tests.
+func (r Report) Filter(predicate func(Package, Test) bool) Report {
+	filtered := r
+	filtered.Packages = nil
+	for _, pkg := range r.Packages {
+		if len(pkg.Tests) == 0 {
+			if predicate(pkg, Test{}) {
+				filtered.Packages = append(filtered.Packages, pkg)
+			}
+			continue
+		}
+
+		var tests []Test
+		for _, test := range pkg.Tests {
+			if predicate(pkg, test) {
+				tests = append(tests, test)
+			}
+		}
+		if len(tests) == 0 {
+			continue
+		}
+		pkg.Tests = tests
+		filtered.Packages = append(filtered.Packages, pkg)
+	}
+	return filtered
+}
+
+// Map returns a copy of report r in which every test has been replaced by the
+// result of calling transform on it, for example to rename tests or strip
+// their output. The tests of report r itself are not modified.
+func (r Report) Map(transform func(Test) Test) Report {
+	mapped := r
+	mapped.Packages = make([]Package, len(r.Packages))
+	for i, pkg := range r.Packages {
+		if pkg.Tests != nil {
+			tests := make([]Test, len(pkg.Tests))
+			for j, test := range pkg.Tests {
+				tests[j] = transform(test)
+			}
+			pkg.Tests = tests
+		}
+		mapped.Packages[i] = pkg
+	}
+	return mapped
+}
@@ -0,0 +1,73 @@
+package gtr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFilter(t *testing.T) {
+	report := Report{Packages: []Package{
+		{
+			Name:  "package/one",
+			Tests: []Test{{Name: "TestA"}, {Name: "TestSlow"}, {Name: "TestB"}},
+		},
+		{
+			Name:  "package/vendor/dep",
+			Tests: []Test{{Name: "TestDep"}},
+		},
+		{Name: "package/vendor/notests"},
+		{Name: "package/notests"},
+		{
+			Name:  "package/slow",
+			Tests: []Test{{Name: "TestSlow"}},
+		},
+	}}
+
+	got := report.Filter(func(pkg Package, test Test) bool {
+		return !strings.Contains(pkg.Name, "/vendor/") && test.Name != "TestSlow"
+	})
+	want := Report{Packages: []Package{
+		{
+			Name:  "package/one",
+			Tests: []Test{{Name: "TestA"}, {Name: "TestB"}},
+		},
+		{Name: "package/notests"},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Filter result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if len(report.Packages[0].Tests) != 3 {
+		t.Errorf("Filter modified the original report")
+	}
+}
+
+func TestMap(t *testing.T) {
+	report := Report{Packages: []Package{
+		{
+			Name:  "package/one",
+			Tests: []Test{{Name: "TestA", Output: []string{"noise"}}, {Name: "TestB"}},
+		},
+		{Name: "package/notests"},
+	}}
+
+	got := report.Map(func(test Test) Test {
+		test.Name = "renamed." + test.Name
+		test.Output = nil
+		return test
+	})
+	want := Report{Packages: []Package{
+		{
+			Name:  "package/one",
+			Tests: []Test{{Name: "renamed.TestA"}, {Name: "renamed.TestB"}},
+		},
+		{Name: "package/notests"},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Map result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if report.Packages[0].Tests[0].Name != "TestA" {
+		t.Errorf("Map modified the original report")
+	}
+}
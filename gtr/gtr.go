@@ -3,11 +3,19 @@
 package gtr
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
 
 // Result is the result of a test.
+//
+// Besides the core results Unknown, Pass, Fail, Skip and Flaky, a test can have
+// an extended result, which describes its outcome in more detail. Additional
+// results can be defined using RegisterResult. Every result has a core result,
+// returned by Base, which is what formats that don't support the result report
+// instead.
 type Result int
 
 // Test results.
@@ -16,8 +24,102 @@ const (
 	Pass
 	Fail
 	Skip
+	Flaky // failed and passed in different attempts, see GroupAttempts
+
+	Inconclusive // ran, but it could not be determined whether it passed; base result Skip
+
+	// firstCustomResult is the first result returned by RegisterResult.
+	firstCustomResult
 )
 
+// customResult is a result registered using RegisterResult.
+type customResult struct {
+	name string
+	base Result
+}
+
+var customResults []customResult
+
+// RegisterResult defines a new result with the given name and base result,
+// and returns it. The name must be unique, it's matched case-insensitively by
+// ParseResult and returned in upper case by Result.String. The base result
+// must be one of the core results. RegisterResult panics if name or base is
+// invalid. It's not safe for concurrent use and should be called during
+// initialization, for example in an init function.
+//
+// The numeric value of a registered result depends on the order in which
+// results are registered, reports containing them should therefore be
+// serialized using their names.
+func RegisterResult(name string, base Result) Result {
+	name = strings.ToUpper(name)
+	if name == "" {
+		panic("gtr: RegisterResult called with empty name")
+	}
+	if _, err := ParseResult(name); err == nil {
+		panic("gtr: RegisterResult called twice for result " + name)
+	}
+	if base < Unknown || base > Flaky {
+		panic("gtr: RegisterResult called with base result that isn't a core result")
+	}
+	customResults = append(customResults, customResult{name, base})
+	return firstCustomResult + Result(len(customResults)-1)
+}
+
+// custom returns the registration of result r, if r was registered using
+// RegisterResult.
+func (r Result) custom() (customResult, bool) {
+	i := int(r - firstCustomResult)
+	if i < 0 || i >= len(customResults) {
+		return customResult{}, false
+	}
+	return customResults[i], true
+}
+
+// IsCustom returns true if result r was registered using RegisterResult.
+func (r Result) IsCustom() bool {
+	_, ok := r.custom()
+	return ok
+}
+
+// Base returns the core result that result r is reported as by formats that
+// only support the core results, i.e. Unknown, Pass, Fail, Skip or Flaky. The
+// base result of a core result is the result itself.
+func (r Result) Base() Result {
+	switch r {
+	case Inconclusive:
+		return Skip
+	}
+	if c, ok := r.custom(); ok {
+		return c.base
+	}
+	return r
+}
+
+// ParseResult returns the Result for the given string. The string is matched
+// case-insensitively against the values returned by Result.String.
+func ParseResult(s string) (Result, error) {
+	switch strings.ToUpper(s) {
+	case "UNKNOWN":
+		return Unknown, nil
+	case "PASS":
+		return Pass, nil
+	case "FAIL":
+		return Fail, nil
+	case "SKIP":
+		return Skip, nil
+	case "FLAKY":
+		return Flaky, nil
+	case "INCONCLUSIVE":
+		return Inconclusive, nil
+	}
+	for i, c := range customResults {
+		if c.name == strings.ToUpper(s) {
+			return firstCustomResult + Result(i), nil
+		}
+	}
+	return Unknown, fmt.Errorf("unknown result: %v", s)
+}
+
 func (r Result) String() string {
 	switch r {
 	case Unknown:
@@ -28,25 +130,119 @@ func (r Result) String() string {
 		return "FAIL"
 	case Skip:
 		return "SKIP"
-	default:
-		panic("invalid Result")
+	case Flaky:
+		return "FLAKY"
+	case Inconclusive:
+		return "INCONCLUSIVE"
+	}
+	if c, ok := r.custom(); ok {
+		return c.name
+	}
+	panic("invalid Result")
+}
+
+// MarshalText implements encoding.TextMarshaler, so that results are encoded
+// by name, e.g. in JSON reports.
+func (r Result) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using ParseResult.
+func (r *Result) UnmarshalText(text []byte) error {
+	result, err := ParseResult(string(text))
+	if err != nil {
+		return err
 	}
+	*r = result
+	return nil
 }
 
+// TestKind is the kind of function a test was run from.
+type TestKind string
+
+// Test kinds.
+const (
+	KindTest    TestKind = ""        // a regular test, or a test of unknown kind
+	KindExample TestKind = "example" // an Example function with checked output
+)
+
 // Report contains the build and test results of a collection of packages.
 type Report struct {
 	Packages []Package
+
+	// Hostname is the host that ran the tests, empty if unknown.
+	Hostname string
+
+	// ShardIndex is the zero-based index of the shard that ran the tests,
+	// when the tests were split into ShardCount shards. ShardCount is zero if
+	// the tests weren't sharded or the shard is unknown.
+	ShardIndex int
+	ShardCount int
+
+	// RunMeta describes how the go test process that produced the report
+	// exited, nil if unknown.
+	RunMeta *RunMeta
+
+	// Aborted is true if the run stopped early after the first failed test,
+	// e.g. because of go test -failfast, see MarkFailFast.
+	Aborted bool
+}
+
+// SetHostname sets the hostname of report r, and of each of its packages
+// whose hostname is unknown.
+func (r *Report) SetHostname(hostname string) {
+	r.Hostname = hostname
+	for i := range r.Packages {
+		if r.Packages[i].Hostname == "" {
+			r.Packages[i].Hostname = hostname
+		}
+	}
+}
+
+// SetShard sets the shard of report r, and of each of its packages whose
+// shard is unknown, to the zero-based index of count shards.
+func (r *Report) SetShard(index, count int) {
+	r.ShardIndex, r.ShardCount = index, count
+	for i := range r.Packages {
+		if r.Packages[i].ShardCount == 0 {
+			r.Packages[i].ShardIndex, r.Packages[i].ShardCount = index, count
+		}
+	}
+}
+
+// SetTestProperty sets a key/value property on every test with the given name
+// in the package with the given name, see Test.SetProperty. It returns false
+// if no such test exists.
+func (r *Report) SetTestProperty(pkgName, testName, key, value string) bool {
+	found := false
+	for i := range r.Packages {
+		if r.Packages[i].Name != pkgName {
+			continue
+		}
+		for j := range r.Packages[i].Tests {
+			if test := &r.Packages[i].Tests[j]; test.Name == testName {
+				test.SetProperty(key, value)
+				found = true
+			}
+		}
+	}
+	return found
 }
 
 // IsSuccessful returns true if none of the packages in this report have build
-// or runtime errors and all tests passed without failures or were skipped.
+// or runtime errors and all tests passed without failures, were skipped or
+// eventually passed after failing in another attempt. A report is never
+// successful if the go test process was terminated by a signal.
 func (r *Report) IsSuccessful() bool {
+	if r.RunMeta != nil && r.RunMeta.Killed() {
+		return false
+	}
 	for _, pkg := range r.Packages {
-		if pkg.BuildError.Name != "" || pkg.RunError.Name != "" {
+		if pkg.BuildError.Name != "" || pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
 			return false
 		}
 		for _, t := range pkg.Tests {
-			if t.Result != Pass && t.Result != Skip {
+			if base := t.Result.Base(); base != Pass && base != Skip && base != Flaky {
 				return false
 			}
 		}
@@ -56,12 +252,23 @@ func (r *Report) IsSuccessful() bool {
 
 // Package contains build and test results for a single package.
 type Package struct {
-	Name       string
-	Timestamp  time.Time
-	Duration   time.Duration
-	Coverage   float64
-	Output     []string
-	Properties []Property
+	Name          string
+	Timestamp     time.Time
+	StartTime     time.Time // time of the first event, zero if unknown
+	EndTime       time.Time // time of the last event, zero if unknown
+	Duration      time.Duration
+	BuildDuration time.Duration // best-effort, zero if the build time is unknown
+	Coverage      float64
+	MaxParallel   int    // maximum number of tests running at the same time, zero if unknown
+	Hostname      string // host that ran the package, empty if unknown
+	ShardIndex    int    // zero-based index of the shard that ran the package
+	ShardCount    int    // number of shards, zero if the shard is unknown
+	NoTestFiles   bool   // the package has no test files, see NoTestFilesMode
+	Cached        bool   // the results were cached by go test, see CachedMode
+	Output        []string
+	Stderr        []string // output written to stderr, if the input kept it separate from Output
+	Properties    []Property
+	Attachments   []Attachment
 
 	Tests []Test
 
@@ -92,20 +299,125 @@ func (p *Package) AddProperty(name, value string) {
 	p.Properties = append(p.Properties, Property{Name: name, Value: value})
 }
 
+// AddAttachment appends an attachment to the current package. The given
+// mime type may be empty if it's unknown.
+func (p *Package) AddAttachment(name, path, mime string) {
+	p.Attachments = append(p.Attachments, Attachment{Name: name, Path: path, MIME: mime})
+}
+
+// OutputBytes returns the number of bytes of output in this package,
+// including the output of all its tests.
+func (p Package) OutputBytes() int {
+	n := outputBytes(p.Output)
+	for _, t := range p.Tests {
+		n += t.OutputBytes()
+	}
+	return n
+}
+
+// Overhead returns the time package p spent outside of its top-level tests,
+// such as in TestMain, init functions and package-level setup and teardown.
+// When the start and end times of all top-level tests are known, for example
+// from go test -json, time during which several parallel tests were running is
+// only counted once. Otherwise the durations of the top-level tests are
+// summed, which underestimates the overhead of packages with parallel tests.
+// Overhead returns 0 if the duration of the package isn't longer than the
+// time spent in its tests.
+func (p Package) Overhead() time.Duration {
+	type span struct{ start, end time.Time }
+	var spans []span
+	var sum time.Duration
+	timed := true
+	for _, t := range p.Tests {
+		if t.Level > 0 {
+			continue
+		}
+		sum += t.Duration
+		if t.StartTime.IsZero() || t.EndTime.IsZero() {
+			timed = false
+		}
+		spans = append(spans, span{t.StartTime, t.EndTime})
+	}
+	if timed && len(spans) > 0 {
+		sort.Slice(spans, func(i, j int) bool { return spans[i].start.Before(spans[j].start) })
+		sum = 0
+		cur := spans[0]
+		for _, s := range spans[1:] {
+			if s.start.After(cur.end) {
+				sum += cur.end.Sub(cur.start)
+				cur = s
+			} else if s.end.After(cur.end) {
+				cur.end = s.end
+			}
+		}
+		sum += cur.end.Sub(cur.start)
+	}
+	if p.Duration <= sum {
+		return 0
+	}
+	return p.Duration - sum
+}
+
+// Times returns the start and end time of package p. When its start time is
+// unknown, the package timestamp is used, or start if there is none. When its
+// end time is unknown, it's the start time plus the package duration.
+func (p Package) Times(start time.Time) (time.Time, time.Time) {
+	if !p.StartTime.IsZero() {
+		start = p.StartTime
+	} else if !p.Timestamp.IsZero() {
+		start = p.Timestamp
+	}
+	end := p.EndTime
+	if end.IsZero() {
+		end = start.Add(p.Duration)
+	}
+	return start, end
+}
+
 // Property is a name/value property.
 type Property struct {
 	Name, Value string
 }
 
+// Attachment is a file created by a test or package, such as a screenshot or
+// a profile, that should be linked from the report.
+type Attachment struct {
+	Name string // display name, may be empty
+	Path string // path or URL of the file
+	MIME string // media type of the file, empty if unknown
+}
+
 // Test contains the results of a single test.
 type Test struct {
-	ID       int
-	Name     string
-	Duration time.Duration
+	ID             int
+	Name           string
+	StartTime      time.Time // time the test started running, zero if unknown
+	EndTime        time.Time // time the test result was reported, zero if unknown
+	Duration       time.Duration
+	RunDuration    time.Duration // time spent running, excluding time paused by t.Parallel; zero if unknown
+	WallDuration   time.Duration // time between starting and ending, including pauses; zero if unknown
+	Result         Result
+	Level          int
+	Kind           TestKind
+	File           string // source file declaring the test function, empty if unknown
+	Line           int    // line of the test function in File, zero if unknown
+	Output         []string
+	Stderr         []string // output written to stderr, if the input kept it separate from Output
+	Properties     []Property
+	Attachments    []Attachment
+	Attempts       []TestAttempt // all attempts if the test ran more than once
+	Panic          *PanicInfo    // the panic that caused the test to fail, if any
+	SkipMessage    string        // the message passed to t.Skip, if known
+	FailureMessage string        // the primary failure message, if known
+	FailureType    string        // the kind of failure, e.g. the assertion library that reported it
+	Data           map[string]interface{}
+}
+
+// TestAttempt contains the result of a single attempt of running a test.
+type TestAttempt struct {
 	Result   Result
-	Level    int
+	Duration time.Duration
 	Output   []string
-	Data     map[string]interface{}
 }
 
 // NewTest creates a new Test with the given id and name.
@@ -113,13 +425,111 @@ func NewTest(id int, name string) Test {
 	return Test{ID: id, Name: name, Data: make(map[string]interface{})}
 }
 
+// SetProperty stores a key/value property in this test. If a property with
+// the given key already exists, its old value will be overwritten with the
+// given value.
+func (t *Test) SetProperty(key, value string) {
+	i := 0
+	for _, prop := range t.Properties {
+		if key != prop.Name {
+			t.Properties[i] = prop
+			i++
+		}
+	}
+	t.Properties = t.Properties[:i]
+	t.AddProperty(key, value)
+}
+
+// AddProperty appends a name/value property to this test.
+func (t *Test) AddProperty(name, value string) {
+	t.Properties = append(t.Properties, Property{Name: name, Value: value})
+}
+
+// AddAttachment appends an attachment to this test. The given mime type may
+// be empty if it's unknown.
+func (t *Test) AddAttachment(name, path, mime string) {
+	t.Attachments = append(t.Attachments, Attachment{Name: name, Path: path, MIME: mime})
+}
+
+// OutputBytes returns the number of bytes of output produced by this test,
+// counting a newline for every line of output.
+func (t Test) OutputBytes() int {
+	return outputBytes(t.Output)
+}
+
+// FailureReason returns the message report writers use to describe why test t
+// failed: the panic message prefixed by "Panic: " if it panicked, or its
+// FailureMessage otherwise, which may be empty.
+func (t Test) FailureReason() string {
+	if t.Panic != nil {
+		return panicReason(t.Panic)
+	}
+	return t.FailureMessage
+}
+
+// outputBytes returns the size in bytes of the given lines, counting a newline
+// for every line.
+func outputBytes(lines []string) int {
+	var n int
+	for _, line := range lines {
+		n += len(line) + 1
+	}
+	return n
+}
+
+// ErrorKindInfra is the Kind of errors caused by the environment the tests
+// ran in, such as running out of file descriptors, rather than by the code
+// under test.
+const ErrorKindInfra = "infra"
+
+// ErrorKindTimeout is the Kind of errors caused by the test binary exceeding
+// the go test -timeout outside of any test. It's also the FailureType of the
+// tests that were running when the timeout occurred.
+const ErrorKindTimeout = "timeout"
+
+// ErrorKindSanitizer is the Kind of errors reported by AddressSanitizer,
+// MemorySanitizer or LeakSanitizer outside of any test. It's also the
+// FailureType of the tests in which a sanitizer reported an error.
+const ErrorKindSanitizer = "sanitizer"
+
 // Error contains details of a build or runtime error.
 type Error struct {
-	ID       int
-	Name     string
-	Duration time.Duration
-	Cause    string
-	Output   []string
+	ID          int
+	Name        string
+	Kind        string // empty, ErrorKindInfra for infrastructure errors, ErrorKindTimeout or ErrorKindSanitizer
+	Duration    time.Duration
+	Cause       string
+	Output      []string
+	Panic       *PanicInfo   // the panic that caused the error, if any
+	Diagnostics []Diagnostic // the compiler diagnostics in Output of a build error
+}
+
+// FailureReason returns the message report writers use to describe error e:
+// "Infrastructure error" for infrastructure errors, the sanitizer report for
+// sanitizer errors, the panic message prefixed by "Panic: " if a panic caused
+// the error, or its Cause otherwise, which may be empty.
+func (e Error) FailureReason() string {
+	switch {
+	case e.Kind == ErrorKindInfra:
+		return "Infrastructure error"
+	case e.Kind == ErrorKindSanitizer:
+		return e.Cause
+	case e.Panic != nil:
+		return panicReason(e.Panic)
+	}
+	return e.Cause
+}
+
+// PanicInfo describes a panic that caused a test or package to fail.
+type PanicInfo struct {
+	Message string   // the panic message, without the "panic: " prefix
+	Test    string   // name of the panicking test, empty if unknown
+	Stack   []string // the goroutine dump printed after the panic message
+}
+
+// panicReason returns the failure reason of a test or error caused by panic p.
+func panicReason(p *PanicInfo) string {
+	return "Panic: " + p.Message
 }
 
 // TrimPrefixSpaces trims the leading whitespace of the given line using the
@@ -133,12 +543,18 @@ func TrimPrefixSpaces(line string, indent int) string {
 	// output. Test output is usually prefixed by a series of 4-space indents,
 	// so we'll check for that to decide whether this output was likely to be
 	// from a test.
-	prefixLen := strings.IndexFunc(line, func(r rune) bool { return r != ' ' })
-	if prefixLen%4 == 0 {
+	prefixLen := 0
+	for prefixLen < len(line) && line[prefixLen] == ' ' {
+		prefixLen++
+	}
+	if prefixLen < len(line) && prefixLen%4 == 0 {
 		// Use the subtest level to trim a consistently sized prefix from the
 		// output lines.
-		for i := 0; i <= indent; i++ {
-			line = strings.TrimPrefix(line, "    ")
+		if trim := 4 * (indent + 1); trim < prefixLen {
+			prefixLen = trim
+		}
+		if prefixLen > 0 {
+			line = line[prefixLen:]
 		}
 	}
 	return strings.TrimPrefix(line, "\t")
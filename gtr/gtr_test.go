@@ -2,6 +2,7 @@ package gtr
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -40,3 +41,255 @@ func TestSetProperty(t *testing.T) {
 		t.Errorf("SetProperty got unexpected diff: %s", diff)
 	}
 }
+
+func TestTestSetProperty(t *testing.T) {
+	test := Test{}
+	test.SetProperty("owner", "team-a")
+	test.SetProperty("shard", "3")
+	test.SetProperty("owner", "team-b")
+
+	want := []Property{{Name: "shard", Value: "3"}, {Name: "owner", Value: "team-b"}}
+	if diff := cmp.Diff(want, test.Properties); diff != "" {
+		t.Errorf("SetProperty got unexpected diff: %s", diff)
+	}
+}
+
+func TestSetTestProperty(t *testing.T) {
+	report := Report{Packages: []Package{
+		{Name: "package/one", Tests: []Test{{Name: "TestA"}, {Name: "TestB"}}},
+		{Name: "package/two", Tests: []Test{{Name: "TestA"}}},
+	}}
+
+	if !report.SetTestProperty("package/one", "TestA", "owner", "team-a") {
+		t.Errorf("SetTestProperty did not find existing test")
+	}
+	if report.SetTestProperty("package/one", "TestC", "owner", "team-a") {
+		t.Errorf("SetTestProperty found non-existing test")
+	}
+
+	want := Report{Packages: []Package{
+		{Name: "package/one", Tests: []Test{{Name: "TestA", Properties: []Property{{Name: "owner", Value: "team-a"}}}, {Name: "TestB"}}},
+		{Name: "package/two", Tests: []Test{{Name: "TestA"}}},
+	}}
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Errorf("SetTestProperty got unexpected diff (-want +got):\n%s\n", diff)
+	}
+}
+
+var resultRetried = RegisterResult("retried", Pass)
+
+func TestResultBase(t *testing.T) {
+	tests := []struct {
+		result Result
+		want   Result
+		custom bool
+	}{
+		{Unknown, Unknown, false},
+		{Pass, Pass, false},
+		{Fail, Fail, false},
+		{Skip, Skip, false},
+		{Flaky, Flaky, false},
+		{Inconclusive, Skip, false},
+		{resultRetried, Pass, true},
+	}
+	for _, test := range tests {
+		if got := test.result.Base(); got != test.want {
+			t.Errorf("%v.Base() = %v, want %v", test.result, got, test.want)
+		}
+		if got := test.result.IsCustom(); got != test.custom {
+			t.Errorf("%v.IsCustom() = %v, want %v", test.result, got, test.custom)
+		}
+	}
+}
+
+func TestRegisterResultInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		base Result
+	}{
+		{"", Pass},
+		{"pass", Pass},
+		{"Retried", Pass},
+		{"degraded", Inconclusive},
+	}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterResult(%q, %v) did not panic", test.name, test.base)
+				}
+			}()
+			RegisterResult(test.name, test.base)
+		}()
+	}
+}
+
+func TestParseResult(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Result
+		wantErr bool
+	}{
+		{"pass", Pass, false},
+		{"FAIL", Fail, false},
+		{"Skip", Skip, false},
+		{"unknown", Unknown, false},
+		{"flaky", Flaky, false},
+		{"INCONCLUSIVE", Inconclusive, false},
+		{"retried", resultRetried, false},
+		{"broken", Unknown, true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseResult(test.in)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseResult(%q) error = %v, wantErr %v", test.in, err, test.wantErr)
+		}
+		if got != test.want {
+			t.Errorf("ParseResult(%q) incorrect, got %v, want %v", test.in, got, test.want)
+		}
+	}
+}
+
+func TestResultMarshalText(t *testing.T) {
+	for _, want := range []Result{Unknown, Pass, Fail, Skip, Flaky, Inconclusive, resultRetried} {
+		text, err := want.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%v) error: %v", want, err)
+		}
+		var got Result
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatalf("UnmarshalText(%q) error: %v", text, err)
+		}
+		if got != want {
+			t.Errorf("UnmarshalText(%q) incorrect, got %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestOutputBytes(t *testing.T) {
+	pkg := Package{
+		Output: []string{"package output"},
+		Tests: []Test{
+			{Name: "TestOne", Output: []string{"one", "two"}},
+			{Name: "TestTwo"},
+		},
+	}
+
+	if got, want := pkg.Tests[0].OutputBytes(), 8; got != want {
+		t.Errorf("Test.OutputBytes() incorrect, got %d, want %d", got, want)
+	}
+	if got, want := pkg.Tests[1].OutputBytes(), 0; got != want {
+		t.Errorf("Test.OutputBytes() incorrect, got %d, want %d", got, want)
+	}
+	if got, want := pkg.OutputBytes(), 23; got != want {
+		t.Errorf("Package.OutputBytes() incorrect, got %d, want %d", got, want)
+	}
+}
+
+func TestTestFailureReason(t *testing.T) {
+	tests := []struct {
+		test Test
+		want string
+	}{
+		{Test{}, ""},
+		{Test{FailureMessage: "assertion failed"}, "assertion failed"},
+		{Test{FailureMessage: "assertion failed", Panic: &PanicInfo{Message: "oops"}}, "Panic: oops"},
+	}
+	for _, test := range tests {
+		if got := test.test.FailureReason(); got != test.want {
+			t.Errorf("FailureReason() of %+v = %q, want %q", test.test, got, test.want)
+		}
+	}
+}
+
+func TestErrorFailureReason(t *testing.T) {
+	tests := []struct {
+		err  Error
+		want string
+	}{
+		{Error{}, ""},
+		{Error{Cause: "exit status 2"}, "exit status 2"},
+		{Error{Cause: "exit status 2", Panic: &PanicInfo{Message: "oops"}}, "Panic: oops"},
+		{Error{Kind: ErrorKindTimeout, Cause: "test timed out after 1s", Panic: &PanicInfo{Message: "test timed out after 1s"}}, "Panic: test timed out after 1s"},
+		{Error{Kind: ErrorKindInfra, Cause: "socket: too many open files"}, "Infrastructure error"},
+		{Error{Kind: ErrorKindSanitizer, Cause: "AddressSanitizer: heap-use-after-free", Panic: &PanicInfo{Message: "oops"}}, "AddressSanitizer: heap-use-after-free"},
+	}
+	for _, test := range tests {
+		if got := test.err.FailureReason(); got != test.want {
+			t.Errorf("FailureReason() of %+v = %q, want %q", test.err, got, test.want)
+		}
+	}
+}
+
+func TestPackageOverhead(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+
+	tests := []struct {
+		name string
+		pkg  Package
+		want time.Duration
+	}{
+		{"no tests", Package{Duration: 3 * time.Second}, 3 * time.Second},
+		{"sequential", Package{Duration: 10 * time.Second, Tests: []Test{
+			{Name: "TestA", Duration: 2 * time.Second},
+			{Name: "TestA/sub", Duration: 2 * time.Second, Level: 1},
+			{Name: "TestB", Duration: 3 * time.Second},
+		}}, 5 * time.Second},
+		{"parallel", Package{Duration: 10 * time.Second, Tests: []Test{
+			{Name: "TestA", Duration: 4 * time.Second, StartTime: at(1), EndTime: at(5)},
+			{Name: "TestB", Duration: 3 * time.Second, StartTime: at(2), EndTime: at(5)},
+			{Name: "TestC", Duration: 1 * time.Second, StartTime: at(7), EndTime: at(8)},
+		}}, 5 * time.Second},
+		{"unknown duration", Package{Tests: []Test{{Name: "TestA", Duration: time.Second}}}, 0},
+	}
+	for _, test := range tests {
+		if got := test.pkg.Overhead(); got != test.want {
+			t.Errorf("Overhead() of %s package = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestPackageTimes(t *testing.T) {
+	fallback := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	start, end := fallback.Add(time.Second), fallback.Add(3*time.Second)
+	tests := []struct {
+		name      string
+		pkg       Package
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{"start and end", Package{StartTime: start, EndTime: end, Timestamp: fallback, Duration: time.Second}, start, end},
+		{"timestamp", Package{Timestamp: start, Duration: time.Second}, start, start.Add(time.Second)},
+		{"fallback", Package{Duration: 2 * time.Second}, fallback, fallback.Add(2 * time.Second)},
+	}
+	for _, test := range tests {
+		gotStart, gotEnd := test.pkg.Times(fallback)
+		if !gotStart.Equal(test.wantStart) || !gotEnd.Equal(test.wantEnd) {
+			t.Errorf("Times() of %s package = %v, %v, want %v, %v", test.name, gotStart, gotEnd, test.wantStart, test.wantEnd)
+		}
+	}
+}
+
+func TestReportSetHostnameAndShard(t *testing.T) {
+	report := Report{Packages: []Package{
+		{Name: "package/one"},
+		{Name: "package/two", Hostname: "other", ShardIndex: 0, ShardCount: 2},
+	}}
+	report.SetHostname("host")
+	report.SetShard(1, 2)
+
+	want := Report{
+		Hostname:   "host",
+		ShardIndex: 1,
+		ShardCount: 2,
+		Packages: []Package{
+			{Name: "package/one", Hostname: "host", ShardIndex: 1, ShardCount: 2},
+			{Name: "package/two", Hostname: "other", ShardIndex: 0, ShardCount: 2},
+		},
+	}
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Errorf("SetHostname and SetShard result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,57 @@
+package gtr
+
+import "strings"
+
+// failed returns true if test t failed or has no result.
+func failed(t Test) bool {
+	return t.Result.Base() == Fail || t.Result == Unknown
+}
+
+// MarkKnownFailures returns a copy of report r in which the tests that failed
+// or have no result, and that also failed or had no result in the baseline
+// report, such as a report of the main branch, are marked as known failures,
+// so that only new failures fail the report. Known failures are marked as
+// skipped, with a "known-failure" property and their actual result recorded
+// in the "known-failure.result" property, e.g. "fail". Tests are matched by
+// package and test name.
+func MarkKnownFailures(r, baseline Report) Report {
+	known := make(map[TestRef]bool)
+	for _, pkg := range baseline.Packages {
+		for _, t := range pkg.Tests {
+			if failed(t) {
+				known[TestRef{pkg.Name, t.Name}] = true
+			}
+		}
+	}
+	if len(known) == 0 {
+		return r
+	}
+
+	marked := r
+	marked.Packages = make([]Package, len(r.Packages))
+	for i, pkg := range r.Packages {
+		tests := make([]Test, len(pkg.Tests))
+		for j, t := range pkg.Tests {
+			if failed(t) && known[TestRef{pkg.Name, t.Name}] {
+				t = markKnownFailure(t)
+			}
+			tests[j] = t
+		}
+		if pkg.Tests == nil {
+			tests = nil
+		}
+		pkg.Tests = tests
+		marked.Packages[i] = pkg
+	}
+	return marked
+}
+
+func markKnownFailure(t Test) Test {
+	t.Properties = copyProperties(t.Properties)
+	t.SetProperty("known-failure", "true")
+	t.SetProperty("known-failure.result", strings.ToLower(t.Result.String()))
+	t.Result = Skip
+	t.SkipMessage = "known failure: also failed in the baseline"
+	t.FailureMessage, t.FailureType = "", ""
+	return t
+}
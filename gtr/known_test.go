@@ -0,0 +1,55 @@
+package gtr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarkKnownFailures(t *testing.T) {
+	baseline := Report{Packages: []Package{
+		{Name: "package/one", Tests: []Test{
+			{Name: "TestKnown", Result: Fail},
+			{Name: "TestFixed", Result: Fail},
+			{Name: "TestCrash", Result: Unknown},
+			{Name: "TestNew", Result: Pass},
+		}},
+		{Name: "package/two", Tests: []Test{{Name: "TestOther", Result: Fail}}},
+	}}
+	report := Report{Packages: []Package{
+		{Name: "package/one", Tests: []Test{
+			{Name: "TestKnown", Result: Fail, FailureMessage: "failed"},
+			{Name: "TestFixed", Result: Pass},
+			{Name: "TestCrash", Result: Unknown},
+			{Name: "TestNew", Result: Fail},
+		}},
+		{Name: "package/three", Tests: []Test{{Name: "TestOther", Result: Fail}}},
+	}}
+
+	want := Report{Packages: []Package{
+		{Name: "package/one", Tests: []Test{
+			{
+				Name:        "TestKnown",
+				Result:      Skip,
+				SkipMessage: "known failure: also failed in the baseline",
+				Properties:  []Property{{Name: "known-failure", Value: "true"}, {Name: "known-failure.result", Value: "fail"}},
+			},
+			{Name: "TestFixed", Result: Pass},
+			{
+				Name:        "TestCrash",
+				Result:      Skip,
+				SkipMessage: "known failure: also failed in the baseline",
+				Properties:  []Property{{Name: "known-failure", Value: "true"}, {Name: "known-failure.result", Value: "unknown"}},
+			},
+			{Name: "TestNew", Result: Fail},
+		}},
+		{Name: "package/three", Tests: []Test{{Name: "TestOther", Result: Fail}}},
+	}}
+	got := MarkKnownFailures(report, baseline)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("MarkKnownFailures incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if report.Packages[0].Tests[0].Result != Fail {
+		t.Errorf("MarkKnownFailures modified the original report")
+	}
+}
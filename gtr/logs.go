@@ -0,0 +1,239 @@
+package gtr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LogLevel is the severity of a structured log line. Higher levels are more
+// severe.
+type LogLevel int
+
+// The log levels in increasing order of severity. LogLevelUnknown is the level
+// of log lines without a level, or with a level that isn't recognized.
+const (
+	LogLevelUnknown LogLevel = iota
+	LogLevelTrace
+	LogLevelDebug
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+	LogLevelFatal
+)
+
+var logLevelNames = map[string]LogLevel{
+	"trace":   LogLevelTrace,
+	"debug":   LogLevelDebug,
+	"info":    LogLevelInfo,
+	"warn":    LogLevelWarn,
+	"warning": LogLevelWarn,
+	"error":   LogLevelError,
+	"dpanic":  LogLevelFatal,
+	"panic":   LogLevelFatal,
+	"fatal":   LogLevelFatal,
+}
+
+// regexLogLevelOffset matches the offset that log/slog appends to the names
+// of levels between the predefined ones, such as DEBUG+2.
+var regexLogLevelOffset = regexp.MustCompile(`[+-][0-9]+$`)
+
+// ParseLogLevel returns the LogLevel with the given name, such as debug or
+// WARN. An empty name returns LogLevelUnknown.
+func ParseLogLevel(name string) (LogLevel, error) {
+	if name == "" {
+		return LogLevelUnknown, nil
+	}
+	if level, ok := logLevelNames[strings.ToLower(regexLogLevelOffset.ReplaceAllString(name, ""))]; ok {
+		return level, nil
+	}
+	return LogLevelUnknown, fmt.Errorf("unknown log level: %s", name)
+}
+
+// LogOptions configures how FormatLogs processes structured log lines.
+type LogOptions struct {
+	// Format replaces each JSON log line with a line containing its time,
+	// level and message followed by its other fields as key=value pairs.
+	Format bool
+
+	// MinLevel is the lowest level of the log lines that are kept. Lines
+	// with a lower level, such as debug lines when MinLevel is
+	// LogLevelInfo, are removed. Lines without a known level are always
+	// kept.
+	MinLevel LogLevel
+}
+
+// FormatLogs returns a copy of report r in which the JSON log lines in all
+// output have been processed according to o. Log lines are lines containing
+// a single JSON object with a msg, message, level, lvl or severity field,
+// such as those written by log/slog, zap and logrus. They may be indented
+// and prefixed with the file:line of a call to t.Log. Other lines are kept
+// unchanged.
+func FormatLogs(r Report, o LogOptions) Report {
+	if !o.Format && o.MinLevel == LogLevelUnknown {
+		return r
+	}
+	formatted := r.Map(func(t Test) Test {
+		t.Output = o.formatLines(t.Output)
+		t.Stderr = o.formatLines(t.Stderr)
+		if t.Attempts != nil {
+			attempts := make([]TestAttempt, len(t.Attempts))
+			for i, a := range t.Attempts {
+				a.Output = o.formatLines(a.Output)
+				attempts[i] = a
+			}
+			t.Attempts = attempts
+		}
+		return t
+	})
+	for i := range formatted.Packages {
+		pkg := &formatted.Packages[i]
+		pkg.Output = o.formatLines(pkg.Output)
+		pkg.Stderr = o.formatLines(pkg.Stderr)
+		pkg.BuildError.Output = o.formatLines(pkg.BuildError.Output)
+		pkg.RunError.Output = o.formatLines(pkg.RunError.Output)
+	}
+	return formatted
+}
+
+func (o LogOptions) formatLines(lines []string) []string {
+	if lines == nil {
+		return nil
+	}
+	formatted := make([]string, 0, len(lines))
+	for _, line := range lines {
+		entry, ok := parseLogLine(line)
+		if !ok {
+			formatted = append(formatted, line)
+			continue
+		}
+		if entry.level != LogLevelUnknown && entry.level < o.MinLevel {
+			continue
+		}
+		if o.Format {
+			line = entry.String()
+		}
+		formatted = append(formatted, line)
+	}
+	return formatted
+}
+
+// regexLogLine matches a line containing a JSON object, optionally indented
+// and prefixed with the file:line added by t.Log.
+var regexLogLine = regexp.MustCompile(`^(\s*(?:[\w.-]+\.go:\d+: )?)(\{.*\})\s*$`)
+
+// logField is a field of a log line. Its value is the JSON encoded value of
+// the field.
+type logField struct {
+	key   string
+	value json.RawMessage
+}
+
+// logEntry is a parsed JSON log line.
+type logEntry struct {
+	prefix    string
+	time      string
+	levelName string
+	level     LogLevel
+	msg       string
+	fields    []logField
+}
+
+// parseLogLine parses line as a JSON log line, keeping the order of its
+// fields. It returns false if line is not a log line.
+func parseLogLine(line string) (logEntry, bool) {
+	m := regexLogLine.FindStringSubmatch(line)
+	if m == nil {
+		return logEntry{}, false
+	}
+	entry := logEntry{prefix: m[1]}
+	var isLog bool
+	dec := json.NewDecoder(strings.NewReader(m[2]))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return logEntry{}, false
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return logEntry{}, false
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return logEntry{}, false
+		}
+		switch s, isString := stringValue(value); {
+		case isString && entry.msg == "" && (key == "msg" || key == "message"):
+			entry.msg, isLog = s, true
+		case isString && entry.levelName == "" && (key == "level" || key == "lvl" || key == "severity"):
+			entry.levelName, isLog = s, true
+			entry.level, _ = ParseLogLevel(s)
+		case entry.time == "" && (key == "time" || key == "ts" || key == "timestamp"):
+			if isString {
+				entry.time = s
+			} else {
+				entry.time = string(value)
+			}
+		default:
+			entry.fields = append(entry.fields, logField{key, value})
+		}
+	}
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('}') {
+		return logEntry{}, false
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return logEntry{}, false
+	}
+	return entry, isLog
+}
+
+// stringValue returns the string in the JSON encoded value, and false if it
+// doesn't contain a string.
+func stringValue(value json.RawMessage) (string, bool) {
+	var s string
+	if len(value) == 0 || value[0] != '"' || json.Unmarshal(value, &s) != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// String returns the log line as a line containing its time, level and
+// message, followed by its other fields as key=value pairs.
+func (e logEntry) String() string {
+	var parts []string
+	if e.time != "" {
+		parts = append(parts, e.time)
+	}
+	if e.levelName != "" {
+		parts = append(parts, strings.ToUpper(e.levelName))
+	}
+	if e.msg != "" {
+		parts = append(parts, e.msg)
+	}
+	for _, f := range e.fields {
+		parts = append(parts, f.key+"="+formatLogValue(f.value))
+	}
+	return e.prefix + strings.Join(parts, " ")
+}
+
+// formatLogValue formats a JSON encoded field value. Strings are only quoted
+// when needed to tell where they end, other values are written as compact
+// JSON.
+func formatLogValue(value json.RawMessage) string {
+	if s, ok := stringValue(value); ok {
+		if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+			return strconv.Quote(s)
+		}
+		return s
+	}
+	var b bytes.Buffer
+	if err := json.Compact(&b, value); err != nil {
+		return string(value)
+	}
+	return b.String()
+}
@@ -0,0 +1,83 @@
+package gtr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	tests := map[string]LogLevel{
+		"":        LogLevelUnknown,
+		"debug":   LogLevelDebug,
+		"INFO":    LogLevelInfo,
+		"Warning": LogLevelWarn,
+		"ERROR+2": LogLevelError,
+		"dpanic":  LogLevelFatal,
+	}
+	for name, want := range tests {
+		got, err := ParseLogLevel(name)
+		if err != nil || got != want {
+			t.Errorf("ParseLogLevel(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseLogLevel("verbose"); err == nil {
+		t.Errorf("ParseLogLevel did not return an error for an unknown level")
+	}
+}
+
+func TestFormatLogs(t *testing.T) {
+	output := []string{
+		`{"time":"2023-01-02T15:04:05Z","level":"INFO","msg":"server started","addr":":8080","tls":false}`,
+		`    main_test.go:12: {"level":"debug","ts":1672671845.5,"msg":"query","sql":"SELECT 1","args":[1, 2]}`,
+		`{"severity":"error","message":"request failed","error":"connection refused","attempt":3}`,
+		`{"id":1,"name":"not a log line"}`,
+		`{"msg":"no level","empty":""}`,
+		`{"msg": "invalid"`,
+		`plain output`,
+	}
+
+	tests := []struct {
+		name string
+		opts LogOptions
+		want []string
+	}{
+		{"none", LogOptions{}, output},
+		{"format", LogOptions{Format: true}, []string{
+			`2023-01-02T15:04:05Z INFO server started addr=:8080 tls=false`,
+			`    main_test.go:12: 1672671845.5 DEBUG query sql="SELECT 1" args=[1,2]`,
+			`ERROR request failed error="connection refused" attempt=3`,
+			`{"id":1,"name":"not a log line"}`,
+			`no level empty=""`,
+			`{"msg": "invalid"`,
+			`plain output`,
+		}},
+		{"min-level", LogOptions{MinLevel: LogLevelInfo}, []string{
+			output[0], output[2], output[3], output[4], output[5], output[6],
+		}},
+		{"format-min-level", LogOptions{Format: true, MinLevel: LogLevelError}, []string{
+			`ERROR request failed error="connection refused" attempt=3`,
+			`{"id":1,"name":"not a log line"}`,
+			`no level empty=""`,
+			`{"msg": "invalid"`,
+			`plain output`,
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := Report{Packages: []Package{{
+				Name:     "package/name",
+				Tests:    []Test{{Name: "TestA", Output: output}},
+				Output:   output,
+				RunError: Error{Name: "package/name", Output: output},
+			}}}
+			got := FormatLogs(report, test.opts)
+			pkg := got.Packages[0]
+			for _, lines := range [][]string{pkg.Tests[0].Output, pkg.Output, pkg.RunError.Output} {
+				if diff := cmp.Diff(test.want, lines); diff != "" {
+					t.Errorf("FormatLogs output incorrect, diff (-want +got):\n%s\n", diff)
+				}
+			}
+		})
+	}
+}
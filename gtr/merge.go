@@ -0,0 +1,274 @@
+package gtr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Merge combines the given reports into a single report, for example to
+// create one report from the results of multiple CI shards. Packages with the
+// same name are merged into a single package, in the order in which they first
+// appear. Their durations are summed and their output and properties
+// combined.
+//
+// Tests that appear in more than one report are merged into a single test
+// whose duration is the sum of all durations. Conflicting results are
+// reconciled by keeping the worst result, where a failure wins over a missing
+// result, which wins over a flaky result, a pass and a skip, in that order.
+//
+// Packages whose hostname or shard is unknown get those of the report they're
+// in. When packages that ran on different hosts or shards are merged, the
+// hostname and shard of the merged package become unknown, and its tests get
+// host.name, shard.index and shard.count properties instead, so that each
+// failure can still be traced to where it ran. The merged report only keeps a
+// hostname and shard that all reports have in common, and the RunMeta of the
+// first report whose process was killed, or else exited with a non-zero code.
+// The merged report is aborted if any of the reports is.
+//
+// Use MergeOptions to combine tests that appear more than once differently.
+func Merge(reports ...Report) Report {
+	return merge(reports, combineTests)
+}
+
+// merge implements Merge, using mergeTest to merge tests that appear in more
+// than one report.
+func merge(reports []Report, mergeTest func(into *Test, from Test)) Report {
+	m := newMerger(mergeTest)
+	for _, r := range reports {
+		m.add(r)
+	}
+	return m.merged
+}
+
+// merger merges reports one at a time, see Merge.
+type merger struct {
+	merged    Report
+	added     int             // number of merged reports
+	index     map[string]int  // package index by name
+	mixed     map[string]bool // packages with tests from different origins
+	mergeTest func(into *Test, from Test)
+}
+
+// newMerger returns a merger that uses mergeTest to merge tests that appear in
+// more than one report.
+func newMerger(mergeTest func(into *Test, from Test)) *merger {
+	return &merger{
+		index:     make(map[string]int),
+		mixed:     make(map[string]bool),
+		mergeTest: mergeTest,
+	}
+}
+
+// add merges report r into the merged report.
+func (m *merger) add(r Report) {
+	merged := &m.merged
+	if m.added == 0 {
+		merged.Hostname, merged.ShardIndex, merged.ShardCount = r.Hostname, r.ShardIndex, r.ShardCount
+	} else if originOf(r.Hostname, r.ShardIndex, r.ShardCount) != originOf(merged.Hostname, merged.ShardIndex, merged.ShardCount) {
+		merged.Hostname, merged.ShardIndex, merged.ShardCount = "", 0, 0
+	}
+	m.added++
+	if runMetaRank(r.RunMeta) > runMetaRank(merged.RunMeta) {
+		merged.RunMeta = r.RunMeta
+	}
+	merged.Aborted = merged.Aborted || r.Aborted
+	for _, pkg := range r.Packages {
+		if pkg.Hostname == "" {
+			pkg.Hostname = r.Hostname
+		}
+		if pkg.ShardCount == 0 {
+			pkg.ShardIndex, pkg.ShardCount = r.ShardIndex, r.ShardCount
+		}
+		if i, ok := m.index[pkg.Name]; ok {
+			into := &merged.Packages[i]
+			if !m.mixed[pkg.Name] && packageOrigin(*into) != packageOrigin(pkg) {
+				m.mixed[pkg.Name] = true
+				for j := range into.Tests {
+					addOrigin(&into.Tests[j], packageOrigin(*into))
+				}
+				into.Hostname, into.ShardIndex, into.ShardCount = "", 0, 0
+			}
+			if m.mixed[pkg.Name] {
+				pkg.Tests = originTests(into.Tests, pkg)
+			}
+			mergePackage(into, pkg, m.mergeTest)
+			continue
+		}
+		m.index[pkg.Name] = len(merged.Packages)
+		merged.Packages = append(merged.Packages, copyPackage(pkg))
+	}
+}
+
+// runMetaRank returns how bad the exit of a process described by m is, so that
+// Merge keeps the worst one.
+func runMetaRank(m *RunMeta) int {
+	switch {
+	case m == nil:
+		return 0
+	case m.Killed():
+		return 3
+	case m.ExitCode != 0:
+		return 2
+	}
+	return 1
+}
+
+// origin identifies the host and shard that ran a package.
+type origin struct {
+	hostname   string
+	shardIndex int
+	shardCount int
+}
+
+func originOf(hostname string, shardIndex, shardCount int) origin {
+	if shardCount == 0 {
+		shardIndex = 0
+	}
+	return origin{hostname, shardIndex, shardCount}
+}
+
+func packageOrigin(pkg Package) origin {
+	return originOf(pkg.Hostname, pkg.ShardIndex, pkg.ShardCount)
+}
+
+// properties returns the properties recording origin o on a test.
+func (o origin) properties() []Property {
+	var props []Property
+	if o.hostname != "" {
+		props = append(props, Property{Name: "host.name", Value: o.hostname})
+	}
+	if o.shardCount > 0 {
+		props = append(props,
+			Property{Name: "shard.index", Value: strconv.Itoa(o.shardIndex)},
+			Property{Name: "shard.count", Value: strconv.Itoa(o.shardCount)})
+	}
+	return props
+}
+
+// addOrigin adds the properties of origin o to test t. If t already has a
+// different value for one of them, for example because it ran in more than one
+// shard, the values are combined as a comma separated list.
+func addOrigin(t *Test, o origin) {
+	t.Properties = copyProperties(t.Properties)
+	for _, prop := range o.properties() {
+		t.SetProperty(prop.Name, joinValue(propertyValue(t.Properties, prop.Name), prop.Value))
+	}
+}
+
+// originTests returns a copy of the tests of pkg with the properties of its
+// origin. Tests that also appear in existing get the combined values of both,
+// so merging them with combineTests keeps every origin.
+func originTests(existing []Test, pkg Package) []Test {
+	tests := make([]Test, len(pkg.Tests))
+	for i, t := range pkg.Tests {
+		t.Properties = copyProperties(t.Properties)
+		if j := findTestByName(existing, t.Name); j >= 0 {
+			for _, prop := range packageOrigin(pkg).properties() {
+				if v := propertyValue(existing[j].Properties, prop.Name); v != "" {
+					t.SetProperty(prop.Name, v)
+				}
+			}
+		}
+		addOrigin(&t, packageOrigin(pkg))
+		tests[i] = t
+	}
+	return tests
+}
+
+// propertyValue returns the value of the last property with the given name,
+// or an empty string if there is no such property.
+func propertyValue(props []Property, name string) string {
+	for i := len(props) - 1; i >= 0; i-- {
+		if props[i].Name == name {
+			return props[i].Value
+		}
+	}
+	return ""
+}
+
+func joinValue(values, value string) string {
+	if values == "" {
+		return value
+	}
+	for _, v := range strings.Split(values, ",") {
+		if v == value {
+			return values
+		}
+	}
+	return values + "," + value
+}
+
+// combineTests merges test from into test into, keeping the worst result.
+func combineTests(into *Test, from Test) {
+	into.Duration += from.Duration
+	into.RunDuration += from.RunDuration
+	into.WallDuration += from.WallDuration
+	into.StartTime = earliest(into.StartTime, from.StartTime)
+	into.EndTime = latest(into.EndTime, from.EndTime)
+	if resultSeverity(from.Result) > resultSeverity(into.Result) {
+		into.Result = from.Result
+	}
+	if into.SkipMessage == "" {
+		into.SkipMessage = from.SkipMessage
+	}
+	if into.FailureMessage == "" {
+		into.FailureMessage, into.FailureType = from.FailureMessage, from.FailureType
+	}
+	if into.File == "" {
+		into.File, into.Line = from.File, from.Line
+	}
+	into.Output = append(copyStrings(into.Output), from.Output...)
+	into.Stderr = append(copyStrings(into.Stderr), from.Stderr...)
+	into.Properties = copyProperties(into.Properties)
+	for _, prop := range from.Properties {
+		into.SetProperty(prop.Name, prop.Value)
+	}
+	into.Attachments = append(copyAttachments(into.Attachments), from.Attachments...)
+}
+
+// resultSeverity returns how bad result r is compared to other results.
+func resultSeverity(r Result) int {
+	switch r.Base() {
+	case Skip:
+		return 0
+	case Pass:
+		return 1
+	case Flaky:
+		return 2
+	case Unknown:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// Update returns a copy of report base in which the packages of report update
+// replace the packages with the same name, for example to update a report
+// with the results of rerunning some of its packages. Packages that only
+// appear in update are appended in the order they appear in update. The other
+// fields of the updated report, such as its hostname, are those of update.
+func Update(base, update Report) Report {
+	index := make(map[string]int) // package index by name in update
+	for i, pkg := range update.Packages {
+		index[pkg.Name] = i
+	}
+	updated := update
+	updated.Packages = nil
+	used := make(map[int]bool)
+	for _, pkg := range base.Packages {
+		if i, ok := index[pkg.Name]; ok {
+			if !used[i] {
+				updated.Packages = append(updated.Packages, copyPackage(update.Packages[i]))
+				used[i] = true
+			}
+			continue
+		}
+		updated.Packages = append(updated.Packages, copyPackage(pkg))
+	}
+	for i, pkg := range update.Packages {
+		if !used[i] {
+			updated.Packages = append(updated.Packages, copyPackage(pkg))
+		}
+	}
+	return updated
+}
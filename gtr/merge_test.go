@@ -0,0 +1,149 @@
+package gtr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMerge(t *testing.T) {
+	shard1 := Report{Packages: []Package{
+		{
+			Name:     "package/one",
+			Duration: 1 * time.Second,
+			Output:   []string{"shard 1"},
+			Tests: []Test{
+				{Name: "TestA", Duration: 100 * time.Millisecond, Result: Pass},
+				{Name: "TestB", Duration: 200 * time.Millisecond, Result: Fail, Output: []string{"b failed"}},
+				{Name: "TestC", Result: Skip},
+			},
+		},
+	}}
+	shard2 := Report{Packages: []Package{
+		{
+			Name:     "package/two",
+			Duration: 3 * time.Second,
+			Tests:    []Test{{Name: "TestD", Result: Pass}},
+		},
+		{
+			Name:     "package/one",
+			Duration: 2 * time.Second,
+			Output:   []string{"shard 2"},
+			Tests: []Test{
+				{Name: "TestA", Duration: 50 * time.Millisecond, Result: Fail, Output: []string{"a failed"}},
+				{Name: "TestB", Duration: 10 * time.Millisecond, Result: Pass},
+				{Name: "TestC", Result: Pass},
+				{Name: "TestE", Result: Pass},
+			},
+		},
+	}}
+
+	want := Report{Packages: []Package{
+		{
+			Name:     "package/one",
+			Duration: 3 * time.Second,
+			Output:   []string{"shard 1", "shard 2"},
+			Tests: []Test{
+				{Name: "TestA", Duration: 150 * time.Millisecond, Result: Fail, Output: []string{"a failed"}},
+				{Name: "TestB", Duration: 210 * time.Millisecond, Result: Fail, Output: []string{"b failed"}},
+				{Name: "TestC", Result: Pass},
+				{Name: "TestE", Result: Pass},
+			},
+		},
+		{
+			Name:     "package/two",
+			Duration: 3 * time.Second,
+			Tests:    []Test{{Name: "TestD", Result: Pass}},
+		},
+	}}
+
+	got := Merge(shard1, shard2)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Merge result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	if shard1.Packages[0].Tests[0].Result != Pass || len(shard1.Packages[0].Output) != 1 {
+		t.Errorf("Merge modified its input reports")
+	}
+}
+
+func TestMergeShards(t *testing.T) {
+	shard1 := Report{Hostname: "runner-1", ShardIndex: 0, ShardCount: 2, Packages: []Package{
+		{Name: "package/one", Tests: []Test{{Name: "TestA", Result: Pass}, {Name: "TestB", Result: Fail}}},
+		{Name: "package/two", Tests: []Test{{Name: "TestC", Result: Pass}}},
+	}}
+	shard2 := Report{Hostname: "runner-2", ShardIndex: 1, ShardCount: 2, Packages: []Package{
+		{Name: "package/one", Tests: []Test{{Name: "TestB", Result: Pass}, {Name: "TestD", Result: Fail}}},
+	}}
+
+	want := Report{Packages: []Package{
+		{
+			Name: "package/one",
+			Tests: []Test{
+				{Name: "TestA", Result: Pass, Properties: []Property{{"host.name", "runner-1"}, {"shard.index", "0"}, {"shard.count", "2"}}},
+				{Name: "TestB", Result: Fail, Properties: []Property{{"host.name", "runner-1,runner-2"}, {"shard.index", "0,1"}, {"shard.count", "2"}}},
+				{Name: "TestD", Result: Fail, Properties: []Property{{"host.name", "runner-2"}, {"shard.index", "1"}, {"shard.count", "2"}}},
+			},
+		},
+		{
+			Name:       "package/two",
+			Hostname:   "runner-1",
+			ShardIndex: 0,
+			ShardCount: 2,
+			Tests:      []Test{{Name: "TestC", Result: Pass}},
+		},
+	}}
+
+	got := Merge(shard1, shard2)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Merge result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if shard1.Packages[0].Tests[0].Properties != nil || shard2.Packages[0].Tests[0].Properties != nil {
+		t.Errorf("Merge modified its input reports")
+	}
+
+	same := Merge(Report{Hostname: "host", ShardIndex: 1, ShardCount: 2}, Report{Hostname: "host", ShardIndex: 1, ShardCount: 2})
+	if same.Hostname != "host" || same.ShardIndex != 1 || same.ShardCount != 2 {
+		t.Errorf("Merge of reports of the same shard = %q %d/%d, want host 1/2", same.Hostname, same.ShardIndex, same.ShardCount)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	old := Report{Packages: []Package{
+		{Name: "package/one", Tests: []Test{{Name: "TestA", Result: Fail}}},
+		{Name: "package/two", Tests: []Test{{Name: "TestB", Result: Pass}}},
+	}}
+	update := Report{Packages: []Package{
+		{Name: "package/three", Tests: []Test{{Name: "TestC", Result: Pass}}},
+		{Name: "package/one", Tests: []Test{{Name: "TestA", Result: Pass}}},
+	}}
+
+	want := Report{Packages: []Package{
+		{Name: "package/one", Tests: []Test{{Name: "TestA", Result: Pass}}},
+		{Name: "package/two", Tests: []Test{{Name: "TestB", Result: Pass}}},
+		{Name: "package/three", Tests: []Test{{Name: "TestC", Result: Pass}}},
+	}}
+	got := Update(old, update)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Update result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if old.Packages[0].Tests[0].Result != Fail {
+		t.Errorf("Update modified old report")
+	}
+}
+
+func TestMergeRunMeta(t *testing.T) {
+	passed := &RunMeta{ExitCode: 0}
+	failed := &RunMeta{ExitCode: 1}
+	killed := &RunMeta{ExitCode: -1, Signal: "killed"}
+
+	got := Merge(Report{RunMeta: passed}, Report{RunMeta: killed}, Report{RunMeta: failed}, Report{})
+	if diff := cmp.Diff(killed, got.RunMeta); diff != "" {
+		t.Errorf("Merge RunMeta incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	got = Merge(Report{}, Report{RunMeta: passed}, Report{RunMeta: failed})
+	if diff := cmp.Diff(failed, got.RunMeta); diff != "" {
+		t.Errorf("Merge RunMeta incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
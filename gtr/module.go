@@ -0,0 +1,85 @@
+package gtr
+
+import (
+	"sort"
+	"strings"
+)
+
+// ModuleProperty is the name of the package property containing the path of
+// the module a package belongs to, see GroupByModule.
+const ModuleProperty = "go.module"
+
+// ModuleOf returns the path of the module in modules that contains the
+// package with the given import path, or an empty string if none of them
+// contains it. When modules are nested, the innermost module is returned.
+func ModuleOf(pkg string, modules []string) string {
+	var module string
+	for _, m := range modules {
+		if (pkg == m || strings.HasPrefix(pkg, m+"/")) && len(m) > len(module) {
+			module = m
+		}
+	}
+	return module
+}
+
+// GroupByModule returns a copy of report r in which each package is marked
+// with a ModuleProperty containing the path of the module in modules that it
+// belongs to, for example the modules of a go.work workspace. The packages of
+// each module are moved next to each other, with the modules in the order in
+// which their first package appears. Packages that don't belong to any of
+// the modules don't get a ModuleProperty and are kept together in the same
+// way.
+func GroupByModule(r Report, modules []string) Report {
+	grouped := r
+	grouped.Packages = make([]Package, len(r.Packages))
+	order := make(map[string]int) // position of each module in the report
+	for i, pkg := range r.Packages {
+		module := ModuleOf(pkg.Name, modules)
+		if module != "" {
+			pkg.Properties = copyProperties(pkg.Properties)
+			pkg.SetProperty(ModuleProperty, module)
+		}
+		if _, ok := order[module]; !ok {
+			order[module] = len(order)
+		}
+		grouped.Packages[i] = pkg
+	}
+	sort.SliceStable(grouped.Packages, func(i, j int) bool {
+		return order[packageModule(grouped.Packages[i])] < order[packageModule(grouped.Packages[j])]
+	})
+	return grouped
+}
+
+// packageModule returns the module of pkg set by GroupByModule, or an empty
+// string if it's unknown.
+func packageModule(pkg Package) string {
+	return propertyValue(pkg.Properties, ModuleProperty)
+}
+
+// ModuleReport is the report of the packages of a single module.
+type ModuleReport struct {
+	Module string // module path, empty for packages of an unknown module
+	Report Report
+}
+
+// SplitByModule splits report r into a report for each module, using the
+// ModuleProperty of its packages set by GroupByModule. The reports appear in
+// the order in which the first package of their module appears in r, and
+// have the same hostname, shard and RunMeta as r.
+func SplitByModule(r Report) []ModuleReport {
+	var reports []ModuleReport
+	index := make(map[string]int) // index in reports by module
+	for _, pkg := range r.Packages {
+		module := packageModule(pkg)
+		i, ok := index[module]
+		if !ok {
+			i = len(reports)
+			index[module] = i
+			mr := ModuleReport{Module: module, Report: r}
+			mr.Report.Packages = nil
+			reports = append(reports, mr)
+		}
+		reports[i].Report.Packages = append(reports[i].Report.Packages, pkg)
+	}
+	return reports
+}
@@ -0,0 +1,61 @@
+package gtr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestModuleOf(t *testing.T) {
+	modules := []string{"example.com/repo", "example.com/repo/tools", "example.com/other"}
+	tests := map[string]string{
+		"example.com/repo":             "example.com/repo",
+		"example.com/repo/pkg":         "example.com/repo",
+		"example.com/repo/tools/lint":  "example.com/repo/tools",
+		"example.com/repository/pkg":   "",
+		"example.com/other/cmd/server": "example.com/other",
+		"golang.org/x/tools":           "",
+	}
+	for pkg, want := range tests {
+		if got := ModuleOf(pkg, modules); got != want {
+			t.Errorf("ModuleOf(%q) = %q, want %q", pkg, got, want)
+		}
+	}
+}
+
+func TestGroupByModule(t *testing.T) {
+	modules := []string{"example.com/api", "example.com/web"}
+	report := Report{Hostname: "host", Packages: []Package{
+		{Name: "example.com/api/handlers"},
+		{Name: "example.com/web/pages"},
+		{Name: "example.com/tools"},
+		{Name: "example.com/api/store"},
+		{Name: "example.com/web/assets"},
+	}}
+
+	module := func(path string) []Property { return []Property{{Name: ModuleProperty, Value: path}} }
+	got := GroupByModule(report, modules)
+	want := Report{Hostname: "host", Packages: []Package{
+		{Name: "example.com/api/handlers", Properties: module("example.com/api")},
+		{Name: "example.com/api/store", Properties: module("example.com/api")},
+		{Name: "example.com/web/pages", Properties: module("example.com/web")},
+		{Name: "example.com/web/assets", Properties: module("example.com/web")},
+		{Name: "example.com/tools"},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GroupByModule result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if report.Packages[0].Properties != nil {
+		t.Errorf("GroupByModule modified its input report")
+	}
+
+	split := SplitByModule(got)
+	wantSplit := []ModuleReport{
+		{"example.com/api", Report{Hostname: "host", Packages: want.Packages[0:2]}},
+		{"example.com/web", Report{Hostname: "host", Packages: want.Packages[2:4]}},
+		{"", Report{Hostname: "host", Packages: want.Packages[4:]}},
+	}
+	if diff := cmp.Diff(wantSplit, split); diff != "" {
+		t.Errorf("SplitByModule result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
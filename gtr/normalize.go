@@ -0,0 +1,128 @@
+package gtr
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"math"
+	"sort"
+)
+
+// Normalize returns a copy of report r without any of the information that
+// typically changes between runs of the same tests. Timestamps, durations and
+// IDs are cleared and packages, tests and properties are sorted by name.
+// Output is preserved as is.
+func (r Report) Normalize() Report {
+	var normalized Report
+	for _, pkg := range r.Packages {
+		p := Package{
+			Name:       pkg.Name,
+			Coverage:   pkg.Coverage,
+			Output:     copyStrings(pkg.Output),
+			Properties: normalizeProperties(pkg.Properties),
+			BuildError: normalizeError(pkg.BuildError),
+			RunError:   normalizeError(pkg.RunError),
+		}
+		for _, test := range pkg.Tests {
+			p.Tests = append(p.Tests, Test{
+				Name:       test.Name,
+				Result:     test.Result,
+				Level:      test.Level,
+				Output:     copyStrings(test.Output),
+				Properties: normalizeProperties(test.Properties),
+				Data:       test.Data,
+			})
+		}
+		sort.SliceStable(p.Tests, func(i, j int) bool {
+			if p.Tests[i].Name != p.Tests[j].Name {
+				return p.Tests[i].Name < p.Tests[j].Name
+			}
+			return p.Tests[i].Result < p.Tests[j].Result
+		})
+		normalized.Packages = append(normalized.Packages, p)
+	}
+	sort.SliceStable(normalized.Packages, func(i, j int) bool {
+		return normalized.Packages[i].Name < normalized.Packages[j].Name
+	})
+	return normalized
+}
+
+// ContentHash returns a hex encoded SHA-256 digest of the normalized report,
+// see Normalize. Reports containing the same packages and tests with the same
+// results have the same hash, regardless of their order, timing or output.
+//
+// Only the following fields contribute to the hash: the package name,
+// coverage, properties, build error (name and cause only), runtime error
+// (name, kind and cause only), and for each test its name, result, level and
+// properties.
+func (r Report) ContentHash() string {
+	h := sha256.New()
+	for _, pkg := range r.Normalize().Packages {
+		writeHashString(h, pkg.Name)
+		writeHashUint(h, math.Float64bits(pkg.Coverage))
+		writeHashProperties(h, pkg.Properties)
+		writeHashString(h, pkg.BuildError.Name)
+		writeHashString(h, pkg.BuildError.Cause)
+		writeHashString(h, pkg.RunError.Name)
+		writeHashString(h, pkg.RunError.Kind)
+		writeHashString(h, pkg.RunError.Cause)
+		writeHashUint(h, uint64(len(pkg.Tests)))
+		for _, test := range pkg.Tests {
+			writeHashString(h, test.Name)
+			writeHashString(h, test.Result.String())
+			writeHashUint(h, uint64(test.Level))
+			writeHashProperties(h, test.Properties)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func normalizeError(e Error) Error {
+	return Error{Name: e.Name, Kind: e.Kind, Cause: e.Cause, Output: copyStrings(e.Output)}
+}
+
+func normalizeProperties(props []Property) []Property {
+	if props == nil {
+		return nil
+	}
+	sorted := make([]Property, len(props))
+	copy(sorted, props)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].Value < sorted[j].Value
+	})
+	return sorted
+}
+
+func copyStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	c := make([]string, len(s))
+	copy(c, s)
+	return c
+}
+
+// writeHashString writes s to h prefixed by its length, to make sure that
+// different sequences of strings always result in a different hash.
+func writeHashString(h hash.Hash, s string) {
+	writeHashUint(h, uint64(len(s)))
+	h.Write([]byte(s)) // ignore error, never fails
+}
+
+func writeHashUint(h hash.Hash, n uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], n)
+	h.Write(b[:]) // ignore error, never fails
+}
+
+func writeHashProperties(h hash.Hash, props []Property) {
+	writeHashUint(h, uint64(len(props)))
+	for _, prop := range props {
+		writeHashString(h, prop.Name)
+		writeHashString(h, prop.Value)
+	}
+}
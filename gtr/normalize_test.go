@@ -0,0 +1,113 @@
+package gtr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNormalize(t *testing.T) {
+	report := Report{
+		Packages: []Package{
+			{
+				Name:      "package/b",
+				Timestamp: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+				Duration:  time.Second,
+				Output:    []string{"output"},
+				Tests: []Test{
+					{ID: 1, Name: "TestTwo", Duration: time.Millisecond, Result: Fail},
+					{ID: 2, Name: "TestOne", Duration: time.Millisecond, Result: Pass},
+				},
+			},
+			{
+				Name:       "package/a",
+				Properties: []Property{{Name: "z", Value: "1"}, {Name: "a", Value: "2"}},
+				BuildError: Error{ID: 3, Name: "package/a", Duration: time.Second, Cause: "[build failed]"},
+			},
+		},
+	}
+
+	want := Report{
+		Packages: []Package{
+			{
+				Name:       "package/a",
+				Properties: []Property{{Name: "a", Value: "2"}, {Name: "z", Value: "1"}},
+				BuildError: Error{Name: "package/a", Cause: "[build failed]"},
+			},
+			{
+				Name:   "package/b",
+				Output: []string{"output"},
+				Tests: []Test{
+					{Name: "TestOne", Result: Pass},
+					{Name: "TestTwo", Result: Fail},
+				},
+			},
+		},
+	}
+
+	got := report.Normalize()
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Normalize incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if report.Packages[0].Name != "package/b" || report.Packages[0].Tests[0].ID != 1 {
+		t.Errorf("Normalize modified the original report")
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	a := Report{
+		Packages: []Package{
+			{
+				Name:     "package/one",
+				Duration: time.Second,
+				Tests: []Test{
+					{ID: 1, Name: "TestA", Result: Pass, Duration: time.Millisecond},
+					{ID: 2, Name: "TestB", Result: Fail, Output: []string{"took 1ms"}},
+				},
+			},
+			{Name: "package/two", Tests: []Test{{ID: 3, Name: "TestC", Result: Skip}}},
+		},
+	}
+	reordered := Report{
+		Packages: []Package{
+			{Name: "package/two", Tests: []Test{{ID: 1, Name: "TestC", Result: Skip}}},
+			{
+				Name:      "package/one",
+				Timestamp: time.Now(),
+				Duration:  2 * time.Second,
+				Tests: []Test{
+					{ID: 2, Name: "TestB", Result: Fail, Output: []string{"took 2ms"}},
+					{ID: 3, Name: "TestA", Result: Pass, Duration: 2 * time.Millisecond},
+				},
+			},
+		},
+	}
+	changed := Report{
+		Packages: []Package{
+			{
+				Name: "package/one",
+				Tests: []Test{
+					{Name: "TestA", Result: Pass},
+					{Name: "TestB", Result: Pass},
+				},
+			},
+			{Name: "package/two", Tests: []Test{{Name: "TestC", Result: Skip}}},
+		},
+	}
+
+	if a.ContentHash() != reordered.ContentHash() {
+		t.Errorf("ContentHash differs for reordered reports")
+	}
+	if a.ContentHash() == changed.ContentHash() {
+		t.Errorf("ContentHash is equal for reports with different results")
+	}
+	infra := Report{Packages: []Package{{Name: "package/one", RunError: Error{Name: "package/one", Kind: ErrorKindInfra}}}}
+	failure := Report{Packages: []Package{{Name: "package/one", RunError: Error{Name: "package/one"}}}}
+	if infra.ContentHash() == failure.ContentHash() {
+		t.Errorf("ContentHash is equal for reports with different run error kinds")
+	}
+	if got := len(a.ContentHash()); got != 64 {
+		t.Errorf("ContentHash has incorrect length, got %d want 64", got)
+	}
+}
@@ -0,0 +1,70 @@
+package gtr
+
+import "fmt"
+
+// NoTestFilesMode determines how packages without test files, which go test
+// reports as "[no test files]", are included in a report, see
+// HandleNoTestFiles.
+type NoTestFilesMode string
+
+const (
+	// NoTestFilesInclude includes packages without test files as packages
+	// without tests, which is the default.
+	NoTestFilesInclude NoTestFilesMode = "include"
+
+	// NoTestFilesOmit removes packages without test files from the report.
+	NoTestFilesOmit NoTestFilesMode = "omit"
+
+	// NoTestFilesSkip includes packages without test files with a single
+	// skipped placeholder test named NoTestFilesTest, for consumers that
+	// ignore packages without tests.
+	NoTestFilesSkip NoTestFilesMode = "skip"
+)
+
+// NoTestFilesTest is the name of the placeholder test added to packages
+// without test files by NoTestFilesSkip.
+const NoTestFilesTest = "NoTestFiles"
+
+// ParseNoTestFilesMode returns the NoTestFilesMode with the given name. An
+// empty name returns NoTestFilesInclude.
+func ParseNoTestFilesMode(name string) (NoTestFilesMode, error) {
+	switch m := NoTestFilesMode(name); m {
+	case "":
+		return NoTestFilesInclude, nil
+	case NoTestFilesInclude, NoTestFilesOmit, NoTestFilesSkip:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown no test files mode: %s", name)
+	}
+}
+
+// HandleNoTestFiles returns a copy of report r in which the packages without
+// test files are included according to mode. Packages without test files that
+// contain tests or errors, e.g. because they were merged with another report,
+// are left unchanged.
+func HandleNoTestFiles(r Report, mode NoTestFilesMode) Report {
+	if mode == "" || mode == NoTestFilesInclude {
+		return r
+	}
+
+	handled := r
+	handled.Packages = nil
+	for _, pkg := range r.Packages {
+		if !pkg.NoTestFiles || len(pkg.Tests) > 0 || pkg.BuildError.Name != "" || pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			handled.Packages = append(handled.Packages, pkg)
+			continue
+		}
+		switch mode {
+		case NoTestFilesOmit:
+			continue
+		case NoTestFilesSkip:
+			t := NewTest(0, NoTestFilesTest)
+			t.Result = Skip
+			t.SkipMessage = "no test files"
+			t.StartTime, t.EndTime = pkg.StartTime, pkg.StartTime
+			pkg.Tests = []Test{t}
+		}
+		handled.Packages = append(handled.Packages, pkg)
+	}
+	return handled
+}
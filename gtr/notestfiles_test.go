@@ -0,0 +1,51 @@
+package gtr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHandleNoTestFiles(t *testing.T) {
+	report := Report{Packages: []Package{
+		{Name: "package/tests", Tests: []Test{{Name: "TestOne", Result: Pass}}},
+		{Name: "package/notests", NoTestFiles: true},
+		{Name: "package/empty"},
+		{Name: "package/infra", NoTestFiles: true, RunError: Error{Kind: ErrorKindInfra, Cause: "socket: too many open files"}},
+	}}
+	placeholder := NewTest(0, NoTestFilesTest)
+	placeholder.Result = Skip
+	placeholder.SkipMessage = "no test files"
+
+	tests := []struct {
+		mode NoTestFilesMode
+		want []Package
+	}{
+		{NoTestFilesInclude, report.Packages},
+		{NoTestFilesOmit, []Package{report.Packages[0], report.Packages[2], report.Packages[3]}},
+		{NoTestFilesSkip, []Package{
+			report.Packages[0],
+			{Name: "package/notests", NoTestFiles: true, Tests: []Test{placeholder}},
+			report.Packages[2],
+			report.Packages[3],
+		}},
+	}
+	for _, test := range tests {
+		got := HandleNoTestFiles(report, test.mode)
+		if diff := cmp.Diff(test.want, got.Packages); diff != "" {
+			t.Errorf("HandleNoTestFiles(%q) incorrect, diff (-want +got):\n%s\n", test.mode, diff)
+		}
+	}
+}
+
+func TestParseNoTestFilesMode(t *testing.T) {
+	if got, err := ParseNoTestFilesMode(""); err != nil || got != NoTestFilesInclude {
+		t.Errorf("ParseNoTestFilesMode(\"\") = %q, %v; want %q", got, err, NoTestFilesInclude)
+	}
+	if got, err := ParseNoTestFilesMode("skip"); err != nil || got != NoTestFilesSkip {
+		t.Errorf("ParseNoTestFilesMode(\"skip\") = %q, %v; want %q", got, err, NoTestFilesSkip)
+	}
+	if _, err := ParseNoTestFilesMode("hide"); err == nil {
+		t.Errorf("ParseNoTestFilesMode(\"hide\") did not return an error")
+	}
+}
@@ -0,0 +1,45 @@
+package gtr
+
+import (
+	"sort"
+	"strings"
+)
+
+// ReorderTests returns a copy of report r in which the tests of each package
+// are ordered by their position in the given order. Subtests that are not
+// listed themselves are placed at the position of their top-level test. Tests
+// that are not listed at all are moved to the end, keeping their original
+// relative order.
+func ReorderTests(r Report, order []string) Report {
+	index := make(map[string]int, len(order))
+	for i, name := range order {
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+
+	position := func(name string) int {
+		if i, ok := index[name]; ok {
+			return i
+		}
+		if idx := strings.IndexByte(name, '/'); idx >= 0 {
+			if i, ok := index[name[:idx]]; ok {
+				return i
+			}
+		}
+		return len(order)
+	}
+
+	reordered := r
+	reordered.Packages = make([]Package, len(r.Packages))
+	for i, pkg := range r.Packages {
+		tests := make([]Test, len(pkg.Tests))
+		copy(tests, pkg.Tests)
+		sort.SliceStable(tests, func(i, j int) bool {
+			return position(tests[i].Name) < position(tests[j].Name)
+		})
+		pkg.Tests = tests
+		reordered.Packages[i] = pkg
+	}
+	return reordered
+}
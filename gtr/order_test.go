@@ -0,0 +1,55 @@
+package gtr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReorderTests(t *testing.T) {
+	report := Report{Packages: []Package{
+		{
+			Name: "package/one",
+			Tests: []Test{
+				{Name: "TestUnlisted1"},
+				{Name: "TestC"},
+				{Name: "TestA"},
+				{Name: "TestA/sub"},
+				{Name: "TestUnlisted2"},
+				{Name: "TestB"},
+			},
+		},
+		{
+			Name:  "package/two",
+			Tests: []Test{{Name: "TestB"}, {Name: "TestA"}},
+		},
+	}}
+	order := []string{"TestA", "TestB", "TestC"}
+
+	want := Report{Packages: []Package{
+		{
+			Name: "package/one",
+			Tests: []Test{
+				{Name: "TestA"},
+				{Name: "TestA/sub"},
+				{Name: "TestB"},
+				{Name: "TestC"},
+				{Name: "TestUnlisted1"},
+				{Name: "TestUnlisted2"},
+			},
+		},
+		{
+			Name:  "package/two",
+			Tests: []Test{{Name: "TestA"}, {Name: "TestB"}},
+		},
+	}}
+
+	got := ReorderTests(report, order)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ReorderTests result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	if report.Packages[0].Tests[0].Name != "TestUnlisted1" {
+		t.Errorf("ReorderTests modified the original report")
+	}
+}
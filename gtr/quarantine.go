@@ -0,0 +1,96 @@
+package gtr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// QuarantineRule quarantines the tests whose name matches Pattern. Reason
+// optionally explains why the tests were quarantined, e.g. with a link to an
+// issue.
+type QuarantineRule struct {
+	Pattern *regexp.Regexp
+	Reason  string
+}
+
+// Matches returns true if rule q matches the test with the given name. A rule
+// that matches a test also matches all of its subtests.
+func (q QuarantineRule) Matches(name string) bool {
+	for {
+		if q.Pattern.MatchString(name) {
+			return true
+		}
+		idx := strings.LastIndexByte(name, '/')
+		if idx < 0 {
+			return false
+		}
+		name = name[:idx]
+	}
+}
+
+// ParseQuarantine parses a quarantine list from the given io.Reader r. Each
+// line contains a test name pattern, optionally followed by whitespace and
+// the reason the tests were quarantined. Patterns are regular expressions that
+// must match the entire test name. Empty lines and lines starting with # are
+// ignored.
+func ParseQuarantine(r io.Reader) ([]QuarantineRule, error) {
+	var rules []QuarantineRule
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		expr, reason := line, ""
+		if idx := strings.IndexAny(line, " \t"); idx >= 0 {
+			expr, reason = line[:idx], strings.TrimSpace(line[idx:])
+		}
+		pattern, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern on line %d: %w", n, err)
+		}
+		rules = append(rules, QuarantineRule{Pattern: pattern, Reason: reason})
+	}
+	return rules, s.Err()
+}
+
+// Quarantine returns a copy of report r in which the tests matching one of the
+// given rules are quarantined, so that they no longer fail the report.
+// Quarantined tests that failed or have no result are marked as skipped, with
+// a skip message containing the reason of the rule. Every quarantined test is
+// marked with a "quarantined" property and its actual result is recorded in
+// the "quarantine.result" property, e.g. "fail".
+func Quarantine(r Report, rules []QuarantineRule) Report {
+	if len(rules) == 0 {
+		return r
+	}
+	return r.Map(func(t Test) Test {
+		for _, rule := range rules {
+			if rule.Matches(t.Name) {
+				return quarantineTest(t, rule)
+			}
+		}
+		return t
+	})
+}
+
+func quarantineTest(t Test, rule QuarantineRule) Test {
+	t.Properties = copyProperties(t.Properties)
+	t.SetProperty("quarantined", "true")
+	t.SetProperty("quarantine.result", strings.ToLower(t.Result.String()))
+	if rule.Reason != "" {
+		t.SetProperty("quarantine.reason", rule.Reason)
+	}
+	if t.Result.Base() == Fail || t.Result == Unknown {
+		t.Result = Skip
+		t.SkipMessage = "quarantined"
+		if rule.Reason != "" {
+			t.SkipMessage += ": " + rule.Reason
+		}
+		t.FailureMessage, t.FailureType = "", ""
+	}
+	return t
+}
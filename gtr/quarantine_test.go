@@ -0,0 +1,115 @@
+package gtr
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseQuarantine(t *testing.T) {
+	input := `# flaky tests
+TestFlaky   https://example.com/issues/1
+
+TestNetwork.*
+`
+	rules, err := ParseQuarantine(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseQuarantine error: %v", err)
+	}
+
+	type rule struct{ Pattern, Reason string }
+	want := []rule{
+		{"^(?:TestFlaky)$", "https://example.com/issues/1"},
+		{"^(?:TestNetwork.*)$", ""},
+	}
+	var got []rule
+	for _, r := range rules {
+		got = append(got, rule{r.Pattern.String(), r.Reason})
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseQuarantine result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	if _, err := ParseQuarantine(strings.NewReader("Test(")); err == nil {
+		t.Errorf("ParseQuarantine did not return an error for an invalid pattern")
+	}
+}
+
+func TestQuarantineRuleMatches(t *testing.T) {
+	rule := QuarantineRule{Pattern: regexp.MustCompile("^(?:TestFlaky)$")}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"TestFlaky", true},
+		{"TestFlaky/subtest", true},
+		{"TestFlaky/subtest/nested", true},
+		{"TestFlakyOther", false},
+		{"TestOther/TestFlaky", false},
+	}
+	for _, test := range tests {
+		if got := rule.Matches(test.name); got != test.want {
+			t.Errorf("Matches(%q) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestQuarantine(t *testing.T) {
+	report := Report{Packages: []Package{
+		{Name: "package/name", Tests: []Test{
+			{Name: "TestFlaky", Result: Fail, FailureMessage: "timeout", Output: []string{"output"}},
+			{Name: "TestFlaky/subtest", Result: Unknown},
+			{Name: "TestNetwork", Result: Pass},
+			{Name: "TestOther", Result: Fail},
+		}},
+	}}
+	rules := []QuarantineRule{
+		{Pattern: regexp.MustCompile("^(?:TestFlaky)$"), Reason: "issue 1"},
+		{Pattern: regexp.MustCompile("^(?:TestNetwork)$")},
+	}
+
+	want := Report{Packages: []Package{
+		{Name: "package/name", Tests: []Test{
+			{
+				Name:        "TestFlaky",
+				Result:      Skip,
+				SkipMessage: "quarantined: issue 1",
+				Output:      []string{"output"},
+				Properties: []Property{
+					{Name: "quarantined", Value: "true"},
+					{Name: "quarantine.result", Value: "fail"},
+					{Name: "quarantine.reason", Value: "issue 1"},
+				},
+			},
+			{
+				Name:        "TestFlaky/subtest",
+				Result:      Skip,
+				SkipMessage: "quarantined: issue 1",
+				Properties: []Property{
+					{Name: "quarantined", Value: "true"},
+					{Name: "quarantine.result", Value: "unknown"},
+					{Name: "quarantine.reason", Value: "issue 1"},
+				},
+			},
+			{
+				Name:   "TestNetwork",
+				Result: Pass,
+				Properties: []Property{
+					{Name: "quarantined", Value: "true"},
+					{Name: "quarantine.result", Value: "pass"},
+				},
+			},
+			{Name: "TestOther", Result: Fail},
+		}},
+	}}
+
+	got := Quarantine(report, rules)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Quarantine result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if report.Packages[0].Tests[0].Result != Fail {
+		t.Errorf("Quarantine modified the original report")
+	}
+}
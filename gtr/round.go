@@ -0,0 +1,36 @@
+package gtr
+
+import "time"
+
+// RoundDurations returns a copy of report r in which the durations of all
+// packages, tests, attempts and errors have been rounded to the nearest
+// multiple of precision, for example to remove the jitter of durations that
+// hardly change between runs. A precision of 0 returns r unchanged.
+func RoundDurations(r Report, precision time.Duration) Report {
+	if precision <= 0 {
+		return r
+	}
+	round := func(d time.Duration) time.Duration { return d.Round(precision) }
+	rounded := r.Map(func(t Test) Test {
+		t.Duration = round(t.Duration)
+		t.RunDuration = round(t.RunDuration)
+		t.WallDuration = round(t.WallDuration)
+		if t.Attempts != nil {
+			attempts := make([]TestAttempt, len(t.Attempts))
+			for i, a := range t.Attempts {
+				a.Duration = round(a.Duration)
+				attempts[i] = a
+			}
+			t.Attempts = attempts
+		}
+		return t
+	})
+	for i := range rounded.Packages {
+		pkg := &rounded.Packages[i]
+		pkg.Duration = round(pkg.Duration)
+		pkg.BuildDuration = round(pkg.BuildDuration)
+		pkg.BuildError.Duration = round(pkg.BuildError.Duration)
+		pkg.RunError.Duration = round(pkg.RunError.Duration)
+	}
+	return rounded
+}
@@ -0,0 +1,48 @@
+package gtr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRoundDurations(t *testing.T) {
+	report := Report{Packages: []Package{{
+		Name:          "package/name",
+		Duration:      1234 * time.Millisecond,
+		BuildDuration: 4 * time.Millisecond,
+		Tests: []Test{{
+			Name:         "TestA",
+			Duration:     15 * time.Millisecond,
+			RunDuration:  14999 * time.Microsecond,
+			WallDuration: 25001 * time.Microsecond,
+			Attempts:     []TestAttempt{{Result: Fail, Duration: 6 * time.Millisecond}},
+		}},
+		RunError: Error{Name: "package/name", Duration: 99 * time.Millisecond},
+	}}}
+
+	got := RoundDurations(report, 10*time.Millisecond)
+	want := Report{Packages: []Package{{
+		Name:          "package/name",
+		Duration:      1230 * time.Millisecond,
+		BuildDuration: 0,
+		Tests: []Test{{
+			Name:         "TestA",
+			Duration:     20 * time.Millisecond,
+			RunDuration:  10 * time.Millisecond,
+			WallDuration: 30 * time.Millisecond,
+			Attempts:     []TestAttempt{{Result: Fail, Duration: 10 * time.Millisecond}},
+		}},
+		RunError: Error{Name: "package/name", Duration: 100 * time.Millisecond},
+	}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("RoundDurations result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if report.Packages[0].Tests[0].Attempts[0].Duration != 6*time.Millisecond {
+		t.Errorf("RoundDurations modified its input report")
+	}
+	if diff := cmp.Diff(report, RoundDurations(report, 0)); diff != "" {
+		t.Errorf("RoundDurations with precision 0 changed the report, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,211 @@
+package gtr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Rule classifies the tests it matches. A test matches a rule when it matches
+// all of the rule's conditions: Package, Test and Output must match the
+// package name, test name and output of the test, and Results must contain
+// its result. Conditions that are nil or empty match every test.
+//
+// A matching test is given Result, if set, and is labeled with Labels and
+// Owner. Every test matching a rule is marked with a "rule" property
+// containing the rule's Name.
+type Rule struct {
+	Name    string
+	Package *regexp.Regexp
+	Test    *regexp.Regexp
+	Output  *regexp.Regexp
+	Results []Result
+
+	Result *Result
+	Labels []string
+	Owner  string
+}
+
+// Matches returns true if rule r matches test t of the package with the
+// given name. The output of a test consists of its output lines and its
+// failure message.
+func (r Rule) Matches(pkgName string, t Test) bool {
+	if r.Package != nil && !r.Package.MatchString(pkgName) {
+		return false
+	}
+	if r.Test != nil && !r.Test.MatchString(t.Name) {
+		return false
+	}
+	if len(r.Results) > 0 && !containsResult(r.Results, t.Result) {
+		return false
+	}
+	if r.Output != nil {
+		output := strings.Join(t.Output, "\n")
+		if t.FailureMessage != "" {
+			output += "\n" + t.FailureMessage
+		}
+		if !r.Output.MatchString(output) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsResult(results []Result, r Result) bool {
+	for _, result := range results {
+		if result == r {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonRule is the JSON representation of a Rule.
+type jsonRule struct {
+	Name  string `json:"name"`
+	Match struct {
+		Package string   `json:"package"`
+		Test    string   `json:"test"`
+		Output  string   `json:"output"`
+		Results []Result `json:"results"`
+	} `json:"match"`
+	Result *Result  `json:"result"`
+	Labels []string `json:"labels"`
+	Owner  string   `json:"owner"`
+}
+
+// ParseRules parses a list of rules in JSON read from r. The rules are an
+// array of objects, for example:
+//
+//	[
+//	  {
+//	    "name": "database",
+//	    "match": {"package": "^example.com/db(/|$)", "results": ["fail"]},
+//	    "owner": "db-team"
+//	  },
+//	  {
+//	    "name": "network",
+//	    "match": {"output": "dial tcp .*: connection refused"},
+//	    "result": "skip",
+//	    "labels": ["infra"]
+//	  }
+//	]
+//
+// The package, test and output conditions are regular expressions, which match
+// when they match any part of the package name, test name or output. Rules
+// without a name are named after their position in the list, starting at 1.
+func ParseRules(r io.Reader) ([]Rule, error) {
+	var parsed []jsonRule
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&parsed); err != nil {
+		return nil, err
+	}
+
+	rules := make([]Rule, len(parsed))
+	for i, pr := range parsed {
+		rule := Rule{
+			Name:    pr.Name,
+			Results: pr.Match.Results,
+			Result:  pr.Result,
+			Labels:  pr.Labels,
+			Owner:   pr.Owner,
+		}
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("%d", i+1)
+		}
+		var err error
+		if rule.Package, err = compileCondition(pr.Match.Package); err != nil {
+			return nil, fmt.Errorf("rule %s: invalid package pattern: %w", rule.Name, err)
+		}
+		if rule.Test, err = compileCondition(pr.Match.Test); err != nil {
+			return nil, fmt.Errorf("rule %s: invalid test pattern: %w", rule.Name, err)
+		}
+		if rule.Output, err = compileCondition(pr.Match.Output); err != nil {
+			return nil, fmt.Errorf("rule %s: invalid output pattern: %w", rule.Name, err)
+		}
+		rules[i] = rule
+	}
+	return rules, nil
+}
+
+// compileCondition compiles expr, returning nil if expr is empty.
+func compileCondition(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
+
+// ApplyRules returns a copy of report r in which the tests matching the given
+// rules have been classified. Rules are applied in order and all matching
+// rules are applied, so when more than one matching rule sets a result or
+// owner, the last one wins. Rules are matched against the tests as they were
+// before any rule was applied.
+//
+// A test whose result is changed by a rule is given the "rule.result"
+// property containing its original result, e.g. "fail". Owners and labels
+// are added as the "owner" and "label" properties.
+func ApplyRules(r Report, rules []Rule) Report {
+	if len(rules) == 0 {
+		return r
+	}
+	classified := r
+	classified.Packages = make([]Package, len(r.Packages))
+	for i, pkg := range r.Packages {
+		if pkg.Tests != nil {
+			tests := make([]Test, len(pkg.Tests))
+			for j, test := range pkg.Tests {
+				tests[j] = applyRules(pkg.Name, test, rules)
+			}
+			pkg.Tests = tests
+		}
+		classified.Packages[i] = pkg
+	}
+	return classified
+}
+
+func applyRules(pkgName string, t Test, rules []Rule) Test {
+	orig := t
+	copied := false
+	for _, rule := range rules {
+		if !rule.Matches(pkgName, orig) {
+			continue
+		}
+		if !copied {
+			t.Properties = copyProperties(t.Properties)
+			copied = true
+		}
+		t.AddProperty("rule", rule.Name)
+		if rule.Result != nil && *rule.Result != t.Result {
+			t.SetProperty("rule.result", strings.ToLower(orig.Result.String()))
+			t.Result = *rule.Result
+			if t.Result.Base() != Fail {
+				t.FailureMessage, t.FailureType = "", ""
+			}
+			if t.Result.Base() == Skip && t.SkipMessage == "" {
+				t.SkipMessage = "classified by rule " + rule.Name
+			}
+		}
+		if rule.Owner != "" {
+			t.SetProperty("owner", rule.Owner)
+		}
+		for _, label := range rule.Labels {
+			if !hasProperty(t.Properties, "label", label) {
+				t.AddProperty("label", label)
+			}
+		}
+	}
+	return t
+}
+
+func hasProperty(props []Property, name, value string) bool {
+	for _, prop := range props {
+		if prop.Name == name && prop.Value == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package gtr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseRules(t *testing.T) {
+	input := `[
+	{"name": "db", "match": {"package": "/db$", "results": ["fail", "unknown"]}, "owner": "db-team"},
+	{"match": {"output": "refused"}, "result": "skip", "labels": ["infra", "network"]}
+]`
+	rules, err := ParseRules(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseRules error: %v", err)
+	}
+
+	skip := Skip
+	type rule struct {
+		Name, Package, Output string
+		Results               []Result
+		Result                *Result
+		Labels                []string
+		Owner                 string
+	}
+	want := []rule{
+		{Name: "db", Package: "/db$", Results: []Result{Fail, Unknown}, Owner: "db-team"},
+		{Name: "2", Output: "refused", Result: &skip, Labels: []string{"infra", "network"}},
+	}
+	var got []rule
+	for _, r := range rules {
+		if r.Test != nil {
+			t.Errorf("rule %s has test pattern %q, want none", r.Name, r.Test)
+		}
+		pr := rule{Name: r.Name, Results: r.Results, Result: r.Result, Labels: r.Labels, Owner: r.Owner}
+		if r.Package != nil {
+			pr.Package = r.Package.String()
+		}
+		if r.Output != nil {
+			pr.Output = r.Output.String()
+		}
+		got = append(got, pr)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseRules result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	invalid := []string{
+		`[{"match": {"test": "Test("}}]`,
+		`[{"match": {"results": ["broken"]}}]`,
+		`[{"owner": "team", "unknown": true}]`,
+		`{"name": "not a list"}`,
+	}
+	for _, in := range invalid {
+		if _, err := ParseRules(strings.NewReader(in)); err == nil {
+			t.Errorf("ParseRules(%q) did not return an error", in)
+		}
+	}
+}
+
+func TestApplyRules(t *testing.T) {
+	rules, err := ParseRules(strings.NewReader(`[
+	{"name": "db", "match": {"package": "/db$"}, "owner": "db-team"},
+	{"name": "network", "match": {"output": "connection refused", "results": ["fail"]}, "result": "skip", "labels": ["infra"]},
+	{"name": "slow", "match": {"test": "^TestSlow"}, "owner": "perf-team", "labels": ["infra"]}
+]`))
+	if err != nil {
+		t.Fatalf("ParseRules error: %v", err)
+	}
+
+	report := Report{Packages: []Package{
+		{
+			Name: "example.com/app/db",
+			Tests: []Test{
+				{Name: "TestQuery", Result: Fail, FailureMessage: "dial: connection refused"},
+				{Name: "TestSlowQuery", Result: Pass, Output: []string{"connection refused"}},
+			},
+		},
+		{
+			Name:  "example.com/app/api",
+			Tests: []Test{{Name: "TestHandler", Result: Fail, Output: []string{"assertion failed"}}},
+		},
+	}}
+
+	want := Report{Packages: []Package{
+		{
+			Name: "example.com/app/db",
+			Tests: []Test{
+				{
+					Name:        "TestQuery",
+					Result:      Skip,
+					SkipMessage: "classified by rule network",
+					Properties: []Property{
+						{Name: "rule", Value: "db"},
+						{Name: "owner", Value: "db-team"},
+						{Name: "rule", Value: "network"},
+						{Name: "rule.result", Value: "fail"},
+						{Name: "label", Value: "infra"},
+					},
+				},
+				{
+					Name:   "TestSlowQuery",
+					Result: Pass,
+					Output: []string{"connection refused"},
+					Properties: []Property{
+						{Name: "rule", Value: "db"},
+						{Name: "rule", Value: "slow"},
+						{Name: "owner", Value: "perf-team"},
+						{Name: "label", Value: "infra"},
+					},
+				},
+			},
+		},
+		{
+			Name:  "example.com/app/api",
+			Tests: []Test{{Name: "TestHandler", Result: Fail, Output: []string{"assertion failed"}}},
+		},
+	}}
+	got := ApplyRules(report, rules)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ApplyRules result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if report.Packages[0].Tests[0].Result != Fail {
+		t.Errorf("ApplyRules modified the original report")
+	}
+}
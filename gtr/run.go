@@ -0,0 +1,97 @@
+package gtr
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// RunMeta describes how the go test process that produced a report exited.
+type RunMeta struct {
+	ExitCode int    // exit code of the process, -1 if it was terminated by a signal
+	Signal   string // name of the signal that terminated the process, such as "killed"
+}
+
+// signalNames contains the names of common signals, as printed by the os
+// package on Unix systems.
+var signalNames = map[int]string{
+	1:  "hangup",
+	2:  "interrupt",
+	3:  "quit",
+	6:  "aborted",
+	9:  "killed",
+	15: "terminated",
+}
+
+// ExitStatus returns the RunMeta of a process that exited with the given exit
+// status, as reported by a shell. Shells report processes terminated by a
+// signal using an exit status of 128 plus the signal number, for example 137
+// when a process was killed because it ran out of memory.
+func ExitStatus(status int) RunMeta {
+	if status <= 128 || status > 128+64 {
+		return RunMeta{ExitCode: status}
+	}
+	sig := status - 128
+	name, ok := signalNames[sig]
+	if !ok {
+		name = "signal " + strconv.Itoa(sig)
+	}
+	return RunMeta{ExitCode: -1, Signal: name}
+}
+
+// Killed returns true if the process was terminated by a signal.
+func (m RunMeta) Killed() bool {
+	return m.Signal != ""
+}
+
+// String returns a description of how the process exited, such as "exit
+// status 1" or "signal: killed".
+func (m RunMeta) String() string {
+	if m.Killed() {
+		return "signal: " + m.Signal
+	}
+	return fmt.Sprintf("exit status %d", m.ExitCode)
+}
+
+// KilledPackage is the name of the package MarkKilled adds when the go test
+// process was terminated but none of the packages in the report was running.
+const KilledPackage = "go test"
+
+// MarkKilled returns a copy of report r in which a go test process that was
+// terminated by a signal, for example after running out of memory or exceeding
+// a CI timeout, is recorded as a runtime error. The error is added to every
+// package with tests without a result, since those were still running when the
+// process was terminated. If there is no such package, a package named
+// KilledPackage is added containing the error instead. Report r is returned
+// unchanged if r.RunMeta is nil or the process wasn't killed.
+func MarkKilled(r Report) Report {
+	if r.RunMeta == nil || !r.RunMeta.Killed() {
+		return r
+	}
+	cause := "go test terminated by " + r.RunMeta.String()
+	marked := r
+	marked.Packages = make([]Package, len(r.Packages), len(r.Packages)+1)
+	found := false
+	for i, pkg := range r.Packages {
+		if pkg.RunError.Name == "" && pkg.RunError.Kind == "" && hasUnknownTests(pkg) {
+			pkg.RunError = Error{Name: pkg.Name, Cause: cause, Output: []string{cause}}
+			found = true
+		}
+		marked.Packages[i] = pkg
+	}
+	if !found {
+		marked.Packages = append(marked.Packages, Package{
+			Name:     KilledPackage,
+			RunError: Error{Name: KilledPackage, Cause: cause, Output: []string{cause}},
+		})
+	}
+	return marked
+}
+
+func hasUnknownTests(pkg Package) bool {
+	for _, t := range pkg.Tests {
+		if t.Result == Unknown {
+			return true
+		}
+	}
+	return false
+}
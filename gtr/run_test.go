@@ -0,0 +1,94 @@
+package gtr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExitStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   RunMeta
+		str    string
+	}{
+		{0, RunMeta{ExitCode: 0}, "exit status 0"},
+		{1, RunMeta{ExitCode: 1}, "exit status 1"},
+		{128, RunMeta{ExitCode: 128}, "exit status 128"},
+		{130, RunMeta{ExitCode: -1, Signal: "interrupt"}, "signal: interrupt"},
+		{137, RunMeta{ExitCode: -1, Signal: "killed"}, "signal: killed"},
+		{143, RunMeta{ExitCode: -1, Signal: "terminated"}, "signal: terminated"},
+		{152, RunMeta{ExitCode: -1, Signal: "signal 24"}, "signal: signal 24"},
+		{255, RunMeta{ExitCode: 255}, "exit status 255"},
+	}
+	for _, test := range tests {
+		got := ExitStatus(test.status)
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("ExitStatus(%d) incorrect, diff (-want +got):\n%s\n", test.status, diff)
+		}
+		if got.String() != test.str {
+			t.Errorf("ExitStatus(%d).String() = %q, want %q", test.status, got.String(), test.str)
+		}
+	}
+}
+
+func TestMarkKilled(t *testing.T) {
+	killed := &RunMeta{ExitCode: -1, Signal: "killed"}
+	cause := "go test terminated by signal: killed"
+
+	tests := []struct {
+		name  string
+		input Report
+		want  Report
+	}{
+		{
+			"not killed",
+			Report{RunMeta: &RunMeta{ExitCode: 1}, Packages: []Package{{Name: "package/name"}}},
+			Report{RunMeta: &RunMeta{ExitCode: 1}, Packages: []Package{{Name: "package/name"}}},
+		},
+		{
+			"running package",
+			Report{RunMeta: killed, Packages: []Package{
+				{Name: "package/done", Tests: []Test{{Name: "TestA", Result: Pass}}},
+				{Name: "package/running", Tests: []Test{{Name: "TestB", Result: Unknown}}},
+			}},
+			Report{RunMeta: killed, Packages: []Package{
+				{Name: "package/done", Tests: []Test{{Name: "TestA", Result: Pass}}},
+				{
+					Name:     "package/running",
+					Tests:    []Test{{Name: "TestB", Result: Unknown}},
+					RunError: Error{Name: "package/running", Cause: cause, Output: []string{cause}},
+				},
+			}},
+		},
+		{
+			"no running package",
+			Report{RunMeta: killed, Packages: []Package{
+				{Name: "package/done", Tests: []Test{{Name: "TestA", Result: Pass}}},
+			}},
+			Report{RunMeta: killed, Packages: []Package{
+				{Name: "package/done", Tests: []Test{{Name: "TestA", Result: Pass}}},
+				{Name: KilledPackage, RunError: Error{Name: KilledPackage, Cause: cause, Output: []string{cause}}},
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := MarkKilled(test.input)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("MarkKilled result incorrect, diff (-want +got):\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestIsSuccessfulKilled(t *testing.T) {
+	report := Report{Packages: []Package{{Name: "package/name", Tests: []Test{{Name: "TestA", Result: Pass}}}}}
+	if !report.IsSuccessful() {
+		t.Fatalf("IsSuccessful() = false for a passing report, want true")
+	}
+	report.RunMeta = &RunMeta{ExitCode: -1, Signal: "killed"}
+	if report.IsSuccessful() {
+		t.Errorf("IsSuccessful() = true for a killed run, want false")
+	}
+}
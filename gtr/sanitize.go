@@ -0,0 +1,111 @@
+package gtr
+
+import (
+	"regexp"
+	"strings"
+)
+
+// regexANSI matches ANSI escape sequences: control sequences such as color
+// codes, operating system commands such as terminal titles and hyperlinks, and
+// the remaining two character escape sequences.
+var regexANSI = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[@-Z\\-_])`)
+
+// StripANSI returns s without any ANSI escape sequences.
+func StripANSI(s string) string {
+	if !strings.ContainsRune(s, '\x1b') {
+		return s
+	}
+	return regexANSI.ReplaceAllString(s, "")
+}
+
+// StripControlChars returns s without the control characters and code points
+// that are not allowed in XML 1.0 documents. Tabs, newlines and carriage
+// returns are kept.
+func StripControlChars(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return r
+		}
+		if r < 0x20 || r == 0x7f || r >= 0xD800 && r <= 0xDFFF || r == 0xFFFE || r == 0xFFFF {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// sanitize removes ANSI escape sequences and control characters from s. When
+// keepANSI is set, the ANSI escape sequences are kept and only the other
+// control characters are removed.
+func sanitize(s string, keepANSI bool) string {
+	if !keepANSI {
+		return StripControlChars(StripANSI(s))
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range regexANSI.FindAllStringIndex(s, -1) {
+		b.WriteString(StripControlChars(s[last:m[0]]))
+		b.WriteString(s[m[0]:m[1]])
+		last = m[1]
+	}
+	b.WriteString(StripControlChars(s[last:]))
+	return b.String()
+}
+
+func sanitizeLines(lines []string, keepANSI bool) []string {
+	if lines == nil {
+		return nil
+	}
+	sanitized := make([]string, len(lines))
+	for i, line := range lines {
+		sanitized[i] = sanitize(line, keepANSI)
+	}
+	return sanitized
+}
+
+func sanitizeError(e Error, keepANSI bool) Error {
+	e.Output = sanitizeLines(e.Output, keepANSI)
+	e.Panic = sanitizePanic(e.Panic, keepANSI)
+	return e
+}
+
+func sanitizePanic(p *PanicInfo, keepANSI bool) *PanicInfo {
+	if p == nil {
+		return nil
+	}
+	return &PanicInfo{
+		Message: sanitize(p.Message, keepANSI),
+		Test:    p.Test,
+		Stack:   sanitizeLines(p.Stack, keepANSI),
+	}
+}
+
+// SanitizeOutput returns a copy of report r in which ANSI escape sequences,
+// such as color codes, and control characters that are invalid in XML 1.0 have
+// been removed from all output and messages. When keepANSI is set, ANSI
+// escape sequences are kept, e.g. so that they can be rendered as colors.
+func SanitizeOutput(r Report, keepANSI bool) Report {
+	sanitized := r.Map(func(t Test) Test {
+		t.Output = sanitizeLines(t.Output, keepANSI)
+		t.Stderr = sanitizeLines(t.Stderr, keepANSI)
+		t.SkipMessage = sanitize(t.SkipMessage, keepANSI)
+		t.FailureMessage = sanitize(t.FailureMessage, keepANSI)
+		t.Panic = sanitizePanic(t.Panic, keepANSI)
+		if t.Attempts != nil {
+			attempts := make([]TestAttempt, len(t.Attempts))
+			for i, a := range t.Attempts {
+				a.Output = sanitizeLines(a.Output, keepANSI)
+				attempts[i] = a
+			}
+			t.Attempts = attempts
+		}
+		return t
+	})
+	for i := range sanitized.Packages {
+		pkg := &sanitized.Packages[i]
+		pkg.Output = sanitizeLines(pkg.Output, keepANSI)
+		pkg.Stderr = sanitizeLines(pkg.Stderr, keepANSI)
+		pkg.BuildError = sanitizeError(pkg.BuildError, keepANSI)
+		pkg.RunError = sanitizeError(pkg.RunError, keepANSI)
+	}
+	return sanitized
+}
@@ -0,0 +1,76 @@
+package gtr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{"\x1b[31mred\x1b[0m text", "red text"},
+		{"\x1b[1;32mbold green\x1b[m", "bold green"},
+		{"\x1b[2K\x1b[1Gprogress", "progress"},
+		{"\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", "link"},
+		{"\x1b]0;title\x07text", "text"},
+		{"\x1bMreverse index", "reverse index"},
+	}
+	for _, test := range tests {
+		if got := StripANSI(test.in); got != test.want {
+			t.Errorf("StripANSI(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestStripControlChars(t *testing.T) {
+	in := "a\x00b\x07c\td\re\nf\x7fg\uFFFEh"
+	want := "abc\td\re\nfgh"
+	if got := StripControlChars(in); got != want {
+		t.Errorf("StripControlChars(%q) = %q, want %q", in, got, want)
+	}
+}
+
+func TestSanitizeOutput(t *testing.T) {
+	report := Report{Packages: []Package{{
+		Name:       "package/name",
+		Output:     []string{"\x1b[33mwarning\x1b[0m\x00"},
+		BuildError: Error{Output: []string{"\x1b[1merror\x1b[0m"}},
+		Tests: []Test{{
+			Name:           "TestOne",
+			Output:         []string{"\x1b[31mFAIL\x1b[0m\x08"},
+			Stderr:         []string{"\x1b[33mwarn\x1b[0m\x01"},
+			FailureMessage: "\x1b[31mexpected\x1b[0m",
+			Panic:          &PanicInfo{Message: "\x1b[31mboom\x1b[0m"},
+			Attempts:       []TestAttempt{{Output: []string{"\x1b[31mattempt\x1b[0m"}}},
+		}},
+	}}}
+
+	want := Report{Packages: []Package{{
+		Name:       "package/name",
+		Output:     []string{"warning"},
+		BuildError: Error{Output: []string{"error"}},
+		Tests: []Test{{
+			Name:           "TestOne",
+			Output:         []string{"FAIL"},
+			Stderr:         []string{"warn"},
+			FailureMessage: "expected",
+			Panic:          &PanicInfo{Message: "boom"},
+			Attempts:       []TestAttempt{{Output: []string{"attempt"}}},
+		}},
+	}}}
+	if diff := cmp.Diff(want, SanitizeOutput(report, false)); diff != "" {
+		t.Errorf("SanitizeOutput result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	got := SanitizeOutput(report, true)
+	if diff := cmp.Diff([]string{"\x1b[31mFAIL\x1b[0m"}, got.Packages[0].Tests[0].Output); diff != "" {
+		t.Errorf("SanitizeOutput(keepANSI) test output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if report.Packages[0].Tests[0].Output[0] != "\x1b[31mFAIL\x1b[0m\x08" {
+		t.Errorf("SanitizeOutput modified the original report")
+	}
+}
@@ -0,0 +1,12 @@
+package gtr
+
+// ShuffleSeedProperty is the name of the package property containing the
+// seed that go test used to shuffle the order of its tests when running with
+// -shuffle, which it prints as "-test.shuffle <seed>".
+const ShuffleSeedProperty = "go.shuffle"
+
+// ShuffleSeed returns the seed that was used to shuffle the tests of p, or an
+// empty string if its tests weren't shuffled.
+func (p Package) ShuffleSeed() string {
+	return propertyValue(p.Properties, ShuffleSeedProperty)
+}
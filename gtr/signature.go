@@ -0,0 +1,122 @@
+package gtr
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	regexTimestamp  = regexp.MustCompile(`\d{4}[-/]\d{2}[-/]\d{2}(?:[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?)?|\b\d{2}:\d{2}:\d{2}(?:\.\d+)?\b`)
+	regexHexAddress = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+	regexLineNumber = regexp.MustCompile(`(\.go|\.s):\d+(:\d+)?`)
+	regexDuration   = regexp.MustCompile(`\b\d+(\.\d+)?(ns|us|µs|ms|s|m|h)\b`)
+	regexTempPath   = regexp.MustCompile(`(^|[\s"'(=])(?:` + tempDirs() + `)[/\\][^\s:"'()]*`)
+	regexAbsPath    = regexp.MustCompile(`(^|[\s"'(=])(?:[A-Za-z]:)?[/\\](?:[^\s:"'()/\\]+[/\\])+`)
+)
+
+// tempDirs returns a regular expression matching the usual temporary
+// directories, in which tests create files with random names.
+func tempDirs() string {
+	dirs := []string{`/tmp`, `/var/tmp`, `(?:/private)?/var/folders/[^/\s]+/[^/\s]+/T`}
+	if dir := filepath.Clean(os.TempDir()); dir != "/tmp" && dir != "." {
+		dirs = append(dirs, regexp.QuoteMeta(dir))
+	}
+	return strings.Join(dirs, "|")
+}
+
+// TestRef identifies a test in a report.
+type TestRef struct {
+	Package string
+	Test    string
+}
+
+// failureSignatures groups all failed tests in report r by their failure
+// signature, see FailureClusters.
+func (r Report) failureSignatures() map[string][]TestRef {
+	signatures := make(map[string][]TestRef)
+	for _, pkg := range r.Packages {
+		for _, test := range pkg.Tests {
+			if test.Result.Base() != Fail {
+				continue
+			}
+			sig := failureSignature(test)
+			signatures[sig] = append(signatures[sig], TestRef{Package: pkg.Name, Test: test.Name})
+		}
+	}
+	return signatures
+}
+
+// FailureCluster is a group of failed tests that share a failure signature,
+// see Report.FailureClusters.
+type FailureCluster struct {
+	Signature string
+	Tests     []TestRef
+}
+
+// FailureClusters returns the failed tests of report r grouped by their
+// failure signature, across all packages. The signature of a test is its
+// FailureMessage, or its output if it has no failure message, with
+// surrounding whitespace removed from each line and with the parts that
+// typically differ between otherwise identical failures masked:
+//
+//   - dates and times such as 2022-01-01T15:04:05Z or 15:04:05.000 are
+//     replaced by ?
+//   - hexadecimal addresses such as 0xc000012345 are replaced by 0x?
+//   - line and column numbers following .go or .s file names are replaced by ?
+//   - durations such as 1.5s or 300ms are replaced by ?
+//   - paths in temporary directories such as /tmp/TestA123/001/file are
+//     replaced by ?
+//   - the directories of other absolute paths such as /home/user/a_test.go
+//     are replaced by ?/
+//
+// Tests without a failure message or output all share the empty signature.
+// Clusters are ordered by the number of tests they contain, largest first, and
+// clusters of the same size by signature. The tests in each cluster are listed
+// in the order they appear in the report.
+func (r Report) FailureClusters() []FailureCluster {
+	var clusters []FailureCluster
+	for sig, tests := range r.failureSignatures() {
+		clusters = append(clusters, FailureCluster{Signature: sig, Tests: tests})
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i].Tests) != len(clusters[j].Tests) {
+			return len(clusters[i].Tests) > len(clusters[j].Tests)
+		}
+		return clusters[i].Signature < clusters[j].Signature
+	})
+	return clusters
+}
+
+// Summary returns the first non-empty line of the signature of cluster c, or
+// an empty string if the tests in c have no failure message or output.
+func (c FailureCluster) Summary() string {
+	for _, line := range strings.Split(c.Signature, "\n") {
+		if line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// failureSignature returns the normalized signature of failed test t.
+func failureSignature(t Test) string {
+	output := t.Output
+	if t.FailureMessage != "" {
+		output = strings.Split(t.FailureMessage, "\n")
+	}
+	lines := make([]string, 0, len(output))
+	for _, line := range output {
+		line = strings.TrimSpace(line)
+		line = regexTempPath.ReplaceAllString(line, "$1?")
+		line = regexAbsPath.ReplaceAllString(line, "$1?/")
+		line = regexTimestamp.ReplaceAllString(line, "?")
+		line = regexHexAddress.ReplaceAllString(line, "0x?")
+		line = regexLineNumber.ReplaceAllString(line, "$1:?")
+		line = regexDuration.ReplaceAllString(line, "?")
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
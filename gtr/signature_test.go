@@ -0,0 +1,84 @@
+package gtr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFailureSignatures(t *testing.T) {
+	report := Report{Packages: []Package{
+		{
+			Name: "package/one",
+			Tests: []Test{
+				{Name: "TestNilOne", Result: Fail, Output: []string{"    helper_test.go:12: nil pointer at 0xc000012345"}},
+				{Name: "TestPass", Result: Pass, Output: []string{"    one_test.go:12: nil pointer at 0xc000012345"}},
+				{Name: "TestTimeout", Result: Fail, Output: []string{"    wait.go:30: timed out after 1.5s"}},
+				{Name: "TestDeadline", Result: Fail, Output: []string{"", "    deadline exceeded at 2022-01-01T15:04:05.123Z"}},
+			},
+		},
+		{
+			Name: "package/two",
+			Tests: []Test{
+				{Name: "TestNilTwo", Result: Fail, Output: []string{"\thelper_test.go:17:3: nil pointer at 0xc0000abcde"}},
+				{Name: "TestSlow", Result: Fail, Output: []string{"    wait.go:31: timed out after 300ms"}},
+				{Name: "TestOther", Result: Fail, Output: []string{"    two_test.go:1: unexpected value 42"}},
+				{Name: "TestLate", Result: Fail, Output: []string{"", "\tdeadline exceeded at 2022/01/02 10:00:00"}},
+			},
+		},
+		{
+			Name: "package/three",
+			Tests: []Test{
+				{Name: "TestOpenA", Result: Fail, FailureMessage: "open /tmp/TestOpenA123/001/config.json: no such file", Output: []string{"    open_test.go:10: first"}},
+				{Name: "TestOpenB", Result: Fail, FailureMessage: "open /tmp/TestOpenB456/001/config.json: no such file", Output: []string{"    open_test.go:20: second"}},
+				{Name: "TestRead", Result: Fail, FailureMessage: "read /home/ci/src/data.txt: permission denied"},
+				{Name: "TestReadAgain", Result: Fail, FailureMessage: "read /builds/project/src/data.txt: permission denied"},
+			},
+		},
+	}}
+
+	want := map[string][]TestRef{
+		"helper_test.go:?: nil pointer at 0x?": {{"package/one", "TestNilOne"}, {"package/two", "TestNilTwo"}},
+		"wait.go:?: timed out after ?":         {{"package/one", "TestTimeout"}, {"package/two", "TestSlow"}},
+		"two_test.go:?: unexpected value 42":   {{"package/two", "TestOther"}},
+		"\ndeadline exceeded at ?":             {{"package/one", "TestDeadline"}, {"package/two", "TestLate"}},
+		"open ?: no such file":                 {{"package/three", "TestOpenA"}, {"package/three", "TestOpenB"}},
+		"read ?/data.txt: permission denied":   {{"package/three", "TestRead"}, {"package/three", "TestReadAgain"}},
+	}
+	got := report.failureSignatures()
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("failureSignatures result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestFailureClusters(t *testing.T) {
+	report := Report{Packages: []Package{
+		{
+			Name: "package/one",
+			Tests: []Test{
+				{Name: "TestA", Result: Fail, Output: []string{"    a_test.go:1: unexpected value"}},
+				{Name: "TestB", Result: Fail, Output: []string{"", "dial tcp 10.0.0.1:5432: connection refused at 15:04:05"}},
+			},
+		},
+		{
+			Name: "package/two",
+			Tests: []Test{
+				{Name: "TestC", Result: Fail, Output: []string{"", "dial tcp 10.0.0.1:5432: connection refused at 15:04:06"}},
+				{Name: "TestD", Result: Fail},
+			},
+		},
+	}}
+
+	want := []FailureCluster{
+		{"\ndial tcp 10.0.0.1:5432: connection refused at ?", []TestRef{{"package/one", "TestB"}, {"package/two", "TestC"}}},
+		{"", []TestRef{{"package/two", "TestD"}}},
+		{"a_test.go:?: unexpected value", []TestRef{{"package/one", "TestA"}}},
+	}
+	got := report.FailureClusters()
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("FailureClusters result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if got, want := got[0].Summary(), "dial tcp 10.0.0.1:5432: connection refused at ?"; got != want {
+		t.Errorf("FailureCluster.Summary() = %q, want %q", got, want)
+	}
+}
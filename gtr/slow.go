@@ -0,0 +1,64 @@
+package gtr
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Slowest returns the n tests in report r with the longest duration, longest
+// first. Tests with the same duration appear in report order. All tests are
+// returned if n is 0 or less.
+func (r Report) Slowest(n int) []TestDuration {
+	var durations []TestDuration
+	for _, pkg := range r.Packages {
+		for _, t := range pkg.Tests {
+			durations = append(durations, TestDuration{TestRef{Package: pkg.Name, Test: t.Name}, t.Duration})
+		}
+	}
+	sort.SliceStable(durations, func(i, j int) bool {
+		return durations[i].Duration > durations[j].Duration
+	})
+	if n > 0 && len(durations) > n {
+		durations = durations[:n]
+	}
+	return durations
+}
+
+// SlowPolicy configures how MarkSlowTests treats tests that took longer than
+// their duration budget.
+type SlowPolicy struct {
+	Threshold time.Duration // maximum duration of a test, 0 means no limit
+	Fail      bool          // mark slow tests that didn't fail already as failed
+}
+
+// MarkSlowTests returns a copy of report r in which every test whose duration
+// exceeds the threshold of policy p is marked with a "slow" property. When
+// p.Fail is set, slow tests that passed or were flaky are also marked as
+// failed, with a failure message containing the threshold. Skipped tests and
+// tests without a result are never considered slow.
+func MarkSlowTests(r Report, p SlowPolicy) Report {
+	if p.Threshold <= 0 {
+		return r
+	}
+	marked := r
+	marked.Packages = make([]Package, len(r.Packages))
+	for i, pkg := range r.Packages {
+		tests := make([]Test, len(pkg.Tests))
+		for j, t := range pkg.Tests {
+			if t.Duration > p.Threshold && t.Result.Base() != Skip && t.Result != Unknown {
+				t.Properties = copyProperties(t.Properties)
+				t.SetProperty("slow", "true")
+				if p.Fail && t.Result.Base() != Fail {
+					t.Result = Fail
+					t.FailureMessage = fmt.Sprintf("test took %v, exceeding the duration budget of %v", t.Duration, p.Threshold)
+					t.FailureType = "slow"
+				}
+			}
+			tests[j] = t
+		}
+		pkg.Tests = tests
+		marked.Packages[i] = pkg
+	}
+	return marked
+}
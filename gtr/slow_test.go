@@ -0,0 +1,98 @@
+package gtr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSlowest(t *testing.T) {
+	report := Report{Packages: []Package{
+		{Name: "package/one", Tests: []Test{
+			{Name: "TestA", Duration: 2 * time.Second},
+			{Name: "TestB", Duration: 5 * time.Second},
+		}},
+		{Name: "package/two", Tests: []Test{
+			{Name: "TestC", Duration: 3 * time.Second},
+			{Name: "TestD", Duration: 2 * time.Second},
+		}},
+	}}
+
+	want := []TestDuration{
+		{TestRef{"package/one", "TestB"}, 5 * time.Second},
+		{TestRef{"package/two", "TestC"}, 3 * time.Second},
+		{TestRef{"package/one", "TestA"}, 2 * time.Second},
+		{TestRef{"package/two", "TestD"}, 2 * time.Second},
+	}
+	if diff := cmp.Diff(want, report.Slowest(0)); diff != "" {
+		t.Errorf("Slowest(0) result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if diff := cmp.Diff(want[:2], report.Slowest(2)); diff != "" {
+		t.Errorf("Slowest(2) result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestMarkSlowTests(t *testing.T) {
+	report := Report{Packages: []Package{
+		{Name: "package/name", Tests: []Test{
+			{Name: "TestFast", Result: Pass, Duration: time.Second},
+			{Name: "TestSlow", Result: Pass, Duration: 31 * time.Second, Properties: []Property{{Name: "key", Value: "value"}}},
+			{Name: "TestSlowFail", Result: Fail, Duration: 40 * time.Second, FailureMessage: "boom"},
+			{Name: "TestSlowSkip", Result: Skip, Duration: 40 * time.Second},
+		}},
+	}}
+	slow := []Property{{Name: "slow", Value: "true"}}
+
+	tests := []struct {
+		name   string
+		policy SlowPolicy
+		want   []Test
+	}{
+		{
+			"mark",
+			SlowPolicy{Threshold: 30 * time.Second},
+			[]Test{
+				{Name: "TestFast", Result: Pass, Duration: time.Second},
+				{Name: "TestSlow", Result: Pass, Duration: 31 * time.Second, Properties: []Property{{Name: "key", Value: "value"}, {Name: "slow", Value: "true"}}},
+				{Name: "TestSlowFail", Result: Fail, Duration: 40 * time.Second, FailureMessage: "boom", Properties: slow},
+				{Name: "TestSlowSkip", Result: Skip, Duration: 40 * time.Second},
+			},
+		},
+		{
+			"fail",
+			SlowPolicy{Threshold: 30 * time.Second, Fail: true},
+			[]Test{
+				{Name: "TestFast", Result: Pass, Duration: time.Second},
+				{
+					Name:           "TestSlow",
+					Result:         Fail,
+					Duration:       31 * time.Second,
+					Properties:     []Property{{Name: "key", Value: "value"}, {Name: "slow", Value: "true"}},
+					FailureMessage: "test took 31s, exceeding the duration budget of 30s",
+					FailureType:    "slow",
+				},
+				{Name: "TestSlowFail", Result: Fail, Duration: 40 * time.Second, FailureMessage: "boom", Properties: slow},
+				{Name: "TestSlowSkip", Result: Skip, Duration: 40 * time.Second},
+			},
+		},
+		{
+			"no threshold",
+			SlowPolicy{Fail: true},
+			report.Packages[0].Tests,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := MarkSlowTests(report, test.policy)
+			if diff := cmp.Diff(test.want, got.Packages[0].Tests); diff != "" {
+				t.Errorf("MarkSlowTests result incorrect, diff (-want +got):\n%s\n", diff)
+			}
+		})
+	}
+
+	if len(report.Packages[0].Tests[1].Properties) != 1 {
+		t.Errorf("MarkSlowTests modified the properties of the original report: %v", report.Packages[0].Tests[1].Properties)
+	}
+}
@@ -0,0 +1,146 @@
+package gtr
+
+import (
+	"sort"
+	"time"
+)
+
+// SortKey is a key by which packages and tests can be ordered, see
+// Report.Sort.
+type SortKey string
+
+// Supported sort keys.
+const (
+	// SortByName orders packages and tests by name.
+	SortByName SortKey = "name"
+
+	// SortByDuration orders packages and tests by duration, longest first.
+	SortByDuration SortKey = "duration"
+
+	// SortByFailuresFirst orders packages with a build or runtime error or
+	// containing failed tests before other packages, and failed tests and
+	// tests without a result before other tests.
+	SortByFailuresFirst SortKey = "failures-first"
+)
+
+// Sort orders the packages in report r and the tests in each package by the
+// given keys. Packages and tests that are equal according to the first key
+// are ordered by the second key and so on. Packages and tests that are equal
+// according to all keys keep their original relative order. Calling Sort
+// without any keys leaves the report unchanged.
+func (r *Report) Sort(by ...SortKey) {
+	r.SortPackages(by...)
+	r.SortTests(by...)
+}
+
+// SortPackages orders the packages in report r by the given keys, like Sort,
+// without changing the order of the tests in each package.
+func (r *Report) SortPackages(by ...SortKey) {
+	if len(by) == 0 {
+		return
+	}
+	sort.SliceStable(r.Packages, func(i, j int) bool {
+		return comparePackages(r.Packages[i], r.Packages[j], by) < 0
+	})
+}
+
+// SortTests orders the tests in each package of report r by the given keys,
+// like Sort, without changing the order of the packages.
+func (r *Report) SortTests(by ...SortKey) {
+	if len(by) == 0 {
+		return
+	}
+	for _, pkg := range r.Packages {
+		tests := pkg.Tests
+		sort.SliceStable(tests, func(i, j int) bool {
+			return compareTests(tests[i], tests[j], by) < 0
+		})
+	}
+}
+
+func comparePackages(a, b Package, by []SortKey) int {
+	for _, key := range by {
+		var c int
+		switch key {
+		case SortByName:
+			c = compareStrings(a.Name, b.Name)
+		case SortByDuration:
+			c = compareDurations(a.Duration, b.Duration)
+		case SortByFailuresFirst:
+			c = compareBools(packageFailed(a), packageFailed(b))
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareTests(a, b Test, by []SortKey) int {
+	for _, key := range by {
+		var c int
+		switch key {
+		case SortByName:
+			c = compareStrings(a.Name, b.Name)
+		case SortByDuration:
+			c = compareDurations(a.Duration, b.Duration)
+		case SortByFailuresFirst:
+			c = compareBools(testFailed(a), testFailed(b))
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareStrings(a, b string) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareDurations orders longer durations first.
+func compareDurations(a, b time.Duration) int {
+	switch {
+	case a > b:
+		return -1
+	case a < b:
+		return 1
+	}
+	return 0
+}
+
+// compareBools orders true before false.
+func compareBools(a, b bool) int {
+	switch {
+	case a && !b:
+		return -1
+	case !a && b:
+		return 1
+	}
+	return 0
+}
+
+// packageFailed returns true if pkg has a build or runtime error, or contains
+// at least one failed test.
+func packageFailed(pkg Package) bool {
+	if pkg.BuildError.Name != "" || pkg.RunError.Name != "" {
+		return true
+	}
+	for _, test := range pkg.Tests {
+		if testFailed(test) {
+			return true
+		}
+	}
+	return false
+}
+
+// testFailed returns true if test failed or has no result.
+func testFailed(test Test) bool {
+	return test.Result.Base() == Fail || test.Result == Unknown
+}
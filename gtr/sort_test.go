@@ -0,0 +1,67 @@
+package gtr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSort(t *testing.T) {
+	report := func() Report {
+		return Report{Packages: []Package{
+			{
+				Name:     "package/b",
+				Duration: 2 * time.Second,
+				Tests: []Test{
+					{Name: "TestZ", Result: Pass, Duration: 1 * time.Second},
+					{Name: "TestY", Result: Fail, Duration: 3 * time.Second},
+					{Name: "TestX", Result: Pass, Duration: 2 * time.Second},
+				},
+			},
+			{Name: "package/c", Duration: 3 * time.Second, BuildError: Error{Name: "package/c"}},
+			{Name: "package/a", Duration: 1 * time.Second},
+		}}
+	}
+	names := func(r Report) []string {
+		var names []string
+		for _, pkg := range r.Packages {
+			names = append(names, pkg.Name)
+			for _, test := range pkg.Tests {
+				names = append(names, pkg.Name+"."+test.Name)
+			}
+		}
+		return names
+	}
+
+	tests := []struct {
+		by   []SortKey
+		want []string
+	}{
+		{nil, []string{"package/b", "package/b.TestZ", "package/b.TestY", "package/b.TestX", "package/c", "package/a"}},
+		{[]SortKey{SortByName}, []string{"package/a", "package/b", "package/b.TestX", "package/b.TestY", "package/b.TestZ", "package/c"}},
+		{[]SortKey{SortByDuration}, []string{"package/c", "package/b", "package/b.TestY", "package/b.TestX", "package/b.TestZ", "package/a"}},
+		{[]SortKey{SortByFailuresFirst}, []string{"package/b", "package/b.TestY", "package/b.TestZ", "package/b.TestX", "package/c", "package/a"}},
+		{[]SortKey{SortByFailuresFirst, SortByName}, []string{"package/b", "package/b.TestY", "package/b.TestX", "package/b.TestZ", "package/c", "package/a"}},
+	}
+	for _, test := range tests {
+		r := report()
+		r.Sort(test.by...)
+		if diff := cmp.Diff(test.want, names(r)); diff != "" {
+			t.Errorf("Sort(%v) result incorrect, diff (-want +got):\n%s\n", test.by, diff)
+		}
+	}
+
+	r := report()
+	r.SortPackages(SortByName)
+	want := []string{"package/a", "package/b", "package/b.TestZ", "package/b.TestY", "package/b.TestX", "package/c"}
+	if diff := cmp.Diff(want, names(r)); diff != "" {
+		t.Errorf("SortPackages(name) result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	r = report()
+	r.SortTests(SortByName)
+	want = []string{"package/b", "package/b.TestX", "package/b.TestY", "package/b.TestZ", "package/c", "package/a"}
+	if diff := cmp.Diff(want, names(r)); diff != "" {
+		t.Errorf("SortTests(name) result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,89 @@
+package gtr
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SubtestDurationMode determines whether the duration of a test with subtests
+// includes the durations of its subtests, see AggregateSubtestDurations.
+type SubtestDurationMode string
+
+const (
+	// SubtestDurationInclusive reports the duration of a test including the
+	// time spent in its subtests, as reported by go test. This is the
+	// default.
+	SubtestDurationInclusive SubtestDurationMode = "inclusive"
+
+	// SubtestDurationExclusive reports the duration of a test excluding the
+	// durations of its subtests, so that adding up the durations of all tests
+	// in a package doesn't count the time spent in subtests more than once.
+	SubtestDurationExclusive SubtestDurationMode = "exclusive"
+)
+
+// ParseSubtestDurationMode returns the SubtestDurationMode with the given
+// name. An empty name returns SubtestDurationInclusive.
+func ParseSubtestDurationMode(name string) (SubtestDurationMode, error) {
+	switch m := SubtestDurationMode(name); m {
+	case "":
+		return SubtestDurationInclusive, nil
+	case SubtestDurationInclusive, SubtestDurationExclusive:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unknown subtest duration mode: %s", name)
+	}
+}
+
+// AggregateSubtestDurations returns a copy of report r in which the durations
+// of tests with subtests are aggregated according to mode. In exclusive mode,
+// the durations of the direct subtests of a test are subtracted from its own
+// duration. Since parallel subtests may run at the same time, the resulting
+// duration is never less than zero. Subtests are matched to their parent in
+// the same way as Package.TestTree.
+func AggregateSubtestDurations(r Report, mode SubtestDurationMode) Report {
+	if mode != SubtestDurationExclusive {
+		return r
+	}
+
+	aggregated := r
+	aggregated.Packages = make([]Package, len(r.Packages))
+	for i, pkg := range r.Packages {
+		subtests := make([]time.Duration, len(pkg.Tests))
+		byName := make(map[string]int)
+		for j, test := range pkg.Tests {
+			if parent, ok := parentIndex(byName, test.Name); ok {
+				subtests[parent] += test.Duration
+			}
+			byName[test.Name] = j
+		}
+
+		pkg.Tests = append([]Test(nil), pkg.Tests...)
+		for j := range pkg.Tests {
+			if subtests[j] == 0 {
+				continue
+			}
+			pkg.Tests[j].Duration -= subtests[j]
+			if pkg.Tests[j].Duration < 0 {
+				pkg.Tests[j].Duration = 0
+			}
+		}
+		aggregated.Packages[i] = pkg
+	}
+	return aggregated
+}
+
+// parentIndex returns the index in byName of the closest parent of the test
+// with the given name, see findParent.
+func parentIndex(byName map[string]int, name string) (int, bool) {
+	for {
+		idx := strings.LastIndexByte(name, '/')
+		if idx < 0 {
+			return 0, false
+		}
+		name = name[:idx]
+		if i, ok := byName[name]; ok {
+			return i, true
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package gtr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAggregateSubtestDurations(t *testing.T) {
+	report := Report{Packages: []Package{{
+		Name: "package/name",
+		Tests: []Test{
+			{Name: "TestA", Duration: 10 * time.Second},
+			{Name: "TestA/one", Duration: 3 * time.Second},
+			{Name: "TestA/one/deep", Duration: 2 * time.Second},
+			{Name: "TestA/two", Duration: 4 * time.Second},
+			{Name: "TestParallel", Duration: time.Second},
+			{Name: "TestParallel/one", Duration: time.Second},
+			{Name: "TestParallel/two", Duration: time.Second},
+			{Name: "TestB", Duration: time.Second},
+		},
+	}}}
+
+	tests := []struct {
+		mode SubtestDurationMode
+		want []time.Duration
+	}{
+		{SubtestDurationInclusive, []time.Duration{10, 3, 2, 4, 1, 1, 1, 1}},
+		{SubtestDurationExclusive, []time.Duration{3, 1, 2, 4, 0, 1, 1, 1}},
+	}
+	for _, test := range tests {
+		got := AggregateSubtestDurations(report, test.mode)
+		var durations []time.Duration
+		for _, t := range got.Packages[0].Tests {
+			durations = append(durations, t.Duration/time.Second)
+		}
+		if diff := cmp.Diff(test.want, durations); diff != "" {
+			t.Errorf("AggregateSubtestDurations(%q) durations incorrect, diff (-want +got):\n%s\n", test.mode, diff)
+		}
+	}
+	if report.Packages[0].Tests[0].Duration != 10*time.Second {
+		t.Errorf("AggregateSubtestDurations modified its input report")
+	}
+}
+
+func TestParseSubtestDurationMode(t *testing.T) {
+	if m, err := ParseSubtestDurationMode(""); err != nil || m != SubtestDurationInclusive {
+		t.Errorf("ParseSubtestDurationMode(\"\") = %q, %v; want %q", m, err, SubtestDurationInclusive)
+	}
+	if _, err := ParseSubtestDurationMode("unknown"); err == nil {
+		t.Errorf("ParseSubtestDurationMode(unknown) did not return an error")
+	}
+}
@@ -0,0 +1,88 @@
+package gtr
+
+import "time"
+
+// SlowestTests is the maximum number of tests in Summary.Slowest.
+const SlowestTests = 10
+
+// Summary contains the totals of a report, see Report.Summary.
+type Summary struct {
+	Tests   int // number of tests, including subtests
+	Passed  int
+	Failed  int
+	Skipped int
+	Flaky   int
+	Errors  int  // tests without a result and packages with a build or runtime error
+	Killed  bool // the go test process was terminated by a signal, see Report.RunMeta
+	Aborted bool // the run stopped after the first failure, see Report.Aborted
+	NotRun  int  // skipped tests that never ran because the run was aborted
+
+	Duration           time.Duration // sum of all package durations
+	TimeToFirstFailure time.Duration // time until the first failed test ended, 0 if unknown
+	Slowest            []TestDuration
+}
+
+// TestDuration is the duration of a single test.
+type TestDuration struct {
+	TestRef
+	Duration time.Duration
+}
+
+// Summary returns the number of tests in report r by result, the total
+// duration of all packages and up to SlowestTests of the slowest tests,
+// longest first.
+func (r Report) Summary() Summary {
+	var s Summary
+	for _, pkg := range r.Packages {
+		s.Duration += pkg.Duration
+		if pkg.BuildError.Name != "" || pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			s.Errors++
+		}
+		for _, t := range pkg.Tests {
+			s.Tests++
+			switch t.Result.Base() {
+			case Pass:
+				s.Passed++
+			case Fail:
+				s.Failed++
+			case Skip:
+				s.Skipped++
+				if hasProperty(t.Properties, NotRunProperty, "true") {
+					s.NotRun++
+				}
+			case Flaky:
+				s.Flaky++
+			default:
+				s.Errors++
+			}
+		}
+	}
+	s.Killed = r.RunMeta != nil && r.RunMeta.Killed()
+	s.Aborted = r.Aborted
+	s.TimeToFirstFailure = timeToFirstFailure(r)
+	s.Slowest = r.Slowest(SlowestTests)
+	return s
+}
+
+// ExitPolicy configures which results are considered a failure by
+// Summary.ExitCode, in addition to failed tests and errors.
+type ExitPolicy struct {
+	FailOnFlaky   bool // fail if a test only passed after failing in another attempt
+	FailOnNoTests bool // fail if the report doesn't contain any tests
+}
+
+// ExitCode returns the exit status of a process reporting summary s: 1 if any
+// test failed, there were errors, the go test process was killed or one of the
+// conditions of policy p applies, and 0 otherwise.
+func (s Summary) ExitCode(p ExitPolicy) int {
+	if s.Failed > 0 || s.Errors > 0 || s.Killed {
+		return 1
+	}
+	if p.FailOnFlaky && s.Flaky > 0 {
+		return 1
+	}
+	if p.FailOnNoTests && s.Tests == 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,82 @@
+package gtr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSummary(t *testing.T) {
+	report := Report{Packages: []Package{
+		{
+			Name:     "package/one",
+			Duration: time.Second,
+			Tests: []Test{
+				{Name: "TestPass", Result: Pass, Duration: 10 * time.Millisecond},
+				{Name: "TestFail", Result: Fail, Duration: 30 * time.Millisecond},
+				{Name: "TestSkip", Result: Skip},
+				{Name: "TestFlaky", Result: Flaky, Duration: 20 * time.Millisecond},
+				{Name: "TestUnknown", Result: Unknown},
+			},
+		},
+		{Name: "package/two", Duration: 2 * time.Second, BuildError: Error{Name: "package/two"}},
+	}}
+
+	want := Summary{
+		Tests:    5,
+		Passed:   1,
+		Failed:   1,
+		Skipped:  1,
+		Flaky:    1,
+		Errors:   2,
+		Duration: 3 * time.Second,
+		Slowest: []TestDuration{
+			{TestRef{"package/one", "TestFail"}, 30 * time.Millisecond},
+			{TestRef{"package/one", "TestFlaky"}, 20 * time.Millisecond},
+			{TestRef{"package/one", "TestPass"}, 10 * time.Millisecond},
+			{TestRef{"package/one", "TestSkip"}, 0},
+			{TestRef{"package/one", "TestUnknown"}, 0},
+		},
+	}
+	if diff := cmp.Diff(want, report.Summary()); diff != "" {
+		t.Errorf("Summary result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestSummarySlowestLimit(t *testing.T) {
+	var pkg Package
+	for i := 0; i < SlowestTests+5; i++ {
+		pkg.Tests = append(pkg.Tests, Test{Name: "Test", Result: Pass, Duration: time.Duration(i)})
+	}
+	s := Report{Packages: []Package{pkg}}.Summary()
+	if len(s.Slowest) != SlowestTests {
+		t.Fatalf("Summary returned %d slowest tests, want %d", len(s.Slowest), SlowestTests)
+	}
+	if got, want := s.Slowest[0].Duration, time.Duration(SlowestTests+4); got != want {
+		t.Errorf("Slowest test duration = %v, want %v", got, want)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		summary Summary
+		policy  ExitPolicy
+		want    int
+	}{
+		{Summary{Tests: 1, Passed: 1}, ExitPolicy{}, 0},
+		{Summary{Tests: 1, Failed: 1}, ExitPolicy{}, 1},
+		{Summary{Errors: 1}, ExitPolicy{}, 1},
+		{Summary{Tests: 1, Passed: 1, Killed: true}, ExitPolicy{}, 1},
+		{Summary{Tests: 1, Flaky: 1}, ExitPolicy{}, 0},
+		{Summary{Tests: 1, Flaky: 1}, ExitPolicy{FailOnFlaky: true}, 1},
+		{Summary{}, ExitPolicy{}, 0},
+		{Summary{}, ExitPolicy{FailOnNoTests: true}, 1},
+		{Summary{Tests: 1, Skipped: 1}, ExitPolicy{FailOnNoTests: true}, 0},
+	}
+	for _, test := range tests {
+		if got := test.summary.ExitCode(test.policy); got != test.want {
+			t.Errorf("%+v.ExitCode(%+v) = %d, want %d", test.summary, test.policy, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package gtr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CoverageTestName is the name of the test added by EnforceCoverage to
+// packages whose coverage is below their minimum.
+const CoverageTestName = "[coverage]"
+
+// CoverageThreshold is the minimum coverage percentage of the packages whose
+// name matches Pattern.
+type CoverageThreshold struct {
+	Pattern *regexp.Regexp
+	Minimum float64
+}
+
+// CoveragePolicy configures the minimum coverage of packages enforced by
+// EnforceCoverage. The minimum of a package is that of the first threshold
+// whose pattern matches its name, or Minimum if no threshold matches. A
+// minimum of 0 means the coverage of the package isn't checked.
+type CoveragePolicy struct {
+	Minimum    float64
+	Thresholds []CoverageThreshold
+}
+
+// minimum returns the minimum coverage of the package with the given name.
+func (p CoveragePolicy) minimum(pkg string) float64 {
+	for _, t := range p.Thresholds {
+		if t.Pattern.MatchString(pkg) {
+			return t.Minimum
+		}
+	}
+	return p.Minimum
+}
+
+// ParseCoverageThresholds parses a list of coverage thresholds from the given
+// io.Reader r. Each line contains a package name pattern followed by
+// whitespace and the minimum coverage percentage of the matching packages,
+// for example:
+//
+//	example.com/module/internal/db  85
+//	example.com/module/legacy/.*    0
+//
+// Patterns are regular expressions that must match the entire package name.
+// Empty lines and lines starting with # are ignored.
+func ParseCoverageThresholds(r io.Reader) ([]CoverageThreshold, error) {
+	var thresholds []CoverageThreshold
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid threshold on line %d: want a pattern and a minimum coverage", n)
+		}
+		pattern, err := regexp.Compile("^(?:" + fields[0] + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern on line %d: %w", n, err)
+		}
+		min, err := strconv.ParseFloat(strings.TrimSuffix(fields[1], "%"), 64)
+		if err != nil || min < 0 || min > 100 {
+			return nil, fmt.Errorf("invalid minimum coverage on line %d: %q", n, fields[1])
+		}
+		thresholds = append(thresholds, CoverageThreshold{Pattern: pattern, Minimum: min})
+	}
+	return thresholds, s.Err()
+}
+
+// EnforceCoverage returns a copy of report r in which every package whose
+// coverage is below its minimum in policy p is given a failed test named
+// CoverageTestName, so that the report is no longer successful. The failure
+// message of the test contains the coverage and the minimum, and every
+// checked package is marked with a "coverage.minimum" property.
+//
+// Packages without tests and packages that failed to build or run are not
+// checked, since their coverage isn't known. Note that the coverage of a
+// package is only reported by go test -cover.
+func EnforceCoverage(r Report, p CoveragePolicy) Report {
+	if p.Minimum <= 0 && len(p.Thresholds) == 0 {
+		return r
+	}
+	enforced := r
+	enforced.Packages = make([]Package, len(r.Packages))
+	for i, pkg := range r.Packages {
+		min := p.minimum(pkg.Name)
+		if min > 0 && len(pkg.Tests) > 0 && pkg.BuildError.Name == "" && pkg.RunError.Name == "" && pkg.RunError.Kind == "" {
+			pkg.Properties = copyProperties(pkg.Properties)
+			pkg.SetProperty("coverage.minimum", strconv.FormatFloat(min, 'f', -1, 64))
+			if pkg.Coverage < min {
+				id := 0
+				for _, t := range pkg.Tests {
+					if t.ID > id {
+						id = t.ID
+					}
+				}
+				pkg.Tests = append(pkg.Tests[:len(pkg.Tests):len(pkg.Tests)], Test{
+					ID:             id + 1,
+					Name:           CoverageTestName,
+					Result:         Fail,
+					FailureMessage: fmt.Sprintf("coverage %.1f%% is below the minimum of %.1f%%", pkg.Coverage, min),
+					FailureType:    "coverage",
+				})
+			}
+		}
+		enforced.Packages[i] = pkg
+	}
+	return enforced
+}
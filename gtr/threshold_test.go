@@ -0,0 +1,87 @@
+package gtr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseCoverageThresholds(t *testing.T) {
+	input := `# critical packages
+example.com/db    85
+
+example.com/legacy/.*  0%
+`
+	thresholds, err := ParseCoverageThresholds(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseCoverageThresholds error: %v", err)
+	}
+
+	type threshold struct {
+		Pattern string
+		Minimum float64
+	}
+	want := []threshold{
+		{"^(?:example.com/db)$", 85},
+		{"^(?:example.com/legacy/.*)$", 0},
+	}
+	var got []threshold
+	for _, t := range thresholds {
+		got = append(got, threshold{t.Pattern.String(), t.Minimum})
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseCoverageThresholds result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	for _, input := range []string{"example.com/db", "example.com/db 85 extra", "example.com/( 85", "example.com/db high", "example.com/db 101"} {
+		if _, err := ParseCoverageThresholds(strings.NewReader(input)); err == nil {
+			t.Errorf("ParseCoverageThresholds(%q) did not return an error", input)
+		}
+	}
+}
+
+func TestEnforceCoverage(t *testing.T) {
+	thresholds, err := ParseCoverageThresholds(strings.NewReader("package/critical 90\npackage/legacy 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := CoveragePolicy{Minimum: 50, Thresholds: thresholds}
+
+	pass := []Test{{ID: 1, Name: "TestA", Result: Pass}, {ID: 2, Name: "TestB", Result: Pass}}
+	report := Report{Packages: []Package{
+		{Name: "package/covered", Coverage: 60, Tests: pass},
+		{Name: "package/low", Coverage: 40, Tests: pass},
+		{Name: "package/critical", Coverage: 80, Tests: pass},
+		{Name: "package/legacy", Coverage: 10, Tests: pass},
+		{Name: "package/notests"},
+		{Name: "package/build", BuildError: Error{Name: "package/build"}, Tests: pass},
+	}}
+	got := EnforceCoverage(report, policy)
+
+	minimum := func(value string) []Property { return []Property{{Name: "coverage.minimum", Value: value}} }
+	failed := func(msg string) []Test {
+		return append(pass[:2:2], Test{ID: 3, Name: CoverageTestName, Result: Fail, FailureMessage: msg, FailureType: "coverage"})
+	}
+	want := Report{Packages: []Package{
+		{Name: "package/covered", Coverage: 60, Tests: pass, Properties: minimum("50")},
+		{Name: "package/low", Coverage: 40, Tests: failed("coverage 40.0% is below the minimum of 50.0%"), Properties: minimum("50")},
+		{Name: "package/critical", Coverage: 80, Tests: failed("coverage 80.0% is below the minimum of 90.0%"), Properties: minimum("90")},
+		{Name: "package/legacy", Coverage: 10, Tests: pass},
+		{Name: "package/notests"},
+		{Name: "package/build", BuildError: Error{Name: "package/build"}, Tests: pass},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("EnforceCoverage result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if got.IsSuccessful() {
+		t.Errorf("report with coverage below the minimum is successful")
+	}
+	if len(report.Packages[1].Tests) != 2 || report.Packages[0].Properties != nil {
+		t.Errorf("EnforceCoverage modified its input report")
+	}
+
+	if diff := cmp.Diff(report, EnforceCoverage(report, CoveragePolicy{})); diff != "" {
+		t.Errorf("EnforceCoverage with empty policy changed the report, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,53 @@
+package gtr
+
+import "strings"
+
+// TestNode is a test together with its subtests.
+type TestNode struct {
+	Test
+	Children []*TestNode
+}
+
+// TestTree returns the tests of package p as a tree, in which each subtest is
+// a child of its closest parent test. Subtests are matched to their parent by
+// name, for example TestA/sub/case is a child of TestA/sub, or of TestA if
+// there is no TestA/sub. Tests without a parent are returned as roots. The
+// order of tests is preserved at every level of the tree.
+func (p Package) TestTree() []*TestNode {
+	var roots []*TestNode
+	byName := make(map[string]*TestNode)
+	for _, test := range p.Tests {
+		n := &TestNode{Test: test}
+		if parent := findParent(byName, test.Name); parent != nil {
+			parent.Children = append(parent.Children, n)
+		} else {
+			roots = append(roots, n)
+		}
+		byName[test.Name] = n
+	}
+	return roots
+}
+
+// TopLevelName returns the name of the top-level test of the test or subtest
+// with the given name, e.g. TestA for TestA/b/c.
+func TopLevelName(name string) string {
+	if idx := strings.IndexByte(name, '/'); idx >= 0 {
+		return name[:idx]
+	}
+	return name
+}
+
+// findParent returns the node of the closest parent of the test with the given
+// name, or nil if it has no parent.
+func findParent(byName map[string]*TestNode, name string) *TestNode {
+	for {
+		idx := strings.LastIndexByte(name, '/')
+		if idx < 0 {
+			return nil
+		}
+		name = name[:idx]
+		if n, ok := byName[name]; ok {
+			return n
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package gtr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTestTree(t *testing.T) {
+	pkg := Package{Tests: []Test{
+		{Name: "TestA"},
+		{Name: "TestA/sub", Level: 1},
+		{Name: "TestA/sub/case", Level: 2},
+		{Name: "TestA/other/case", Level: 2},
+		{Name: "TestB"},
+		{Name: "TestC/orphan", Level: 1},
+	}}
+
+	want := []*TestNode{
+		{
+			Test: Test{Name: "TestA"},
+			Children: []*TestNode{
+				{
+					Test:     Test{Name: "TestA/sub", Level: 1},
+					Children: []*TestNode{{Test: Test{Name: "TestA/sub/case", Level: 2}}},
+				},
+				{Test: Test{Name: "TestA/other/case", Level: 2}},
+			},
+		},
+		{Test: Test{Name: "TestB"}},
+		{Test: Test{Name: "TestC/orphan", Level: 1}},
+	}
+	if diff := cmp.Diff(want, pkg.TestTree()); diff != "" {
+		t.Errorf("TestTree result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestTopLevelName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"TestA", "TestA"},
+		{"TestA/sub", "TestA"},
+		{"TestA/sub/case", "TestA"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := TopLevelName(test.name); got != test.want {
+			t.Errorf("TopLevelName(%q) incorrect, got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package gtr
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// TruncateMode determines which part of the output is kept when it's
+// truncated.
+type TruncateMode string
+
+// Supported truncate modes.
+const (
+	// TruncateTail keeps the end of the output, which usually contains the
+	// reason a test failed. It's the default truncate mode.
+	TruncateTail TruncateMode = "tail"
+
+	// TruncateHead keeps the beginning of the output.
+	TruncateHead TruncateMode = "head"
+
+	// TruncateHeadTail keeps both the beginning and the end of the output,
+	// each taking up half of the limit.
+	TruncateHeadTail TruncateMode = "head-tail"
+)
+
+// ParseTruncateMode returns the TruncateMode with the given name.
+func ParseTruncateMode(name string) (TruncateMode, error) {
+	switch mode := TruncateMode(name); mode {
+	case TruncateTail, TruncateHead, TruncateHeadTail:
+		return mode, nil
+	case "":
+		return TruncateTail, nil
+	default:
+		return "", fmt.Errorf("unknown truncate mode: %s", name)
+	}
+}
+
+// OutputLimits limits the size of the output of tests and packages. A limit
+// of 0 means there is no limit. Byte limits include the newline after each
+// line.
+type OutputLimits struct {
+	TestLines    int
+	TestBytes    int
+	PackageLines int // applies to package output and build and run errors
+	PackageBytes int
+	Mode         TruncateMode // TruncateTail if empty
+}
+
+func (l OutputLimits) enabled() bool {
+	return l.TestLines > 0 || l.TestBytes > 0 || l.PackageLines > 0 || l.PackageBytes > 0
+}
+
+// TruncateOutput returns a copy of report r in which all output exceeding the
+// given limits has been truncated. The Output and Stderr of a test or package,
+// the output of each attempt of a test and the stack of a panic are limited
+// separately. The removed lines are replaced by a single marker
+// line mentioning how many lines were removed, and truncated tests and
+// packages are marked with an "output.truncated" property containing the
+// number of removed bytes.
+func TruncateOutput(r Report, l OutputLimits) Report {
+	if !l.enabled() {
+		return r
+	}
+	truncated := r
+	truncated.Packages = make([]Package, len(r.Packages))
+	for i, pkg := range r.Packages {
+		var removed, n int
+		pkg.Output, n = TruncateLines(pkg.Output, l.PackageLines, l.PackageBytes, l.Mode)
+		removed += n
+		pkg.Stderr, n = TruncateLines(pkg.Stderr, l.PackageLines, l.PackageBytes, l.Mode)
+		removed += n
+		pkg.BuildError.Output, n = TruncateLines(pkg.BuildError.Output, l.PackageLines, l.PackageBytes, l.Mode)
+		removed += n
+		pkg.BuildError.Panic, n = truncatePanic(pkg.BuildError.Panic, l.PackageLines, l.PackageBytes, l.Mode)
+		removed += n
+		pkg.RunError.Output, n = TruncateLines(pkg.RunError.Output, l.PackageLines, l.PackageBytes, l.Mode)
+		removed += n
+		pkg.RunError.Panic, n = truncatePanic(pkg.RunError.Panic, l.PackageLines, l.PackageBytes, l.Mode)
+		removed += n
+		if removed > 0 {
+			pkg.Properties = copyProperties(pkg.Properties)
+			pkg.SetProperty("output.truncated", strconv.Itoa(removed))
+		}
+
+		if pkg.Tests != nil {
+			tests := make([]Test, len(pkg.Tests))
+			for j, t := range pkg.Tests {
+				var testRemoved int
+				t.Output, n = TruncateLines(t.Output, l.TestLines, l.TestBytes, l.Mode)
+				testRemoved += n
+				t.Stderr, n = TruncateLines(t.Stderr, l.TestLines, l.TestBytes, l.Mode)
+				testRemoved += n
+				t.Panic, n = truncatePanic(t.Panic, l.TestLines, l.TestBytes, l.Mode)
+				testRemoved += n
+				if t.Attempts != nil {
+					attempts := make([]TestAttempt, len(t.Attempts))
+					for k, a := range t.Attempts {
+						a.Output, n = TruncateLines(a.Output, l.TestLines, l.TestBytes, l.Mode)
+						testRemoved += n
+						attempts[k] = a
+					}
+					t.Attempts = attempts
+				}
+				if testRemoved > 0 {
+					t.Properties = copyProperties(t.Properties)
+					t.SetProperty("output.truncated", strconv.Itoa(testRemoved))
+				}
+				tests[j] = t
+			}
+			pkg.Tests = tests
+		}
+		truncated.Packages[i] = pkg
+	}
+	return truncated
+}
+
+// truncatePanic returns p with its stack truncated to the given limits and
+// the number of removed bytes. p itself is not modified.
+func truncatePanic(p *PanicInfo, maxLines, maxBytes int, mode TruncateMode) (*PanicInfo, int) {
+	if p == nil {
+		return nil, 0
+	}
+	stack, n := TruncateLines(p.Stack, maxLines, maxBytes, mode)
+	if n == 0 {
+		return p, 0
+	}
+	truncated := *p
+	truncated.Stack = stack
+	return &truncated, n
+}
+
+// TruncateLines limits lines to at most maxLines lines containing at most
+// maxBytes bytes, not counting the marker line that replaces the removed
+// lines. It returns the truncated lines and the number of removed bytes. When
+// nothing needs to be removed, lines is returned as is. A line that doesn't
+// fit by itself is shortened, so that some output is always kept.
+func TruncateLines(lines []string, maxLines, maxBytes int, mode TruncateMode) ([]string, int) {
+	total := outputBytes(lines)
+	if (maxLines <= 0 || len(lines) <= maxLines) && (maxBytes <= 0 || total <= maxBytes) {
+		return lines, 0
+	}
+	if maxLines <= 0 || maxLines > len(lines) {
+		maxLines = len(lines)
+	}
+	if maxBytes <= 0 || maxBytes > total {
+		maxBytes = total
+	}
+
+	var head, tail []string
+	switch mode {
+	case TruncateHead:
+		head = keepHead(lines, maxLines, maxBytes)
+	case TruncateHeadTail:
+		head = keepHead(lines, (maxLines+1)/2, (maxBytes+1)/2)
+		tail = keepTail(lines[len(head):], maxLines/2, maxBytes/2)
+	default:
+		tail = keepTail(lines, maxLines, maxBytes)
+	}
+
+	removedLines := len(lines) - len(head) - len(tail)
+	kept := outputBytes(head) + outputBytes(tail)
+	removedBytes := total - kept
+	if removedLines == 0 && removedBytes == 0 {
+		return lines, 0
+	}
+
+	result := make([]string, 0, len(head)+len(tail)+1)
+	result = append(result, head...)
+	result = append(result, fmt.Sprintf("... [%d lines, %d bytes truncated] ...", removedLines, removedBytes))
+	result = append(result, tail...)
+	return result, removedBytes
+}
+
+// keepHead returns the first lines containing at most maxLines lines and
+// maxBytes bytes.
+func keepHead(lines []string, maxLines, maxBytes int) []string {
+	var kept []string
+	size := 0
+	for _, line := range lines {
+		if len(kept) == maxLines {
+			break
+		}
+		if size+len(line)+1 > maxBytes {
+			if len(kept) == 0 && maxBytes > 1 {
+				end := maxBytes - 1
+				for end > 0 && !utf8.RuneStart(line[end]) {
+					end--
+				}
+				kept = append(kept, line[:end])
+			}
+			break
+		}
+		kept = append(kept, line)
+		size += len(line) + 1
+	}
+	return kept
+}
+
+// keepTail returns the last lines containing at most maxLines lines and
+// maxBytes bytes.
+func keepTail(lines []string, maxLines, maxBytes int) []string {
+	start := len(lines)
+	size := 0
+	for start > 0 && len(lines)-start < maxLines {
+		line := lines[start-1]
+		if size+len(line)+1 > maxBytes {
+			if start == len(lines) && maxBytes > 1 {
+				return []string{line[runeStart(line, len(line)-maxBytes+1):]}
+			}
+			break
+		}
+		start--
+		size += len(line) + 1
+	}
+	return lines[start:]
+}
+
+// runeStart returns the index of the first rune in s that starts at or after
+// index i, so that s can be split there without splitting a rune.
+func runeStart(s string, i int) int {
+	for i < len(s) && !utf8.RuneStart(s[i]) {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,123 @@
+package gtr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTruncateLines(t *testing.T) {
+	lines := []string{"one", "two", "three", "four", "five"}
+	tests := []struct {
+		name            string
+		maxLines        int
+		maxBytes        int
+		mode            TruncateMode
+		want            []string
+		wantRemovedSize int
+	}{
+		{"no limits", 0, 0, TruncateTail, lines, 0},
+		{"within limits", 5, 24, TruncateTail, lines, 0},
+		{"tail lines", 2, 0, TruncateTail, []string{"... [3 lines, 14 bytes truncated] ...", "four", "five"}, 14},
+		{"default mode", 2, 0, "", []string{"... [3 lines, 14 bytes truncated] ...", "four", "five"}, 14},
+		{"head lines", 2, 0, TruncateHead, []string{"one", "two", "... [3 lines, 16 bytes truncated] ..."}, 16},
+		{"head-tail lines", 3, 0, TruncateHeadTail, []string{"one", "two", "... [2 lines, 11 bytes truncated] ...", "five"}, 11},
+		{"tail bytes", 0, 10, TruncateTail, []string{"... [3 lines, 14 bytes truncated] ...", "four", "five"}, 14},
+		{"head bytes", 0, 9, TruncateHead, []string{"one", "two", "... [3 lines, 16 bytes truncated] ..."}, 16},
+		{"long line", 0, 3, TruncateTail, []string{"... [4 lines, 21 bytes truncated] ...", "ve"}, 21},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, removed := TruncateLines(lines, test.maxLines, test.maxBytes, test.mode)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("TruncateLines output incorrect, diff (-want +got):\n%s\n", diff)
+			}
+			if removed != test.wantRemovedSize {
+				t.Errorf("TruncateLines removed %d bytes, want %d", removed, test.wantRemovedSize)
+			}
+		})
+	}
+}
+
+func TestTruncateLinesMultibyte(t *testing.T) {
+	lines := []string{strings.Repeat("é", 4)}
+	got, _ := TruncateLines(lines, 0, 4, TruncateHead)
+	if got[0] != "é" {
+		t.Errorf("TruncateLines(head) kept %q, want %q", got[0], "é")
+	}
+	got, _ = TruncateLines(lines, 0, 4, TruncateTail)
+	if got[1] != "é" {
+		t.Errorf("TruncateLines(tail) kept %q, want %q", got[1], "é")
+	}
+}
+
+func TestTruncateOutput(t *testing.T) {
+	report := Report{Packages: []Package{{
+		Name:       "package/name",
+		Output:     []string{"a", "b", "c"},
+		BuildError: Error{Output: []string{"d", "e"}},
+		RunError:   Error{Panic: &PanicInfo{Message: "boom", Stack: []string{"p1", "p2", "p3"}}},
+		Tests: []Test{
+			{Name: "TestOne", Output: []string{"1", "2", "3"}},
+			{Name: "TestTwo", Output: []string{"1"}, Stderr: []string{"e1", "e2"}},
+			{
+				Name:     "TestThree",
+				Attempts: []TestAttempt{{Result: Fail, Output: []string{"a1", "a2"}}, {Result: Pass}},
+				Panic:    &PanicInfo{Message: "oops", Test: "TestThree", Stack: []string{"s1", "s2"}},
+			},
+		},
+	}}}
+
+	want := Report{Packages: []Package{{
+		Name:       "package/name",
+		Output:     []string{"... [1 lines, 2 bytes truncated] ...", "b", "c"},
+		BuildError: Error{Output: []string{"d", "e"}},
+		RunError:   Error{Panic: &PanicInfo{Message: "boom", Stack: []string{"... [1 lines, 3 bytes truncated] ...", "p2", "p3"}}},
+		Properties: []Property{{Name: "output.truncated", Value: "5"}},
+		Tests: []Test{
+			{
+				Name:       "TestOne",
+				Output:     []string{"... [2 lines, 4 bytes truncated] ...", "3"},
+				Properties: []Property{{Name: "output.truncated", Value: "4"}},
+			},
+			{
+				Name:       "TestTwo",
+				Output:     []string{"1"},
+				Stderr:     []string{"... [1 lines, 3 bytes truncated] ...", "e2"},
+				Properties: []Property{{Name: "output.truncated", Value: "3"}},
+			},
+			{
+				Name: "TestThree",
+				Attempts: []TestAttempt{
+					{Result: Fail, Output: []string{"... [1 lines, 3 bytes truncated] ...", "a2"}},
+					{Result: Pass},
+				},
+				Panic:      &PanicInfo{Message: "oops", Test: "TestThree", Stack: []string{"... [1 lines, 3 bytes truncated] ...", "s2"}},
+				Properties: []Property{{Name: "output.truncated", Value: "6"}},
+			},
+		},
+	}}}
+
+	got := TruncateOutput(report, OutputLimits{TestLines: 1, PackageLines: 2})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("TruncateOutput result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if len(report.Packages[0].Tests[0].Output) != 3 ||
+		len(report.Packages[0].Tests[2].Attempts[0].Output) != 2 ||
+		len(report.Packages[0].Tests[2].Panic.Stack) != 2 {
+		t.Errorf("TruncateOutput modified the original report")
+	}
+}
+
+func TestParseTruncateMode(t *testing.T) {
+	for _, name := range []string{"", "tail", "head", "head-tail"} {
+		if _, err := ParseTruncateMode(name); err != nil {
+			t.Errorf("ParseTruncateMode(%q) returned an unexpected error: %v", name, err)
+		}
+	}
+	if _, err := ParseTruncateMode("middle"); err == nil {
+		t.Errorf("ParseTruncateMode(%q) did not return an error", "middle")
+	}
+}
@@ -0,0 +1,68 @@
+package gtr
+
+import "fmt"
+
+// Kinds of problems found by Validate.
+const (
+	ProblemDuplicateTest    = "duplicate-test"    // a package contains more than one test with the same name
+	ProblemNegativeDuration = "negative-duration" // a package or test has a negative duration
+	ProblemNoResult         = "no-result"         // a test has no result
+	ProblemZeroDuration     = "zero-duration"     // a package with tests has no duration
+)
+
+// Problem is a structural problem of a report found by Validate.
+type Problem struct {
+	TestRef        // package and test that have the problem, Test is empty for problems of a package
+	Kind    string // kind of problem, one of the Problem constants
+	Message string
+}
+
+// String returns a description of problem p, prefixed by the package and test
+// that have the problem.
+func (p Problem) String() string {
+	name := p.Package
+	if p.Test != "" {
+		name += "." + p.Test
+	}
+	return fmt.Sprintf("%s: %s", name, p.Message)
+}
+
+// Validate checks report r for structural problems that usually indicate
+// malformed input or a bug in the parser that created it, and returns the
+// problems it found in report order. It reports tests that have the same name
+// as an earlier test in their package, negative durations, tests without a
+// result and packages that contain tests but have no duration.
+//
+// Some of these problems have legitimate causes. For example, go test -count
+// runs tests more than once unless they're grouped using GroupAttempts, and
+// the duration of cached packages isn't known.
+func (r Report) Validate() []Problem {
+	var problems []Problem
+	add := func(pkg, test, kind, format string, args ...interface{}) {
+		problems = append(problems, Problem{TestRef{pkg, test}, kind, fmt.Sprintf(format, args...)})
+	}
+	for _, pkg := range r.Packages {
+		if pkg.Duration < 0 {
+			add(pkg.Name, "", ProblemNegativeDuration, "negative duration %v", pkg.Duration)
+		} else if pkg.Duration == 0 && len(pkg.Tests) > 0 {
+			add(pkg.Name, "", ProblemZeroDuration, "package contains %d tests but has no duration", len(pkg.Tests))
+		}
+		if pkg.BuildDuration < 0 {
+			add(pkg.Name, "", ProblemNegativeDuration, "negative build duration %v", pkg.BuildDuration)
+		}
+		seen := make(map[string]bool)
+		for _, t := range pkg.Tests {
+			if seen[t.Name] {
+				add(pkg.Name, t.Name, ProblemDuplicateTest, "duplicate test name")
+			}
+			seen[t.Name] = true
+			if t.Duration < 0 {
+				add(pkg.Name, t.Name, ProblemNegativeDuration, "negative duration %v", t.Duration)
+			}
+			if t.Result == Unknown {
+				add(pkg.Name, t.Name, ProblemNoResult, "no test result found")
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,41 @@
+package gtr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidate(t *testing.T) {
+	report := Report{Packages: []Package{
+		{Name: "package/ok", Duration: time.Second, Tests: []Test{
+			{Name: "TestA", Result: Pass, Duration: time.Second},
+		}},
+		{Name: "package/bad", Duration: -time.Second, Tests: []Test{
+			{Name: "TestA", Result: Pass},
+			{Name: "TestB", Result: Unknown},
+			{Name: "TestA", Result: Fail, Duration: -time.Millisecond},
+		}},
+		{Name: "package/zero", Tests: []Test{{Name: "TestC", Result: Skip}}},
+		{Name: "package/empty", BuildDuration: -time.Second},
+	}}
+
+	want := []Problem{
+		{TestRef{"package/bad", ""}, ProblemNegativeDuration, "negative duration -1s"},
+		{TestRef{"package/bad", "TestB"}, ProblemNoResult, "no test result found"},
+		{TestRef{"package/bad", "TestA"}, ProblemDuplicateTest, "duplicate test name"},
+		{TestRef{"package/bad", "TestA"}, ProblemNegativeDuration, "negative duration -1ms"},
+		{TestRef{"package/zero", ""}, ProblemZeroDuration, "package contains 1 tests but has no duration"},
+		{TestRef{"package/empty", ""}, ProblemNegativeDuration, "negative build duration -1s"},
+	}
+	if diff := cmp.Diff(want, report.Validate()); diff != "" {
+		t.Errorf("Validate result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if got := want[2].String(); got != "package/bad.TestA: duplicate test name" {
+		t.Errorf("Problem.String() = %q", got)
+	}
+	if got := (Report{Packages: report.Packages[:1]}).Validate(); got != nil {
+		t.Errorf("Validate found problems in valid report: %v", got)
+	}
+}
@@ -0,0 +1,84 @@
+package gtrjson
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+type diffJSON struct {
+	Version   int              `json:"version"`
+	Added     []testRef        `json:"added,omitempty"`
+	Removed   []testRef        `json:"removed,omitempty"`
+	Results   []resultChange   `json:"results,omitempty"`
+	Durations []durationChange `json:"durations,omitempty"`
+}
+
+type testRef struct {
+	Package string `json:"package"`
+	Test    string `json:"test"`
+}
+
+type resultChange struct {
+	testRef
+	Before    string `json:"before"`
+	After     string `json:"after"`
+	Regressed bool   `json:"regressed,omitempty"`
+	Fixed     bool   `json:"fixed,omitempty"`
+}
+
+type durationChange struct {
+	testRef
+	Before int64 `json:"before_nanos"`
+	After  int64 `json:"after_nanos"`
+}
+
+// WriteDiff writes the indented JSON encoding of report diff d to w. The diff
+// is an object with the same version as reports, and lists of added and
+// removed tests, result changes and duration changes, using the same naming
+// conventions and encoding of results and durations as reports. Result
+// changes have a regressed or fixed field set to true if the test started or
+// stopped failing.
+func WriteDiff(w io.Writer, d gtr.ReportDiff) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(encodeDiff(d))
+}
+
+func encodeDiff(d gtr.ReportDiff) diffJSON {
+	diff := diffJSON{
+		Version: Version,
+		Added:   encodeTestRefs(d.Added),
+		Removed: encodeTestRefs(d.Removed),
+	}
+	for _, c := range d.Results {
+		diff.Results = append(diff.Results, resultChange{
+			testRef:   encodeTestRef(c.TestRef),
+			Before:    encodeResult(c.Before),
+			After:     encodeResult(c.After),
+			Regressed: c.Regressed(),
+			Fixed:     c.Fixed(),
+		})
+	}
+	for _, c := range d.Durations {
+		diff.Durations = append(diff.Durations, durationChange{
+			testRef: encodeTestRef(c.TestRef),
+			Before:  int64(c.Before),
+			After:   int64(c.After),
+		})
+	}
+	return diff
+}
+
+func encodeTestRefs(refs []gtr.TestRef) []testRef {
+	var out []testRef
+	for _, ref := range refs {
+		out = append(out, encodeTestRef(ref))
+	}
+	return out
+}
+
+func encodeTestRef(ref gtr.TestRef) testRef {
+	return testRef{Package: ref.Package, Test: ref.Test}
+}
@@ -0,0 +1,58 @@
+package gtrjson
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteDiff(t *testing.T) {
+	d := gtr.ReportDiff{
+		Added: []gtr.TestRef{{Package: "package/one", Test: "TestAdded"}},
+		Results: []gtr.ResultChange{
+			{TestRef: gtr.TestRef{Package: "package/one", Test: "TestFixed"}, Before: gtr.Fail, After: gtr.Pass},
+		},
+		Durations: []gtr.DurationChange{
+			{TestRef: gtr.TestRef{Package: "package/one", Test: "TestSlower"}, Before: time.Second, After: 2 * time.Second},
+		},
+	}
+
+	want := `{
+	"version": 1,
+	"added": [
+		{
+			"package": "package/one",
+			"test": "TestAdded"
+		}
+	],
+	"results": [
+		{
+			"package": "package/one",
+			"test": "TestFixed",
+			"before": "fail",
+			"after": "pass",
+			"fixed": true
+		}
+	],
+	"durations": [
+		{
+			"package": "package/one",
+			"test": "TestSlower",
+			"before_nanos": 1000000000,
+			"after_nanos": 2000000000
+		}
+	]
+}
+`
+	var buf bytes.Buffer
+	if err := WriteDiff(&buf, d); err != nil {
+		t.Fatalf("WriteDiff failed: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("WriteDiff output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,439 @@
+// Package gtrjson writes and reads gtr.Reports as JSON, using a versioned
+// schema that is independent of the Go types in package gtr.
+//
+// The schema is described by schema.json. A report is an object with a
+// version and a list of packages. All field names use snake_case, fields with
+// default values are omitted, durations are stored as nanoseconds, timestamps
+// as RFC 3339 strings with nanosecond precision, and results as lowercase
+// strings: "unknown", "pass", "fail", "skip" or "flaky". The Data field of
+// gtr.Test is not stored.
+//
+// Fields may be added to the schema without changing its version. Readers
+// should ignore fields they don't know. The version is only incremented for
+// changes that are not backwards compatible, and Unmarshal rejects reports
+// with a version it doesn't support.
+package gtrjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// Version is the version of the schema written by this package.
+const Version = 1
+
+type report struct {
+	Version    int       `json:"version"`
+	Hostname   string    `json:"hostname,omitempty"`
+	ShardIndex int       `json:"shard_index,omitempty"`
+	ShardCount int       `json:"shard_count,omitempty"`
+	RunMeta    *runMeta  `json:"run_meta,omitempty"`
+	Aborted    bool      `json:"aborted,omitempty"`
+	Packages   []pkgJSON `json:"packages,omitempty"`
+}
+
+type runMeta struct {
+	ExitCode int    `json:"exit_code"`
+	Signal   string `json:"signal,omitempty"`
+}
+
+type pkgJSON struct {
+	Name          string       `json:"name"`
+	Timestamp     string       `json:"timestamp,omitempty"`
+	StartTime     string       `json:"start_time,omitempty"`
+	EndTime       string       `json:"end_time,omitempty"`
+	Duration      int64        `json:"duration_nanos,omitempty"`
+	BuildDuration int64        `json:"build_duration_nanos,omitempty"`
+	Coverage      float64      `json:"coverage,omitempty"`
+	MaxParallel   int          `json:"max_parallel,omitempty"`
+	Hostname      string       `json:"hostname,omitempty"`
+	ShardIndex    int          `json:"shard_index,omitempty"`
+	ShardCount    int          `json:"shard_count,omitempty"`
+	NoTestFiles   bool         `json:"no_test_files,omitempty"`
+	Cached        bool         `json:"cached,omitempty"`
+	Output        []string     `json:"output,omitempty"`
+	Stderr        []string     `json:"stderr,omitempty"`
+	Properties    []property   `json:"properties,omitempty"`
+	Attachments   []attachment `json:"attachments,omitempty"`
+	Tests         []test       `json:"tests,omitempty"`
+	BuildError    *errorJSON   `json:"build_error,omitempty"`
+	RunError      *errorJSON   `json:"run_error,omitempty"`
+}
+
+type attachment struct {
+	Name string `json:"name,omitempty"`
+	Path string `json:"path"`
+	MIME string `json:"mime,omitempty"`
+}
+
+type property struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type test struct {
+	ID             int          `json:"id,omitempty"`
+	Name           string       `json:"name"`
+	StartTime      string       `json:"start_time,omitempty"`
+	EndTime        string       `json:"end_time,omitempty"`
+	Duration       int64        `json:"duration_nanos,omitempty"`
+	RunDuration    int64        `json:"run_duration_nanos,omitempty"`
+	WallDuration   int64        `json:"wall_duration_nanos,omitempty"`
+	Result         string       `json:"result"`
+	Level          int          `json:"level,omitempty"`
+	Kind           string       `json:"kind,omitempty"`
+	File           string       `json:"file,omitempty"`
+	Line           int          `json:"line,omitempty"`
+	Output         []string     `json:"output,omitempty"`
+	Stderr         []string     `json:"stderr,omitempty"`
+	Properties     []property   `json:"properties,omitempty"`
+	Attachments    []attachment `json:"attachments,omitempty"`
+	Attempts       []attempt    `json:"attempts,omitempty"`
+	Panic          *panicInfo   `json:"panic,omitempty"`
+	SkipMessage    string       `json:"skip_message,omitempty"`
+	FailureMessage string       `json:"failure_message,omitempty"`
+	FailureType    string       `json:"failure_type,omitempty"`
+}
+
+type attempt struct {
+	Result   string   `json:"result"`
+	Duration int64    `json:"duration_nanos,omitempty"`
+	Output   []string `json:"output,omitempty"`
+}
+
+type errorJSON struct {
+	ID          int          `json:"id,omitempty"`
+	Name        string       `json:"name,omitempty"`
+	Kind        string       `json:"kind,omitempty"`
+	Duration    int64        `json:"duration_nanos,omitempty"`
+	Cause       string       `json:"cause,omitempty"`
+	Output      []string     `json:"output,omitempty"`
+	Panic       *panicInfo   `json:"panic,omitempty"`
+	Diagnostics []diagnostic `json:"diagnostics,omitempty"`
+}
+
+type diagnostic struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+type panicInfo struct {
+	Message string   `json:"message"`
+	Test    string   `json:"test,omitempty"`
+	Stack   []string `json:"stack,omitempty"`
+}
+
+// Marshal returns the JSON encoding of report r.
+func Marshal(r gtr.Report) ([]byte, error) {
+	return json.Marshal(encodeReport(r))
+}
+
+// Unmarshal parses the JSON encoded data and returns the report it contains.
+func Unmarshal(data []byte) (gtr.Report, error) {
+	var rep report
+	if err := json.Unmarshal(data, &rep); err != nil {
+		return gtr.Report{}, err
+	}
+	if rep.Version != Version {
+		return gtr.Report{}, fmt.Errorf("gtrjson: unsupported version %d", rep.Version)
+	}
+	return decodeReport(rep)
+}
+
+// Write writes the indented JSON encoding of report r to w.
+func Write(w io.Writer, r gtr.Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(encodeReport(r))
+}
+
+// Read reads a JSON encoded report from r.
+func Read(r io.Reader) (gtr.Report, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return gtr.Report{}, err
+	}
+	return Unmarshal(data)
+}
+
+func encodeReport(r gtr.Report) report {
+	rep := report{Version: Version, Hostname: r.Hostname, ShardIndex: r.ShardIndex, ShardCount: r.ShardCount, Aborted: r.Aborted}
+	if r.RunMeta != nil {
+		rep.RunMeta = &runMeta{ExitCode: r.RunMeta.ExitCode, Signal: r.RunMeta.Signal}
+	}
+	for _, pkg := range r.Packages {
+		p := pkgJSON{
+			Name:          pkg.Name,
+			Timestamp:     encodeTime(pkg.Timestamp),
+			StartTime:     encodeTime(pkg.StartTime),
+			EndTime:       encodeTime(pkg.EndTime),
+			Duration:      int64(pkg.Duration),
+			BuildDuration: int64(pkg.BuildDuration),
+			Coverage:      pkg.Coverage,
+			MaxParallel:   pkg.MaxParallel,
+			Hostname:      pkg.Hostname,
+			ShardIndex:    pkg.ShardIndex,
+			ShardCount:    pkg.ShardCount,
+			NoTestFiles:   pkg.NoTestFiles,
+			Cached:        pkg.Cached,
+			Output:        pkg.Output,
+			Stderr:        pkg.Stderr,
+			Properties:    encodeProperties(pkg.Properties),
+			Attachments:   encodeAttachments(pkg.Attachments),
+			BuildError:    encodeError(pkg.BuildError),
+			RunError:      encodeError(pkg.RunError),
+		}
+		for _, t := range pkg.Tests {
+			p.Tests = append(p.Tests, encodeTest(t))
+		}
+		rep.Packages = append(rep.Packages, p)
+	}
+	return rep
+}
+
+func decodeReport(rep report) (gtr.Report, error) {
+	r := gtr.Report{Hostname: rep.Hostname, ShardIndex: rep.ShardIndex, ShardCount: rep.ShardCount, Aborted: rep.Aborted}
+	if rep.RunMeta != nil {
+		r.RunMeta = &gtr.RunMeta{ExitCode: rep.RunMeta.ExitCode, Signal: rep.RunMeta.Signal}
+	}
+	for _, p := range rep.Packages {
+		pkg := gtr.Package{
+			Name:          p.Name,
+			Duration:      time.Duration(p.Duration),
+			BuildDuration: time.Duration(p.BuildDuration),
+			Coverage:      p.Coverage,
+			MaxParallel:   p.MaxParallel,
+			Hostname:      p.Hostname,
+			ShardIndex:    p.ShardIndex,
+			ShardCount:    p.ShardCount,
+			NoTestFiles:   p.NoTestFiles,
+			Cached:        p.Cached,
+			Output:        p.Output,
+			Stderr:        p.Stderr,
+			Properties:    decodeProperties(p.Properties),
+			Attachments:   decodeAttachments(p.Attachments),
+			BuildError:    decodeError(p.BuildError),
+			RunError:      decodeError(p.RunError),
+		}
+		var err error
+		if pkg.Timestamp, err = decodeTime(p.Timestamp); err != nil {
+			return r, err
+		}
+		if pkg.StartTime, err = decodeTime(p.StartTime); err != nil {
+			return r, err
+		}
+		if pkg.EndTime, err = decodeTime(p.EndTime); err != nil {
+			return r, err
+		}
+		for _, t := range p.Tests {
+			test, err := decodeTest(t)
+			if err != nil {
+				return r, err
+			}
+			pkg.Tests = append(pkg.Tests, test)
+		}
+		r.Packages = append(r.Packages, pkg)
+	}
+	return r, nil
+}
+
+func encodeTest(t gtr.Test) test {
+	enc := test{
+		ID:             t.ID,
+		Name:           t.Name,
+		StartTime:      encodeTime(t.StartTime),
+		EndTime:        encodeTime(t.EndTime),
+		Duration:       int64(t.Duration),
+		RunDuration:    int64(t.RunDuration),
+		WallDuration:   int64(t.WallDuration),
+		Result:         encodeResult(t.Result),
+		Level:          t.Level,
+		Kind:           string(t.Kind),
+		File:           t.File,
+		Line:           t.Line,
+		Output:         t.Output,
+		Stderr:         t.Stderr,
+		Properties:     encodeProperties(t.Properties),
+		Attachments:    encodeAttachments(t.Attachments),
+		Panic:          encodePanic(t.Panic),
+		SkipMessage:    t.SkipMessage,
+		FailureMessage: t.FailureMessage,
+		FailureType:    t.FailureType,
+	}
+	for _, a := range t.Attempts {
+		enc.Attempts = append(enc.Attempts, attempt{Result: encodeResult(a.Result), Duration: int64(a.Duration), Output: a.Output})
+	}
+	return enc
+}
+
+func decodeTest(t test) (gtr.Test, error) {
+	dec := gtr.Test{
+		ID:             t.ID,
+		Name:           t.Name,
+		Duration:       time.Duration(t.Duration),
+		RunDuration:    time.Duration(t.RunDuration),
+		WallDuration:   time.Duration(t.WallDuration),
+		Level:          t.Level,
+		Kind:           gtr.TestKind(t.Kind),
+		File:           t.File,
+		Line:           t.Line,
+		Output:         t.Output,
+		Stderr:         t.Stderr,
+		Properties:     decodeProperties(t.Properties),
+		Attachments:    decodeAttachments(t.Attachments),
+		Panic:          decodePanic(t.Panic),
+		SkipMessage:    t.SkipMessage,
+		FailureMessage: t.FailureMessage,
+		FailureType:    t.FailureType,
+	}
+	var err error
+	if dec.Result, err = decodeResult(t.Result); err != nil {
+		return dec, err
+	}
+	if dec.StartTime, err = decodeTime(t.StartTime); err != nil {
+		return dec, err
+	}
+	if dec.EndTime, err = decodeTime(t.EndTime); err != nil {
+		return dec, err
+	}
+	for _, a := range t.Attempts {
+		result, err := decodeResult(a.Result)
+		if err != nil {
+			return dec, err
+		}
+		dec.Attempts = append(dec.Attempts, gtr.TestAttempt{Result: result, Duration: time.Duration(a.Duration), Output: a.Output})
+	}
+	return dec, nil
+}
+
+func encodeProperties(props []gtr.Property) []property {
+	var enc []property
+	for _, p := range props {
+		enc = append(enc, property{Name: p.Name, Value: p.Value})
+	}
+	return enc
+}
+
+func decodeProperties(props []property) []gtr.Property {
+	var dec []gtr.Property
+	for _, p := range props {
+		dec = append(dec, gtr.Property{Name: p.Name, Value: p.Value})
+	}
+	return dec
+}
+
+func encodeAttachments(attachments []gtr.Attachment) []attachment {
+	var enc []attachment
+	for _, a := range attachments {
+		enc = append(enc, attachment{Name: a.Name, Path: a.Path, MIME: a.MIME})
+	}
+	return enc
+}
+
+func decodeAttachments(attachments []attachment) []gtr.Attachment {
+	var dec []gtr.Attachment
+	for _, a := range attachments {
+		dec = append(dec, gtr.Attachment{Name: a.Name, Path: a.Path, MIME: a.MIME})
+	}
+	return dec
+}
+
+func encodeError(e gtr.Error) *errorJSON {
+	if e.ID == 0 && e.Name == "" && e.Kind == "" && e.Duration == 0 && e.Cause == "" && len(e.Output) == 0 && e.Panic == nil && len(e.Diagnostics) == 0 {
+		return nil
+	}
+	return &errorJSON{
+		ID:          e.ID,
+		Name:        e.Name,
+		Kind:        e.Kind,
+		Duration:    int64(e.Duration),
+		Cause:       e.Cause,
+		Output:      e.Output,
+		Panic:       encodePanic(e.Panic),
+		Diagnostics: encodeDiagnostics(e.Diagnostics),
+	}
+}
+
+func decodeError(e *errorJSON) gtr.Error {
+	if e == nil {
+		return gtr.Error{}
+	}
+	return gtr.Error{
+		ID:          e.ID,
+		Name:        e.Name,
+		Kind:        e.Kind,
+		Duration:    time.Duration(e.Duration),
+		Cause:       e.Cause,
+		Output:      e.Output,
+		Panic:       decodePanic(e.Panic),
+		Diagnostics: decodeDiagnostics(e.Diagnostics),
+	}
+}
+
+func encodeDiagnostics(diags []gtr.Diagnostic) []diagnostic {
+	var enc []diagnostic
+	for _, d := range diags {
+		enc = append(enc, diagnostic{File: d.File, Line: d.Line, Column: d.Column, Message: d.Message})
+	}
+	return enc
+}
+
+func decodeDiagnostics(diags []diagnostic) []gtr.Diagnostic {
+	var dec []gtr.Diagnostic
+	for _, d := range diags {
+		dec = append(dec, gtr.Diagnostic{File: d.File, Line: d.Line, Column: d.Column, Message: d.Message})
+	}
+	return dec
+}
+
+func encodePanic(p *gtr.PanicInfo) *panicInfo {
+	if p == nil {
+		return nil
+	}
+	return &panicInfo{Message: p.Message, Test: p.Test, Stack: p.Stack}
+}
+
+func decodePanic(p *panicInfo) *gtr.PanicInfo {
+	if p == nil {
+		return nil
+	}
+	return &gtr.PanicInfo{Message: p.Message, Test: p.Test, Stack: p.Stack}
+}
+
+func encodeResult(r gtr.Result) string {
+	return strings.ToLower(r.String())
+}
+
+func decodeResult(s string) (gtr.Result, error) {
+	r, err := gtr.ParseResult(s)
+	if err != nil {
+		return r, fmt.Errorf("gtrjson: %w", err)
+	}
+	return r, nil
+}
+
+func encodeTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func decodeTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("gtrjson: invalid time: %w", err)
+	}
+	return t, nil
+}
@@ -0,0 +1,73 @@
+package gtrjson
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/gtrtest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarshal(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{{Name: "a", Tests: []gtr.Test{{Name: "TestA", Result: gtr.Pass}}}}}
+	want := `{"version":1,"packages":[{"name":"a","tests":[{"name":"TestA","result":"pass"}]}]}`
+
+	got, err := Marshal(report)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("Marshal result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	want := gtrtest.FullReport()
+
+	data, err := Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	got, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unmarshal result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	want := gtrtest.FullReport()
+
+	var buf bytes.Buffer
+	if err := Write(&buf, want); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Read result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	tests := []string{
+		`{"packages":[]}`,
+		`{"version":2}`,
+		`{"version":1,"packages":[{"name":"a","tests":[{"name":"TestA","result":"bad"}]}]}`,
+		`{"version":1,"packages":[{"name":"a","timestamp":"yesterday"}]}`,
+		`{"version":1`,
+	}
+	for _, data := range tests {
+		if _, err := Unmarshal([]byte(data)); err == nil {
+			t.Errorf("Unmarshal(%s) did not return an error", data)
+		}
+	}
+}
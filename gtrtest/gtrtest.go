@@ -0,0 +1,324 @@
+// Package gtrtest provides report fixtures and golden file comparison for
+// testing custom output formats of gtr.Report.
+//
+// The fixtures returned by Fixtures cover the parts of a report that output
+// formats usually need to handle, such as failed and skipped tests, subtests,
+// flaky tests and build and runtime errors. RunGolden writes every fixture
+// using a writer under test and compares the output to a golden file per
+// fixture:
+//
+//	func TestWrite(t *testing.T) {
+//		gtrtest.RunGolden(t, "testdata", mypkg.Write)
+//	}
+//
+// FullReport returns a report in which every field is set, to check that
+// formats that can be read back don't lose any data.
+//
+// Golden files that don't exist yet, or that need to change because the output
+// was changed on purpose, are written by running the tests with the
+// -gtrtest.update flag:
+//
+//	go test ./mypkg -gtrtest.update
+package gtrtest
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+var update = flag.Bool("gtrtest.update", false, "write the golden files compared by gtrtest instead of comparing them")
+
+// WriteFunc writes report r to w in an output format, like tap.Write.
+type WriteFunc func(w io.Writer, r gtr.Report) error
+
+// Fixture is a named report to be used as test input.
+type Fixture struct {
+	Name   string
+	Report gtr.Report
+}
+
+// timestamp is the time at which the packages of the fixtures ran.
+var timestamp = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Fixtures returns the report fixtures, which are:
+//
+//   - empty: a report without any packages
+//   - pass: a package with passing tests, properties and coverage
+//   - fail: a package with a failed test, a test that panicked and a test
+//     without a result
+//   - skip: a package with skipped tests
+//   - subtests: a package with nested subtests
+//   - flaky: a package with a test that failed and passed in different attempts
+//   - errors: packages with a build error and a runtime error
+//   - multiple: several packages with different results
+//
+// Each call returns new reports, which may be modified by the caller.
+func Fixtures() []Fixture {
+	return []Fixture{
+		{"empty", gtr.Report{}},
+		{"pass", gtr.Report{Packages: []gtr.Package{passPackage()}}},
+		{"fail", gtr.Report{Packages: []gtr.Package{failPackage()}}},
+		{"skip", gtr.Report{Packages: []gtr.Package{skipPackage()}}},
+		{"subtests", gtr.Report{Packages: []gtr.Package{subtestsPackage()}}},
+		{"flaky", gtr.Report{Packages: []gtr.Package{flakyPackage()}}},
+		{"errors", gtr.Report{Packages: []gtr.Package{buildErrorPackage(), runErrorPackage()}}},
+		{"multiple", gtr.Report{Packages: []gtr.Package{passPackage(), failPackage(), skipPackage(), buildErrorPackage()}}},
+	}
+}
+
+// Report returns the report of the fixture with the given name. It panics if
+// no such fixture exists.
+func Report(name string) gtr.Report {
+	for _, f := range Fixtures() {
+		if f.Name == name {
+			return f.Report
+		}
+	}
+	panic("gtrtest: unknown fixture " + name)
+}
+
+func passPackage() gtr.Package {
+	return gtr.Package{
+		Name:       "package/pass",
+		Timestamp:  timestamp,
+		Duration:   150 * time.Millisecond,
+		Coverage:   81.5,
+		Properties: []gtr.Property{{Name: "go.version", Value: "1.0"}},
+		Tests: []gtr.Test{
+			{ID: 1, Name: "TestOne", Duration: 100 * time.Millisecond, Result: gtr.Pass, Output: []string{"    pass_test.go:10: some output"}},
+			{ID: 2, Name: "TestTwo", Duration: 50 * time.Millisecond, Result: gtr.Pass},
+		},
+	}
+}
+
+func failPackage() gtr.Package {
+	return gtr.Package{
+		Name:      "package/fail",
+		Timestamp: timestamp,
+		Duration:  300 * time.Millisecond,
+		Output:    []string{"FAIL", "exit status 1"},
+		Tests: []gtr.Test{
+			{ID: 1, Name: "TestPass", Duration: 100 * time.Millisecond, Result: gtr.Pass},
+			{
+				ID:             2,
+				Name:           "TestFail",
+				Duration:       200 * time.Millisecond,
+				Result:         gtr.Fail,
+				Output:         []string{"    fail_test.go:12: got 1, want 2"},
+				FailureMessage: "got 1, want 2",
+			},
+			{
+				ID:     3,
+				Name:   "TestPanic",
+				Result: gtr.Fail,
+				Output: []string{"panic: runtime error: index out of range [1] with length 1", "", "goroutine 7 [running]:"},
+				Panic: &gtr.PanicInfo{
+					Message: "runtime error: index out of range [1] with length 1",
+					Test:    "TestPanic",
+					Stack:   []string{"goroutine 7 [running]:"},
+				},
+			},
+			{ID: 4, Name: "TestUnknown", Result: gtr.Unknown},
+		},
+	}
+}
+
+func skipPackage() gtr.Package {
+	return gtr.Package{
+		Name:      "package/skip",
+		Timestamp: timestamp,
+		Duration:  10 * time.Millisecond,
+		Tests: []gtr.Test{
+			{ID: 1, Name: "TestSkip", Result: gtr.Skip, Output: []string{"    skip_test.go:5: not supported"}, SkipMessage: "not supported"},
+			{ID: 2, Name: "TestSkipNoMessage", Result: gtr.Skip},
+		},
+	}
+}
+
+func subtestsPackage() gtr.Package {
+	return gtr.Package{
+		Name:      "package/subtests",
+		Timestamp: timestamp,
+		Duration:  60 * time.Millisecond,
+		Output:    []string{"FAIL", "exit status 1"},
+		Tests: []gtr.Test{
+			{ID: 1, Name: "TestParent", Duration: 60 * time.Millisecond, Result: gtr.Fail},
+			{ID: 2, Name: "TestParent/pass", Duration: 10 * time.Millisecond, Result: gtr.Pass, Level: 1},
+			{ID: 3, Name: "TestParent/fail", Duration: 20 * time.Millisecond, Result: gtr.Fail, Level: 1, Output: []string{"        sub_test.go:20: failed"}},
+			{ID: 4, Name: "TestParent/fail/nested", Duration: 20 * time.Millisecond, Result: gtr.Fail, Level: 2},
+			{ID: 5, Name: "TestParent/skip", Result: gtr.Skip, Level: 1},
+		},
+	}
+}
+
+func flakyPackage() gtr.Package {
+	return gtr.Package{
+		Name:      "package/flaky",
+		Timestamp: timestamp,
+		Duration:  30 * time.Millisecond,
+		Tests: []gtr.Test{
+			{
+				ID:       1,
+				Name:     "TestFlaky",
+				Duration: 20 * time.Millisecond,
+				Result:   gtr.Flaky,
+				Output:   []string{"    flaky_test.go:8: timeout"},
+				Attempts: []gtr.TestAttempt{
+					{Result: gtr.Fail, Duration: 10 * time.Millisecond, Output: []string{"    flaky_test.go:8: timeout"}},
+					{Result: gtr.Pass, Duration: 10 * time.Millisecond},
+				},
+			},
+		},
+	}
+}
+
+func buildErrorPackage() gtr.Package {
+	return gtr.Package{
+		Name:      "package/build",
+		Timestamp: timestamp,
+		BuildError: gtr.Error{
+			Name:        "package/build",
+			Cause:       "[build failed]",
+			Output:      []string{"# package/build", "build/build.go:5:2: undefined: x"},
+			Diagnostics: []gtr.Diagnostic{{File: "build/build.go", Line: 5, Column: 2, Message: "undefined: x"}},
+		},
+	}
+}
+
+func runErrorPackage() gtr.Package {
+	return gtr.Package{
+		Name:      "package/run",
+		Timestamp: timestamp,
+		Duration:  5 * time.Millisecond,
+		RunError: gtr.Error{
+			Name:   "package/run",
+			Output: []string{"panic: init failed", "exit status 2"},
+			Panic:  &gtr.PanicInfo{Message: "init failed"},
+		},
+	}
+}
+
+// FullReport returns a report in which every field of every gtr type is set,
+// except for gtr.Test.Data. It's useful to check that an output format that
+// can be read back keeps all data of a report. Each call returns a new report,
+// which may be modified by the caller.
+func FullReport() gtr.Report {
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	panicInfo := &gtr.PanicInfo{Message: "boom", Test: "TestFail", Stack: []string{"goroutine 1 [running]:"}}
+	return gtr.Report{
+		Hostname:   "ci-runner-1",
+		ShardIndex: 1,
+		ShardCount: 3,
+		RunMeta:    &gtr.RunMeta{ExitCode: -1, Signal: "killed"},
+		Aborted:    true,
+		Packages: []gtr.Package{
+			{
+				Name:          "package/name",
+				Timestamp:     time.Date(2022, 6, 26, 0, 0, 0, 0, time.UTC),
+				StartTime:     time.Date(2022, 6, 26, 0, 0, 1, 123456789, zone),
+				EndTime:       time.Date(2022, 6, 26, 0, 0, 2, 0, zone),
+				Duration:      1500 * time.Millisecond,
+				BuildDuration: 200 * time.Millisecond,
+				Coverage:      87.5,
+				MaxParallel:   4,
+				Hostname:      "ci-runner-2",
+				ShardIndex:    2,
+				ShardCount:    3,
+				NoTestFiles:   true,
+				Cached:        true,
+				Output:        []string{"package output"},
+				Stderr:        []string{"package stderr"},
+				Properties:    []gtr.Property{{Name: "go.version", Value: "1.18"}},
+				Attachments:   []gtr.Attachment{{Name: "cpu profile", Path: "cpu.pprof", MIME: "application/octet-stream"}},
+				Tests: []gtr.Test{
+					{
+						ID:             1,
+						Name:           "TestFail",
+						StartTime:      time.Date(2022, 6, 26, 0, 0, 1, 0, time.UTC),
+						EndTime:        time.Date(2022, 6, 26, 0, 0, 2, 0, time.UTC),
+						Duration:       3 * time.Millisecond,
+						RunDuration:    2 * time.Millisecond,
+						WallDuration:   5 * time.Millisecond,
+						Result:         gtr.Flaky,
+						Level:          1,
+						Kind:           gtr.KindExample,
+						File:           "name_test.go",
+						Line:           12,
+						Output:         []string{"    fail_test.go:10: boom"},
+						Stderr:         []string{"warning: stderr"},
+						Properties:     []gtr.Property{{Name: "key", Value: "value"}},
+						Attachments:    []gtr.Attachment{{Name: "screenshot", Path: "shots/fail.png", MIME: "image/png"}},
+						Attempts:       []gtr.TestAttempt{{Result: gtr.Fail, Duration: time.Millisecond, Output: []string{"boom"}}, {Result: gtr.Pass}},
+						Panic:          panicInfo,
+						SkipMessage:    "skipped",
+						FailureMessage: "boom",
+						FailureType:    "testify",
+					},
+				},
+				BuildError: gtr.Error{ID: 2, Name: "package/name", Kind: gtr.ErrorKindInfra, Duration: time.Second, Cause: "[build failed]", Output: []string{"error"}, Panic: panicInfo, Diagnostics: []gtr.Diagnostic{{File: "main.go", Line: 10, Column: 5, Message: "undefined: x"}}},
+				RunError:   gtr.Error{ID: 3, Name: "package/name", Kind: gtr.ErrorKindInfra, Duration: time.Second, Cause: "exit status 2", Output: []string{"error"}, Panic: panicInfo},
+			},
+			{Name: "package/empty"},
+		},
+	}
+}
+
+// Golden compares got to the contents of the golden file at path, and reports
+// a test failure containing the differences if they're not equal. If the
+// -gtrtest.update flag is set, the golden file is written with got instead,
+// creating its directory if necessary.
+func Golden(t testing.TB, path string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("error creating golden file directory: %v", err)
+		}
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("error writing golden file: %v", err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("golden file %s does not exist, run the test with -gtrtest.update to create it", path)
+	} else if err != nil {
+		t.Fatalf("error reading golden file: %v", err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("output differs from golden file %s, diff (-want +got):\n%s\n", path, diff)
+	}
+}
+
+// RunGolden writes the report of each fixture using write, and compares the
+// output to the golden file named after the fixture in directory dir, for
+// example dir/fail.golden, see Golden. Every fixture runs as a subtest of t.
+func RunGolden(t *testing.T, dir string, write WriteFunc) {
+	for _, f := range Fixtures() {
+		f := f
+		t.Run(f.Name, func(t *testing.T) {
+			got, err := Output(f.Report, write)
+			if err != nil {
+				t.Fatalf("error writing report: %v", err)
+			}
+			Golden(t, filepath.Join(dir, f.Name+".golden"), got)
+		})
+	}
+}
+
+// Output returns the output of writing report r using write.
+func Output(r gtr.Report, write WriteFunc) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := write(&buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,122 @@
+package gtrtest
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+func TestFixtures(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, f := range Fixtures() {
+		if seen[f.Name] {
+			t.Errorf("duplicate fixture %q", f.Name)
+		}
+		seen[f.Name] = true
+		for _, p := range f.Report.Validate() {
+			if p.Kind != gtr.ProblemNoResult {
+				t.Errorf("fixture %q has problem: %v", f.Name, p)
+			}
+		}
+	}
+
+	report := Report("pass")
+	report.Packages[0].Name = "modified"
+	if got := Report("pass").Packages[0].Name; got != "package/pass" {
+		t.Errorf("modifying a fixture changed the next result of Report, got package name %q", got)
+	}
+}
+
+func TestFullReportSetsAllFields(t *testing.T) {
+	// Make sure fields added to gtr in the future are also added to FullReport.
+	report := FullReport()
+	checkFieldsSet(t, "Report", reflect.ValueOf(report))
+	pkg := report.Packages[0]
+	checkFieldsSet(t, "Package", reflect.ValueOf(pkg))
+	checkFieldsSet(t, "Test", reflect.ValueOf(pkg.Tests[0]))
+	checkFieldsSet(t, "TestAttempt", reflect.ValueOf(pkg.Tests[0].Attempts[0]))
+	checkFieldsSet(t, "Error", reflect.ValueOf(pkg.BuildError))
+	checkFieldsSet(t, "PanicInfo", reflect.ValueOf(*pkg.BuildError.Panic))
+	checkFieldsSet(t, "Diagnostic", reflect.ValueOf(pkg.BuildError.Diagnostics[0]))
+}
+
+func checkFieldsSet(t *testing.T, name string, v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if name == "Test" && field.Name == "Data" {
+			continue
+		}
+		if v.Field(i).IsZero() {
+			t.Errorf("field %s.%s is not set in FullReport", name, field.Name)
+		}
+	}
+}
+
+// recorder is a testing.TB that records failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gtrtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "out.golden")
+	if err := ioutil.WriteFile(path, []byte("line 1\nline 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recorder{TB: t}
+	Golden(r, path, []byte("line 1\nline 2\n"))
+	if len(r.failures) > 0 {
+		t.Errorf("Golden reported failures for equal output: %v", r.failures)
+	}
+
+	r = &recorder{TB: t}
+	Golden(r, path, []byte("line 1\nchanged\n"))
+	if len(r.failures) != 1 || !strings.Contains(r.failures[0], "changed") {
+		t.Errorf("Golden failures for different output = %v, want one failure containing the diff", r.failures)
+	}
+
+	r = &recorder{TB: t}
+	Golden(r, filepath.Join(dir, "missing.golden"), nil)
+	if len(r.failures) != 1 || !strings.Contains(r.failures[0], "-gtrtest.update") {
+		t.Errorf("Golden failures for missing file = %v, want one failure mentioning -gtrtest.update", r.failures)
+	}
+}
+
+func TestOutput(t *testing.T) {
+	write := func(w io.Writer, r gtr.Report) error {
+		for _, pkg := range r.Packages {
+			fmt.Fprintf(w, "%s %d\n", pkg.Name, len(pkg.Tests))
+		}
+		return nil
+	}
+	got, err := Output(Report("errors"), write)
+	if err != nil {
+		t.Fatalf("Output error: %v", err)
+	}
+	if want := "package/build 0\npackage/run 0\n"; string(got) != want {
+		t.Errorf("Output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,225 @@
+// Package history stores the results of multiple test runs in a file, so that
+// trends such as frequently failing tests or tests that are getting slower can
+// be found without an external service.
+//
+// Each run is identified by an id, such as a commit hash or CI build number.
+// Only the result and duration of each test are stored, not its output, to
+// keep the file small. The store is a JSON file that's rewritten completely
+// when saved, so it's meant for a moderate number of runs; use Prune to limit
+// the number of runs that are kept.
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// Version is the version of the file format written by Store.Save.
+const Version = 1
+
+// Store contains the results of a number of runs, oldest first.
+type Store struct {
+	Version int   `json:"version"`
+	Runs    []Run `json:"runs"`
+}
+
+// Run contains the results of all tests in a single run.
+type Run struct {
+	ID    string       `json:"id"`
+	Time  time.Time    `json:"time"`
+	Tests []TestResult `json:"tests,omitempty"`
+}
+
+// TestResult is the result of a single test in a run.
+type TestResult struct {
+	Package  string        `json:"package"`
+	Test     string        `json:"test,omitempty"` // empty for build and runtime errors
+	Result   gtr.Result    `json:"result"`
+	Duration time.Duration `json:"duration_nanos,omitempty"`
+}
+
+// Ref returns the package and name of the test.
+func (t TestResult) Ref() gtr.TestRef {
+	return gtr.TestRef{Package: t.Package, Test: t.Test}
+}
+
+// NewRun returns the run with the given id and time containing the results of
+// all tests in report r. Packages that failed to build or failed outside of a
+// test are stored as a test with an empty name and a Fail result.
+func NewRun(id string, t time.Time, r gtr.Report) Run {
+	run := Run{ID: id, Time: t}
+	for _, pkg := range r.Packages {
+		if pkg.BuildError.Name != "" || pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			run.Tests = append(run.Tests, TestResult{Package: pkg.Name, Result: gtr.Fail, Duration: pkg.Duration})
+		}
+		for _, test := range pkg.Tests {
+			run.Tests = append(run.Tests, TestResult{
+				Package:  pkg.Name,
+				Test:     test.Name,
+				Result:   test.Result,
+				Duration: test.Duration,
+			})
+		}
+	}
+	return run
+}
+
+// Load reads the store in the given file. An empty store is returned if the
+// file doesn't exist yet.
+func Load(file string) (*Store, error) {
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return &Store{Version: Version}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var s Store
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("history: error reading %s: %w", file, err)
+	}
+	if s.Version != Version {
+		return nil, fmt.Errorf("history: unsupported version %d in %s", s.Version, file)
+	}
+	return &s, nil
+}
+
+// Save writes store s to the given file. The file is replaced atomically, so
+// it's never left partially written.
+func (s *Store) Save(file string) error {
+	s.Version = Version
+	data, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// Add adds run r as the most recent run. An existing run with the same id is
+// removed first, so rerunning the same build replaces its results.
+func (s *Store) Add(r Run) {
+	runs := s.Runs[:0]
+	for _, run := range s.Runs {
+		if run.ID != r.ID {
+			runs = append(runs, run)
+		}
+	}
+	s.Runs = append(runs, r)
+}
+
+// Prune removes the oldest runs so that at most max runs remain. Nothing is
+// removed if max is 0 or less.
+func (s *Store) Prune(max int) {
+	if max > 0 && len(s.Runs) > max {
+		s.Runs = append([]Run{}, s.Runs[len(s.Runs)-max:]...)
+	}
+}
+
+// Last returns the n most recent runs, oldest first. All runs are returned if
+// n is 0 or less.
+func (s *Store) Last(n int) []Run {
+	if n <= 0 || n > len(s.Runs) {
+		return s.Runs
+	}
+	return s.Runs[len(s.Runs)-n:]
+}
+
+// FailureCount is the number of runs in which a test failed.
+type FailureCount struct {
+	gtr.TestRef
+	Failures int // number of runs in which the test failed or was flaky
+	Runs     int // number of runs in which the test ran
+}
+
+// FrequentFailures returns the tests that failed or were flaky in at least
+// min of the last n runs, most failures first. Tests with the same number of
+// failures are ordered by package and test name. A test counts as failed at
+// most once per run, even if it ran more than once.
+func (s *Store) FrequentFailures(n, min int) []FailureCount {
+	counts := make(map[gtr.TestRef]*FailureCount)
+	for _, run := range s.Last(n) {
+		seen := make(map[gtr.TestRef]bool)
+		failed := make(map[gtr.TestRef]bool)
+		for _, t := range run.Tests {
+			seen[t.Ref()] = true
+			if t.Result.Base() == gtr.Fail || t.Result == gtr.Flaky {
+				failed[t.Ref()] = true
+			}
+		}
+		for ref := range seen {
+			c, ok := counts[ref]
+			if !ok {
+				c = &FailureCount{TestRef: ref}
+				counts[ref] = c
+			}
+			c.Runs++
+			if failed[ref] {
+				c.Failures++
+			}
+		}
+	}
+
+	var result []FailureCount
+	for _, c := range counts {
+		if c.Failures > 0 && c.Failures >= min {
+			result = append(result, *c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Failures != result[j].Failures {
+			return result[i].Failures > result[j].Failures
+		}
+		if result[i].Package != result[j].Package {
+			return result[i].Package < result[j].Package
+		}
+		return result[i].Test < result[j].Test
+	})
+	return result
+}
+
+// DurationPoint is the duration of a test in a single run.
+type DurationPoint struct {
+	RunID    string
+	Time     time.Time
+	Duration time.Duration
+}
+
+// DurationTrend returns the duration of the given test in each of the last n
+// runs in which it ran, oldest first. When the test ran more than once in a
+// run, the durations are summed.
+func (s *Store) DurationTrend(ref gtr.TestRef, n int) []DurationPoint {
+	var points []DurationPoint
+	for _, run := range s.Last(n) {
+		found := false
+		var d time.Duration
+		for _, t := range run.Tests {
+			if t.Ref() == ref {
+				found = true
+				d += t.Duration
+			}
+		}
+		if found {
+			points = append(points, DurationPoint{RunID: run.ID, Time: run.Time, Duration: d})
+		}
+	}
+	return points
+}
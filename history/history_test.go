@@ -0,0 +1,139 @@
+package history
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var start = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// testStore returns a store with a run for each of the given results of
+// TestFlaky and TestSlow in package/name.
+func testStore(flaky []gtr.Result) *Store {
+	s := &Store{Version: Version}
+	for i, result := range flaky {
+		s.Add(NewRun(string(rune('a'+i)), start.Add(time.Duration(i)*time.Hour), gtr.Report{Packages: []gtr.Package{
+			{Name: "package/name", Tests: []gtr.Test{
+				{Name: "TestFlaky", Result: result, Duration: time.Second},
+				{Name: "TestSlow", Result: gtr.Pass, Duration: time.Duration(i+1) * time.Second},
+			}},
+		}}))
+	}
+	return s
+}
+
+func TestNewRun(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{
+		{Name: "package/one", Tests: []gtr.Test{{Name: "TestOne", Result: gtr.Pass, Duration: time.Second, Output: []string{"ignored"}}}},
+		{Name: "package/broken", Duration: 2 * time.Second, BuildError: gtr.Error{Name: "package/broken"}},
+	}}
+
+	want := Run{
+		ID:   "abc123",
+		Time: start,
+		Tests: []TestResult{
+			{Package: "package/one", Test: "TestOne", Result: gtr.Pass, Duration: time.Second},
+			{Package: "package/broken", Result: gtr.Fail, Duration: 2 * time.Second},
+		},
+	}
+	if diff := cmp.Diff(want, NewRun("abc123", start, report)); diff != "" {
+		t.Errorf("NewRun result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestAddPrune(t *testing.T) {
+	s := testStore([]gtr.Result{gtr.Pass, gtr.Pass, gtr.Pass})
+	s.Add(Run{ID: "b"})
+
+	var ids []string
+	for _, run := range s.Runs {
+		ids = append(ids, run.ID)
+	}
+	if diff := cmp.Diff([]string{"a", "c", "b"}, ids); diff != "" {
+		t.Errorf("Add did not replace the existing run, diff (-want +got):\n%s\n", diff)
+	}
+
+	s.Prune(2)
+	if len(s.Runs) != 2 || s.Runs[0].ID != "c" {
+		t.Errorf("Prune(2) did not keep the 2 most recent runs: %+v", s.Runs)
+	}
+	s.Prune(0)
+	if len(s.Runs) != 2 {
+		t.Errorf("Prune(0) removed runs, got %d runs, want 2", len(s.Runs))
+	}
+}
+
+func TestFrequentFailures(t *testing.T) {
+	s := testStore([]gtr.Result{gtr.Fail, gtr.Fail, gtr.Pass, gtr.Flaky, gtr.Pass, gtr.Fail})
+
+	want := []FailureCount{{TestRef: gtr.TestRef{Package: "package/name", Test: "TestFlaky"}, Failures: 4, Runs: 6}}
+	if diff := cmp.Diff(want, s.FrequentFailures(10, 4)); diff != "" {
+		t.Errorf("FrequentFailures(10, 4) incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	want = []FailureCount{{TestRef: gtr.TestRef{Package: "package/name", Test: "TestFlaky"}, Failures: 2, Runs: 3}}
+	if diff := cmp.Diff(want, s.FrequentFailures(3, 1)); diff != "" {
+		t.Errorf("FrequentFailures(3, 1) incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	if got := s.FrequentFailures(3, 3); len(got) != 0 {
+		t.Errorf("FrequentFailures(3, 3) = %+v, want none", got)
+	}
+}
+
+func TestDurationTrend(t *testing.T) {
+	s := testStore([]gtr.Result{gtr.Pass, gtr.Pass, gtr.Pass})
+	s.Add(Run{ID: "d", Time: start.Add(3 * time.Hour)})
+
+	want := []DurationPoint{
+		{RunID: "b", Time: start.Add(time.Hour), Duration: 2 * time.Second},
+		{RunID: "c", Time: start.Add(2 * time.Hour), Duration: 3 * time.Second},
+	}
+	got := s.DurationTrend(gtr.TestRef{Package: "package/name", Test: "TestSlow"}, 3)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("DurationTrend incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "history.json")
+
+	empty, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load of missing file returned an error: %v", err)
+	}
+	if len(empty.Runs) != 0 {
+		t.Errorf("Load of missing file returned %d runs, want 0", len(empty.Runs))
+	}
+
+	want := testStore([]gtr.Result{gtr.Pass, gtr.Fail})
+	if err := want.Save(file); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+	got, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Load after Save incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	if err := ioutil.WriteFile(file, []byte(`{"version": 2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(file); err == nil {
+		t.Errorf("Load of unsupported version did not return an error")
+	}
+}
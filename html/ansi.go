@@ -0,0 +1,154 @@
+package html
+
+import (
+	"fmt"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// regexANSI matches ANSI escape sequences, see gtr.StripANSI. Select Graphic
+// Rendition (SGR) sequences, which end in m, capture their parameters.
+var regexANSI = regexp.MustCompile(`\x1b(?:\[([0-?]*)([ -/]*[@-~])|\][^\x07\x1b]*(?:\x07|\x1b\\)|[@-Z\\-_])`)
+
+// palette contains the colors for the 8 standard and 8 bright ANSI colors.
+var palette = [16]string{
+	"#000000", "#cd3131", "#0dbc79", "#e5e510", "#2472c8", "#bc3fbc", "#11a8cd", "#e5e5e5",
+	"#666666", "#f14c4c", "#23d18b", "#f5f543", "#3b8eea", "#d670d6", "#29b8db", "#ffffff",
+}
+
+// style is the graphic rendition state of ANSI formatted text.
+type style struct {
+	bold, italic, underline bool
+	fg, bg                  string // CSS colors, empty for the default color
+}
+
+// css returns the inline CSS for style s, or an empty string for the default
+// style.
+func (s style) css() string {
+	var props []string
+	if s.bold {
+		props = append(props, "font-weight: bold")
+	}
+	if s.italic {
+		props = append(props, "font-style: italic")
+	}
+	if s.underline {
+		props = append(props, "text-decoration: underline")
+	}
+	if s.fg != "" {
+		props = append(props, "color: "+s.fg)
+	}
+	if s.bg != "" {
+		props = append(props, "background-color: "+s.bg)
+	}
+	return strings.Join(props, "; ")
+}
+
+// apply updates style s with the given SGR parameters.
+func (s *style) apply(params string) {
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil {
+			code = 0 // an empty parameter means reset
+		}
+		switch {
+		case code == 0:
+			*s = style{}
+		case code == 1:
+			s.bold = true
+		case code == 3:
+			s.italic = true
+		case code == 4:
+			s.underline = true
+		case code == 22:
+			s.bold = false
+		case code == 23:
+			s.italic = false
+		case code == 24:
+			s.underline = false
+		case code >= 30 && code <= 37:
+			s.fg = palette[code-30]
+		case code >= 90 && code <= 97:
+			s.fg = palette[code-90+8]
+		case code == 39:
+			s.fg = ""
+		case code >= 40 && code <= 47:
+			s.bg = palette[code-40]
+		case code >= 100 && code <= 107:
+			s.bg = palette[code-100+8]
+		case code == 49:
+			s.bg = ""
+		case code == 38 || code == 48:
+			color, n := extendedColor(codes[i+1:])
+			if code == 38 {
+				s.fg = color
+			} else {
+				s.bg = color
+			}
+			i += n
+		}
+	}
+}
+
+// extendedColor parses the parameters of a 256 color (5;n) or 24-bit color
+// (2;r;g;b) SGR sequence. It returns the CSS color and the number of
+// parameters that were used. Colors of the 256 color palette other than the
+// 16 standard colors aren't supported and result in the default color.
+func extendedColor(params []string) (string, int) {
+	if len(params) >= 2 && params[0] == "5" {
+		if n, err := strconv.Atoi(params[1]); err == nil && n >= 0 && n < len(palette) {
+			return palette[n], 2
+		}
+		return "", 2
+	}
+	if len(params) >= 4 && params[0] == "2" {
+		var rgb [3]int
+		for i := range rgb {
+			n, err := strconv.Atoi(params[i+1])
+			if err != nil || n < 0 || n > 255 {
+				return "", 4
+			}
+			rgb[i] = n
+		}
+		return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2]), 4
+	}
+	return "", len(params)
+}
+
+// renderANSI returns the HTML for text s, in which ANSI color and text style
+// sequences are rendered as styled spans. Other escape sequences are removed
+// and the remaining text is escaped.
+func renderANSI(s string) template.HTML {
+	if !strings.ContainsRune(s, '\x1b') {
+		return template.HTML(template.HTMLEscapeString(s))
+	}
+
+	var b strings.Builder
+	var current style
+	open := false
+	last := 0
+	for _, m := range regexANSI.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(template.HTMLEscapeString(s[last:m[0]]))
+		last = m[1]
+		if m[4] < 0 || s[m[4]:m[5]] != "m" {
+			continue // not an SGR sequence
+		}
+		current.apply(s[m[2]:m[3]])
+		if open {
+			b.WriteString("</span>")
+			open = false
+		}
+		if css := current.css(); css != "" {
+			fmt.Fprintf(&b, `<span style="%s">`, css)
+			open = true
+		}
+	}
+	b.WriteString(template.HTMLEscapeString(s[last:]))
+	if open {
+		b.WriteString("</span>")
+	}
+	return template.HTML(b.String())
+}
@@ -0,0 +1,26 @@
+package html
+
+import (
+	"html/template"
+	"testing"
+)
+
+func TestRenderANSI(t *testing.T) {
+	tests := []struct {
+		in   string
+		want template.HTML
+	}{
+		{"<plain> & text", "&lt;plain&gt; &amp; text"},
+		{"\x1b[31mFAIL\x1b[0m done", `<span style="color: #cd3131">FAIL</span> done`},
+		{"\x1b[1;92mok\x1b[m", `<span style="font-weight: bold; color: #23d18b">ok</span>`},
+		{"\x1b[31mred \x1b[44mon blue", `<span style="color: #cd3131">red </span><span style="color: #cd3131; background-color: #2472c8">on blue</span>`},
+		{"\x1b[38;5;4mblue\x1b[39m", `<span style="color: #2472c8">blue</span>`},
+		{"\x1b[38;2;255;128;0morange", `<span style="color: #ff8000">orange</span>`},
+		{"\x1b[2Kcleared", "cleared"},
+	}
+	for _, test := range tests {
+		if got := renderANSI(test.in); got != test.want {
+			t.Errorf("renderANSI(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Package html writes reports as a standalone HTML page.
+//
+// The page contains a summary of the report followed by a collapsible section
+// for each package, which lists its tests with their result, duration and
+// output. Attachments are linked by their path. Packages and tests that failed
+// are expanded by default. ANSI color codes in the output are rendered as
+// colors. The page doesn't refer to any external resources, so it can be
+// stored as a build artifact and opened in any browser.
+package html
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/timefmt"
+)
+
+// Write writes the HTML representation of report r to writer w.
+func Write(w io.Writer, r gtr.Report) error {
+	return page.Execute(w, newReport(r))
+}
+
+type report struct {
+	Packages []pkg
+	Tests    int
+	Failures int
+	Skipped  int
+	Errors   int
+}
+
+type pkg struct {
+	Name        string
+	Class       string
+	Duration    string
+	Tests       []test
+	Output      template.HTML
+	Attachments []gtr.Attachment
+	Errors      []pkgError
+	Failures    int
+}
+
+type pkgError struct {
+	Title  string
+	Output template.HTML
+}
+
+type test struct {
+	Name        string
+	Indent      template.CSS
+	Class       string
+	Result      string
+	Duration    string
+	Output      template.HTML
+	Attachments []gtr.Attachment
+}
+
+func newReport(r gtr.Report) report {
+	var rep report
+	for _, p := range r.Packages {
+		hp := pkg{
+			Name:        p.Name,
+			Class:       "pass",
+			Duration:    timefmt.Readable(p.Duration),
+			Output:      formatOutput(p.Output),
+			Attachments: p.Attachments,
+		}
+		for _, t := range p.Tests {
+			ht := test{
+				Name:        t.Name,
+				Indent:      template.CSS(fmt.Sprintf("%.1fem", 1.5*float64(t.Level+1))),
+				Class:       resultClass(t.Result),
+				Result:      t.Result.String(),
+				Duration:    timefmt.Readable(t.Duration),
+				Output:      formatOutput(t.Output),
+				Attachments: t.Attachments,
+			}
+			rep.Tests++
+			switch t.Result.Base() {
+			case gtr.Fail, gtr.Unknown:
+				hp.Failures++
+				rep.Failures++
+			case gtr.Skip:
+				rep.Skipped++
+			}
+			hp.Tests = append(hp.Tests, ht)
+		}
+		if p.BuildError.Name != "" {
+			hp.Errors = append(hp.Errors, pkgError{"Build error", formatOutput(p.BuildError.Output)})
+		}
+		if p.RunError.Name != "" || p.RunError.Kind != "" {
+			hp.Errors = append(hp.Errors, pkgError{"Runtime error", formatOutput(p.RunError.Output)})
+		}
+		rep.Errors += len(hp.Errors)
+		if hp.Failures > 0 || len(hp.Errors) > 0 {
+			hp.Class = "fail"
+		}
+		rep.Packages = append(rep.Packages, hp)
+	}
+	return rep
+}
+
+func resultClass(r gtr.Result) string {
+	switch r.Base() {
+	case gtr.Pass:
+		return "pass"
+	case gtr.Skip:
+		return "skip"
+	case gtr.Flaky:
+		return "flaky"
+	default:
+		return "fail"
+	}
+}
+
+// formatOutput returns the HTML for the given output lines, in which ANSI colors
+// are rendered, see renderANSI.
+func formatOutput(lines []string) template.HTML {
+	return renderANSI(strings.Join(lines, "\n"))
+}
+
+var page = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Test report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+summary { cursor: pointer; padding: 0.2em 0; }
+pre { background: #f6f8fa; padding: 0.5em; overflow-x: auto; margin: 0.2em 0 0.5em 1.5em; }
+.pass { color: #1a7f37; }
+.fail { color: #cf222e; }
+.skip { color: #6e7781; }
+.flaky { color: #9a6700; }
+.duration { color: #6e7781; font-size: smaller; }
+.test { margin-left: 1.5em; }
+.attachments { margin: 0.2em 0 0.5em 0; }
+</style>
+</head>
+<body>
+<h1>Test report</h1>
+<p>{{.Tests}} tests, {{.Failures}} failures, {{.Skipped}} skipped, {{.Errors}} errors</p>
+{{- range .Packages}}
+<details class="package"{{if eq .Class "fail"}} open{{end}}>
+<summary><span class="{{.Class}}">{{.Name}}</span> <span class="duration">{{.Duration}}</span></summary>
+{{- range .Errors}}
+<div class="test"><span class="fail">{{.Title}}</span>
+{{- if .Output}}
+<pre>{{.Output}}</pre>
+{{- end}}
+</div>
+{{- end}}
+{{- range .Tests}}
+<details class="test" style="margin-left: {{.Indent}}"{{if eq .Class "fail"}} open{{end}}>
+<summary><span class="{{.Class}}">{{.Result}}</span> {{.Name}} <span class="duration">{{.Duration}}</span></summary>
+{{- if .Output}}
+<pre>{{.Output}}</pre>
+{{- end}}
+{{- template "attachments" .Attachments}}
+</details>
+{{- end}}
+{{- if .Output}}
+<pre>{{.Output}}</pre>
+{{- end}}
+{{- template "attachments" .Attachments}}
+</details>
+{{- end}}
+</body>
+</html>
+{{- define "attachments"}}
+{{- if .}}
+<ul class="attachments">
+{{- range .}}
+<li><a href="{{.Path}}"{{if .MIME}} type="{{.MIME}}"{{end}}>{{if .Name}}{{.Name}}{{else}}{{.Path}}{{end}}</a></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+`))
@@ -0,0 +1,97 @@
+package html
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+func TestWrite(t *testing.T) {
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{
+				Name:     "package/one",
+				Duration: 1500 * time.Millisecond,
+				Tests: []gtr.Test{
+					{Name: "TestPass", Result: gtr.Pass, Duration: 10 * time.Millisecond},
+					{Name: "TestFail", Result: gtr.Fail, Output: []string{"fail_test.go:10: got <nil>"}},
+					{Name: "TestFail/sub", Result: gtr.Skip, Level: 1},
+				},
+			},
+			{
+				Name:       "package/two",
+				BuildError: gtr.Error{Name: "package/two", Output: []string{"undefined: x"}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		"<p>3 tests, 1 failures, 1 skipped, 1 errors</p>",
+		`<details class="package" open>
+<summary><span class="fail">package/one</span> <span class="duration">1.500s</span></summary>`,
+		`<details class="test" style="margin-left: 1.5em">
+<summary><span class="pass">PASS</span> TestPass <span class="duration">0.010s</span></summary>
+</details>`,
+		`<details class="test" style="margin-left: 1.5em" open>
+<summary><span class="fail">FAIL</span> TestFail <span class="duration">0.000s</span></summary>
+<pre>fail_test.go:10: got &lt;nil&gt;</pre>`,
+		`<details class="test" style="margin-left: 3.0em">
+<summary><span class="skip">SKIP</span> TestFail/sub`,
+		`<div class="test"><span class="fail">Build error</span>
+<pre>undefined: x</pre>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Write output does not contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestWriteAttachments(t *testing.T) {
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{
+				Name:        "package/one",
+				Attachments: []gtr.Attachment{{Path: "cpu.pprof"}},
+				Tests: []gtr.Test{
+					{
+						Name:        "TestScreenshot",
+						Result:      gtr.Fail,
+						Attachments: []gtr.Attachment{{Name: "screenshot", Path: "shots/fail.png", MIME: "image/png"}, {Path: "javascript:alert(1)"}},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		`<ul class="attachments">
+<li><a href="shots/fail.png" type="image/png">screenshot</a></li>
+<li><a href="#ZgotmplZ">javascript:alert(1)</a></li>
+</ul>
+</details>`,
+		`<ul class="attachments">
+<li><a href="cpu.pprof">cpu.pprof</a></li>
+</ul>
+</details>
+</body>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Write output does not contain %q, got:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Package escape implements the escaping shared by the report writers, so
+// that package and test names containing spaces, quotes, unicode or characters
+// that have a special meaning in an output format are written correctly.
+//
+// Names parsed from go test output never contain line breaks or other control
+// characters, since the testing package replaces them, but names read from
+// JUnit XML reports and other inputs may. Line escapes these the same way the
+// testing package does, and the format specific functions apply it to the
+// names and messages that must be written on a single line.
+package escape
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Line returns s with every rune that isn't printable, such as line breaks
+// and other control characters, replaced by its Go escape sequence, e.g. \n or
+// \x01, so that it can be written on a single line. Spaces and tabs are kept,
+// and invalid UTF-8 is replaced by U+FFFD.
+func Line(s string) string {
+	if isLine(s) {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if r == ' ' || r == '\t' || strconv.IsPrint(r) {
+			b.WriteRune(r)
+			continue
+		}
+		q := strconv.QuoteRune(r)
+		b.WriteString(q[1 : len(q)-1])
+	}
+	return b.String()
+}
+
+// isLine reports whether s only contains printable runes, spaces and tabs.
+func isLine(s string) bool {
+	for i, r := range s {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				return false
+			}
+		}
+		if r != ' ' && r != '\t' && !strconv.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// XML returns s with every character that isn't allowed in XML 1.0 documents
+// replaced by U+FFFD.
+func XML(s string) string {
+	return strings.Map(func(r rune) rune {
+		if isInCharacterRange(r) {
+			return r
+		}
+		return '\uFFFD'
+	}, s)
+}
+
+// isInCharacterRange reports whether r is in the XML Character Range, per the
+// Char production of https://www.xml.com/axml/testaxml.htm, Section 2.2
+// Characters.
+// From: encoding/xml/xml.go
+func isInCharacterRange(r rune) bool {
+	return r == 0x09 ||
+		r == 0x0A ||
+		r == 0x0D ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
+
+// Regexp returns a regular expression that matches the literal text s, in
+// which the runes that Line would escape are written as \x{...} escapes so
+// that the expression fits on a single line.
+func Regexp(s string) string {
+	quoted := regexp.QuoteMeta(s)
+	if isLine(quoted) {
+		return quoted
+	}
+	var b strings.Builder
+	for _, r := range quoted {
+		if r == ' ' || r == '\t' || strconv.IsPrint(r) && r != utf8.RuneError {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteString(`\x{` + strconv.FormatInt(int64(r), 16) + `}`)
+	}
+	return b.String()
+}
+
+var tapEscaper = strings.NewReplacer(`\`, `\\`, "#", `\#`)
+
+// TAP escapes a test name for use in a TAP test point description.
+func TAP(name string) string {
+	return tapEscaper.Replace(Line(name))
+}
+
+var teamCityEscaper = strings.NewReplacer(
+	"|", "||",
+	"'", "|'",
+	"\n", "|n",
+	"\r", "|r",
+	"[", "|[",
+	"]", "|]",
+	"\u0085", "|x",
+	"\u2028", "|l",
+	"\u2029", "|p",
+)
+
+// TeamCity escapes a TeamCity service message attribute value.
+func TeamCity(s string) string {
+	return teamCityEscaper.Replace(s)
+}
+
+var (
+	gitHubDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	gitHubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// GitHubData escapes the message of a GitHub Actions workflow command.
+func GitHubData(s string) string {
+	return gitHubDataEscaper.Replace(s)
+}
+
+// GitHubProperty escapes a property value of a GitHub Actions workflow
+// command.
+func GitHubProperty(s string) string {
+	return gitHubPropertyEscaper.Replace(s)
+}
+
+var (
+	htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	cellEscaper = strings.NewReplacer("|", `\|`, "<", "&lt;", ">", "&gt;", "`", "\\`", "*", `\*`, "_", `\_`)
+)
+
+// HTML escapes the characters of s that start HTML markup, and the runes that
+// Line would escape, for use in inline HTML in Markdown.
+func HTML(s string) string {
+	return htmlEscaper.Replace(Line(s))
+}
+
+// MarkdownCell escapes s for use in a cell of a Markdown table.
+func MarkdownCell(s string) string {
+	return cellEscaper.Replace(Line(s))
+}
@@ -0,0 +1,106 @@
+//go:build go1.18
+// +build go1.18
+
+package escape
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func addSeeds(f *testing.F) {
+	for _, test := range escapeTests {
+		f.Add(test.in)
+	}
+}
+
+func FuzzLine(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, in string) {
+		got := Line(in)
+		if strings.ContainsAny(got, "\r\n") || !utf8.ValidString(got) {
+			t.Fatalf("Line(%q) = %q is not a single line of valid UTF-8", in, got)
+		}
+		if Line(got) != got {
+			t.Fatalf("Line(%q) = %q is escaped again by Line", in, got)
+		}
+	})
+}
+
+func FuzzXML(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, in string) {
+		type element struct {
+			Attr  string `xml:"attr,attr"`
+			CDATA string `xml:",cdata"`
+		}
+		var buf bytes.Buffer
+		if err := xml.NewEncoder(&buf).Encode(element{Attr: XML(in), CDATA: XML(in)}); err != nil {
+			t.Fatalf("error encoding XML(%q): %v", in, err)
+		}
+		d := xml.NewDecoder(&buf)
+		for {
+			if _, err := d.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("XML(%q) produced invalid XML %q: %v", in, buf.String(), err)
+			}
+		}
+	})
+}
+
+func FuzzRegexp(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, in string) {
+		if !utf8.ValidString(in) {
+			t.Skip()
+		}
+		got := Regexp(in)
+		if strings.ContainsAny(got, "\r\n") {
+			t.Fatalf("Regexp(%q) = %q is not a single line", in, got)
+		}
+		re, err := regexp.Compile("^" + got + "$")
+		if err != nil {
+			t.Fatalf("Regexp(%q) = %q is not a valid regular expression: %v", in, got, err)
+		}
+		if !re.MatchString(in) {
+			t.Fatalf("Regexp(%q) = %q does not match its input", in, got)
+		}
+	})
+}
+
+func FuzzTeamCity(f *testing.F) {
+	addSeeds(f)
+	unescaper := strings.NewReplacer("||", "|", "|'", "'", "|n", "\n", "|r", "\r", "|[", "[", "|]", "]", "|x", "\u0085", "|l", "\u2028", "|p", "\u2029")
+	f.Fuzz(func(t *testing.T, in string) {
+		got := TeamCity(in)
+		if strings.ContainsAny(got, "\r\n") {
+			t.Fatalf("TeamCity(%q) = %q is not a single line", in, got)
+		}
+		if unescaped := unescaper.Replace(got); unescaped != in {
+			t.Fatalf("TeamCity(%q) = %q unescapes to %q", in, got, unescaped)
+		}
+	})
+}
+
+func FuzzSingleLine(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, in string) {
+		for _, fn := range []struct {
+			name string
+			fn   func(string) string
+		}{{"TAP", TAP}, {"GitHubData", GitHubData}, {"GitHubProperty", GitHubProperty}, {"HTML", HTML}, {"MarkdownCell", MarkdownCell}} {
+			if got := fn.fn(in); strings.ContainsAny(got, "\r\n") {
+				t.Fatalf("%s(%q) = %q is not a single line", fn.name, in, got)
+			}
+		}
+		if got := MarkdownCell(in); strings.Count(got, "|") != strings.Count(got, `\|`) {
+			t.Fatalf("MarkdownCell(%q) = %q contains an unescaped |", in, got)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package escape
+
+import (
+	"regexp"
+	"testing"
+)
+
+var escapeTests = []struct {
+	name string
+	fn   func(string) string
+	in   string
+	want string
+}{
+	{"Line", Line, "TestA/with space \"quoted\" ünïcödé 日本", "TestA/with space \"quoted\" ünïcödé 日本"},
+	{"Line", Line, "TestA/a\nb\r\x01\x7f\u2028\tc", `TestA/a\nb\r\x01\x7f\u2028` + "\tc"},
+	{"Line", Line, "invalid \xff utf-8", "invalid \uFFFD utf-8"},
+	{"XML", XML, "a\x00b\x1b[31mc\uFFFE<&>\n", "a\uFFFDb\uFFFD[31mc\uFFFD<&>\n"},
+	{"Regexp", Regexp, "TestA/a b.c(d)", `TestA/a b\.c\(d\)`},
+	{"Regexp", Regexp, "TestA/a\nb\u2028", `TestA/a\x{a}b\x{2028}`},
+	{"TAP", TAP, `TestA/a#b\c` + "\nd", `TestA/a\#b\\c\\nd`},
+	{"TeamCity", TeamCity, "a|b'c\nd\re[f]g\u0085h\u2028i\u2029", "a||b|'c|nd|re|[f|]g|xh|li|p"},
+	{"GitHubData", GitHubData, "100%\r\nok: a,b", "100%25%0D%0Aok: a,b"},
+	{"GitHubProperty", GitHubProperty, "100%\r\nok: a,b", "100%25%0D%0Aok%3A a%2Cb"},
+	{"HTML", HTML, "TestA/<b>&amp;\n", `TestA/&lt;b&gt;&amp;amp;\n`},
+	{"MarkdownCell", MarkdownCell, "TestA/a|b_<c>", `TestA/a\|b\_&lt;c&gt;`},
+	{"MarkdownCell", MarkdownCell, "TestA/`*a*`\nb", "TestA/\\`\\*a\\*\\`\\nb"},
+}
+
+func TestEscape(t *testing.T) {
+	for _, test := range escapeTests {
+		if got := test.fn(test.in); got != test.want {
+			t.Errorf("%s(%q) = %q, want %q", test.name, test.in, got, test.want)
+		}
+	}
+}
+
+func TestRegexpMatchesLiteral(t *testing.T) {
+	for _, s := range []string{"TestA/a b", "TestA/[x]+(y)?", "TestA/a\nb", "TestA/\x01\u2028日本"} {
+		re, err := regexp.Compile("^" + Regexp(s) + "$")
+		if err != nil {
+			t.Errorf("Regexp(%q) = %q is not a valid regular expression: %v", s, Regexp(s), err)
+			continue
+		}
+		if !re.MatchString(s) {
+			t.Errorf("Regexp(%q) = %q does not match %q", s, Regexp(s), s)
+		}
+	}
+}
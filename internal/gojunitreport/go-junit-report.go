@@ -1,53 +1,563 @@
 package gojunitreport
 
 import (
+	"bytes"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/jstemmer/go-junit-report/v2/azuredevops"
+	"github.com/jstemmer/go-junit-report/v2/benchfmt"
+	"github.com/jstemmer/go-junit-report/v2/buildkite"
+	"github.com/jstemmer/go-junit-report/v2/checkpoint"
+	"github.com/jstemmer/go-junit-report/v2/codeowners"
+	"github.com/jstemmer/go-junit-report/v2/coverage"
+	"github.com/jstemmer/go-junit-report/v2/ctrf"
+	"github.com/jstemmer/go-junit-report/v2/evergreen"
+	"github.com/jstemmer/go-junit-report/v2/github"
 	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/gtrjson"
+	"github.com/jstemmer/go-junit-report/v2/html"
 	"github.com/jstemmer/go-junit-report/v2/junit"
+	"github.com/jstemmer/go-junit-report/v2/markdown"
+	"github.com/jstemmer/go-junit-report/v2/nunit"
+	"github.com/jstemmer/go-junit-report/v2/parser"
+	"github.com/jstemmer/go-junit-report/v2/parser/bazel"
+	"github.com/jstemmer/go-junit-report/v2/parser/ginkgo"
 	"github.com/jstemmer/go-junit-report/v2/parser/gotest"
+	_ "github.com/jstemmer/go-junit-report/v2/parser/junitxml" // registers the junit parser
+	"github.com/jstemmer/go-junit-report/v2/parser/lint"
+	"github.com/jstemmer/go-junit-report/v2/pretty"
+	"github.com/jstemmer/go-junit-report/v2/progress"
+	"github.com/jstemmer/go-junit-report/v2/provenance"
+	"github.com/jstemmer/go-junit-report/v2/rerun"
+	"github.com/jstemmer/go-junit-report/v2/sonarqube"
+	"github.com/jstemmer/go-junit-report/v2/srcloc"
+	"github.com/jstemmer/go-junit-report/v2/tabular"
+	"github.com/jstemmer/go-junit-report/v2/tap"
+	"github.com/jstemmer/go-junit-report/v2/teamcity"
+	"github.com/jstemmer/go-junit-report/v2/tmpl"
+	"github.com/jstemmer/go-junit-report/v2/xunit"
 )
 
-type parser interface {
-	Parse(r io.Reader) (gtr.Report, error)
+type eventParser interface {
+	parser.Parser
 	Events() []gotest.Event
 }
 
+// registeredParser is a parser from the parser registry, which doesn't record
+// any events.
+type registeredParser struct {
+	parser.Parser
+}
+
+func (registeredParser) Events() []gotest.Event { return nil }
+
+// formats maps the supported output formats to the function that writes a
+// report in that format.
+var formats = map[string]func(c Config, w io.Writer, report gtr.Report) error{
+	"junit":     Config.writeJunitXML,
+	"tap":       Config.writeTAP,
+	"json":      Config.writeJSON,
+	"html":      Config.writeHTML,
+	"github":    Config.writeGitHub,
+	"sonarqube": Config.writeSonarQube,
+	"teamcity":  Config.writeTeamCity,
+	"rerun":     Config.writeRerun,
+	"markdown":  Config.writeMarkdown,
+	"ctrf":      Config.writeCTRF,
+	"xunit":     Config.writeXUnit,
+	"nunit":     Config.writeNUnit,
+	"benchfmt":  Config.writeBenchfmt,
+	"csv":       Config.writeCSV,
+	"tsv":       Config.writeTSV,
+	"template":  Config.writeTemplate,
+	"buildkite": Config.writeBuildkite,
+	"evergreen": Config.writeEvergreen,
+	"azure":     Config.writeAzureDevOps,
+	"gitlab":    Config.writeGitLab,
+	"pretty":    Config.writePretty,
+}
+
+// contentTypes maps the output formats to the media type of the reports
+// written in that format.
+var contentTypes = map[string]string{
+	"junit":     "application/xml",
+	"tap":       "text/plain; charset=utf-8",
+	"json":      "application/json",
+	"html":      "text/html; charset=utf-8",
+	"github":    "text/plain; charset=utf-8",
+	"sonarqube": "application/xml",
+	"teamcity":  "text/plain; charset=utf-8",
+	"rerun":     "text/plain; charset=utf-8",
+	"markdown":  "text/markdown; charset=utf-8",
+	"ctrf":      "application/json",
+	"xunit":     "application/xml",
+	"nunit":     "application/xml",
+	"benchfmt":  "text/plain; charset=utf-8",
+	"csv":       "text/csv; charset=utf-8",
+	"tsv":       "text/tab-separated-values; charset=utf-8",
+	"template":  "text/plain; charset=utf-8",
+	"buildkite": "application/json",
+	"evergreen": "application/json",
+	"azure":     "application/json",
+	"gitlab":    "application/xml",
+	"pretty":    "text/plain; charset=utf-8",
+}
+
+// ContentType returns the media type of reports written in the given output
+// format, or application/octet-stream for unknown formats.
+func ContentType(format string) string {
+	if format == "" {
+		format = "junit"
+	}
+	if ct, ok := contentTypes[format]; ok {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
 // Config contains the go-junit-report command configuration.
 type Config struct {
 	Parser        string
 	Hostname      string
 	PackageName   string
 	SkipXMLHeader bool
+	XMLStylesheet string
+	Dialect       junit.Dialect
 	SubtestMode   gotest.SubtestMode
 	Properties    map[string]string
 	TimestampFunc func() time.Time
 
+	// CaptureEnvironment adds properties describing the environment, such as
+	// the target platform and CI build URL, to every package, see
+	// gtr.EnvironmentProperties. The Hostname is added as the host.name
+	// property and each of the EnvironmentVariables that is set as an env.NAME
+	// property. Properties take precedence over these.
+	CaptureEnvironment   bool
+	EnvironmentVariables []string
+
+	// ShardIndex is the zero-based index of the shard that produced the
+	// input, when the tests were split into ShardCount shards. If ShardCount
+	// is greater than zero, it's set as the shard of the report and of every
+	// package whose shard is unknown, see gtr.Report.SetShard. The Hostname
+	// is set as the hostname of the report and its packages in the same way.
+	ShardIndex int
+	ShardCount int
+
+	// RunMeta, if set, describes how the go test process that produced the
+	// input exited. When the process was terminated by a signal, the report
+	// records it as a runtime error, see gtr.MarkKilled.
+	RunMeta *gtr.RunMeta
+
+	// Format is the output format of the report: junit (default), tap, json,
+	// html, github (GitHub Actions workflow commands), sonarqube (SonarQube
+	// generic test execution XML), teamcity (TeamCity service messages),
+	// rerun (go test -run patterns of the failed tests, see rerun.Write),
+	// markdown (a summary for pull request comments, see markdown.Write),
+	// ctrf (Common Test Report Format JSON), xunit (xUnit.net v2 XML),
+	// nunit (NUnit 3 XML), pretty (a colored summary for terminals, see
+	// pretty.Write) or template (a custom Go template, see Template).
+	// The XML options only apply to the junit format. TeamCity service
+	// messages are written while the input is parsed, so options that change
+	// the report after parsing don't apply to them.
+	Format string
+
+	// StripModulePrefix, PackageSeparator, PackageNameFormat and
+	// TestNamePrefix change the names of the testsuites and testcases in the
+	// junit format, see junit.WithoutModulePrefix, junit.WithPackageSeparator,
+	// junit.WithPackageNameFormat and junit.WithTestNamePrefix.
+	StripModulePrefix string
+	PackageSeparator  string
+	PackageNameFormat string
+	TestNamePrefix    string
+
+	// TimeFormat is the format of the durations in the time attributes of the
+	// junit format, see junit.WithTimeFormat.
+	TimeFormat junit.TimeFormat
+
+	// DurationPrecision rounds all durations in the report to a multiple of
+	// it before the report is written, see gtr.RoundDurations. A
+	// DurationPrecision of 0 means durations aren't rounded.
+	DurationPrecision time.Duration
+
+	// BenchfmtConfig writes the properties of each package as configuration
+	// lines in the benchfmt format, see benchfmt.WithConfig.
+	BenchfmtConfig bool
+
+	// Columns are the columns written by the csv and tsv formats, see
+	// tabular.Options.
+	Columns []string
+
+	// SonarQubePaths maps package and test names to source paths for the
+	// sonarqube format, see sonarqube.Mapping.
+	SonarQubePaths sonarqube.Mapping
+
+	// Template is the template used to write the report in the template
+	// format, see tmpl.Write.
+	Template tmpl.Template
+
+	// Color enables ANSI colors in the pretty format, see pretty.Options.
+	Color bool
+
+	// Provenance, if set, adds properties describing the tool that generated
+	// the report, the digest of its input and the time it was generated to
+	// every package, see provenance.Annotate. An empty InputDigest is set to
+	// the digest of the input, or of the concatenated InputFiles, and an empty
+	// Generated time is set using TimestampFunc.
+	Provenance *provenance.Info
+
+	// Failfast indicates the tests were run using `go test -failfast`. Since
+	// such runs stop after the first failure, the report is inherently partial:
+	// it's marked as aborted, every package is marked with a failfast property
+	// and the ExpectedTests that never ran are added as skipped tests, see
+	// gtr.MarkFailFast. When it's not set, aborted runs are detected using the
+	// ExpectedTests, see gtr.DetectFailFast.
+	Failfast bool
+
+	// GroupAttempts combines tests that ran more than once in a package into
+	// a single test, which is marked as flaky when it both failed and passed.
+	// See gtr.GroupAttempts.
+	GroupAttempts bool
+
+	// Duplicates is the strategy used to combine tests that appear more than
+	// once in a package, for example when using go test -count or when
+	// merging InputFiles, see gtr.Dedup and gtr.MergeOptions. When it's
+	// empty, duplicate tests in the same input are kept and duplicate tests
+	// in different InputFiles are combined.
+	Duplicates gtr.DedupStrategy
+
+	// NoTestFiles determines how packages without test files are included in
+	// the report, see gtr.HandleNoTestFiles. They're included as packages
+	// without tests if it's empty.
+	NoTestFiles gtr.NoTestFilesMode
+
+	// Cached determines how the durations of packages whose results were
+	// cached by go test are reported, see gtr.HandleCached. They're kept if
+	// it's empty.
+	Cached gtr.CachedMode
+
+	// SubtestDurations determines whether the durations of tests with
+	// subtests include the durations of their subtests, see
+	// gtr.AggregateSubtestDurations. They're included, as reported by go
+	// test, if it's empty.
+	SubtestDurations gtr.SubtestDurationMode
+
+	// Overrides maps test names to the result they should be given in the
+	// report. Overrides are applied after the input has been parsed, so
+	// overriding a failing test to pass will also affect the result of
+	// gtr.Report.IsSuccessful.
+	Overrides map[string]gtr.Result
+
+	// Quarantine lists the quarantined tests, whose failures are reported as
+	// skipped tests so they don't fail the report, see gtr.Quarantine.
+	// Overrides take precedence over the quarantine.
+	Quarantine []gtr.QuarantineRule
+
+	// FailureBaseline is a report of a previous run, e.g. of the main branch.
+	// When set, tests that also failed in it are reported as skipped known
+	// failures, so that only new failures fail the report, see
+	// gtr.MarkKnownFailures.
+	FailureBaseline *gtr.Report
+
+	// CodeOwners are the rules used to add owner properties to packages and
+	// tests based on their source files, which are found using go list. The
+	// patterns of the rules are relative to CodeOwnersRoot, see
+	// codeowners.Annotate.
+	CodeOwners     codeowners.Ruleset
+	CodeOwnersRoot string
+
+	// LocateTests sets the File and Line of each test to the declaration of
+	// its test function, which is found by parsing the source files of its
+	// package found using go list. File names are relative to the current
+	// directory, see srcloc.Annotate.
+	LocateTests bool
+
+	// Rules classify the tests they match by changing their result or adding
+	// owner and label properties, see gtr.ApplyRules. Rules are applied after
+	// the quarantine, and overrides take precedence over rules.
+	Rules []gtr.Rule
+
+	// SlowThreshold is the duration budget of a single test. Tests that took
+	// longer are marked with a slow property, and also marked as failed when
+	// FailSlowTests is set, see gtr.MarkSlowTests. A SlowThreshold of 0 means
+	// there is no budget.
+	SlowThreshold time.Duration
+	FailSlowTests bool
+
+	// Sort is the order in which packages and tests appear in the report,
+	// see SortDeclaration, SortName and SortFailuresFirst.
+	Sort string
+
+	// Modules are the paths of the modules the packages in the report belong
+	// to, for example the modules of a go.work workspace. When set, packages
+	// are grouped by module after they're sorted, and marked with a go.module
+	// property, see gtr.GroupByModule.
+	Modules []string
+
+	// TestOrder lists test names in the order they should appear in each
+	// package, see gtr.ReorderTests. It's applied after Sort.
+	TestOrder []string
+
+	// EmitOutputSize adds an output-bytes property to each package and test
+	// containing the size of its output.
+	EmitOutputSize bool
+
+	// EmitOverhead adds an overhead-seconds property to each package with a
+	// known duration, containing the time it spent outside of its tests,
+	// such as in TestMain, see gtr.Package.Overhead.
+	EmitOverhead bool
+
+	// DropPassedOutput discards the output of tests that passed while the
+	// input is parsed, which reduces the memory needed to convert runs with a
+	// large number of tests, see gotest.DropPassedOutput.
+	DropPassedOutput bool
+
+	// OutputLimits limits the size of the output of each test and package in
+	// the report, see gtr.TruncateOutput. Output sizes added by EmitOutputSize
+	// are those before truncation.
+	OutputLimits gtr.OutputLimits
+
+	// SanitizeOutput removes ANSI escape sequences, such as color codes, and
+	// control characters that are invalid in XML from all output before the
+	// report is written, see gtr.SanitizeOutput. When PreserveHTMLColors is
+	// set, ANSI escape sequences are kept for the html format, which renders
+	// them as colors.
+	SanitizeOutput     bool
+	PreserveHTMLColors bool
+
+	// RedactPatterns are matched against all output and messages, whose
+	// matching text is replaced by gtr.Redacted before the output is truncated
+	// and the report is written, see gtr.RedactOutput.
+	RedactPatterns []*regexp.Regexp
+
+	// LogOutput formats or filters JSON log lines, such as those written by
+	// log/slog or zap, in all output before it's truncated, see
+	// gtr.FormatLogs.
+	LogOutput gtr.LogOptions
+
+	// MaxSubtestDepth is the maximum nesting level of subtests. The level of
+	// subtests nested deeper than this is capped at MaxSubtestDepth and they
+	// are marked with a subtest-depth property containing their actual depth.
+	// A MaxSubtestDepth of 0 means there is no limit.
+	MaxSubtestDepth int
+
+	// WallDuration is the total wall clock duration of the test run. When
+	// set, it's used as the time of the testsuites element instead of the
+	// sum of all testsuite times, which overstates the elapsed time when
+	// packages or shards ran in parallel. The sum is then added to each
+	// testsuite as the summed.duration property.
+	WallDuration time.Duration
+
+	// EmitIDs replaces the default testsuite ids with ids that are stable
+	// across runs. The testsuites id is the content hash of the report, see
+	// gtr.Report.ContentHash, and each testsuite id is derived from its
+	// package name, see junit.SuiteID.
+	EmitIDs bool
+
+	// InfraErrorPatterns are used to recognize infrastructure errors in
+	// addition to the parser's built-in patterns, see
+	// gotest.InfraErrorPatterns.
+	InfraErrorPatterns []*regexp.Regexp
+
+	// BenchmarkBaseline contains the output of a previous run, in the same
+	// format as the input. When set, benchmarks in the report are compared
+	// to the benchmarks in the baseline and benchmarks that regressed by more
+	// than BenchmarkThreshold are marked as failed, see
+	// gotest.CompareBenchmarks.
+	BenchmarkBaseline  io.Reader
+	BenchmarkThreshold float64
+
+	// CoverageProfile is the coverage profile created by `go test
+	// -coverprofile`. When set, the coverage of each package in the report is
+	// calculated from the profile instead of the coverage line in the test
+	// output. CoveragePerFile additionally adds the coverage of each file as a
+	// package property, see coverage.AddToReport.
+	CoverageProfile *coverage.Profile
+	CoveragePerFile bool
+
+	// CoveragePolicy sets the minimum coverage of each package. Packages
+	// whose coverage is below their minimum are given a failed test, see
+	// gtr.EnforceCoverage.
+	CoveragePolicy gtr.CoveragePolicy
+
+	// ExpectedTests lists the tests that are expected to appear in the report.
+	// Expected tests that don't appear in it are added as failed or skipped
+	// tests according to MissingTests, see gtr.AddMissingTests.
+	ExpectedTests []gtr.TestRef
+	MissingTests  gtr.MissingTestMode
+
+	// DurationBudgets sets the maximum duration of packages. Packages that
+	// took longer than their budget are given a failed test, see
+	// gtr.EnforceDurationBudgets.
+	DurationBudgets []gtr.DurationBudget
+
+	// Lint contains the output of go vet -json or staticcheck. The
+	// diagnostics in each of them are added to the report as failing tests of
+	// the lint package, see lint.Parser.
+	Lint []io.Reader
+
+	// Bazel contains test.xml files written by Bazel for go_test targets.
+	// Their results are merged with the parsed report, using the Duplicates
+	// strategy for tests that appear in both, see bazel.Parser. The Bazel
+	// package of each target is prefixed with BazelImportPrefix to get the
+	// name of its package.
+	Bazel             []io.Reader
+	BazelImportPrefix string
+
+	// Progress is where a live progress line with the number of passed,
+	// failed and skipped tests is written while the input is parsed, see
+	// progress.Writer. No progress is shown if Progress is nil, or when using
+	// a parser from the parser registry.
+	Progress io.Writer
+
+	// Checkpoint is where every package is written as soon as it has been
+	// parsed, so that the results of finished packages are kept if the
+	// report is never written, see checkpoint.Writer. Packages are written
+	// as parsed, before any other options are applied. Nothing is written
+	// when using a parser from the parser registry.
+	Checkpoint io.Writer
+
+	// InputFiles lists files to read the input from instead of the input
+	// passed to Run, for example the logs of multiple CI shards. The files
+	// are parsed concurrently by up to Workers goroutines, or one per CPU if
+	// Workers is not positive, and the results are merged into one report,
+	// see parser.ParseFiles.
+	InputFiles []string
+	Workers    int
+
+	// Update is a report of an earlier run to update with the results parsed
+	// from the input, for example when rerunning only some of its packages in
+	// watch mode. Packages in the input replace the packages with the same
+	// name in Update, see gtr.Update.
+	Update *gtr.Report
+
+	// Warnings is where the warnings found while parsing the input, such as
+	// tests that ended without having been started or output that doesn't
+	// belong to any package, are written, one per line, see
+	// gotest.WarningHandler. If FailOnWarnings is set, Run returns an error
+	// if there are any warnings.
+	Warnings       io.Writer
+	FailOnWarnings bool
+
+	// Problems is where the problems that gtr.Report.Validate finds in the
+	// parsed report are written, one per line. If FailOnProblems is set, Run
+	// returns an error when any problems are found. The report is only
+	// validated if Problems is not nil or FailOnProblems is set.
+	Problems       io.Writer
+	FailOnProblems bool
+
 	// For debugging
 	PrintEvents bool
 }
 
 // Run runs the go-junit-report command and returns the generated report.
 func (c Config) Run(input io.Reader, output io.Writer) (*gtr.Report, error) {
-	var p parser
-	switch c.Parser {
-	case "gotest":
-		p = gotest.NewParser(c.gotestOptions()...)
-	case "gojson":
-		p = gotest.NewJSONParser(c.gotestOptions()...)
-	default:
-		return nil, fmt.Errorf("invalid parser: %s", c.Parser)
+	format := c.Format
+	if format == "" {
+		format = "junit"
+	}
+	write, ok := formats[format]
+	if !ok {
+		return nil, fmt.Errorf("invalid format: %s", c.Format)
+	}
+
+	var options []gotest.Option
+	var handlers []func(gotest.Event)
+	var events *teamcity.EventWriter
+	if format == "teamcity" {
+		events = teamcity.NewEventWriter(output)
+		handlers = append(handlers, events.HandleEvent)
+	}
+	var pw *progress.Writer
+	if c.Progress != nil {
+		pw = progress.NewWriter(c.Progress)
+		handlers = append(handlers, pw.HandleEvent)
+	}
+	if len(handlers) > 0 {
+		var mu sync.Mutex // events of InputFiles are parsed concurrently
+		options = append(options, gotest.EventHandler(func(ev gotest.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, handle := range handlers {
+				handle(ev)
+			}
+		}))
+	}
+
+	var cw *checkpoint.Writer
+	if c.Checkpoint != nil {
+		cw = checkpoint.NewWriter(c.Checkpoint)
+		var mu sync.Mutex // packages of InputFiles are parsed concurrently
+		options = append(options, gotest.PackageHandler(func(pkg gtr.Package) {
+			mu.Lock()
+			defer mu.Unlock()
+			cw.HandlePackage(pkg)
+		}))
+	}
+
+	var warnings []gotest.Warning
+	if c.Warnings != nil || c.FailOnWarnings {
+		var mu sync.Mutex // InputFiles are parsed concurrently
+		options = append(options, gotest.WarningHandler(func(w gotest.Warning) {
+			mu.Lock()
+			defer mu.Unlock()
+			warnings = append(warnings, w)
+		}))
+	}
+
+	p, err := c.newParser(options...)
+	if err != nil {
+		return nil, err
 	}
 
-	report, err := p.Parse(input)
+	var inputDigest *provenance.Writer
+	if c.Provenance != nil && len(c.InputFiles) == 0 {
+		inputDigest = provenance.NewWriter(ioutil.Discard, nil)
+		input = io.TeeReader(input, inputDigest)
+	}
+
+	var report gtr.Report
+	if len(c.InputFiles) > 0 {
+		report, err = c.parseFiles(options)
+	} else {
+		report, err = p.Parse(input)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error parsing input: %w", err)
 	}
+	if c.Hostname != "" {
+		report.SetHostname(c.Hostname)
+	}
+	if c.ShardCount > 0 {
+		report.SetShard(c.ShardIndex, c.ShardCount)
+	}
+	if c.RunMeta != nil {
+		report.RunMeta = c.RunMeta
+	}
+	if events != nil {
+		if err := events.Finish(); err != nil {
+			return nil, err
+		}
+	}
+	if pw != nil {
+		if err := pw.Finish(); err != nil {
+			return nil, err
+		}
+	}
+	if cw != nil {
+		if err := cw.Err(); err != nil {
+			return nil, fmt.Errorf("error writing checkpoint: %w", err)
+		}
+	}
 
 	if c.PrintEvents {
 		enc := json.NewEncoder(os.Stderr)
@@ -58,33 +568,580 @@ func (c Config) Run(input io.Reader, output io.Writer) (*gtr.Report, error) {
 		}
 	}
 
+	if err := c.writeWarnings(warnings); err != nil {
+		return nil, err
+	}
+
+	if c.Problems != nil || c.FailOnProblems {
+		if err := c.validate(report); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Update != nil {
+		report = gtr.Update(*c.Update, report)
+	}
+	report = gtr.MarkKilled(report)
+
+	if len(c.Lint) > 0 {
+		if report, err = c.addLint(report); err != nil {
+			return nil, err
+		}
+	}
+	if len(c.Bazel) > 0 {
+		if report, err = c.addBazel(report); err != nil {
+			return nil, err
+		}
+	}
+
 	for i := range report.Packages {
 		for k, v := range c.Properties {
 			report.Packages[i].SetProperty(k, v)
 		}
 	}
+	if c.Provenance != nil {
+		if report, err = c.addProvenance(report, inputDigest); err != nil {
+			return nil, err
+		}
+	}
+	if c.Failfast || gtr.DetectFailFast(report, c.ExpectedTests) {
+		report = gtr.MarkFailFast(report, c.ExpectedTests)
+	}
+
+	if c.CoverageProfile != nil {
+		coverage.AddToReport(&report, c.CoverageProfile, c.CoveragePerFile)
+	}
+
+	report = gtr.HandleNoTestFiles(report, c.NoTestFiles)
+	report = gtr.HandleCached(report, c.Cached)
+	report = gtr.Dedup(report, c.Duplicates)
+
+	if c.GroupAttempts {
+		report = gtr.GroupAttempts(report)
+	}
 
-	if err = c.writeJunitXML(output, report); err != nil {
+	if c.BenchmarkBaseline != nil {
+		if report, err = c.compareBenchmarks(report); err != nil {
+			return nil, err
+		}
+	}
+
+	report = gtr.MarkSlowTests(report, gtr.SlowPolicy{Threshold: c.SlowThreshold, Fail: c.FailSlowTests})
+
+	report = gtr.EnforceCoverage(report, c.CoveragePolicy)
+	report = gtr.EnforceDurationBudgets(report, c.DurationBudgets)
+	report = gtr.AddMissingTests(report, c.ExpectedTests, c.MissingTests)
+
+	if len(c.CodeOwners) > 0 || c.LocateTests {
+		if report, err = c.addSourceInfo(report); err != nil {
+			return nil, err
+		}
+	}
+
+	report = gtr.Quarantine(report, c.Quarantine)
+	if c.FailureBaseline != nil {
+		report = gtr.MarkKnownFailures(report, *c.FailureBaseline)
+	}
+
+	report = gtr.ApplyRules(report, c.Rules)
+
+	c.applyOverrides(&report)
+
+	if c.MaxSubtestDepth > 0 {
+		capSubtestDepth(&report, c.MaxSubtestDepth)
+	}
+
+	if c.EmitOutputSize {
+		addOutputSizes(&report)
+	}
+
+	if c.EmitOverhead {
+		addOverhead(&report)
+	}
+
+	report = gtr.FormatLogs(report, c.LogOutput)
+	report = gtr.RedactOutput(report, c.RedactPatterns)
+	report = gtr.TruncateOutput(report, c.OutputLimits)
+	if c.SanitizeOutput {
+		report = gtr.SanitizeOutput(report, c.PreserveHTMLColors && format == "html")
+	}
+
+	report = gtr.AggregateSubtestDurations(report, c.SubtestDurations)
+	report = gtr.RoundDurations(report, c.DurationPrecision)
+
+	if err := sortReport(&report, c.Sort); err != nil {
+		return nil, err
+	}
+	if len(c.Modules) > 0 {
+		report = gtr.GroupByModule(report, c.Modules)
+	}
+	if len(c.TestOrder) > 0 {
+		report = gtr.ReorderTests(report, c.TestOrder)
+	}
+
+	if err = write(c, output, report); err != nil {
 		return nil, err
 	}
 	return &report, nil
 }
 
+// Write writes report to w in the output format of c, like Run does after
+// parsing the input. It can be used to write parts of the returned report
+// separately, for example the report of each module, see gtr.SplitByModule.
+func (c Config) Write(w io.Writer, report gtr.Report) error {
+	format := c.Format
+	if format == "" {
+		format = "junit"
+	}
+	write, ok := formats[format]
+	if !ok || format == "teamcity" {
+		return fmt.Errorf("invalid format: %s", c.Format)
+	}
+	return write(c, w, report)
+}
+
+// newParser returns the parser selected by c.Parser, using the options of c
+// and the given additional options. Parsers other than gotest, gojson and
+// ginkgo are looked up in the parser registry, and don't use any options.
+func (c Config) newParser(options ...gotest.Option) (eventParser, error) {
+	options = append(c.gotestOptions(), options...)
+	switch c.Parser {
+	case "gotest", "text":
+		return gotest.NewParser(options...), nil
+	case "gojson", "json":
+		return gotest.NewJSONParser(options...), nil
+	case "ginkgo":
+		return ginkgo.NewParser(options...), nil
+	default:
+		p, err := parser.New(c.Parser)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parser: %s", c.Parser)
+		}
+		return registeredParser{p}, nil
+	}
+}
+
+// addProvenance returns report annotated with c.Provenance. The input digest
+// is taken from inputDigest, or computed from c.InputFiles if it's nil.
+func (c Config) addProvenance(report gtr.Report, inputDigest *provenance.Writer) (gtr.Report, error) {
+	info := *c.Provenance
+	if info.InputDigest == "" {
+		if inputDigest == nil {
+			inputDigest = provenance.NewWriter(ioutil.Discard, nil)
+			for _, name := range c.InputFiles {
+				if err := copyFile(inputDigest, name); err != nil {
+					return report, fmt.Errorf("error reading input: %w", err)
+				}
+			}
+		}
+		info.InputDigest = inputDigest.Digest()
+	}
+	if info.Generated.IsZero() {
+		info.Generated = time.Now()
+		if c.TimestampFunc != nil {
+			info.Generated = c.TimestampFunc()
+		}
+	}
+	return provenance.Annotate(report, info), nil
+}
+
+// copyFile copies the contents of the named file to w.
+func copyFile(w io.Writer, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// parseFiles parses c.InputFiles concurrently, using a new parser with the
+// given options for each file.
+func (c Config) parseFiles(options []gotest.Option) (gtr.Report, error) {
+	factory := func() parser.Parser {
+		p, _ := c.newParser(options...) // already known to succeed in Run
+		return p
+	}
+	reports, err := parser.ParseEach(factory, c.InputFiles, c.Workers)
+	if err != nil {
+		return gtr.Report{}, err
+	}
+	return gtr.MergeOptions{Duplicates: c.Duplicates}.Merge(reports...), nil
+}
+
+// writeWarnings writes warnings to c.Warnings, and returns an error if there
+// are any and c.FailOnWarnings is set.
+func (c Config) writeWarnings(warnings []gotest.Warning) error {
+	if c.Warnings != nil {
+		for _, w := range warnings {
+			if _, err := fmt.Fprintf(c.Warnings, "warning: %s\n", w); err != nil {
+				return err
+			}
+		}
+	}
+	if c.FailOnWarnings && len(warnings) > 0 {
+		return fmt.Errorf("parsing input failed: %d warnings", len(warnings))
+	}
+	return nil
+}
+
+// validate writes the problems found in report to c.Problems, and returns an
+// error if there are any and c.FailOnProblems is set.
+func (c Config) validate(report gtr.Report) error {
+	problems := report.Validate()
+	if c.Problems != nil {
+		for _, p := range problems {
+			if _, err := fmt.Fprintf(c.Problems, "%s\n", p); err != nil {
+				return err
+			}
+		}
+	}
+	if c.FailOnProblems && len(problems) > 0 {
+		return fmt.Errorf("report validation failed: %d problems found", len(problems))
+	}
+	return nil
+}
+
+// compareBenchmarks parses the benchmark baseline and returns a copy of report
+// in which benchmarks that regressed are marked as failed.
+func (c Config) compareBenchmarks(report gtr.Report) (gtr.Report, error) {
+	p, err := c.newParser()
+	if err != nil {
+		return report, err
+	}
+	baseline, err := p.Parse(c.BenchmarkBaseline)
+	if err != nil {
+		return report, fmt.Errorf("error parsing benchmark baseline: %w", err)
+	}
+	report, _ = gotest.CompareBenchmarks(baseline, report, c.BenchmarkThreshold)
+	return report, nil
+}
+
+// addLint parses the diagnostics in c.Lint and returns report merged with the
+// resulting lint package.
+func (c Config) addLint(report gtr.Report) (gtr.Report, error) {
+	reports := []gtr.Report{report}
+	for _, r := range c.Lint {
+		lintReport, err := lint.NewParser().Parse(r)
+		if err != nil {
+			return report, fmt.Errorf("error parsing lint output: %w", err)
+		}
+		reports = append(reports, lintReport)
+	}
+	return gtr.Merge(reports...), nil
+}
+
+// addBazel parses the test.xml files in c.Bazel and returns report merged
+// with their results.
+func (c Config) addBazel(report gtr.Report) (gtr.Report, error) {
+	reports := []gtr.Report{report}
+	for _, r := range c.Bazel {
+		bazelReport, err := bazel.NewParser(bazel.ImportPrefix(c.BazelImportPrefix)).Parse(r)
+		if err != nil {
+			return report, fmt.Errorf("error parsing bazel test.xml: %w", err)
+		}
+		reports = append(reports, bazelReport)
+	}
+	return gtr.MergeOptions{Duplicates: c.Duplicates}.Merge(reports...), nil
+}
+
+// addSourceInfo adds the code owners and test locations, which are both
+// based on the source files of the packages in report.
+func (c Config) addSourceInfo(report gtr.Report) (gtr.Report, error) {
+	var names []string
+	for _, pkg := range report.Packages {
+		names = append(names, pkg.Name)
+	}
+	src, err := srcloc.ListSources(names...)
+	if err != nil {
+		return report, fmt.Errorf("error finding package sources: %w", err)
+	}
+	if len(c.CodeOwners) > 0 {
+		report = codeowners.Annotate(report, c.CodeOwners, c.CodeOwnersRoot, src)
+	}
+	if c.LocateTests {
+		report = srcloc.Annotate(report, ".", src)
+	}
+	return report, nil
+}
+
 func (c Config) writeJunitXML(w io.Writer, report gtr.Report) error {
-	testsuites := junit.CreateFromReport(report, c.Hostname)
+	testsuites := junit.CreateFromReport(report, c.Hostname, c.junitCreateOptions()...)
+	if c.EmitIDs {
+		testsuites.ID = report.ContentHash()
+		for i := range testsuites.Suites {
+			testsuites.Suites[i].ID = junit.SuiteID(testsuites.Suites[i].Name)
+		}
+	}
+	if c.WallDuration > 0 {
+		setWallDuration(&testsuites, c.WallDuration, c.TimeFormat)
+	}
+	// The header is written before the post processors' output, so that the
+	// xml-stylesheet processing instruction is placed after it.
 	if !c.SkipXMLHeader {
 		_, err := fmt.Fprintf(w, xml.Header)
 		if err != nil {
 			return err
 		}
 	}
-	return testsuites.WriteXML(w)
+	var options []junit.WriteOption
+	if c.Dialect != "" {
+		options = append(options, junit.WithDialect(c.Dialect))
+	}
+	if c.XMLStylesheet != "" {
+		options = append(options, junit.WithXMLPostProcessor(xmlStylesheet(c.XMLStylesheet)))
+	}
+	return testsuites.WriteXML(w, options...)
+}
+
+// junitCreateOptions returns the junit.CreateOptions for the naming options of
+// c.
+func (c Config) junitCreateOptions() []junit.CreateOption {
+	var options []junit.CreateOption
+	if c.StripModulePrefix != "" {
+		options = append(options, junit.WithoutModulePrefix(c.StripModulePrefix))
+	}
+	if c.PackageSeparator != "" {
+		options = append(options, junit.WithPackageSeparator(c.PackageSeparator))
+	}
+	if c.PackageNameFormat != "" {
+		options = append(options, junit.WithPackageNameFormat(c.PackageNameFormat))
+	}
+	if c.TestNamePrefix != "" {
+		options = append(options, junit.WithTestNamePrefix(c.TestNamePrefix))
+	}
+	if c.TimeFormat != (junit.TimeFormat{}) {
+		options = append(options, junit.WithTimeFormat(c.TimeFormat))
+	}
+	return options
+}
+
+func (c Config) writeTAP(w io.Writer, report gtr.Report) error {
+	return tap.Write(w, report)
+}
+
+func (c Config) writeHTML(w io.Writer, report gtr.Report) error {
+	return html.Write(w, report)
+}
+
+func (c Config) writeMarkdown(w io.Writer, report gtr.Report) error {
+	return markdown.Write(w, report)
+}
+
+func (c Config) writeTemplate(w io.Writer, report gtr.Report) error {
+	if c.Template == nil {
+		return fmt.Errorf("no template for the template format")
+	}
+	timestamp := time.Now()
+	if c.TimestampFunc != nil {
+		timestamp = c.TimestampFunc()
+	}
+	return tmpl.Write(w, c.Template, report, timestamp)
+}
+
+func (c Config) writeCTRF(w io.Writer, report gtr.Report) error {
+	return ctrf.Write(w, report)
+}
+
+func (c Config) writeBuildkite(w io.Writer, report gtr.Report) error {
+	payload, err := BuildkitePayload(report)
+	if err != nil {
+		return err
+	}
+	return payload.WriteJSON(w)
+}
+
+// BuildkitePayload returns the Buildkite Test Analytics payload of report,
+// describing the Buildkite build it's running in. Outside of Buildkite, the
+// payload has a new random run key.
+func BuildkitePayload(report gtr.Report) (buildkite.Payload, error) {
+	env := buildkite.EnvFromEnviron(os.Getenv)
+	if env.Key == "" {
+		key, err := buildkite.NewKey()
+		if err != nil {
+			return buildkite.Payload{}, err
+		}
+		env.Key = key
+	}
+	return buildkite.CreateFromReport(report, buildkite.Options{RunEnv: env}), nil
+}
+
+func (c Config) writeEvergreen(w io.Writer, report gtr.Report) error {
+	return evergreen.Write(w, report)
+}
+
+func (c Config) writeAzureDevOps(w io.Writer, report gtr.Report) error {
+	return azuredevops.Write(w, report)
+}
+
+// writeGitLab writes report as JUnit XML in the dialect of the GitLab unit test
+// reports, regardless of the configured dialect.
+func (c Config) writeGitLab(w io.Writer, report gtr.Report) error {
+	c.Dialect = junit.DialectGitLab
+	return c.writeJunitXML(w, report)
+}
+
+func (c Config) writePretty(w io.Writer, report gtr.Report) error {
+	return pretty.Write(w, report, pretty.Options{Color: c.Color})
+}
+
+func (c Config) writeXUnit(w io.Writer, report gtr.Report) error {
+	assemblies := xunit.CreateFromReport(report)
+	return assemblies.WriteXML(w)
+}
+
+func (c Config) writeNUnit(w io.Writer, report gtr.Report) error {
+	run := nunit.CreateFromReport(report)
+	return run.WriteXML(w)
+}
+
+func (c Config) writeGitHub(w io.Writer, report gtr.Report) error {
+	return github.Write(w, report)
+}
+
+func (c Config) writeSonarQube(w io.Writer, report gtr.Report) error {
+	execs := sonarqube.CreateFromReport(report, c.SonarQubePaths)
+	return execs.WriteXML(w)
+}
+
+// writeTeamCity does nothing, since the service messages have already been
+// written while parsing the input.
+func (c Config) writeTeamCity(w io.Writer, report gtr.Report) error {
+	return nil
+}
+
+func (c Config) writeRerun(w io.Writer, report gtr.Report) error {
+	return rerun.Write(w, report)
+}
+
+func (c Config) writeBenchfmt(w io.Writer, report gtr.Report) error {
+	return benchfmt.Write(w, report, benchfmt.WithConfig(c.BenchfmtConfig))
+}
+
+func (c Config) writeCSV(w io.Writer, report gtr.Report) error {
+	return tabular.Write(w, report, tabular.Options{Columns: c.Columns})
+}
+
+func (c Config) writeTSV(w io.Writer, report gtr.Report) error {
+	return tabular.Write(w, report, tabular.Options{Columns: c.Columns, Comma: '\t'})
+}
+
+func (c Config) writeJSON(w io.Writer, report gtr.Report) error {
+	return gtrjson.Write(w, report)
+}
+
+// setWallDuration sets the time of testsuites to the given wall clock duration
+// and adds the sum of all testsuite times as a property to each testsuite.
+func setWallDuration(testsuites *junit.Testsuites, wall time.Duration, tf junit.TimeFormat) {
+	var sum float64
+	for _, suite := range testsuites.Suites {
+		t, _ := strconv.ParseFloat(suite.Time, 64) // ignore error
+		sum += t
+	}
+	if tf == (junit.TimeFormat{}) {
+		tf = junit.DefaultTimeFormat
+	}
+	summed := strconv.FormatFloat(sum, 'f', tf.Decimals, 64)
+	for i := range testsuites.Suites {
+		testsuites.Suites[i].AddProperty("summed.duration", summed)
+	}
+	testsuites.Time = tf.Format(wall)
+}
+
+// xmlStylesheet returns an XML post processor that prepends an
+// xml-stylesheet processing instruction referring to href.
+func xmlStylesheet(href string) func([]byte) ([]byte, error) {
+	return func(data []byte) ([]byte, error) {
+		var buf bytes.Buffer
+		buf.WriteString(`<?xml-stylesheet type="text/xsl" href="`)
+		if err := xml.EscapeText(&buf, []byte(href)); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\"?>\n")
+		buf.Write(data)
+		return buf.Bytes(), nil
+	}
+}
+
+// applyOverrides sets the result of every test named in c.Overrides and marks
+// them with an "overridden" property.
+func (c Config) applyOverrides(report *gtr.Report) {
+	if len(c.Overrides) == 0 {
+		return
+	}
+	for i := range report.Packages {
+		for j := range report.Packages[i].Tests {
+			test := &report.Packages[i].Tests[j]
+			if result, ok := c.Overrides[test.Name]; ok {
+				test.Result = result
+				test.AddProperty("overridden", "true")
+			}
+		}
+	}
+}
+
+// capSubtestDepth limits the level of all tests in the given report to max.
+// The depth of a test is its level or the number of subtest separators in its
+// name, whichever is larger.
+func capSubtestDepth(report *gtr.Report, max int) {
+	for i := range report.Packages {
+		for j := range report.Packages[i].Tests {
+			test := &report.Packages[i].Tests[j]
+			depth := test.Level
+			if n := strings.Count(test.Name, "/"); n > depth {
+				depth = n
+			}
+			if depth > max {
+				test.Level = max
+				test.AddProperty("subtest-depth", strconv.Itoa(depth))
+			}
+		}
+	}
+}
+
+// addOutputSizes adds the output-bytes property to all packages and tests in
+// the given report.
+func addOutputSizes(report *gtr.Report) {
+	for i := range report.Packages {
+		pkg := &report.Packages[i]
+		pkg.AddProperty("output-bytes", strconv.Itoa(pkg.OutputBytes()))
+		for j := range pkg.Tests {
+			test := &pkg.Tests[j]
+			test.AddProperty("output-bytes", strconv.Itoa(test.OutputBytes()))
+		}
+	}
+}
+
+func addOverhead(report *gtr.Report) {
+	for i := range report.Packages {
+		pkg := &report.Packages[i]
+		if pkg.Duration > 0 {
+			pkg.AddProperty("overhead-seconds", fmt.Sprintf("%.3f", pkg.Overhead().Seconds()))
+		}
+	}
 }
 
 func (c Config) gotestOptions() []gotest.Option {
-	return []gotest.Option{
+	options := []gotest.Option{
 		gotest.PackageName(c.PackageName),
 		gotest.SetSubtestMode(c.SubtestMode),
 		gotest.TimestampFunc(c.TimestampFunc),
+		gotest.InfraErrorPatterns(c.InfraErrorPatterns...),
+		gotest.DropPassedOutput(c.DropPassedOutput),
+		gotest.RecordEvents(c.PrintEvents),
+	}
+	if c.CaptureEnvironment {
+		options = append(options, gotest.PackageProperties(c.environmentProperties()...))
+	}
+	return options
+}
+
+// environmentProperties returns the properties added by CaptureEnvironment.
+func (c Config) environmentProperties() []gtr.Property {
+	props := gtr.EnvironmentProperties(os.Getenv)
+	if c.Hostname != "" {
+		props = append(props, gtr.Property{Name: "host.name", Value: c.Hostname})
 	}
+	return append(props, gtr.EnvironmentVariables(os.Getenv, c.EnvironmentVariables...)...)
 }
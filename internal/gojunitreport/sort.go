@@ -0,0 +1,43 @@
+package gojunitreport
+
+import (
+	"fmt"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// Sort orders supported by Config.Sort.
+const (
+	// SortDeclaration keeps packages and tests in the order they appeared in
+	// the input.
+	SortDeclaration = "declaration"
+
+	// SortName orders packages and tests by name.
+	SortName = "name"
+
+	// SortDuration orders packages and tests by duration, longest first.
+	SortDuration = "duration"
+
+	// SortFailuresFirst orders packages containing failures or errors before
+	// other packages, each group ordered by name. Within each package, failed
+	// tests are placed before the other tests, keeping their relative order.
+	SortFailuresFirst = "failures-first"
+)
+
+// sortReport orders the packages and tests in the given report according to
+// the given sort order.
+func sortReport(report *gtr.Report, order string) error {
+	switch order {
+	case "", SortDeclaration:
+	case SortName:
+		report.Sort(gtr.SortByName)
+	case SortDuration:
+		report.Sort(gtr.SortByDuration)
+	case SortFailuresFirst:
+		report.SortPackages(gtr.SortByFailuresFirst, gtr.SortByName)
+		report.SortTests(gtr.SortByFailuresFirst)
+	default:
+		return fmt.Errorf("invalid sort order: %s", order)
+	}
+	return nil
+}
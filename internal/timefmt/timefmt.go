@@ -0,0 +1,38 @@
+// Package timefmt implements the formatting of durations and timestamps
+// shared by the report writers.
+package timefmt
+
+import (
+	"strconv"
+	"time"
+)
+
+// Seconds returns duration d in seconds with the given number of decimal
+// places, e.g. "1.500" for 1500ms and 3 decimals.
+func Seconds(d time.Duration, decimals int) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', decimals, 64)
+}
+
+// Readable returns duration d in seconds with millisecond precision followed
+// by the unit, e.g. "1.500s", as written in reports that are meant to be read
+// by people.
+func Readable(d time.Duration) string {
+	return Seconds(d, 3) + "s"
+}
+
+// Millis returns duration d in whole milliseconds, or 0 if d is negative.
+func Millis(d time.Duration) int64 {
+	if d < 0 {
+		return 0
+	}
+	return int64(d / time.Millisecond)
+}
+
+// UnixMillis returns t as milliseconds since the Unix epoch, or 0 if t is the
+// zero time.
+func UnixMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,57 @@
+package timefmt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeconds(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		decimals int
+		want     string
+	}{
+		{0, 3, "0.000"},
+		{1500 * time.Millisecond, 3, "1.500"},
+		{1234567 * time.Microsecond, 3, "1.235"},
+		{1234567 * time.Nanosecond, 6, "0.001235"},
+	}
+	for _, test := range tests {
+		if got := Seconds(test.d, test.decimals); got != test.want {
+			t.Errorf("Seconds(%v, %d) = %q, want %q", test.d, test.decimals, got, test.want)
+		}
+	}
+}
+
+func TestReadable(t *testing.T) {
+	if got, want := Readable(1500*time.Millisecond), "1.500s"; got != want {
+		t.Errorf("Readable(1.5s) = %q, want %q", got, want)
+	}
+}
+
+func TestMillis(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want int64
+	}{
+		{0, 0},
+		{1500 * time.Microsecond, 1},
+		{2 * time.Second, 2000},
+		{-time.Second, 0},
+	}
+	for _, test := range tests {
+		if got := Millis(test.d); got != test.want {
+			t.Errorf("Millis(%v) = %d, want %d", test.d, got, test.want)
+		}
+	}
+}
+
+func TestUnixMillis(t *testing.T) {
+	if got := UnixMillis(time.Time{}); got != 0 {
+		t.Errorf("UnixMillis(zero time) = %d, want 0", got)
+	}
+	ts := time.Date(2022, 1, 1, 0, 0, 0, int(500*time.Millisecond), time.UTC)
+	if got, want := UnixMillis(ts), int64(1640995200500); got != want {
+		t.Errorf("UnixMillis(%v) = %d, want %d", ts, got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package junit
+
+import "fmt"
+
+// Dialect is a variant of the JUnit XML format understood by a particular
+// tool. Since there's no official JUnit XML specification, tools differ in
+// which elements and attributes they accept.
+type Dialect string
+
+// Supported dialects.
+const (
+	// DialectDefault is the format written by default, which includes
+	// everything go-junit-report is able to report.
+	DialectDefault Dialect = "default"
+
+	// DialectJenkins targets the Jenkins JUnit plugin, which accepts the
+	// default format as is.
+	DialectJenkins Dialect = "jenkins"
+
+	// DialectSurefire follows the Maven Surefire XML schema, which does not
+	// allow properties or a status on testcases, and output on testsuites.
+	DialectSurefire Dialect = "surefire"
+
+	// DialectAzure targets the Azure DevOps test results publisher, which
+	// does not support properties on testcases.
+	DialectAzure Dialect = "azure"
+
+	// DialectGitLab targets the GitLab unit test reports, which ignore
+	// properties and output on testsuites, and only show the contents of
+	// the failure, error or skipped element of a testcase. Output on stderr
+	// is added to this element so it's shown as well.
+	DialectGitLab Dialect = "gitlab"
+)
+
+// ParseDialect returns the Dialect with the given name.
+func ParseDialect(name string) (Dialect, error) {
+	switch d := Dialect(name); d {
+	case DialectDefault, DialectJenkins, DialectSurefire, DialectAzure, DialectGitLab:
+		return d, nil
+	default:
+		return "", fmt.Errorf("unknown junit dialect: %s", name)
+	}
+}
+
+// forDialect returns a copy of t adapted to dialect d.
+func (t *Testsuites) forDialect(d Dialect) (Testsuites, error) {
+	adapted := *t
+	adapted.Suites = make([]Testsuite, len(t.Suites))
+	for i, suite := range t.Suites {
+		suite.Testcases = append([]Testcase(nil), suite.Testcases...)
+		switch d {
+		case DialectDefault, DialectJenkins:
+		case DialectSurefire:
+			suite.SystemOut = nil
+			suite.SystemErr = nil
+			for j := range suite.Testcases {
+				suite.Testcases[j].Properties = nil
+				suite.Testcases[j].Status = ""
+			}
+		case DialectAzure:
+			for j := range suite.Testcases {
+				suite.Testcases[j].Properties = nil
+			}
+		case DialectGitLab:
+			suite.Properties = nil
+			suite.SystemOut = nil
+			suite.SystemErr = nil
+			for j := range suite.Testcases {
+				tc := &suite.Testcases[j]
+				tc.Properties = nil
+				tc.Status = ""
+				if tc.SystemErr != nil {
+					tc.Failure = withOutput(tc.Failure, tc.SystemErr.Data)
+					tc.Error = withOutput(tc.Error, tc.SystemErr.Data)
+					tc.Skipped = withOutput(tc.Skipped, tc.SystemErr.Data)
+					if tc.Failure != nil || tc.Error != nil || tc.Skipped != nil {
+						tc.SystemErr = nil
+					}
+				}
+			}
+		default:
+			return Testsuites{}, fmt.Errorf("unknown junit dialect: %s", d)
+		}
+		adapted.Suites[i] = suite
+	}
+	return adapted, nil
+}
+
+// withOutput returns a copy of result r with output appended to its data, or
+// nil if r is nil.
+func withOutput(r *Result, output string) *Result {
+	if r == nil {
+		return nil
+	}
+	adapted := *r
+	if adapted.Data != "" {
+		adapted.Data += "\n"
+	}
+	adapted.Data += output
+	return &adapted
+}
@@ -0,0 +1,119 @@
+package junit
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteXMLDialect(t *testing.T) {
+	props := []Property{{Name: "key", Value: "value"}}
+	suites := Testsuites{Suites: []Testsuite{{
+		Name:  "suite",
+		Tests: 1,
+		Testcases: []Testcase{{
+			Name:       "Test",
+			Classname:  "suite",
+			Status:     "run",
+			Properties: &props,
+		}},
+		SystemOut: &Output{Data: "output"},
+	}}}
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectJenkins, `<testsuites>
+	<testsuite name="suite" tests="1" failures="0" errors="0" id="0" time="">
+		<testcase name="Test" classname="suite" status="run">
+			<properties>
+				<property name="key" value="value"></property>
+			</properties>
+		</testcase>
+		<system-out><![CDATA[output]]></system-out>
+	</testsuite>
+</testsuites>
+`},
+		{DialectSurefire, `<testsuites>
+	<testsuite name="suite" tests="1" failures="0" errors="0" id="0" time="">
+		<testcase name="Test" classname="suite"></testcase>
+	</testsuite>
+</testsuites>
+`},
+		{DialectAzure, `<testsuites>
+	<testsuite name="suite" tests="1" failures="0" errors="0" id="0" time="">
+		<testcase name="Test" classname="suite" status="run"></testcase>
+		<system-out><![CDATA[output]]></system-out>
+	</testsuite>
+</testsuites>
+`},
+		{DialectGitLab, `<testsuites>
+	<testsuite name="suite" tests="1" failures="0" errors="0" id="0" time="">
+		<testcase name="Test" classname="suite"></testcase>
+	</testsuite>
+</testsuites>
+`},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.dialect), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := suites.WriteXML(&buf, WithDialect(test.dialect)); err != nil {
+				t.Fatalf("WriteXML failed: %v", err)
+			}
+			if diff := cmp.Diff(test.want, buf.String()); diff != "" {
+				t.Errorf("WriteXML mismatch, diff (-want +got):\n%s\n", diff)
+			}
+		})
+	}
+
+	if suites.Suites[0].Testcases[0].Properties == nil || suites.Suites[0].SystemOut == nil {
+		t.Errorf("WriteXML with dialect modified the original testsuites")
+	}
+}
+
+func TestWriteXMLDialectGitLabStderr(t *testing.T) {
+	failure := &Result{Message: "Failed", Data: "output"}
+	suites := Testsuites{Suites: []Testsuite{{
+		Name:  "suite",
+		Tests: 2,
+		Testcases: []Testcase{
+			{Name: "TestFail", Classname: "suite", Failure: failure, SystemErr: &Output{Data: "stderr"}},
+			{Name: "TestPass", Classname: "suite", SystemErr: &Output{Data: "stderr"}},
+		},
+	}}}
+
+	want := `<testsuites>
+	<testsuite name="suite" tests="2" failures="0" errors="0" id="0" time="">
+		<testcase name="TestFail" classname="suite">
+			<failure message="Failed"><![CDATA[output
+stderr]]></failure>
+		</testcase>
+		<testcase name="TestPass" classname="suite">
+			<system-err><![CDATA[stderr]]></system-err>
+		</testcase>
+	</testsuite>
+</testsuites>
+`
+	var buf bytes.Buffer
+	if err := suites.WriteXML(&buf, WithDialect(DialectGitLab)); err != nil {
+		t.Fatalf("WriteXML failed: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("WriteXML mismatch, diff (-want +got):\n%s\n", diff)
+	}
+	if failure.Data != "output" {
+		t.Errorf("WriteXML with dialect modified the original failure")
+	}
+}
+
+func TestParseDialect(t *testing.T) {
+	if d, err := ParseDialect("surefire"); err != nil || d != DialectSurefire {
+		t.Errorf("ParseDialect(surefire) = %q, %v; want %q", d, err, DialectSurefire)
+	}
+	if _, err := ParseDialect("unknown"); err == nil {
+		t.Errorf("ParseDialect(unknown) did not return an error")
+	}
+}
@@ -1,21 +1,33 @@
 // Package junit defines a JUnit XML report and includes convenience methods
 // for working with these reports.
+//
+// Attributes and child elements are always written in the order in which the
+// corresponding fields are declared in the types of this package, which
+// encoding/xml guarantees regardless of the Go version used. This order is
+// considered part of the output format, so reports created from the same input
+// are byte-for-byte identical.
 package junit
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
+	"hash/fnv"
 	"io"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/escape"
+	"github.com/jstemmer/go-junit-report/v2/internal/timefmt"
 )
 
 // Testsuites is a collection of JUnit testsuites.
 type Testsuites struct {
 	XMLName xml.Name `xml:"testsuites"`
 
+	ID       string `xml:"id,attr,omitempty"`
 	Name     string `xml:"name,attr,omitempty"`
 	Time     string `xml:"time,attr,omitempty"` // total duration in seconds
 	Tests    int    `xml:"tests,attr,omitempty"`
@@ -37,8 +49,74 @@ func (t *Testsuites) AddSuite(ts Testsuite) {
 	t.Disabled += ts.Disabled
 }
 
+// WriteOption defines options that can be passed to Testsuites.WriteXML.
+type WriteOption func(*writeOptions)
+
+type writeOptions struct {
+	dialect        Dialect
+	postProcessors []func([]byte) ([]byte, error)
+}
+
+// WithDialect is a WriteOption that adapts the report to the JUnit dialect d
+// before it is written.
+func WithDialect(d Dialect) WriteOption {
+	return func(o *writeOptions) {
+		o.dialect = d
+	}
+}
+
+// WithXMLPostProcessor is a WriteOption that adds a function to transform the
+// serialized XML document before it is written. Post processors receive the
+// testsuites element as written by WriteXML, after the report has been adapted
+// to the WithDialect dialect. This doesn't include the XML declaration
+// (xml.Header), which WriteXML doesn't write: callers write it to w before
+// calling WriteXML, so it always precedes the output of the post processors,
+// e.g. a processing instruction prepended by one of them.
+//
+// Post processors are called in the order they were given, each receiving the
+// output of the previous one. Nothing is written to the writer until all post
+// processors have returned without error.
+func WithXMLPostProcessor(f func([]byte) ([]byte, error)) WriteOption {
+	return func(o *writeOptions) {
+		o.postProcessors = append(o.postProcessors, f)
+	}
+}
+
 // WriteXML writes the XML representation of Testsuites t to writer w.
-func (t *Testsuites) WriteXML(w io.Writer) error {
+func (t *Testsuites) WriteXML(w io.Writer, options ...WriteOption) error {
+	var opts writeOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	if opts.dialect != "" && opts.dialect != DialectDefault {
+		adapted, err := t.forDialect(opts.dialect)
+		if err != nil {
+			return err
+		}
+		t = &adapted
+	}
+
+	if len(opts.postProcessors) == 0 {
+		return t.writeXML(w)
+	}
+
+	var buf bytes.Buffer
+	if err := t.writeXML(&buf); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+	for _, f := range opts.postProcessors {
+		var err error
+		if data, err = f(data); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+func (t *Testsuites) writeXML(w io.Writer) error {
 	enc := xml.NewEncoder(w)
 	enc.Indent("", "\t")
 	if err := enc.Encode(t); err != nil {
@@ -51,6 +129,15 @@ func (t *Testsuites) WriteXML(w io.Writer) error {
 	return err
 }
 
+// SuiteID returns a deterministic testsuite id for the suite with the given
+// name. The id is the 32-bit FNV-1a hash of the name with the sign bit
+// cleared, so the same name always results in the same non-negative id.
+func SuiteID(name string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return int(h.Sum32() & 0x7fffffff)
+}
+
 // Testsuite is a single JUnit testsuite containing testcases.
 type Testsuite struct {
 	// required attributes
@@ -118,6 +205,10 @@ type Testcase struct {
 	// optional attributes
 	Time   string `xml:"time,attr,omitempty"` // duration in seconds
 	Status string `xml:"status,attr,omitempty"`
+	File   string `xml:"file,attr,omitempty"`
+	Line   int    `xml:"line,attr,omitempty"`
+
+	Properties *[]Property `xml:"properties>property,omitempty"`
 
 	Skipped   *Result `xml:"skipped,omitempty"`
 	Error     *Result `xml:"error,omitempty"`
@@ -126,6 +217,17 @@ type Testcase struct {
 	SystemErr *Output `xml:"system-err,omitempty"`
 }
 
+// AddProperty adds a property with the given name and value to this Testcase.
+func (t *Testcase) AddProperty(name, value string) {
+	prop := Property{Name: name, Value: value}
+	if t.Properties == nil {
+		t.Properties = &[]Property{prop}
+		return
+	}
+	props := append(*t.Properties, prop)
+	t.Properties = &props
+}
+
 // Property represents a key/value pair.
 type Property struct {
 	Name  string `xml:"name,attr"`
@@ -144,46 +246,111 @@ type Output struct {
 	Data string `xml:",cdata"`
 }
 
+// Package properties with these names set the corresponding attribute of the
+// testsuite created by CreateFromReport, instead of being added to the
+// testsuite as a property. An invalid PropertyID is ignored.
+const (
+	PropertyHostname = "junit.hostname"
+	PropertyID       = "junit.id"
+	PropertyPackage  = "junit.package"
+)
+
+// PropertyFailFast is the property added to every testsuite created by
+// CreateFromReport for a report that was aborted after the first failed test,
+// see gtr.Report.Aborted. Although it describes the whole report, it's added
+// to each testsuite since the testsuites element can't contain properties in
+// JUnit XML.
+const PropertyFailFast = "failfast"
+
 // CreateFromReport creates a JUnit representation of the given gtr.Report.
-func CreateFromReport(report gtr.Report, hostname string) Testsuites {
+// The hostname attribute of each testsuite is set to the hostname of its
+// package, or to hostname if unknown, unless the package has a
+// PropertyHostname property. The shard of a package is added as the
+// shard.index and shard.count properties, and an aborted report is marked
+// with the PropertyFailFast property. The given options change how
+// testsuites and testcases are named, see CreateOption.
+func CreateFromReport(report gtr.Report, hostname string, options ...CreateOption) Testsuites {
+	var opts createOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
 	var suites Testsuites
 	for _, pkg := range report.Packages {
 		var duration time.Duration
 		suite := Testsuite{
-			Name:     pkg.Name,
+			Name:     opts.packageName(pkg.Name),
 			Hostname: hostname,
 			ID:       len(suites.Suites),
 		}
+		if pkg.Hostname != "" {
+			suite.Hostname = pkg.Hostname
+		}
 
 		if !pkg.Timestamp.IsZero() {
 			suite.SetTimestamp(pkg.Timestamp)
 		}
 
 		for _, p := range pkg.Properties {
-			suite.AddProperty(p.Name, p.Value)
+			switch p.Name {
+			case PropertyHostname:
+				suite.Hostname = p.Value
+			case PropertyID:
+				if id, err := strconv.Atoi(p.Value); err == nil {
+					suite.ID = id
+				}
+			case PropertyPackage:
+				suite.Package = p.Value
+			default:
+				suite.AddProperty(p.Name, p.Value)
+			}
+		}
+		if report.Aborted {
+			suite.AddProperty(PropertyFailFast, "true")
+		}
+
+		if pkg.ShardCount > 0 {
+			suite.AddProperty("shard.index", strconv.Itoa(pkg.ShardIndex))
+			suite.AddProperty("shard.count", strconv.Itoa(pkg.ShardCount))
 		}
 
 		if len(pkg.Output) > 0 {
 			suite.SystemOut = &Output{Data: formatOutput(pkg.Output)}
 		}
+		addAttachments(&suite.SystemOut, pkg.Attachments)
+		if len(pkg.Stderr) > 0 {
+			suite.SystemErr = &Output{Data: formatOutput(pkg.Stderr)}
+		}
 
 		if pkg.Coverage > 0 {
 			suite.AddProperty("coverage.statements.pct", fmt.Sprintf("%.2f", pkg.Coverage))
 		}
 
+		if pkg.BuildDuration > 0 {
+			suite.AddProperty("build.duration", timefmt.Seconds(pkg.BuildDuration, 3))
+			suite.AddProperty("test.duration", timefmt.Seconds(pkg.Duration, 3))
+		}
+
 		for _, test := range pkg.Tests {
 			duration += test.Duration
-			suite.AddTestcase(createTestcaseForTest(pkg.Name, test))
+			tc := createTestcaseForTest(suite.Name, test, opts.timeFormat)
+			tc.Name = opts.testName(tc.Name)
+			suite.AddTestcase(tc)
 		}
 
 		// JUnit doesn't have a good way of dealing with build or runtime
-		// errors that happen before a test has started, so we create a single
-		// failing test that contains the build error details.
-		if pkg.BuildError.Name != "" {
+		// errors that happen before a test has started, so we create a
+		// failing test for each compiler diagnostic, or a single failing test
+		// that contains the build error details if there are none.
+		if pkg.BuildError.Name != "" && len(pkg.BuildError.Diagnostics) > 0 {
+			for _, d := range pkg.BuildError.Diagnostics {
+				suite.AddTestcase(createTestcaseForDiagnostic(opts.packageName(pkg.BuildError.Name), d, opts.timeFormat))
+			}
+		} else if pkg.BuildError.Name != "" {
 			tc := Testcase{
-				Classname: pkg.BuildError.Name,
+				Classname: opts.packageName(pkg.BuildError.Name),
 				Name:      pkg.BuildError.Cause,
-				Time:      formatDuration(0),
+				Time:      opts.timeFormat.Format(0),
 				Error: &Result{
 					Message: "Build error",
 					Data:    strings.Join(pkg.BuildError.Output, "\n"),
@@ -192,13 +359,20 @@ func CreateFromReport(report gtr.Report, hostname string) Testsuites {
 			suite.AddTestcase(tc)
 		}
 
-		if pkg.RunError.Name != "" {
+		if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			message, errorType := pkg.RunError.FailureReason(), ""
+			if pkg.RunError.Kind == gtr.ErrorKindSanitizer {
+				errorType = gtr.ErrorKindSanitizer
+			} else if pkg.RunError.Kind != gtr.ErrorKindInfra && pkg.RunError.Panic == nil {
+				message = "Runtime error"
+			}
 			tc := Testcase{
-				Classname: pkg.RunError.Name,
+				Classname: opts.packageName(pkg.RunError.Name),
 				Name:      "Failure",
-				Time:      formatDuration(0),
+				Time:      opts.timeFormat.Format(0),
 				Error: &Result{
-					Message: "Runtime error",
+					Message: message,
+					Type:    errorType,
 					Data:    strings.Join(pkg.RunError.Output, "\n"),
 				},
 			}
@@ -206,33 +380,74 @@ func CreateFromReport(report gtr.Report, hostname string) Testsuites {
 		}
 
 		if (pkg.Duration) == 0 {
-			suite.Time = formatDuration(duration)
+			suite.Time = opts.timeFormat.Format(duration)
 		} else {
-			suite.Time = formatDuration(pkg.Duration)
+			suite.Time = opts.timeFormat.Format(pkg.Duration)
 		}
 		suites.AddSuite(suite)
 	}
 	return suites
 }
 
-func createTestcaseForTest(pkgName string, test gtr.Test) Testcase {
+// createTestcaseForDiagnostic returns a failing testcase for compiler
+// diagnostic d of a build error, named after the location of d.
+func createTestcaseForDiagnostic(name string, d gtr.Diagnostic, tf TimeFormat) Testcase {
+	return Testcase{
+		Classname: name,
+		Name:      d.Location(),
+		Time:      tf.Format(0),
+		File:      d.File,
+		Line:      d.Line,
+		Error: &Result{
+			Message: "Build error",
+			Data:    d.String(),
+		},
+	}
+}
+
+func createTestcaseForTest(pkgName string, test gtr.Test, tf TimeFormat) Testcase {
 	tc := Testcase{
 		Classname: pkgName,
 		Name:      test.Name,
-		Time:      formatDuration(test.Duration),
+		Time:      tf.Format(test.Duration),
+		File:      test.File,
+		Line:      test.Line,
+	}
+
+	for _, p := range test.Properties {
+		tc.AddProperty(p.Name, p.Value)
+	}
+	if len(test.Attempts) > 0 {
+		tc.AddProperty("attempts", strconv.Itoa(len(test.Attempts)))
 	}
 
-	if test.Result == gtr.Fail {
+	result := test.Result.Base()
+	if result != test.Result {
+		// Extended results aren't supported by JUnit, so the test is
+		// reported with its base result and the actual result is recorded
+		// in a property.
+		tc.AddProperty("result", strings.ToLower(test.Result.String()))
+	}
+	if result == gtr.Fail {
+		message := test.FailureReason()
+		if message == "" {
+			message = "Failed"
+		}
 		tc.Failure = &Result{
-			Message: "Failed",
+			Message: message,
+			Type:    test.FailureType,
 			Data:    formatOutput(test.Output),
 		}
-	} else if test.Result == gtr.Skip {
+	} else if result == gtr.Skip {
+		message := "Skipped"
+		if test.SkipMessage != "" {
+			message = test.SkipMessage
+		}
 		tc.Skipped = &Result{
-			Message: "Skipped",
+			Message: message,
 			Data:    formatOutput(test.Output),
 		}
-	} else if test.Result == gtr.Unknown {
+	} else if result == gtr.Unknown {
 		tc.Error = &Result{
 			Message: "No test result found",
 			Data:    formatOutput(test.Output),
@@ -240,38 +455,79 @@ func createTestcaseForTest(pkgName string, test gtr.Test) Testcase {
 	} else if len(test.Output) > 0 {
 		tc.SystemOut = &Output{Data: formatOutput(test.Output)}
 	}
+
+	addAttachments(&tc.SystemOut, test.Attachments)
+	if len(test.Stderr) > 0 {
+		tc.SystemErr = &Output{Data: formatOutput(test.Stderr)}
+	}
+
+	// Flaky tests eventually passed, so they're reported as passing tests
+	// that are marked as flaky.
+	if test.Result == gtr.Flaky {
+		tc.AddProperty("flaky", "true")
+	}
 	return tc
 }
 
-// formatDuration returns the JUnit string representation of the given
-// duration.
-func formatDuration(d time.Duration) string {
-	return fmt.Sprintf("%.3f", d.Seconds())
+// addAttachments adds a [[ATTACHMENT|path]] line to out for each of the
+// given attachments that isn't already referenced that way in out. This is the
+// convention used by the Jenkins JUnit Attachments plugin.
+func addAttachments(out **Output, attachments []gtr.Attachment) {
+	var existing []string
+	if *out != nil {
+		existing = strings.Split((*out).Data, "\n")
+	}
+	var lines []string
+	for _, a := range attachments {
+		line := escape.XML("[[ATTACHMENT|" + a.Path + "]]")
+		if !containsLine(existing, line) {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	if *out == nil {
+		*out = &Output{Data: strings.Join(lines, "\n")}
+	} else {
+		(*out).Data += "\n" + strings.Join(lines, "\n")
+	}
 }
 
-// formatOutput combines the lines from the given output into a single string.
-func formatOutput(output []string) string {
-	return escapeIllegalChars(strings.Join(output, "\n"))
+// containsLine returns true if one of the given lines equals line, ignoring
+// surrounding whitespace.
+func containsLine(lines []string, line string) bool {
+	for _, l := range lines {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
 }
 
-func escapeIllegalChars(str string) string {
-	return strings.Map(func(r rune) rune {
-		if isInCharacterRange(r) {
-			return r
-		}
-		return '\uFFFD'
-	}, str)
+// TimeFormat determines how durations are written in the time attributes of
+// testsuites and testcases: as a multiple of Unit with Decimals decimal
+// places. The zero TimeFormat is DefaultTimeFormat.
+type TimeFormat struct {
+	Unit     time.Duration // time.Second if zero
+	Decimals int
+}
+
+// DefaultTimeFormat writes durations in seconds with millisecond precision,
+// as expected by most tools.
+var DefaultTimeFormat = TimeFormat{Unit: time.Second, Decimals: 3}
+
+// Format returns the time attribute value of duration d.
+func (f TimeFormat) Format(d time.Duration) string {
+	if f == (TimeFormat{}) {
+		f = DefaultTimeFormat
+	} else if f.Unit <= 0 {
+		f.Unit = time.Second
+	}
+	return strconv.FormatFloat(float64(d)/float64(f.Unit), 'f', f.Decimals, 64)
 }
 
-// Decide whether the given rune is in the XML Character Range, per
-// the Char production of https://www.xml.com/axml/testaxml.htm,
-// Section 2.2 Characters.
-// From: encoding/xml/xml.go
-func isInCharacterRange(r rune) (inrange bool) {
-	return r == 0x09 ||
-		r == 0x0A ||
-		r == 0x0D ||
-		r >= 0x20 && r <= 0xD7FF ||
-		r >= 0xE000 && r <= 0xFFFD ||
-		r >= 0x10000 && r <= 0x10FFFF
+// formatOutput combines the lines from the given output into a single string.
+func formatOutput(output []string) string {
+	return escape.XML(strings.Join(output, "\n"))
 }
@@ -3,6 +3,7 @@ package junit
 import (
 	"bytes"
 	"encoding/xml"
+	"errors"
 	"testing"
 	"time"
 
@@ -207,3 +208,345 @@ func TestWriteXML(t *testing.T) {
 		t.Errorf("WriteXML mismatch, diff (-want +got):\n%s\n", diff)
 	}
 }
+
+func TestWriteXMLAttributeOrder(t *testing.T) {
+	want := `<testsuites id="ids" name="all" time="1.000" tests="1" errors="1" failures="1" skipped="1" disabled="1">
+	<testsuite name="suite" tests="1" failures="1" errors="1" id="1" disabled="1" hostname="host" package="pkg" skipped="1" time="1.000" timestamp="2022-01-01T00:00:00Z" file="file.go">
+		<properties>
+			<property name="key" value="value"></property>
+		</properties>
+		<testcase name="Test" classname="pkg" time="1.000" status="run">
+			<properties>
+				<property name="key" value="value"></property>
+			</properties>
+			<skipped message="skip" type="s"><![CDATA[skipped]]></skipped>
+			<error message="error" type="e"><![CDATA[error]]></error>
+			<failure message="failure" type="f"><![CDATA[failure]]></failure>
+			<system-out><![CDATA[stdout]]></system-out>
+			<system-err><![CDATA[stderr]]></system-err>
+		</testcase>
+		<system-out><![CDATA[suite stdout]]></system-out>
+		<system-err><![CDATA[suite stderr]]></system-err>
+	</testsuite>
+</testsuites>
+`
+
+	props := []Property{{Name: "key", Value: "value"}}
+	suites := Testsuites{
+		ID: "ids", Name: "all", Time: "1.000", Tests: 1, Errors: 1, Failures: 1, Skipped: 1, Disabled: 1,
+		Suites: []Testsuite{{
+			Name: "suite", Tests: 1, Failures: 1, Errors: 1, ID: 1, Disabled: 1,
+			Hostname: "host", Package: "pkg", Skipped: 1, Time: "1.000",
+			Timestamp: "2022-01-01T00:00:00Z", File: "file.go",
+			Properties: &props,
+			Testcases: []Testcase{{
+				Name: "Test", Classname: "pkg", Time: "1.000", Status: "run",
+				Properties: &props,
+				Skipped:    &Result{Message: "skip", Type: "s", Data: "skipped"},
+				Error:      &Result{Message: "error", Type: "e", Data: "error"},
+				Failure:    &Result{Message: "failure", Type: "f", Data: "failure"},
+				SystemOut:  &Output{Data: "stdout"},
+				SystemErr:  &Output{Data: "stderr"},
+			}},
+			SystemOut: &Output{Data: "suite stdout"},
+			SystemErr: &Output{Data: "suite stderr"},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := suites.WriteXML(&buf); err != nil {
+		t.Fatalf("WriteXML failed: %v\n", err)
+	}
+
+	got := buf.String()
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("WriteXML attribute order changed, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWriteXMLPostProcessor(t *testing.T) {
+	want := `<!-- first --><!-- second --><testsuites></testsuites>
+`
+
+	prepend := func(s string) func([]byte) ([]byte, error) {
+		return func(data []byte) ([]byte, error) {
+			return append([]byte(s), data...), nil
+		}
+	}
+
+	var suites Testsuites
+	var buf bytes.Buffer
+	err := suites.WriteXML(&buf,
+		WithXMLPostProcessor(prepend("<!-- second -->")),
+		WithXMLPostProcessor(prepend("<!-- first -->")),
+	)
+	if err != nil {
+		t.Fatalf("WriteXML failed: %v\n", err)
+	}
+
+	got := buf.String()
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("WriteXML mismatch, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWriteXMLPostProcessorError(t *testing.T) {
+	fail := func([]byte) ([]byte, error) {
+		return nil, errors.New("post processor error")
+	}
+
+	var suites Testsuites
+	var buf bytes.Buffer
+	if err := suites.WriteXML(&buf, WithXMLPostProcessor(fail)); err == nil {
+		t.Fatalf("WriteXML did not return an error")
+	}
+	if buf.Len() > 0 {
+		t.Errorf("WriteXML wrote %d bytes, want none", buf.Len())
+	}
+}
+
+func TestCreateFromReportAttachments(t *testing.T) {
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{
+				Name:        "package/name",
+				Output:      []string{"[[ATTACHMENT|cpu.pprof]]"},
+				Attachments: []gtr.Attachment{{Path: "cpu.pprof"}, {Path: "mem.pprof"}},
+				Tests: []gtr.Test{
+					{
+						Name:        "TestPass",
+						Result:      gtr.Pass,
+						Output:      []string{"ok"},
+						Attachments: []gtr.Attachment{{Name: "screenshot", Path: "pass.png", MIME: "image/png"}},
+					},
+					{
+						Name:        "TestFail",
+						Result:      gtr.Fail,
+						Output:      []string{"[[ATTACHMENT|fail.png]]"},
+						Attachments: []gtr.Attachment{{Path: "fail.png"}},
+					},
+				},
+			},
+		},
+	}
+
+	suite := CreateFromReport(report, "").Suites[0]
+	want := []*Output{
+		{Data: "[[ATTACHMENT|cpu.pprof]]\n[[ATTACHMENT|mem.pprof]]"},
+		{Data: "ok\n[[ATTACHMENT|pass.png]]"},
+		{Data: "[[ATTACHMENT|fail.png]]"},
+	}
+	got := []*Output{suite.SystemOut, suite.Testcases[0].SystemOut, suite.Testcases[1].SystemOut}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CreateFromReport system-out incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestCreateFromReportAttributeProperties(t *testing.T) {
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{
+				Name: "package/one",
+				Properties: []gtr.Property{
+					{Name: "go.version", Value: "1.18"},
+					{Name: PropertyHostname, Value: "runner-1"},
+					{Name: PropertyID, Value: "42"},
+					{Name: PropertyPackage, Value: "one"},
+				},
+			},
+			{
+				Name:       "package/two",
+				Properties: []gtr.Property{{Name: PropertyID, Value: "invalid"}},
+			},
+			{
+				Name:       "package/three",
+				Hostname:   "runner-3",
+				ShardIndex: 2,
+				ShardCount: 4,
+			},
+		},
+	}
+
+	suites := CreateFromReport(report, "hostname")
+	type attrs struct {
+		Hostname, Package string
+		ID                int
+		Properties        *[]Property
+	}
+	want := []attrs{
+		{"runner-1", "one", 42, &[]Property{{Name: "go.version", Value: "1.18"}}},
+		{"hostname", "", 1, nil},
+		{"runner-3", "", 2, &[]Property{{Name: "shard.index", Value: "2"}, {Name: "shard.count", Value: "4"}}},
+	}
+	var got []attrs
+	for _, suite := range suites.Suites {
+		got = append(got, attrs{suite.Hostname, suite.Package, suite.ID, suite.Properties})
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CreateFromReport testsuite attributes incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestCreateFromReportAborted(t *testing.T) {
+	report := gtr.Report{
+		Aborted: true,
+		Packages: []gtr.Package{
+			{Name: "package/one", Properties: []gtr.Property{{Name: "go.version", Value: "1.18"}}},
+			{Name: "package/two"},
+		},
+	}
+
+	want := []*[]Property{
+		{{Name: "go.version", Value: "1.18"}, {Name: PropertyFailFast, Value: "true"}},
+		{{Name: PropertyFailFast, Value: "true"}},
+	}
+	var got []*[]Property
+	for _, suite := range CreateFromReport(report, "").Suites {
+		got = append(got, suite.Properties)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CreateFromReport testsuite properties incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestCreateFromReportDiagnostics(t *testing.T) {
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{
+				Name: "package/name",
+				BuildError: gtr.Error{
+					Name:   "package/name",
+					Cause:  "[build failed]",
+					Output: []string{"./main.go:10:5: undefined: x", "./util.go:3: missing return"},
+					Diagnostics: []gtr.Diagnostic{
+						{File: "./main.go", Line: 10, Column: 5, Message: "undefined: x"},
+						{File: "./util.go", Line: 3, Message: "missing return"},
+					},
+				},
+			},
+		},
+	}
+
+	want := []Testcase{
+		{
+			Name:      "./main.go:10:5",
+			Classname: "package/name",
+			Time:      "0.000",
+			File:      "./main.go",
+			Line:      10,
+			Error:     &Result{Message: "Build error", Data: "./main.go:10:5: undefined: x"},
+		},
+		{
+			Name:      "./util.go:3",
+			Classname: "package/name",
+			Time:      "0.000",
+			File:      "./util.go",
+			Line:      3,
+			Error:     &Result{Message: "Build error", Data: "./util.go:3: missing return"},
+		},
+	}
+
+	got := CreateFromReport(report, "")
+	if len(got.Suites) != 1 {
+		t.Fatalf("CreateFromReport created %d suites, want 1", len(got.Suites))
+	}
+	if diff := cmp.Diff(want, got.Suites[0].Testcases); diff != "" {
+		t.Errorf("CreateFromReport testcases incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if got.Suites[0].Tests != 2 || got.Suites[0].Errors != 2 {
+		t.Errorf("CreateFromReport suite has %d tests and %d errors, want 2 and 2", got.Suites[0].Tests, got.Suites[0].Errors)
+	}
+}
+
+func TestCreateFromReportNaming(t *testing.T) {
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{
+				Name:  "github.com/org/repo/pkg/util",
+				Tests: []gtr.Test{{Name: "TestOne", Result: gtr.Pass}},
+			},
+			{
+				Name:     "github.com/org/repo",
+				Tests:    []gtr.Test{{Name: "TestTwo", Result: gtr.Pass}},
+				RunError: gtr.Error{Name: "github.com/org/repo"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		options []CreateOption
+		want    []string // suite name, testcase classname and name of each testcase
+	}{
+		{
+			"default",
+			nil,
+			[]string{
+				"github.com/org/repo/pkg/util", "github.com/org/repo/pkg/util", "TestOne",
+				"github.com/org/repo", "github.com/org/repo", "TestTwo", "github.com/org/repo", "Failure",
+			},
+		},
+		{
+			"strip module prefix",
+			[]CreateOption{WithoutModulePrefix("github.com/org/repo/")},
+			[]string{
+				"pkg/util", "pkg/util", "TestOne",
+				"github.com/org/repo", "github.com/org/repo", "TestTwo", "github.com/org/repo", "Failure",
+			},
+		},
+		{
+			"all options",
+			[]CreateOption{
+				WithoutModulePrefix("github.com/org/repo"),
+				WithPackageSeparator("."),
+				WithPackageNameFormat("unit.{package}"),
+				WithTestNamePrefix("go."),
+			},
+			[]string{
+				"unit.pkg.util", "unit.pkg.util", "go.TestOne",
+				"unit.github.com.org.repo", "unit.github.com.org.repo", "go.TestTwo", "unit.github.com.org.repo", "Failure",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			for _, suite := range CreateFromReport(report, "", test.options...).Suites {
+				got = append(got, suite.Name)
+				for _, tc := range suite.Testcases {
+					got = append(got, tc.Classname, tc.Name)
+				}
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("CreateFromReport names incorrect, diff (-want +got):\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestTimeFormat(t *testing.T) {
+	d := 1234567 * time.Microsecond
+	tests := []struct {
+		format TimeFormat
+		want   string
+	}{
+		{TimeFormat{}, "1.235"},
+		{DefaultTimeFormat, "1.235"},
+		{TimeFormat{Unit: time.Second, Decimals: 1}, "1.2"},
+		{TimeFormat{Unit: time.Second}, "1"},
+		{TimeFormat{Unit: time.Millisecond}, "1235"},
+		{TimeFormat{Decimals: 2}, "1.23"},
+	}
+	for _, test := range tests {
+		if got := test.format.Format(d); got != test.want {
+			t.Errorf("%+v.Format(%v) = %q, want %q", test.format, d, got, test.want)
+		}
+	}
+
+	report := gtr.Report{Packages: []gtr.Package{{Name: "package/name", Duration: d, Tests: []gtr.Test{{Name: "TestA", Result: gtr.Pass, Duration: d}}}}}
+	suites := CreateFromReport(report, "", WithTimeFormat(TimeFormat{Unit: time.Millisecond}))
+	if got := suites.Suites[0].Time + " " + suites.Suites[0].Testcases[0].Time; got != "1235 1235" {
+		t.Errorf("CreateFromReport with time format in milliseconds: times = %q, want %q", got, "1235 1235")
+	}
+}
@@ -0,0 +1,86 @@
+package junit
+
+import "strings"
+
+// CreateOption defines options that can be passed to CreateFromReport.
+type CreateOption func(*createOptions)
+
+type createOptions struct {
+	modulePrefix      string
+	packageSeparator  string
+	packageNameFormat string
+	testNamePrefix    string
+	timeFormat        TimeFormat
+}
+
+// WithTimeFormat is a CreateOption that sets the format of the time attributes
+// of testsuites and testcases. Note that tools reading JUnit reports usually
+// expect durations in seconds, so changing the Unit should only be needed
+// for tools that don't.
+func WithTimeFormat(f TimeFormat) CreateOption {
+	return func(o *createOptions) {
+		o.timeFormat = f
+	}
+}
+
+// WithoutModulePrefix is a CreateOption that removes module path prefix
+// module from package names, e.g. github.com/org/repo/pkg/util becomes
+// pkg/util for module github.com/org/repo. The package of the module itself
+// keeps its name.
+func WithoutModulePrefix(module string) CreateOption {
+	return func(o *createOptions) {
+		o.modulePrefix = strings.TrimSuffix(module, "/")
+	}
+}
+
+// WithPackageSeparator is a CreateOption that replaces the slashes in package
+// names with sep. Using "." results in Java-like names, which some tools such
+// as Jenkins use to group testsuites into a hierarchy of packages.
+func WithPackageSeparator(sep string) CreateOption {
+	return func(o *createOptions) {
+		o.packageSeparator = sep
+	}
+}
+
+// WithPackageNameFormat is a CreateOption that sets the testsuite name and
+// testcase classname to format, in which every occurrence of {package} is
+// replaced by the package name. The format is applied after the module prefix
+// has been removed and the separators have been replaced.
+func WithPackageNameFormat(format string) CreateOption {
+	return func(o *createOptions) {
+		o.packageNameFormat = format
+	}
+}
+
+// WithTestNamePrefix is a CreateOption that adds prefix to the name of every
+// testcase created for a test.
+func WithTestNamePrefix(prefix string) CreateOption {
+	return func(o *createOptions) {
+		o.testNamePrefix = prefix
+	}
+}
+
+// packageName returns the name of package pkgName after applying the naming
+// options.
+func (o createOptions) packageName(pkgName string) string {
+	if pkgName == "" {
+		return pkgName
+	}
+	name := pkgName
+	if o.modulePrefix != "" && strings.HasPrefix(name, o.modulePrefix+"/") {
+		name = strings.TrimPrefix(name, o.modulePrefix+"/")
+	}
+	if o.packageSeparator != "" {
+		name = strings.Replace(name, "/", o.packageSeparator, -1)
+	}
+	if o.packageNameFormat != "" {
+		name = strings.Replace(o.packageNameFormat, "{package}", name, -1)
+	}
+	return name
+}
+
+// testName returns the name of test testName after applying the naming
+// options.
+func (o createOptions) testName(testName string) string {
+	return o.testNamePrefix + testName
+}
@@ -4,14 +4,49 @@
 package main
 
 import (
+	"bytes"
+	"crypto/ed25519"
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/jstemmer/go-junit-report/v2/allure"
+	"github.com/jstemmer/go-junit-report/v2/buildkite"
+	"github.com/jstemmer/go-junit-report/v2/bundle"
+	"github.com/jstemmer/go-junit-report/v2/cobertura"
+	"github.com/jstemmer/go-junit-report/v2/codeowners"
+	"github.com/jstemmer/go-junit-report/v2/config"
+	"github.com/jstemmer/go-junit-report/v2/coverage"
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/gtrjson"
+	"github.com/jstemmer/go-junit-report/v2/history"
 	"github.com/jstemmer/go-junit-report/v2/internal/gojunitreport"
+	"github.com/jstemmer/go-junit-report/v2/junit"
+	"github.com/jstemmer/go-junit-report/v2/markdown"
+	"github.com/jstemmer/go-junit-report/v2/metrics"
+	"github.com/jstemmer/go-junit-report/v2/notify"
+	"github.com/jstemmer/go-junit-report/v2/otlp"
+	gtrparser "github.com/jstemmer/go-junit-report/v2/parser"
 	"github.com/jstemmer/go-junit-report/v2/parser/gotest"
+	"github.com/jstemmer/go-junit-report/v2/provenance"
+	"github.com/jstemmer/go-junit-report/v2/rerun"
+	"github.com/jstemmer/go-junit-report/v2/server"
+	"github.com/jstemmer/go-junit-report/v2/sink"
+	"github.com/jstemmer/go-junit-report/v2/sonarqube"
+	"github.com/jstemmer/go-junit-report/v2/tabular"
+	"github.com/jstemmer/go-junit-report/v2/timing"
+	"github.com/jstemmer/go-junit-report/v2/tmpl"
+	"github.com/jstemmer/go-junit-report/v2/watch"
 )
 
 // Current release information printed by the -version flag.
@@ -23,14 +58,128 @@ var (
 
 var (
 	noXMLHeader = flag.Bool("no-xml-header", false, "do not print xml header")
+	stylesheet  = flag.String("xml-stylesheet", "", "add an xml-stylesheet processing instruction referring to `href` to the report")
+	dialectName = flag.String("junit-dialect", "default", "adapt the report to the JUnit `dialect` of a tool: default, jenkins, surefire, azure, gitlab")
 	packageName = flag.String("package-name", "", "specify a default package `name` to use if output does not contain a package name")
+	flaky       = flag.Bool("flaky", false, "combine repeated runs of the same test into one test and mark tests that both failed and passed as flaky")
+	duplicates  = flag.String("duplicates", "", "combine tests that appear more than once in a package, e.g. with go test -count or in several input files, using `strategy`: combine, keep-first, keep-last, keep-worst or keep-all-as-attempts; by default they're only combined across input files")
+	noTestFiles = flag.String("no-test-files", "", "report packages without test files according to `mode`: include (as an empty testsuite, default), omit, or skip (with a skipped placeholder test)")
+	cachedMode  = flag.String("cached", "", "report the durations of packages whose results were cached by go test according to `mode`: keep (default), zero, or annotate (with a cached property)")
+	subtestDurs = flag.String("subtest-durations", "", "report the durations of tests with subtests according to `mode`: inclusive (of their subtests, default) or exclusive")
+	failfast    = flag.Bool("failfast", false, "mark the report as created by go test -failfast, which stops after the first failure")
 	setExitCode = flag.Bool("set-exit-code", false, "set exit code to 1 if tests failed")
+	failFlaky   = flag.Bool("fail-on-flaky", false, "with -set-exit-code, also set exit code to 1 if tests are flaky")
+	failNoTests = flag.Bool("fail-on-no-tests", false, "with -set-exit-code, also set exit code to 1 if no tests were found")
 	version     = flag.Bool("version", false, "print version")
-	input       = flag.String("in", "", "read go test log from `file`")
-	output      = flag.String("out", "", "write XML report to `file`")
-	iocopy      = flag.Bool("iocopy", false, "copy input to stdout; can only be used in conjunction with -out")
+	input       = flag.String("in", "", "read go test log from `file`; use - to read from stdin")
+	inputPath   = flag.String("input", "", "read go test log from `file`; use - to read from stdin (same as -in)")
+	output      = flag.String("out", "", "write report to `file`; use - to write to stdout, or an s3://, gs:// or https:// URL to upload it; write a report per package if it contains {package}")
+	outputPath  = flag.String("output", "", "write report to `file`; use - to write to stdout (same as -out)")
+	watchMode   = flag.Bool("watch", false, "run the -watch-cmd, or read the -in file, and rewrite the -out report every time the Go source files in the current directory or the -in file change")
+	watchCmd    = flag.String("watch-cmd", "go test -v", "set the `command` run by -watch, to which the patterns of the packages to test are appended")
+	watchEvery  = flag.Duration("watch-interval", watch.DefaultInterval, "check for changes to the files watched by -watch every `duration`")
+	serveAddr   = flag.String("serve", "", "run an HTTP server on `addr`, e.g. :8080, that converts the go test output posted to /reports and serves the stored reports as JUnit XML, HTML or JSON")
+	serveDir    = flag.String("serve-dir", "", "with -serve, store the reports in `dir` instead of in memory")
+	workers     = flag.Int("workers", 0, "parse at most `n` input files at the same time when more than one is given; 0 means one per CPU")
+	iocopy      = flag.Bool("iocopy", false, "copy input to stdout; can only be used in conjunction with -out, enabled by default when -out is used and stdout is a terminal")
 	properties  = make(keyValueFlag)
-	parser      = flag.String("parser", "gotest", "set input parser: gotest, gojson")
+	sonarPaths  = make(keyValueFlag)
+	otlpHeaders = make(keyValueFlag)
+	overrides   = make(overrideFlag)
+	infraErrors regexpsFlag
+	envVars     stringsFlag
+	lintFiles   stringsFlag
+	bazelFiles  stringsFlag
+	ownerRules  stringsFlag
+	redactPats  regexpsFlag
+	redactEnv   stringsFlag
+	hostFlag    = flag.String("hostname", "", "set the `name` of the host that ran the tests, default the name of the current host")
+	shardFlag   = flag.String("shard", "", "record that the tests ran in shard `index/count`, e.g. 2/4, or use auto to detect the shard from the environment variables of CI systems that run parallel jobs")
+	exitStatus  = flag.Int("exit-code", 0, "record the exit `code` of the go test process, e.g. ${PIPESTATUS[0]}; a code above 128 means the process was terminated by a signal and marks the report as failed. When -watch runs the -watch-cmd, its exit code is recorded instead")
+	provenanceF = flag.Bool("provenance", false, "add provenance.tool, provenance.input_digest and provenance.generated properties with the go-junit-report version, the SHA-256 digest of the input and the generation time to each testsuite")
+	digestFile  = flag.String("digest", "", "also write the SHA-256 digest of the report to `file` in the format of sha256sum, to check the report with sha256sum -c")
+	signKey     = flag.String("sign-key", "", "sign the report with the PEM encoded Ed25519 private key in `file`, e.g. generated with openssl genpkey -algorithm ed25519; requires -signature")
+	sigFile     = flag.String("signature", "", "write the base64 encoded Ed25519 signature of the report created with -sign-key to `file`")
+	captureEnv  = flag.Bool("capture-env", false, "add properties describing the environment, such as go.version, go.os, go.arch, host.name and ci.build.url, to each testsuite")
+	parser      = flag.String("parser", "gotest", "set input parser: gotest (or text), gojson (or json), ginkgo (go test output of Ginkgo suites), lint (go vet -json or staticcheck output), junit (JUnit XML reports), bazel (Bazel test.xml files), checkpoint (files written by -checkpoint), or another parser registered in the parser package")
+	format      = flag.String("format", "junit", "set the output `format` of the report: junit, tap, json, html, github, sonarqube, teamcity, rerun, markdown, ctrf, xunit, nunit, benchfmt, csv, tsv, template, buildkite, evergreen, azure, gitlab, pretty")
+	tmplFile    = flag.String("template", "", "write the report using the Go text/template in `file`, or html/template if it has an .html extension; implies -format template")
+	columns     = flag.String("columns", "", "set the comma separated `list` of columns written by -format csv and tsv, default "+strings.Join(tabular.DefaultColumns, ","))
+	benchConfig = flag.Bool("benchfmt-config", false, "write package properties as configuration lines in -format benchfmt")
+	colorMode   = flag.String("color", "auto", "use ANSI colors in -format pretty: auto (when writing to a terminal and $NO_COLOR is not set), always or never")
+	bazelPrefix = flag.String("bazel-prefix", "", "prefix the Bazel package of the -bazel targets with import `path` to get their package names, usually the module path")
+	stripModule = flag.String("strip-module-prefix", "", "remove the `module` path prefix from the testsuite and classname of packages in the module")
+	pkgSep      = flag.String("package-separator", "", "replace the slashes in testsuite and classname package names with `sep`, e.g. .")
+	pkgFormat   = flag.String("package-name-format", "", "set testsuite and classname names to `format`, in which {package} is replaced by the package name")
+	testPrefix  = flag.String("test-name-prefix", "", "add `prefix` to the name of every testcase")
+	byModule    = flag.Bool("group-by-module", false, "group packages by the module they belong to, found with go list -m, e.g. the modules of a go.work workspace; write a report per module if -out contains {module}")
+	checkpoint  = flag.String("checkpoint", "", "write every package to `file` as soon as it has finished, so the results of finished packages are kept if go-junit-report is killed; convert it to a report with -parser checkpoint")
+	warnings    = flag.Bool("warnings", false, "write the anomalies found while parsing the input, such as tests that ended without having been started or output that doesn't belong to any package, to stderr")
+	failWarned  = flag.Bool("fail-on-warnings", false, "fail the conversion if -warnings finds any anomalies, enables -warnings")
+	validate    = flag.Bool("validate", false, "write the structural problems found in the parsed report, such as duplicate test names, negative durations or tests without a result, to stderr")
+	failInvalid = flag.Bool("fail-on-problems", false, "fail the conversion if -validate finds any problems, enables -validate")
+	showProg    = flag.Bool("progress", false, "show a live progress line with the number of passed, failed and skipped tests on stderr while converting")
+	timeUnit    = flag.String("junit-time-unit", "s", "write the time attributes of the JUnit report in `unit`: s (seconds) or ms (milliseconds)")
+	timeDigits  = flag.Int("junit-time-decimals", 3, "write the time attributes of the JUnit report with `n` decimal places")
+	precision   = flag.Duration("duration-precision", 0, "round all durations to a multiple of `duration`, e.g. 10ms, to reduce the jitter between runs")
+	timestamp   = flag.String("timestamp", "", "use `time` in RFC 3339 format, e.g. 2022-01-01T00:00:00Z, instead of the current time as the timestamp of the report, for reproducible reports")
+	wallTime    = flag.Duration("wall-duration", 0, "set the time of the testsuites element to the wall clock `duration` of the run instead of the sum of all testsuites")
+	emitIDs     = flag.Bool("emit-ids", false, "emit testsuite ids that are stable across runs")
+	outputSize  = flag.Bool("emit-output-size", false, "add output-bytes property with the output size of each package and test")
+	overhead    = flag.Bool("emit-overhead", false, "add overhead-seconds property with the time each package spent outside of its tests, such as in TestMain")
+	expectFile  = flag.String("expect", "", "add the tests listed in `file` as \"package test\" or \"package\" lines that don't appear in the report as failed tests")
+	missingMode = flag.String("missing", "", "report the -expect tests that don't appear in the report according to `mode`: fail (default) or skip")
+	testOrder   = flag.String("test-order", "", "order tests by the list of test names in `file`, such as the output of go test -list")
+	sortOrder   = flag.String("sort", "declaration", "set the `order` of packages and tests in the report: declaration, name, duration (longest first), failures-first")
+	benchBase   = flag.String("benchmark-baseline", "", "compare benchmarks to the go test log of a previous run in `file` and mark regressed benchmarks as failed")
+	benchThresh = flag.Float64("benchmark-threshold", 0.1, "mark benchmarks as failed when ns/op, B/op or allocs/op increased by more than `fraction` compared to the -benchmark-baseline")
+	coverProf   = flag.String("coverprofile", "", "read the coverage profile created by go test -coverprofile from `file` and use it for the coverage of each package")
+	coverFiles  = flag.Bool("coverage-per-file", false, "add the coverage of each file in the -coverprofile as a package property")
+	coberturaTo = flag.String("cobertura", "", "write a Cobertura XML coverage report to `file`, with file names relative to the module in the current directory; requires -coverprofile")
+	minCoverage = flag.Float64("min-coverage", 0, "add a failed test to packages whose coverage is below `percent`, unless set differently by the -coverage-thresholds")
+	coverThresh = flag.String("coverage-thresholds", "", "add a failed test to packages whose coverage is below the minimum of the first package pattern in `file` that matches them")
+	configFile  = flag.String("config", "", "read the coverage minimums, duration budgets and quarantine list of packages from the JSON `file`; by default "+config.DefaultName+" is read from the current directory or its nearest parent that contains one, use none to disable")
+	bundleFile  = flag.String("bundle", "", "also write an archive containing the report, the go test output and the files attached to tests to `file`, a .zip or .tar.gz")
+	reproCmds   = flag.String("repro-commands", "", "also write the go test command reproducing the failures of each package to `file`, using the -shuffle seed of shuffled packages")
+	rerunFails  = flag.String("rerun-fails-report", "", "also write the package and name of each failed test to `file`, in the format of the rerun fails report of gotestsum")
+	metricsFile = flag.String("metrics", "", "also write the results as Prometheus metrics for the node_exporter textfile collector to `file`")
+	notifyURL   = flag.String("notify-url", "", "post a summary of the results to the webhook at `url`, e.g. a Slack or Microsoft Teams incoming webhook; defaults to $GO_JUNIT_REPORT_NOTIFY_URL")
+	notifyFmt   = flag.String("notify-format", notify.FormatSlack, "set the `format` of the -notify-url message: slack, teams or json")
+	notifyTmpl  = flag.String("notify-template", "", "create the -notify-url request body using the Go text/template in `file` instead of -notify-format")
+	notifyTitle = flag.String("notify-title", notify.DefaultTitle, "set the `title` of the -notify-url message")
+	notifyLink  = flag.String("notify-artifacts-url", "", "link to the artifacts of the test run at `url` in the -notify-url message")
+	notifyOn    = flag.String("notify-on", "always", "post the -notify-url message `always` or only on `failure`")
+	otlpURL     = flag.String("otlp-endpoint", "", "also send the results as OpenTelemetry traces to the OTLP/HTTP collector at `url`, e.g. http://localhost:4318")
+	otlpService = flag.String("otlp-service-name", otlp.DefaultServiceName, "set the service.name of the traces sent to the -otlp-endpoint to `name`")
+	bkUpload    = flag.Bool("buildkite-upload", false, "also upload the results to Buildkite Test Analytics, using the test suite API token in $BUILDKITE_ANALYTICS_TOKEN")
+	bkURL       = flag.String("buildkite-url", buildkite.DefaultUploadURL, "set the `url` of the Test Analytics upload API used by -buildkite-upload")
+	allureDir   = flag.String("allure", "", "also write the results as Allure 2 result files to `dir`")
+	codeOwners  = flag.String("codeowners", "", "add owner properties to packages and tests based on the CODEOWNERS `file` and the source paths reported by go list")
+	locateTests = flag.Bool("locate-tests", false, "add the file and line of the test function of each test, found by parsing the source files of its package reported by go list, for file and line attributes and annotations")
+	rulesFile   = flag.String("rules", "", "classify the tests matching the JSON rules in `file` by changing their result or adding owner and label properties")
+	quarantine  = flag.String("quarantine", "", "report failures of the tests matching the patterns in `file` as skipped, recording their actual result in a quarantine.result property")
+	slowThresh  = flag.Duration("slow-threshold", 0, "mark tests that took longer than `duration` with a slow property")
+	failSlow    = flag.Bool("fail-slow", false, "mark tests that took longer than the -slow-threshold as failed")
+	failureBase = flag.String("failure-baseline", "", "report tests that also failed in the report of a previous run written with -format json in `file`, e.g. of the main branch, as skipped known failures, so that only new failures fail the report")
+	diffBase    = flag.String("diff-baseline", "", "compare the results to the report of a previous run written with -format json in `file`, e.g. of the main branch, and write the differences to the -diff-out file")
+	diffOut     = flag.String("diff-out", "", "write the differences to the -diff-baseline to `file`")
+	diffFormat  = flag.String("diff-format", "markdown", "set the `format` of the -diff-out file: markdown or json")
+	timingFile  = flag.String("timing", "", "update the durations of top-level tests in the timing data `file`, which can be used to split tests into shards with the timing package")
+	historyFile = flag.String("history", "", "add the results of this run to the history of previous runs in `file`")
+	historyID   = flag.String("history-id", "", "identify this run in the -history by `id`, such as a commit hash or build number; defaults to the current time")
+	historyMax  = flag.Int("history-max-runs", 100, "keep at most `n` runs in the -history; 0 means no limit")
+	maxDepth    = flag.Int("max-subtest-depth", 64, "cap the nesting level of subtests at `depth`; 0 means no limit")
+	testLines   = flag.Int("max-test-output-lines", 0, "truncate the output of each test to at most `n` lines; 0 means no limit")
+	testBytes   = flag.Int("max-test-output-bytes", 0, "truncate the output of each test to at most `n` bytes; 0 means no limit")
+	pkgLines    = flag.Int("max-package-output-lines", 0, "truncate the output of each package to at most `n` lines; 0 means no limit")
+	pkgBytes    = flag.Int("max-package-output-bytes", 0, "truncate the output of each package to at most `n` bytes; 0 means no limit")
+	dropPassed  = flag.Bool("drop-passed-output", false, "discard the output of tests that passed, which reduces memory usage for runs with many tests")
+	sanitize    = flag.Bool("sanitize-output", false, "remove ANSI escape codes, such as colors, and control characters that are invalid in XML from the output")
+	htmlColors  = flag.Bool("html-ansi-colors", false, "with -sanitize-output, keep ANSI color codes for -format html, which renders them as colors")
+	redact      = flag.Bool("redact-secrets", false, "replace common secrets in the output, such as passwords, tokens, authorization headers and the credentials in URLs, with [REDACTED]")
+	jsonLogs    = flag.Bool("format-json-logs", false, "rewrite JSON log lines in the output, such as those of log/slog or zap, as readable lines with their time, level, message and key=value fields")
+	minLogLevel = flag.String("min-log-level", "", "remove JSON log lines below `level` from the output, e.g. info to remove debug lines")
+	truncMode   = flag.String("truncate-mode", "tail", "set which part of truncated output to keep: tail, head or head-tail")
 	mode        = flag.String("subtest-mode", "", "set subtest `mode`: ignore-parent-results (subtest parents always pass), exclude-parents (subtest parents are excluded from the report)")
 
 	// debug flags
@@ -40,14 +189,127 @@ var (
 	goVersionFlag = flag.String("go-version", "", "(deprecated, use -prop) the value to use for the go.version property in the generated XML")
 )
 
+// clock returns the current time, or the time set by -timestamp.
+var clock = time.Now
+
 func main() {
 	flag.Var(&properties, "p", "add `key=value` property to generated report; repeat this flag to add multiple properties.")
+	flag.Var(&infraErrors, "infra-error-pattern", "treat output outside of tests matching `regexp` as an infrastructure error; repeat this flag to add multiple patterns.")
+	flag.Var(&envVars, "capture-env-var", "with -capture-env, also add environment variable `name` as an env.name property; repeat this flag to add multiple variables.")
+	flag.Var(&sonarPaths, "sonarqube-path", "map package or test `name=path` to its source path for -format sonarqube; repeat this flag to add multiple mappings.")
+	flag.Var(&lintFiles, "lint", "add the diagnostics in the go vet -json or staticcheck output in `file` as failing tests of a lint package; repeat this flag to add multiple files.")
+	flag.Var(&bazelFiles, "bazel", "merge the results in the Bazel test.xml `file`, or in all test.xml files in a directory such as bazel-testlogs; repeat this flag to add multiple files.")
+	flag.Var(&otlpHeaders, "otlp-header", "add header `key=value` to the requests sent to the -otlp-endpoint; repeat this flag to add multiple headers.")
+	flag.Var(&ownerRules, "owner", "add owners to the packages and tests whose source paths match `pattern=owner[,owner]`, taking precedence over -codeowners; repeat this flag to add multiple patterns.")
+	flag.Var(&redactPats, "redact-pattern", "replace the text in the output matching `regexp`, or only the text matching its groups, with [REDACTED]; repeat this flag to add multiple patterns.")
+	flag.Var(&redactEnv, "redact-env", "replace the value of the environment variables matching `name`, e.g. *_TOKEN, in the output with [REDACTED]; repeat this flag to add multiple names.")
+	flag.Var(&overrides, "override", "override the result of test `name:result` in the generated report; repeat this flag to override multiple tests.")
 	flag.Parse()
+	started := time.Now()
+
+	inFiles, err := resolveInput(*input, *inputPath, flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		flag.Usage()
+		exitf("")
+	}
+	outFile, err := resolveOutput(*output, *outputPath)
+	if err != nil {
+		exitf("%v", err)
+	}
+
+	if *watchMode && outFile == "" {
+		exitf("you must specify an output file with -out when using -watch")
+	}
+
+	if *watchMode && len(inFiles) > 1 {
+		exitf("-watch can't be used with more than one input file")
+	}
+
+	if *serveAddr != "" && (*watchMode || len(inFiles) > 0 || outFile != "" || *checkpoint != "") {
+		exitf("-serve can't be used with -watch, -checkpoint or input and output files")
+	}
+
+	if *serveDir != "" && *serveAddr == "" {
+		exitf("you must specify an address with -serve when using -serve-dir")
+	}
 
-	if *iocopy && *output == "" {
+	if *iocopy && len(inFiles) > 1 {
+		exitf("-iocopy can't be used with more than one input file")
+	}
+
+	if *iocopy && outFile == "" {
 		exitf("you must specify an output file with -out when using -iocopy")
 	}
 
+	if *coberturaTo != "" && *coverProf == "" {
+		exitf("you must specify a coverage profile with -coverprofile when using -cobertura")
+	}
+
+	if *diffBase != "" && *diffOut == "" {
+		exitf("you must specify an output file with -diff-out when using -diff-baseline")
+	}
+
+	if *diffFormat != "markdown" && *diffFormat != "json" {
+		exitf("invalid -diff-format: %s", *diffFormat)
+	}
+
+	switch *notifyFmt {
+	case notify.FormatSlack, notify.FormatTeams, notify.FormatJSON:
+	default:
+		exitf("invalid -notify-format: %s", *notifyFmt)
+	}
+
+	if *notifyOn != "always" && *notifyOn != "failure" {
+		exitf("invalid -notify-on: %s", *notifyOn)
+	}
+
+	var bundleFormat string
+	if *bundleFile != "" {
+		if *watchMode || *serveAddr != "" {
+			exitf("-bundle cannot be used with -watch or -serve\n")
+		}
+		var err error
+		if bundleFormat, err = bundle.FormatFromName(*bundleFile); err != nil {
+			exitf("invalid value for -bundle: %s\n", err)
+		}
+	}
+
+	if *notifyURL == "" {
+		*notifyURL = os.Getenv("GO_JUNIT_REPORT_NOTIFY_URL")
+	}
+
+	if *bkUpload && os.Getenv("BUILDKITE_ANALYTICS_TOKEN") == "" {
+		exitf("you must set $BUILDKITE_ANALYTICS_TOKEN when using -buildkite-upload")
+	}
+
+	if *failSlow && *slowThresh <= 0 {
+		exitf("you must specify a duration with -slow-threshold when using -fail-slow")
+	}
+
+	if *coverFiles && *coverProf == "" {
+		exitf("you must specify a coverage profile with -coverprofile when using -coverage-per-file")
+	}
+
+	color, err := useColor(*colorMode, outFile)
+	if err != nil {
+		exitf("invalid value for -color: %s\n", err)
+	}
+
+	var provenanceInfo *provenance.Info
+	if *provenanceF {
+		provenanceInfo = &provenance.Info{Tool: "go-junit-report " + Version}
+	}
+
+	if *tmplFile != "" {
+		if isFlagSet("format") && *format != "template" {
+			exitf("-template can only be used with -format template")
+		}
+		*format = "template"
+	} else if *format == "template" {
+		exitf("you must specify a template file with -template when using -format template")
+	}
+
 	if *version {
 		fmt.Printf("go-junit-report %s %s (%s)\n", Version, BuildTime, Revision)
 		return
@@ -58,6 +320,77 @@ func main() {
 		properties["go.version"] = *goVersionFlag
 	}
 
+	dialect, err := junit.ParseDialect(*dialectName)
+	if err != nil {
+		exitf("invalid value for -junit-dialect: %s\n", err)
+	}
+
+	dedup, err := gtr.ParseDedupStrategy(*duplicates)
+	if err != nil {
+		exitf("invalid value for -duplicates: %s\n", err)
+	}
+
+	noTestFilesMode, err := gtr.ParseNoTestFilesMode(*noTestFiles)
+	if err != nil {
+		exitf("invalid value for -no-test-files: %s\n", err)
+	}
+
+	cached, err := gtr.ParseCachedMode(*cachedMode)
+	if err != nil {
+		exitf("invalid value for -cached: %s\n", err)
+	}
+
+	subtestDurations, err := gtr.ParseSubtestDurationMode(*subtestDurs)
+	if err != nil {
+		exitf("invalid value for -subtest-durations: %s\n", err)
+	}
+
+	logLevel, err := gtr.ParseLogLevel(*minLogLevel)
+	if err != nil {
+		exitf("invalid value for -min-log-level: %s\n", err)
+	}
+
+	var redactions []*regexp.Regexp
+	if *redact {
+		redactions = gtr.DefaultSecretPatterns()
+	}
+	redactions = append(redactions, redactPats...)
+	redactions = append(redactions, gtr.EnvSecretPatterns(os.Environ(), redactEnv)...)
+
+	timeFormat := junit.TimeFormat{Unit: time.Second, Decimals: *timeDigits}
+	switch *timeUnit {
+	case "s":
+	case "ms":
+		timeFormat.Unit = time.Millisecond
+	default:
+		exitf("invalid value for -junit-time-unit: %s\n", *timeUnit)
+	}
+	if *timeDigits < 0 {
+		exitf("invalid value for -junit-time-decimals: %d\n", *timeDigits)
+	}
+
+	var reportTime func() time.Time
+	if *timestamp != "" {
+		t, err := time.Parse(time.RFC3339, *timestamp)
+		if err != nil {
+			exitf("invalid value for -timestamp: %s\n", err)
+		}
+		reportTime = func() time.Time { return t }
+		clock = reportTime
+	}
+
+	truncateMode, err := gtr.ParseTruncateMode(*truncMode)
+	if err != nil {
+		exitf("invalid value for -truncate-mode: %s\n", err)
+	}
+	limits := gtr.OutputLimits{
+		TestLines:    *testLines,
+		TestBytes:    *testBytes,
+		PackageLines: *pkgLines,
+		PackageBytes: *pkgBytes,
+		Mode:         truncateMode,
+	}
+
 	subtestMode := gotest.SubtestModeDefault
 	if *mode != "" {
 		var err error
@@ -66,16 +399,13 @@ func main() {
 		}
 	}
 
-	if flag.NArg() != 0 {
-		fmt.Fprintf(os.Stderr, "invalid argument(s): %s\n", strings.Join(flag.Args(), " "))
-		fmt.Fprintf(os.Stderr, "%s does not accept positional arguments\n", os.Args[0])
-		flag.Usage()
-		exitf("")
-	}
-
 	var in io.Reader = os.Stdin
-	if *input != "" {
-		f, err := os.Open(*input)
+	var concurrentInputs []string
+	if len(inFiles) > 1 {
+		// Multiple input files are parsed concurrently by Run.
+		concurrentInputs, in = inFiles, nil
+	} else if len(inFiles) == 1 {
+		f, err := os.Open(inFiles[0])
 		if err != nil {
 			exitf("error opening input file: %v", err)
 		}
@@ -83,38 +413,479 @@ func main() {
 		in = f
 	}
 
+	// When writing the report to a file in an interactive shell, the input
+	// is passed through to the terminal by default so the test output can
+	// still be followed live.
+	if outFile != "" && len(inFiles) <= 1 && !*watchMode && !isFlagSet("iocopy") && isTerminal(os.Stdout) {
+		*iocopy = true
+	}
+	if *iocopy {
+		in = io.TeeReader(in, os.Stdout)
+		if len(inFiles) == 0 {
+			// Pressing Ctrl-C interrupts go test as well, which prints its
+			// results before exiting. Ignore the interrupt, so that the
+			// report of the interrupted run is still written on EOF.
+			signal.Ignore(os.Interrupt)
+		}
+	}
+
+	// The go test output is kept for the bundle, unless it's read from
+	// multiple files, which are added to the bundle directly.
+	var rawLog *bytes.Buffer
+	if *bundleFile != "" && in != nil {
+		rawLog = &bytes.Buffer{}
+		in = io.TeeReader(in, rawLog)
+	}
+
+	missing, err := gtr.ParseMissingTestMode(*missingMode)
+	if err != nil {
+		exitf("invalid value for -missing: %s\n", err)
+	}
+
+	var expected []gtr.TestRef
+	if *expectFile != "" {
+		var err error
+		if expected, err = readExpectedTests(*expectFile); err != nil {
+			exitf("error reading expected tests: %v", err)
+		}
+	}
+
+	var order []string
+	if *testOrder != "" {
+		var err error
+		if order, err = readTestOrder(*testOrder); err != nil {
+			exitf("error reading test order: %v", err)
+		}
+	}
+
+	var baseline io.Reader
+	if *benchBase != "" {
+		f, err := os.Open(*benchBase)
+		if err != nil {
+			exitf("error opening benchmark baseline: %v", err)
+		}
+		defer f.Close()
+		baseline = f
+	}
+
+	var profile *coverage.Profile
+	if *coverProf != "" {
+		var err error
+		if profile, err = readCoverProfile(*coverProf); err != nil {
+			exitf("error reading coverage profile: %v", err)
+		}
+	}
+
+	var lintOutputs []io.Reader
+	for _, name := range lintFiles {
+		f, err := os.Open(name)
+		if err != nil {
+			exitf("error opening lint output: %v", err)
+		}
+		defer f.Close()
+		lintOutputs = append(lintOutputs, f)
+	}
+
+	var bazelOutputs []io.Reader
+	for _, name := range bazelFiles {
+		files, err := bazelTestXMLFiles(name)
+		if err != nil {
+			exitf("error finding bazel test.xml files: %v", err)
+		}
+		for _, file := range files {
+			f, err := os.Open(file)
+			if err != nil {
+				exitf("error opening bazel test.xml: %v", err)
+			}
+			defer f.Close()
+			bazelOutputs = append(bazelOutputs, f)
+		}
+	}
+
+	var owners codeowners.Ruleset
+	ownersRoot := "."
+	if *codeOwners != "" {
+		var err error
+		if owners, err = readCodeOwners(*codeOwners); err != nil {
+			exitf("error reading CODEOWNERS file: %v", err)
+		}
+		ownersRoot = codeowners.Root(*codeOwners)
+	}
+	for _, value := range ownerRules {
+		idx := strings.LastIndexByte(value, '=')
+		if idx == -1 {
+			exitf("-owner %v is not specified as \"pattern=owner\"", value)
+		}
+		rule, err := codeowners.NewRule(value[:idx], strings.Split(value[idx+1:], ",")...)
+		if err != nil {
+			exitf("invalid -owner %v: %v", value, err)
+		}
+		owners = append(owners, rule)
+	}
+
+	var template tmpl.Template
+	if *tmplFile != "" {
+		var err error
+		if template, err = tmpl.ParseFile(*tmplFile); err != nil {
+			exitf("error reading template: %v", err)
+		}
+	}
+
+	var modules []string
+	if *byModule {
+		var err error
+		if modules, err = listModules(); err != nil {
+			exitf("error finding modules: %v", err)
+		}
+	}
+	splitPackages := strings.Contains(outFile, "{package}")
+	splitModules := *byModule && strings.Contains(outFile, "{module}") && !splitPackages
+	if (splitModules || splitPackages) && *watchMode {
+		exitf("-out cannot contain {module} or {package} when using -watch")
+	}
+
+	var signingKey ed25519.PrivateKey
+	if *signKey != "" || *sigFile != "" {
+		if *signKey == "" || *sigFile == "" {
+			exitf("-sign-key and -signature must be used together")
+		}
+		data, err := ioutil.ReadFile(*signKey)
+		if err != nil {
+			exitf("error reading signing key: %v", err)
+		}
+		if signingKey, err = provenance.ParsePrivateKey(data); err != nil {
+			exitf("invalid value for -sign-key: %s", err)
+		}
+	}
+	if *digestFile != "" || *sigFile != "" {
+		if splitModules || splitPackages {
+			exitf("-digest and -signature cannot be used when -out contains {module} or {package}")
+		}
+		if *watchMode || *serveAddr != "" {
+			exitf("-digest and -signature cannot be used with -watch or -serve")
+		}
+	}
+
+	var rules []gtr.Rule
+	if *rulesFile != "" {
+		var err error
+		if rules, err = readRules(*rulesFile); err != nil {
+			exitf("error reading rules: %v", err)
+		}
+	}
+
+	var policy config.Policy
+	if name := *configFile; name != "none" {
+		if name == "" {
+			name = config.Find(".")
+		}
+		if name != "" {
+			var err error
+			if policy, err = config.Load(name); err != nil {
+				exitf("error reading config file %s: %v", name, err)
+			}
+		}
+	}
+
+	var quarantined []gtr.QuarantineRule
+	if *quarantine != "" {
+		var err error
+		if quarantined, err = readQuarantine(*quarantine); err != nil {
+			exitf("error reading quarantine list: %v", err)
+		}
+	}
+
+	var failureBaseline *gtr.Report
+	if *failureBase != "" {
+		baseline, err := readJSONReport(*failureBase)
+		if err != nil {
+			exitf("error reading failure baseline: %v", err)
+		}
+		failureBaseline = &baseline
+	}
+
+	coveragePolicy := gtr.CoveragePolicy{Minimum: *minCoverage}
+	if *coverThresh != "" {
+		var err error
+		if coveragePolicy.Thresholds, err = readCoverageThresholds(*coverThresh); err != nil {
+			exitf("error reading coverage thresholds: %v", err)
+		}
+	}
+	// The policies set by flags take precedence over those of the config
+	// file.
+	if coveragePolicy.Minimum == 0 {
+		coveragePolicy.Minimum = policy.Coverage.Minimum
+	}
+	coveragePolicy.Thresholds = append(coveragePolicy.Thresholds, policy.Coverage.Thresholds...)
+	quarantined = append(quarantined, policy.Quarantine...)
+
+	var progress io.Writer
+	if *showProg {
+		progress = os.Stderr
+	}
+
+	var checkpointFile *os.File
+	var warningsOut io.Writer
+	if *warnings || *failWarned {
+		warningsOut = os.Stderr
+	}
+
+	var problems io.Writer
+	if *validate || *failInvalid {
+		problems = os.Stderr
+	}
+
+	var checkpointOut io.Writer
+	if *checkpoint != "" {
+		if *watchMode {
+			exitf("-checkpoint cannot be used with -watch\n")
+		}
+		f, err := os.Create(*checkpoint)
+		if err != nil {
+			exitf("error creating checkpoint file: %v\n", err)
+		}
+		checkpointFile, checkpointOut = f, f
+	}
+
 	var out io.Writer = os.Stdout
-	if *output != "" {
-		f, err := os.Create(*output)
+	var reportFile sink.Writer
+	if splitModules || splitPackages {
+		out = ioutil.Discard
+	} else if outFile != "" && !*watchMode {
+		f, err := createOutput(outFile, gojunitreport.ContentType(*format))
 		if err != nil {
 			exitf("error creating output file: %v", err)
 		}
-		defer f.Close()
+		reportFile = f
 		out = f
 	}
-
-	if *iocopy {
-		in = io.TeeReader(in, os.Stdout)
+	var signed *provenance.Writer
+	if *digestFile != "" || *sigFile != "" {
+		signed = provenance.NewWriter(out, signingKey)
+		out = signed
 	}
 
-	hostname, _ := os.Hostname() // ignore error
+	hostname := *hostFlag
+	if hostname == "" {
+		hostname, _ = os.Hostname() // ignore error
+	}
+	shardIndex, shardCount, err := parseShard(*shardFlag)
+	if err != nil {
+		exitf("invalid value for -shard: %v\n", err)
+	}
+	var runMeta *gtr.RunMeta
+	if isFlagSet("exit-code") {
+		m := gtr.ExitStatus(*exitStatus)
+		runMeta = &m
+	}
 
 	config := gojunitreport.Config{
-		Parser:        *parser,
-		Hostname:      hostname,
-		PackageName:   *packageName,
-		SkipXMLHeader: *noXMLHeader,
-		SubtestMode:   subtestMode,
-		Properties:    properties,
-		PrintEvents:   *printEvents,
+		Parser:               *parser,
+		Format:               *format,
+		CaptureEnvironment:   *captureEnv,
+		EnvironmentVariables: envVars,
+		SonarQubePaths:       sonarqube.Mapping(sonarPaths),
+		Template:             template,
+		BenchfmtConfig:       *benchConfig,
+		Color:                color,
+		Provenance:           provenanceInfo,
+		Columns:              splitList(*columns),
+		Hostname:             hostname,
+		ShardIndex:           shardIndex,
+		ShardCount:           shardCount,
+		RunMeta:              runMeta,
+		PackageName:          *packageName,
+		SkipXMLHeader:        *noXMLHeader,
+		XMLStylesheet:        *stylesheet,
+		Dialect:              dialect,
+		StripModulePrefix:    *stripModule,
+		PackageSeparator:     *pkgSep,
+		PackageNameFormat:    *pkgFormat,
+		TestNamePrefix:       *testPrefix,
+		TimeFormat:           timeFormat,
+		DurationPrecision:    *precision,
+		TimestampFunc:        reportTime,
+		SubtestMode:          subtestMode,
+		Properties:           properties,
+		Overrides:            overrides,
+		Quarantine:           quarantined,
+		FailureBaseline:      failureBaseline,
+		Rules:                rules,
+		CodeOwners:           owners,
+		CodeOwnersRoot:       ownersRoot,
+		LocateTests:          *locateTests,
+		Failfast:             *failfast,
+		GroupAttempts:        *flaky,
+		Duplicates:           dedup,
+		NoTestFiles:          noTestFilesMode,
+		Cached:               cached,
+		SubtestDurations:     subtestDurations,
+		Sort:                 *sortOrder,
+		TestOrder:            order,
+		Modules:              modules,
+		MaxSubtestDepth:      *maxDepth,
+		OutputLimits:         limits,
+		DropPassedOutput:     *dropPassed,
+		SanitizeOutput:       *sanitize,
+		PreserveHTMLColors:   *htmlColors,
+		LogOutput:            gtr.LogOptions{Format: *jsonLogs, MinLevel: logLevel},
+		RedactPatterns:       redactions,
+		EmitOutputSize:       *outputSize,
+		EmitIDs:              *emitIDs,
+		EmitOverhead:         *overhead,
+		WallDuration:         *wallTime,
+		InfraErrorPatterns:   infraErrors,
+		BenchmarkBaseline:    baseline,
+		BenchmarkThreshold:   *benchThresh,
+		SlowThreshold:        *slowThresh,
+		FailSlowTests:        *failSlow,
+		CoverageProfile:      profile,
+		CoveragePerFile:      *coverFiles,
+		CoveragePolicy:       coveragePolicy,
+		DurationBudgets:      policy.DurationBudgets,
+		ExpectedTests:        expected,
+		MissingTests:         missing,
+		Lint:                 lintOutputs,
+		Bazel:                bazelOutputs,
+		BazelImportPrefix:    *bazelPrefix,
+		Progress:             progress,
+		Checkpoint:           checkpointOut,
+		Warnings:             warningsOut,
+		FailOnWarnings:       *failWarned,
+		Problems:             problems,
+		FailOnProblems:       *failInvalid,
+		InputFiles:           concurrentInputs,
+		Workers:              *workers,
+		PrintEvents:          *printEvents,
+	}
+	if *watchMode {
+		var watchFile string
+		if len(inFiles) == 1 {
+			watchFile = inFiles[0]
+		}
+		if err := runWatch(config, watchFile, outFile); err != nil {
+			exitf("error: %v\n", err)
+		}
+		return
 	}
+
+	if *serveAddr != "" {
+		if err := runServer(config, *serveAddr, *serveDir); err != nil {
+			exitf("error: %v\n", err)
+		}
+		return
+	}
+
 	report, err := config.Run(in, out)
 	if err != nil {
+		if reportFile != nil {
+			reportFile.Abort()
+		}
 		exitf("error: %v\n", err)
 	}
+	if checkpointFile != nil {
+		if err := checkpointFile.Close(); err != nil {
+			exitf("error writing checkpoint file: %v\n", err)
+		}
+	}
+	if reportFile != nil {
+		if err := reportFile.Commit(); err != nil {
+			exitf("error writing output file: %v\n", err)
+		}
+	}
+	if signed != nil {
+		if err := writeProvenance(signed, outFile, *digestFile, *sigFile); err != nil {
+			exitf("error writing provenance: %v", err)
+		}
+	}
+	if splitModules {
+		if err := writeModuleReports(config, *report, outFile); err != nil {
+			exitf("error writing output file: %v\n", err)
+		}
+	}
+	if splitPackages {
+		if err := writePackageReports(config, *report, outFile); err != nil {
+			exitf("error writing output file: %v\n", err)
+		}
+	}
+
+	if *coberturaTo != "" {
+		if err := writeCobertura(profile, *coberturaTo); err != nil {
+			exitf("error writing cobertura report: %v\n", err)
+		}
+	}
+
+	if *allureDir != "" {
+		if err := allure.CreateFromReport(*report).WriteDir(*allureDir); err != nil {
+			exitf("error writing allure results: %v\n", err)
+		}
+	}
+
+	if *metricsFile != "" {
+		if err := writeMetrics(*report, *metricsFile); err != nil {
+			exitf("error writing metrics: %v\n", err)
+		}
+	}
+
+	if *rerunFails != "" {
+		if err := writeRerunFails(*report, *rerunFails); err != nil {
+			exitf("error writing rerun fails report: %v\n", err)
+		}
+	}
+
+	if *reproCmds != "" {
+		if err := writeReproCommands(*report, *reproCmds); err != nil {
+			exitf("error writing repro commands: %v\n", err)
+		}
+	}
+
+	if *bundleFile != "" {
+		if err := writeBundle(config, *report, *bundleFile, bundleFormat, outFile, rawLog, inFiles); err != nil {
+			exitf("error writing bundle: %v\n", err)
+		}
+	}
+
+	if *otlpURL != "" {
+		if err := exportTraces(*report, started); err != nil {
+			exitf("error exporting traces: %v\n", err)
+		}
+	}
+
+	if *bkUpload {
+		if err := uploadBuildkite(*report); err != nil {
+			exitf("error uploading to buildkite test analytics: %v\n", err)
+		}
+	}
+
+	if *diffBase != "" {
+		if err := writeDiff(*report, *diffBase, *diffOut); err != nil {
+			exitf("error writing diff: %v\n", err)
+		}
+	}
+
+	if *notifyURL != "" && (*notifyOn != "failure" || !report.IsSuccessful()) {
+		// A failed notification shouldn't fail the conversion, or prevent
+		// the timing data and history from being updated.
+		if err := sendNotification(*report); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: error sending notification: %v\n", err)
+		}
+	}
+
+	if *timingFile != "" {
+		if err := updateTiming(*timingFile, *report); err != nil {
+			exitf("error updating timing data: %v\n", err)
+		}
+	}
+
+	if *historyFile != "" {
+		if err := addToHistory(*historyFile, *historyID, *report); err != nil {
+			exitf("error updating history: %v\n", err)
+		}
+	}
 
-	if *setExitCode && !report.IsSuccessful() {
-		os.Exit(1)
+	if *setExitCode {
+		policy := gtr.ExitPolicy{FailOnFlaky: *failFlaky, FailOnNoTests: *failNoTests}
+		os.Exit(report.Summary().ExitCode(policy))
 	}
 }
 
@@ -125,15 +896,788 @@ func exitf(msg string, args ...interface{}) {
 	os.Exit(2)
 }
 
-type keyValueFlag map[string]string
-
-func (f *keyValueFlag) String() string {
-	if f != nil {
-		var pairs []string
-		for k, v := range *f {
-			pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+// resolveInput returns the files to read the go test log from, or nil when it
+// should be read from stdin. The input is selected by the -in or -input flag if
+// set, otherwise by the positional arguments args if present, and otherwise
+// defaults to stdin. Positional arguments may be glob patterns, which are
+// expanded by parser.Glob. A path of "-" always means stdin and can't be
+// combined with other files. It's an error to select the input in more than
+// one way.
+func resolveInput(in, input string, args []string) ([]string, error) {
+	path, err := selectPath("-in", in, "-input", input)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > 0 {
+		if path != "" {
+			return nil, fmt.Errorf("input file %q conflicts with positional argument %q", path, args[0])
 		}
-		return strings.Join(pairs, ",")
+		if len(args) == 1 && args[0] == "-" {
+			return nil, nil
+		}
+		for _, arg := range args {
+			if arg == "-" {
+				return nil, fmt.Errorf("invalid argument(s): %s\nstdin (-) can't be combined with other input files", strings.Join(args, " "))
+			}
+		}
+		return gtrparser.Glob(args...)
+	}
+	if path == "" || path == "-" {
+		return nil, nil
+	}
+	return []string{path}, nil
+}
+
+// runWatch converts the output of the -watch-cmd, or of inFile if set, writes
+// the report to outFile, and repeats this whenever the files being watched
+// change until interrupted. When running the -watch-cmd, only the packages
+// in directories with changed Go files are tested again, and their results
+// replace those of the previous report. The -metrics, -allure and -diff-out
+// files are rewritten along with the report.
+func runWatch(config gojunitreport.Config, inFile, outFile string) error {
+	cmd := strings.Fields(*watchCmd)
+	if inFile == "" && len(cmd) == 0 {
+		return fmt.Errorf("-watch-cmd is empty")
+	}
+
+	convert := func(packages []string) error {
+		var in io.Reader
+		if inFile != "" {
+			data, err := ioutil.ReadFile(inFile)
+			if err != nil {
+				return err
+			}
+			in = bytes.NewReader(data)
+		} else {
+			var buf bytes.Buffer
+			c := exec.Command(cmd[0], append(cmd[1:], packages...)...)
+			c.Stdout, c.Stderr = &buf, &buf
+			if err := c.Run(); err != nil {
+				// go test exits with a non-zero status when tests fail.
+				if _, ok := err.(*exec.ExitError); !ok {
+					return err
+				}
+			}
+			config.RunMeta = processRunMeta(c.ProcessState)
+			in = &buf
+		}
+
+		f, err := createOutput(outFile, gojunitreport.ContentType(*format))
+		if err != nil {
+			return err
+		}
+		report, err := config.Run(in, f)
+		if err != nil {
+			f.Abort()
+			return err
+		}
+		if err := f.Commit(); err != nil {
+			return err
+		}
+		if inFile == "" {
+			config.Update = report
+		}
+
+		if *allureDir != "" {
+			if err := allure.CreateFromReport(*report).WriteDir(*allureDir); err != nil {
+				return fmt.Errorf("error writing allure results: %w", err)
+			}
+		}
+		if *metricsFile != "" {
+			if err := writeMetrics(*report, *metricsFile); err != nil {
+				return fmt.Errorf("error writing metrics: %w", err)
+			}
+		}
+		if *rerunFails != "" {
+			if err := writeRerunFails(*report, *rerunFails); err != nil {
+				return fmt.Errorf("error writing rerun fails report: %w", err)
+			}
+		}
+		if *reproCmds != "" {
+			if err := writeReproCommands(*report, *reproCmds); err != nil {
+				return fmt.Errorf("error writing repro commands: %w", err)
+			}
+		}
+		if *diffBase != "" {
+			if err := writeDiff(*report, *diffBase, *diffOut); err != nil {
+				return fmt.Errorf("error writing diff: %w", err)
+			}
+		}
+
+		s := report.Summary()
+		fmt.Fprintf(os.Stderr, "%s %d passed, %d failed, %d skipped, %d errors; wrote %s\n",
+			time.Now().Format("15:04:05"), s.Passed, s.Failed, s.Skipped, s.Errors, outFile)
+		return nil
+	}
+
+	w := watch.Watcher{Root: inFile, Interval: *watchEvery}
+	if inFile == "" {
+		w.Root, w.Match = ".", watch.GoFiles
+	}
+	if err := convert([]string{"./..."}); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	}
+
+	stop := make(chan struct{})
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		close(stop)
+	}()
+	return w.Watch(stop, func(changed []string) error {
+		// Errors are reported, but don't stop watching, since they are
+		// usually fixed by the next change.
+		if err := convert(watch.PackageDirs(changed)); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+		return nil
+	})
+}
+
+// runServer runs the HTTP server of package server on addr. The posted test
+// output is converted using config, with the parser set by the request, so
+// that options such as -p and -rules apply to the stored reports. Reports are
+// stored in dir, or in memory if dir is empty.
+func runServer(config gojunitreport.Config, addr, dir string) error {
+	srv := &server.Server{
+		Convert: func(r io.Reader, parserName string) (gtr.Report, error) {
+			if parserName == "gtrjson" {
+				return server.Convert(r, parserName)
+			}
+			c := config
+			c.Parser = parserName
+			report, err := c.Run(r, ioutil.Discard)
+			if err != nil {
+				return gtr.Report{}, err
+			}
+			return *report, nil
+		},
+	}
+	if dir != "" {
+		srv.Store = server.DirStore{Dir: dir}
+	}
+	fmt.Fprintf(os.Stderr, "serving reports on %s\n", addr)
+	return http.ListenAndServe(addr, srv)
+}
+
+// createOutput creates an output file with the given name, which is either a
+// file or a URL supported by package sink, such as s3://bucket/report.xml.
+// Like atomic files, the output is only written to its destination when it's
+// committed. The contentType is the media type of uploaded output.
+func createOutput(name, contentType string) (sink.Writer, error) {
+	if sink.IsURL(name) {
+		return sink.Create(name, contentType)
+	}
+	return createAtomic(name)
+}
+
+// atomicFile is a file that is written to a temporary file in the same
+// directory first, which replaces the actual file once it's complete. This
+// ensures the file is never left partially written, for example when the
+// input couldn't be parsed.
+type atomicFile struct {
+	*os.File
+	name string
+}
+
+// createAtomic creates a temporary file that will replace the file with the
+// given name when it's committed.
+func createAtomic(name string) (*atomicFile, error) {
+	f, err := ioutil.TempFile(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	// TempFile creates files that are only readable by the owner, while the
+	// file it replaces would have been created with the default permissions.
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &atomicFile{File: f, name: name}, nil
+}
+
+// Commit closes f and replaces the file it was created for.
+func (f *atomicFile) Commit() error {
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), f.name)
+}
+
+// Abort closes and removes f, leaving the file it was created for untouched.
+func (f *atomicFile) Abort() {
+	f.Close()
+	os.Remove(f.Name())
+}
+
+// isFlagSet returns true if the flag with the given name was set on the
+// command line.
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// isTerminal returns true if f refers to a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// useColor returns whether the report should be written with ANSI colors for
+// the given -color mode. In auto mode colors are used when the report is
+// written to a terminal, unless the NO_COLOR environment variable is set.
+func useColor(mode, outFile string) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto", "":
+		_, noColor := os.LookupEnv("NO_COLOR")
+		return outFile == "" && !noColor && isTerminal(os.Stdout), nil
+	}
+	return false, fmt.Errorf("unknown color mode: %s", mode)
+}
+
+// resolveOutput returns the file to write the report to, or an empty string
+// when it should be written to stdout. The output is selected by the -out or
+// -output flag and defaults to stdout. A path of "-" always means stdout.
+func resolveOutput(out, output string) (string, error) {
+	path, err := selectPath("-out", out, "-output", output)
+	if err != nil || path == "-" {
+		return "", err
+	}
+	return path, nil
+}
+
+// selectPath returns the value of whichever of the two equivalent flags was
+// set, or an error if both were set to different values.
+func selectPath(name1, value1, name2, value2 string) (string, error) {
+	if value1 != "" && value2 != "" && value1 != value2 {
+		return "", fmt.Errorf("%s %q conflicts with %s %q", name1, value1, name2, value2)
+	}
+	if value1 != "" {
+		return value1, nil
+	}
+	return value2, nil
+}
+
+// readTestOrder reads the list of test names in file, one per line. Lines
+// containing more than one field, such as the summary line printed by `go test
+// -list`, are ignored.
+func readTestOrder(file string) ([]string, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) == 1 {
+			names = append(names, fields[0])
+		}
+	}
+	return names, nil
+}
+
+// readJSONReport reads the report written with -format json in the given
+// file.
+func readJSONReport(file string) (gtr.Report, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return gtr.Report{}, err
+	}
+	defer f.Close()
+	return gtrjson.Read(f)
+}
+
+// readQuarantine reads the quarantine list in the given file.
+func readQuarantine(file string) ([]gtr.QuarantineRule, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return gtr.ParseQuarantine(f)
+}
+
+// readExpectedTests reads the manifest of expected tests in the given file.
+func readExpectedTests(file string) ([]gtr.TestRef, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return gtr.ParseExpectedTests(f)
+}
+
+// readCoverageThresholds reads the coverage thresholds in the given file.
+func readCoverageThresholds(file string) ([]gtr.CoverageThreshold, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return gtr.ParseCoverageThresholds(f)
+}
+
+// readCodeOwners reads the CODEOWNERS file with the given name.
+func readCodeOwners(file string) (codeowners.Ruleset, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return codeowners.Parse(f)
+}
+
+// readRules reads the classification rules in the given file.
+func readRules(file string) ([]gtr.Rule, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return gtr.ParseRules(f)
+}
+
+// readCoverProfile reads the coverage profile in the given file.
+func readCoverProfile(file string) (*coverage.Profile, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return coverage.Parse(f)
+}
+
+// writeCobertura writes coverage profile p as a Cobertura XML report to out,
+// a file or URL like the -out report. The file names in the report are made
+// relative to the module in the current directory, whose path is the
+// -strip-module-prefix or is read from its go.mod file.
+func writeCobertura(p *coverage.Profile, out string) error {
+	report := cobertura.CreateFromProfile(p, clock())
+	modulePath := *stripModule
+	if modulePath == "" {
+		modulePath = readModulePath("go.mod")
+	}
+	if dir, err := os.Getwd(); err == nil && modulePath != "" {
+		report.SetSource(dir, modulePath)
+	}
+
+	f, err := createOutput(out, "application/xml")
+	if err != nil {
+		return err
+	}
+	if err := report.WriteXML(f); err != nil {
+		f.Abort()
+		return err
+	}
+	return f.Commit()
+}
+
+// readModulePath returns the module path declared in the go.mod file with the
+// given name, or an empty string if it can't be read.
+func readModulePath(file string) string {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// listModules returns the paths of the main modules reported by go list -m,
+// which are all modules of the workspace when using a go.work file.
+func listModules() ([]string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", "list", "-m", "-f", "{{.Path}}")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// writeModuleReports writes the report of each module in report to the file
+// or URL out, in which {module} is replaced by the module path with its
+// slashes replaced by underscores, or by "other" for packages that don't
+// belong to any module.
+func writeModuleReports(config gojunitreport.Config, report gtr.Report, out string) error {
+	for _, mr := range gtr.SplitByModule(report) {
+		name := "other"
+		if mr.Module != "" {
+			name = strings.Replace(mr.Module, "/", "_", -1)
+		}
+		f, err := createOutput(strings.Replace(out, "{module}", name, -1), gojunitreport.ContentType(config.Format))
+		if err != nil {
+			return err
+		}
+		if err := config.Write(f, mr.Report); err != nil {
+			f.Abort()
+			return err
+		}
+		if err := f.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writePackageReports writes a report for each package in report to the file
+// or URL out, in which {package} is replaced by the package name with its
+// slashes replaced by underscores, and {module} by the module of the package
+// in the same way, see writeModuleReports. Packages without a name are
+// written to "other".
+func writePackageReports(config gojunitreport.Config, report gtr.Report, out string) error {
+	for _, mr := range gtr.SplitByModule(report) {
+		module := "other"
+		if mr.Module != "" {
+			module = strings.Replace(mr.Module, "/", "_", -1)
+		}
+		for _, pkg := range mr.Report.Packages {
+			name := "other"
+			if pkg.Name != "" {
+				name = strings.Replace(pkg.Name, "/", "_", -1)
+			}
+			path := strings.Replace(strings.Replace(out, "{package}", name, -1), "{module}", module, -1)
+			f, err := createOutput(path, gojunitreport.ContentType(config.Format))
+			if err != nil {
+				return err
+			}
+			pr := mr.Report
+			pr.Packages = []gtr.Package{pkg}
+			if err := config.Write(f, pr); err != nil {
+				f.Abort()
+				return err
+			}
+			if err := f.Commit(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeMetrics writes report as Prometheus metrics to file out. The file is
+// replaced atomically, so that the textfile collector never reads a partially
+// written file.
+func writeMetrics(report gtr.Report, out string) error {
+	f, err := createAtomic(out)
+	if err != nil {
+		return err
+	}
+	if err := metrics.Write(f, report); err != nil {
+		f.Abort()
+		return err
+	}
+	return f.Commit()
+}
+
+// writeProvenance writes the digest of the report written to w to file
+// digestOut and its signature to file sigOut, if they're not empty. The digest
+// refers to the report by the base name of outFile.
+func writeProvenance(w *provenance.Writer, outFile, digestOut, sigOut string) error {
+	if digestOut != "" {
+		name := "-"
+		if outFile != "" && outFile != "-" {
+			name = filepath.Base(outFile)
+		}
+		f, err := createAtomic(digestOut)
+		if err != nil {
+			return err
+		}
+		if err := provenance.WriteDigest(f, w.Digest(), name); err != nil {
+			f.Abort()
+			return err
+		}
+		if err := f.Commit(); err != nil {
+			return err
+		}
+	}
+	if sigOut != "" {
+		f, err := createAtomic(sigOut)
+		if err != nil {
+			return err
+		}
+		if err := provenance.WriteSignature(f, w.Signature()); err != nil {
+			f.Abort()
+			return err
+		}
+		return f.Commit()
+	}
+	return nil
+}
+
+// writeRerunFails writes the failed tests of report to file out in the format
+// of the rerun fails report of gotestsum, see rerun.WriteFailures.
+func writeRerunFails(report gtr.Report, out string) error {
+	f, err := createAtomic(out)
+	if err != nil {
+		return err
+	}
+	if err := rerun.WriteFailures(f, report); err != nil {
+		f.Abort()
+		return err
+	}
+	return f.Commit()
+}
+
+// writeReproCommands writes the go test commands reproducing the failures of
+// report to file out, see rerun.WriteCommands.
+func writeReproCommands(report gtr.Report, out string) error {
+	f, err := createAtomic(out)
+	if err != nil {
+		return err
+	}
+	if err := rerun.WriteCommands(f, report); err != nil {
+		f.Abort()
+		return err
+	}
+	return f.Commit()
+}
+
+// writeBundle writes an archive in the given format to file out, containing
+// report in the -format, the go test output and the files attached to tests.
+// The output is taken from rawLog if it was read from stdin or a single file,
+// or from the input files otherwise.
+func writeBundle(config gojunitreport.Config, report gtr.Report, out, format, outFile string, rawLog *bytes.Buffer, inFiles []string) error {
+	var buf bytes.Buffer
+	if err := config.Write(&buf, report); err != nil {
+		return err
+	}
+	b := bundle.Bundle{Report: report, Created: time.Now()}
+	b.Files = append(b.Files, bundle.File{Name: bundleReportName(config.Format, outFile), Kind: bundle.KindReport, Data: buf.Bytes()})
+	if rawLog != nil {
+		name := "go-test.log"
+		if len(inFiles) == 1 {
+			name = filepath.Base(inFiles[0])
+		}
+		b.Files = append(b.Files, bundle.File{Name: name, Kind: bundle.KindLog, Data: rawLog.Bytes()})
+	} else {
+		for _, name := range inFiles {
+			data, err := ioutil.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			b.Files = append(b.Files, bundle.File{Name: filepath.Base(name), Kind: bundle.KindLog, Data: data})
+		}
+	}
+
+	f, err := createAtomic(out)
+	if err != nil {
+		return err
+	}
+	if err := b.Write(f, format); err != nil {
+		f.Abort()
+		return err
+	}
+	return f.Commit()
+}
+
+// bundleReportName returns the name of the report in the bundle: the name of
+// the -out file if the report is written to a single file, or "report" with
+// the extension of the given format otherwise.
+func bundleReportName(format, outFile string) string {
+	if outFile != "" && !strings.Contains(outFile, "{") && !strings.Contains(outFile, "://") {
+		return filepath.Base(outFile)
+	}
+	switch ct := gojunitreport.ContentType(format); {
+	case ct == "application/xml":
+		return "report.xml"
+	case ct == "application/json":
+		return "report.json"
+	case strings.HasPrefix(ct, "text/html"):
+		return "report.html"
+	case strings.HasPrefix(ct, "text/markdown"):
+		return "report.md"
+	case strings.HasPrefix(ct, "text/csv"):
+		return "report.csv"
+	case strings.HasPrefix(ct, "text/tab-separated-values"):
+		return "report.tsv"
+	default:
+		return "report.txt"
+	}
+}
+
+// writeDiff compares report to the baseline report in file base and writes
+// the differences to file out in the -diff-format.
+func writeDiff(report gtr.Report, base, out string) error {
+	baseline, err := readJSONReport(base)
+	if err != nil {
+		return fmt.Errorf("error reading baseline: %w", err)
+	}
+	diff := gtr.Diff(baseline, report)
+
+	w, err := createAtomic(out)
+	if err != nil {
+		return err
+	}
+	if *diffFormat == "json" {
+		err = gtrjson.WriteDiff(w, diff)
+	} else {
+		err = markdown.WriteDiff(w, diff)
+	}
+	if err != nil {
+		w.Abort()
+		return err
+	}
+	return w.Commit()
+}
+
+// exportTraces sends report as OpenTelemetry traces to the -otlp-endpoint.
+// Packages without a start time are assumed to have started at start.
+func exportTraces(report gtr.Report, start time.Time) error {
+	traceID, err := otlp.NewTraceID()
+	if err != nil {
+		return err
+	}
+	traces := otlp.CreateFromReport(report, otlp.Options{ServiceName: *otlpService, TraceID: traceID, Start: start})
+	client := &http.Client{Timeout: 30 * time.Second}
+	return otlp.Export(client, *otlpURL, otlpHeaders, traces)
+}
+
+// uploadBuildkite uploads report to Buildkite Test Analytics at the
+// -buildkite-url.
+func uploadBuildkite(report gtr.Report) error {
+	payload, err := gojunitreport.BuildkitePayload(report)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	return buildkite.Upload(client, *bkURL, os.Getenv("BUILDKITE_ANALYTICS_TOKEN"), payload)
+}
+
+// sendNotification posts a summary of report to the -notify-url.
+func sendNotification(report gtr.Report) error {
+	n := notify.Notifier{
+		Client: &http.Client{Timeout: 30 * time.Second},
+		URL:    *notifyURL,
+		Format: *notifyFmt,
+	}
+	if *notifyTmpl != "" {
+		data, err := ioutil.ReadFile(*notifyTmpl)
+		if err != nil {
+			return err
+		}
+		if n.Template, err = notify.ParseTemplate(string(data)); err != nil {
+			return err
+		}
+	}
+	return n.Notify(notify.NewMessage(report, notify.Options{Title: *notifyTitle, ArtifactsURL: *notifyLink}))
+}
+
+// addToHistory adds the results in report to the history in file, identified
+// by id.
+func addToHistory(file, id string, report gtr.Report) error {
+	store, err := history.Load(file)
+	if err != nil {
+		return err
+	}
+	now := clock()
+	if id == "" {
+		id = now.UTC().Format(time.RFC3339)
+	}
+	store.Add(history.NewRun(id, now, report))
+	store.Prune(*historyMax)
+	return store.Save(file)
+}
+
+// updateTiming updates the timing data in file with the durations of the
+// tests in report. The file is created if it doesn't exist yet.
+func updateTiming(file string, report gtr.Report) error {
+	var data timing.Data
+	if f, err := os.Open(file); err == nil {
+		data, err = timing.ReadJSON(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	w, err := createAtomic(file)
+	if err != nil {
+		return err
+	}
+	if err := timing.Update(data, timing.FromReport(report)).WriteJSON(w); err != nil {
+		w.Abort()
+		return err
+	}
+	return w.Commit()
+}
+
+// processRunMeta returns the RunMeta of the exited process ps.
+func processRunMeta(ps *os.ProcessState) *gtr.RunMeta {
+	m := gtr.RunMeta{ExitCode: ps.ExitCode()}
+	if m.ExitCode == -1 && !ps.Exited() {
+		m.Signal = strings.TrimPrefix(ps.String(), "signal: ")
+	}
+	return &m
+}
+
+// parseShard parses the value of the -shard flag, which is either a one-based
+// index and the number of shards separated by a slash, or auto to use the
+// shard of the current CI job, if any. It returns the zero-based index.
+func parseShard(value string) (index, count int, err error) {
+	switch value {
+	case "":
+		return 0, 0, nil
+	case "auto":
+		index, count, _ = gtr.EnvironmentShard(os.Getenv)
+		return index, count, nil
+	}
+	i := strings.IndexByte(value, '/')
+	if i < 0 {
+		return 0, 0, fmt.Errorf("%q is not of the form index/count", value)
+	}
+	if index, err = strconv.Atoi(value[:i]); err != nil {
+		return 0, 0, err
+	}
+	if count, err = strconv.Atoi(value[i+1:]); err != nil {
+		return 0, 0, err
+	}
+	if count < 1 || index < 1 || index > count {
+		return 0, 0, fmt.Errorf("shard %d of %d does not exist", index, count)
+	}
+	return index - 1, count, nil
+}
+
+// splitList returns the comma separated elements of list, with surrounding
+// whitespace removed. Empty elements are left out.
+func splitList(list string) []string {
+	var elems []string
+	for _, elem := range strings.Split(list, ",") {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			elems = append(elems, elem)
+		}
+	}
+	return elems
+}
+
+type keyValueFlag map[string]string
+
+func (f *keyValueFlag) String() string {
+	if f != nil {
+		var pairs []string
+		for k, v := range *f {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+		}
+		return strings.Join(pairs, ",")
 	}
 	return ""
 }
@@ -147,3 +1691,88 @@ func (f *keyValueFlag) Set(value string) error {
 	(*f)[k] = v
 	return nil
 }
+
+type overrideFlag map[string]gtr.Result
+
+func (f *overrideFlag) String() string {
+	if f != nil {
+		var pairs []string
+		for name, result := range *f {
+			pairs = append(pairs, fmt.Sprintf("%s:%s", name, strings.ToLower(result.String())))
+		}
+		return strings.Join(pairs, ",")
+	}
+	return ""
+}
+
+func (f *overrideFlag) Set(value string) error {
+	idx := strings.LastIndexByte(value, ':')
+	if idx == -1 {
+		return fmt.Errorf("%v is not specified as \"name:result\"", value)
+	}
+	result, err := gtr.ParseResult(value[idx+1:])
+	if err != nil {
+		return err
+	}
+	(*f)[value[:idx]] = result
+	return nil
+}
+
+type regexpsFlag []*regexp.Regexp
+
+func (f *regexpsFlag) String() string {
+	if f != nil {
+		var patterns []string
+		for _, re := range *f {
+			patterns = append(patterns, re.String())
+		}
+		return strings.Join(patterns, ",")
+	}
+	return ""
+}
+
+func (f *regexpsFlag) Set(value string) error {
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, re)
+	return nil
+}
+
+// bazelTestXMLFiles returns name if it's a file, or the test.xml files in
+// directory name and its subdirectories otherwise, in lexical order.
+func bazelTestXMLFiles(name string) ([]string, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{name}, nil
+	}
+	var files []string
+	err = filepath.Walk(name, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && info.Name() == "test.xml" {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	if f != nil {
+		return strings.Join(*f, ",")
+	}
+	return ""
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/gojunitreport"
+)
+
+func TestResolveInput(t *testing.T) {
+	tests := []struct {
+		in, input string
+		args      []string
+		want      []string
+		wantErr   bool
+	}{
+		{want: nil},
+		{in: "-", want: nil},
+		{input: "-", want: nil},
+		{in: "a.txt", want: []string{"a.txt"}},
+		{input: "a.txt", want: []string{"a.txt"}},
+		{in: "a.txt", input: "a.txt", want: []string{"a.txt"}},
+		{in: "a.txt", input: "b.txt", wantErr: true},
+		{args: []string{"a.txt"}, want: []string{"a.txt"}},
+		{args: []string{"-"}, want: nil},
+		{in: "a.txt", args: []string{"b.txt"}, wantErr: true},
+		{input: "-", args: []string{"b.txt"}, wantErr: true},
+		{args: []string{"a.txt", "b.txt"}, want: []string{"a.txt", "b.txt"}},
+		{args: []string{"a.txt", "-"}, wantErr: true},
+		{args: []string{"testdata/no-such-file-*.txt"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := resolveInput(test.in, test.input, test.args)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("resolveInput(%q, %q, %q) did not return an error", test.in, test.input, test.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("resolveInput(%q, %q, %q) returned an unexpected error: %v", test.in, test.input, test.args, err)
+		} else if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("resolveInput(%q, %q, %q) incorrect, diff (-want +got):\n%s\n", test.in, test.input, test.args, diff)
+		}
+	}
+}
+
+func TestResolveOutput(t *testing.T) {
+	tests := []struct {
+		out, output string
+		want        string
+		wantErr     bool
+	}{
+		{want: ""},
+		{out: "-", want: ""},
+		{output: "-", want: ""},
+		{out: "report.xml", want: "report.xml"},
+		{output: "report.xml", want: "report.xml"},
+		{out: "report.xml", output: "report.xml", want: "report.xml"},
+		{out: "a.xml", output: "b.xml", wantErr: true},
+		{out: "-", output: "b.xml", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := resolveOutput(test.out, test.output)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("resolveOutput(%q, %q) did not return an error", test.out, test.output)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("resolveOutput(%q, %q) returned an unexpected error: %v", test.out, test.output, err)
+		} else if got != test.want {
+			t.Errorf("resolveOutput(%q, %q) = %q, want %q", test.out, test.output, got, test.want)
+		}
+	}
+}
+
+func TestParseShard(t *testing.T) {
+	tests := []struct {
+		value        string
+		index, count int
+		wantErr      bool
+	}{
+		{value: ""},
+		{value: "1/1", index: 0, count: 1},
+		{value: "3/4", index: 2, count: 4},
+		{value: "0/4", wantErr: true},
+		{value: "5/4", wantErr: true},
+		{value: "2", wantErr: true},
+		{value: "a/b", wantErr: true},
+	}
+
+	for _, test := range tests {
+		index, count, err := parseShard(test.value)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("parseShard(%q) did not return an error", test.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseShard(%q) returned an unexpected error: %v", test.value, err)
+		} else if index != test.index || count != test.count {
+			t.Errorf("parseShard(%q) = %d, %d, want %d, %d", test.value, index, count, test.index, test.count)
+		}
+	}
+}
+
+func TestReadTestOrder(t *testing.T) {
+	f, err := ioutil.TempFile("", "test-order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("TestB\nTestA\nExampleC\n\nok  \tpackage/name\t0.002s\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	got, err := readTestOrder(f.Name())
+	if err != nil {
+		t.Fatalf("readTestOrder returned an unexpected error: %v", err)
+	}
+	want := []string{"TestB", "TestA", "ExampleC"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("readTestOrder result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestAtomicFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atomic-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "report.xml")
+	if err := ioutil.WriteFile(name, []byte("previous"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := createAtomic(name)
+	if err != nil {
+		t.Fatalf("createAtomic returned an unexpected error: %v", err)
+	}
+	if _, err := f.WriteString("aborted"); err != nil {
+		t.Fatal(err)
+	}
+	f.Abort()
+	checkFileContents(t, dir, name, "previous")
+
+	if f, err = createAtomic(name); err != nil {
+		t.Fatalf("createAtomic returned an unexpected error: %v", err)
+	}
+	if _, err := f.WriteString("report"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Commit(); err != nil {
+		t.Fatalf("Commit returned an unexpected error: %v", err)
+	}
+	checkFileContents(t, dir, name, "report")
+}
+
+// checkFileContents checks that file name contains want and that it's the
+// only file in dir.
+func checkFileContents(t *testing.T, dir, name, want string) {
+	t.Helper()
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("file contains %q, want %q", data, want)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("directory contains %d files, want 1", len(files))
+	}
+}
+
+func TestProcessRunMeta(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	tests := []struct {
+		script string
+		want   gtr.RunMeta
+	}{
+		{"exit 0", gtr.RunMeta{ExitCode: 0}},
+		{"exit 3", gtr.RunMeta{ExitCode: 3}},
+		{"kill -KILL $$", gtr.RunMeta{ExitCode: -1, Signal: "killed"}},
+	}
+	for _, test := range tests {
+		c := exec.Command("sh", "-c", test.script)
+		c.Run() // ignore error, the exit status is checked below
+		if diff := cmp.Diff(&test.want, processRunMeta(c.ProcessState)); diff != "" {
+			t.Errorf("processRunMeta(%q) incorrect, diff (-want +got):\n%s\n", test.script, diff)
+		}
+	}
+}
+
+func TestReadModulePath(t *testing.T) {
+	if got, want := readModulePath("go.mod"), "github.com/jstemmer/go-junit-report/v2"; got != want {
+		t.Errorf("readModulePath(go.mod) = %q, want %q", got, want)
+	}
+	if got := readModulePath("missing.mod"); got != "" {
+		t.Errorf("readModulePath(missing.mod) = %q, want empty string", got)
+	}
+}
+
+func TestWriteModuleReports(t *testing.T) {
+	dir, err := ioutil.TempDir("", "module-reports")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	report := gtr.GroupByModule(gtr.Report{Packages: []gtr.Package{
+		{Name: "example.com/api/store", Tests: []gtr.Test{{Name: "TestA", Result: gtr.Pass}}},
+		{Name: "example.com/web", Tests: []gtr.Test{{Name: "TestB", Result: gtr.Fail}}},
+		{Name: "example.com/tools"},
+	}}, []string{"example.com/api", "example.com/web"})
+	config := gojunitreport.Config{Format: "rerun"}
+	if err := writeModuleReports(config, report, filepath.Join(dir, "{module}.txt")); err != nil {
+		t.Fatalf("writeModuleReports error: %v", err)
+	}
+	want := map[string]string{
+		"example.com_api.txt": "",
+		"example.com_web.txt": "example.com/web\t^TestB$\n",
+		"other.txt":           "",
+	}
+	got := make(map[string]string)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[f.Name()] = string(data)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("writeModuleReports files incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWritePackageReports(t *testing.T) {
+	dir, err := ioutil.TempDir("", "package-reports")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	report := gtr.GroupByModule(gtr.Report{Packages: []gtr.Package{
+		{Name: "example.com/api/store", Tests: []gtr.Test{{Name: "TestA", Result: gtr.Fail}}},
+		{Name: "example.com/web", Tests: []gtr.Test{{Name: "TestB", Result: gtr.Fail}}},
+		{Name: "example.com/api"},
+	}}, []string{"example.com/api"})
+	config := gojunitreport.Config{Format: "rerun"}
+	if err := writePackageReports(config, report, filepath.Join(dir, "{module}-{package}.txt")); err != nil {
+		t.Fatalf("writePackageReports error: %v", err)
+	}
+	want := map[string]string{
+		"example.com_api-example.com_api_store.txt": "example.com/api/store\t^TestA$\n",
+		"example.com_api-example.com_api.txt":       "",
+		"other-example.com_web.txt":                 "example.com/web\t^TestB$\n",
+	}
+	got := make(map[string]string)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[f.Name()] = string(data)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("writePackageReports files incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWriteBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	report := gtr.Report{Packages: []gtr.Package{{Name: "package/name", Tests: []gtr.Test{{Name: "TestA", Result: gtr.Fail}}}}}
+	config := gojunitreport.Config{Format: "rerun"}
+	out := filepath.Join(dir, "results.zip")
+	rawLog := bytes.NewBufferString("--- FAIL: TestA (0.00s)\n")
+	if err := writeBundle(config, report, out, "zip", "", rawLog, nil); err != nil {
+		t.Fatalf("writeBundle error: %v", err)
+	}
+
+	zr, err := zip.OpenReader(out)
+	if err != nil {
+		t.Fatalf("error opening bundle: %v", err)
+	}
+	defer zr.Close()
+	var got []string
+	for _, f := range zr.File {
+		got = append(got, f.Name)
+	}
+	want := []string{"report.txt", "go-test.log", "manifest.json"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("writeBundle files incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
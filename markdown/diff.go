@@ -0,0 +1,74 @@
+package markdown
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/escape"
+	"github.com/jstemmer/go-junit-report/v2/internal/timefmt"
+)
+
+// WriteDiff writes the Markdown summary of report diff d to writer w. The
+// summary starts with a table of the number of changes, followed by a table of
+// the tests whose result changed, a table of the tests whose duration changed
+// and lists of the added and removed tests.
+func WriteDiff(w io.Writer, d gtr.ReportDiff) error {
+	bw := bufio.NewWriter(w)
+
+	var regressed, fixed int
+	for _, c := range d.Results {
+		if c.Regressed() {
+			regressed++
+		} else if c.Fixed() {
+			fixed++
+		}
+	}
+	fmt.Fprintf(bw, "### Test report diff\n\n")
+	if d.Empty() {
+		fmt.Fprintf(bw, "No differences.\n")
+		return bw.Flush()
+	}
+	fmt.Fprintf(bw, "| Newly failing | Fixed | Result changes | Duration changes | Added | Removed |\n")
+	fmt.Fprintf(bw, "| ---: | ---: | ---: | ---: | ---: | ---: |\n")
+	fmt.Fprintf(bw, "| %d | %d | %d | %d | %d | %d |\n",
+		regressed, fixed, len(d.Results), len(d.Durations), len(d.Added), len(d.Removed))
+
+	if len(d.Results) > 0 {
+		fmt.Fprintf(bw, "\n#### Result changes\n\n")
+		fmt.Fprintf(bw, "| Test | Before | After |\n")
+		fmt.Fprintf(bw, "| --- | --- | --- |\n")
+		for _, c := range d.Results {
+			fmt.Fprintf(bw, "| %s | %s | %s |\n", escape.MarkdownCell(c.Package+"."+c.Test), c.Before, c.After)
+		}
+	}
+
+	if len(d.Durations) > 0 {
+		fmt.Fprintf(bw, "\n#### Duration changes\n\n")
+		fmt.Fprintf(bw, "| Test | Before | After | Change |\n")
+		fmt.Fprintf(bw, "| --- | ---: | ---: | ---: |\n")
+		for _, c := range d.Durations {
+			change := "n/a"
+			if c.Before > 0 {
+				change = fmt.Sprintf("%+.0f%%", 100*c.Ratio())
+			}
+			fmt.Fprintf(bw, "| %s | %s | %s | %s |\n", escape.MarkdownCell(c.Package+"."+c.Test),
+				timefmt.Readable(c.Before), timefmt.Readable(c.After), change)
+		}
+	}
+
+	writeRefs(bw, "Added tests", d.Added)
+	writeRefs(bw, "Removed tests", d.Removed)
+	return bw.Flush()
+}
+
+func writeRefs(w io.Writer, title string, refs []gtr.TestRef) {
+	if len(refs) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n#### %s\n\n", title)
+	for _, ref := range refs {
+		fmt.Fprintf(w, "- %s\n", escape.MarkdownCell(ref.Package+"."+ref.Test))
+	}
+}
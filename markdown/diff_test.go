@@ -0,0 +1,62 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteDiff(t *testing.T) {
+	d := gtr.ReportDiff{
+		Added:   []gtr.TestRef{{Package: "package/one", Test: "TestAdded"}},
+		Removed: []gtr.TestRef{{Package: "package/two", Test: "TestRemoved"}},
+		Results: []gtr.ResultChange{
+			{TestRef: gtr.TestRef{Package: "package/one", Test: "TestBroken"}, Before: gtr.Pass, After: gtr.Fail},
+			{TestRef: gtr.TestRef{Package: "package/one", Test: "TestSkipped"}, Before: gtr.Pass, After: gtr.Skip},
+		},
+		Durations: []gtr.DurationChange{
+			{TestRef: gtr.TestRef{Package: "package/one", Test: "TestSlower"}, Before: time.Second, After: 1500 * time.Millisecond},
+		},
+	}
+
+	want := "### Test report diff\n\n" +
+		"| Newly failing | Fixed | Result changes | Duration changes | Added | Removed |\n" +
+		"| ---: | ---: | ---: | ---: | ---: | ---: |\n" +
+		"| 1 | 0 | 2 | 1 | 1 | 1 |\n" +
+		"\n#### Result changes\n\n" +
+		"| Test | Before | After |\n" +
+		"| --- | --- | --- |\n" +
+		"| package/one.TestBroken | PASS | FAIL |\n" +
+		"| package/one.TestSkipped | PASS | SKIP |\n" +
+		"\n#### Duration changes\n\n" +
+		"| Test | Before | After | Change |\n" +
+		"| --- | ---: | ---: | ---: |\n" +
+		"| package/one.TestSlower | 1.000s | 1.500s | +50% |\n" +
+		"\n#### Added tests\n\n" +
+		"- package/one.TestAdded\n" +
+		"\n#### Removed tests\n\n" +
+		"- package/two.TestRemoved\n"
+
+	var buf bytes.Buffer
+	if err := WriteDiff(&buf, d); err != nil {
+		t.Fatalf("WriteDiff failed: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("WriteDiff output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWriteDiffEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDiff(&buf, gtr.ReportDiff{}); err != nil {
+		t.Fatalf("WriteDiff failed: %v", err)
+	}
+	want := "### Test report diff\n\nNo differences.\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("WriteDiff output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,181 @@
+// Package markdown writes a compact summary of a report in GitHub flavored
+// Markdown.
+//
+// The summary starts with a table containing the totals of the report,
+// followed by a table of the most common failure signatures when several tests
+// failed the same way, a collapsible section for each failed test, each file
+// with compiler errors and each other build or runtime error, and a table of
+// the slowest tests. It's meant to be posted as a pull request comment or
+// written to $GITHUB_STEP_SUMMARY, so the output of each failure is limited to
+// its last MaxOutputLines lines.
+package markdown
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/escape"
+	"github.com/jstemmer/go-junit-report/v2/internal/timefmt"
+)
+
+const (
+	// MaxOutputLines is the maximum number of output lines shown for each
+	// failure.
+	MaxOutputLines = 50
+
+	// SlowestTests is the maximum number of tests in the slowest tests table.
+	SlowestTests = 5
+
+	// TopSignatures is the maximum number of failure signatures in the top
+	// failure signatures table.
+	TopSignatures = 5
+)
+
+// Write writes the Markdown summary of report r to writer w.
+func Write(w io.Writer, r gtr.Report) error {
+	bw := bufio.NewWriter(w)
+	s := r.Summary()
+
+	status := "passed"
+	if s.Failed > 0 || s.Errors > 0 {
+		status = "failed"
+	}
+	fmt.Fprintf(bw, "### Test report: %s\n\n", status)
+	fmt.Fprintf(bw, "| Tests | Passed | Failed | Skipped | Flaky | Errors | Duration |\n")
+	fmt.Fprintf(bw, "| ---: | ---: | ---: | ---: | ---: | ---: | ---: |\n")
+	fmt.Fprintf(bw, "| %d | %d | %d | %d | %d | %d | %s |\n",
+		s.Tests, s.Passed, s.Failed, s.Skipped, s.Flaky, s.Errors, timefmt.Readable(s.Duration))
+	if s.Aborted {
+		fmt.Fprintf(bw, "\nThe run was aborted after the first failure")
+		if s.TimeToFirstFailure > 0 {
+			fmt.Fprintf(bw, " at %s", timefmt.Readable(s.TimeToFirstFailure))
+		}
+		fmt.Fprintf(bw, ", %d of the skipped tests did not run.\n", s.NotRun)
+	}
+
+	writeSignatures(bw, r)
+
+	var failures []failure
+	for _, pkg := range r.Packages {
+		if pkg.BuildError.Name != "" {
+			failures = append(failures, buildFailures(pkg.Name, pkg.BuildError)...)
+		}
+		if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			failures = append(failures, failure{pkg.Name + ": runtime error", pkg.RunError.Output})
+		}
+		for _, test := range pkg.Tests {
+			if test.Result.Base() == gtr.Fail || test.Result == gtr.Unknown {
+				failures = append(failures, failure{fmt.Sprintf("%s %s.%s", test.Result, pkg.Name, test.Name), test.Output})
+			}
+		}
+	}
+	if len(failures) > 0 {
+		fmt.Fprintf(bw, "\n#### Failures\n\n")
+		for _, f := range failures {
+			f.write(bw)
+		}
+	}
+
+	slowest := r.Slowest(SlowestTests)
+	for len(slowest) > 0 && slowest[len(slowest)-1].Duration <= 0 {
+		slowest = slowest[:len(slowest)-1]
+	}
+	if len(slowest) > 0 {
+		fmt.Fprintf(bw, "\n#### Slowest tests\n\n")
+		fmt.Fprintf(bw, "| Test | Duration |\n")
+		fmt.Fprintf(bw, "| --- | ---: |\n")
+		for _, td := range slowest {
+			fmt.Fprintf(bw, "| %s | %s |\n", escape.MarkdownCell(td.Package+"."+td.Test), timefmt.Readable(td.Duration))
+		}
+	}
+	return bw.Flush()
+}
+
+// writeSignatures writes a table of the TopSignatures failure signatures shared
+// by the most failed tests, see gtr.Report.FailureClusters. Signatures of a
+// single test and of tests without output are left out, so nothing is written
+// unless at least two tests failed with the same output.
+func writeSignatures(w io.Writer, r gtr.Report) {
+	var top []gtr.FailureCluster
+	for _, c := range r.FailureClusters() {
+		if len(c.Tests) < 2 || len(top) == TopSignatures {
+			break
+		}
+		if c.Summary() != "" {
+			top = append(top, c)
+		}
+	}
+	if len(top) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n#### Top failure signatures\n\n")
+	fmt.Fprintf(w, "| Tests | Signature | First test |\n")
+	fmt.Fprintf(w, "| ---: | --- | --- |\n")
+	for _, c := range top {
+		first := c.Tests[0]
+		fmt.Fprintf(w, "| %d | %s | %s |\n", len(c.Tests), escape.MarkdownCell(c.Summary()), escape.MarkdownCell(first.Package+"."+first.Test))
+	}
+}
+
+// buildFailures returns a failure for each file that compiler diagnostics of
+// build error e refer to, or a single failure containing the output of e if
+// it doesn't have any diagnostics.
+func buildFailures(pkgName string, e gtr.Error) []failure {
+	files, byFile := e.DiagnosticsByFile()
+	if len(files) == 0 {
+		return []failure{{pkgName + ": build error", e.Output}}
+	}
+	var failures []failure
+	for _, file := range files {
+		f := failure{title: pkgName + ": build error in " + file}
+		for _, d := range byFile[file] {
+			f.output = append(f.output, d.String())
+		}
+		failures = append(failures, f)
+	}
+	return failures
+}
+
+// failure is a failed test or a build or runtime error of a package.
+type failure struct {
+	title  string
+	output []string
+}
+
+// write writes f as a collapsible section containing the last MaxOutputLines
+// lines of its output in a code block.
+func (f failure) write(w io.Writer) {
+	fmt.Fprintf(w, "<details>\n<summary>%s</summary>\n\n", escape.HTML(f.title))
+	output := f.output
+	if omitted := len(output) - MaxOutputLines; omitted > 0 {
+		fmt.Fprintf(w, "%d earlier lines omitted.\n\n", omitted)
+		output = output[omitted:]
+	}
+	if len(output) > 0 {
+		fence := codeFence(output)
+		fmt.Fprintf(w, "%s\n%s\n%s\n\n", fence, strings.Join(output, "\n"), fence)
+	}
+	fmt.Fprintf(w, "</details>\n")
+}
+
+// codeFence returns a code fence that is longer than any sequence of
+// backticks in lines, so the code block can't be terminated early.
+func codeFence(lines []string) string {
+	n := 3
+	for _, line := range lines {
+		run := 0
+		for _, c := range line {
+			if c != '`' {
+				run = 0
+				continue
+			}
+			if run++; run >= n {
+				n = run + 1
+			}
+		}
+	}
+	return strings.Repeat("`", n)
+}
@@ -0,0 +1,168 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/gtrtest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrite(t *testing.T) {
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{
+				Name:     "package/one",
+				Duration: 1500 * time.Millisecond,
+				Tests: []gtr.Test{
+					{Name: "TestPass", Result: gtr.Pass, Duration: 10 * time.Millisecond},
+					{Name: "TestFail", Result: gtr.Fail, Duration: 20 * time.Millisecond, Output: []string{"fail_test.go:10: got <nil>"}},
+					{Name: "TestSkip", Result: gtr.Skip},
+				},
+			},
+			{
+				Name:       "package/two",
+				BuildError: gtr.Error{Name: "package/two", Output: []string{"undefined: x"}},
+			},
+		},
+	}
+
+	want := "### Test report: failed\n" +
+		"\n" +
+		"| Tests | Passed | Failed | Skipped | Flaky | Errors | Duration |\n" +
+		"| ---: | ---: | ---: | ---: | ---: | ---: | ---: |\n" +
+		"| 3 | 1 | 1 | 1 | 0 | 1 | 1.500s |\n" +
+		"\n" +
+		"#### Failures\n" +
+		"\n" +
+		"<details>\n<summary>FAIL package/one.TestFail</summary>\n\n" +
+		"```\nfail_test.go:10: got <nil>\n```\n\n" +
+		"</details>\n" +
+		"<details>\n<summary>package/two: build error</summary>\n\n" +
+		"```\nundefined: x\n```\n\n" +
+		"</details>\n" +
+		"\n" +
+		"#### Slowest tests\n" +
+		"\n" +
+		"| Test | Duration |\n" +
+		"| --- | ---: |\n" +
+		"| package/one.TestFail | 0.020s |\n" +
+		"| package/one.TestPass | 0.010s |\n"
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Write output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWritePassed(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{{Name: "package/one", Tests: []gtr.Test{{Name: "TestPass", Result: gtr.Pass}}}}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	got := buf.String()
+	if !strings.HasPrefix(got, "### Test report: passed\n") {
+		t.Errorf("Write output has incorrect heading, got:\n%s", got)
+	}
+	if strings.Contains(got, "####") {
+		t.Errorf("Write output contains sections for a passing report without durations, got:\n%s", got)
+	}
+}
+
+func TestWriteLongOutput(t *testing.T) {
+	var output []string
+	for i := 0; i < MaxOutputLines+5; i++ {
+		output = append(output, fmt.Sprintf("line %d", i))
+	}
+	output[len(output)-1] = "```go"
+	report := gtr.Report{Packages: []gtr.Package{{Name: "package/one", Tests: []gtr.Test{{Name: "Test<T>", Result: gtr.Fail, Output: output}}}}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"<summary>FAIL package/one.Test&lt;T&gt;</summary>",
+		"5 earlier lines omitted.\n\n````\nline 5\n",
+		"\n```go\n````\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Write output does not contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "line 4\n") {
+		t.Errorf("Write output contains omitted line, got:\n%s", got)
+	}
+}
+
+func TestWriteSignatures(t *testing.T) {
+	refused := func(port int) []string {
+		return []string{fmt.Sprintf("    db_test.go:%d: dial tcp: connection refused at 0xc00001%04d", port, port)}
+	}
+	report := gtr.Report{Packages: []gtr.Package{
+		{Name: "package/one", Tests: []gtr.Test{
+			{Name: "TestA", Result: gtr.Fail, Output: refused(1)},
+			{Name: "TestB", Result: gtr.Fail, Output: refused(2)},
+			{Name: "TestC", Result: gtr.Fail, Output: []string{"c_test.go:1: unexpected value"}},
+		}},
+		{Name: "package/two", Tests: []gtr.Test{
+			{Name: "TestD", Result: gtr.Fail, Output: refused(3)},
+		}},
+	}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := "\n#### Top failure signatures\n\n" +
+		"| Tests | Signature | First test |\n" +
+		"| ---: | --- | --- |\n" +
+		"| 3 | db\\_test.go:?: dial tcp: connection refused at 0x? | package/one.TestA |\n" +
+		"\n#### Failures\n"
+	if got := buf.String(); !strings.Contains(got, want) {
+		t.Errorf("Write output does not contain %q, got:\n%s", want, got)
+	}
+}
+
+func TestWriteDiagnostics(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{{
+		Name: "package/one",
+		BuildError: gtr.Error{
+			Name: "package/one",
+			Diagnostics: []gtr.Diagnostic{
+				{File: "a.go", Line: 1, Message: "undefined: x"},
+				{File: "b.go", Line: 2, Message: "undefined: y"},
+				{File: "a.go", Line: 3, Message: "undefined: z"},
+			},
+		},
+	}}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"<summary>package/one: build error in a.go</summary>\n\n```\na.go:1: undefined: x\na.go:3: undefined: z\n```\n",
+		"<summary>package/one: build error in b.go</summary>\n\n```\nb.go:2: undefined: y\n```\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Write output does not contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestWriteGolden(t *testing.T) {
+	gtrtest.RunGolden(t, "testdata", Write)
+}
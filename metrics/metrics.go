@@ -0,0 +1,96 @@
+// Package metrics writes a report as Prometheus metrics in the text
+// exposition format, as read by the textfile collector of node_exporter.
+//
+// The following metrics are written for each package in the report:
+//
+//	test_total{package="...",result="..."}           number of tests by result
+//	test_duration_seconds{package="...",test="..."}  duration of each test
+//	package_duration_seconds{package="..."}          duration of the package
+//	package_coverage_percent{package="..."}          statement coverage
+//
+// The result label is one of pass, fail, skip, flaky and unknown, and all
+// results are written for every package so that series don't disappear when a
+// count drops to zero. Coverage is only written for packages that reported it.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// results lists the results in the order in which they're written for the
+// test_total metric. Tests are counted by their base result.
+var results = []gtr.Result{gtr.Pass, gtr.Fail, gtr.Skip, gtr.Flaky, gtr.Unknown}
+
+// Write writes the metrics for report r to w.
+func Write(w io.Writer, r gtr.Report) error {
+	bw := bufio.NewWriter(w)
+
+	header(bw, "test_total", "gauge", "Number of tests by result.")
+	for _, pkg := range r.Packages {
+		counts := make(map[gtr.Result]int)
+		for _, t := range pkg.Tests {
+			counts[t.Result.Base()]++
+		}
+		for _, result := range results {
+			sample(bw, "test_total", float64(counts[result]), "package", pkg.Name, "result", strings.ToLower(result.String()))
+		}
+	}
+
+	header(bw, "test_duration_seconds", "gauge", "Duration of each test in seconds.")
+	for _, pkg := range r.Packages {
+		for _, t := range pkg.Tests {
+			sample(bw, "test_duration_seconds", t.Duration.Seconds(), "package", pkg.Name, "test", t.Name)
+		}
+	}
+
+	header(bw, "package_duration_seconds", "gauge", "Duration of each package in seconds.")
+	for _, pkg := range r.Packages {
+		sample(bw, "package_duration_seconds", pkg.Duration.Seconds(), "package", pkg.Name)
+	}
+
+	header(bw, "package_coverage_percent", "gauge", "Statement coverage of each package in percent.")
+	for _, pkg := range r.Packages {
+		if pkg.Coverage > 0 {
+			sample(bw, "package_coverage_percent", pkg.Coverage, "package", pkg.Name)
+		}
+	}
+
+	return bw.Flush()
+}
+
+func header(w *bufio.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes a sample of metric name with the given value and label name
+// and value pairs.
+func sample(w *bufio.Writer, name string, value float64, labels ...string) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", labels[i], escapeLabel(labels[i+1]))
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	w.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes the backslashes, double quotes and newlines in label
+// value v.
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrite(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{
+		{
+			Name:     "package/one",
+			Duration: 1500 * time.Millisecond,
+			Coverage: 72.5,
+			Tests: []gtr.Test{
+				{Name: "TestOne", Result: gtr.Pass, Duration: 10 * time.Millisecond},
+				{Name: "TestTwo", Result: gtr.Fail, Duration: 2 * time.Second},
+				{Name: `Test/"quoted"\path`, Result: gtr.Pass},
+			},
+		},
+		{
+			Name:  "package/two",
+			Tests: []gtr.Test{{Name: "TestThree", Result: gtr.Skip}},
+		},
+	}}
+
+	want := `# HELP test_total Number of tests by result.
+# TYPE test_total gauge
+test_total{package="package/one",result="pass"} 2
+test_total{package="package/one",result="fail"} 1
+test_total{package="package/one",result="skip"} 0
+test_total{package="package/one",result="flaky"} 0
+test_total{package="package/one",result="unknown"} 0
+test_total{package="package/two",result="pass"} 0
+test_total{package="package/two",result="fail"} 0
+test_total{package="package/two",result="skip"} 1
+test_total{package="package/two",result="flaky"} 0
+test_total{package="package/two",result="unknown"} 0
+# HELP test_duration_seconds Duration of each test in seconds.
+# TYPE test_duration_seconds gauge
+test_duration_seconds{package="package/one",test="TestOne"} 0.01
+test_duration_seconds{package="package/one",test="TestTwo"} 2
+test_duration_seconds{package="package/one",test="Test/\"quoted\"\\path"} 0
+test_duration_seconds{package="package/two",test="TestThree"} 0
+# HELP package_duration_seconds Duration of each package in seconds.
+# TYPE package_duration_seconds gauge
+package_duration_seconds{package="package/one"} 1.5
+package_duration_seconds{package="package/two"} 0
+# HELP package_coverage_percent Statement coverage of each package in percent.
+# TYPE package_coverage_percent gauge
+package_coverage_percent{package="package/one"} 72.5
+`
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Write output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// slackMessage returns the Slack incoming webhook payload for message m,
+// using Slack's mrkdwn formatting.
+func slackMessage(m Message) interface{} {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s %s*\n%s\n", slackEscape(m.Title), m.Status(), slackEscape(m.Totals()))
+	writeList := func(heading string, list []Failure, more int) {
+		if len(list) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n*%s*\n", heading)
+		for _, f := range list {
+			fmt.Fprintf(&b, "• `%s`", slackEscape(f.Name()))
+			if f.Message != "" {
+				fmt.Fprintf(&b, ": %s", slackEscape(firstLine(f.Message)))
+			}
+			b.WriteString("\n")
+		}
+		if more > 0 {
+			fmt.Fprintf(&b, "_and %d more_\n", more)
+		}
+	}
+	writeList("Failures", m.Failures, m.MoreFailures)
+	writeList("Flaky tests", m.Flaky, m.MoreFlaky)
+	if m.ArtifactsURL != "" {
+		fmt.Fprintf(&b, "\n<%s|Artifacts>\n", slackEscape(m.ArtifactsURL))
+	}
+	return struct {
+		Text string `json:"text"`
+	}{strings.TrimSuffix(b.String(), "\n")}
+}
+
+// slackEscape escapes the characters that have a special meaning in Slack
+// messages.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// teamsMessage returns the Microsoft Teams webhook payload for message m,
+// containing an Adaptive Card.
+func teamsMessage(m Message) interface{} {
+	type element map[string]interface{}
+	color := "good"
+	if !m.Successful {
+		color = "attention"
+	}
+	body := []element{
+		{"type": "TextBlock", "text": m.Title + " " + m.Status(), "weight": "bolder", "size": "medium", "color": color, "wrap": true},
+		{"type": "TextBlock", "text": m.Totals(), "wrap": true},
+	}
+	addList := func(heading string, list []Failure, more int) {
+		if len(list) == 0 {
+			return
+		}
+		var b strings.Builder
+		for _, f := range list {
+			fmt.Fprintf(&b, "- %s", f.Name())
+			if f.Message != "" {
+				fmt.Fprintf(&b, ": %s", firstLine(f.Message))
+			}
+			b.WriteString("\n")
+		}
+		if more > 0 {
+			fmt.Fprintf(&b, "- and %d more\n", more)
+		}
+		body = append(body,
+			element{"type": "TextBlock", "text": heading, "weight": "bolder", "spacing": "medium"},
+			element{"type": "TextBlock", "text": strings.TrimSuffix(b.String(), "\n"), "wrap": true},
+		)
+	}
+	addList("Failures", m.Failures, m.MoreFailures)
+	addList("Flaky tests", m.Flaky, m.MoreFlaky)
+
+	card := element{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if m.ArtifactsURL != "" {
+		card["actions"] = []element{{"type": "Action.OpenUrl", "title": "Artifacts", "url": m.ArtifactsURL}}
+	}
+	return element{
+		"type": "message",
+		"attachments": []element{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     card,
+		}},
+	}
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+		return s[:idx]
+	}
+	return s
+}
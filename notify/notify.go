@@ -0,0 +1,283 @@
+// Package notify posts a summary of a report to a chat or webhook service,
+// such as Slack or Microsoft Teams.
+//
+// A report is first summarized as a Message, containing its totals, failures
+// and flaky tests. The message is then formatted for the service receiving it,
+// either using one of the built-in formats or a custom text/template, and
+// posted to the webhook URL of that service, see Notifier.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// DefaultTitle is the title of messages, unless another title is given in
+// Options.
+const DefaultTitle = "go test"
+
+// DefaultMaxFailures is the maximum number of failures and flaky tests listed
+// in a message, unless another maximum is given in Options.
+const DefaultMaxFailures = 10
+
+// Formats of the notifications sent by a Notifier.
+const (
+	FormatSlack = "slack" // Slack incoming webhook message
+	FormatTeams = "teams" // Microsoft Teams message with an Adaptive Card
+	FormatJSON  = "json"  // the Message as JSON, for generic webhooks
+)
+
+// Options configures how a report is summarized in a Message.
+type Options struct {
+	// Title is the title of the message, DefaultTitle if empty.
+	Title string
+
+	// ArtifactsURL is an optional link to the artifacts of the test run,
+	// such as the full report.
+	ArtifactsURL string
+
+	// MaxFailures is the maximum number of failures and flaky tests each
+	// listed in the message, DefaultMaxFailures if 0.
+	MaxFailures int
+}
+
+// Message is the summary of a report that is sent as a notification. It's
+// also the data passed to custom templates.
+type Message struct {
+	Title        string    `json:"title"`
+	Successful   bool      `json:"successful"`
+	Summary      Summary   `json:"summary"`
+	Failures     []Failure `json:"failures,omitempty"`
+	MoreFailures int       `json:"more_failures,omitempty"` // failures not listed in Failures
+	Flaky        []Failure `json:"flaky,omitempty"`
+	MoreFlaky    int       `json:"more_flaky,omitempty"` // flaky tests not listed in Flaky
+	ArtifactsURL string    `json:"artifacts_url,omitempty"`
+}
+
+// Summary contains the totals of a report.
+type Summary struct {
+	Tests    int           `json:"tests"`
+	Passed   int           `json:"passed"`
+	Failed   int           `json:"failed"`
+	Skipped  int           `json:"skipped"`
+	Flaky    int           `json:"flaky"`
+	Errors   int           `json:"errors"`
+	Duration time.Duration `json:"duration_nanos"`
+}
+
+// Failure is a failed test, or a package that failed to build or run when
+// Test is empty.
+type Failure struct {
+	Package string `json:"package"`
+	Test    string `json:"test,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Name returns the name of failure f, which is its package and test name.
+func (f Failure) Name() string {
+	if f.Test == "" {
+		return f.Package
+	}
+	return f.Package + "." + f.Test
+}
+
+// NewMessage returns the message summarizing report r.
+func NewMessage(r gtr.Report, opts Options) Message {
+	if opts.Title == "" {
+		opts.Title = DefaultTitle
+	}
+	if opts.MaxFailures <= 0 {
+		opts.MaxFailures = DefaultMaxFailures
+	}
+
+	s := r.Summary()
+	m := Message{
+		Title:      opts.Title,
+		Successful: r.IsSuccessful(),
+		Summary: Summary{
+			Tests:    s.Tests,
+			Passed:   s.Passed,
+			Failed:   s.Failed,
+			Skipped:  s.Skipped,
+			Flaky:    s.Flaky,
+			Errors:   s.Errors,
+			Duration: s.Duration,
+		},
+		ArtifactsURL: opts.ArtifactsURL,
+	}
+	addFailure := func(f Failure) {
+		if len(m.Failures) < opts.MaxFailures {
+			m.Failures = append(m.Failures, f)
+		} else {
+			m.MoreFailures++
+		}
+	}
+	for _, pkg := range r.Packages {
+		if pkg.BuildError.Name != "" {
+			addFailure(Failure{Package: pkg.Name, Message: errorMessage(pkg.BuildError, "build failed")})
+		}
+		if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			addFailure(Failure{Package: pkg.Name, Message: errorMessage(pkg.RunError, "run failed")})
+		}
+		for _, test := range pkg.Tests {
+			switch test.Result.Base() {
+			case gtr.Fail, gtr.Unknown:
+				msg := test.FailureMessage
+				if msg == "" && test.Result == gtr.Unknown {
+					msg = "no result"
+				}
+				addFailure(Failure{Package: pkg.Name, Test: test.Name, Message: msg})
+			case gtr.Flaky:
+				if len(m.Flaky) < opts.MaxFailures {
+					m.Flaky = append(m.Flaky, Failure{Package: pkg.Name, Test: test.Name})
+				} else {
+					m.MoreFlaky++
+				}
+			}
+		}
+	}
+	return m
+}
+
+func errorMessage(e gtr.Error, fallback string) string {
+	if e.Cause != "" {
+		return e.Cause
+	}
+	return fallback
+}
+
+// Status returns "passed" if the report of message m was successful and
+// "failed" otherwise.
+func (m Message) Status() string {
+	if m.Successful {
+		return "passed"
+	}
+	return "failed"
+}
+
+// Totals returns a single line describing the totals of message m, such as
+// "12 tests: 10 passed, 1 failed, 1 skipped in 2.5s".
+func (m Message) Totals() string {
+	s := m.Summary
+	line := fmt.Sprintf("%d tests: %d passed, %d failed, %d skipped", s.Tests, s.Passed, s.Failed, s.Skipped)
+	if s.Flaky > 0 {
+		line += fmt.Sprintf(", %d flaky", s.Flaky)
+	}
+	if s.Errors > 0 {
+		line += fmt.Sprintf(", %d errors", s.Errors)
+	}
+	return line + " in " + s.Duration.Round(time.Millisecond).String()
+}
+
+// Notifier posts messages to a webhook.
+type Notifier struct {
+	// Client is the HTTP client used to post messages, http.DefaultClient if
+	// nil.
+	Client *http.Client
+
+	// URL is the webhook URL messages are posted to.
+	URL string
+
+	// Format is the format of the messages: FormatSlack, FormatTeams or
+	// FormatJSON. It's ignored when Template is set.
+	Format string
+
+	// Template, if set, is executed with the Message to create the request
+	// body instead of using Format. Templates can use the json function to
+	// encode a value as JSON, e.g. {{json .Title}}.
+	Template *template.Template
+
+	// Headers are added to every request.
+	Headers map[string]string
+}
+
+// ParseTemplate parses a template for Notifier.Template.
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("notification").Funcs(template.FuncMap{"json": jsonString}).Parse(text)
+}
+
+func jsonString(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// Body returns the request body n posts for message m.
+func (n Notifier) Body(m Message) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if n.Template != nil {
+		err = n.Template.Execute(&buf, m)
+		return buf.Bytes(), err
+	}
+	switch n.Format {
+	case FormatSlack, "":
+		err = writeJSON(&buf, slackMessage(m))
+	case FormatTeams:
+		err = writeJSON(&buf, teamsMessage(m))
+	case FormatJSON:
+		err = writeJSON(&buf, m)
+	default:
+		return nil, fmt.Errorf("unknown notification format: %q", n.Format)
+	}
+	return buf.Bytes(), err
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// Notify posts message m to the webhook of n. Since webhook URLs usually
+// contain a secret, the returned error only mentions the scheme and host of
+// the URL.
+func (n Notifier) Notify(m Message) error {
+	body, err := n.Body(m)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("invalid webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.Headers {
+		req.Header.Set(k, v)
+	}
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return redactURL(err, req.URL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, err = io.Copy(ioutil.Discard, resp.Body)
+	return redactURL(err, req.URL)
+}
+
+// redactURL returns err with the webhook URL u in it replaced by its scheme
+// and host.
+func redactURL(err error, u *url.URL) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	return fmt.Errorf("%s %s://%s: %w", urlErr.Op, u.Scheme, u.Host, urlErr.Err)
+}
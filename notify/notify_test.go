@@ -0,0 +1,203 @@
+package notify
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+var testReport = gtr.Report{Packages: []gtr.Package{
+	{
+		Name:     "package/one",
+		Duration: 1500 * time.Millisecond,
+		Tests: []gtr.Test{
+			{Name: "TestPass", Result: gtr.Pass},
+			{Name: "TestFail", Result: gtr.Fail, FailureMessage: "want 1, got 2\nmore details"},
+			{Name: "TestFlaky", Result: gtr.Flaky},
+			{Name: "TestSkip", Result: gtr.Skip},
+			{Name: "TestTimeout", Result: gtr.Unknown},
+		},
+	},
+	{
+		Name:       "package/two",
+		BuildError: gtr.Error{Name: "package/two", Cause: "setup failed"},
+	},
+}}
+
+func TestNewMessage(t *testing.T) {
+	want := Message{
+		Title:      DefaultTitle,
+		Successful: false,
+		Summary:    Summary{Tests: 5, Passed: 1, Failed: 1, Skipped: 1, Flaky: 1, Errors: 2, Duration: 1500 * time.Millisecond},
+		Failures: []Failure{
+			{Package: "package/one", Test: "TestFail", Message: "want 1, got 2\nmore details"},
+			{Package: "package/one", Test: "TestTimeout", Message: "no result"},
+		},
+		MoreFailures: 1,
+		Flaky:        []Failure{{Package: "package/one", Test: "TestFlaky"}},
+		ArtifactsURL: "https://ci.example.com/1",
+	}
+	got := NewMessage(testReport, Options{ArtifactsURL: "https://ci.example.com/1", MaxFailures: 2})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("NewMessage result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	if got, want := got.Totals(), "5 tests: 1 passed, 1 failed, 1 skipped, 1 flaky, 2 errors in 1.5s"; got != want {
+		t.Errorf("Totals() = %q, want %q", got, want)
+	}
+}
+
+func TestBody(t *testing.T) {
+	m := NewMessage(testReport, Options{Title: "CI <main>", ArtifactsURL: "https://ci.example.com/1"})
+
+	body, err := Notifier{Format: FormatSlack}.Body(m)
+	if err != nil {
+		t.Fatalf("Body error: %v", err)
+	}
+	var slack struct{ Text string }
+	if err := json.Unmarshal(body, &slack); err != nil {
+		t.Fatalf("error decoding slack message: %v", err)
+	}
+	wantSlack := "*CI &lt;main&gt; failed*\n" +
+		"5 tests: 1 passed, 1 failed, 1 skipped, 1 flaky, 2 errors in 1.5s\n" +
+		"\n*Failures*\n" +
+		"• `package/one.TestFail`: want 1, got 2\n" +
+		"• `package/one.TestTimeout`: no result\n" +
+		"• `package/two`: setup failed\n" +
+		"\n*Flaky tests*\n" +
+		"• `package/one.TestFlaky`\n" +
+		"\n<https://ci.example.com/1|Artifacts>"
+	if diff := cmp.Diff(wantSlack, slack.Text); diff != "" {
+		t.Errorf("slack message incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	body, err = Notifier{Format: FormatTeams}.Body(m)
+	if err != nil {
+		t.Fatalf("Body error: %v", err)
+	}
+	var teams struct {
+		Type        string
+		Attachments []struct {
+			ContentType string
+			Content     struct {
+				Type    string
+				Body    []struct{ Text string }
+				Actions []struct{ URL string }
+			}
+		}
+	}
+	if err := json.Unmarshal(body, &teams); err != nil {
+		t.Fatalf("error decoding teams message: %v", err)
+	}
+	if teams.Type != "message" || len(teams.Attachments) != 1 || teams.Attachments[0].Content.Type != "AdaptiveCard" {
+		t.Fatalf("teams message is not an adaptive card: %s", body)
+	}
+	card := teams.Attachments[0].Content
+	var texts []string
+	for _, block := range card.Body {
+		texts = append(texts, block.Text)
+	}
+	wantTexts := []string{
+		"CI <main> failed",
+		"5 tests: 1 passed, 1 failed, 1 skipped, 1 flaky, 2 errors in 1.5s",
+		"Failures",
+		"- package/one.TestFail: want 1, got 2\n- package/one.TestTimeout: no result\n- package/two: setup failed",
+		"Flaky tests",
+		"- package/one.TestFlaky",
+	}
+	if diff := cmp.Diff(wantTexts, texts); diff != "" {
+		t.Errorf("teams card text incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if len(card.Actions) != 1 || card.Actions[0].URL != "https://ci.example.com/1" {
+		t.Errorf("teams card actions = %+v, want artifacts link", card.Actions)
+	}
+
+	body, err = Notifier{Format: FormatJSON}.Body(m)
+	if err != nil {
+		t.Fatalf("Body error: %v", err)
+	}
+	var decoded Message
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("error decoding json message: %v", err)
+	}
+	if diff := cmp.Diff(m, decoded); diff != "" {
+		t.Errorf("json message incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	if _, err := (Notifier{Format: "email"}).Body(m); err == nil {
+		t.Errorf("Body did not return an error for an unknown format")
+	}
+}
+
+func TestBodyTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate(`{"text": {{json (printf "%s %s: %d failures" .Title .Status (len .Failures))}}}`)
+	if err != nil {
+		t.Fatalf("ParseTemplate error: %v", err)
+	}
+	m := NewMessage(testReport, Options{Title: `say "hi"`})
+	body, err := Notifier{Template: tmpl}.Body(m)
+	if err != nil {
+		t.Fatalf("Body error: %v", err)
+	}
+	want := `{"text": "say \"hi\" failed: 3 failures"}`
+	if got := string(body); got != want {
+		t.Errorf("Body = %s, want %s", got, want)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	var gotType, gotAuth, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotType, gotAuth = r.Header.Get("Content-Type"), r.Header.Get("Authorization")
+		data, _ := ioutil.ReadAll(r.Body)
+		gotBody = string(data)
+	}))
+	defer server.Close()
+
+	n := Notifier{URL: server.URL, Format: FormatJSON, Headers: map[string]string{"Authorization": "Bearer token"}}
+	if err := n.Notify(NewMessage(testReport, Options{})); err != nil {
+		t.Fatalf("Notify error: %v", err)
+	}
+	if gotType != "application/json" || gotAuth != "Bearer token" {
+		t.Errorf("Notify sent Content-Type %q and Authorization %q", gotType, gotAuth)
+	}
+	if !strings.Contains(gotBody, `"title":"go test"`) {
+		t.Errorf("Notify sent unexpected body: %s", gotBody)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+	}))
+	defer failing.Close()
+	n.URL = failing.URL
+	if err := n.Notify(NewMessage(testReport, Options{})); err == nil || !strings.Contains(err.Error(), "invalid_payload") {
+		t.Errorf("Notify error = %v, want error containing invalid_payload", err)
+	}
+}
+
+func TestNotifyErrorRedactsURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	host := server.Listener.Addr().String()
+	server.Close()
+
+	for _, u := range []string{
+		"http://" + host + "/services/T000/B000/secret-token",
+		"http://[::1/services/secret-token",
+	} {
+		n := Notifier{URL: u}
+		err := n.Notify(NewMessage(testReport, Options{}))
+		if err == nil {
+			t.Fatalf("Notify(%q) did not return an error", u)
+		}
+		if strings.Contains(err.Error(), "secret-token") {
+			t.Errorf("Notify(%q) error contains the webhook URL: %v", u, err)
+		}
+	}
+}
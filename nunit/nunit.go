@@ -0,0 +1,391 @@
+// Package nunit defines the NUnit 3 test result XML format and includes
+// convenience methods to create these reports from a gtr.Report.
+//
+// Each package is reported as a test suite of type Assembly. Tests without
+// subtests are test cases, while tests with subtests become nested test
+// suites of type TestSuite containing their subtests. Skipped tests have the
+// Skipped result with the Ignored label, and inconclusive tests and tests
+// without a result are reported as Inconclusive.
+package nunit
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/timefmt"
+)
+
+// Test results and labels.
+const (
+	ResultPassed       = "Passed"
+	ResultFailed       = "Failed"
+	ResultSkipped      = "Skipped"
+	ResultInconclusive = "Inconclusive"
+
+	LabelIgnored = "Ignored"
+	LabelError   = "Error"
+)
+
+// Suite types.
+const (
+	TypeAssembly  = "Assembly"
+	TypeTestSuite = "TestSuite"
+)
+
+// timeFormat is the format of start and end times.
+const timeFormat = "2006-01-02 15:04:05Z"
+
+// Counts contains the number of test cases by result.
+type Counts struct {
+	TestCaseCount int `xml:"testcasecount,attr"`
+	Total         int `xml:"total,attr"`
+	Passed        int `xml:"passed,attr"`
+	Failed        int `xml:"failed,attr"`
+	Inconclusive  int `xml:"inconclusive,attr"`
+	Skipped       int `xml:"skipped,attr"`
+}
+
+// add adds the counts in o to c.
+func (c *Counts) add(o Counts) {
+	c.TestCaseCount += o.TestCaseCount
+	c.Total += o.Total
+	c.Passed += o.Passed
+	c.Failed += o.Failed
+	c.Inconclusive += o.Inconclusive
+	c.Skipped += o.Skipped
+}
+
+// addResult counts a single test case with the given result.
+func (c *Counts) addResult(result string) {
+	c.TestCaseCount++
+	c.Total++
+	switch result {
+	case ResultPassed:
+		c.Passed++
+	case ResultFailed:
+		c.Failed++
+	case ResultInconclusive:
+		c.Inconclusive++
+	case ResultSkipped:
+		c.Skipped++
+	}
+}
+
+// result returns the result of a suite with counts c.
+func (c Counts) result() string {
+	switch {
+	case c.Failed > 0:
+		return ResultFailed
+	case c.Passed > 0:
+		return ResultPassed
+	case c.Inconclusive > 0:
+		return ResultInconclusive
+	case c.Skipped > 0:
+		return ResultSkipped
+	default:
+		return ResultPassed
+	}
+}
+
+// TestRun is the root element of an NUnit 3 test result file.
+type TestRun struct {
+	XMLName xml.Name `xml:"test-run"`
+	ID      string   `xml:"id,attr"`
+	Counts
+	Result    string      `xml:"result,attr"`
+	StartTime string      `xml:"start-time,attr,omitempty"`
+	EndTime   string      `xml:"end-time,attr,omitempty"`
+	Duration  string      `xml:"duration,attr"` // in seconds
+	Suites    []TestSuite `xml:"test-suite"`
+}
+
+// TestSuite is a suite of tests, either a package or a test with subtests.
+type TestSuite struct {
+	Type     string `xml:"type,attr"`
+	ID       string `xml:"id,attr"`
+	Name     string `xml:"name,attr"`
+	FullName string `xml:"fullname,attr"`
+	Counts
+	Result     string      `xml:"result,attr"`
+	Label      string      `xml:"label,attr,omitempty"`
+	StartTime  string      `xml:"start-time,attr,omitempty"`
+	EndTime    string      `xml:"end-time,attr,omitempty"`
+	Duration   string      `xml:"duration,attr"` // in seconds
+	Properties *Properties `xml:"properties,omitempty"`
+	Failure    *Failure    `xml:"failure,omitempty"`
+	Reason     *Reason     `xml:"reason,omitempty"`
+	Output     string      `xml:"output,omitempty"`
+	Suites     []TestSuite `xml:"test-suite"`
+	Cases      []TestCase  `xml:"test-case"`
+}
+
+// TestCase contains the result of a single test.
+type TestCase struct {
+	ID         string      `xml:"id,attr"`
+	Name       string      `xml:"name,attr"`
+	FullName   string      `xml:"fullname,attr"`
+	MethodName string      `xml:"methodname,attr"`
+	ClassName  string      `xml:"classname,attr"`
+	Result     string      `xml:"result,attr"`
+	Label      string      `xml:"label,attr,omitempty"`
+	StartTime  string      `xml:"start-time,attr,omitempty"`
+	EndTime    string      `xml:"end-time,attr,omitempty"`
+	Duration   string      `xml:"duration,attr"` // in seconds
+	Properties *Properties `xml:"properties,omitempty"`
+	Failure    *Failure    `xml:"failure,omitempty"`
+	Reason     *Reason     `xml:"reason,omitempty"`
+	Output     string      `xml:"output,omitempty"`
+}
+
+// Properties contains the properties of a suite or test case.
+type Properties struct {
+	Properties []Property `xml:"property"`
+}
+
+// Property is a name/value pair.
+type Property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// Failure describes why a test failed.
+type Failure struct {
+	Message    string `xml:"message"`
+	StackTrace string `xml:"stack-trace,omitempty"`
+}
+
+// Reason describes why a test was skipped or is inconclusive.
+type Reason struct {
+	Message string `xml:"message"`
+}
+
+// CreateFromReport creates an NUnit 3 representation of the given gtr.Report.
+func CreateFromReport(report gtr.Report) TestRun {
+	b := builder{}
+	run := TestRun{ID: b.nextID()}
+	var start, end time.Time
+	var duration time.Duration
+	for _, pkg := range report.Packages {
+		suite := b.createPackageSuite(pkg)
+		run.Counts.add(suite.Counts)
+		run.Suites = append(run.Suites, suite)
+		duration += packageDuration(pkg)
+		if !pkg.Timestamp.IsZero() {
+			if start.IsZero() || pkg.Timestamp.Before(start) {
+				start = pkg.Timestamp
+			}
+			if e := pkg.Timestamp.Add(packageDuration(pkg)); e.After(end) {
+				end = e
+			}
+		}
+	}
+	run.Result = run.Counts.result()
+	for _, suite := range run.Suites {
+		if suite.Result == ResultFailed {
+			run.Result = ResultFailed
+		}
+	}
+	run.StartTime, run.EndTime = formatTime(start), formatTime(end)
+	run.Duration = timefmt.Seconds(duration, 6)
+	return run
+}
+
+// builder assigns sequential ids to the elements of a test run.
+type builder struct {
+	id int
+}
+
+func (b *builder) nextID() string {
+	id := strconv.Itoa(b.id)
+	b.id++
+	return id
+}
+
+func (b *builder) createPackageSuite(pkg gtr.Package) TestSuite {
+	suite := TestSuite{
+		Type:       TypeAssembly,
+		ID:         b.nextID(),
+		Name:       pkg.Name,
+		FullName:   pkg.Name,
+		Duration:   timefmt.Seconds(packageDuration(pkg), 6),
+		Properties: properties(pkg.Properties),
+		Output:     strings.Join(pkg.Output, "\n"),
+	}
+	if !pkg.Timestamp.IsZero() {
+		suite.StartTime = formatTime(pkg.Timestamp)
+		suite.EndTime = formatTime(pkg.Timestamp.Add(packageDuration(pkg)))
+	}
+
+	for _, node := range pkg.TestTree() {
+		b.addNode(&suite, pkg.Name, node)
+	}
+	suite.Result = suite.Counts.result()
+
+	var failures []string
+	var output []string
+	if pkg.BuildError.Name != "" {
+		failures = append(failures, "Build error: "+pkg.BuildError.Cause)
+		output = append(output, pkg.BuildError.Output...)
+	}
+	if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+		message := pkg.RunError.FailureReason()
+		if message == "" {
+			message = "Runtime error"
+		}
+		failures = append(failures, message)
+		output = append(output, pkg.RunError.Output...)
+	}
+	if len(failures) > 0 {
+		suite.Result, suite.Label = ResultFailed, LabelError
+		suite.Failure = &Failure{Message: strings.Join(failures, "\n"), StackTrace: strings.Join(output, "\n")}
+	}
+	return suite
+}
+
+// addNode adds the test in node n of package pkgName to suite, as a test case
+// if it has no subtests or as a nested suite otherwise.
+func (b *builder) addNode(suite *TestSuite, pkgName string, n *gtr.TestNode) {
+	if len(n.Children) == 0 {
+		tc := b.createTestCase(pkgName, n.Test)
+		suite.Counts.addResult(tc.Result)
+		suite.Cases = append(suite.Cases, tc)
+		return
+	}
+
+	tc := b.createTestCase(pkgName, n.Test)
+	sub := TestSuite{
+		Type:       TypeTestSuite,
+		ID:         tc.ID,
+		Name:       tc.Name,
+		FullName:   tc.FullName,
+		StartTime:  tc.StartTime,
+		EndTime:    tc.EndTime,
+		Duration:   tc.Duration,
+		Properties: tc.Properties,
+		Failure:    tc.Failure,
+		Reason:     tc.Reason,
+		Output:     tc.Output,
+	}
+	for _, child := range n.Children {
+		b.addNode(&sub, pkgName, child)
+	}
+	sub.Result = sub.Counts.result()
+	if tc.Result == ResultFailed {
+		sub.Result, sub.Label = ResultFailed, tc.Label
+	}
+	suite.Counts.add(sub.Counts)
+	suite.Suites = append(suite.Suites, sub)
+}
+
+func (b *builder) createTestCase(pkgName string, test gtr.Test) TestCase {
+	name := test.Name
+	if idx := strings.LastIndexByte(name, '/'); idx >= 0 {
+		name = name[idx+1:]
+	}
+	tc := TestCase{
+		ID:         b.nextID(),
+		Name:       name,
+		FullName:   pkgName + "." + test.Name,
+		MethodName: test.Name,
+		ClassName:  pkgName,
+		Duration:   timefmt.Seconds(test.Duration, 6),
+		Output:     strings.Join(test.Output, "\n"),
+	}
+	if !test.StartTime.IsZero() {
+		tc.StartTime = formatTime(test.StartTime)
+	}
+	if !test.EndTime.IsZero() {
+		tc.EndTime = formatTime(test.EndTime)
+	}
+
+	props := test.Properties
+	if test.Result == gtr.Flaky {
+		props = append(append([]gtr.Property(nil), props...), gtr.Property{Name: "flaky", Value: "true"})
+	}
+	tc.Properties = properties(props)
+
+	if test.Result == gtr.Inconclusive {
+		tc.Result = ResultInconclusive
+		return tc
+	}
+	switch test.Result.Base() {
+	case gtr.Pass, gtr.Flaky:
+		tc.Result = ResultPassed
+	case gtr.Skip:
+		tc.Result, tc.Label = ResultSkipped, LabelIgnored
+		message := "Skipped"
+		if test.SkipMessage != "" {
+			message = test.SkipMessage
+		}
+		tc.Reason = &Reason{Message: message}
+	case gtr.Fail:
+		tc.Result = ResultFailed
+		message := test.FailureReason()
+		if message == "" {
+			message = "Failed"
+		}
+		if test.Panic != nil {
+			tc.Label = LabelError
+		}
+		tc.Failure = &Failure{Message: message}
+		if test.Panic != nil {
+			tc.Failure.StackTrace = strings.Join(test.Panic.Stack, "\n")
+		}
+	default:
+		tc.Result = ResultInconclusive
+		tc.Reason = &Reason{Message: "No test result found"}
+	}
+	return tc
+}
+
+func properties(props []gtr.Property) *Properties {
+	if len(props) == 0 {
+		return nil
+	}
+	p := &Properties{}
+	for _, prop := range props {
+		p.Properties = append(p.Properties, Property{Name: prop.Name, Value: prop.Value})
+	}
+	return p
+}
+
+// packageDuration returns the duration of pkg, or the sum of its test
+// durations if unknown.
+func packageDuration(pkg gtr.Package) time.Duration {
+	if pkg.Duration > 0 {
+		return pkg.Duration
+	}
+	var d time.Duration
+	for _, test := range pkg.Tests {
+		d += test.Duration
+	}
+	return d
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(timeFormat)
+}
+
+// WriteXML writes the XML representation of TestRun t to writer w.
+func (t *TestRun) WriteXML(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(t); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
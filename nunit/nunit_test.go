@@ -0,0 +1,144 @@
+package nunit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCreateFromReport(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{
+		{
+			Name:       "package/one",
+			Timestamp:  time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+			Duration:   time.Second,
+			Properties: []gtr.Property{{Name: "go.version", Value: "1.18"}},
+			Tests: []gtr.Test{
+				{Name: "TestPass", Result: gtr.Pass, Duration: 10 * time.Millisecond, Output: []string{"ok"}},
+				{Name: "TestFail", Result: gtr.Fail, FailureMessage: "boom"},
+				{Name: "TestFail/sub_pass", Result: gtr.Pass},
+				{Name: "TestFail/sub_fail", Result: gtr.Fail},
+				{Name: "TestSkip", Result: gtr.Skip, SkipMessage: "not today"},
+				{Name: "TestFlaky", Result: gtr.Flaky},
+				{Name: "TestUnknown", Result: gtr.Unknown},
+			},
+		},
+		{
+			Name:       "package/two",
+			BuildError: gtr.Error{Name: "package/two", Cause: "[build failed]", Output: []string{"undefined: x"}},
+		},
+	}}
+
+	want := TestRun{
+		ID:        "0",
+		Counts:    Counts{TestCaseCount: 6, Total: 6, Passed: 3, Failed: 1, Inconclusive: 1, Skipped: 1},
+		Result:    ResultFailed,
+		StartTime: "2022-01-02 03:04:05Z",
+		EndTime:   "2022-01-02 03:04:06Z",
+		Duration:  "1.000000",
+		Suites: []TestSuite{
+			{
+				Type:       TypeAssembly,
+				ID:         "1",
+				Name:       "package/one",
+				FullName:   "package/one",
+				Counts:     Counts{TestCaseCount: 6, Total: 6, Passed: 3, Failed: 1, Inconclusive: 1, Skipped: 1},
+				Result:     ResultFailed,
+				StartTime:  "2022-01-02 03:04:05Z",
+				EndTime:    "2022-01-02 03:04:06Z",
+				Duration:   "1.000000",
+				Properties: &Properties{[]Property{{Name: "go.version", Value: "1.18"}}},
+				Suites: []TestSuite{{
+					Type:     TypeTestSuite,
+					ID:       "3",
+					Name:     "TestFail",
+					FullName: "package/one.TestFail",
+					Counts:   Counts{TestCaseCount: 2, Total: 2, Passed: 1, Failed: 1},
+					Result:   ResultFailed,
+					Duration: "0.000000",
+					Failure:  &Failure{Message: "boom"},
+					Cases: []TestCase{
+						{ID: "4", Name: "sub_pass", FullName: "package/one.TestFail/sub_pass", MethodName: "TestFail/sub_pass", ClassName: "package/one", Result: ResultPassed, Duration: "0.000000"},
+						{ID: "5", Name: "sub_fail", FullName: "package/one.TestFail/sub_fail", MethodName: "TestFail/sub_fail", ClassName: "package/one", Result: ResultFailed, Duration: "0.000000", Failure: &Failure{Message: "Failed"}},
+					},
+				}},
+				Cases: []TestCase{
+					{ID: "2", Name: "TestPass", FullName: "package/one.TestPass", MethodName: "TestPass", ClassName: "package/one", Result: ResultPassed, Duration: "0.010000", Output: "ok"},
+					{
+						ID: "6", Name: "TestSkip", FullName: "package/one.TestSkip", MethodName: "TestSkip", ClassName: "package/one",
+						Result: ResultSkipped, Label: LabelIgnored, Duration: "0.000000", Reason: &Reason{Message: "not today"},
+					},
+					{
+						ID: "7", Name: "TestFlaky", FullName: "package/one.TestFlaky", MethodName: "TestFlaky", ClassName: "package/one",
+						Result: ResultPassed, Duration: "0.000000", Properties: &Properties{[]Property{{Name: "flaky", Value: "true"}}},
+					},
+					{
+						ID: "8", Name: "TestUnknown", FullName: "package/one.TestUnknown", MethodName: "TestUnknown", ClassName: "package/one",
+						Result: ResultInconclusive, Duration: "0.000000", Reason: &Reason{Message: "No test result found"},
+					},
+				},
+			},
+			{
+				Type:     TypeAssembly,
+				ID:       "9",
+				Name:     "package/two",
+				FullName: "package/two",
+				Result:   ResultFailed,
+				Label:    LabelError,
+				Duration: "0.000000",
+				Failure:  &Failure{Message: "Build error: [build failed]", StackTrace: "undefined: x"},
+			},
+		},
+	}
+
+	got := CreateFromReport(report)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CreateFromReport result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWriteXML(t *testing.T) {
+	run := TestRun{
+		ID:       "0",
+		Counts:   Counts{TestCaseCount: 2, Total: 2, Passed: 1, Skipped: 1},
+		Result:   ResultPassed,
+		Duration: "0.012000",
+		Suites: []TestSuite{{
+			Type:     TypeAssembly,
+			ID:       "1",
+			Name:     "package/one",
+			FullName: "package/one",
+			Counts:   Counts{TestCaseCount: 2, Total: 2, Passed: 1, Skipped: 1},
+			Result:   ResultPassed,
+			Duration: "0.012000",
+			Cases: []TestCase{
+				{ID: "2", Name: "TestPass", FullName: "package/one.TestPass", MethodName: "TestPass", ClassName: "package/one", Result: ResultPassed, Duration: "0.012000"},
+				{ID: "3", Name: "TestSkip", FullName: "package/one.TestSkip", MethodName: "TestSkip", ClassName: "package/one", Result: ResultSkipped, Label: LabelIgnored, Duration: "0.000000", Reason: &Reason{Message: "a < b"}},
+			},
+		}},
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<test-run id="0" testcasecount="2" total="2" passed="1" failed="0" inconclusive="0" skipped="1" result="Passed" duration="0.012000">
+	<test-suite type="Assembly" id="1" name="package/one" fullname="package/one" testcasecount="2" total="2" passed="1" failed="0" inconclusive="0" skipped="1" result="Passed" duration="0.012000">
+		<test-case id="2" name="TestPass" fullname="package/one.TestPass" methodname="TestPass" classname="package/one" result="Passed" duration="0.012000"></test-case>
+		<test-case id="3" name="TestSkip" fullname="package/one.TestSkip" methodname="TestSkip" classname="package/one" result="Skipped" label="Ignored" duration="0.000000">
+			<reason>
+				<message>a &lt; b</message>
+			</reason>
+		</test-case>
+	</test-suite>
+</test-run>
+`
+	var buf bytes.Buffer
+	if err := run.WriteXML(&buf); err != nil {
+		t.Fatalf("WriteXML failed: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("WriteXML output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,352 @@
+// Package otlp exports a report as OpenTelemetry traces, using the JSON
+// encoding of the OpenTelemetry Protocol (OTLP).
+//
+// A report is converted into a single trace. Its root span covers the entire
+// test run and has a child span for each package, which in turn has a child
+// span for each of its tests. Subtests are children of their parent test. The
+// result of each test is reported as the span status, and test output is added
+// as a span event. Traces can be sent to any collector that accepts OTLP over
+// HTTP, see Export.
+package otlp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// DefaultServiceName is the service.name resource attribute of traces, unless
+// another name is given in Options.
+const DefaultServiceName = "go-test"
+
+// Span kinds and status codes, as defined by OTLP.
+const (
+	spanKindInternal = 1
+
+	statusUnset = 0
+	statusOK    = 1
+	statusError = 2
+)
+
+// TraceID is the id of a trace.
+type TraceID [16]byte
+
+// NewTraceID returns a new random trace id.
+func NewTraceID() (TraceID, error) {
+	var id TraceID
+	_, err := rand.Read(id[:])
+	return id, err
+}
+
+// String returns the hex encoding of trace id t.
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// Options configures how a report is converted to traces.
+type Options struct {
+	// ServiceName is the service.name resource attribute, DefaultServiceName
+	// if empty.
+	ServiceName string
+
+	// TraceID is the id of the trace.
+	TraceID TraceID
+
+	// Start is the start time of packages without a known start time or
+	// timestamp.
+	Start time.Time
+}
+
+// Traces is an OTLP ExportTraceServiceRequest, containing the spans of a
+// report.
+type Traces struct {
+	ResourceSpans []ResourceSpans `json:"resourceSpans"`
+}
+
+// ResourceSpans contains the spans of a single resource.
+type ResourceSpans struct {
+	Resource   Resource     `json:"resource"`
+	ScopeSpans []ScopeSpans `json:"scopeSpans"`
+}
+
+// Resource describes the entity that produced the spans.
+type Resource struct {
+	Attributes []Attribute `json:"attributes,omitempty"`
+}
+
+// ScopeSpans contains the spans created by a single instrumentation scope.
+type ScopeSpans struct {
+	Scope Scope  `json:"scope"`
+	Spans []Span `json:"spans"`
+}
+
+// Scope is the instrumentation scope that created the spans.
+type Scope struct {
+	Name string `json:"name"`
+}
+
+// Span is a single operation in a trace, such as running a test.
+type Span struct {
+	TraceID           string      `json:"traceId"`
+	SpanID            string      `json:"spanId"`
+	ParentSpanID      string      `json:"parentSpanId,omitempty"`
+	Name              string      `json:"name"`
+	Kind              int         `json:"kind"`
+	StartTimeUnixNano string      `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string      `json:"endTimeUnixNano"`
+	Attributes        []Attribute `json:"attributes,omitempty"`
+	Events            []Event     `json:"events,omitempty"`
+	Status            Status      `json:"status"`
+}
+
+// Event is an event that happened during a span.
+type Event struct {
+	TimeUnixNano string      `json:"timeUnixNano"`
+	Name         string      `json:"name"`
+	Attributes   []Attribute `json:"attributes,omitempty"`
+}
+
+// Status is the status of a span.
+type Status struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Attribute is a key/value pair describing a span, event or resource.
+type Attribute struct {
+	Key   string `json:"key"`
+	Value Value  `json:"value"`
+}
+
+// Value is the value of an attribute. Only one of its fields is set.
+type Value struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"` // int64 encoded as a string
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func stringAttr(key, value string) Attribute {
+	return Attribute{Key: key, Value: Value{StringValue: &value}}
+}
+
+func intAttr(key string, value int64) Attribute {
+	s := strconv.FormatInt(value, 10)
+	return Attribute{Key: key, Value: Value{IntValue: &s}}
+}
+
+func doubleAttr(key string, value float64) Attribute {
+	return Attribute{Key: key, Value: Value{DoubleValue: &value}}
+}
+
+// CreateFromReport converts report r into traces using the given options.
+func CreateFromReport(r gtr.Report, opts Options) Traces {
+	b := builder{traceID: opts.TraceID.String()}
+
+	root := b.newSpan("", "go test")
+	start, end := time.Time{}, time.Time{}
+	for _, pkg := range r.Packages {
+		pkgStart, pkgEnd := pkg.Times(opts.Start)
+		b.addPackage(root.SpanID, pkg, pkgStart, pkgEnd)
+		if start.IsZero() || pkgStart.Before(start) {
+			start = pkgStart
+		}
+		if pkgEnd.After(end) {
+			end = pkgEnd
+		}
+	}
+	if start.IsZero() {
+		start, end = opts.Start, opts.Start
+	}
+	root.StartTimeUnixNano, root.EndTimeUnixNano = unixNano(start), unixNano(end)
+	root.Status = Status{Code: statusOK}
+	if !r.IsSuccessful() {
+		root.Status = Status{Code: statusError, Message: "tests failed"}
+	}
+	b.spans = append([]Span{*root}, b.spans...)
+
+	serviceName := opts.ServiceName
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+	return Traces{ResourceSpans: []ResourceSpans{{
+		Resource:   Resource{Attributes: []Attribute{stringAttr("service.name", serviceName)}},
+		ScopeSpans: []ScopeSpans{{Scope: Scope{Name: "go-junit-report"}, Spans: b.spans}},
+	}}}
+}
+
+// builder creates the spans of a single trace.
+type builder struct {
+	traceID string
+	nextID  uint64
+	spans   []Span
+}
+
+// newSpan returns a new span with the given parent and name. Span ids are
+// assigned sequentially, which makes them unique within the trace.
+func (b *builder) newSpan(parentID, name string) *Span {
+	b.nextID++
+	var id [8]byte
+	binary.BigEndian.PutUint64(id[:], b.nextID)
+	return &Span{
+		TraceID:      b.traceID,
+		SpanID:       hex.EncodeToString(id[:]),
+		ParentSpanID: parentID,
+		Name:         name,
+		Kind:         spanKindInternal,
+	}
+}
+
+func (b *builder) addPackage(parentID string, pkg gtr.Package, start, end time.Time) {
+	span := b.newSpan(parentID, pkg.Name)
+	span.StartTimeUnixNano, span.EndTimeUnixNano = unixNano(start), unixNano(end)
+	span.Attributes = []Attribute{stringAttr("test.package", pkg.Name)}
+	if pkg.Coverage > 0 {
+		span.Attributes = append(span.Attributes, doubleAttr("test.coverage", pkg.Coverage))
+	}
+	for _, p := range pkg.Properties {
+		span.Attributes = append(span.Attributes, stringAttr(p.Name, p.Value))
+	}
+	span.Status = Status{Code: statusOK}
+	if pkg.BuildError.Name != "" {
+		span.Status = Status{Code: statusError, Message: "build error"}
+		span.Events = append(span.Events, outputEvent("build_error", end, pkg.BuildError.Output))
+	}
+	if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+		span.Status = Status{Code: statusError, Message: "runtime error"}
+		span.Events = append(span.Events, outputEvent("run_error", end, pkg.RunError.Output))
+	}
+	pkgIndex := len(b.spans)
+	b.spans = append(b.spans, *span)
+
+	ids := make(map[string]string) // span ids by test name
+	for _, test := range pkg.Tests {
+		parent := span.SpanID
+		if id, ok := ids[parentName(test.Name)]; ok {
+			parent = id
+		}
+		ids[test.Name] = b.addTest(parent, pkg.Name, test, start)
+		if (test.Result.Base() == gtr.Fail || test.Result == gtr.Unknown) && span.Status.Code != statusError {
+			span.Status = Status{Code: statusError, Message: "tests failed"}
+			b.spans[pkgIndex].Status = span.Status
+		}
+	}
+}
+
+// addTest adds a span for test in package pkgName and returns its id. Tests
+// without a known start time are assumed to have started at pkgStart.
+func (b *builder) addTest(parentID, pkgName string, test gtr.Test, pkgStart time.Time) string {
+	span := b.newSpan(parentID, test.Name)
+	start := test.StartTime
+	if start.IsZero() {
+		start = pkgStart
+	}
+	end := test.EndTime
+	if end.IsZero() {
+		end = start.Add(test.Duration)
+	}
+	span.StartTimeUnixNano, span.EndTimeUnixNano = unixNano(start), unixNano(end)
+	span.Attributes = []Attribute{
+		stringAttr("test.package", pkgName),
+		stringAttr("test.name", test.Name),
+		stringAttr("test.result", strings.ToLower(test.Result.String())),
+	}
+	if len(test.Attempts) > 0 {
+		span.Attributes = append(span.Attributes, intAttr("test.attempts", int64(len(test.Attempts))))
+	}
+	for _, p := range test.Properties {
+		span.Attributes = append(span.Attributes, stringAttr(p.Name, p.Value))
+	}
+
+	switch test.Result.Base() {
+	case gtr.Pass, gtr.Flaky:
+		span.Status = Status{Code: statusOK}
+	case gtr.Fail:
+		span.Status = Status{Code: statusError, Message: test.FailureMessage}
+		if span.Status.Message == "" {
+			span.Status.Message = "test failed"
+		}
+	case gtr.Unknown:
+		span.Status = Status{Code: statusError, Message: "no test result found"}
+	case gtr.Skip:
+		span.Status = Status{Code: statusUnset, Message: test.SkipMessage}
+	}
+	if len(test.Output) > 0 {
+		span.Events = append(span.Events, outputEvent("output", end, test.Output))
+	}
+	b.spans = append(b.spans, *span)
+	return span.SpanID
+}
+
+// outputEvent returns an event with the given name, containing the given
+// output lines in its message attribute.
+func outputEvent(name string, t time.Time, output []string) Event {
+	return Event{
+		TimeUnixNano: unixNano(t),
+		Name:         name,
+		Attributes:   []Attribute{stringAttr("message", strings.Join(output, "\n"))},
+	}
+}
+
+func parentName(name string) string {
+	if idx := strings.LastIndexByte(name, '/'); idx >= 0 {
+		return name[:idx]
+	}
+	return ""
+}
+
+func unixNano(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// WriteJSON writes the OTLP JSON encoding of traces t to w.
+func (t Traces) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(t)
+}
+
+// Export sends traces t to the OTLP/HTTP collector at endpoint, adding the
+// given headers to the request, e.g. for authentication. The endpoint is the
+// base URL of the collector, such as http://localhost:4318, to which the
+// /v1/traces path is added unless it's already present.
+func Export(client *http.Client, endpoint string, headers map[string]string, t Traces) error {
+	var body bytes.Buffer
+	if err := t.WriteJSON(&body); err != nil {
+		return err
+	}
+	url := endpoint
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url = strings.TrimSuffix(url, "/") + "/v1/traces"
+	}
+	req, err := http.NewRequest(http.MethodPost, url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("collector returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, err = io.Copy(ioutil.Discard, resp.Body)
+	return err
+}
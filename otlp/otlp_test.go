@@ -0,0 +1,106 @@
+package otlp
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var (
+	testTraceID = TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+	testStart   = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestCreateFromReport(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{
+		{
+			Name:     "package/one",
+			Duration: 3 * time.Second,
+			Tests: []gtr.Test{
+				{Name: "TestOne", Result: gtr.Pass, Duration: time.Second},
+				{Name: "TestOne/sub", Result: gtr.Fail, Duration: time.Second, FailureMessage: "boom", Output: []string{"one.go:1: boom"}},
+				{Name: "TestTwo", Result: gtr.Skip, SkipMessage: "not today"},
+			},
+		},
+	}}
+
+	type span struct {
+		ID, Parent, Name string
+		Start, End       string
+		Status           Status
+		Events           int
+	}
+	want := []span{
+		{"0000000000000001", "", "go test", "1640995200000000000", "1640995203000000000", Status{Code: statusError, Message: "tests failed"}, 0},
+		{"0000000000000002", "0000000000000001", "package/one", "1640995200000000000", "1640995203000000000", Status{Code: statusError, Message: "tests failed"}, 0},
+		{"0000000000000003", "0000000000000002", "TestOne", "1640995200000000000", "1640995201000000000", Status{Code: statusOK}, 0},
+		{"0000000000000004", "0000000000000003", "TestOne/sub", "1640995200000000000", "1640995201000000000", Status{Code: statusError, Message: "boom"}, 1},
+		{"0000000000000005", "0000000000000002", "TestTwo", "1640995200000000000", "1640995200000000000", Status{Message: "not today"}, 0},
+	}
+
+	traces := CreateFromReport(report, Options{TraceID: testTraceID, Start: testStart})
+	if len(traces.ResourceSpans) != 1 || len(traces.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("CreateFromReport returned unexpected traces: %+v", traces)
+	}
+	var got []span
+	for _, s := range traces.ResourceSpans[0].ScopeSpans[0].Spans {
+		if s.TraceID != "0102030405060708090a0b0c0d0e0f10" {
+			t.Errorf("span %q has trace id %q", s.Name, s.TraceID)
+		}
+		got = append(got, span{s.SpanID, s.ParentSpanID, s.Name, s.StartTimeUnixNano, s.EndTimeUnixNano, s.Status, len(s.Events)})
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CreateFromReport spans incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	service := traces.ResourceSpans[0].Resource.Attributes[0]
+	if service.Key != "service.name" || *service.Value.StringValue != DefaultServiceName {
+		t.Errorf("CreateFromReport resource attribute = %s=%v, want service.name=%s", service.Key, *service.Value.StringValue, DefaultServiceName)
+	}
+}
+
+func TestExport(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotTraces Traces
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		data, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &gotTraces); err != nil {
+			t.Errorf("error decoding request body: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	report := gtr.Report{Packages: []gtr.Package{{Name: "package/one", Tests: []gtr.Test{{Name: "TestOne", Result: gtr.Pass}}}}}
+	traces := CreateFromReport(report, Options{TraceID: testTraceID, Start: testStart})
+	if err := Export(server.Client(), server.URL+"/", map[string]string{"Authorization": "Bearer token"}, traces); err != nil {
+		t.Fatalf("Export error: %v", err)
+	}
+	if gotPath != "/v1/traces" {
+		t.Errorf("Export sent request to path %q, want /v1/traces", gotPath)
+	}
+	if gotAuth != "Bearer token" {
+		t.Errorf("Export sent Authorization header %q, want %q", gotAuth, "Bearer token")
+	}
+	if diff := cmp.Diff(traces, gotTraces); diff != "" {
+		t.Errorf("Export sent incorrect traces, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestExportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	if err := Export(server.Client(), server.URL, nil, Traces{}); err == nil {
+		t.Errorf("Export did not return an error for a failed request")
+	}
+}
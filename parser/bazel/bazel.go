@@ -0,0 +1,177 @@
+// Package bazel parses the test.xml files that Bazel writes for each test
+// target, so that the results of go_test targets run by Bazel can be merged
+// with the results of go test, for example by merging the reports with
+// gtr.Merge.
+//
+// The test.xml files written by rules_go contain a testsuite named after the
+// label of the test target, such as //foo/bar:bar_test, with a testcase for
+// each test. Each testsuite becomes a package named after the Bazel package of
+// its target, foo/bar in this example, optionally prefixed with an import path
+// using ImportPrefix, so that it matches the name go test uses for the same
+// package. The label of the target is kept in the TargetProperty of the
+// package. Test names are stripped of the classname rules_go adds to them.
+//
+// When a test doesn't write a test.xml file itself, Bazel generates one with
+// a single testcase named after the target that contains the test log. For
+// these files, the log is parsed as go test output instead, see
+// gotest.Parser.
+package bazel
+
+import (
+	"io"
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/parser"
+	"github.com/jstemmer/go-junit-report/v2/parser/gotest"
+	"github.com/jstemmer/go-junit-report/v2/parser/junitxml"
+)
+
+// TargetProperty is the name of the package property containing the label of
+// the Bazel test target.
+const TargetProperty = "bazel.target"
+
+func init() {
+	parser.Register("bazel", func() parser.Parser { return NewParser() })
+}
+
+// Option configures a Parser.
+type Option func(*Parser)
+
+// ImportPrefix sets the import path that is prefixed to the Bazel package of
+// each target to get the name of its package, usually the module path of the
+// repository.
+func ImportPrefix(prefix string) Option {
+	return func(p *Parser) {
+		p.importPrefix = strings.TrimSuffix(prefix, "/")
+	}
+}
+
+// Parser parses Bazel test.xml files.
+type Parser struct {
+	importPrefix string
+}
+
+// NewParser returns a new Bazel test.xml parser.
+func NewParser(options ...Option) *Parser {
+	p := &Parser{}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// Parse parses a Bazel test.xml file from the given io.Reader r and returns
+// the report it describes. Several files may be concatenated.
+func (p *Parser) Parse(r io.Reader) (gtr.Report, error) {
+	report, err := junitxml.NewParser().Parse(r)
+	if err != nil {
+		return report, err
+	}
+	for i, pkg := range report.Packages {
+		report.Packages[i] = p.convertPackage(pkg)
+	}
+	return report, nil
+}
+
+// convertPackage returns package pkg of a test.xml file, named after the Bazel
+// package of its target.
+func (p *Parser) convertPackage(pkg gtr.Package) gtr.Package {
+	label := pkg.Name
+	if isLabel(label) && len(pkg.Tests) == 1 && pkg.Tests[0].Name == label {
+		if parsed, ok := parseLog(pkg); ok {
+			pkg = parsed
+		}
+	}
+
+	pkg.Name = p.PackageName(label)
+	if isLabel(label) {
+		pkg.SetProperty(TargetProperty, label)
+	}
+	for i := range pkg.Tests {
+		pkg.Tests[i].Name = stripClassname(pkg.Tests[i].Name)
+		pkg.Tests[i].Level = strings.Count(pkg.Tests[i].Name, "/")
+	}
+	if pkg.BuildError.Name == label {
+		pkg.BuildError.Name = pkg.Name
+	}
+	if pkg.RunError.Name == label {
+		pkg.RunError.Name = pkg.Name
+	}
+	return pkg
+}
+
+// PackageName returns the name of the package of the Bazel target with the
+// given label, which is its Bazel package prefixed with the import prefix of
+// p. Names that aren't labels are returned unchanged.
+func (p *Parser) PackageName(label string) string {
+	if !isLabel(label) {
+		return label
+	}
+	name := label
+	if i := strings.Index(name, "//"); i >= 0 {
+		name = name[i+2:]
+	}
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		name = name[:i]
+	}
+	if p.importPrefix == "" {
+		return name
+	} else if name == "" {
+		return p.importPrefix
+	}
+	return p.importPrefix + "/" + name
+}
+
+// parseLog parses the test log in the output of package pkg of a test.xml file
+// generated by Bazel as go test output. It returns false if the log doesn't
+// contain any test results.
+func parseLog(pkg gtr.Package) (gtr.Package, bool) {
+	target := pkg.Tests[0]
+	output := append(append([]string(nil), pkg.Output...), target.Output...)
+	report, err := gotest.NewParser(gotest.PackageName(pkg.Name)).Parse(strings.NewReader(strings.Join(output, "\n")))
+	if err != nil || len(report.Packages) != 1 || len(report.Packages[0].Tests) == 0 {
+		return pkg, false
+	}
+
+	parsed := report.Packages[0]
+	parsed.Name = pkg.Name
+	parsed.Hostname = pkg.Hostname
+	parsed.Properties = pkg.Properties
+	if !pkg.Timestamp.IsZero() {
+		parsed.Timestamp = pkg.Timestamp
+	}
+	if pkg.Duration > 0 {
+		parsed.Duration = pkg.Duration
+	} else if target.Duration > 0 {
+		parsed.Duration = target.Duration
+	}
+	// A target can fail without any of its tests failing, e.g. when TestMain
+	// exits with a non-zero status.
+	if target.Result == gtr.Fail && (&gtr.Report{Packages: []gtr.Package{parsed}}).IsSuccessful() {
+		parsed.RunError = gtr.Error{Name: pkg.Name, Cause: target.FailureMessage}
+	}
+	return parsed, true
+}
+
+// stripClassname returns test name without the classname that rules_go adds
+// before the name of the test function, e.g. bar_test.TestBar becomes
+// TestBar.
+func stripClassname(name string) string {
+	i := strings.IndexByte(name, '.')
+	if i < 0 || strings.ContainsRune(name[:i], '/') {
+		return name
+	}
+	for _, prefix := range []string{"Test", "Benchmark", "Example", "Fuzz"} {
+		if strings.HasPrefix(name[i+1:], prefix) {
+			return name[i+1:]
+		}
+	}
+	return name
+}
+
+// isLabel reports whether name is the label of a Bazel target, such as
+// //foo/bar:bar_test or @repo//foo:foo_test.
+func isLabel(name string) bool {
+	return strings.HasPrefix(name, "//") || strings.HasPrefix(name, "@")
+}
@@ -0,0 +1,108 @@
+package bazel
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []gtr.Package
+	}{
+		{
+			"rules_go",
+			`<testsuites>
+	<testsuite errors="0" failures="1" skipped="0" tests="3" time="0.5" name="//foo/bar:bar_test">
+		<testcase classname="bar_test" name="TestPass" time="0.1"></testcase>
+		<testcase classname="bar_test" name="TestFail/sub" time="0.2"><failure message="Failed" type="">bar_test.go:10: boom</failure></testcase>
+		<testcase classname="bar_test" name="TestSkip" time="0"><skipped message="Skipped" type=""></skipped></testcase>
+	</testsuite>
+</testsuites>`,
+			[]gtr.Package{{
+				Name:       "example.com/repo/foo/bar",
+				Duration:   500 * time.Millisecond,
+				Properties: []gtr.Property{{Name: TargetProperty, Value: "//foo/bar:bar_test"}},
+				Tests: []gtr.Test{
+					{ID: 0, Name: "TestPass", Duration: 100 * time.Millisecond, Result: gtr.Pass},
+					{ID: 1, Name: "TestFail/sub", Level: 1, Duration: 200 * time.Millisecond, Result: gtr.Fail, Output: []string{"bar_test.go:10: boom"}},
+					{ID: 2, Name: "TestSkip", Result: gtr.Skip},
+				},
+			}},
+		},
+		{
+			"generated",
+			`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="//baz:baz_test" tests="1" failures="0" errors="1">
+    <testcase name="//baz:baz_test" status="run" duration="2" time="2"><error message="exited with error code 1"></error></testcase>
+      <system-out>
+Generated test.log (if the file is not UTF-8, then this may be unreadable):
+<![CDATA[=== RUN   TestOne
+--- PASS: TestOne (0.01s)
+=== RUN   TestTwo
+    baz_test.go:5: boom
+--- FAIL: TestTwo (0.02s)
+FAIL]]>
+      </system-out>
+    </testsuite>
+</testsuites>`,
+			[]gtr.Package{{
+				Name:        "example.com/repo/baz",
+				Duration:    2 * time.Second,
+				MaxParallel: 1,
+				Properties:  []gtr.Property{{Name: TargetProperty, Value: "//baz:baz_test"}},
+				Tests: []gtr.Test{
+					{ID: 1, Name: "TestOne", Duration: 10 * time.Millisecond, Result: gtr.Pass},
+					{ID: 2, Name: "TestTwo", Duration: 20 * time.Millisecond, Result: gtr.Fail, FailureMessage: "boom", Output: []string{"    baz_test.go:5: boom"}},
+				},
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := NewParser(ImportPrefix("example.com/repo/")).Parse(strings.NewReader(test.input))
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			for i := range report.Packages {
+				report.Packages[i].Output = nil
+				report.Packages[i].Timestamp = time.Time{}
+				for j := range report.Packages[i].Tests {
+					report.Packages[i].Tests[j].StartTime = time.Time{}
+					report.Packages[i].Tests[j].EndTime = time.Time{}
+				}
+			}
+			if diff := cmp.Diff(test.want, report.Packages, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Parse result incorrect, diff (-want +got):\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	tests := []struct {
+		prefix string
+		label  string
+		want   string
+	}{
+		{"", "//foo/bar:bar_test", "foo/bar"},
+		{"example.com/repo", "//foo/bar:bar_test", "example.com/repo/foo/bar"},
+		{"example.com/repo", "//:root_test", "example.com/repo"},
+		{"", "@other//pkg:pkg_test", "pkg"},
+		{"example.com/repo", "not/a/label", "not/a/label"},
+	}
+	for _, test := range tests {
+		if got := NewParser(ImportPrefix(test.prefix)).PackageName(test.label); got != test.want {
+			t.Errorf("PackageName(%q) with prefix %q = %q, want %q", test.label, test.prefix, got, test.want)
+		}
+	}
+}
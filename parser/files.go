@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// ParseFiles parses the given files concurrently and merges the resulting
+// reports into a single report using gtr.Merge, for example to convert the
+// logs of multiple CI shards at once. Each file is parsed by a new Parser
+// created by factory, using at most workers files at a time, or one per CPU if
+// workers is not positive. The reports are merged in the order of files, so the
+// result doesn't depend on which file is parsed first. If any of the files
+// can't be opened or parsed, the error of the first such file is returned.
+func ParseFiles(factory Factory, files []string, workers int) (gtr.Report, error) {
+	reports, err := ParseEach(factory, files, workers)
+	if err != nil {
+		return gtr.Report{}, err
+	}
+	return gtr.Merge(reports...), nil
+}
+
+// ParseEach parses the given files concurrently like ParseFiles, but returns
+// the report of each file in the order of files instead of merging them, for
+// example to merge them using gtr.MergeOptions.
+func ParseEach(factory Factory, files []string, workers int) ([]gtr.Report, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(files) {
+		workers = len(files)
+	}
+
+	reports := make([]gtr.Report, len(files))
+	errs := make([]error, len(files))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				reports[i], errs[i] = parseFile(factory(), files[i])
+			}
+		}()
+	}
+	for i := range files {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", files[i], err)
+		}
+	}
+	return reports, nil
+}
+
+func parseFile(p Parser, file string) (gtr.Report, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return gtr.Report{}, err
+	}
+	defer f.Close()
+	return p.Parse(f)
+}
+
+// Glob returns the names of the files matching the given patterns, using the
+// syntax of filepath.Glob. Files are returned in the order of the patterns
+// that match them, and in lexical order for each pattern, but only once.
+// Patterns without any special characters are returned unchanged, even if the
+// file doesn't exist. It's an error if a pattern doesn't match any files.
+func Glob(patterns ...string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(pattern); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %q", pattern)
+			}
+		}
+		for _, file := range matches {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	return files, nil
+}
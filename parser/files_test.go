@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// writeFiles writes each of the given contents to a file in a new temporary
+// directory, and returns the directory and file names. The caller should
+// remove the directory when done.
+func writeFiles(t *testing.T, contents ...string) (string, []string) {
+	dir, err := ioutil.TempDir("", "parser-files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for i, content := range contents {
+		file := filepath.Join(dir, string(rune('a'+i))+".log")
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+	return dir, files
+}
+
+func TestParseFiles(t *testing.T) {
+	dir, files := writeFiles(t, "one two", "three one", "four")
+	defer os.RemoveAll(dir)
+	factory := func() Parser { return lineParser{} }
+
+	want := gtr.Report{Packages: []gtr.Package{{Name: "one"}, {Name: "two"}, {Name: "three"}, {Name: "four"}}}
+	for _, workers := range []int{0, 1, 2, 10} {
+		got, err := ParseFiles(factory, files, workers)
+		if err != nil {
+			t.Fatalf("ParseFiles(workers=%d) error: %v", workers, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("ParseFiles(workers=%d) result incorrect, diff (-want +got):\n%s\n", workers, diff)
+		}
+	}
+
+	if _, err := ParseFiles(factory, append(files, files[0]+".missing"), 2); err == nil {
+		t.Errorf("ParseFiles with a missing file did not return an error")
+	}
+}
+
+func TestGlob(t *testing.T) {
+	dir, files := writeFiles(t, "a", "b", "c")
+	defer os.RemoveAll(dir)
+
+	got, err := Glob(files[1], filepath.Join(dir, "*.log"), "missing.log")
+	if err != nil {
+		t.Fatalf("Glob error: %v", err)
+	}
+	want := []string{files[1], files[0], files[2], "missing.log"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Glob result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	if _, err := Glob(filepath.Join(dir, "*.txt")); err == nil {
+		t.Errorf("Glob with a pattern that matches no files did not return an error")
+	}
+}
@@ -0,0 +1,273 @@
+// Package ginkgo parses the output of Go tests that run Ginkgo test suites.
+//
+// A Ginkgo suite runs inside a regular Go test, so its specs are invisible to
+// the gotest parser, which only sees the output of the Go test. The Parser in
+// this package parses go test output like the gotest parser, and then adds
+// every spec printed by Ginkgo as a subtest of the Go test that ran the
+// suite. Regular Go tests in the same run are left as they are.
+//
+// Specs are named by their full text, i.e. the texts of their containers and
+// the spec itself joined by spaces, the same way Ginkgo matches specs for the
+// -ginkgo.focus flag. Ginkgo only prints the name of passing specs when it
+// runs in verbose mode (-ginkgo.v), so passing specs are only added to the
+// report in that mode. Failed, skipped and pending specs are always added.
+package ginkgo
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/parser"
+	"github.com/jstemmer/go-junit-report/v2/parser/gotest"
+)
+
+// FailureType is the failure type of specs that failed.
+const FailureType = "ginkgo"
+
+var (
+	regexSuite     = regexp.MustCompile(`^Running Suite: `)
+	regexSeparator = regexp.MustCompile(`^-{10,}$`)
+	regexState     = regexp.MustCompile(`^([•✓SP])(?:\s+(?:\[([A-Z][A-Z ]*)\]|((?:Failure|Panic|Timeout|Interrupted)[^\[\]]*?)))?(?:\s+\[(\d+(?:\.\d+)?) seconds\])?$`)
+	regexLocation  = regexp.MustCompile(`^\S+\.go:\d+$`)
+	regexNodeType  = regexp.MustCompile(`\s*\[[A-Z][A-Za-z]*\]\s*`)
+	regexFailure   = regexp.MustCompile(`^\[(?:FAILED|PANICKED|TIMEDOUT|INTERRUPTED)\] (.+)$`)
+	regexSkipped   = regexp.MustCompile(`^\[SKIPPED\] (.+)$`)
+)
+
+func init() {
+	parser.Register("ginkgo", func() parser.Parser { return NewParser() })
+}
+
+// Parser is a Go test output parser that adds the specs of Ginkgo suites as
+// subtests.
+type Parser struct {
+	gp *gotest.Parser
+}
+
+// NewParser returns a new Ginkgo output parser. The options are passed to the
+// underlying gotest parser.
+func NewParser(options ...gotest.Option) *Parser {
+	return &Parser{gp: gotest.NewParser(options...)}
+}
+
+// Parse parses Go test output from the given io.Reader r and returns
+// gtr.Report, see ExpandSpecs.
+func (p *Parser) Parse(r io.Reader) (gtr.Report, error) {
+	report, err := p.gp.Parse(r)
+	if err != nil {
+		return report, err
+	}
+	return ExpandSpecs(report), nil
+}
+
+// Events returns the events of the last call to Parse, see
+// gotest.Parser.Events.
+func (p *Parser) Events() []gotest.Event {
+	return p.gp.Events()
+}
+
+// ExpandSpecs returns a copy of report r in which the specs of every Ginkgo
+// suite found in the output of a test are added as subtests of that test.
+func ExpandSpecs(r gtr.Report) gtr.Report {
+	packages := make([]gtr.Package, len(r.Packages))
+	for i, pkg := range r.Packages {
+		nextID := 0
+		for _, t := range pkg.Tests {
+			if t.ID >= nextID {
+				nextID = t.ID + 1
+			}
+		}
+
+		var tests []gtr.Test
+		for _, t := range pkg.Tests {
+			tests = append(tests, t)
+			for _, s := range parseSpecs(t.Output) {
+				test := gtr.NewTest(nextID, t.Name+"/"+s.name)
+				test.Level = t.Level + 1
+				test.Result = s.result
+				test.Duration = s.duration
+				test.Output = s.output
+				test.SkipMessage = s.skipMessage
+				if s.result == gtr.Fail {
+					test.FailureMessage, test.FailureType = s.failureMessage, FailureType
+				}
+				tests = append(tests, test)
+				nextID++
+			}
+		}
+		pkg.Tests = tests
+		packages[i] = pkg
+	}
+	r.Packages = packages
+	return r
+}
+
+// spec is a Ginkgo spec parsed from the output of a suite.
+type spec struct {
+	name           string
+	result         gtr.Result
+	duration       time.Duration
+	output         []string
+	skipMessage    string
+	failureMessage string
+}
+
+// parseSpecs returns the specs in the given output of a Ginkgo suite, or nil
+// if the output doesn't contain a Ginkgo suite. Ginkgo prints every spec it
+// reports on in a block delimited by lines of dashes, except for the first
+// spec, which directly follows the suite header.
+func parseSpecs(output []string) []spec {
+	var specs []spec
+	var block []string
+	inSuite := false
+	for _, line := range output {
+		if regexSuite.MatchString(line) {
+			block, inSuite = nil, true
+			continue
+		}
+		if !inSuite {
+			continue
+		}
+		if regexSeparator.MatchString(strings.TrimSpace(line)) {
+			if s, ok := parseBlock(block); ok {
+				specs = append(specs, s)
+			}
+			block = nil
+			continue
+		}
+		block = append(block, line)
+	}
+	return specs
+}
+
+// parseBlock parses a block of output containing a single spec. The block
+// starts with a line containing the state and duration of the spec, followed
+// by the texts and locations of its containers and the spec itself, and any
+// failure details after an empty line. In verbose mode, older Ginkgo versions
+// print the texts before the state of passing specs instead.
+func parseBlock(lines []string) (spec, bool) {
+	state := -1
+	var matches []string
+	for i, line := range lines {
+		if matches = regexState.FindStringSubmatch(strings.TrimSpace(line)); matches != nil {
+			state = i
+			break
+		}
+	}
+	if state < 0 {
+		return spec{}, false
+	}
+
+	texts, end := nodeTexts(lines[state+1:])
+	details := lines[state+1+end:]
+	if len(texts) == 0 {
+		start := state
+		for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+			start--
+		}
+		texts, _ = nodeTexts(lines[start:state])
+		details = nil
+	}
+	if len(texts) == 0 {
+		return spec{}, false
+	}
+
+	s := spec{name: specName(texts), result: gtr.Pass}
+	if matches[4] != "" {
+		seconds, _ := strconv.ParseFloat(matches[4], 64)
+		s.duration = time.Duration(seconds * float64(time.Second))
+	}
+
+	status := matches[2]
+	if status == "" && matches[3] != "" {
+		status = strings.Fields(matches[3])[0]
+	}
+	switch strings.ToUpper(status) {
+	case "FAILED", "FAILURE", "PANICKED", "PANIC", "TIMEDOUT", "TIMEOUT", "INTERRUPTED", "ABORTED":
+		s.result = gtr.Fail
+	case "SKIPPED", "SKIPPING":
+		s.result = gtr.Skip
+	case "PENDING":
+		s.result, s.skipMessage = gtr.Skip, "pending"
+	default:
+		switch matches[1] {
+		case "S":
+			s.result = gtr.Skip
+		case "P":
+			s.result, s.skipMessage = gtr.Skip, "pending"
+		}
+	}
+
+	s.output = trimEmpty(details)
+	if s.result == gtr.Fail {
+		s.failureMessage = failureMessage(s.output)
+	} else if s.result == gtr.Skip && s.skipMessage == "" {
+		s.skipMessage = findMessage(regexSkipped, s.output)
+	}
+	return s, true
+}
+
+// nodeTexts returns the texts of the containers and spec at the start of
+// lines, skipping their locations, and the number of lines they take up.
+func nodeTexts(lines []string) ([]string, int) {
+	var texts []string
+	i := 0
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			break
+		}
+		if !regexLocation.MatchString(line) {
+			texts = append(texts, line)
+		}
+	}
+	return texts, i
+}
+
+// specName returns the full text of a spec with the given node texts, without
+// the node type markers such as [It] that Ginkgo adds to some of them.
+func specName(texts []string) string {
+	name := strings.Join(texts, " ")
+	return strings.TrimSpace(regexNodeType.ReplaceAllString(name, " "))
+}
+
+// failureMessage returns the failure message in the given details of a
+// failed spec, or the first line if it doesn't have an explicit message.
+func failureMessage(details []string) string {
+	if msg := findMessage(regexFailure, details); msg != "" {
+		return msg
+	}
+	if len(details) > 0 {
+		return strings.TrimSpace(details[0])
+	}
+	return ""
+}
+
+// findMessage returns the first submatch of re in lines, or an empty string
+// if none of the lines match.
+func findMessage(re *regexp.Regexp, lines []string) string {
+	for _, line := range lines {
+		if matches := re.FindStringSubmatch(strings.TrimSpace(line)); matches != nil {
+			return matches[1]
+		}
+	}
+	return ""
+}
+
+// trimEmpty returns lines without leading and trailing empty lines.
+func trimEmpty(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return append([]string(nil), lines...)
+}
@@ -0,0 +1,128 @@
+package ginkgo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestParseV1Verbose(t *testing.T) {
+	input := `=== RUN   TestBooks
+Running Suite: Books Suite
+==========================
+Random Seed: 1640995200
+Will run 2 of 2 specs
+
+Books Categorizing book length
+  should be a novel
+  /home/user/books/books_test.go:20
+•
+------------------------------
+• Failure [0.001 seconds]
+Books
+/home/user/books/books_test.go:10
+  Categorizing book length
+  /home/user/books/books_test.go:15
+    should be a short story [It]
+    /home/user/books/books_test.go:25
+
+    Expected
+        <string>: NOVEL
+    to equal
+        <string>: SHORT STORY
+
+    /home/user/books/books_test.go:27
+------------------------------
+
+Ran 2 of 2 Specs in 0.002 seconds
+FAIL! -- 1 Passed | 1 Failed | 0 Pending | 0 Skipped
+--- FAIL: TestBooks (0.00s)
+FAIL
+FAIL	example.com/books	0.012s
+`
+	report, err := NewParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	want := []gtr.Test{
+		{ID: 1, Name: "TestBooks", Result: gtr.Fail},
+		{ID: 2, Name: "TestBooks/Books Categorizing book length should be a novel", Result: gtr.Pass, Level: 1},
+		{
+			ID:             3,
+			Name:           "TestBooks/Books Categorizing book length should be a short story",
+			Result:         gtr.Fail,
+			Level:          1,
+			Duration:       time.Millisecond,
+			FailureMessage: "Expected",
+			FailureType:    FailureType,
+			Output: []string{
+				"    Expected",
+				"        <string>: NOVEL",
+				"    to equal",
+				"        <string>: SHORT STORY",
+				"",
+				"    /home/user/books/books_test.go:27",
+			},
+		},
+	}
+	got := report.Packages[0].Tests
+	opts := cmpopts.IgnoreFields(gtr.Test{}, "Duration", "Data", "StartTime", "EndTime", "RunDuration", "WallDuration", "Output")
+	if diff := cmp.Diff(want, got, opts); diff != "" {
+		t.Errorf("Parse tests incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if diff := cmp.Diff(want[2].Output, got[2].Output); diff != "" {
+		t.Errorf("Parse failed spec output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if got[2].Duration != want[2].Duration {
+		t.Errorf("Parse failed spec duration = %v, want %v", got[2].Duration, want[2].Duration)
+	}
+}
+
+func TestExpandSpecsWithoutSuite(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{{
+		Name: "package/one",
+		Tests: []gtr.Test{{
+			Name:   "TestOne",
+			Result: gtr.Pass,
+			Output: []string{"------------------------------", "• [0.001 seconds]", "not a spec", "------------------------------"},
+		}},
+	}}}
+	got := ExpandSpecs(report)
+	if diff := cmp.Diff(report, got); diff != "" {
+		t.Errorf("ExpandSpecs changed a report without Ginkgo suites, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestParseBlockState(t *testing.T) {
+	tests := []struct {
+		state       string
+		result      gtr.Result
+		duration    time.Duration
+		skipMessage string
+	}{
+		{"• [0.250 seconds]", gtr.Pass, 250 * time.Millisecond, ""},
+		{"✓ [1.5 seconds]", gtr.Pass, 1500 * time.Millisecond, ""},
+		{"• [PANICKED] [0.001 seconds]", gtr.Fail, time.Millisecond, ""},
+		{"• [TIMEDOUT] [2.000 seconds]", gtr.Fail, 2 * time.Second, ""},
+		{"• Failure in Spec Setup (BeforeEach) [0.001 seconds]", gtr.Fail, time.Millisecond, ""},
+		{"S [SKIPPING] [0.000 seconds]", gtr.Skip, 0, ""},
+		{"P [PENDING]", gtr.Skip, 0, "pending"},
+	}
+
+	for _, test := range tests {
+		s, ok := parseBlock([]string{test.state, "Books [It] works", "/home/user/books/books_test.go:20"})
+		if !ok {
+			t.Errorf("parseBlock(%q) did not find a spec", test.state)
+			continue
+		}
+		if s.name != "Books works" || s.result != test.result || s.duration != test.duration || s.skipMessage != test.skipMessage {
+			t.Errorf("parseBlock(%q) = %+v, want result %v, duration %v and skip message %q", test.state, s, test.result, test.duration, test.skipMessage)
+		}
+	}
+}
@@ -18,6 +18,11 @@ type Benchmark struct {
 	MBPerSec    float64
 	BytesPerOp  int64
 	AllocsPerOp int64
+
+	// Metrics contains the custom metrics reported by b.ReportMetric, such
+	// as requests/s or p99-ns, by unit. Metrics that have a field of their
+	// own, such as ns/op, are not included.
+	Metrics map[string]float64
 }
 
 // ApproximateDuration returns the duration calculated by multiplying the
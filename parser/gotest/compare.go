@@ -0,0 +1,99 @@
+package gotest
+
+import (
+	"fmt"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// BenchmarkDelta describes how a benchmark changed between two reports. The
+// deltas are relative to the old benchmark, e.g. a NsPerOp of 0.1 means the
+// new benchmark is 10% slower. Deltas are 0 when the old value is 0.
+type BenchmarkDelta struct {
+	Package string
+	Name    string
+	Old     Benchmark
+	New     Benchmark
+
+	NsPerOp     float64
+	BytesPerOp  float64
+	AllocsPerOp float64
+}
+
+// Regressed returns true if any of the deltas exceeds threshold.
+func (d BenchmarkDelta) Regressed(threshold float64) bool {
+	return d.NsPerOp > threshold || d.BytesPerOp > threshold || d.AllocsPerOp > threshold
+}
+
+// CompareBenchmarks pairs the benchmarks in report head with the benchmarks of
+// the same name in the same package in report base and returns the deltas for
+// each pair, in the order they appear in report head. Benchmarks that don't
+// appear in both reports are ignored.
+//
+// CompareBenchmarks also returns a copy of report head in which each benchmark
+// that regressed by more than threshold is marked as failed and has the
+// regression added to its output. A threshold of 0 or less disables this.
+func CompareBenchmarks(base, head gtr.Report, threshold float64) (gtr.Report, []BenchmarkDelta) {
+	type key struct{ pkg, name string }
+	baseline := make(map[key]Benchmark)
+	for _, pkg := range base.Packages {
+		for _, test := range pkg.Tests {
+			if bm, ok := GetBenchmarkData(test); ok {
+				baseline[key{pkg.Name, test.Name}] = bm
+			}
+		}
+	}
+
+	var deltas []BenchmarkDelta
+	result := head
+	result.Packages = make([]gtr.Package, len(head.Packages))
+	for i, pkg := range head.Packages {
+		tests := make([]gtr.Test, len(pkg.Tests))
+		copy(tests, pkg.Tests)
+		for j := range tests {
+			bm, ok := GetBenchmarkData(tests[j])
+			if !ok {
+				continue
+			}
+			oldBm, ok := baseline[key{pkg.Name, tests[j].Name}]
+			if !ok {
+				continue
+			}
+			d := BenchmarkDelta{
+				Package:     pkg.Name,
+				Name:        tests[j].Name,
+				Old:         oldBm,
+				New:         bm,
+				NsPerOp:     relativeDelta(oldBm.NsPerOp, bm.NsPerOp),
+				BytesPerOp:  relativeDelta(float64(oldBm.BytesPerOp), float64(bm.BytesPerOp)),
+				AllocsPerOp: relativeDelta(float64(oldBm.AllocsPerOp), float64(bm.AllocsPerOp)),
+			}
+			deltas = append(deltas, d)
+			if threshold > 0 && d.Regressed(threshold) {
+				tests[j].Result = gtr.Fail
+				tests[j].Output = append(append([]string(nil), tests[j].Output...), regressionOutput(d)...)
+			}
+		}
+		pkg.Tests = tests
+		result.Packages[i] = pkg
+	}
+	return result, deltas
+}
+
+// relativeDelta returns the change from base to head relative to base, or 0 if
+// base is 0.
+func relativeDelta(base, head float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return (head - base) / base
+}
+
+// regressionOutput returns output lines describing benchmark regression d.
+func regressionOutput(d BenchmarkDelta) []string {
+	return []string{
+		fmt.Sprintf("benchmark regression: ns/op %g -> %g (%+.1f%%)", d.Old.NsPerOp, d.New.NsPerOp, d.NsPerOp*100),
+		fmt.Sprintf("benchmark regression: B/op %d -> %d (%+.1f%%)", d.Old.BytesPerOp, d.New.BytesPerOp, d.BytesPerOp*100),
+		fmt.Sprintf("benchmark regression: allocs/op %d -> %d (%+.1f%%)", d.Old.AllocsPerOp, d.New.AllocsPerOp, d.AllocsPerOp*100),
+	}
+}
@@ -0,0 +1,71 @@
+package gotest
+
+import (
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func benchmarkReport(benchmarks map[string]Benchmark, names ...string) gtr.Report {
+	pkg := gtr.Package{Name: "package/name"}
+	for i, name := range names {
+		test := gtr.NewTest(i+1, name)
+		test.Result = gtr.Pass
+		SetBenchmarkData(&test, benchmarks[name])
+		pkg.Tests = append(pkg.Tests, test)
+	}
+	return gtr.Report{Packages: []gtr.Package{pkg}}
+}
+
+func TestCompareBenchmarks(t *testing.T) {
+	old := benchmarkReport(map[string]Benchmark{
+		"BenchmarkFast": {NsPerOp: 100, BytesPerOp: 10, AllocsPerOp: 2},
+		"BenchmarkSlow": {NsPerOp: 100, BytesPerOp: 10, AllocsPerOp: 2},
+		"BenchmarkOld":  {NsPerOp: 100},
+	}, "BenchmarkFast", "BenchmarkSlow", "BenchmarkOld")
+	new := benchmarkReport(map[string]Benchmark{
+		"BenchmarkFast": {NsPerOp: 105, BytesPerOp: 10, AllocsPerOp: 1},
+		"BenchmarkSlow": {NsPerOp: 150, BytesPerOp: 10, AllocsPerOp: 2},
+		"BenchmarkNew":  {NsPerOp: 100},
+	}, "BenchmarkFast", "BenchmarkSlow", "BenchmarkNew")
+
+	report, deltas := CompareBenchmarks(old, new, 0.1)
+
+	wantDeltas := []BenchmarkDelta{
+		{
+			Package:     "package/name",
+			Name:        "BenchmarkFast",
+			Old:         Benchmark{NsPerOp: 100, BytesPerOp: 10, AllocsPerOp: 2},
+			New:         Benchmark{NsPerOp: 105, BytesPerOp: 10, AllocsPerOp: 1},
+			NsPerOp:     0.05,
+			AllocsPerOp: -0.5,
+		},
+		{
+			Package: "package/name",
+			Name:    "BenchmarkSlow",
+			Old:     Benchmark{NsPerOp: 100, BytesPerOp: 10, AllocsPerOp: 2},
+			New:     Benchmark{NsPerOp: 150, BytesPerOp: 10, AllocsPerOp: 2},
+			NsPerOp: 0.5,
+		},
+	}
+	if diff := cmp.Diff(wantDeltas, deltas); diff != "" {
+		t.Errorf("CompareBenchmarks deltas incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	var results []gtr.Result
+	for _, test := range report.Packages[0].Tests {
+		results = append(results, test.Result)
+	}
+	if diff := cmp.Diff([]gtr.Result{gtr.Pass, gtr.Fail, gtr.Pass}, results); diff != "" {
+		t.Errorf("CompareBenchmarks results incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	wantOutput := "benchmark regression: ns/op 100 -> 150 (+50.0%)"
+	if output := report.Packages[0].Tests[1].Output; len(output) == 0 || output[0] != wantOutput {
+		t.Errorf("CompareBenchmarks output incorrect, got %q, want first line %q", output, wantOutput)
+	}
+	if new.Packages[0].Tests[1].Result != gtr.Pass {
+		t.Errorf("CompareBenchmarks modified its input report")
+	}
+}
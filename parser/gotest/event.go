@@ -16,6 +16,8 @@ type Event struct {
 	Duration time.Duration `json:"duration,omitempty"`
 	Data     string        `json:"data,omitempty"`
 	Indent   int           `json:"indent,omitempty"`
+	Stream   string        `json:"stream,omitempty"` // "stderr" for output written to stderr
+	Time     time.Time     `json:"time,omitempty"`
 
 	// Code coverage
 	CovPct      float64  `json:"coverage_percentage,omitempty"`
@@ -27,6 +29,16 @@ type Event struct {
 	MBPerSec    float64 `json:"benchmark_mb_per_sec,omitempty"`
 	BytesPerOp  int64   `json:"benchmark_bytes_per_op,omitempty"`
 	AllocsPerOp int64   `json:"benchmark_allocs_per_op,omitempty"`
+
+	// BenchMetrics contains the custom metrics of a benchmark reported by
+	// b.ReportMetric, by unit.
+	BenchMetrics map[string]float64 `json:"benchmark_metrics,omitempty"`
+
+	// Fuzzing
+	FuzzCorpus         int   `json:"fuzz_corpus,omitempty"`
+	FuzzExecs          int64 `json:"fuzz_execs,omitempty"`
+	FuzzExecsPerSec    int64 `json:"fuzz_execs_per_sec,omitempty"`
+	FuzzNewInteresting int   `json:"fuzz_new_interesting,omitempty"`
 }
 
 func (e *Event) applyMetadata(m *reader.Metadata) {
@@ -34,4 +46,13 @@ func (e *Event) applyMetadata(m *reader.Metadata) {
 		return
 	}
 	e.Package = m.Package
+	e.Time = m.Time
+	if e.Type == "output" && m.Test != "" {
+		// The test that printed the output is known, so it doesn't need to
+		// be derived from the preceding events.
+		e.Name = m.Test
+	}
+	if e.Type == "output" {
+		e.Stream = m.Stream
+	}
 }
@@ -0,0 +1,77 @@
+package gotest
+
+import (
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+const (
+	exampleKey = "gotest.example"
+
+	// ExampleFailureType is the failure type of examples whose output didn't
+	// match their expected output.
+	ExampleFailureType = "example"
+)
+
+// ExampleMismatch contains the actual and expected output of an example that
+// failed because its output didn't match, and is intended to be used as extra
+// data in a gtr.Test.
+type ExampleMismatch struct {
+	Got       []string
+	Want      []string
+	Unordered bool // true if the example has an "Unordered output:" comment
+}
+
+// GetExampleData is a helper function that returns the output mismatch
+// contained in the data field of the given gtr.Test t. If no (valid) mismatch
+// is present, ok will be set to false.
+func GetExampleData(t gtr.Test) (m ExampleMismatch, ok bool) {
+	if t.Data != nil {
+		if data, exists := t.Data[exampleKey]; exists {
+			m, ok := data.(ExampleMismatch)
+			return m, ok
+		}
+	}
+	return ExampleMismatch{}, false
+}
+
+// SetExampleData is a helper function that writes the output mismatch m to
+// the data field of the given gtr.Test t.
+func SetExampleData(t *gtr.Test, m ExampleMismatch) {
+	if t.Data != nil {
+		t.Data[exampleKey] = m
+	}
+}
+
+// isExample returns true if name is the name of an example function.
+func isExample(name string) bool {
+	return strings.HasPrefix(name, "Example") && !strings.Contains(name, "/")
+}
+
+// parseExampleMismatch returns the got and want blocks printed by go test
+// after the result of an example whose output didn't match. It returns false
+// if output doesn't contain these blocks.
+func parseExampleMismatch(output []string) (ExampleMismatch, bool) {
+	var m ExampleMismatch
+	start := -1
+	for i, line := range output {
+		if line == "got:" {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return m, false
+	}
+	for i := start + 1; i < len(output); i++ {
+		switch output[i] {
+		case "want:", "want (unordered):":
+			m.Got = append([]string(nil), output[start+1:i]...)
+			m.Want = append([]string(nil), output[i+1:]...)
+			m.Unordered = output[i] == "want (unordered):"
+			return m, true
+		}
+	}
+	return m, false
+}
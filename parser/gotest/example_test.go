@@ -0,0 +1,79 @@
+package gotest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseExamples(t *testing.T) {
+	input := strings.Join([]string{
+		"=== RUN   TestPass",
+		"--- PASS: TestPass (0.00s)",
+		"=== RUN   ExampleHello",
+		"--- FAIL: ExampleHello (0.00s)",
+		"got:",
+		"hello",
+		"want:",
+		"bye",
+		"=== RUN   ExampleSet",
+		"--- FAIL: ExampleSet (0.00s)",
+		"got:",
+		"b",
+		"a",
+		"want (unordered):",
+		"a",
+		"c",
+		"=== RUN   ExamplePass",
+		"--- PASS: ExamplePass (0.00s)",
+		"FAIL",
+		"exit status 1",
+		"FAIL\tpackage/examples\t0.001s",
+	}, "\n")
+
+	report, err := NewParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	tests := report.Packages[0].Tests
+	if len(tests) != 4 {
+		t.Fatalf("Parse returned %d tests, want 4", len(tests))
+	}
+	wantKinds := []gtr.TestKind{gtr.KindTest, gtr.KindExample, gtr.KindExample, gtr.KindExample}
+	for i, test := range tests {
+		if test.Kind != wantKinds[i] {
+			t.Errorf("Kind of %s = %q, want %q", test.Name, test.Kind, wantKinds[i])
+		}
+	}
+
+	wantMismatches := []struct {
+		test gtr.Test
+		want ExampleMismatch
+	}{
+		{tests[1], ExampleMismatch{Got: []string{"hello"}, Want: []string{"bye"}}},
+		{tests[2], ExampleMismatch{Got: []string{"b", "a"}, Want: []string{"a", "c"}, Unordered: true}},
+	}
+	for _, m := range wantMismatches {
+		got, ok := GetExampleData(m.test)
+		if !ok {
+			t.Errorf("GetExampleData(%s) returned false, want true", m.test.Name)
+			continue
+		}
+		if diff := cmp.Diff(m.want, got); diff != "" {
+			t.Errorf("GetExampleData(%s) incorrect, diff (-want +got):\n%s\n", m.test.Name, diff)
+		}
+		if m.test.FailureType != ExampleFailureType {
+			t.Errorf("FailureType of %s = %q, want %q", m.test.Name, m.test.FailureType, ExampleFailureType)
+		}
+	}
+	if _, ok := GetExampleData(tests[3]); ok {
+		t.Errorf("GetExampleData(%s) returned true for passing example", tests[3].Name)
+	}
+	if diff := cmp.Diff([]string{"exit status 1"}, report.Packages[0].Output); diff != "" {
+		t.Errorf("Package output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
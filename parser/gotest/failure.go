@@ -0,0 +1,108 @@
+package gotest
+
+import "strings"
+
+// FailureExtractor extracts the primary failure message from the output of a
+// failed test. The message and type are stored in the FailureMessage and
+// FailureType fields of the gtr.Test.
+type FailureExtractor interface {
+	// ExtractFailure returns the failure message and type found in output.
+	// It returns false if output doesn't contain a failure it recognizes.
+	ExtractFailure(output []string) (message, typ string, ok bool)
+}
+
+// FailureExtractorFunc is an adapter to allow the use of ordinary functions
+// as a FailureExtractor.
+type FailureExtractorFunc func(output []string) (message, typ string, ok bool)
+
+// ExtractFailure returns f(output).
+func (f FailureExtractorFunc) ExtractFailure(output []string) (message, typ string, ok bool) {
+	return f(output)
+}
+
+var (
+	// TestifyExtractor extracts the Error field of a failed
+	// github.com/stretchr/testify assertion. The failure type is "testify".
+	TestifyExtractor FailureExtractor = FailureExtractorFunc(testifyFailure)
+
+	// LogLineExtractor extracts the message of the first line logged by the
+	// test using t.Error, t.Fatal or t.Log, without its file:line prefix. The
+	// failure type is empty.
+	LogLineExtractor FailureExtractor = FailureExtractorFunc(logLineFailure)
+)
+
+// defaultFailureExtractors are the extractors used when the FailureExtractors
+// option is not set.
+var defaultFailureExtractors = []FailureExtractor{TestifyExtractor, LogLineExtractor}
+
+// FailureExtractors is an Option that sets the extractors used to determine
+// the failure message of failed tests. The extractors are tried in order and
+// the result of the first one that recognizes the output is used. By default
+// TestifyExtractor and LogLineExtractor are used. Passing no extractors
+// disables failure message extraction.
+func FailureExtractors(extractors ...FailureExtractor) Option {
+	return func(p *Parser) {
+		p.failureExtractors = append([]FailureExtractor{}, extractors...)
+	}
+}
+
+// testifyFailure returns the message of the first Error field in output. A
+// testify failure is logged as a number of tab separated fields, where lines
+// with an empty field name continue the previous field. The message ends at
+// the next field or at the first empty line, which in case of a "Not equal"
+// error is followed by a diff.
+func testifyFailure(output []string) (string, string, bool) {
+	var lines []string
+	found := false
+	for _, line := range output {
+		line = strings.TrimLeft(line, " ")
+		if !strings.HasPrefix(line, "\t") {
+			if found {
+				break
+			}
+			continue
+		}
+		fields := strings.SplitN(line[1:], "\t", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		name, value := strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1])
+		if !found {
+			found = name == "Error:"
+			if found {
+				lines = append(lines, value)
+			}
+			continue
+		}
+		if name != "" || value == "" {
+			break
+		}
+		lines = append(lines, value)
+	}
+	if !found {
+		return "", "", false
+	}
+	return strings.Join(lines, "\n"), "testify", true
+}
+
+// logLineFailure returns the message of the first non-empty line logged in
+// output.
+func logLineFailure(output []string) (string, string, bool) {
+	for _, line := range output {
+		if m := regexLogLine.FindStringSubmatch(line); m != nil && strings.TrimSpace(m[1]) != "" {
+			return strings.TrimSpace(m[1]), "", true
+		}
+	}
+	return "", "", false
+}
+
+// extractFailure returns the failure message and type of the first extractor
+// that recognizes output.
+func extractFailure(extractors []FailureExtractor, output []string) (string, string) {
+	for _, e := range extractors {
+		if message, typ, ok := e.ExtractFailure(output); ok {
+			return message, typ
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,113 @@
+package gotest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFailureExtractors(t *testing.T) {
+	testify := []string{
+		"    foo_test.go:12: ",
+		"        \tError Trace:\tfoo_test.go:12",
+		"        \tError:      \tNot equal: ",
+		"        \t            \texpected: 1",
+		"        \t            \tactual  : 2",
+		"        \t            \t",
+		"        \t            \tDiff:",
+		"        \tTest:       \tTestFoo",
+	}
+
+	tests := []struct {
+		name      string
+		extractor FailureExtractor
+		output    []string
+		message   string
+		typ       string
+		ok        bool
+	}{
+		{"testify", TestifyExtractor, testify, "Not equal:\nexpected: 1\nactual  : 2", "testify", true},
+		{"testify-no-match", TestifyExtractor, []string{"    foo_test.go:12: failed"}, "", "", false},
+		{"log-line", LogLineExtractor, []string{"output", "    foo_test.go:12: failed", "    foo_test.go:13: again"}, "failed", "", true},
+		{"log-line-testify", LogLineExtractor, testify, "", "", false},
+		{"log-line-no-match", LogLineExtractor, []string{"output"}, "", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			message, typ, ok := test.extractor.ExtractFailure(test.output)
+			if message != test.message || typ != test.typ || ok != test.ok {
+				t.Errorf("ExtractFailure() = (%q, %q, %v), want (%q, %q, %v)", message, typ, ok, test.message, test.typ, test.ok)
+			}
+		})
+	}
+}
+
+func TestParentFailureMessage(t *testing.T) {
+	input := `=== RUN   TestParent
+    parent_test.go:6: setting up
+=== RUN   TestParent/a
+    parent_test.go:9: boom
+=== RUN   TestParent/b
+=== RUN   TestParent/c
+    parent_test.go:9: boom
+--- FAIL: TestParent (0.00s)
+    --- FAIL: TestParent/a (0.00s)
+    --- PASS: TestParent/b (0.00s)
+    --- FAIL: TestParent/c (0.00s)
+=== RUN   TestOne
+    one_test.go:6: boom
+--- FAIL: TestOne (0.00s)
+FAIL
+FAIL	package/fail	0.001s
+`
+	report, err := NewParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	want := map[string]string{
+		"TestParent":   "",
+		"TestParent/a": "boom",
+		"TestParent/b": "",
+		"TestParent/c": "boom",
+		"TestOne":      "boom",
+	}
+	got := make(map[string]string)
+	for _, test := range report.Packages[0].Tests {
+		got[test.Name] = test.FailureMessage
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("FailureMessage incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestFailureExtractorsOption(t *testing.T) {
+	input := "=== RUN   TestFail\n    fail_test.go:6: boom\n--- FAIL: TestFail (0.00s)\nFAIL\nFAIL\tpackage/fail\t0.001s\n"
+	custom := FailureExtractorFunc(func(output []string) (string, string, bool) {
+		return "custom", "custom-type", true
+	})
+
+	tests := []struct {
+		name    string
+		options []Option
+		want    gtr.Test
+	}{
+		{"default", nil, gtr.Test{FailureMessage: "boom"}},
+		{"custom", []Option{FailureExtractors(custom)}, gtr.Test{FailureMessage: "custom", FailureType: "custom-type"}},
+		{"disabled", []Option{FailureExtractors()}, gtr.Test{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := NewParser(test.options...).Parse(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			got := report.Packages[0].Tests[0]
+			if got.FailureMessage != test.want.FailureMessage || got.FailureType != test.want.FailureType {
+				t.Errorf("Parse failure = (%q, %q), want (%q, %q)", got.FailureMessage, got.FailureType, test.want.FailureMessage, test.want.FailureType)
+			}
+		})
+	}
+}
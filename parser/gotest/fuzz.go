@@ -0,0 +1,44 @@
+package gotest
+
+import (
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+const (
+	fuzzKey = "gotest.fuzz"
+)
+
+// Fuzz contains the results of running a fuzz test with fuzzing enabled, i.e.
+// using `go test -fuzz`, and is intended to be used as extra data in a
+// gtr.Test.
+type Fuzz struct {
+	SeedCorpus     int   // number of seed corpus and cached inputs
+	Execs          int64 // total number of executions
+	ExecsPerSec    int64 // executions per second at the last progress report
+	NewInteresting int   // number of new interesting inputs found
+
+	// FailingInput is the path of the failing input written by go test, or
+	// empty if fuzzing didn't find a failing input.
+	FailingInput string
+}
+
+// GetFuzzData is a helper function that returns the fuzz results contained in
+// the data field of the given gtr.Test t. If no (valid) fuzz results are
+// present, ok will be set to false.
+func GetFuzzData(t gtr.Test) (f Fuzz, ok bool) {
+	if t.Data != nil {
+		if data, exists := t.Data[fuzzKey]; exists {
+			f, ok := data.(Fuzz)
+			return f, ok
+		}
+	}
+	return Fuzz{}, false
+}
+
+// SetFuzzData is a helper function that writes the fuzz results f to the data
+// field of the given gtr.Test t.
+func SetFuzzData(t *gtr.Test, f Fuzz) {
+	if t.Data != nil {
+		t.Data[fuzzKey] = f
+	}
+}
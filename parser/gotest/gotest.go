@@ -11,9 +11,15 @@ import (
 	"time"
 
 	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/parser"
 	"github.com/jstemmer/go-junit-report/v2/parser/gotest/internal/reader"
 )
 
+func init() {
+	parser.Register("gotest", func() parser.Parser { return NewParser() })
+	parser.Register("gojson", func() parser.Parser { return NewJSONParser() })
+}
+
 const (
 	// maxLineSize is the maximum amount of bytes we'll read for a single line.
 	// Lines longer than maxLineSize will be truncated.
@@ -21,13 +27,24 @@ const (
 )
 
 var (
-	// regexBenchInfo captures 3-5 groups: benchmark name, number of times ran, ns/op (with or without decimal), MB/sec (optional), B/op (optional), and allocs/op (optional).
+	// regexBenchSummary captures 3 groups: benchmark name, number of times ran
+	// and the metrics, which are pairs of a value and a unit such as ns/op,
+	// MB/s, B/op, allocs/op or a custom unit reported by b.ReportMetric.
 	regexBenchmark    = regexp.MustCompile(`^(Benchmark[^ -]+)$`)
-	regexBenchSummary = regexp.MustCompile(`^(Benchmark[^ -]+)(?:-\d+\s+|\s+)(\d+)\s+(\d+|\d+\.\d+)\sns\/op(?:\s+(\d+|\d+\.\d+)\sMB\/s)?(?:\s+(\d+)\sB\/op)?(?:\s+(\d+)\sallocs/op)?`)
+	regexBenchSummary = regexp.MustCompile(`^(Benchmark[^ -]+)(?:-\d+\s+|\s+)(\d+)((?:\s+[-+]?\d+(?:\.\d+)?(?:[eE][-+]?\d+)?\s+[^\s\d]\S*)+)\s*$`)
 	regexCoverage     = regexp.MustCompile(`^coverage:\s+(\d+|\d+\.\d+)%\s+of\s+statements(?:\sin\s(.+))?$`)
-	regexEndBenchmark = regexp.MustCompile(`^--- (BENCH|FAIL|SKIP): (Benchmark[^ -]+)(?:-\d+)?$`)
-	regexEndTest      = regexp.MustCompile(`((?:    )*)--- (PASS|FAIL|SKIP): ([^ ]+) \((\d+\.\d+)(?: seconds|s)\)`)
-	regexStatus       = regexp.MustCompile(`^(PASS|FAIL|SKIP)$`)
+	regexEndBenchmark = regexp.MustCompile(`^(?:    )*--- (BENCH|FAIL|SKIP): (Benchmark[^ -]+)(?:-\d+)?$`)
+	regexEndTest      = regexp.MustCompile(`((?:    )*)--- (PASS|FAIL|SKIP): (.+) \((\d+\.\d+)(?: seconds|s)\)(.*)$`)
+	regexFuzzBaseline = regexp.MustCompile(`^fuzz: elapsed: \S+, gathering baseline coverage: \d+/(\d+) completed`)
+	regexFuzzExecs    = regexp.MustCompile(`^fuzz: elapsed: \S+, execs: (\d+) \((\d+)/sec\), new interesting: (\d+)`)
+	regexFuzzFailure  = regexp.MustCompile(`^\s*Failing input written to (\S*/([^/\s]+)/[^/\s]+)$`)
+	regexPanic        = regexp.MustCompile(`^panic: (.+?)(?: \[recovered(?:, repanicked)?\])?$`)
+	regexPanicTest    = regexp.MustCompile(`^(?:[^\s(]*\.)?((?:Test|Benchmark|Fuzz|Example)[^.(\s]*)[.(]`)
+	regexRunningTest  = regexp.MustCompile(`^\t\t(\S.*?) \((\S+)\)$`)
+	regexShuffle      = regexp.MustCompile(`^-test\.shuffle (-?\d+)$`)
+	regexTimeout      = regexp.MustCompile(`^test timed out after (\S+)$`)
+	regexExitStatus   = regexp.MustCompile(`^exit status \d+$`)
+	regexStatus       = regexp.MustCompile(`^(PASS|FAIL|SKIP)\s*$`)
 	regexSummary      = regexp.MustCompile(`` +
 		// 1: result
 		`^(\?|ok|FAIL)` +
@@ -43,9 +60,18 @@ var (
 		// 7: [status message] (optional)
 		`(?:\s+(\[[^\]]+\]))?` +
 		`$`)
+
+	// defaultInfraErrorPatterns match output caused by problems with the
+	// machine running the tests rather than by the tests themselves.
+	defaultInfraErrorPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`too many open files`),
+		regexp.MustCompile(`resource temporarily unavailable`),
+		regexp.MustCompile(`cannot allocate memory`),
+		regexp.MustCompile(`no space left on device`),
+	}
 )
 
-// Option defines options that can be passed to gotest.New.
+// Option defines options that can be passed to NewParser and NewJSONParser.
 type Option func(*Parser)
 
 // PackageName is an Option that sets the default package name to use when it
@@ -58,13 +84,116 @@ func PackageName(name string) Option {
 
 // TimestampFunc is an Option that sets the timestamp function that is used to
 // determine the current time when creating the Report. This can be used to
-// override the default behaviour of using time.Now().
+// override the default behaviour of using time.Now(), or the time of the first
+// event of each package when it is known, as is the case for `go test -json`
+// output.
 func TimestampFunc(f func() time.Time) Option {
 	return func(p *Parser) {
 		p.timestampFunc = f
 	}
 }
 
+// Clock is an Option that sets the function used to timestamp events that
+// don't have a time of their own, which includes all events parsed from
+// regular `go test` output. Events are timestamped when they are parsed, so
+// this is mostly useful when parsing output as it's being produced, for example
+// using ParseLine. The timestamps are used to set the StartTime and EndTime of
+// packages and tests in the report.
+func Clock(f func() time.Time) Option {
+	return func(p *Parser) {
+		p.clock = f
+	}
+}
+
+// InfraErrorPatterns is an Option that adds patterns used to recognize
+// infrastructure errors, in addition to the built-in patterns for errors such
+// as "too many open files" and "fork/exec: resource temporarily unavailable".
+// Output lines outside of any test that match one of these patterns cause the
+// package to be reported with a RunError of kind gtr.ErrorKindInfra.
+func InfraErrorPatterns(patterns ...*regexp.Regexp) Option {
+	return func(p *Parser) {
+		p.infraPatterns = append(p.infraPatterns, patterns...)
+	}
+}
+
+// PackageProperties is an Option that adds the given properties to every
+// package in the report, for example properties describing the environment
+// the tests ran in, see gtr.EnvironmentProperties.
+func PackageProperties(props ...gtr.Property) Option {
+	return func(p *Parser) {
+		p.properties = append(p.properties, props...)
+	}
+}
+
+// Hostname is an Option that sets the hostname of the report and of every
+// package in it, see gtr.Report.SetHostname.
+func Hostname(name string) Option {
+	return func(p *Parser) {
+		p.hostname = name
+	}
+}
+
+// Shard is an Option that sets the shard of the report and of every package in
+// it to the zero-based index of count shards, see gtr.Report.SetShard. The
+// shard of the current CI job can be found using gtr.EnvironmentShard.
+func Shard(index, count int) Option {
+	return func(p *Parser) {
+		p.shardIndex, p.shardCount = index, count
+	}
+}
+
+// EventHandler is an Option that sets a function that is called with every
+// event as soon as it has been parsed, in addition to adding it to the report.
+// This makes it possible to act on test results while output is still being
+// parsed, for example to show test progress in real time.
+func EventHandler(f func(Event)) Option {
+	return func(p *Parser) {
+		p.eventHandler = f
+	}
+}
+
+// PackageHandler is an Option that sets a function that is called with every
+// package as soon as its summary line has been parsed. Packages that are only
+// completed when the report is built, such as packages without a summary, are
+// not passed to f. This makes it possible to save the results of finished
+// packages while output is still being parsed.
+func PackageHandler(f func(gtr.Package)) Option {
+	return func(p *Parser) {
+		p.packageHandler = f
+	}
+}
+
+// DropPassedOutput is an Option that discards the output of tests as soon as
+// they pass, which reduces the memory needed to parse runs with a large number
+// of tests. The output of tests that failed or were skipped and output outside
+// of tests is kept. Attachments referenced in the output of passed tests are
+// not reported.
+func DropPassedOutput(drop bool) Option {
+	return func(p *Parser) {
+		p.dropPassedOutput = drop
+	}
+}
+
+// RecordEvents is an Option that makes the parser keep the events it creates
+// while parsing the input of Parse and ParseBytes, so that they can be
+// retrieved using Events. Events aren't kept by default, since keeping every
+// event of a large input uses a lot of memory.
+func RecordEvents(record bool) Option {
+	return func(p *Parser) {
+		p.keepEvents = record
+	}
+}
+
+// MaxOutput is an Option that truncates the output of each test and package
+// exceeding limits as soon as its package has finished, see
+// gtr.TruncateOutput. Unlike truncating the parsed report, this also applies
+// to the packages passed to the PackageHandler.
+func MaxOutput(limits gtr.OutputLimits) Option {
+	return func(p *Parser) {
+		p.outputLimits = limits
+	}
+}
+
 // SubtestMode configures how Go subtests should be handled by the parser.
 type SubtestMode string
 
@@ -115,13 +244,33 @@ type Parser struct {
 	subtestMode SubtestMode
 
 	timestampFunc func() time.Time
-
-	events []Event
+	clock         func() time.Time
+	infraPatterns []*regexp.Regexp
+
+	failureExtractors []FailureExtractor
+	eventHandler      func(Event)
+	packageHandler    func(gtr.Package)
+	warningHandler    func(Warning)
+	properties        []gtr.Property
+	dropPassedOutput  bool
+	keepEvents        bool
+	outputLimits      gtr.OutputLimits
+	hostname          string
+	shardIndex        int
+	shardCount        int
+
+	events       []Event
+	recordEvents bool                // whether to retain events in events
+	sessions     map[string]*session // test sessions by package name
+	races        map[string][]string // lines of unfinished race reports by package name
+	sanitizers   map[string][]string // lines of unfinished sanitizer reports by package name
+	names        map[string]string   // interned package and test names
+	builder      *reportBuilder      // builder for the report being parsed
 }
 
 // NewParser returns a new Go test output parser.
 func NewParser(options ...Option) *Parser {
-	p := &Parser{}
+	p := &Parser{failureExtractors: defaultFailureExtractors}
 	for _, option := range options {
 		option(p)
 	}
@@ -134,55 +283,201 @@ func (p *Parser) Parse(r io.Reader) (gtr.Report, error) {
 	return p.parse(reader.NewLimitedLineReader(r, maxLineSize))
 }
 
+// ParseBytes parses Go test output from data and returns gtr.Report. Unlike
+// Parse, ParseBytes converts data to a string only once and reads lines from
+// it without allocating a copy of each line. The lines that end up in the
+// report output are still copied into the parser's output buffers, so the
+// report doesn't refer to data. It should be preferred over Parse for large
+// logs that have been read into memory already.
+func (p *Parser) ParseBytes(data []byte) (gtr.Report, error) {
+	return p.parse(reader.NewStringLineReader(string(data), maxLineSize))
+}
+
 func (p *Parser) parse(r reader.LineReader) (gtr.Report, error) {
-	p.events = nil
+	p.reset(p.keepEvents)
 	for {
 		line, metadata, err := r.ReadLine()
 		if err == io.EOF {
 			break
 		} else if err != nil {
+			p.builder = nil
 			return gtr.Report{}, err
 		}
+		p.processLine(line, metadata)
+	}
+	return p.Flush(), nil
+}
 
-		var evs []Event
-
-		// Lines that exceed bufio.MaxScanTokenSize are not expected to contain
-		// any relevant test infrastructure output, so instead of parsing them
-		// we treat them as regular output to increase performance.
-		//
-		// Parser used a bufio.Scanner in the past, which only supported
-		// reading lines up to bufio.MaxScanTokenSize in length. Since this
-		// turned out to be fine in almost all cases, it seemed an appropriate
-		// value to use to decide whether or not to attempt parsing this line.
-		if len(line) > bufio.MaxScanTokenSize {
-			evs = p.output(line)
-		} else {
-			evs = p.parseLine(line)
-		}
+// ParseLine parses a single line of Go test output, without the trailing
+// newline. Together with Flush, this makes it possible to parse output while
+// it's being produced instead of reading it all at once using Parse. Events
+// created by ParseLine are not retained, so they are not returned by Events.
+func (p *Parser) ParseLine(line string) {
+	if p.builder == nil {
+		p.reset(false)
+	}
+	p.processLine(limitLine(line), nil)
+}
 
-		for _, ev := range evs {
-			ev.applyMetadata(metadata)
+// limitLine truncates line to maxLineSize bytes, like the line readers used
+// by Parse do.
+func limitLine(line string) string {
+	if len(line) > maxLineSize {
+		return line[:maxLineSize]
+	}
+	return line
+}
+
+// Flush returns the report containing everything parsed since the last call
+// to Flush, and resets the parser so it can be used to parse new output.
+func (p *Parser) Flush() gtr.Report {
+	if p.builder == nil {
+		p.reset(false)
+	}
+	report := p.builder.Build()
+	p.builder = nil
+	p.sessions = nil
+	return report
+}
+
+// reset prepares the parser for parsing new output. The events created while
+// parsing are only retained if recordEvents is true.
+func (p *Parser) reset(recordEvents bool) {
+	p.events = nil
+	p.recordEvents = recordEvents
+	p.sessions = make(map[string]*session)
+	p.races = make(map[string][]string)
+	p.sanitizers = make(map[string][]string)
+	p.names = make(map[string]string)
+
+	p.builder = newReportBuilder()
+	p.builder.packageName = p.packageName
+	p.builder.subtestMode = p.subtestMode
+	p.builder.failureExtractors = p.failureExtractors
+	p.builder.properties = p.properties
+	p.builder.dropPassedOutput = p.dropPassedOutput
+	p.builder.outputLimits = p.outputLimits
+	p.builder.packageHandler = p.packageHandler
+	p.builder.warningHandler = p.warningHandler
+	p.builder.SetHostname(p.hostname)
+	p.builder.SetShard(p.shardIndex, p.shardCount)
+	if p.timestampFunc != nil {
+		p.builder.timestampFunc = p.timestampFunc
+	} else {
+		p.builder.eventTime = true
+	}
+}
+
+// processLine parses the given line and adds the resulting events to the
+// report builder.
+func (p *Parser) processLine(line string, metadata *reader.Metadata) {
+	var evs []Event
+
+	// Lines that exceed bufio.MaxScanTokenSize are not expected to contain
+	// any relevant test infrastructure output, so instead of parsing them
+	// we treat them as regular output to increase performance.
+	//
+	// Parser used a bufio.Scanner in the past, which only supported
+	// reading lines up to bufio.MaxScanTokenSize in length. Since this
+	// turned out to be fine in almost all cases, it seemed an appropriate
+	// value to use to decide whether or not to attempt parsing this line.
+	var pkg string
+	if metadata != nil {
+		metadata.Package = p.intern(metadata.Package)
+		metadata.Test = p.intern(metadata.Test)
+		pkg = metadata.Package
+	}
+	s := p.session(pkg)
+	if len(line) > bufio.MaxScanTokenSize {
+		evs = p.output(line)
+	} else if s.IsNested(line) {
+		evs = p.output(line)
+	} else if raceEvs, ok := p.raceLine(pkg, line); ok {
+		evs = raceEvs
+	} else if sanitizerEvs, ok := p.sanitizerLine(pkg, line); ok {
+		evs = sanitizerEvs
+	} else {
+		evs = append(sanitizerEvs, p.parseLine(line)...)
+		s.Track(evs)
+	}
+
+	for _, ev := range evs {
+		ev.applyMetadata(metadata)
+		if ev.Time.IsZero() && p.clock != nil {
+			ev.Time = p.clock()
+		}
+		if p.recordEvents {
 			p.events = append(p.events, ev)
 		}
+		p.builder.ProcessEvent(ev)
+		if p.eventHandler != nil {
+			p.eventHandler(ev)
+		}
 	}
-	return p.report(p.events), nil
 }
 
-// report generates a gtr.Report from the given list of events.
-func (p *Parser) report(events []Event) gtr.Report {
-	rb := newReportBuilder()
-	rb.packageName = p.packageName
-	rb.subtestMode = p.subtestMode
-	if p.timestampFunc != nil {
-		rb.timestampFunc = p.timestampFunc
+// raceLine collects the lines of race detector reports for the given package.
+// It returns false if line is not part of a race report. Lines that are part
+// of a race report are returned as output, and a race event is added once the
+// complete report has been read.
+func (p *Parser) raceLine(pkg, line string) ([]Event, bool) {
+	lines, inRace := p.races[pkg]
+	switch {
+	case !inRace && line == raceSeparator:
+		p.races[pkg] = []string{}
+	case !inRace:
+		return nil, false
+	case len(lines) == 0 && line != raceWarning:
+		// The separator wasn't followed by a race report after all.
+		delete(p.races, pkg)
+		return nil, false
+	case line == raceSeparator:
+		delete(p.races, pkg)
+		return append(p.output(line), Race{Lines: lines}.Event()), true
+	default:
+		p.races[pkg] = append(lines, line)
 	}
-	for _, ev := range events {
-		rb.ProcessEvent(ev)
+	return p.output(line), true
+}
+
+// sanitizerLine collects the lines of sanitizer reports for the given package.
+// It returns false if line is not part of a sanitizer report. Lines that are
+// part of a sanitizer report are returned as output, and a sanitizer event is
+// added once the complete report has been read. When a report is cut short by
+// go test output, false is returned together with the sanitizer event, so that
+// the line is parsed as usual.
+func (p *Parser) sanitizerLine(pkg, line string) ([]Event, bool) {
+	lines, inReport := p.sanitizers[pkg]
+	switch {
+	case !inReport && strings.HasPrefix(line, "==") && regexSanitizerStart.MatchString(line):
+		p.sanitizers[pkg] = []string{line}
+	case !inReport:
+		return nil, false
+	case isSanitizerEnd(line):
+		delete(p.sanitizers, pkg)
+		if !strings.HasPrefix(line, "SUMMARY: ") {
+			return []Event{Sanitizer{Lines: lines}.Event()}, false
+		}
+		return append(p.output(line), Sanitizer{Lines: append(lines, line)}.Event()), true
+	default:
+		p.sanitizers[pkg] = append(lines, line)
+	}
+	return p.output(line), true
+}
+
+// session returns the test session for the given package, creating one if
+// necessary.
+func (p *Parser) session(pkg string) *session {
+	s, ok := p.sessions[pkg]
+	if !ok {
+		s = newSession()
+		p.sessions[pkg] = s
 	}
-	return rb.Build()
+	return s
 }
 
-// Events returns the events created by the parser.
+// Events returns the events created by the last call to Parse or ParseBytes,
+// if the parser was created with the RecordEvents option.
 func (p *Parser) Events() []Event {
 	events := make([]Event, len(p.events))
 	copy(events, p.events)
@@ -196,117 +491,213 @@ func (p *Parser) parseLine(line string) (events []Event) {
 		return p.pauseTest(strings.TrimSpace(line[10:]))
 	} else if strings.HasPrefix(line, "=== CONT ") {
 		return p.contTest(strings.TrimSpace(line[9:]))
-	} else if matches := regexEndTest.FindStringSubmatch(line); len(matches) == 5 {
-		return p.endTest(line, matches[1], matches[2], matches[3], matches[4])
-	} else if matches := regexStatus.FindStringSubmatch(line); len(matches) == 2 {
+	} else if strings.HasPrefix(line, "=== NAME ") {
+		// Since Go 1.20, `=== NAME` is printed whenever output switches to a
+		// different test, which we handle the same way as `=== CONT`.
+		return p.contTest(strings.TrimSpace(line[9:]))
+	} else if matches := findSubmatch(regexEndTest, line, "--- "); len(matches) == 6 {
+		return p.endTest(line, matches[1], matches[2], matches[3], matches[4], matches[5])
+	} else if matches := findSubmatch(regexStatus, line, "PASS", "FAIL", "SKIP"); len(matches) == 2 {
 		return p.status(matches[1])
-	} else if matches := regexSummary.FindStringSubmatch(line); len(matches) == 8 {
+	} else if matches := findSubmatch(regexSummary, line, "?", "ok", "FAIL"); len(matches) == 8 {
 		return p.summary(matches[1], matches[2], matches[3], matches[4], matches[7], matches[5], matches[6])
-	} else if matches := regexCoverage.FindStringSubmatch(line); len(matches) == 3 {
+	} else if matches := findSubmatch(regexCoverage, line, "coverage:"); len(matches) == 3 {
 		return p.coverage(matches[1], matches[2])
-	} else if matches := regexBenchmark.FindStringSubmatch(line); len(matches) == 2 {
+	} else if matches := findSubmatch(regexBenchmark, line, "Benchmark"); len(matches) == 2 {
 		return p.runBench(matches[1])
-	} else if matches := regexBenchSummary.FindStringSubmatch(line); len(matches) == 7 {
-		return p.benchSummary(matches[1], matches[2], matches[3], matches[4], matches[5], matches[6])
-	} else if matches := regexEndBenchmark.FindStringSubmatch(line); len(matches) == 3 {
+	} else if matches := findSubmatch(regexBenchSummary, line, "Benchmark"); len(matches) == 4 {
+		return p.benchSummary(matches[1], matches[2], matches[3])
+	} else if matches := findSubmatch(regexEndBenchmark, line, "--- "); len(matches) == 3 {
 		return p.endBench(matches[1], matches[2])
+	} else if matches := findSubmatch(regexFuzzBaseline, line, "fuzz: "); len(matches) == 2 {
+		return p.fuzzBaseline(line, matches[1])
+	} else if matches := findSubmatch(regexFuzzExecs, line, "fuzz: "); len(matches) == 4 {
+		return p.fuzzExecs(line, matches[1], matches[2], matches[3])
+	} else if matches := findSubmatch(regexFuzzFailure, line, "Failing input"); len(matches) == 3 {
+		return p.fuzzFailure(line, matches[2], matches[1])
+	} else if matches := findSubmatch(regexShuffle, line, "-test.shuffle "); len(matches) == 2 {
+		return p.shuffle(line, matches[1])
+	} else if strings.HasPrefix(line, "panic: ") && regexPanic.MatchString(line) {
+		return p.panic(line)
 	} else if strings.HasPrefix(line, "# ") {
 		fields := strings.Fields(strings.TrimPrefix(line, "# "))
 		if len(fields) == 1 || len(fields) == 2 {
 			return p.buildOutput(fields[0])
 		}
+	} else if p.isInfraError(line) {
+		return p.infraError(line)
 	}
 	return p.output(line)
 }
 
+// findSubmatch returns the submatches of regular expression re in line. Since
+// most lines are regular output, the expression is only run if line contains
+// one of the substrings that any match must contain, which is much cheaper to
+// check.
+func findSubmatch(re *regexp.Regexp, line string, substrs ...string) []string {
+	for _, substr := range substrs {
+		if strings.Contains(line, substr) {
+			return re.FindStringSubmatch(line)
+		}
+	}
+	return nil
+}
+
+// isInfraError returns true if line matches any of the infrastructure error
+// patterns.
+func (p *Parser) isInfraError(line string) bool {
+	for _, patterns := range [][]*regexp.Regexp{defaultInfraErrorPatterns, p.infraPatterns} {
+		for _, re := range patterns {
+			if prefix, complete := re.LiteralPrefix(); complete {
+				if strings.Contains(line, prefix) {
+					return true
+				}
+			} else if re.MatchString(line) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (p *Parser) runTest(name string) []Event {
-	return []Event{{Type: "run_test", Name: name}}
+	return []Event{RunTest{Name: p.intern(name)}.Event()}
 }
 
 func (p *Parser) pauseTest(name string) []Event {
-	return []Event{{Type: "pause_test", Name: name}}
+	return []Event{PauseTest{Name: p.intern(name)}.Event()}
 }
 
 func (p *Parser) contTest(name string) []Event {
-	return []Event{{Type: "cont_test", Name: name}}
+	return []Event{ContTest{Name: p.intern(name)}.Event()}
 }
 
-func (p *Parser) endTest(line, indent, result, name, duration string) []Event {
+// intern returns the first seen string equal to name. Package and test names
+// are repeated in the events of every line belonging to them, interning them
+// makes sure only a single copy of each name is retained in the report and
+// in the recorded events.
+func (p *Parser) intern(name string) string {
+	if s, ok := p.names[name]; ok {
+		return s
+	}
+	if p.names == nil {
+		p.names = make(map[string]string)
+	}
+	p.names[name] = name
+	return name
+}
+
+func (p *Parser) endTest(line, indent, result, name, duration, note string) []Event {
 	var events []Event
+	name = p.intern(name)
 	if idx := strings.Index(line, fmt.Sprintf("%s--- %s:", indent, result)); idx > 0 {
 		events = append(events, p.output(line[:idx])...)
 	}
 	_, n := stripIndent(indent)
-	events = append(events, Event{
-		Type:     "end_test",
+	if n > 0 && !strings.Contains(name, "/") {
+		// Subtests always contain their parent's name, so this is the
+		// result of a fuzz test reporting its failing input. The output that
+		// follows belongs to the fuzz test.
+		return append(events, ContTest{Name: name}.Event(), Output{Line: line}.Event())
+	}
+	events = append(events, EndTest{
 		Name:     name,
 		Result:   result,
 		Indent:   n,
 		Duration: parseSeconds(duration),
-	})
+		Note:     strings.TrimSpace(note),
+	}.Event())
 	return events
 }
 
 func (p *Parser) status(result string) []Event {
-	return []Event{{Type: "status", Result: result}}
+	return []Event{Status{Result: result}.Event()}
 }
 
 func (p *Parser) summary(result, name, duration, cached, status, covpct, packages string) []Event {
-	return []Event{{
-		Type:        "summary",
-		Result:      result,
-		Name:        name,
-		Duration:    parseSeconds(duration),
-		Data:        strings.TrimSpace(cached + " " + status),
-		CovPct:      parseFloat(covpct),
-		CovPackages: parsePackages(packages),
-	}}
+	return []Event{Summary{
+		Result:          result,
+		Name:            name,
+		Duration:        parseSeconds(duration),
+		Note:            strings.TrimSpace(cached + " " + status),
+		Coverage:        parseFloat(covpct),
+		CoveredPackages: parsePackages(packages),
+	}.Event()}
 }
 
 func (p *Parser) coverage(percent, packages string) []Event {
-	return []Event{{
-		Type:        "coverage",
-		CovPct:      parseFloat(percent),
-		CovPackages: parsePackages(packages),
-	}}
+	return []Event{Coverage{
+		Percent:  parseFloat(percent),
+		Packages: parsePackages(packages),
+	}.Event()}
 }
 
 func (p *Parser) runBench(name string) []Event {
-	return []Event{{
-		Type: "run_benchmark",
-		Name: name,
-	}}
+	return []Event{RunBenchmark{Name: name}.Event()}
 }
 
-func (p *Parser) benchSummary(name, iterations, nsPerOp, mbPerSec, bytesPerOp, allocsPerOp string) []Event {
-	return []Event{{
-		Type:        "benchmark",
-		Name:        name,
-		Iterations:  parseInt(iterations),
-		NsPerOp:     parseFloat(nsPerOp),
-		MBPerSec:    parseFloat(mbPerSec),
-		BytesPerOp:  parseInt(bytesPerOp),
-		AllocsPerOp: parseInt(allocsPerOp),
-	}}
+func (p *Parser) benchSummary(name, iterations, metrics string) []Event {
+	bench := Benchmark{Iterations: parseInt(iterations)}
+	fields := strings.Fields(metrics)
+	for i := 0; i+1 < len(fields); i += 2 {
+		value, unit := fields[i], fields[i+1]
+		switch unit {
+		case "ns/op":
+			bench.NsPerOp = parseFloat(value)
+		case "MB/s":
+			bench.MBPerSec = parseFloat(value)
+		case "B/op":
+			bench.BytesPerOp = int64(parseFloat(value))
+		case "allocs/op":
+			bench.AllocsPerOp = int64(parseFloat(value))
+		default:
+			if bench.Metrics == nil {
+				bench.Metrics = make(map[string]float64)
+			}
+			bench.Metrics[unit] = parseFloat(value)
+		}
+	}
+	return []Event{BenchmarkResult{Name: name, Benchmark: bench}.Event()}
 }
 
 func (p *Parser) endBench(result, name string) []Event {
-	return []Event{{
-		Type:   "end_benchmark",
-		Name:   name,
-		Result: result,
-	}}
+	return []Event{EndBenchmark{Name: name, Result: result}.Event()}
+}
+
+func (p *Parser) fuzzBaseline(line, corpus string) []Event {
+	return append(p.output(line), FuzzProgress{Corpus: int(parseInt(corpus))}.Event())
+}
+
+func (p *Parser) fuzzExecs(line, execs, execsPerSec, newInteresting string) []Event {
+	return append(p.output(line), FuzzProgress{
+		Execs:          parseInt(execs),
+		ExecsPerSec:    parseInt(execsPerSec),
+		NewInteresting: int(parseInt(newInteresting)),
+	}.Event())
+}
+
+func (p *Parser) fuzzFailure(line, name, path string) []Event {
+	return append(p.output(line), FuzzFailure{Name: name, Path: path}.Event())
+}
+
+func (p *Parser) shuffle(line, seed string) []Event {
+	return append(p.output(line), Shuffle{Seed: seed}.Event())
+}
+
+func (p *Parser) panic(line string) []Event {
+	return []Event{Panic{Line: line}.Event()}
 }
 
 func (p *Parser) buildOutput(packageName string) []Event {
-	return []Event{{
-		Type: "build_output",
-		Name: packageName,
-	}}
+	return []Event{BuildOutput{Name: packageName}.Event()}
+}
+
+func (p *Parser) infraError(line string) []Event {
+	return []Event{InfraError{Line: line}.Event()}
 }
 
 func (p *Parser) output(line string) []Event {
-	return []Event{{Type: "output", Data: line}}
+	return []Event{Output{Line: line}.Event()}
 }
 
 func parseSeconds(s string) time.Duration {
@@ -1,11 +1,16 @@
 package gotest
 
 import (
+	"bytes"
 	"fmt"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/parser"
+
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -36,6 +41,10 @@ var parseLineTests = []parseLineTest{
 		"=== RUN   TestTwo/Subtest",
 		[]Event{{Type: "run_test", Name: "TestTwo/Subtest"}},
 	},
+	{
+		"=== RUN   TestTwo/my case",
+		[]Event{{Type: "run_test", Name: "TestTwo/my case"}},
+	},
 	{
 		"=== PAUSE TestOne",
 		[]Event{{Type: "pause_test", Name: "TestOne"}},
@@ -44,6 +53,10 @@ var parseLineTests = []parseLineTest{
 		"=== CONT  TestOne",
 		[]Event{{Type: "cont_test", Name: "TestOne"}},
 	},
+	{
+		"=== NAME  TestOne",
+		[]Event{{Type: "cont_test", Name: "TestOne"}},
+	},
 	{
 		"--- PASS: TestOne (12.34 seconds)",
 		[]Event{{Type: "end_test", Name: "TestOne", Result: "PASS", Duration: 12_340 * time.Millisecond}},
@@ -56,6 +69,61 @@ var parseLineTests = []parseLineTest{
 		"        --- FAIL: TestOne/Subtest/#01 (0.35s)",
 		[]Event{{Type: "end_test", Name: "TestOne/Subtest/#01", Result: "FAIL", Duration: 350 * time.Millisecond, Indent: 2}},
 	},
+	{
+		"    --- PASS: TestOne/my case (0.12s)",
+		[]Event{{Type: "end_test", Name: "TestOne/my case", Result: "PASS", Duration: 120 * time.Millisecond, Indent: 1}},
+	},
+	{
+		"    --- PASS: TestOne/retry after (1.50s) (0.12s)",
+		[]Event{{Type: "end_test", Name: "TestOne/retry after (1.50s)", Result: "PASS", Duration: 120 * time.Millisecond, Indent: 1}},
+	},
+	{
+		`    --- FAIL: TestOne/"quoted"_<a&b>_ünïcödé_日本 (0.12s)`,
+		[]Event{{Type: "end_test", Name: `TestOne/"quoted"_<a&b>_ünïcödé_日本`, Result: "FAIL", Duration: 120 * time.Millisecond, Indent: 1}},
+	},
+	{
+		"    --- FAIL: FuzzOne (0.00s)",
+		[]Event{
+			{Type: "cont_test", Name: "FuzzOne"},
+			{Type: "output", Data: "    --- FAIL: FuzzOne (0.00s)"},
+		},
+	},
+	{
+		"fuzz: elapsed: 0s, gathering baseline coverage: 3/3 completed, now fuzzing with 8 workers",
+		[]Event{
+			{Type: "output", Data: "fuzz: elapsed: 0s, gathering baseline coverage: 3/3 completed, now fuzzing with 8 workers"},
+			{Type: "fuzz_progress", FuzzCorpus: 3},
+		},
+	},
+	{
+		"fuzz: elapsed: 3s, execs: 136464 (45472/sec), new interesting: 1 (total: 4)",
+		[]Event{
+			{Type: "output", Data: "fuzz: elapsed: 3s, execs: 136464 (45472/sec), new interesting: 1 (total: 4)"},
+			{Type: "fuzz_progress", FuzzExecs: 136464, FuzzExecsPerSec: 45472, FuzzNewInteresting: 1},
+		},
+	},
+	{
+		"-test.shuffle 1652986251466377000",
+		[]Event{
+			{Type: "output", Data: "-test.shuffle 1652986251466377000"},
+			{Type: "shuffle", Data: "1652986251466377000"},
+		},
+	},
+	{
+		"    Failing input written to testdata/fuzz/FuzzOne/1de061fa29cfbb3d",
+		[]Event{
+			{Type: "output", Data: "    Failing input written to testdata/fuzz/FuzzOne/1de061fa29cfbb3d"},
+			{Type: "fuzz_failure", Name: "FuzzOne", Data: "testdata/fuzz/FuzzOne/1de061fa29cfbb3d"},
+		},
+	},
+	{
+		"    --- FAIL: TestOne/two (2) words (0.12s)",
+		[]Event{{Type: "end_test", Name: "TestOne/two (2) words", Result: "FAIL", Duration: 120 * time.Millisecond, Indent: 1}},
+	},
+	{
+		"--- PASS: TestOne (1.23s) # instrumented",
+		[]Event{{Type: "end_test", Name: "TestOne", Result: "PASS", Duration: 1_230 * time.Millisecond, Data: "# instrumented"}},
+	},
 	{
 		"some text--- PASS: TestTwo (0.06 seconds)",
 		[]Event{
@@ -71,6 +139,10 @@ var parseLineTests = []parseLineTest{
 		"FAIL",
 		[]Event{{Type: "status", Result: "FAIL"}},
 	},
+	{
+		"FAIL\r",
+		[]Event{{Type: "status", Result: "FAIL"}},
+	},
 	{
 		"SKIP",
 		[]Event{{Type: "status", Result: "SKIP"}},
@@ -163,6 +235,14 @@ var parseLineTests = []parseLineTest{
 		"BenchmarkFour-8         	   10000	    104427 ns/op	  95.76 MB/s	   40629 B/op	       5 allocs/op",
 		[]Event{{Type: "benchmark", Name: "BenchmarkFour", Iterations: 10_000, NsPerOp: 104_427, MBPerSec: 95.76, BytesPerOp: 40_629, AllocsPerOp: 5}},
 	},
+	{
+		"BenchmarkFive-8   	    5000	    201234 ns/op	      4969 requests/s	    350000 p99-ns	     512 B/op	       3 allocs/op",
+		[]Event{{Type: "benchmark", Name: "BenchmarkFive", Iterations: 5_000, NsPerOp: 201_234, BytesPerOp: 512, AllocsPerOp: 3, BenchMetrics: map[string]float64{"requests/s": 4969, "p99-ns": 350_000}}},
+	},
+	{
+		"BenchmarkSix 	 1000	 1.5e-05 hit-ratio",
+		[]Event{{Type: "benchmark", Name: "BenchmarkSix", Iterations: 1_000, BenchMetrics: map[string]float64{"hit-ratio": 1.5e-05}}},
+	},
 	{
 		"--- BENCH: BenchmarkOK-8",
 		[]Event{{Type: "end_benchmark", Name: "BenchmarkOK", Result: "BENCH"}},
@@ -175,6 +255,10 @@ var parseLineTests = []parseLineTest{
 		"--- SKIP: BenchmarkSkip",
 		[]Event{{Type: "end_benchmark", Name: "BenchmarkSkip", Result: "SKIP"}},
 	},
+	{
+		"    --- SKIP: BenchmarkSkip/sub-8",
+		[]Event{{Type: "end_benchmark", Name: "BenchmarkSkip/sub", Result: "SKIP"}},
+	},
 	{
 		"# package/name/failing1",
 		[]Event{{Type: "build_output", Name: "package/name/failing1"}},
@@ -207,6 +291,93 @@ var parseLineTests = []parseLineTest{
 		"\t\tmessage.",
 		[]Event{{Type: "output", Data: "\t\tmessage."}},
 	},
+	{
+		"fork/exec /tmp/go-build/b001/pkg.test: resource temporarily unavailable",
+		[]Event{{Type: "infra_error", Data: "fork/exec /tmp/go-build/b001/pkg.test: resource temporarily unavailable"}},
+	},
+	{
+		"dial tcp: socket: too many open files",
+		[]Event{{Type: "infra_error", Data: "dial tcp: socket: too many open files"}},
+	},
+}
+
+func TestInfraErrorPatterns(t *testing.T) {
+	parser := NewParser(InfraErrorPatterns(regexp.MustCompile(`^runner: lost connection`)))
+
+	want := []Event{{Type: "infra_error", Data: "runner: lost connection to agent"}}
+	if diff := cmp.Diff(want, parser.parseLine("runner: lost connection to agent")); diff != "" {
+		t.Errorf("parseLine returned unexpected events for custom pattern, diff (-want, +got):\n%v", diff)
+	}
+
+	want = []Event{{Type: "infra_error", Data: "socket: too many open files"}}
+	if diff := cmp.Diff(want, parser.parseLine("socket: too many open files")); diff != "" {
+		t.Errorf("parseLine returned unexpected events for built-in pattern, diff (-want, +got):\n%v", diff)
+	}
+}
+
+func TestPackageProperties(t *testing.T) {
+	props := []gtr.Property{{Name: "go.os", Value: "linux"}}
+	input := "ok  \tpackage/one\t0.001s\nok  \tpackage/two\t0.001s\n"
+	report, err := NewParser(PackageProperties(props...)).Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	if len(report.Packages) != 2 {
+		t.Fatalf("Parse returned unexpected report: %#v", report)
+	}
+
+	report.Packages[0].SetProperty("go.os", "changed")
+	if diff := cmp.Diff(props, report.Packages[1].Properties); diff != "" {
+		t.Errorf("package properties incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestHostnameAndShard(t *testing.T) {
+	input := "ok  \tpackage/one\t0.001s\n"
+	report, err := NewParser(Hostname("runner-1"), Shard(2, 4)).Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	if report.Hostname != "runner-1" || report.ShardIndex != 2 || report.ShardCount != 4 {
+		t.Errorf("report hostname and shard = %q %d/%d, want runner-1 2/4", report.Hostname, report.ShardIndex, report.ShardCount)
+	}
+	if len(report.Packages) != 1 {
+		t.Fatalf("Parse returned unexpected report: %#v", report)
+	}
+	if pkg := report.Packages[0]; pkg.Hostname != "runner-1" || pkg.ShardIndex != 2 || pkg.ShardCount != 4 {
+		t.Errorf("package hostname and shard = %q %d/%d, want runner-1 2/4", pkg.Hostname, pkg.ShardIndex, pkg.ShardCount)
+	}
+}
+
+func TestEventHandler(t *testing.T) {
+	var handled []Event
+	p := NewParser(RecordEvents(true), EventHandler(func(ev Event) { handled = append(handled, ev) }))
+	if _, err := p.Parse(strings.NewReader("=== RUN   TestOne\n--- PASS: TestOne (0.01s)\nok  \tpackage/name\t0.011s\n")); err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(p.Events(), handled); diff != "" {
+		t.Errorf("EventHandler received unexpected events, diff (-want +got):\n%s\n", diff)
+	}
+	if len(handled) == 0 {
+		t.Errorf("EventHandler received no events")
+	}
+}
+
+func TestPackageHandler(t *testing.T) {
+	var handled []gtr.Package
+	p := NewParser(Hostname("runner-1"), PackageHandler(func(pkg gtr.Package) { handled = append(handled, pkg) }))
+	input := "=== RUN   TestOne\n--- PASS: TestOne (0.01s)\nPASS\nok  \tpackage/one\t0.011s\n" +
+		"=== RUN   TestTwo\n"
+	report, err := p.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	if len(report.Packages) != 2 {
+		t.Fatalf("Parse returned %d packages, want 2", len(report.Packages))
+	}
+	if diff := cmp.Diff(report.Packages[:1], handled); diff != "" {
+		t.Errorf("PackageHandler received unexpected packages, diff (-want +got):\n%s\n", diff)
+	}
 }
 
 func TestParseLine(t *testing.T) {
@@ -221,3 +392,177 @@ func TestParseLine(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordEvents(t *testing.T) {
+	input := "=== RUN   TestOne\n--- PASS: TestOne (0.01s)\nok  \tpackage/name\t0.011s\n"
+	for _, record := range []bool{false, true} {
+		p := NewParser(RecordEvents(record))
+		if _, err := p.Parse(strings.NewReader(input)); err != nil {
+			t.Fatalf("Parse returned an unexpected error: %v", err)
+		}
+		if got := len(p.Events()); (got > 0) != record {
+			t.Errorf("Events returned %d events with RecordEvents(%t)", got, record)
+		}
+	}
+}
+
+func TestParseLineFlush(t *testing.T) {
+	input := "=== RUN   TestOne\n--- PASS: TestOne (0.01s)\n=== RUN   TestTwo\n    two_test.go:1: broken\n--- FAIL: TestTwo (0.02s)\nFAIL\nFAIL\tpackage/name\t0.030s"
+
+	want, err := NewParser(TimestampFunc(testTimestampFunc)).Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+
+	p := NewParser(TimestampFunc(testTimestampFunc))
+	for _, line := range strings.Split(input, "\n") {
+		p.ParseLine(line)
+	}
+	got := p.Flush()
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseLine and Flush returned unexpected report, diff (-want +got):\n%s\n", diff)
+	}
+
+	if events := p.Events(); len(events) != 0 {
+		t.Errorf("Events returned %d events after ParseLine, want none", len(events))
+	}
+
+	// The parser should be reset after Flush.
+	p.ParseLine("ok  \tpackage/other\t0.001s")
+	if got := p.Flush(); len(got.Packages) != 1 || got.Packages[0].Name != "package/other" {
+		t.Errorf("Flush after reset returned unexpected report: %+v", got)
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	input := "=== RUN   TestOne\r\n--- PASS: TestOne (0.01s)\n=== RUN   TestTwo\n    two_test.go:1: broken\n--- FAIL: TestTwo (0.02s)\nFAIL\nFAIL\tpackage/name\t0.030s\n"
+
+	p := NewParser(TimestampFunc(testTimestampFunc), RecordEvents(true))
+	want, err := p.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	wantEvents := p.Events()
+
+	got, err := p.ParseBytes([]byte(input))
+	if err != nil {
+		t.Fatalf("ParseBytes returned an unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseBytes returned unexpected report, diff (-want +got):\n%s\n", diff)
+	}
+	if diff := cmp.Diff(wantEvents, p.Events()); diff != "" {
+		t.Errorf("ParseBytes created unexpected events, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestJSONParseLineFlush(t *testing.T) {
+	input := []string{
+		`{"Action":"run","Package":"package/name","Test":"TestOne"}`,
+		`{"Action":"output","Package":"package/name","Test":"TestOne","Output":"=== RUN   TestOne\n"}`,
+		`{"Action":"output","Package":"package/name","Test":"TestOne","Output":"--- PASS: TestOne (0.01s)\n"}`,
+		`{"Action":"pass","Package":"package/name","Test":"TestOne","Elapsed":0.01}`,
+		`{"Action":"output","Package":"package/name","Output":"ok  \tpackage/name\t0.011s\n"}`,
+	}
+
+	want, err := NewJSONParser(TimestampFunc(testTimestampFunc)).Parse(strings.NewReader(strings.Join(input, "\n")))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+
+	p := NewJSONParser(TimestampFunc(testTimestampFunc))
+	for _, line := range input {
+		if err := p.ParseLine(line); err != nil {
+			t.Fatalf("ParseLine(%q) returned an unexpected error: %v", line, err)
+		}
+	}
+	got := p.Flush()
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseLine and Flush returned unexpected report, diff (-want +got):\n%s\n", diff)
+	}
+
+	if err := p.ParseLine("{invalid"); err == nil {
+		t.Errorf("ParseLine did not return an error for invalid JSON")
+	}
+}
+
+func TestParseLineMaxLineSize(t *testing.T) {
+	long := strings.Repeat("x", maxLineSize+10)
+	want := []string{long[:maxLineSize]}
+
+	p := NewParser()
+	p.ParseLine("=== RUN   TestOne")
+	p.ParseLine(long)
+	p.ParseLine("--- PASS: TestOne (0.01s)")
+	p.ParseLine("ok  \tpackage/name\t0.011s")
+	got := p.Flush()
+	if diff := cmp.Diff(want, got.Packages[0].Tests[0].Output); diff != "" {
+		t.Errorf("ParseLine test output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	jp := NewJSONParser()
+	for _, line := range []string{
+		`{"Action":"output","Package":"package/name","Test":"TestOne","Output":"=== RUN   TestOne\n"}`,
+		`{"Action":"output","Package":"package/name","Test":"TestOne","Output":"` + long + `\n"}`,
+		`{"Action":"output","Package":"package/name","Test":"TestOne","Output":"--- PASS: TestOne (0.01s)\n"}`,
+		`{"Action":"output","Package":"package/name","Output":"ok  \tpackage/name\t0.011s\n"}`,
+	} {
+		if err := jp.ParseLine(line); err != nil {
+			t.Fatalf("JSONParser.ParseLine returned an unexpected error: %v", err)
+		}
+	}
+	got = jp.Flush()
+	if diff := cmp.Diff(want, got.Packages[0].Tests[0].Output); diff != "" {
+		t.Errorf("JSONParser.ParseLine test output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestRegisteredParsers(t *testing.T) {
+	for _, name := range []string{"gotest", "gojson"} {
+		if _, err := parser.New(name); err != nil {
+			t.Errorf("parser.New(%q) error: %v", name, err)
+		}
+	}
+}
+
+// benchmarkInput returns the output of a large verbose go test run.
+func benchmarkInput() []byte {
+	var input bytes.Buffer
+	for pkg := 0; pkg < 10; pkg++ {
+		for i := 0; i < 500; i++ {
+			fmt.Fprintf(&input, "=== RUN   TestName%d\n", i)
+			fmt.Fprintf(&input, "=== PAUSE TestName%d\n", i)
+			fmt.Fprintf(&input, "=== CONT  TestName%d\n", i)
+			for j := 0; j < 10; j++ {
+				fmt.Fprintf(&input, "    file_test.go:%d: output line %d\n", j, j)
+			}
+			fmt.Fprintf(&input, "--- PASS: TestName%d (0.01s)\n", i)
+		}
+		fmt.Fprintf(&input, "PASS\nok  \tpackage/name%d\t10.000s\n", pkg)
+	}
+	return input.Bytes()
+}
+
+func BenchmarkParse(b *testing.B) {
+	input := benchmarkInput()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewParser().Parse(bytes.NewReader(input)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseBytes(b *testing.B) {
+	input := benchmarkInput()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewParser().ParseBytes(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,27 +1,50 @@
 // Package collector collects output lines grouped by id and provides ways to
 // retrieve and merge output ordered by the time each line was added.
+//
+// To keep memory usage low for runs with a large amount of output, the text of
+// all lines is stored in a shared arena of large chunks rather than as
+// separately allocated strings. Each id only holds references to the ranges of
+// the arena containing its lines. Get and GetAll copy the text of the
+// requested lines into a single new string, so the returned lines don't keep
+// chunks alive. Chunks are released once none of their lines are referenced by
+// any id anymore.
 package collector
 
 import (
 	"sort"
-	"time"
+	"strings"
 )
 
-// line is a single line of output captured at some point in time.
+// chunkSize is the size in bytes of the chunks lines are stored in. Lines that
+// are longer than chunkSize are stored in a chunk of their own.
+const chunkSize = 64 << 10
+
+// line refers to a single line of output stored in the arena.
 type line struct {
-	Timestamp time.Time
-	Text      string
+	seq   uint64 // order in which lines were added
+	chunk int
+	off   uint32
+	n     uint32
+}
+
+// chunk is a part of the arena. Text is only ever appended to a chunk while
+// it has enough capacity left, so strings previously returned by
+// strings.Builder.String remain valid.
+type chunk struct {
+	b    *strings.Builder
+	live int // number of collected lines stored in this chunk
 }
 
 // Output stores output lines grouped by id. Output can be retrieved for one or
 // more ids and output for different ids can be merged together, while
-// preserving their insertion original order based on the time it was
-// collected.
+// preserving their original insertion order.
 // Output also tracks the active id, so you can append output without providing
 // an id.
 type Output struct {
-	m  map[int][]line
-	id int // active id
+	m      map[int][]line
+	id     int // active id
+	seq    uint64
+	chunks []chunk
 }
 
 // New returns a new output collector.
@@ -31,18 +54,19 @@ func New() *Output {
 
 // Clear deletes all output for the given id.
 func (o *Output) Clear(id int) {
+	o.release(o.m[id])
 	delete(o.m, id)
 }
 
 // Append appends the given line of text to the output of the currently active
 // id.
 func (o *Output) Append(text string) {
-	o.m[o.id] = append(o.m[o.id], line{time.Now(), text})
+	o.AppendToID(o.id, text)
 }
 
 // AppendToID appends the given line of text to the output of the given id.
 func (o *Output) AppendToID(id int, text string) {
-	o.m[id] = append(o.m[id], line{time.Now(), text})
+	o.m[id] = append(o.m[id], o.store(text))
 }
 
 // Contains returns true if any output lines were collected for the given id.
@@ -52,46 +76,105 @@ func (o *Output) Contains(id int) bool {
 
 // Get returns the output lines for the given id.
 func (o *Output) Get(id int) []string {
-	var lines []string
-	for _, line := range o.m[id] {
-		lines = append(lines, line.Text)
-	}
-	return lines
+	return o.text(o.m[id])
 }
 
-// GetAll returns the output lines for all ids sorted by the collection
-// timestamp of each line of output.
+// GetAll returns the output lines for all ids sorted by the order in which
+// each line of output was collected.
 func (o *Output) GetAll(ids ...int) []string {
 	var output []line
 	for _, id := range ids {
 		output = append(output, o.m[id]...)
 	}
-	sort.Slice(output, func(i, j int) bool {
-		return output[i].Timestamp.Before(output[j].Timestamp)
-	})
-	var lines []string
-	for _, line := range output {
-		lines = append(lines, line.Text)
-	}
-	return lines
+	sortLines(output)
+	return o.text(output)
 }
 
 // Merge merges the output lines from fromID into intoID, and sorts the output
-// by the collection timestamp of each line of output.
+// by the order in which each line of output was collected.
 func (o *Output) Merge(fromID, intoID int) {
 	var merged []line
 	for _, id := range []int{fromID, intoID} {
 		merged = append(merged, o.m[id]...)
 	}
-	sort.Slice(merged, func(i, j int) bool {
-		return merged[i].Timestamp.Before(merged[j].Timestamp)
-	})
+	sortLines(merged)
 	o.m[intoID] = merged
 	delete(o.m, fromID)
 }
 
+// ActiveID returns the active id.
+func (o *Output) ActiveID() int {
+	return o.id
+}
+
 // SetActiveID sets the active id. Text appended to this output will be
 // associated with the active id.
 func (o *Output) SetActiveID(id int) {
 	o.id = id
 }
+
+// store adds text to the arena and returns a reference to it.
+func (o *Output) store(text string) line {
+	o.seq++
+	last := len(o.chunks) - 1
+	if last < 0 || o.chunks[last].b.Cap()-o.chunks[last].b.Len() < len(text) {
+		if last >= 0 && o.chunks[last].live == 0 {
+			o.chunks[last].b = nil
+		}
+		b := &strings.Builder{}
+		if len(text) < chunkSize {
+			b.Grow(chunkSize)
+		} else {
+			b.Grow(len(text))
+		}
+		o.chunks = append(o.chunks, chunk{b: b})
+		last++
+	}
+	c := &o.chunks[last]
+	l := line{seq: o.seq, chunk: last, off: uint32(c.b.Len()), n: uint32(len(text))}
+	c.b.WriteString(text)
+	c.live++
+	return l
+}
+
+// release drops the references to the given lines, releasing the chunks
+// that are no longer used. The last chunk is kept, since new lines are
+// still being added to it.
+func (o *Output) release(lines []line) {
+	for _, l := range lines {
+		c := &o.chunks[l.chunk]
+		if c.live--; c.live == 0 && l.chunk != len(o.chunks)-1 {
+			c.b = nil
+		}
+	}
+}
+
+// text returns copies of the text of the given lines. The text is copied out
+// of the arena, so that lines that are kept, e.g. in a report, don't keep the
+// entire chunk they're stored in alive.
+func (o *Output) text(lines []line) []string {
+	if len(lines) == 0 {
+		return nil
+	}
+	var n int
+	for _, l := range lines {
+		n += int(l.n)
+	}
+	var b strings.Builder
+	b.Grow(n)
+	for _, l := range lines {
+		b.WriteString(o.chunks[l.chunk].b.String()[l.off : l.off+l.n])
+	}
+	all := b.String()
+	texts := make([]string, len(lines))
+	for i, l := range lines {
+		texts[i], all = all[:l.n], all[l.n:]
+	}
+	return texts
+}
+
+func sortLines(lines []line) {
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].seq < lines[j].seq
+	})
+}
@@ -2,6 +2,7 @@ package collector
 
 import (
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -115,3 +116,35 @@ func TestActiveID(t *testing.T) {
 	}
 
 }
+
+func TestArenaChunks(t *testing.T) {
+	o := New()
+	long := strings.Repeat("x", chunkSize+1)
+	half := strings.Repeat("y", chunkSize/2)
+	o.AppendToID(2, long)
+	o.AppendToID(1, half)
+	o.AppendToID(1, half)
+
+	if diff := cmp.Diff([]string{half, half}, o.Get(1)); diff != "" {
+		t.Errorf("Get(1) incorrect (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{long}, o.Get(2)); diff != "" {
+		t.Errorf("Get(2) incorrect (-want +got):\n%s", diff)
+	}
+	if len(o.chunks) != 2 {
+		t.Fatalf("got %d chunks, want 2", len(o.chunks))
+	}
+
+	got := o.Get(2)
+	o.Clear(2)
+	if o.chunks[0].b != nil {
+		t.Errorf("Clear(2) did not release unused chunk")
+	}
+	if diff := cmp.Diff([]string{long}, got); diff != "" {
+		t.Errorf("output returned by Get(2) changed after Clear(2) (-want +got):\n%s", diff)
+	}
+	o.Clear(1)
+	if o.chunks[1].b == nil {
+		t.Errorf("Clear(1) released the last chunk")
+	}
+}
@@ -17,6 +17,9 @@ type LineReader interface {
 // Metadata contains metadata that belongs to a line.
 type Metadata struct {
 	Package string
+	Test    string // name of the test that printed the line, if known
+	Stream  string // "stderr" if the line was written to stderr, empty if unknown
+	Time    time.Time
 }
 
 // LimitedLineReader reads lines from an io.Reader object with a configurable
@@ -72,7 +75,45 @@ func (r *LimitedLineReader) ReadLine() (string, *Metadata, error) {
 	return buf.String(), nil, nil
 }
 
-// Event represents a JSON event emitted by `go test -json`.
+// StringLineReader reads lines from a string with a configurable line size
+// limit. Lines are returned as substrings of the string, so reading them
+// doesn't allocate. Lines exceeding the limit will be truncated.
+type StringLineReader struct {
+	s     string
+	limit int
+}
+
+var _ LineReader = &StringLineReader{}
+
+// NewStringLineReader returns a StringLineReader to read lines from s with a
+// maximum line size of limit.
+func NewStringLineReader(s string, limit int) *StringLineReader {
+	return &StringLineReader{s: s, limit: limit}
+}
+
+// ReadLine returns the next line from the string, without the trailing "\n"
+// or "\r\n". The length of the line will not exceed the configured limit.
+// ReadLine returns io.EOF once all lines have been read.
+func (r *StringLineReader) ReadLine() (string, *Metadata, error) {
+	if len(r.s) == 0 {
+		return "", nil, io.EOF
+	}
+	line := r.s
+	if idx := strings.IndexByte(r.s, '\n'); idx >= 0 {
+		line, r.s = r.s[:idx], r.s[idx+1:]
+		line = strings.TrimSuffix(line, "\r")
+	} else {
+		r.s = ""
+	}
+	if len(line) > r.limit {
+		line = line[:r.limit]
+	}
+	return line, nil, nil
+}
+
+// Event represents a JSON event emitted by `go test -json`. Stream is not set
+// by go test, but can be set to "stderr" by tools that wrap it to keep the
+// output written to stderr separate.
 type Event struct {
 	Time    time.Time
 	Action  string
@@ -80,6 +121,7 @@ type Event struct {
 	Test    string
 	Elapsed float64 // seconds
 	Output  string
+	Stream  string
 }
 
 // JSONEventReader reads JSON events from an io.Reader object.
@@ -106,17 +148,32 @@ func (r *JSONEventReader) ReadLine() (string, *Metadata, error) {
 		if err != nil {
 			return "", nil, err
 		}
-		if len(line) == 0 || line[0] != '{' {
-			return line, nil, nil
-		}
-		event := &Event{}
-		if err := json.Unmarshal([]byte(line), event); err != nil {
+		output, metadata, ok, err := DecodeJSONLine(line)
+		if err != nil {
 			return "", nil, err
 		}
-		if event.Output == "" {
+		if !ok {
 			// Skip events without output
 			continue
 		}
-		return strings.TrimSuffix(event.Output, "\n"), &Metadata{Package: event.Package}, nil
+		return output, metadata, nil
+	}
+}
+
+// DecodeJSONLine returns the output and metadata contained in the given line
+// of `go test -json` output. Lines that do not contain a JSON event are
+// returned as is without metadata. If the event does not contain any output,
+// ok is false.
+func DecodeJSONLine(line string) (output string, metadata *Metadata, ok bool, err error) {
+	if len(line) == 0 || line[0] != '{' {
+		return line, nil, true, nil
+	}
+	event := &Event{}
+	if err := json.Unmarshal([]byte(line), event); err != nil {
+		return "", nil, false, err
+	}
+	if event.Output == "" {
+		return "", nil, false, nil
 	}
+	return strings.TrimSuffix(event.Output, "\n"), &Metadata{Package: event.Package, Test: event.Test, Stream: event.Stream, Time: event.Time}, true, nil
 }
@@ -5,6 +5,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -69,6 +70,32 @@ func TestLimitedLineReader(t *testing.T) {
 	}
 }
 
+func TestStringLineReader(t *testing.T) {
+	input := "first line\r\n\nthird line\rwith carriage return\n" + strings.Repeat("x", 20) + "\nlast line\r"
+	want := []string{
+		"first line",
+		"",
+		"third line\rwith ",
+		"xxxxxxxxxxxxxxxx",
+		"last line\r",
+	}
+
+	var got []string
+	for r := NewStringLineReader(input, 16); ; {
+		line, _, err := r.ReadLine()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("ReadLine() returned error %v", err)
+		}
+		got = append(got, line)
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ReadLine() returned incorrect lines, diff (-want +got):\n%s\n", diff)
+	}
+}
+
 func TestJSONEventReader(t *testing.T) {
 	input := `some other output
 {"Time":"2019-10-09T00:00:00.708139047+00:00","Action":"output","Package":"package/name/ok","Test":"TestOK"}
@@ -79,7 +106,7 @@ func TestJSONEventReader(t *testing.T) {
 		metadata *Metadata
 	}{
 		{"some other output", nil},
-		{"=== RUN   TestOK", &Metadata{Package: "package/name/ok"}},
+		{"=== RUN   TestOK", &Metadata{Package: "package/name/ok", Test: "TestOK", Time: time.Date(2019, 10, 9, 0, 0, 0, 708139047, time.UTC)}},
 	}
 
 	r := NewJSONEventReader(strings.NewReader(input))
@@ -12,7 +12,11 @@ func NewJSONParser(options ...Option) *JSONParser {
 	return &JSONParser{gp: NewParser(options...)}
 }
 
-// JSONParser is a `go test -json` output Parser.
+// JSONParser is a `go test -json` output Parser. Test results are parsed from
+// the output contained in the JSON events, with the event timestamps used as
+// the start time of each package. Output is added to the running test named in
+// its event, so the interleaved output of parallel tests is attributed to the
+// tests that printed it.
 type JSONParser struct {
 	gp *Parser
 }
@@ -23,6 +27,28 @@ func (p *JSONParser) Parse(r io.Reader) (gtr.Report, error) {
 	return p.gp.parse(reader.NewJSONEventReader(r))
 }
 
+// ParseLine parses a single line of Go test json output, see
+// Parser.ParseLine. The output in the line is truncated to the same maximum
+// line size as the output parsed by Parser.ParseLine. An error is returned if
+// the line could not be decoded.
+func (p *JSONParser) ParseLine(line string) error {
+	if p.gp.builder == nil {
+		p.gp.reset(false)
+	}
+	output, metadata, ok, err := reader.DecodeJSONLine(line)
+	if err != nil || !ok {
+		return err
+	}
+	p.gp.processLine(limitLine(output), metadata)
+	return nil
+}
+
+// Flush returns the report containing everything parsed since the last call
+// to Flush, and resets the parser so it can be used to parse new output.
+func (p *JSONParser) Flush() gtr.Report {
+	return p.gp.Flush()
+}
+
 // Events returns the events created by the parser.
 func (p *JSONParser) Events() []Event {
 	return p.gp.Events()
@@ -0,0 +1,101 @@
+package gotest
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+const (
+	racesKey = "gotest.races"
+
+	// raceSeparator surrounds each report printed by the race detector.
+	raceSeparator = "=================="
+	raceWarning   = "WARNING: DATA RACE"
+)
+
+var (
+	regexRaceAccess    = regexp.MustCompile(`^(.+) at (0x[0-9a-f]+) by (main goroutine|goroutine \d+):$`)
+	regexRaceGoroutine = regexp.MustCompile(`^Goroutine (\d+) \((.+)\) created at:$`)
+	regexStackLocation = regexp.MustCompile(`^\s+(\S+):(\d+)(?: \+0x[0-9a-f]+)?$`)
+)
+
+// RaceReport is a data race found by the race detector, and is intended to be
+// used as extra data in a gtr.Test.
+type RaceReport struct {
+	Accesses   []RaceAccess    // conflicting memory accesses, most recent first
+	Goroutines []RaceGoroutine // goroutines involved in the race
+	Output     []string        // the report as printed by the race detector
+}
+
+// RaceAccess is a memory access that's part of a data race.
+type RaceAccess struct {
+	Op        string // e.g. "Write" or "Previous read"
+	Addr      string
+	Goroutine string // e.g. "goroutine 8" or "main goroutine"
+	Stack     []StackFrame
+}
+
+// RaceGoroutine is a goroutine that's part of a data race.
+type RaceGoroutine struct {
+	ID    int
+	State string       // e.g. "running" or "finished"
+	Stack []StackFrame // where the goroutine was created
+}
+
+// StackFrame is a single function call in a stack trace.
+type StackFrame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// GetRaceReports is a helper function that returns the data races contained
+// in the data field of the given gtr.Test t. If no races are present, ok will
+// be set to false.
+func GetRaceReports(t gtr.Test) (races []RaceReport, ok bool) {
+	if t.Data != nil {
+		if data, exists := t.Data[racesKey]; exists {
+			races, ok := data.([]RaceReport)
+			return races, ok
+		}
+	}
+	return nil, false
+}
+
+// AddRaceReport is a helper function that adds the data race r to the data
+// field of the given gtr.Test t.
+func AddRaceReport(t *gtr.Test, r RaceReport) {
+	if t.Data != nil {
+		races, _ := GetRaceReports(*t)
+		t.Data[racesKey] = append(races, r)
+	}
+}
+
+// parseRaceReport parses the given lines of a race detector report, excluding
+// the surrounding separators.
+func parseRaceReport(lines []string) RaceReport {
+	r := RaceReport{Output: lines}
+	var stack *[]StackFrame
+	for _, line := range lines {
+		if matches := regexRaceAccess.FindStringSubmatch(line); matches != nil {
+			r.Accesses = append(r.Accesses, RaceAccess{Op: matches[1], Addr: matches[2], Goroutine: matches[3]})
+			stack = &r.Accesses[len(r.Accesses)-1].Stack
+		} else if matches := regexRaceGoroutine.FindStringSubmatch(line); matches != nil {
+			id, _ := strconv.Atoi(matches[1])
+			r.Goroutines = append(r.Goroutines, RaceGoroutine{ID: id, State: matches[2]})
+			stack = &r.Goroutines[len(r.Goroutines)-1].Stack
+		} else if stack == nil || strings.TrimSpace(line) == "" {
+			stack = nil
+		} else if matches := regexStackLocation.FindStringSubmatch(line); matches != nil && len(*stack) > 0 {
+			frame := &(*stack)[len(*stack)-1]
+			frame.File = matches[1]
+			frame.Line, _ = strconv.Atoi(matches[2])
+		} else {
+			*stack = append(*stack, StackFrame{Function: strings.TrimSpace(line)})
+		}
+	}
+	return r
+}
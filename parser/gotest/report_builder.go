@@ -2,6 +2,7 @@ package gotest
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -14,6 +15,19 @@ const (
 	globalID = 0
 )
 
+// regexLogLine matches output logged by a test using t.Log and friends, which
+// is prefixed with the file and line number of the call.
+var regexLogLine = regexp.MustCompile(`^\s*[^\s:]+\.go:\d+: (.*)$`)
+
+// regexAttachment matches output lines that refer to a file using the
+// [[ATTACHMENT|path]] convention of the Jenkins JUnit Attachments plugin,
+// optionally logged using t.Log.
+var regexAttachment = regexp.MustCompile(`^\s*(?:[^\s:]+\.go:\d+: )?\[\[ATTACHMENT\|([^\]]+)\]\]\s*$`)
+
+// regexMarker matches output lines containing a `--- LABEL: value`, `--- LINK:
+// url` or `--- PROPERTY: name=value` marker, optionally logged using t.Log.
+var regexMarker = regexp.MustCompile(`^\s*(?:[^\s:]+\.go:\d+: )?--- (LABEL|LINK|PROPERTY): (.*\S)\s*$`)
+
 // reportBuilder helps build a test Report from a collection of events.
 //
 // The reportBuilder delegates to the packageBuilder for creating packages from
@@ -26,15 +40,27 @@ const (
 type reportBuilder struct {
 	packageBuilders map[string]*packageBuilder
 	buildErrors     map[int]gtr.Error
+	buildTimes      map[int]*timeRange // timestamps seen for each build error
+	activeBuildID   int                // id of the build error receiving output
 
 	nextID   int               // next free unused id
 	output   *collector.Output // output collected for each id
 	packages []gtr.Package     // completed packages
 
 	// options
-	packageName   string
-	subtestMode   SubtestMode
-	timestampFunc func() time.Time
+	packageName       string
+	subtestMode       SubtestMode
+	failureExtractors []FailureExtractor
+	properties        []gtr.Property // properties added to every package
+	timestampFunc     func() time.Time
+	eventTime         bool              // use the time of the first event as package timestamp
+	dropPassedOutput  bool              // discard the output of tests that passed
+	outputLimits      gtr.OutputLimits  // limits applied to completed packages
+	packageHandler    func(gtr.Package) // called with every package completed by its summary
+	warningHandler    func(Warning)     // called with every warning, if set
+	hostname          string
+	shardIndex        int
+	shardCount        int // zero if the shard is unknown
 }
 
 // newReportBuilder creates a new reportBuilder.
@@ -42,12 +68,24 @@ func newReportBuilder() *reportBuilder {
 	return &reportBuilder{
 		packageBuilders: make(map[string]*packageBuilder),
 		buildErrors:     make(map[int]gtr.Error),
+		buildTimes:      make(map[int]*timeRange),
 		nextID:          1,
 		output:          collector.New(),
 		timestampFunc:   time.Now,
 	}
 }
 
+// SetHostname sets the hostname of the report and its packages.
+func (b *reportBuilder) SetHostname(hostname string) {
+	b.hostname = hostname
+}
+
+// SetShard sets the shard of the report and its packages to the zero-based
+// index of count shards.
+func (b *reportBuilder) SetShard(index, count int) {
+	b.shardIndex, b.shardCount = index, count
+}
+
 // getPackageBuilder returns the packageBuilder for the given packageName. If
 // no packageBuilder exists for the given package, a new one is created.
 func (b *reportBuilder) getPackageBuilder(packageName string) *packageBuilder {
@@ -65,41 +103,158 @@ func (b *reportBuilder) getPackageBuilder(packageName string) *packageBuilder {
 
 // ProcessEvent takes a test event and adds it to the report.
 func (b *reportBuilder) ProcessEvent(ev Event) {
-	switch ev.Type {
-	case "run_test":
-		b.getPackageBuilder(ev.Package).CreateTest(ev.Name)
-	case "pause_test":
-		b.getPackageBuilder(ev.Package).PauseTest(ev.Name)
-	case "cont_test":
-		b.getPackageBuilder(ev.Package).ContinueTest(ev.Name)
-	case "end_test":
-		b.getPackageBuilder(ev.Package).EndTest(ev.Name, ev.Result, ev.Duration, ev.Indent)
-	case "run_benchmark":
-		b.getPackageBuilder(ev.Package).CreateTest(ev.Name)
-	case "benchmark":
-		b.getPackageBuilder(ev.Package).BenchmarkResult(ev.Name, ev.Iterations, ev.NsPerOp, ev.MBPerSec, ev.BytesPerOp, ev.AllocsPerOp)
-	case "end_benchmark":
-		b.getPackageBuilder(ev.Package).EndTest(ev.Name, ev.Result, 0, 0)
-	case "status":
-		b.getPackageBuilder(ev.Package).End()
-	case "summary":
+	typed, err := ev.Typed()
+	if err != nil {
+		// This shouldn't happen, but just in case report a warning and ignore
+		// this event.
+		b.warn(ev.Package, ev.Name, WarningUnknownEvent, "unhandled event type: %v", ev.Type)
+		if pb, ok := b.packageBuilders[ev.Package]; ok {
+			pb.times.Add(ev.Time)
+		}
+		return
+	}
+	b.process(typed)
+}
+
+// process adds the typed event ev to the report.
+func (b *reportBuilder) process(ev TypedEvent) {
+	var pkg string
+	var t time.Time
+	switch ev := ev.(type) {
+	case RunTest:
+		pkg, t = ev.Package, ev.Time
+		b.activeBuildID = 0
+		pb := b.getPackageBuilder(ev.Package)
+		id := pb.CreateTest(ev.Name)
+		pb.SetStartTime(id, ev.Time)
+		pb.StartRunning(id, ev.Time)
+	case PauseTest:
+		pkg, t = ev.Package, ev.Time
+		b.getPackageBuilder(ev.Package).PauseTest(ev.Name, ev.Time)
+	case ContTest:
+		pkg, t = ev.Package, ev.Time
+		b.getPackageBuilder(ev.Package).ContinueTest(ev.Name, ev.Time)
+	case EndTest:
+		pkg, t = ev.Package, ev.Time
+		pb := b.getPackageBuilder(ev.Package)
+		if _, ok := pb.findTest(ev.Name); !ok {
+			b.warn(ev.Package, ev.Name, WarningTestNotStarted, "test ended without having been started, go test may have run without -v")
+		}
+		pb.EndTest(ev.Name, ev.Result, ev.Duration, ev.Indent)
+		pb.SetEndTime(ev.Name, ev.Time)
+		if ev.Note != "" {
+			// Annotations following the test result are added to the output
+			// of the test that just ended.
+			pb.TestOutput(ev.Name, ev.Note)
+		}
+		if b.dropPassedOutput {
+			pb.DropPassedOutput(ev.Name)
+		}
+		if ev.Result == "FAIL" && isExample(ev.Name) {
+			// The got and want output of a failed example is printed after
+			// its result.
+			pb.ResumeOutput(ev.Name)
+		}
+	case RunBenchmark:
+		pkg, t = ev.Package, ev.Time
+		b.activeBuildID = 0
+		pb := b.getPackageBuilder(ev.Package)
+		id := pb.CreateTest(ev.Name)
+		pb.SetStartTime(id, ev.Time)
+		pb.StartRunning(id, ev.Time)
+	case BenchmarkResult:
+		pkg, t = ev.Package, ev.Time
+		pb := b.getPackageBuilder(ev.Package)
+		pb.BenchmarkResult(ev.Name, ev.Benchmark)
+		pb.SetEndTime(ev.Name, ev.Time)
+	case EndBenchmark:
+		pkg, t = ev.Package, ev.Time
+		pb := b.getPackageBuilder(ev.Package)
+		pb.EndTest(ev.Name, ev.Result, 0, 0)
+		pb.SetEndTime(ev.Name, ev.Time)
+	case Panic:
+		pkg, t = ev.Package, ev.Time
+		b.getPackageBuilder(ev.Package).Panic(ev.Line)
+	case Race:
+		pkg, t = ev.Package, ev.Time
+		b.getPackageBuilder(ev.Package).Race(ev.Lines)
+	case Sanitizer:
+		pkg, t = ev.Package, ev.Time
+		b.getPackageBuilder(ev.Package).Sanitizer(ev.Lines)
+	case FuzzProgress:
+		pkg, t = ev.Package, ev.Time
+		b.getPackageBuilder(ev.Package).FuzzProgress(ev.Corpus, ev.Execs, ev.ExecsPerSec, ev.NewInteresting)
+	case FuzzFailure:
+		pkg, t = ev.Package, ev.Time
+		b.getPackageBuilder(ev.Package).FuzzFailure(ev.Name, ev.Path)
+	case Shuffle:
+		pkg, t = ev.Package, ev.Time
+		b.getPackageBuilder(ev.Package).shuffleSeed = ev.Seed
+	case Status:
+		pkg, t = ev.Package, ev.Time
+		// The overall PASS/FAIL status printed at the end of a `go test ./...`
+		// run doesn't belong to any package, so we don't want it to create a
+		// new one.
+		if pb, ok := b.packageBuilders[ev.Package]; ok {
+			pb.End()
+		}
+	case Summary:
+		pkg, t = ev.Package, ev.Time
 		// The summary marks the end of a package. We can now create the actual
 		// package from all the events we've processed so far for this package.
-		b.packages = append(b.packages, b.CreatePackage(ev.Package, ev.Name, ev.Result, ev.Duration, ev.Data))
-	case "coverage":
-		b.getPackageBuilder(ev.Package).Coverage(ev.CovPct, ev.CovPackages)
-	case "build_output":
+		if pb, ok := b.packageBuilders[ev.Package]; ok {
+			pb.times.Add(ev.Time)
+		}
+		created := b.addPackage(b.CreatePackage(ev.Package, ev.Name, ev.Result, ev.Duration, ev.Note))
+		if b.packageHandler != nil {
+			b.packageHandler(b.complete(created))
+		}
+	case Coverage:
+		pkg, t = ev.Package, ev.Time
+		b.getPackageBuilder(ev.Package).Coverage(ev.Percent, ev.Packages)
+	case BuildOutput:
+		pkg, t = ev.Package, ev.Time
 		b.CreateBuildError(ev.Name)
-	case "output":
-		if ev.Package != "" {
-			b.getPackageBuilder(ev.Package).Output(ev.Data)
+		b.buildTimes[b.activeBuildID].Add(ev.Time)
+	case InfraError:
+		pkg, t = ev.Package, ev.Time
+		if ev.Package == "" && b.activeBuildID != 0 {
+			// Output of a build error, not of any package.
+			b.output.Append(ev.Line)
+			b.buildTimes[b.activeBuildID].Add(ev.Time)
 		} else {
-			b.output.Append(ev.Data)
+			b.getPackageBuilder(ev.Package).InfraError(ev.Line)
 		}
-	default:
-		// This shouldn't happen, but just in case print a warning and ignore
-		// this event.
-		fmt.Printf("reportBuilder: unhandled event type: %v\n", ev.Type)
+	case Output:
+		pkg, t = ev.Package, ev.Time
+		if ev.Stream == "stderr" {
+			b.getPackageBuilder(ev.Package).Stderr(ev.Test, ev.Line)
+		} else if ev.Package != "" && ev.Test != "" {
+			b.getPackageBuilder(ev.Package).OutputFrom(ev.Test, ev.Line)
+		} else if ev.Package != "" {
+			b.getPackageBuilder(ev.Package).Output(ev.Line)
+		} else {
+			if pb, ok := b.packageBuilders[ev.Package]; ok {
+				pb.Output(ev.Line)
+			} else {
+				b.output.Append(ev.Line)
+			}
+			if b.activeBuildID != 0 {
+				b.buildTimes[b.activeBuildID].Add(ev.Time)
+			}
+		}
+	}
+
+	if pb, ok := b.packageBuilders[pkg]; ok {
+		pb.times.Add(t)
+	}
+}
+
+// warn passes a warning about the given package and test to the warning
+// handler, if any.
+func (b *reportBuilder) warn(pkg, test, kind, format string, args ...interface{}) {
+	if b.warningHandler != nil {
+		b.warningHandler(Warning{Package: pkg, Test: test, Kind: kind, Message: fmt.Sprintf(format, args...)})
 	}
 }
 
@@ -113,19 +268,108 @@ func (b *reportBuilder) generateID() int {
 // Build returns the new Report containing all the tests, build errors and
 // their output created from the processed events.
 func (b *reportBuilder) Build() gtr.Report {
-	// Create packages for any leftover package builders.
+	// Create packages for any leftover package builders, ordered by name so
+	// the report doesn't depend on map iteration order.
+	var names []string
 	for name, pb := range b.packageBuilders {
-		if pb.IsEmpty() {
+		if !pb.IsEmpty() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := b.packageBuilders[""]; !ok && b.output.Contains(globalID) {
+		b.warn("", "", WarningUnattributedOutput, "output doesn't belong to any package (%d lines)", len(b.output.Get(globalID)))
+	}
+	for _, name := range names {
+		pkg := b.CreatePackage(name, b.packageName, "", 0, "")
+		if pkg.Name == "" && len(pkg.Tests) == 0 {
+			b.warn("", "", WarningUnattributedOutput, "output doesn't belong to any package (%d lines)", len(pkg.Output)+len(pkg.RunError.Output))
+		} else {
+			b.warn(pkg.Name, "", WarningMissingSummary, "package has no summary line, go test may have been interrupted")
+		}
+		b.addPackage(pkg)
+	}
+
+	// Create packages for any leftover build errors, in the order they were
+	// created.
+	for _, id := range b.buildErrorIDs() {
+		if buildErr, ok := b.buildErrors[id]; ok {
+			b.warn(buildErr.Name, "", WarningMissingSummary, "build error has no summary line")
+			b.addPackage(b.CreatePackage("", buildErr.Name, "", 0, ""))
+		}
+	}
+
+	for i := range b.packages {
+		b.packages[i].Output = dropControlTokens(b.packages[i].Output)
+	}
+	report := gtr.Report{Packages: b.packages}
+	if b.hostname != "" {
+		report.SetHostname(b.hostname)
+	}
+	if b.shardCount > 0 {
+		report.SetShard(b.shardIndex, b.shardCount)
+	}
+	return report
+}
+
+// addPackage adds pkg to the completed packages, after truncating its output
+// to the output limits, and returns the added package.
+func (b *reportBuilder) addPackage(pkg gtr.Package) gtr.Package {
+	if b.outputLimits != (gtr.OutputLimits{}) {
+		pkg.Output = dropControlTokens(pkg.Output)
+		pkg = gtr.TruncateOutput(gtr.Report{Packages: []gtr.Package{pkg}}, b.outputLimits).Packages[0]
+	}
+	b.packages = append(b.packages, pkg)
+	return pkg
+}
+
+// complete returns pkg as it will appear in the report returned by Build.
+func (b *reportBuilder) complete(pkg gtr.Package) gtr.Package {
+	pkg.Output = dropControlTokens(pkg.Output)
+	if pkg.Hostname == "" {
+		pkg.Hostname = b.hostname
+	}
+	if pkg.ShardCount == 0 {
+		pkg.ShardIndex, pkg.ShardCount = b.shardIndex, b.shardCount
+	}
+	return pkg
+}
+
+// dropControlTokens returns the given output without lines that only contain
+// one of the PASS, FAIL, SKIP or ok tokens printed by go test, which may be
+// left over when they were not recognized by the parser.
+func dropControlTokens(output []string) []string {
+	var lines []string
+	for _, line := range output {
+		switch strings.TrimSpace(line) {
+		case "PASS", "FAIL", "SKIP", "ok":
 			continue
 		}
-		b.packages = append(b.packages, b.CreatePackage(name, b.packageName, "", 0, ""))
+		lines = append(lines, line)
 	}
+	return lines
+}
 
-	// Create packages for any leftover build errors.
-	for _, buildErr := range b.buildErrors {
-		b.packages = append(b.packages, b.CreatePackage("", buildErr.Name, "", 0, ""))
+// buildErrorIDs returns the ids of the build errors that haven't been added
+// to a package yet, in the order they were created.
+func (b *reportBuilder) buildErrorIDs() []int {
+	var ids []int
+	for id := range b.buildErrors {
+		ids = append(ids, id)
 	}
-	return gtr.Report{Packages: b.packages}
+	sort.Ints(ids)
+	return ids
+}
+
+// removeBuild removes the build error with the given id, after it has been
+// added to a package.
+func (b *reportBuilder) removeBuild(id int) {
+	delete(b.buildErrors, id)
+	delete(b.buildTimes, id)
+	if b.activeBuildID == id {
+		b.activeBuildID = 0
+	}
+	b.output.SetActiveID(0)
 }
 
 // CreateBuildError creates a new build error and marks it as active.
@@ -133,6 +377,8 @@ func (b *reportBuilder) CreateBuildError(packageName string) {
 	id := b.generateID()
 	b.output.SetActiveID(id)
 	b.buildErrors[id] = gtr.Error{ID: id, Name: packageName}
+	b.buildTimes[id] = &timeRange{}
+	b.activeBuildID = id
 }
 
 // CreatePackage returns a new package containing all the build errors, output,
@@ -142,23 +388,41 @@ func (b *reportBuilder) CreateBuildError(packageName string) {
 // case the packageName was unknown until this point.
 func (b *reportBuilder) CreatePackage(packageName, newPackageName, result string, duration time.Duration, data string) gtr.Package {
 	pkg := gtr.Package{
-		Name:      newPackageName,
-		Duration:  duration,
-		Timestamp: b.timestampFunc(),
+		Name:        newPackageName,
+		Duration:    duration,
+		Timestamp:   b.timestampFunc(),
+		NoTestFiles: strings.Contains(data, "[no test files]"),
+		Cached:      strings.Contains(data, "(cached)"),
+		Properties:  append([]gtr.Property(nil), b.properties...),
 	}
 
 	// First check if this package contained a build error. If that's the case,
-	// we won't find any tests in this package.
-	for id, buildErr := range b.buildErrors {
+	// we won't find any tests in this package. Build output of a package whose
+	// summary says it passed, such as compiler warnings, is not an error, but
+	// it's kept in the package output and its duration is recorded.
+	var buildOutput []string
+	for _, id := range b.buildErrorIDs() {
+		buildErr := b.buildErrors[id]
 		if buildErr.Name == newPackageName || strings.TrimSuffix(buildErr.Name, "_test") == newPackageName {
+			if result == "ok" || result == "?" {
+				buildOutput = append(buildOutput, b.output.Get(id)...)
+				pkg.BuildDuration += b.buildTimes[id].Duration()
+				b.output.Clear(id)
+				b.removeBuild(id)
+				continue
+			}
 			pkg.BuildError = buildErr
 			pkg.BuildError.ID = id
 			pkg.BuildError.Duration = duration
 			pkg.BuildError.Cause = data
 			pkg.BuildError.Output = b.output.Get(id)
+			pkg.BuildError.Diagnostics = gtr.ParseDiagnostics(pkg.BuildError.Output)
+			pkg.BuildDuration = b.buildTimes[id].Duration()
+			if r := b.buildTimes[id]; r != nil {
+				pkg.StartTime, pkg.EndTime = r.start, r.end
+			}
 
-			delete(b.buildErrors, id)
-			b.output.SetActiveID(0)
+			b.removeBuild(id)
 			return pkg
 		}
 	}
@@ -167,7 +431,21 @@ func (b *reportBuilder) CreatePackage(packageName, newPackageName, result string
 	// future events for this package will use a new packageBuilder.
 	pb := b.getPackageBuilder(packageName)
 	delete(b.packageBuilders, packageName)
+	pb.resolvePanic()
 	pb.output.SetActiveID(0)
+	if result == "ok" || result == "?" {
+		// Infrastructure errors can only explain packages that did not pass,
+		// in passing packages they're just output.
+		pb.infraErrors = nil
+	}
+	pkg.StartTime, pkg.EndTime = pb.times.start, pb.times.end
+	pkg.MaxParallel = pb.maxParallel
+	if pb.shuffleSeed != "" {
+		pkg.SetProperty(gtr.ShuffleSeedProperty, pb.shuffleSeed)
+	}
+	if b.eventTime && !pkg.StartTime.IsZero() {
+		pkg.Timestamp = pkg.StartTime
+	}
 
 	// If the packageBuilder is empty, we never received any events for this
 	// package so there's no need to continue.
@@ -179,31 +457,27 @@ func (b *reportBuilder) CreatePackage(packageName, newPackageName, result string
 				Name: newPackageName,
 			}
 		}
+		pkg.Output = buildOutput
 		return pkg
 	}
 
 	// If we've collected output, but there were no tests, then this package
 	// had a runtime error or it simply didn't have any tests.
-	if pb.output.Contains(globalID) && len(pb.tests) == 0 {
-		if parseResult(result) == gtr.Fail {
-			pkg.RunError = gtr.Error{
-				Name:   newPackageName,
-				Output: pb.output.Get(globalID),
-			}
+	if (pb.output.Contains(globalID) || len(pb.stderr) > 0) && len(pb.tests) == 0 {
+		if parseResult(result) == gtr.Fail || len(pb.infraErrors) > 0 || len(pb.sanitizers) > 0 {
+			pkg.RunError = pb.runError(newPackageName)
 		} else {
-			pkg.Output = pb.output.Get(globalID)
+			pkg.Output = append(buildOutput, pb.output.Get(globalID)...)
 		}
+		pkg.Stderr = pb.stderr[globalID]
 		pb.output.Clear(globalID)
 		return pkg
 	}
 
 	// If the summary result says we failed, but there were no failing tests
 	// then something else must have failed.
-	if parseResult(result) == gtr.Fail && len(pb.tests) > 0 && !pb.containsFailures() {
-		pkg.RunError = gtr.Error{
-			Name:   newPackageName,
-			Output: pb.output.Get(globalID),
-		}
+	if parseResult(result) == gtr.Fail && len(pb.tests) > 0 && !pb.containsFailures() || len(pb.infraErrors) > 0 || len(pb.sanitizers) > 0 {
+		pkg.RunError = pb.runError(newPackageName)
 		pb.output.Clear(globalID)
 	}
 
@@ -215,10 +489,14 @@ func (b *reportBuilder) CreatePackage(packageName, newPackageName, result string
 				t.Result = gtr.Pass
 			} else if b.subtestMode == ExcludeParents {
 				pb.output.Merge(id, globalID)
+				if len(pb.stderr[id]) > 0 {
+					pb.stderr[globalID] = append(pb.stderr[globalID], pb.stderr[id]...)
+				}
 				continue
 			}
 		}
 		t.Output = pb.output.Get(id)
+		t.Stderr = pb.stderr[id]
 		tests = append(tests, t)
 	}
 	tests = groupBenchmarksByName(tests, b.output)
@@ -229,12 +507,115 @@ func (b *reportBuilder) CreatePackage(packageName, newPackageName, result string
 	})
 
 	pkg.Tests = groupBenchmarksByName(tests, pb.output)
+	for i := range pkg.Tests {
+		t := &pkg.Tests[i]
+		addMarkerProperties(t)
+		if t.Result == gtr.Skip {
+			t.SkipMessage = skipMessage(t.Output)
+		} else if t.Result == gtr.Fail && t.FailureType != gtr.ErrorKindTimeout {
+			// The output of a parent test usually doesn't describe why its
+			// subtests failed, so their messages aren't repeated.
+			if !hasFailedSubtest(pkg.Tests, *t) {
+				t.FailureMessage, t.FailureType = extractFailure(b.failureExtractors, t.Output)
+			}
+			if reports, ok := GetSanitizerReports(*t); ok {
+				t.FailureMessage, t.FailureType = reports[0].Message(), gtr.ErrorKindSanitizer
+			}
+			if m, ok := parseExampleMismatch(t.Output); ok && t.Kind == gtr.KindExample {
+				SetExampleData(t, m)
+				t.FailureMessage, t.FailureType = "got output does not match want", ExampleFailureType
+			}
+		}
+		t.Attachments = findAttachments(t.Output)
+	}
 	pkg.Coverage = pb.coverage
-	pkg.Output = pb.output.Get(globalID)
+	pkg.Output = append(buildOutput, pb.output.Get(globalID)...)
+	pkg.Stderr = pb.stderr[globalID]
+	pkg.Attachments = findAttachments(pkg.Output)
 	pb.output.Clear(globalID)
 	return pkg
 }
 
+// findAttachments returns an attachment for every [[ATTACHMENT|path]] line in
+// output.
+func findAttachments(output []string) []gtr.Attachment {
+	var attachments []gtr.Attachment
+	for _, line := range output {
+		if !strings.Contains(line, "[[ATTACHMENT|") {
+			continue
+		}
+		if m := regexAttachment.FindStringSubmatch(line); m != nil {
+			attachments = append(attachments, gtr.Attachment{Path: m[1]})
+		}
+	}
+	return attachments
+}
+
+// addMarkerProperties adds a property to test t for every marker line in its
+// output, and removes these lines from the output. Tests can print markers to
+// annotate their results: a `--- LABEL: value` line adds a label property, a
+// `--- LINK: url` line a link property and a `--- PROPERTY: name=value` line
+// sets the named property.
+func addMarkerProperties(t *gtr.Test) {
+	var output []string
+	for i, line := range t.Output {
+		var m []string
+		if strings.Contains(line, "--- ") {
+			m = regexMarker.FindStringSubmatch(line)
+		}
+		if m == nil {
+			if output != nil {
+				output = append(output, line)
+			}
+			continue
+		}
+		if output == nil {
+			output = append(make([]string, 0, len(t.Output)-1), t.Output[:i]...)
+		}
+		switch m[1] {
+		case "LABEL":
+			t.AddProperty("label", m[2])
+		case "LINK":
+			t.AddProperty("link", m[2])
+		case "PROPERTY":
+			if idx := strings.IndexByte(m[2], '='); idx > 0 {
+				t.SetProperty(strings.TrimSpace(m[2][:idx]), strings.TrimSpace(m[2][idx+1:]))
+			}
+		}
+	}
+	if output != nil {
+		t.Output = output
+	}
+}
+
+// hasFailedSubtest returns true if one of the subtests of test parent in tests
+// failed.
+func hasFailedSubtest(tests []gtr.Test, parent gtr.Test) bool {
+	for _, t := range tests {
+		if t.Level > parent.Level && strings.HasPrefix(t.Name, parent.Name+"/") && t.Result.Base() == gtr.Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// skipMessage returns the message of the last line logged by a skipped test,
+// which is where t.Skip writes its arguments. Indented lines following it are
+// included as continuation lines of a multiline message.
+func skipMessage(output []string) string {
+	var lines []string
+	for _, line := range output {
+		if m := regexLogLine.FindStringSubmatch(line); m != nil {
+			lines = []string{m[1]}
+		} else if len(lines) > 0 && strings.HasPrefix(line, " ") {
+			lines = append(lines, strings.TrimSpace(line))
+		} else {
+			lines = nil
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // parseResult returns a gtr.Result for the given result string r.
 func parseResult(r string) gtr.Result {
 	switch r {
@@ -278,9 +659,10 @@ func groupBenchmarksByName(tests []gtr.Test, output *collector.Output) []gtr.Tes
 			continue
 		}
 		var (
-			ids   []int
-			total Benchmark
-			count int
+			ids          []int
+			total        Benchmark
+			count        int
+			metricCounts map[string]int
 		)
 		for _, test := range byName[group.Name] {
 			ids = append(ids, test.ID)
@@ -294,6 +676,14 @@ func groupBenchmarksByName(tests []gtr.Test, output *collector.Output) []gtr.Tes
 				total.MBPerSec += bench.MBPerSec
 				total.BytesPerOp += bench.BytesPerOp
 				total.AllocsPerOp += bench.AllocsPerOp
+				for unit, value := range bench.Metrics {
+					if total.Metrics == nil {
+						total.Metrics = make(map[string]float64)
+						metricCounts = make(map[string]int)
+					}
+					total.Metrics[unit] += value
+					metricCounts[unit]++
+				}
 				count++
 			}
 		}
@@ -307,6 +697,9 @@ func groupBenchmarksByName(tests []gtr.Test, output *collector.Output) []gtr.Tes
 			total.MBPerSec /= float64(count)
 			total.BytesPerOp /= int64(count)
 			total.AllocsPerOp /= int64(count)
+			for unit, n := range metricCounts {
+				total.Metrics[unit] /= float64(n)
+			}
 			SetBenchmarkData(&group, total)
 		}
 		grouped[i] = group
@@ -337,14 +730,54 @@ func groupResults(tests []gtr.Test) gtr.Result {
 	return result
 }
 
+// timeRange keeps track of the first and last time something was seen.
+type timeRange struct {
+	start, end time.Time
+}
+
+// Add extends the range to include t. Zero times are ignored.
+func (r *timeRange) Add(t time.Time) {
+	if r == nil || t.IsZero() {
+		return
+	}
+	if r.start.IsZero() {
+		r.start = t
+	}
+	r.end = t
+}
+
+// Duration returns the time between the first and last time in this range.
+func (r *timeRange) Duration() time.Duration {
+	if r == nil {
+		return 0
+	}
+	return r.end.Sub(r.start)
+}
+
 // packageBuilder helps build a gtr.Package from a collection of test events.
 type packageBuilder struct {
 	generateID func() int
 	output     *collector.Output
 
-	tests     map[int]gtr.Test
-	parentIDs map[int]struct{} // set of test id's that contain subtests
-	coverage  float64          // coverage percentage
+	tests       map[int]gtr.Test
+	testIDs     map[string]int    // id of the most recently created test, by name
+	parentIDs   map[int]struct{}  // set of test id's that contain subtests
+	coverage    float64           // coverage percentage
+	infraErrors []string          // infrastructure errors found outside tests
+	sanitizers  []SanitizerReport // sanitizer reports found outside tests
+	stderr      map[int][]string  // output written to stderr, by test id
+	lastFailed  int               // id of the most recently failed test
+	running     map[int]time.Time // tests that are running and not paused, by id, with the time they started or continued
+	maxParallel int               // maximum number of tests running at the same time
+	panic       *gtr.PanicInfo    // panic that hasn't been attributed yet
+	panicID     int               // output id of the panic
+	panicFrom   int               // active id when the panic started
+	pkgPanic    *gtr.PanicInfo    // panic that didn't occur in any test
+	shuffleSeed string            // seed used to shuffle the tests, if any
+	times       timeRange         // times of the first and last event, if known
+	ended       []int             // ids of the most recently ended tests, by level
+	logID       int               // id of the ended test receiving the current log entry
+	logLevel    int               // indentation level of the current log entry
 }
 
 // newPackageBuilder creates a new packageBuilder. New tests will be assigned
@@ -355,14 +788,16 @@ func newPackageBuilder(generateID func() int, output *collector.Output) *package
 		generateID: generateID,
 		output:     output,
 		tests:      make(map[int]gtr.Test),
+		testIDs:    make(map[string]int),
 		parentIDs:  make(map[int]struct{}),
+		running:    make(map[int]time.Time),
 	}
 }
 
 // IsEmpty returns true if this package builder does not have any tests and has
 // not collected any global output.
 func (b packageBuilder) IsEmpty() bool {
-	return len(b.tests) == 0 && !b.output.Contains(0)
+	return len(b.tests) == 0 && !b.output.Contains(0) && b.panic == nil && len(b.stderr) == 0
 }
 
 // CreateTest adds a test with the given name to the package, marks it as
@@ -373,22 +808,38 @@ func (b *packageBuilder) CreateTest(name string) int {
 	}
 	id := b.generateID()
 	b.output.SetActiveID(id)
-	b.tests[id] = gtr.NewTest(id, name)
+	test := gtr.NewTest(id, name)
+	if isExample(name) {
+		test.Kind = gtr.KindExample
+	}
+	b.tests[id] = test
+	b.testIDs[name] = id
+	b.lastFailed = 0
 	return id
 }
 
-// PauseTest marks the test with the given name no longer active. Any results
-// or output added to the package after calling PauseTest will no longer be
-// associated with this test.
-func (b *packageBuilder) PauseTest(name string) {
+// PauseTest marks the test with the given name no longer active and stops its
+// run time at time t. Any results or output added to the package after calling
+// PauseTest will no longer be associated with this test.
+func (b *packageBuilder) PauseTest(name string, t time.Time) {
+	if id, ok := b.findTest(name); ok {
+		b.stopRunning(id, t)
+	}
+	b.ended, b.logID = nil, 0
 	b.output.SetActiveID(0)
 }
 
-// ContinueTest finds the test with the given name and marks it as active. If
-// more than one test exist with this name, the most recently created test will
-// be used.
-func (b *packageBuilder) ContinueTest(name string) {
-	id, _ := b.findTest(name)
+// ContinueTest finds the test with the given name, marks it as active and
+// resumes its run time at time t. If more than one test exist with this name,
+// the most recently created test will be used. If no test exists with this
+// name, any output that follows will be associated with the package instead
+// of the previously active test.
+func (b *packageBuilder) ContinueTest(name string, t time.Time) {
+	id, ok := b.findTest(name)
+	if ok {
+		b.StartRunning(id, t)
+	}
+	b.ended, b.logID = nil, 0
 	b.output.SetActiveID(id)
 }
 
@@ -407,56 +858,466 @@ func (b *packageBuilder) EndTest(name, result string, duration time.Duration, le
 
 	t := b.tests[id]
 	t.Result = parseResult(result)
+	if _, raced := GetRaceReports(t); raced {
+		t.Result = gtr.Fail
+	}
+	if _, ok := GetSanitizerReports(t); ok {
+		t.Result = gtr.Fail
+	}
 	t.Duration = duration
 	t.Level = level
 	b.tests[id] = t
+	if t.Result == gtr.Fail {
+		b.lastFailed = id
+	}
+	if level < len(b.ended) {
+		b.ended = b.ended[:level]
+	}
+	for len(b.ended) < level {
+		b.ended = append(b.ended, 0)
+	}
+	b.ended = append(b.ended, id)
+	b.logID = 0
 	b.output.SetActiveID(0)
 }
 
+// ResumeOutput associates the output that follows with the most recently
+// created test with the given name, without marking it as running again.
+func (b *packageBuilder) ResumeOutput(name string) {
+	if id, ok := b.findTest(name); ok {
+		b.output.SetActiveID(id)
+	}
+}
+
+// SetStartTime sets the start time of the test with the given id, unless t is
+// the zero time.
+func (b *packageBuilder) SetStartTime(id int, t time.Time) {
+	if test, ok := b.tests[id]; ok && !t.IsZero() {
+		test.StartTime = t
+		b.tests[id] = test
+	}
+}
+
+// SetEndTime marks the most recently created test with the given name as no
+// longer running and sets its end time and wall duration, unless t is the
+// zero time.
+func (b *packageBuilder) SetEndTime(name string, t time.Time) {
+	id, ok := b.findTest(name)
+	if !ok {
+		return
+	}
+	b.stopRunning(id, t)
+	if !t.IsZero() {
+		test := b.tests[id]
+		test.EndTime = t
+		if !test.StartTime.IsZero() {
+			test.WallDuration = t.Sub(test.StartTime)
+		}
+		b.tests[id] = test
+	}
+}
+
+// StartRunning marks the test with the given id as running since time t and
+// updates the maximum parallelism of the package.
+func (b *packageBuilder) StartRunning(id int, t time.Time) {
+	if _, ok := b.running[id]; ok {
+		return
+	}
+	b.running[id] = t
+	if n := b.parallelism(); n > b.maxParallel {
+		b.maxParallel = n
+	}
+}
+
+// stopRunning marks the test with the given id as no longer running and adds
+// the time it ran until time t to its run duration, if known.
+func (b *packageBuilder) stopRunning(id int, t time.Time) {
+	since, ok := b.running[id]
+	if !ok {
+		return
+	}
+	delete(b.running, id)
+	if test, ok := b.tests[id]; ok && !since.IsZero() && !t.IsZero() {
+		test.RunDuration += t.Sub(since)
+		b.tests[id] = test
+	}
+}
+
+// parallelism returns the number of tests that are currently running. Tests
+// that are only waiting for their running subtests to finish are not counted.
+func (b *packageBuilder) parallelism() int {
+	n := 0
+	for id := range b.running {
+		prefix := b.tests[id].Name + "/"
+		waiting := false
+		for other := range b.running {
+			if strings.HasPrefix(b.tests[other].Name, prefix) {
+				waiting = true
+				break
+			}
+		}
+		if !waiting {
+			n++
+		}
+	}
+	return n
+}
+
 // End resets the active test.
 func (b *packageBuilder) End() {
 	b.output.SetActiveID(0)
 }
 
 // BenchmarkResult updates an existing or adds a new test with the given
-// results and marks it as active. If an existing test with this name exists
-// but without result, then that one is updated. Otherwise a new one is added
-// to the report.
-func (b *packageBuilder) BenchmarkResult(name string, iterations int64, nsPerOp, mbPerSec float64, bytesPerOp, allocsPerOp int64) {
+// benchmark results and marks it as active. If an existing test with this
+// name exists but without result, then that one is updated. Otherwise a new
+// one is added to the report.
+func (b *packageBuilder) BenchmarkResult(name string, benchmark Benchmark) {
 	id, ok := b.findTest(name)
 	if !ok || b.tests[id].Result != gtr.Unknown {
 		id = b.CreateTest(name)
 	}
 	b.output.SetActiveID(id)
 
-	benchmark := Benchmark{iterations, nsPerOp, mbPerSec, bytesPerOp, allocsPerOp}
 	test := gtr.NewTest(id, name)
+	test.StartTime = b.tests[id].StartTime
 	test.Result = gtr.Pass
 	test.Duration = benchmark.ApproximateDuration()
 	SetBenchmarkData(&test, benchmark)
 	b.tests[id] = test
 }
 
+// Race adds the data race report in lines to the active test. Races found
+// outside of tests are only kept in the output.
+func (b *packageBuilder) Race(lines []string) {
+	test, ok := b.tests[b.output.ActiveID()]
+	if !ok {
+		return
+	}
+	AddRaceReport(&test, parseRaceReport(lines))
+	if test.Result != gtr.Unknown {
+		test.Result = gtr.Fail
+	}
+	b.tests[b.output.ActiveID()] = test
+}
+
+// Sanitizer adds the sanitizer report in lines to the active test and marks
+// it as failed. Since sanitizers usually abort the test binary, the test may
+// never report a result itself. Reports found outside of tests are reported
+// as a runtime error of the package.
+func (b *packageBuilder) Sanitizer(lines []string) {
+	r := parseSanitizerReport(lines)
+	test, ok := b.tests[b.output.ActiveID()]
+	if !ok {
+		b.sanitizers = append(b.sanitizers, r)
+		return
+	}
+	AddSanitizerReport(&test, r)
+	test.Result = gtr.Fail
+	b.tests[b.output.ActiveID()] = test
+}
+
+// FuzzProgress updates the fuzz results of the active test with the given
+// progress. Only the non-zero values are updated.
+func (b *packageBuilder) FuzzProgress(corpus int, execs, execsPerSec int64, newInteresting int) {
+	test, ok := b.tests[b.output.ActiveID()]
+	if !ok {
+		return
+	}
+	fuzz, _ := GetFuzzData(test)
+	if corpus > 0 {
+		fuzz.SeedCorpus = corpus
+	}
+	if execs > 0 {
+		fuzz.Execs = execs
+		fuzz.ExecsPerSec = execsPerSec
+		fuzz.NewInteresting = newInteresting
+	}
+	SetFuzzData(&test, fuzz)
+}
+
+// FuzzFailure records the path of the failing input written for the most
+// recently created test with the given name.
+func (b *packageBuilder) FuzzFailure(name, path string) {
+	id, ok := b.findTest(name)
+	if !ok {
+		return
+	}
+	test := b.tests[id]
+	fuzz, _ := GetFuzzData(test)
+	fuzz.FailingInput = path
+	SetFuzzData(&test, fuzz)
+}
+
 // Coverage sets the code coverage percentage.
 func (b *packageBuilder) Coverage(pct float64, packages []string) {
 	b.coverage = pct
 }
 
-// Output appends data to the output of this package.
-func (b *packageBuilder) Output(data string) {
+// TestOutput appends data to the output of the most recently created test
+// with the given name. The data is discarded if no such test exists.
+func (b *packageBuilder) TestOutput(name, data string) {
+	if id, ok := b.findTest(name); ok {
+		b.output.AppendToID(id, data)
+	}
+}
+
+// DropPassedOutput discards the output collected for the most recently
+// created test with the given name if it passed.
+func (b *packageBuilder) DropPassedOutput(name string) {
+	if id, ok := b.findTest(name); ok && b.tests[id].Result == gtr.Pass {
+		b.output.Clear(id)
+		delete(b.stderr, id)
+	}
+}
+
+// InfraError adds data to the output of this package. If no test is active,
+// data is also recorded as an infrastructure error.
+func (b *packageBuilder) InfraError(data string) {
+	if b.output.ActiveID() == globalID {
+		b.infraErrors = append(b.infraErrors, data)
+	}
 	b.output.Append(data)
 }
 
+// runError returns a run error for the package with the given name containing
+// the package output. If any infrastructure errors were found, the first one
+// is used as its cause.
+func (b *packageBuilder) runError(name string) gtr.Error {
+	e := gtr.Error{
+		Name:   name,
+		Output: b.output.Get(globalID),
+	}
+	if len(b.infraErrors) > 0 {
+		e.Kind = gtr.ErrorKindInfra
+		e.Cause = b.infraErrors[0]
+	}
+	if len(b.sanitizers) > 0 && e.Kind == "" {
+		e.Kind = gtr.ErrorKindSanitizer
+		e.Cause = b.sanitizers[0].Message()
+	}
+	e.Panic = b.pkgPanic
+	if e.Panic != nil && regexTimeout.MatchString(e.Panic.Message) && e.Kind == "" {
+		e.Kind = gtr.ErrorKindTimeout
+		e.Cause = e.Panic.Message
+	}
+	return e
+}
+
+// Output appends data to the output of this package, or to the output of the
+// test it belongs to, see endedTestID.
+func (b *packageBuilder) Output(data string) {
+	if id := b.endedTestID(data); id != 0 {
+		b.output.AppendToID(id, data)
+	} else {
+		b.output.Append(data)
+	}
+	b.collectPanic(data)
+}
+
+// OutputFrom appends data to the output of the running test with the given
+// name, regardless of which test is active. This is used when the test that
+// printed data is known, as in the output of go test -json, so that the output
+// of parallel tests is attributed correctly even when their output is
+// interleaved. If the test isn't running, data is added like Output instead:
+// go test -json attributes the output of benchmarks and the output printed
+// after a test has ended to the wrong test.
+func (b *packageBuilder) OutputFrom(name, data string) {
+	id, ok := b.findTest(name)
+	if _, running := b.running[id]; !ok || !running {
+		b.Output(data)
+		return
+	}
+	b.output.AppendToID(id, data)
+	b.collectPanic(data)
+}
+
+// Stderr appends data written to stderr to the stderr output of the running
+// test with the given name, or to that of the package if no such test is
+// running.
+func (b *packageBuilder) Stderr(name, data string) {
+	id := globalID
+	if testID, ok := b.findTest(name); ok && name != "" {
+		if _, running := b.running[testID]; running {
+			id = testID
+		}
+	}
+	if b.stderr == nil {
+		b.stderr = make(map[int][]string)
+	}
+	b.stderr[id] = append(b.stderr[id], data)
+	b.collectPanic(data)
+}
+
+// endedTestID returns the id of the test that printed output line data after
+// its result, or 0 if data doesn't belong to a test that ended. This happens
+// when running go test without -v, or with versions of Go before 1.14, which
+// print the output of a test indented below its result line:
+//
+//	--- FAIL: TestTable (0.00s)
+//	    --- FAIL: TestTable/case (0.00s)
+//	        table_test.go:12: failed
+//
+// Log lines with a file:line prefix belong to the test whose result is
+// indented one level less, and lines that are indented further continue the
+// preceding log line.
+func (b *packageBuilder) endedTestID(data string) int {
+	if len(b.ended) == 0 || b.output.ActiveID() != globalID {
+		return 0
+	}
+	line, level := outputLevel(data)
+	switch {
+	case level == 0:
+		b.ended, b.logID = nil, 0
+	case regexLogLine.MatchString(line) && level <= len(b.ended) && b.ended[level-1] != 0:
+		b.logID, b.logLevel = b.ended[level-1], level
+	case b.logID != 0 && level > b.logLevel:
+		// continuation of the current log entry
+	default:
+		b.logID = 0
+	}
+	return b.logID
+}
+
+// outputLevel returns line without its indentation, and the indentation level
+// of line. Each level is indented by four spaces, or a tab in versions of Go
+// before 1.14.
+func outputLevel(line string) (string, int) {
+	var level int
+	for {
+		if strings.HasPrefix(line, "    ") {
+			line = line[4:]
+		} else if strings.HasPrefix(line, "\t") {
+			line = line[1:]
+		} else {
+			return line, level
+		}
+		level++
+	}
+}
+
+// collectPanic adds data to the stack trace of the current panic, if any.
+func (b *packageBuilder) collectPanic(data string) {
+	if b.panic != nil && !regexExitStatus.MatchString(data) {
+		b.panic.Stack = append(b.panic.Stack, data)
+	}
+}
+
+// Panic starts collecting the output of the panic printed in line. Since the
+// panicking test can only be determined once the stack trace has been read,
+// the output is kept separately until resolvePanic is called.
+func (b *packageBuilder) Panic(line string) {
+	var message string
+	if matches := regexPanic.FindStringSubmatch(line); len(matches) == 2 {
+		message = matches[1]
+	}
+	b.panic = &gtr.PanicInfo{Message: message}
+	b.panicID = b.generateID()
+	b.panicFrom = b.output.ActiveID()
+	b.output.SetActiveID(b.panicID)
+	b.output.Append(line)
+}
+
+// resolvePanic attributes the panic, if any, to the test found in its stack
+// trace, marks that test as failed and moves the panic output to the test.
+// Otherwise the panic output is returned to where it would have been without
+// the panic, and the panic is kept as a package panic.
+func (b *packageBuilder) resolvePanic() {
+	if b.panic == nil {
+		return
+	}
+	p := b.panic
+	b.panic = nil
+
+	id := b.panicTestID(p.Stack)
+	timeout := regexTimeout.MatchString(p.Message)
+	if timeout {
+		if last := b.markTimedOut(p); id == 0 {
+			id = last
+		}
+	}
+	if id == 0 {
+		b.output.Merge(b.panicID, b.panicFrom)
+		b.pkgPanic = p
+		return
+	}
+	test := b.tests[id]
+	p.Test = test.Name
+	test.Panic = p
+	test.Result = gtr.Fail
+	if timeout {
+		test.FailureMessage = p.Message
+		test.FailureType = gtr.ErrorKindTimeout
+	}
+	b.tests[id] = test
+	b.output.Merge(b.panicID, id)
+}
+
+// markTimedOut marks the tests that were still running when the test binary
+// timed out with panic p as failed, with the duration they had been running.
+// Since Go 1.20 these tests are listed below the panic message. It returns the
+// id of the last listed test, or 0 if no listed test was found.
+func (b *packageBuilder) markTimedOut(p *gtr.PanicInfo) int {
+	var last int
+	for i, line := range p.Stack {
+		if i == 0 && line != "\trunning tests:" {
+			break
+		}
+		if i == 0 {
+			continue
+		}
+		matches := regexRunningTest.FindStringSubmatch(line)
+		if len(matches) != 3 {
+			break
+		}
+		id, ok := b.findTest(matches[1])
+		if !ok {
+			continue
+		}
+		test := b.tests[id]
+		if test.Result == gtr.Unknown || test.Result == gtr.Fail {
+			test.Result = gtr.Fail
+			test.FailureMessage = p.Message
+			test.FailureType = gtr.ErrorKindTimeout
+		}
+		if d, err := time.ParseDuration(matches[2]); err == nil && test.Duration == 0 {
+			test.Duration = d
+		}
+		b.tests[id] = test
+		last = id
+	}
+	return last
+}
+
+// panicTestID returns the id of the test that panicked with the given stack
+// trace, or 0 if it's unknown. The stack trace only contains the name of the
+// top level test, so the test that was active or most recently failed when the
+// panic occurred is preferred if it's a subtest of that test.
+func (b *packageBuilder) panicTestID(stack []string) int {
+	var name string
+	for _, line := range stack {
+		if matches := regexPanicTest.FindStringSubmatch(line); len(matches) == 2 && matches[1] != "TestMain" {
+			name = matches[1]
+			break
+		}
+	}
+	if name == "" {
+		return 0
+	}
+	for _, id := range []int{b.panicFrom, b.lastFailed} {
+		if test, ok := b.tests[id]; ok && (test.Name == name || strings.HasPrefix(test.Name, name+"/")) {
+			return id
+		}
+	}
+	id, _ := b.findTest(name)
+	return id
+}
+
 // findTest returns the id of the most recently created test with the given
 // name if it exists.
 func (b *packageBuilder) findTest(name string) (int, bool) {
-	var maxid int
-	for id, test := range b.tests {
-		if maxid < id && test.Name == name {
-			maxid = id
-		}
-	}
-	return maxid, maxid > 0
+	id, ok := b.testIDs[name]
+	return id, ok
 }
 
 // findTestParentID searches the existing tests in this package for a parent of
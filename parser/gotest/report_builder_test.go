@@ -2,6 +2,7 @@ package gotest
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -41,9 +42,10 @@ func TestReport(t *testing.T) {
 	want := gtr.Report{
 		Packages: []gtr.Package{
 			{
-				Name:      "package/name",
-				Duration:  1 * time.Millisecond,
-				Timestamp: testTimestamp,
+				Name:        "package/name",
+				Duration:    1 * time.Millisecond,
+				Timestamp:   testTimestamp,
+				MaxParallel: 1,
 				Tests: []gtr.Test{
 					{
 						ID:       1,
@@ -65,9 +67,10 @@ func TestReport(t *testing.T) {
 				},
 			},
 			{
-				Name:      "package/name2",
-				Duration:  1 * time.Millisecond,
-				Timestamp: testTimestamp,
+				Name:        "package/name2",
+				Duration:    1 * time.Millisecond,
+				Timestamp:   testTimestamp,
+				MaxParallel: 1,
 				Tests: []gtr.Test{
 					{
 						ID:       3,
@@ -82,9 +85,10 @@ func TestReport(t *testing.T) {
 				},
 			},
 			{
-				Name:      "package/name3",
-				Duration:  1234 * time.Millisecond,
-				Timestamp: testTimestamp,
+				Name:        "package/name3",
+				Duration:    1234 * time.Millisecond,
+				Timestamp:   testTimestamp,
+				MaxParallel: 1,
 				Tests: []gtr.Test{
 					{
 						ID:     4,
@@ -142,9 +146,10 @@ func TestBuildReportMultiplePackages(t *testing.T) {
 	want := gtr.Report{
 		Packages: []gtr.Package{
 			{
-				Name:      "package/name2",
-				Duration:  1 * time.Millisecond,
-				Timestamp: testTimestamp,
+				Name:        "package/name2",
+				Duration:    1 * time.Millisecond,
+				Timestamp:   testTimestamp,
+				MaxParallel: 1,
 				Tests: []gtr.Test{
 					{
 						ID:       2,
@@ -157,9 +162,10 @@ func TestBuildReportMultiplePackages(t *testing.T) {
 				},
 			},
 			{
-				Name:      "package/name1",
-				Duration:  1 * time.Millisecond,
-				Timestamp: testTimestamp,
+				Name:        "package/name1",
+				Duration:    1 * time.Millisecond,
+				Timestamp:   testTimestamp,
+				MaxParallel: 1,
 				Tests: []gtr.Test{
 					{
 						ID:       1,
@@ -185,6 +191,31 @@ func TestBuildReportMultiplePackages(t *testing.T) {
 	}
 }
 
+func TestBuildReportUnfinishedPackagesOrder(t *testing.T) {
+	var events []Event
+	for _, pkg := range []string{"package/c", "package/a", "package/b", "package/e", "package/d"} {
+		events = append(events,
+			Event{Package: pkg, Type: "run_test", Name: "Test" + pkg},
+			Event{Package: pkg, Type: "end_test", Name: "Test" + pkg, Result: "PASS"},
+		)
+	}
+	want := []string{"Testpackage/a", "Testpackage/b", "Testpackage/c", "Testpackage/d", "Testpackage/e"}
+
+	for i := 0; i < 10; i++ {
+		rb := newReportBuilder()
+		for _, ev := range events {
+			rb.ProcessEvent(ev)
+		}
+		var got []string
+		for _, pkg := range rb.Build().Packages {
+			got = append(got, pkg.Tests[0].Name)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("Incorrect package order, diff (-want, +got):\n%v", diff)
+		}
+	}
+}
+
 func TestSubtestModes(t *testing.T) {
 	events := []Event{
 		{Type: "run_test", Name: "TestParent"},
@@ -213,9 +244,10 @@ func TestSubtestModes(t *testing.T) {
 			want: gtr.Report{
 				Packages: []gtr.Package{
 					{
-						Name:      "package/name",
-						Duration:  1 * time.Millisecond,
-						Timestamp: testTimestamp,
+						Name:        "package/name",
+						Duration:    1 * time.Millisecond,
+						Timestamp:   testTimestamp,
+						MaxParallel: 2,
 						Tests: []gtr.Test{
 							{
 								ID:       1,
@@ -253,9 +285,10 @@ func TestSubtestModes(t *testing.T) {
 			want: gtr.Report{
 				Packages: []gtr.Package{
 					{
-						Name:      "package/name",
-						Duration:  1 * time.Millisecond,
-						Timestamp: testTimestamp,
+						Name:        "package/name",
+						Duration:    1 * time.Millisecond,
+						Timestamp:   testTimestamp,
+						MaxParallel: 2,
 						Tests: []gtr.Test{
 							{
 								ID:       2,
@@ -326,6 +359,16 @@ func TestGroupBenchmarksByName(t *testing.T) {
 				{ID: 1, Name: "BenchmarkOne", Result: gtr.Pass, Output: []string{"output-1", "output-2", "output-3", "output-4"}, Data: map[string]interface{}{key: Benchmark{NsPerOp: 25, MBPerSec: 250, BytesPerOp: 2, AllocsPerOp: 4}}},
 			},
 		},
+		{
+			"custom metrics",
+			[]gtr.Test{
+				{ID: 1, Name: "BenchmarkMetrics", Result: gtr.Pass, Data: map[string]interface{}{key: Benchmark{NsPerOp: 10, Metrics: map[string]float64{"requests/s": 100, "p99-ns": 30}}}},
+				{ID: 2, Name: "BenchmarkMetrics", Result: gtr.Pass, Data: map[string]interface{}{key: Benchmark{NsPerOp: 20, Metrics: map[string]float64{"requests/s": 50}}}},
+			},
+			[]gtr.Test{
+				{ID: 1, Name: "BenchmarkMetrics", Result: gtr.Pass, Output: []string{"output-1", "output-2"}, Data: map[string]interface{}{key: Benchmark{NsPerOp: 15, Metrics: map[string]float64{"requests/s": 75, "p99-ns": 30}}}},
+			},
+		},
 		{
 			"four mixed result benchmarks",
 			[]gtr.Test{
@@ -372,6 +415,60 @@ func TestReportSpecialCases(t *testing.T) {
 				},
 			},
 		},
+		{
+			"trailing-status",
+			[]Event{
+				{Type: "summary", Result: "ok", Name: "package/name", Duration: 1 * time.Millisecond},
+				{Type: "status", Result: "FAIL"},
+				{Type: "output", Data: "make: *** [test] Error 1"},
+			},
+			gtr.Report{
+				Packages: []gtr.Package{
+					{
+						Name:      "package/name",
+						Duration:  1 * time.Millisecond,
+						Timestamp: testTimestamp,
+					},
+				},
+			},
+		},
+		{
+			"passed-infra-error",
+			[]Event{
+				{Type: "infra_error", Data: "socket: too many open files"},
+				{Type: "status", Result: "PASS"},
+				{Type: "summary", Result: "ok", Name: "package/name", Duration: 1 * time.Millisecond},
+			},
+			gtr.Report{
+				Packages: []gtr.Package{
+					{
+						Name:      "package/name",
+						Duration:  1 * time.Millisecond,
+						Timestamp: testTimestamp,
+						Output:    []string{"socket: too many open files"},
+					},
+				},
+			},
+		},
+		{
+			"leftover-infra-error",
+			[]Event{
+				{Type: "output", Data: "go: downloading example.com/mod v1.0.0"},
+				{Type: "infra_error", Data: "open /tmp/go-build: too many open files"},
+			},
+			gtr.Report{
+				Packages: []gtr.Package{
+					{
+						Timestamp: testTimestamp,
+						RunError: gtr.Error{
+							Kind:   gtr.ErrorKindInfra,
+							Cause:  "open /tmp/go-build: too many open files",
+							Output: []string{"go: downloading example.com/mod v1.0.0", "open /tmp/go-build: too many open files"},
+						},
+					},
+				},
+			},
+		},
 		{
 			"leftover-builderror",
 			[]Event{
@@ -412,6 +509,72 @@ func TestReportSpecialCases(t *testing.T) {
 				},
 			},
 		},
+		{
+			"build output of passed package",
+			[]Event{
+				{Type: "build_output", Name: "package/name", Time: testTimestamp},
+				{Type: "output", Data: "warning: unused variable", Time: testTimestamp.Add(time.Second)},
+				{Type: "summary", Name: "package/name", Result: "ok", Duration: 1 * time.Millisecond},
+			},
+			gtr.Report{
+				Packages: []gtr.Package{
+					{
+						Name:          "package/name",
+						Timestamp:     testTimestamp,
+						Duration:      1 * time.Millisecond,
+						BuildDuration: time.Second,
+						Output:        []string{"warning: unused variable"},
+					},
+				},
+			},
+		},
+		{
+			"build output of passed package and its tests",
+			[]Event{
+				{Type: "build_output", Name: "package/name"},
+				{Type: "output", Data: "warning: unused variable"},
+				{Type: "build_output", Name: "package/name_test"},
+				{Type: "output", Data: "warning: unused import"},
+				{Type: "summary", Name: "package/name", Result: "ok", Duration: 1 * time.Millisecond},
+			},
+			gtr.Report{
+				Packages: []gtr.Package{
+					{
+						Name:      "package/name",
+						Timestamp: testTimestamp,
+						Duration:  1 * time.Millisecond,
+						Output:    []string{"warning: unused variable", "warning: unused import"},
+					},
+				},
+			},
+		},
+		{
+			"build error diagnostics",
+			[]Event{
+				{Type: "build_output", Name: "package/name"},
+				{Type: "output", Data: "./main.go:10:5: undefined: x"},
+				{Type: "output", Data: "./main.go:12:9: cannot use s as int value in return statement"},
+				{Type: "summary", Name: "package/name", Result: "FAIL", Data: "[build failed]"},
+			},
+			gtr.Report{
+				Packages: []gtr.Package{
+					{
+						Name:      "package/name",
+						Timestamp: testTimestamp,
+						BuildError: gtr.Error{
+							ID:     1,
+							Name:   "package/name",
+							Cause:  "[build failed]",
+							Output: []string{"./main.go:10:5: undefined: x", "./main.go:12:9: cannot use s as int value in return statement"},
+							Diagnostics: []gtr.Diagnostic{
+								{File: "./main.go", Line: 10, Column: 5, Message: "undefined: x"},
+								{File: "./main.go", Line: 12, Column: 9, Message: "cannot use s as int value in return statement"},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -428,3 +591,656 @@ func TestReportSpecialCases(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONPackageTimestamp(t *testing.T) {
+	input := `{"Time":"2022-03-04T05:06:07.5Z","Action":"run","Package":"package/name","Test":"TestOne"}
+{"Time":"2022-03-04T05:06:07.5Z","Action":"output","Package":"package/name","Test":"TestOne","Output":"=== RUN   TestOne\n"}
+{"Time":"2022-03-04T05:06:08Z","Action":"output","Package":"package/name","Test":"TestOne","Output":"--- PASS: TestOne (0.50s)\n"}
+{"Time":"2022-03-04T05:06:08Z","Action":"output","Package":"package/name","Output":"ok  \tpackage/name\t0.501s\n"}
+`
+	report, err := NewJSONParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	if len(report.Packages) != 1 {
+		t.Fatalf("Parse returned %d packages, want 1", len(report.Packages))
+	}
+
+	want := time.Date(2022, 3, 4, 5, 6, 7, 5e8, time.UTC)
+	if got := report.Packages[0].Timestamp; !got.Equal(want) {
+		t.Errorf("package timestamp = %v, want %v", got, want)
+	}
+}
+
+func TestJSONStartEndTimes(t *testing.T) {
+	input := `{"Time":"2022-03-04T05:06:07.5Z","Action":"output","Package":"package/name","Test":"TestOne","Output":"=== RUN   TestOne\n"}
+{"Time":"2022-03-04T05:06:08Z","Action":"output","Package":"package/name","Test":"TestOne","Output":"--- PASS: TestOne (0.50s)\n"}
+{"Time":"2022-03-04T05:06:09Z","Action":"output","Package":"package/name","Output":"ok  \tpackage/name\t0.501s\n"}
+`
+	report, err := NewJSONParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	if len(report.Packages) != 1 || len(report.Packages[0].Tests) != 1 {
+		t.Fatalf("Parse returned unexpected report: %#v", report)
+	}
+
+	pkg, test := report.Packages[0], report.Packages[0].Tests[0]
+	times := []struct {
+		name      string
+		got, want time.Time
+	}{
+		{"package start", pkg.StartTime, time.Date(2022, 3, 4, 5, 6, 7, 5e8, time.UTC)},
+		{"package end", pkg.EndTime, time.Date(2022, 3, 4, 5, 6, 9, 0, time.UTC)},
+		{"test start", test.StartTime, time.Date(2022, 3, 4, 5, 6, 7, 5e8, time.UTC)},
+		{"test end", test.EndTime, time.Date(2022, 3, 4, 5, 6, 8, 0, time.UTC)},
+	}
+	for _, tt := range times {
+		if !tt.got.Equal(tt.want) {
+			t.Errorf("%s time = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestJSONInterleavedOutput(t *testing.T) {
+	input := `{"Action":"output","Package":"package/name","Test":"TestA","Output":"=== RUN   TestA\n"}
+{"Action":"output","Package":"package/name","Test":"TestA","Output":"=== PAUSE TestA\n"}
+{"Action":"output","Package":"package/name","Test":"TestB","Output":"=== RUN   TestB\n"}
+{"Action":"output","Package":"package/name","Test":"TestB","Output":"=== PAUSE TestB\n"}
+{"Action":"output","Package":"package/name","Test":"TestA","Output":"=== CONT  TestA\n"}
+{"Action":"output","Package":"package/name","Test":"TestB","Output":"=== CONT  TestB\n"}
+{"Action":"output","Package":"package/name","Test":"TestA","Output":"    a_test.go:5: output of a\n"}
+{"Action":"output","Package":"package/name","Test":"TestB","Output":"    b_test.go:5: output of b\n"}
+{"Action":"output","Package":"package/name","Test":"TestA","Output":"    a_test.go:6: more output of a\n"}
+{"Action":"output","Package":"package/name","Test":"TestB","Output":"--- FAIL: TestB (0.00s)\n"}
+{"Action":"output","Package":"package/name","Test":"TestA","Output":"--- FAIL: TestA (0.00s)\n"}
+{"Action":"output","Package":"package/name","Output":"FAIL\n"}
+{"Action":"output","Package":"package/name","Output":"FAIL\tpackage/name\t0.001s\n"}
+`
+	report, err := NewJSONParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	if len(report.Packages) != 1 {
+		t.Fatalf("Parse returned unexpected report: %#v", report)
+	}
+	want := [][]string{
+		{"    a_test.go:5: output of a", "    a_test.go:6: more output of a"},
+		{"    b_test.go:5: output of b"},
+	}
+	var got [][]string
+	for _, test := range report.Packages[0].Tests {
+		got = append(got, test.Output)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Test output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestMaxOutput(t *testing.T) {
+	input := strings.Join([]string{
+		"=== RUN   TestOne",
+		"    one_test.go:1: line 1",
+		"    one_test.go:2: line 2",
+		"    one_test.go:3: line 3",
+		"--- FAIL: TestOne (0.00s)",
+		"FAIL",
+		"FAIL\tpackage/one\t0.001s",
+		"=== RUN   TestTwo",
+		"    two_test.go:1: line 1",
+		"    two_test.go:2: line 2",
+		"    two_test.go:3: line 3",
+		"--- FAIL: TestTwo (0.00s)",
+	}, "\n")
+
+	var handled []gtr.Package
+	parser := NewParser(MaxOutput(gtr.OutputLimits{TestLines: 2}), PackageHandler(func(pkg gtr.Package) {
+		handled = append(handled, pkg)
+	}))
+	report, err := parser.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if len(report.Packages) != 2 || len(handled) != 1 {
+		t.Fatalf("Parse returned %d packages and handled %d, want 2 and 1", len(report.Packages), len(handled))
+	}
+	want := []string{"... [1 lines, 26 bytes truncated] ...", "    one_test.go:2: line 2", "    one_test.go:3: line 3"}
+	if diff := cmp.Diff(want, report.Packages[0].Tests[0].Output); diff != "" {
+		t.Errorf("Truncated test output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if diff := cmp.Diff(want, handled[0].Tests[0].Output); diff != "" {
+		t.Errorf("Truncated output of handled package incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if got := report.Packages[1].Tests[0].Output; len(got) != 3 || got[0] != "... [1 lines, 26 bytes truncated] ..." {
+		t.Errorf("Output of package without summary = %q, want it to be truncated to 2 lines", got)
+	}
+}
+
+func TestJSONStderrOutput(t *testing.T) {
+	input := `{"Action":"output","Package":"package/name","Test":"TestA","Output":"=== RUN   TestA\n"}
+{"Action":"output","Package":"package/name","Test":"TestA","Output":"    a_test.go:5: output of a\n"}
+{"Action":"output","Package":"package/name","Test":"TestA","Output":"warning: deprecated flag\n","Stream":"stderr"}
+{"Action":"output","Package":"package/name","Test":"TestA","Output":"--- PASS: TestA (0.00s)\n"}
+{"Action":"output","Package":"package/name","Output":"package warning\n","Stream":"stderr"}
+{"Action":"output","Package":"package/name","Output":"PASS\n"}
+{"Action":"output","Package":"package/name","Output":"ok  \tpackage/name\t0.001s\n"}
+`
+	report, err := NewJSONParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	if len(report.Packages) != 1 || len(report.Packages[0].Tests) != 1 {
+		t.Fatalf("Parse returned unexpected report: %#v", report)
+	}
+	pkg := report.Packages[0]
+	if diff := cmp.Diff([]string{"package warning"}, pkg.Stderr); diff != "" {
+		t.Errorf("Package stderr incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	test := pkg.Tests[0]
+	if diff := cmp.Diff([]string{"    a_test.go:5: output of a"}, test.Output); diff != "" {
+		t.Errorf("Test output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if diff := cmp.Diff([]string{"warning: deprecated flag"}, test.Stderr); diff != "" {
+		t.Errorf("Test stderr incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	report, err = NewJSONParser(DropPassedOutput(true)).Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	if stderr := report.Packages[0].Tests[0].Stderr; stderr != nil {
+		t.Errorf("Test stderr with DropPassedOutput = %q, want nil", stderr)
+	}
+}
+
+func TestClock(t *testing.T) {
+	start := time.Date(2022, 3, 4, 5, 6, 0, 0, time.UTC)
+	var ticks int
+	clock := func() time.Time {
+		ticks++
+		return start.Add(time.Duration(ticks) * time.Second)
+	}
+
+	input := "=== RUN   TestOne\n--- PASS: TestOne (0.00s)\nok  \tpackage/name\t0.001s\n"
+	report, err := NewParser(Clock(clock)).Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	if len(report.Packages) != 1 || len(report.Packages[0].Tests) != 1 {
+		t.Fatalf("Parse returned unexpected report: %#v", report)
+	}
+
+	test := report.Packages[0].Tests[0]
+	if want := start.Add(1 * time.Second); !test.StartTime.Equal(want) {
+		t.Errorf("test start time = %v, want %v", test.StartTime, want)
+	}
+	if want := start.Add(2 * time.Second); !test.EndTime.Equal(want) {
+		t.Errorf("test end time = %v, want %v", test.EndTime, want)
+	}
+	if want := start.Add(3 * time.Second); !report.Packages[0].EndTime.Equal(want) {
+		t.Errorf("package end time = %v, want %v", report.Packages[0].EndTime, want)
+	}
+}
+
+func TestParallelDurations(t *testing.T) {
+	start := time.Date(2022, 3, 4, 5, 6, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+	events := []Event{
+		{Type: "run_test", Name: "TestOne", Time: at(0)},
+		{Type: "pause_test", Name: "TestOne", Time: at(1)},
+		{Type: "run_test", Name: "TestTwo", Time: at(1)},
+		{Type: "pause_test", Name: "TestTwo", Time: at(2)},
+		{Type: "run_test", Name: "TestThree", Time: at(2)},
+		{Type: "run_test", Name: "TestThree/Sub", Time: at(2)},
+		{Type: "end_test", Name: "TestThree/Sub", Result: "PASS", Time: at(3)},
+		{Type: "end_test", Name: "TestThree", Result: "PASS", Time: at(3)},
+		{Type: "cont_test", Name: "TestOne", Time: at(4)},
+		{Type: "cont_test", Name: "TestTwo", Time: at(4)},
+		{Type: "end_test", Name: "TestTwo", Result: "PASS", Time: at(6)},
+		{Type: "end_test", Name: "TestOne", Result: "PASS", Time: at(7)},
+		{Type: "summary", Result: "ok", Name: "package/name", Time: at(7)},
+	}
+	rb := newReportBuilder()
+	for _, ev := range events {
+		rb.ProcessEvent(ev)
+	}
+	report := rb.Build()
+	if len(report.Packages) != 1 {
+		t.Fatalf("Build returned unexpected report: %#v", report)
+	}
+
+	pkg := report.Packages[0]
+	if pkg.MaxParallel != 2 {
+		t.Errorf("package MaxParallel = %d, want 2", pkg.MaxParallel)
+	}
+
+	type durations struct{ Run, Wall time.Duration }
+	want := map[string]durations{
+		"TestOne":       {4 * time.Second, 7 * time.Second},
+		"TestTwo":       {3 * time.Second, 5 * time.Second},
+		"TestThree":     {time.Second, time.Second},
+		"TestThree/Sub": {time.Second, time.Second},
+	}
+	got := make(map[string]durations)
+	for _, test := range pkg.Tests {
+		got[test.Name] = durations{test.RunDuration, test.WallDuration}
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("test durations incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestFuzzData(t *testing.T) {
+	input := `=== RUN   FuzzOne
+fuzz: elapsed: 0s, gathering baseline coverage: 2/2 completed, now fuzzing with 8 workers
+fuzz: elapsed: 3s, execs: 136464 (45472/sec), new interesting: 1 (total: 3)
+--- FAIL: FuzzOne (3.07s)
+    --- FAIL: FuzzOne (0.00s)
+        fuzz_test.go:6: failed
+    
+    Failing input written to testdata/fuzz/FuzzOne/1de061fa29cfbb3d
+FAIL
+FAIL	package/name	3.070s
+`
+	report, err := NewParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	if len(report.Packages) != 1 || len(report.Packages[0].Tests) != 1 {
+		t.Fatalf("Parse returned unexpected report: %#v", report)
+	}
+
+	test := report.Packages[0].Tests[0]
+	got, ok := GetFuzzData(test)
+	if !ok {
+		t.Fatalf("GetFuzzData(%v) returned no fuzz data", test.Name)
+	}
+	want := Fuzz{
+		SeedCorpus:     2,
+		Execs:          136464,
+		ExecsPerSec:    45472,
+		NewInteresting: 1,
+		FailingInput:   "testdata/fuzz/FuzzOne/1de061fa29cfbb3d",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetFuzzData() incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if test.Result != gtr.Fail || test.Duration != 3070*time.Millisecond {
+		t.Errorf("test result = %v (%v), want FAIL (3.07s)", test.Result, test.Duration)
+	}
+}
+
+func TestRaceReport(t *testing.T) {
+	input := `=== RUN   TestRace
+==================
+WARNING: DATA RACE
+Write at 0x00c000018308 by goroutine 8:
+  example.TestRace.func1()
+      /src/example/r_test.go:5 +0x2e
+
+Previous read at 0x00c000018308 by main goroutine:
+  example.TestRace()
+      /src/example/r_test.go:6 +0xae
+
+Goroutine 8 (running) created at:
+  example.TestRace()
+      /src/example/r_test.go:5 +0xa4
+==================
+--- PASS: TestRace (0.01s)
+PASS
+ok  	example	0.022s
+`
+	report, err := NewParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	if len(report.Packages) != 1 || len(report.Packages[0].Tests) != 1 {
+		t.Fatalf("Parse returned unexpected report: %#v", report)
+	}
+
+	test := report.Packages[0].Tests[0]
+	if test.Result != gtr.Fail {
+		t.Errorf("test result = %v, want %v", test.Result, gtr.Fail)
+	}
+	if len(test.Output) != 14 {
+		t.Errorf("test output has %d lines, want 14", len(test.Output))
+	}
+
+	races, ok := GetRaceReports(test)
+	if !ok || len(races) != 1 {
+		t.Fatalf("GetRaceReports(%v) = %v, want 1 race", test.Name, races)
+	}
+	race := races[0]
+	race.Output = nil
+	want := RaceReport{
+		Accesses: []RaceAccess{
+			{
+				Op:        "Write",
+				Addr:      "0x00c000018308",
+				Goroutine: "goroutine 8",
+				Stack:     []StackFrame{{"example.TestRace.func1()", "/src/example/r_test.go", 5}},
+			},
+			{
+				Op:        "Previous read",
+				Addr:      "0x00c000018308",
+				Goroutine: "main goroutine",
+				Stack:     []StackFrame{{"example.TestRace()", "/src/example/r_test.go", 6}},
+			},
+		},
+		Goroutines: []RaceGoroutine{
+			{
+				ID:    8,
+				State: "running",
+				Stack: []StackFrame{{"example.TestRace()", "/src/example/r_test.go", 5}},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, race); diff != "" {
+		t.Errorf("RaceReport incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestSanitizerReport(t *testing.T) {
+	input := `=== RUN   TestFree
+==4242==ERROR: AddressSanitizer: heap-use-after-free on address 0x602000000010 at pc 0x4f2c7a bp 0x7ffd2d9a6e50 sp 0x7ffd2d9a6e48
+READ of size 4 at 0x602000000010 thread T0
+    #0 0x4f2c79 in readValue /src/example/buffer.c:12:10
+    #1 0x4c3a0f in runtime.asmcgocall /usr/local/go/src/runtime/asm_amd64.s:848
+
+freed by thread T0 here:
+    #0 0x4a1e4d in free (/tmp/example.test+0x4a1e4d)
+
+SUMMARY: AddressSanitizer: heap-use-after-free /src/example/buffer.c:12:10 in readValue
+==4242==ABORTING
+exit status 1
+FAIL	example	0.012s
+`
+	report, err := NewParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	if len(report.Packages) != 1 || len(report.Packages[0].Tests) != 1 {
+		t.Fatalf("Parse returned unexpected report: %#v", report)
+	}
+
+	test := report.Packages[0].Tests[0]
+	if test.Result != gtr.Fail || test.FailureType != gtr.ErrorKindSanitizer {
+		t.Errorf("test result = %v with failure type %q, want %v with %q", test.Result, test.FailureType, gtr.Fail, gtr.ErrorKindSanitizer)
+	}
+
+	reports, ok := GetSanitizerReports(test)
+	if !ok || len(reports) != 1 {
+		t.Fatalf("GetSanitizerReports(%v) = %v, want 1 report", test.Name, reports)
+	}
+	got := reports[0]
+	got.Output = nil
+	want := SanitizerReport{
+		Sanitizer:   "AddressSanitizer",
+		Kind:        "heap-use-after-free",
+		Description: "heap-use-after-free on address 0x602000000010 at pc 0x4f2c7a bp 0x7ffd2d9a6e50 sp 0x7ffd2d9a6e48",
+		Summary:     "AddressSanitizer: heap-use-after-free /src/example/buffer.c:12:10 in readValue",
+		Stack: []StackFrame{
+			{"readValue", "/src/example/buffer.c", 12},
+			{"runtime.asmcgocall", "/usr/local/go/src/runtime/asm_amd64.s", 848},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SanitizerReport incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestPanicInfo(t *testing.T) {
+	input := `=== RUN   TestOne
+--- PASS: TestOne (0.00s)
+=== RUN   TestPanic
+=== RUN   TestPanic/sub
+--- FAIL: TestPanic (0.00s)
+    --- FAIL: TestPanic/sub (0.00s)
+panic: boom [recovered]
+	panic: boom
+
+goroutine 7 [running]:
+example.TestPanic.func1(0xc000007860)
+	/src/example/panic_test.go:6 +0x28
+exit status 2
+FAIL	example	0.004s
+`
+	report, err := NewParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	if len(report.Packages) != 1 || len(report.Packages[0].Tests) != 3 {
+		t.Fatalf("Parse returned unexpected report: %#v", report)
+	}
+
+	pkg := report.Packages[0]
+	want := &gtr.PanicInfo{
+		Message: "boom",
+		Test:    "TestPanic/sub",
+		Stack: []string{
+			"\tpanic: boom",
+			"",
+			"goroutine 7 [running]:",
+			"example.TestPanic.func1(0xc000007860)",
+			"\t/src/example/panic_test.go:6 +0x28",
+		},
+	}
+	if diff := cmp.Diff(want, pkg.Tests[2].Panic); diff != "" {
+		t.Errorf("PanicInfo incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if pkg.Tests[1].Panic != nil {
+		t.Errorf("PanicInfo for parent test %s = %v, want nil", pkg.Tests[1].Name, pkg.Tests[1].Panic)
+	}
+	if got := len(pkg.Tests[2].Output); got != 7 {
+		t.Errorf("panicking test has %d lines of output, want 7", got)
+	}
+	if len(pkg.Output) != 0 {
+		t.Errorf("package output = %q, want no output", pkg.Output)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	input := `=== RUN   TestFast
+--- PASS: TestFast (0.00s)
+=== RUN   TestOther
+=== PAUSE TestOther
+=== RUN   TestSlow
+=== RUN   TestSlow/sub
+panic: test timed out after 1s
+	running tests:
+		TestSlow (1s)
+		TestSlow/sub (1s)
+
+goroutine 8 [sleep]:
+time.Sleep(0xb2d05e00)
+	/usr/local/go/src/runtime/time.go:368 +0x165
+example.TestSlow.func1(0xc000007860)
+	/src/example/slow_test.go:12 +0x18
+FAIL	example	1.004s
+`
+	report, err := NewParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	if len(report.Packages) != 1 || len(report.Packages[0].Tests) != 4 {
+		t.Fatalf("Parse returned unexpected report: %#v", report)
+	}
+
+	type result struct {
+		Result         gtr.Result
+		Duration       time.Duration
+		FailureMessage string
+		FailureType    string
+		Panic          bool
+	}
+	want := []result{
+		{Result: gtr.Pass},
+		{Result: gtr.Unknown},
+		{gtr.Fail, time.Second, "test timed out after 1s", gtr.ErrorKindTimeout, false},
+		{gtr.Fail, time.Second, "test timed out after 1s", gtr.ErrorKindTimeout, true},
+	}
+	var got []result
+	for _, test := range report.Packages[0].Tests {
+		got = append(got, result{test.Result, test.Duration, test.FailureMessage, test.FailureType, test.Panic != nil})
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Timed out tests incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if got := len(report.Packages[0].Tests[3].Output); got != 10 {
+		t.Errorf("timed out test has %d lines of output, want 10", got)
+	}
+	if pkg := report.Packages[0]; pkg.RunError.Name != "" {
+		t.Errorf("RunError = %+v, want none", pkg.RunError)
+	}
+}
+
+func TestTimeoutOutsideTest(t *testing.T) {
+	input := `panic: test timed out after 1s
+
+goroutine 1 [sleep]:
+time.Sleep(0xb2d05e00)
+	/usr/local/go/src/runtime/time.go:368 +0x165
+example.init.0()
+	/src/example/main_test.go:8 +0x18
+FAIL	example	1.004s
+`
+	report, err := NewParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse returned an unexpected error: %v", err)
+	}
+	if len(report.Packages) != 1 {
+		t.Fatalf("Parse returned unexpected report: %#v", report)
+	}
+	got := report.Packages[0].RunError
+	if got.Kind != gtr.ErrorKindTimeout || got.Cause != "test timed out after 1s" {
+		t.Errorf("RunError kind and cause = %q, %q, want %q, %q", got.Kind, got.Cause, gtr.ErrorKindTimeout, "test timed out after 1s")
+	}
+}
+
+func TestSkipMessage(t *testing.T) {
+	tests := []struct {
+		output []string
+		want   string
+	}{
+		{nil, ""},
+		{[]string{"    skip_test.go:6: not supported"}, "not supported"},
+		{[]string{"    skip_test.go:6: setup", "    skip_test.go:7: multi", "        line"}, "multi\nline"},
+		{[]string{"    skip_test.go:6: log", "unrelated output"}, ""},
+	}
+	for _, test := range tests {
+		if got := skipMessage(test.output); got != test.want {
+			t.Errorf("skipMessage(%q) = %q, want %q", test.output, got, test.want)
+		}
+	}
+}
+
+func TestDropPassedOutput(t *testing.T) {
+	input := strings.Join([]string{
+		"=== RUN   TestPass",
+		"    pass_test.go:1: passed",
+		"--- PASS: TestPass (0.00s)",
+		"=== RUN   TestFail",
+		"    fail_test.go:1: failed",
+		"--- FAIL: TestFail (0.00s)",
+		"=== RUN   TestSkip",
+		"    skip_test.go:1: skipped",
+		"--- SKIP: TestSkip (0.00s)",
+		"FAIL",
+		"FAIL\tpackage/name\t0.001s",
+	}, "\n")
+
+	report, err := NewParser(DropPassedOutput(true)).Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	want := [][]string{
+		nil,
+		{"    fail_test.go:1: failed"},
+		{"    skip_test.go:1: skipped"},
+	}
+	var got [][]string
+	for _, test := range report.Packages[0].Tests {
+		got = append(got, test.Output)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Test output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestEndedTestOutput(t *testing.T) {
+	input := strings.Join([]string{
+		"--- FAIL: TestTable (0.00s)",
+		"    table_test.go:5: setup",
+		"    --- PASS: TestTable/a (0.00s)",
+		"    --- FAIL: TestTable/b (0.00s)",
+		"        table_test.go:8: checking b",
+		"        --- FAIL: TestTable/b/inner (0.00s)",
+		"            table_test.go:11: failed b",
+		"                got: 1",
+		"    table_test.go:14: done",
+		"FAIL",
+		"exit status 1",
+		"FAIL\tpackage/name\t0.001s",
+	}, "\n")
+
+	report, err := NewParser().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	want := [][]string{
+		{"    table_test.go:5: setup", "    table_test.go:14: done"},
+		nil,
+		{"        table_test.go:8: checking b"},
+		{"            table_test.go:11: failed b", "                got: 1"},
+	}
+	var got [][]string
+	for _, test := range report.Packages[0].Tests {
+		got = append(got, test.Output)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Test output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if diff := cmp.Diff([]string{"exit status 1"}, report.Packages[0].Output); diff != "" {
+		t.Errorf("Package output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	input := strings.Join([]string{
+		"stray output",
+		"ok  \tpackage/empty\t0.001s",
+		"--- FAIL: TestNotStarted (0.00s)",
+		"FAIL\tpackage/name\t0.001s",
+		"=== RUN   TestInterrupted",
+	}, "\n")
+
+	var warnings []Warning
+	p := NewParser(WarningHandler(func(w Warning) { warnings = append(warnings, w) }))
+	if _, err := p.Parse(strings.NewReader(input)); err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	p.builder = newReportBuilder()
+	p.builder.warningHandler = p.warningHandler
+	p.builder.ProcessEvent(Event{Type: "end_tset", Package: "package/name", Name: "TestOne"})
+
+	want := []Warning{
+		{Package: "", Test: "TestNotStarted", Kind: WarningTestNotStarted, Message: "test ended without having been started, go test may have run without -v"},
+		{Package: "", Kind: WarningMissingSummary, Message: "package has no summary line, go test may have been interrupted"},
+		{Package: "package/name", Test: "TestOne", Kind: WarningUnknownEvent, Message: "unhandled event type: end_tset"},
+	}
+	if diff := cmp.Diff(want, warnings); diff != "" {
+		t.Errorf("Warnings incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	warnings = nil
+	if _, err := p.Parse(strings.NewReader("stray output\nmore output\n")); err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	want = []Warning{{Kind: WarningUnattributedOutput, Message: "output doesn't belong to any package (2 lines)"}}
+	if diff := cmp.Diff(want, warnings); diff != "" {
+		t.Errorf("Warnings incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if got, want := warnings[0].String(), "output doesn't belong to any package (2 lines)"; got != want {
+		t.Errorf("Warning.String() = %q, want %q", got, want)
+	}
+}
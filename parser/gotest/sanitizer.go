@@ -0,0 +1,94 @@
+package gotest
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+const sanitizersKey = "gotest.sanitizers"
+
+var (
+	regexSanitizerStart = regexp.MustCompile(`^==\d+==(?:ERROR|WARNING): (\w+Sanitizer): (.+)$`)
+	regexSanitizerFrame = regexp.MustCompile(`^\s*#\d+ 0x[0-9a-f]+ in (\S+)(?: (\S+?):(\d+)(?::\d+)?)?$`)
+)
+
+// SanitizerReport is an error found by AddressSanitizer, MemorySanitizer or
+// LeakSanitizer in a test built with go test -asan or -msan, and is intended
+// to be used as extra data in a gtr.Test.
+type SanitizerReport struct {
+	Sanitizer   string       // e.g. "AddressSanitizer"
+	Kind        string       // e.g. "heap-use-after-free", or "memory-leak" for LeakSanitizer
+	Description string       // the description of the error following the sanitizer name
+	Summary     string       // the SUMMARY line, without the "SUMMARY: " prefix
+	Stack       []StackFrame // the first stack trace of the report
+	Output      []string     // the report as printed by the sanitizer
+}
+
+// Message returns a one line description of sanitizer report r.
+func (r SanitizerReport) Message() string {
+	if r.Summary != "" {
+		return r.Summary
+	}
+	return r.Sanitizer + ": " + r.Description
+}
+
+// GetSanitizerReports is a helper function that returns the sanitizer reports
+// contained in the data field of the given gtr.Test t. If no reports are
+// present, ok will be set to false.
+func GetSanitizerReports(t gtr.Test) (reports []SanitizerReport, ok bool) {
+	if t.Data != nil {
+		if data, exists := t.Data[sanitizersKey]; exists {
+			reports, ok := data.([]SanitizerReport)
+			return reports, ok
+		}
+	}
+	return nil, false
+}
+
+// AddSanitizerReport is a helper function that adds sanitizer report r to the
+// data field of the given gtr.Test t.
+func AddSanitizerReport(t *gtr.Test, r SanitizerReport) {
+	if t.Data != nil {
+		reports, _ := GetSanitizerReports(*t)
+		t.Data[sanitizersKey] = append(reports, r)
+	}
+}
+
+// isSanitizerEnd returns true if line ends a sanitizer report. Reports end with their SUMMARY line, or when go test output
+// follows a report that was cut short.
+func isSanitizerEnd(line string) bool {
+	return strings.HasPrefix(line, "SUMMARY: ") && strings.Contains(line, "Sanitizer: ") ||
+		strings.HasPrefix(line, "=== ") || strings.HasPrefix(line, "--- ") ||
+		strings.HasPrefix(line, "FAIL") || strings.HasPrefix(line, "ok ")
+}
+
+// parseSanitizerReport parses the given lines of a sanitizer report, starting
+// with the line that contains the name of the sanitizer.
+func parseSanitizerReport(lines []string) SanitizerReport {
+	r := SanitizerReport{Output: lines}
+	if m := regexSanitizerStart.FindStringSubmatch(lines[0]); m != nil {
+		r.Sanitizer, r.Description = m[1], m[2]
+		if r.Sanitizer == "LeakSanitizer" {
+			r.Kind = "memory-leak"
+		} else if fields := strings.Fields(m[2]); len(fields) > 0 {
+			r.Kind = strings.TrimSuffix(fields[0], ":")
+		}
+	}
+	inStack := false
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "SUMMARY: ") {
+			r.Summary = strings.TrimPrefix(line, "SUMMARY: ")
+		} else if m := regexSanitizerFrame.FindStringSubmatch(line); m != nil && (inStack || len(r.Stack) == 0) {
+			inStack = true
+			frame := StackFrame{Function: m[1], File: m[2]}
+			frame.Line, _ = strconv.Atoi(m[3])
+			r.Stack = append(r.Stack, frame)
+		} else if inStack {
+			inStack = false
+		}
+	}
+	return r
+}
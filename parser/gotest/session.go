@@ -0,0 +1,76 @@
+package gotest
+
+import (
+	"strings"
+)
+
+// session keeps track of the running tests in a single `go test` session in
+// order to detect the output of another `go test` session nested inside it.
+// This happens for example when a test runs `go test` itself and writes its
+// output to stdout or logs it using t.Log.
+//
+// A nested session starts when a `=== RUN` line is found while another top
+// level test is still running. This is either an indented `=== RUN` line, or
+// a `=== RUN` line for a top level test while a different top level test has
+// not yet ended or paused. Once a nested session has been detected, all lines
+// up to and including its package summary are treated as regular output of
+// the test that was running, so they don't interfere with the outer session.
+type session struct {
+	running map[string]struct{} // top level tests that are currently running
+
+	nested bool   // whether we are currently in a nested session
+	indent string // indentation of the nested session
+}
+
+func newSession() *session {
+	return &session{running: make(map[string]struct{})}
+}
+
+// IsNested returns true if the given line belongs to a nested session.
+func (s *session) IsNested(line string) bool {
+	if s.nested {
+		if regexSummary.MatchString(strings.TrimPrefix(line, s.indent)) {
+			s.nested = false
+		}
+		return true
+	}
+
+	if len(s.running) == 0 {
+		return false
+	}
+
+	trimmed := strings.TrimLeft(line, " \t")
+	if !strings.HasPrefix(trimmed, "=== RUN ") {
+		return false
+	}
+
+	indent := line[:len(line)-len(trimmed)]
+	name := strings.TrimSpace(trimmed[8:])
+	if indent == "" {
+		if strings.Contains(name, "/") {
+			return false
+		}
+		if _, ok := s.running[name]; ok {
+			return false
+		}
+	}
+	s.nested = true
+	s.indent = indent
+	return true
+}
+
+// Track updates the running tests of this session using the given events.
+func (s *session) Track(events []Event) {
+	for _, ev := range events {
+		switch ev.Type {
+		case "run_test", "cont_test":
+			if !strings.Contains(ev.Name, "/") {
+				s.running[ev.Name] = struct{}{}
+			}
+		case "pause_test", "end_test":
+			delete(s.running, ev.Name)
+		case "summary":
+			s.running = make(map[string]struct{})
+		}
+	}
+}
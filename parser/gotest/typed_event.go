@@ -0,0 +1,402 @@
+package gotest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// TypedEvent is an event of a specific type. Each type of event is a separate
+// struct containing only the fields that apply to it, so that events can be
+// constructed programmatically with compile-time checking, e.g. by parsers
+// of other output formats, see FromEvents. Unlike with an Event, there's no
+// Type string that can be misspelled.
+//
+// TypedEvent is implemented by the event types in this package only. The
+// Typed method of Event converts an Event to its TypedEvent.
+type TypedEvent interface {
+	// Event returns the equivalent Event.
+	Event() Event
+
+	typedEvent()
+}
+
+// RunTest is the event of a test that started running.
+type RunTest struct {
+	Package string
+	Name    string
+	Time    time.Time
+}
+
+// PauseTest is the event of a parallel test that was paused.
+type PauseTest struct {
+	Package string
+	Name    string
+	Time    time.Time
+}
+
+// ContTest is the event of a paused test that continued running.
+type ContTest struct {
+	Package string
+	Name    string
+	Time    time.Time
+}
+
+// EndTest is the event of a test that ended with a result, such as PASS,
+// FAIL or SKIP. Indent is the indentation level of the result line, and Note
+// is the text following the result, if any.
+type EndTest struct {
+	Package  string
+	Name     string
+	Result   string
+	Duration time.Duration
+	Indent   int
+	Note     string
+	Time     time.Time
+}
+
+// RunBenchmark is the event of a benchmark that started running.
+type RunBenchmark struct {
+	Package string
+	Name    string
+	Time    time.Time
+}
+
+// BenchmarkResult is the event of the results of a benchmark.
+type BenchmarkResult struct {
+	Package   string
+	Name      string
+	Benchmark Benchmark
+	Time      time.Time
+}
+
+// EndBenchmark is the event of a benchmark that ended with a result.
+type EndBenchmark struct {
+	Package string
+	Name    string
+	Result  string
+	Time    time.Time
+}
+
+// Panic is the event of the first line of a panic.
+type Panic struct {
+	Package string
+	Line    string
+	Time    time.Time
+}
+
+// Race is the event of a complete race detector report.
+type Race struct {
+	Package string
+	Lines   []string
+	Time    time.Time
+}
+
+// Sanitizer is the event of a complete AddressSanitizer, MemorySanitizer or
+// LeakSanitizer report.
+type Sanitizer struct {
+	Package string
+	Lines   []string
+	Time    time.Time
+}
+
+// FuzzProgress is the event of a progress line of a fuzz test.
+type FuzzProgress struct {
+	Package        string
+	Corpus         int
+	Execs          int64
+	ExecsPerSec    int64
+	NewInteresting int
+	Time           time.Time
+}
+
+// FuzzFailure is the event of a fuzz test that found a failing input, which
+// was written to the file at Path.
+type FuzzFailure struct {
+	Package string
+	Name    string
+	Path    string
+	Time    time.Time
+}
+
+// Shuffle is the event of the seed go test used to shuffle the order of the
+// tests of a package, when running with -shuffle.
+type Shuffle struct {
+	Package string
+	Seed    string
+	Time    time.Time
+}
+
+// Status is the event of the overall PASS or FAIL status of a package.
+type Status struct {
+	Package string
+	Result  string
+	Time    time.Time
+}
+
+// Summary is the event of the summary line that ends a package. Name is the
+// name of the package in the summary line, and Note the text following the
+// duration, such as (cached).
+type Summary struct {
+	Package         string
+	Name            string
+	Result          string
+	Duration        time.Duration
+	Note            string
+	Coverage        float64
+	CoveredPackages []string
+	Time            time.Time
+}
+
+// Coverage is the event of the coverage of a package.
+type Coverage struct {
+	Package  string
+	Percent  float64
+	Packages []string
+	Time     time.Time
+}
+
+// BuildOutput is the event of the start of the build output of the package
+// with the given Name.
+type BuildOutput struct {
+	Package string
+	Name    string
+	Time    time.Time
+}
+
+// InfraError is the event of an infrastructure error in the output.
+type InfraError struct {
+	Package string
+	Line    string
+	Time    time.Time
+}
+
+// Output is the event of a line of output. Test is the name of the test that
+// printed it, if known. Stream is "stderr" if the line is known to have been
+// written to stderr.
+type Output struct {
+	Package string
+	Test    string
+	Line    string
+	Stream  string
+	Time    time.Time
+}
+
+func (e RunTest) Event() Event {
+	return Event{Type: "run_test", Package: e.Package, Name: e.Name, Time: e.Time}
+}
+
+func (e PauseTest) Event() Event {
+	return Event{Type: "pause_test", Package: e.Package, Name: e.Name, Time: e.Time}
+}
+
+func (e ContTest) Event() Event {
+	return Event{Type: "cont_test", Package: e.Package, Name: e.Name, Time: e.Time}
+}
+
+func (e EndTest) Event() Event {
+	return Event{Type: "end_test", Package: e.Package, Name: e.Name, Result: e.Result, Duration: e.Duration, Indent: e.Indent, Data: e.Note, Time: e.Time}
+}
+
+func (e RunBenchmark) Event() Event {
+	return Event{Type: "run_benchmark", Package: e.Package, Name: e.Name, Time: e.Time}
+}
+
+func (e BenchmarkResult) Event() Event {
+	return Event{
+		Type:         "benchmark",
+		Package:      e.Package,
+		Name:         e.Name,
+		Time:         e.Time,
+		Iterations:   e.Benchmark.Iterations,
+		NsPerOp:      e.Benchmark.NsPerOp,
+		MBPerSec:     e.Benchmark.MBPerSec,
+		BytesPerOp:   e.Benchmark.BytesPerOp,
+		AllocsPerOp:  e.Benchmark.AllocsPerOp,
+		BenchMetrics: e.Benchmark.Metrics,
+	}
+}
+
+func (e EndBenchmark) Event() Event {
+	return Event{Type: "end_benchmark", Package: e.Package, Name: e.Name, Result: e.Result, Time: e.Time}
+}
+
+func (e Panic) Event() Event {
+	return Event{Type: "panic", Package: e.Package, Data: e.Line, Time: e.Time}
+}
+
+func (e Race) Event() Event {
+	return Event{Type: "race", Package: e.Package, Data: strings.Join(e.Lines, "\n"), Time: e.Time}
+}
+
+func (e Sanitizer) Event() Event {
+	return Event{Type: "sanitizer", Package: e.Package, Data: strings.Join(e.Lines, "\n"), Time: e.Time}
+}
+
+func (e FuzzProgress) Event() Event {
+	return Event{
+		Type:               "fuzz_progress",
+		Package:            e.Package,
+		Time:               e.Time,
+		FuzzCorpus:         e.Corpus,
+		FuzzExecs:          e.Execs,
+		FuzzExecsPerSec:    e.ExecsPerSec,
+		FuzzNewInteresting: e.NewInteresting,
+	}
+}
+
+func (e FuzzFailure) Event() Event {
+	return Event{Type: "fuzz_failure", Package: e.Package, Name: e.Name, Data: e.Path, Time: e.Time}
+}
+
+func (e Shuffle) Event() Event {
+	return Event{Type: "shuffle", Package: e.Package, Data: e.Seed, Time: e.Time}
+}
+
+func (e Status) Event() Event {
+	return Event{Type: "status", Package: e.Package, Result: e.Result, Time: e.Time}
+}
+
+func (e Summary) Event() Event {
+	return Event{
+		Type:        "summary",
+		Package:     e.Package,
+		Name:        e.Name,
+		Result:      e.Result,
+		Duration:    e.Duration,
+		Data:        e.Note,
+		Time:        e.Time,
+		CovPct:      e.Coverage,
+		CovPackages: e.CoveredPackages,
+	}
+}
+
+func (e Coverage) Event() Event {
+	return Event{Type: "coverage", Package: e.Package, Time: e.Time, CovPct: e.Percent, CovPackages: e.Packages}
+}
+
+func (e BuildOutput) Event() Event {
+	return Event{Type: "build_output", Package: e.Package, Name: e.Name, Time: e.Time}
+}
+
+func (e InfraError) Event() Event {
+	return Event{Type: "infra_error", Package: e.Package, Data: e.Line, Time: e.Time}
+}
+
+func (e Output) Event() Event {
+	return Event{Type: "output", Package: e.Package, Name: e.Test, Data: e.Line, Stream: e.Stream, Time: e.Time}
+}
+
+func (RunTest) typedEvent()         {}
+func (PauseTest) typedEvent()       {}
+func (ContTest) typedEvent()        {}
+func (EndTest) typedEvent()         {}
+func (RunBenchmark) typedEvent()    {}
+func (BenchmarkResult) typedEvent() {}
+func (EndBenchmark) typedEvent()    {}
+func (Panic) typedEvent()           {}
+func (Race) typedEvent()            {}
+func (Sanitizer) typedEvent()       {}
+func (FuzzProgress) typedEvent()    {}
+func (FuzzFailure) typedEvent()     {}
+func (Shuffle) typedEvent()         {}
+func (Status) typedEvent()          {}
+func (Summary) typedEvent()         {}
+func (Coverage) typedEvent()        {}
+func (BuildOutput) typedEvent()     {}
+func (InfraError) typedEvent()      {}
+func (Output) typedEvent()          {}
+
+// Typed returns the TypedEvent of e, for compatibility with code that creates
+// or processes Events. An error is returned if the Type of e is unknown.
+func (e Event) Typed() (TypedEvent, error) {
+	switch e.Type {
+	case "run_test":
+		return RunTest{Package: e.Package, Name: e.Name, Time: e.Time}, nil
+	case "pause_test":
+		return PauseTest{Package: e.Package, Name: e.Name, Time: e.Time}, nil
+	case "cont_test":
+		return ContTest{Package: e.Package, Name: e.Name, Time: e.Time}, nil
+	case "end_test":
+		return EndTest{Package: e.Package, Name: e.Name, Result: e.Result, Duration: e.Duration, Indent: e.Indent, Note: e.Data, Time: e.Time}, nil
+	case "run_benchmark":
+		return RunBenchmark{Package: e.Package, Name: e.Name, Time: e.Time}, nil
+	case "benchmark":
+		return BenchmarkResult{
+			Package: e.Package,
+			Name:    e.Name,
+			Time:    e.Time,
+			Benchmark: Benchmark{
+				Iterations:  e.Iterations,
+				NsPerOp:     e.NsPerOp,
+				MBPerSec:    e.MBPerSec,
+				BytesPerOp:  e.BytesPerOp,
+				AllocsPerOp: e.AllocsPerOp,
+				Metrics:     e.BenchMetrics,
+			},
+		}, nil
+	case "end_benchmark":
+		return EndBenchmark{Package: e.Package, Name: e.Name, Result: e.Result, Time: e.Time}, nil
+	case "panic":
+		return Panic{Package: e.Package, Line: e.Data, Time: e.Time}, nil
+	case "race":
+		return Race{Package: e.Package, Lines: strings.Split(e.Data, "\n"), Time: e.Time}, nil
+	case "sanitizer":
+		return Sanitizer{Package: e.Package, Lines: strings.Split(e.Data, "\n"), Time: e.Time}, nil
+	case "fuzz_progress":
+		return FuzzProgress{
+			Package:        e.Package,
+			Time:           e.Time,
+			Corpus:         e.FuzzCorpus,
+			Execs:          e.FuzzExecs,
+			ExecsPerSec:    e.FuzzExecsPerSec,
+			NewInteresting: e.FuzzNewInteresting,
+		}, nil
+	case "fuzz_failure":
+		return FuzzFailure{Package: e.Package, Name: e.Name, Path: e.Data, Time: e.Time}, nil
+	case "shuffle":
+		return Shuffle{Package: e.Package, Seed: e.Data, Time: e.Time}, nil
+	case "status":
+		return Status{Package: e.Package, Result: e.Result, Time: e.Time}, nil
+	case "summary":
+		return Summary{
+			Package:         e.Package,
+			Name:            e.Name,
+			Result:          e.Result,
+			Duration:        e.Duration,
+			Note:            e.Data,
+			Time:            e.Time,
+			Coverage:        e.CovPct,
+			CoveredPackages: e.CovPackages,
+		}, nil
+	case "coverage":
+		return Coverage{Package: e.Package, Time: e.Time, Percent: e.CovPct, Packages: e.CovPackages}, nil
+	case "build_output":
+		return BuildOutput{Package: e.Package, Name: e.Name, Time: e.Time}, nil
+	case "infra_error":
+		return InfraError{Package: e.Package, Line: e.Data, Time: e.Time}, nil
+	case "output":
+		return Output{Package: e.Package, Test: e.Name, Line: e.Data, Stream: e.Stream, Time: e.Time}, nil
+	default:
+		return nil, fmt.Errorf("unknown event type: %q", e.Type)
+	}
+}
+
+// FromEvents creates a report from events in the same way as a Parser does
+// from the events it parses, using the given options. It can be used to build
+// reports from events created programmatically, for example by a parser of
+// another output format. Options that only affect parsing, such as
+// InfraErrorPatterns, are ignored.
+func FromEvents(events []TypedEvent, options ...Option) gtr.Report {
+	p := NewParser(options...)
+	p.reset(false)
+	for _, ev := range events {
+		p.builder.process(ev)
+		if p.eventHandler != nil {
+			p.eventHandler(ev.Event())
+		}
+	}
+	return p.Flush()
+}
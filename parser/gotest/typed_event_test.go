@@ -0,0 +1,82 @@
+package gotest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTypedEventRoundTrip(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []TypedEvent{
+		RunTest{Package: "pkg", Name: "TestOne", Time: now},
+		PauseTest{Package: "pkg", Name: "TestOne", Time: now},
+		ContTest{Package: "pkg", Name: "TestOne", Time: now},
+		EndTest{Package: "pkg", Name: "TestOne", Result: "FAIL", Duration: time.Second, Indent: 1, Note: "note", Time: now},
+		RunBenchmark{Package: "pkg", Name: "BenchmarkOne", Time: now},
+		BenchmarkResult{Package: "pkg", Name: "BenchmarkOne", Benchmark: Benchmark{Iterations: 10, NsPerOp: 1.5, MBPerSec: 2, BytesPerOp: 3, AllocsPerOp: 4, Metrics: map[string]float64{"p99-ns": 5}}},
+		EndBenchmark{Package: "pkg", Name: "BenchmarkOne", Result: "PASS"},
+		Panic{Package: "pkg", Line: "panic: boom"},
+		Race{Package: "pkg", Lines: []string{"WARNING: DATA RACE", "Write at 0x00c000010000"}},
+		Sanitizer{Package: "pkg", Lines: []string{"==1==ERROR: AddressSanitizer: heap-use-after-free", "SUMMARY: AddressSanitizer: heap-use-after-free"}},
+		FuzzProgress{Package: "pkg", Corpus: 1, Execs: 2, ExecsPerSec: 3, NewInteresting: 4},
+		FuzzFailure{Package: "pkg", Name: "FuzzOne", Path: "testdata/fuzz/FuzzOne/abc"},
+		Shuffle{Package: "pkg", Seed: "1652986251466377000"},
+		Status{Package: "pkg", Result: "FAIL"},
+		Summary{Package: "pkg", Name: "pkg", Result: "FAIL", Duration: time.Second, Note: "(cached)", Coverage: 12.5, CoveredPackages: []string{"pkg"}},
+		Coverage{Package: "pkg", Percent: 12.5, Packages: []string{"pkg"}},
+		BuildOutput{Name: "pkg"},
+		InfraError{Package: "pkg", Line: "signal: killed"},
+		Output{Package: "pkg", Test: "TestOne", Line: "output"},
+		Output{Package: "pkg", Test: "TestOne", Line: "error", Stream: "stderr"},
+	}
+	for _, want := range events {
+		got, err := want.Event().Typed()
+		if err != nil {
+			t.Errorf("Typed(%+v) error: %v", want.Event(), err)
+			continue
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Typed(%+v) incorrect, diff (-want +got):\n%s\n", want.Event(), diff)
+		}
+	}
+}
+
+func TestTypedUnknownEvent(t *testing.T) {
+	if _, err := (Event{Type: "end_tset"}).Typed(); err == nil {
+		t.Errorf("Typed of event with unknown type: got nil error, want error")
+	}
+}
+
+func TestFromEvents(t *testing.T) {
+	events := []TypedEvent{
+		RunTest{Package: "package/name", Name: "TestOne"},
+		Output{Package: "package/name", Test: "TestOne", Line: "one_test.go:10: failed"},
+		EndTest{Package: "package/name", Name: "TestOne", Result: "FAIL", Duration: time.Millisecond},
+		RunTest{Package: "package/name", Name: "TestTwo"},
+		EndTest{Package: "package/name", Name: "TestTwo", Result: "PASS"},
+		Summary{Package: "package/name", Name: "package/name", Result: "FAIL", Duration: time.Second},
+	}
+
+	var handled []Event
+	report := FromEvents(events, TimestampFunc(func() time.Time { return time.Time{} }), EventHandler(func(e Event) { handled = append(handled, e) }))
+
+	want := gtr.Report{Packages: []gtr.Package{{
+		Name:        "package/name",
+		Duration:    time.Second,
+		MaxParallel: 1,
+		Tests: []gtr.Test{
+			{ID: 1, Name: "TestOne", Result: gtr.Fail, Duration: time.Millisecond, Output: []string{"one_test.go:10: failed"}, FailureMessage: "failed", Data: map[string]interface{}{}},
+			{ID: 2, Name: "TestTwo", Result: gtr.Pass, Data: map[string]interface{}{}},
+		},
+	}}}
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Errorf("FromEvents report incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if len(handled) != len(events) {
+		t.Errorf("FromEvents called the event handler %d times, want %d", len(handled), len(events))
+	}
+}
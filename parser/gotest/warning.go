@@ -0,0 +1,46 @@
+package gotest
+
+import "fmt"
+
+// Kinds of warnings passed to the WarningHandler.
+const (
+	WarningUnknownEvent       = "unknown-event"       // an event has a type that isn't recognized
+	WarningTestNotStarted     = "test-not-started"    // a test ended without having been started, e.g. when go test ran without -v
+	WarningMissingSummary     = "missing-summary"     // a package or build error has no summary line, e.g. because go test was killed
+	WarningUnattributedOutput = "unattributed-output" // output doesn't belong to any package
+)
+
+// Warning is an anomaly found while creating a report from the parsed events.
+// Warnings don't prevent a report from being created, but may mean that the
+// input was incomplete or that the report doesn't contain everything in it.
+type Warning struct {
+	Package string // package the warning applies to, if known
+	Test    string // test the warning applies to, if any
+	Kind    string // kind of warning, one of the Warning constants
+	Message string
+}
+
+// String returns a description of warning w, prefixed by the package and test
+// it applies to.
+func (w Warning) String() string {
+	name := w.Package
+	if w.Test != "" && name != "" {
+		name += "." + w.Test
+	} else if w.Test != "" {
+		name = w.Test
+	}
+	if name == "" {
+		return w.Message
+	}
+	return fmt.Sprintf("%s: %s", name, w.Message)
+}
+
+// WarningHandler is an Option that sets a function that is called with every
+// warning found while creating the report, such as events of an unknown type
+// or tests that ended without having been started. Warnings are ignored if no
+// WarningHandler is set.
+func WarningHandler(f func(Warning)) Option {
+	return func(p *Parser) {
+		p.warningHandler = f
+	}
+}
@@ -0,0 +1,335 @@
+// Package junitxml parses JUnit XML reports, so that reports created by other
+// tools, or by an earlier run of go-junit-report, can be merged with other
+// reports, compared or converted to a different format.
+//
+// Every testsuite containing testcases becomes a package, named after the
+// testsuite. Testsuites may be nested in testsuites elements or in other
+// testsuites, in which case the nested testsuites inherit the properties of
+// their parents. The input may contain more than one XML document, for example
+// when several reports are concatenated.
+//
+// Testcases are converted to tests named after the testcase. Tests whose
+// classname differs from the package name are prefixed with their classname
+// and a dot. The failure, error and skipped elements of a testcase determine
+// its result, and their content becomes the output of the test, followed by
+// the content of its system-out and system-err elements.
+//
+// Reports written by go-junit-report are converted back into the report they
+// were created from as closely as possible: build and runtime errors, flaky
+// tests, extended results, coverage, shards, aborted runs, panics and
+// attachments are recognized. Information that isn't stored in JUnit XML, such
+// as the test output of passing tests written to system-out by the default
+// dialect, is restored where possible and lost otherwise.
+package junitxml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/junit"
+	"github.com/jstemmer/go-junit-report/v2/parser"
+)
+
+func init() {
+	parser.Register("junit", func() parser.Parser { return NewParser() })
+}
+
+// Parser parses JUnit XML reports.
+type Parser struct{}
+
+// NewParser returns a new JUnit XML parser.
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// testsuites is a testsuites element, which may contain testsuites and other
+// testsuites elements.
+type testsuites struct {
+	Suites []testsuite  `xml:"testsuite"`
+	Groups []testsuites `xml:"testsuites"`
+}
+
+// testsuite is a junit.Testsuite that may contain nested testsuites.
+type testsuite struct {
+	junit.Testsuite
+	Suites []testsuite `xml:"testsuite"`
+}
+
+// Parse parses JUnit XML reports from the given io.Reader r and returns the
+// gtr.Report they describe. The root element of each report must be either
+// testsuites or testsuite.
+func (p *Parser) Parse(r io.Reader) (gtr.Report, error) {
+	var report gtr.Report
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return report, nil
+		} else if err != nil {
+			return report, err
+		}
+		if text, ok := tok.(xml.CharData); ok && len(bytes.TrimSpace(text)) > 0 {
+			return report, fmt.Errorf("junitxml: unexpected text %q outside of a report", firstLine(string(text)))
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "testsuites":
+			var ts testsuites
+			if err := dec.DecodeElement(&ts, &start); err != nil {
+				return report, err
+			}
+			addSuites(&report, ts, nil)
+		case "testsuite":
+			var ts testsuite
+			if err := dec.DecodeElement(&ts, &start); err != nil {
+				return report, err
+			}
+			addSuite(&report, ts, nil)
+		default:
+			return report, fmt.Errorf("junitxml: unexpected root element %q, want testsuites or testsuite", start.Name.Local)
+		}
+	}
+}
+
+func addSuites(report *gtr.Report, ts testsuites, props []gtr.Property) {
+	for _, suite := range ts.Suites {
+		addSuite(report, suite, props)
+	}
+	for _, group := range ts.Groups {
+		addSuites(report, group, props)
+	}
+}
+
+// addSuite adds the package of testsuite ts to report, followed by the
+// packages of its nested testsuites. The properties of the package start with
+// the given properties inherited from its parents.
+func addSuite(report *gtr.Report, ts testsuite, props []gtr.Property) {
+	pkg := gtr.Package{Name: ts.Name, Hostname: ts.Hostname}
+	if pkg.Name == "" && len(ts.Testcases) > 0 {
+		pkg.Name = ts.Testcases[0].Classname
+	}
+	pkg.Properties = append(pkg.Properties, props...)
+	if ts.Package != "" {
+		pkg.Properties = append(pkg.Properties, gtr.Property{Name: junit.PropertyPackage, Value: ts.Package})
+	}
+	if ts.Properties != nil {
+		for _, prop := range *ts.Properties {
+			switch prop.Name {
+			case "coverage.statements.pct":
+				pkg.Coverage, _ = strconv.ParseFloat(prop.Value, 64)
+			case "build.duration":
+				pkg.BuildDuration = parseSeconds(prop.Value)
+			case "test.duration":
+				// the duration of the testsuite
+			case "shard.index":
+				pkg.ShardIndex, _ = strconv.Atoi(prop.Value)
+			case "shard.count":
+				pkg.ShardCount, _ = strconv.Atoi(prop.Value)
+			case junit.PropertyFailFast:
+				report.Aborted = report.Aborted || prop.Value == "true"
+			default:
+				pkg.Properties = append(pkg.Properties, gtr.Property{Name: prop.Name, Value: prop.Value})
+			}
+		}
+	}
+	pkg.Timestamp = parseTimestamp(ts.Timestamp)
+	pkg.Duration = parseSeconds(ts.Time)
+	pkg.Output, pkg.Stderr, pkg.Attachments = splitOutput(ts.SystemOut, ts.SystemErr)
+
+	for _, tc := range ts.Testcases {
+		addTestcase(&pkg, tc)
+	}
+	if len(pkg.Tests) > 0 || pkg.BuildError.Name != "" || pkg.RunError.Name != "" || len(ts.Suites) == 0 {
+		report.Packages = append(report.Packages, pkg)
+	}
+
+	inherited := append([]gtr.Property(nil), props...)
+	if ts.Properties != nil {
+		for _, prop := range *ts.Properties {
+			inherited = append(inherited, gtr.Property{Name: prop.Name, Value: prop.Value})
+		}
+	}
+	for _, child := range ts.Suites {
+		if child.Name == "" {
+			child.Name = ts.Name
+		}
+		addSuite(report, child, inherited)
+	}
+}
+
+// addTestcase adds testcase tc to pkg, either as a test or as the build or
+// runtime error of pkg.
+func addTestcase(pkg *gtr.Package, tc junit.Testcase) {
+	if tc.Error != nil && addError(pkg, tc) {
+		return
+	}
+
+	test := gtr.Test{
+		ID:       len(pkg.Tests),
+		Name:     tc.Name,
+		Duration: parseSeconds(tc.Time),
+		Result:   gtr.Pass,
+	}
+	if tc.Classname != "" && tc.Classname != pkg.Name {
+		test.Name = tc.Classname + "." + tc.Name
+	}
+	test.Level = strings.Count(test.Name, "/")
+
+	var extended gtr.Result
+	if tc.Properties != nil {
+		for _, prop := range *tc.Properties {
+			if prop.Name == "flaky" && prop.Value == "true" {
+				test.Result = gtr.Flaky
+				continue
+			}
+			if prop.Name == "result" {
+				if r, err := gtr.ParseResult(prop.Value); err == nil {
+					extended = r
+					continue
+				}
+			}
+			test.Properties = append(test.Properties, gtr.Property{Name: prop.Name, Value: prop.Value})
+		}
+	}
+
+	var data string
+	switch {
+	case tc.Failure != nil:
+		test.Result = gtr.Fail
+		data = tc.Failure.Data
+		test.FailureType = tc.Failure.Type
+		if strings.HasPrefix(tc.Failure.Message, "Panic: ") {
+			test.Panic = &gtr.PanicInfo{Message: strings.TrimPrefix(tc.Failure.Message, "Panic: "), Test: test.Name}
+		} else if tc.Failure.Message != "Failed" {
+			test.FailureMessage = tc.Failure.Message
+		}
+	case tc.Error != nil:
+		data = tc.Error.Data
+		if tc.Error.Message == "No test result found" {
+			test.Result = gtr.Unknown
+		} else {
+			test.Result = gtr.Fail
+			test.FailureMessage = tc.Error.Message
+			test.FailureType = tc.Error.Type
+		}
+	case tc.Skipped != nil:
+		test.Result = gtr.Skip
+		data = tc.Skipped.Data
+		if tc.Skipped.Message != "Skipped" {
+			test.SkipMessage = tc.Skipped.Message
+		}
+	}
+	if extended != gtr.Unknown && extended.Base() == test.Result {
+		test.Result = extended
+	}
+	test.Output = splitLines(data)
+	output, stderr, attachments := splitOutput(tc.SystemOut, tc.SystemErr)
+	test.Output = append(test.Output, output...)
+	test.Stderr = stderr
+	test.Attachments = attachments
+	pkg.Tests = append(pkg.Tests, test)
+}
+
+// addError sets the build or runtime error of pkg if testcase tc has the form
+// of the testcases that go-junit-report creates for these errors. It returns
+// false if tc is a regular testcase.
+func addError(pkg *gtr.Package, tc junit.Testcase) bool {
+	name := tc.Classname
+	if name == "" {
+		name = pkg.Name
+	}
+	output := splitLines(tc.Error.Data)
+	switch {
+	case tc.Error.Message == "Build error":
+		pkg.BuildError.Name = name
+		pkg.BuildError.Output = append(pkg.BuildError.Output, output...)
+		if tc.File != "" {
+			pkg.BuildError.Diagnostics = append(pkg.BuildError.Diagnostics, gtr.ParseDiagnostics(output)...)
+		} else {
+			pkg.BuildError.Cause = tc.Name
+		}
+	case tc.Name == "Failure" && (tc.Error.Message == "Runtime error" || tc.Error.Message == "Infrastructure error" || tc.Error.Type == gtr.ErrorKindSanitizer || strings.HasPrefix(tc.Error.Message, "Panic: ")):
+		pkg.RunError = gtr.Error{Name: name, Output: output}
+		if tc.Error.Message == "Infrastructure error" {
+			pkg.RunError.Kind = gtr.ErrorKindInfra
+		} else if tc.Error.Type == gtr.ErrorKindSanitizer {
+			pkg.RunError.Kind, pkg.RunError.Cause = gtr.ErrorKindSanitizer, tc.Error.Message
+		} else if strings.HasPrefix(tc.Error.Message, "Panic: ") {
+			pkg.RunError.Panic = &gtr.PanicInfo{Message: strings.TrimPrefix(tc.Error.Message, "Panic: ")}
+			if strings.HasPrefix(pkg.RunError.Panic.Message, "test timed out after ") {
+				pkg.RunError.Kind = gtr.ErrorKindTimeout
+				pkg.RunError.Cause = pkg.RunError.Panic.Message
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// splitOutput returns the lines of the system-out and system-err elements
+// stdout and stderr, and the attachments referenced in them using the
+// [[ATTACHMENT|path]] convention of the Jenkins JUnit Attachments plugin.
+func splitOutput(stdout, stderr *junit.Output) ([]string, []string, []gtr.Attachment) {
+	var lines [2][]string
+	var attachments []gtr.Attachment
+	for i, out := range []*junit.Output{stdout, stderr} {
+		if out == nil {
+			continue
+		}
+		for _, line := range splitLines(out.Data) {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "[[ATTACHMENT|") && strings.HasSuffix(trimmed, "]]") {
+				attachments = append(attachments, gtr.Attachment{Path: trimmed[len("[[ATTACHMENT|") : len(trimmed)-len("]]")]})
+				continue
+			}
+			lines[i] = append(lines[i], line)
+		}
+	}
+	return lines[0], lines[1], attachments
+}
+
+func firstLine(text string) string {
+	text = strings.TrimSpace(text)
+	if idx := strings.IndexByte(text, '\n'); idx >= 0 {
+		return text[:idx]
+	}
+	return text
+}
+
+func splitLines(data string) []string {
+	if data == "" {
+		return nil
+	}
+	return strings.Split(data, "\n")
+}
+
+// parseSeconds parses a duration in seconds, returning 0 if s is not a valid
+// number.
+func parseSeconds(s string) time.Duration {
+	sec, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(sec * float64(time.Second)).Round(time.Microsecond)
+}
+
+// parseTimestamp parses an ISO8601 timestamp, with or without timezone. It
+// returns the zero time if s is not a valid timestamp.
+func parseTimestamp(s string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
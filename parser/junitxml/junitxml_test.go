@@ -0,0 +1,252 @@
+package junitxml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/junit"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  gtr.Report
+	}{
+		{
+			"nested testsuites",
+			`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+	<testsuite name="root" timestamp="2022-01-01T00:00:00">
+		<properties>
+			<property name="os" value="linux"></property>
+		</properties>
+		<testsuite name="com.example.FooTest" tests="3" failures="1" time="1.5">
+			<properties>
+				<property name="java.version" value="17"></property>
+			</properties>
+			<testcase name="testPass" classname="com.example.FooTest" time="0.5">
+				<system-out>hello
+world</system-out>
+			</testcase>
+			<testcase name="testFail" classname="com.example.FooTest" time="1">
+				<failure message="expected 1" type="AssertionError">at FooTest.java:10</failure>
+				<system-err>stderr</system-err>
+			</testcase>
+			<testcase name="testSkip" classname="com.example.FooTest">
+				<skipped></skipped>
+			</testcase>
+		</testsuite>
+	</testsuite>
+</testsuites>`,
+			gtr.Report{Packages: []gtr.Package{
+				{
+					Name:       "com.example.FooTest",
+					Duration:   1500 * time.Millisecond,
+					Properties: []gtr.Property{{Name: "os", Value: "linux"}, {Name: "java.version", Value: "17"}},
+					Tests: []gtr.Test{
+						{ID: 0, Name: "testPass", Duration: 500 * time.Millisecond, Result: gtr.Pass, Output: []string{"hello", "world"}},
+						{ID: 1, Name: "testFail", Duration: time.Second, Result: gtr.Fail, FailureMessage: "expected 1", FailureType: "AssertionError", Output: []string{"at FooTest.java:10"}, Stderr: []string{"stderr"}},
+						{ID: 2, Name: "testSkip", Result: gtr.Skip, SkipMessage: ""},
+					},
+				},
+			}},
+		},
+		{
+			"testsuite root element",
+			`<testsuite name="pytest" timestamp="2022-01-01T12:00:00.123456">
+	<testcase classname="tests.test_one" name="test_a" time="0.001"></testcase>
+	<testcase classname="tests.test_one" name="test_b"><error message="fixture not found"></error></testcase>
+</testsuite>`,
+			gtr.Report{Packages: []gtr.Package{
+				{
+					Name:      "pytest",
+					Timestamp: time.Date(2022, 1, 1, 12, 0, 0, 123456000, time.UTC),
+					Tests: []gtr.Test{
+						{ID: 0, Name: "tests.test_one.test_a", Duration: time.Millisecond, Result: gtr.Pass},
+						{ID: 1, Name: "tests.test_one.test_b", Result: gtr.Fail, FailureMessage: "fixture not found"},
+					},
+				},
+			}},
+		},
+		{
+			"go-junit-report",
+			`<testsuites tests="6" failures="2" errors="2" skipped="1">
+	<testsuite name="package/one" tests="4" failures="2" errors="1" id="0" hostname="host" skipped="1" time="0.500" timestamp="2022-01-01T00:00:00Z">
+		<properties>
+			<property name="go.version" value="1.18"></property>
+			<property name="coverage.statements.pct" value="75.50"></property>
+			<property name="shard.index" value="1"></property>
+			<property name="shard.count" value="2"></property>
+			<property name="failfast" value="true"></property>
+		</properties>
+		<system-out><![CDATA[[[ATTACHMENT|out.log]]]]></system-out>
+		<testcase name="TestFlaky/sub" classname="package/one" time="0.100">
+			<properties>
+				<property name="flaky" value="true"></property>
+			</properties>
+		</testcase>
+		<testcase name="TestPanic" classname="package/one" time="0.000">
+			<failure message="Panic: oops"><![CDATA[panic: oops]]></failure>
+		</testcase>
+		<testcase name="TestSkip" classname="package/one" time="0.000">
+			<skipped message="Skipped"><![CDATA[skip_test.go:5: ]]></skipped>
+		</testcase>
+		<testcase name="TestUnknown" classname="package/one" time="0.000">
+			<error message="No test result found"></error>
+		</testcase>
+		<testcase name="Failure" classname="package/one" time="0.000">
+			<error message="Runtime error"><![CDATA[exit status 1]]></error>
+		</testcase>
+	</testsuite>
+	<testsuite name="package/two" tests="2" failures="0" errors="2" id="1" time="0.000">
+		<testcase name="two.go:1:2" classname="package/two" time="0.000" file="two.go" line="1">
+			<error message="Build error"><![CDATA[two.go:1:2: undefined: x]]></error>
+		</testcase>
+		<testcase name="two.go:3" classname="package/two" time="0.000" file="two.go" line="3">
+			<error message="Build error"><![CDATA[two.go:3: undefined: y]]></error>
+		</testcase>
+	</testsuite>
+</testsuites>`,
+			gtr.Report{Aborted: true, Packages: []gtr.Package{
+				{
+					Name:        "package/one",
+					Timestamp:   time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+					Duration:    500 * time.Millisecond,
+					Coverage:    75.5,
+					Hostname:    "host",
+					ShardIndex:  1,
+					ShardCount:  2,
+					Properties:  []gtr.Property{{Name: "go.version", Value: "1.18"}},
+					Attachments: []gtr.Attachment{{Path: "out.log"}},
+					Tests: []gtr.Test{
+						{ID: 0, Name: "TestFlaky/sub", Duration: 100 * time.Millisecond, Result: gtr.Flaky, Level: 1},
+						{ID: 1, Name: "TestPanic", Result: gtr.Fail, Panic: &gtr.PanicInfo{Message: "oops", Test: "TestPanic"}, Output: []string{"panic: oops"}},
+						{ID: 2, Name: "TestSkip", Result: gtr.Skip, Output: []string{"skip_test.go:5: "}},
+						{ID: 3, Name: "TestUnknown", Result: gtr.Unknown},
+					},
+					RunError: gtr.Error{Name: "package/one", Output: []string{"exit status 1"}},
+				},
+				{
+					Name: "package/two",
+					BuildError: gtr.Error{
+						Name:   "package/two",
+						Output: []string{"two.go:1:2: undefined: x", "two.go:3: undefined: y"},
+						Diagnostics: []gtr.Diagnostic{
+							{File: "two.go", Line: 1, Column: 2, Message: "undefined: x"},
+							{File: "two.go", Line: 3, Message: "undefined: y"},
+						},
+					},
+				},
+			}},
+		},
+		{
+			"concatenated reports",
+			`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites><testsuite name="one"><testcase name="TestOne" classname="one"></testcase></testsuite></testsuites>
+<?xml version="1.0" encoding="UTF-8"?>
+<testsuites><testsuite name="two"><testcase name="TestTwo" classname="two"></testcase></testsuite></testsuites>`,
+			gtr.Report{Packages: []gtr.Package{
+				{Name: "one", Tests: []gtr.Test{{Name: "TestOne", Result: gtr.Pass}}},
+				{Name: "two", Tests: []gtr.Test{{Name: "TestTwo", Result: gtr.Pass}}},
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NewParser().Parse(strings.NewReader(test.input))
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Parse returned unexpected report, diff (-want +got):\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, input := range []string{"<html></html>", "<testsuites><testsuite>", "not xml"} {
+		if _, err := NewParser().Parse(strings.NewReader(input)); err == nil {
+			t.Errorf("Parse(%q) did not return an error", input)
+		}
+	}
+}
+
+// TestRoundTrip verifies that the reports in testdata are written identically
+// after parsing them. Reports that were written with options that only change
+// the testsuites element are skipped.
+func TestRoundTrip(t *testing.T) {
+	skip := map[string]bool{
+		"005-report.xml": true, // no XML header
+		"042-report.xml": true, // stylesheet
+		"049-report.xml": true, // stable ids
+		"054-report.xml": true, // wall time
+	}
+	files, err := filepath.Glob("../../testdata/*-report.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if skip[filepath.Base(file)] {
+			continue
+		}
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			want, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			report, err := NewParser().Parse(bytes.NewReader(want))
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			suites := junit.CreateFromReport(report, "")
+			var got bytes.Buffer
+			got.WriteString(xml.Header)
+			if err := suites.WriteXML(&got); err != nil {
+				t.Fatalf("WriteXML error: %v", err)
+			}
+			if diff := cmp.Diff(string(want), got.String()); diff != "" {
+				t.Errorf("WriteXML output incorrect, diff (-want +got):\n%s\n", diff)
+			}
+		})
+	}
+}
+
+// resultTimedOut is a result registered for TestExtendedResults.
+var resultTimedOut = gtr.RegisterResult("timedout", gtr.Fail)
+
+func TestExtendedResults(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{{
+		Name: "package/name",
+		Tests: []gtr.Test{
+			{ID: 0, Name: "TestTimedOut", Result: resultTimedOut, FailureMessage: "test timed out after 1s"},
+			{ID: 1, Name: "TestInconclusive", Result: gtr.Inconclusive},
+		},
+	}}}
+	suites := junit.CreateFromReport(report, "")
+	var buf bytes.Buffer
+	if err := suites.WriteXML(&buf); err != nil {
+		t.Fatalf("WriteXML error: %v", err)
+	}
+	for _, want := range []string{`<failure message="test timed out after 1s">`, `<property name="result" value="timedout"></property>`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteXML output does not contain %s:\n%s", want, buf.String())
+		}
+	}
+
+	got, err := NewParser().Parse(&buf)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if diff := cmp.Diff(report, got); diff != "" {
+		t.Errorf("Parse result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,255 @@
+// Package lint parses the diagnostics reported by go vet and staticcheck.
+//
+// The diagnostics are added to a report as a synthetic package, named "lint"
+// by default, containing a failing test for each diagnostic. This makes it
+// possible to include code quality findings in the same report as the test
+// results, for example by merging the reports with gtr.Merge.
+//
+// The Parser recognizes the following formats:
+//   - the output of go vet -json, which consists of JSON objects mapping
+//     package paths and analyzer names to diagnostics,
+//   - the output of staticcheck -f json, which contains a JSON object for
+//     each diagnostic,
+//   - the text output of go vet and staticcheck, which contains a
+//     file:line:column: message line for each diagnostic.
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/parser"
+)
+
+// DefaultPackageName is the name of the package containing the diagnostics,
+// unless another name is given with PackageName.
+const DefaultPackageName = "lint"
+
+var (
+	regexPosition = regexp.MustCompile(`^(.+?):(\d+)(?::(\d+))?$`)
+	regexCode     = regexp.MustCompile(`^(.*) \(([A-Z]+\d+)\)$`)
+)
+
+func init() {
+	parser.Register("lint", func() parser.Parser { return NewParser() })
+}
+
+// Finding is a diagnostic reported by a check of go vet or staticcheck.
+type Finding struct {
+	gtr.Diagnostic
+	Check    string // name of the analyzer or staticcheck code, empty if unknown
+	Package  string // import path of the package, empty if unknown
+	Severity string // staticcheck severity, empty if unknown
+}
+
+// Option configures a Parser.
+type Option func(*Parser)
+
+// PackageName sets the name of the package containing the diagnostics.
+func PackageName(name string) Option {
+	return func(p *Parser) {
+		p.packageName = name
+	}
+}
+
+// Parser parses go vet and staticcheck output.
+type Parser struct {
+	packageName string
+}
+
+// NewParser returns a new lint parser.
+func NewParser(options ...Option) *Parser {
+	p := &Parser{packageName: DefaultPackageName}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// Parse parses go vet or staticcheck output from the given io.Reader r and
+// returns a report containing a single package with a test for each
+// diagnostic. Diagnostics that staticcheck reports with the "ignored"
+// severity are skipped, all others fail.
+func (p *Parser) Parse(r io.Reader) (gtr.Report, error) {
+	findings, err := ParseFindings(r)
+	if err != nil {
+		return gtr.Report{}, err
+	}
+
+	pkg := gtr.Package{Name: p.packageName}
+	for i, f := range findings {
+		test := gtr.NewTest(i+1, testName(f))
+		test.Result = gtr.Fail
+		test.FailureMessage = f.Message
+		test.FailureType = f.Check
+		test.Output = []string{f.String()}
+		if f.Severity == "ignored" {
+			test.Result = gtr.Skip
+			test.FailureMessage, test.FailureType = "", ""
+			test.SkipMessage = f.Message
+		}
+		if f.Check != "" {
+			test.AddProperty("lint.check", f.Check)
+		}
+		if f.Package != "" {
+			test.AddProperty("lint.package", f.Package)
+		}
+		if f.Severity != "" {
+			test.AddProperty("lint.severity", f.Severity)
+		}
+		pkg.Tests = append(pkg.Tests, test)
+	}
+	return gtr.Report{Packages: []gtr.Package{pkg}}, nil
+}
+
+// testName returns the name of the test for finding f, consisting of its
+// check and location.
+func testName(f Finding) string {
+	if f.Check == "" {
+		return f.Location()
+	}
+	return f.Check + " " + f.Location()
+}
+
+// ParseFindings parses go vet or staticcheck output from the given io.Reader
+// r and returns all findings in the order in which they appear.
+func ParseFindings(r io.Reader) ([]Finding, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// go vet prints a "# package" line before the diagnostics of each
+	// package, which are removed so the rest can be parsed as JSON.
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	body := strings.TrimSpace(strings.Join(lines, "\n"))
+	if strings.HasPrefix(body, "{") {
+		return parseJSON(body)
+	}
+	return parseText(lines), nil
+}
+
+// staticcheckDiagnostic is a diagnostic in the output of staticcheck -f json.
+type staticcheckDiagnostic struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Location struct {
+		File   string `json:"file"`
+		Line   int    `json:"line"`
+		Column int    `json:"column"`
+	} `json:"location"`
+	Message string `json:"message"`
+}
+
+// vetDiagnostic is a diagnostic in the output of go vet -json.
+type vetDiagnostic struct {
+	Posn    string `json:"posn"`
+	Message string `json:"message"`
+}
+
+// parseJSON parses a stream of JSON values, each of which is either a
+// staticcheck diagnostic or the go vet diagnostics of one or more packages.
+func parseJSON(body string) ([]Finding, error) {
+	var findings []Finding
+	dec := json.NewDecoder(strings.NewReader(body))
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			return findings, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("lint: invalid JSON: %w", err)
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, fmt.Errorf("lint: invalid JSON: %w", err)
+		}
+		if _, ok := fields["code"]; ok {
+			var d staticcheckDiagnostic
+			if err := json.Unmarshal(raw, &d); err != nil {
+				return nil, fmt.Errorf("lint: invalid staticcheck diagnostic: %w", err)
+			}
+			findings = append(findings, Finding{
+				Diagnostic: gtr.Diagnostic{File: d.Location.File, Line: d.Location.Line, Column: d.Location.Column, Message: d.Message},
+				Check:      d.Code,
+				Severity:   d.Severity,
+			})
+			continue
+		}
+
+		vet, err := parseVet(fields)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, vet...)
+	}
+}
+
+// parseVet parses the go vet -json diagnostics of the packages in fields.
+// Analyzers that failed to run report an error instead of diagnostics, which
+// are ignored.
+func parseVet(fields map[string]json.RawMessage) ([]Finding, error) {
+	var findings []Finding
+	for _, pkgName := range sortedKeys(fields) {
+		var analyzers map[string]json.RawMessage
+		if err := json.Unmarshal(fields[pkgName], &analyzers); err != nil {
+			return nil, fmt.Errorf("lint: invalid go vet output for package %s: %w", pkgName, err)
+		}
+		for _, name := range sortedKeys(analyzers) {
+			var diags []vetDiagnostic
+			if !bytes.HasPrefix(bytes.TrimSpace(analyzers[name]), []byte("[")) {
+				continue
+			}
+			if err := json.Unmarshal(analyzers[name], &diags); err != nil {
+				return nil, fmt.Errorf("lint: invalid go vet output for analyzer %s: %w", name, err)
+			}
+			for _, d := range diags {
+				f := Finding{Diagnostic: gtr.Diagnostic{File: d.Posn, Message: d.Message}, Check: name, Package: pkgName}
+				if matches := regexPosition.FindStringSubmatch(d.Posn); matches != nil {
+					f.File = matches[1]
+					f.Line, _ = strconv.Atoi(matches[2])
+					f.Column, _ = strconv.Atoi(matches[3])
+				}
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings, nil
+}
+
+// parseText parses text output containing a file:line:column: message line
+// for each diagnostic. Staticcheck adds the check code in parentheses at the
+// end of the message.
+func parseText(lines []string) []Finding {
+	var findings []Finding
+	for _, d := range gtr.ParseDiagnostics(lines) {
+		f := Finding{Diagnostic: d}
+		if matches := regexCode.FindStringSubmatch(d.Message); matches != nil {
+			f.Message, f.Check = matches[1], matches[2]
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,152 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseFindings(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Finding
+	}{
+		{
+			"vet json",
+			`# package/one
+{
+	"package/one": {
+		"printf": [
+			{
+				"posn": "/src/one/one.go:12:2",
+				"message": "fmt.Println call has possible Printf formatting directive %d"
+			}
+		],
+		"unreachable": [
+			{
+				"posn": "/src/one/one.go:20:2",
+				"message": "unreachable code"
+			}
+		]
+	}
+}
+# package/two
+{
+	"package/two": {
+		"copylocks": {
+			"error": "analysis skipped due to errors in package"
+		}
+	}
+}
+`,
+			[]Finding{
+				{
+					Diagnostic: gtr.Diagnostic{File: "/src/one/one.go", Line: 12, Column: 2, Message: "fmt.Println call has possible Printf formatting directive %d"},
+					Check:      "printf",
+					Package:    "package/one",
+				},
+				{
+					Diagnostic: gtr.Diagnostic{File: "/src/one/one.go", Line: 20, Column: 2, Message: "unreachable code"},
+					Check:      "unreachable",
+					Package:    "package/one",
+				},
+			},
+		},
+		{
+			"staticcheck json",
+			`{"code":"SA4006","severity":"error","location":{"file":"/src/one/one.go","line":5,"column":2},"end":{"file":"/src/one/one.go","line":5,"column":3},"message":"this value of x is never used"}
+{"code":"S1000","severity":"ignored","location":{"file":"/src/two/two.go","line":8,"column":1},"end":{"file":"","line":0,"column":0},"message":"should use a simple channel send"}
+`,
+			[]Finding{
+				{
+					Diagnostic: gtr.Diagnostic{File: "/src/one/one.go", Line: 5, Column: 2, Message: "this value of x is never used"},
+					Check:      "SA4006",
+					Severity:   "error",
+				},
+				{
+					Diagnostic: gtr.Diagnostic{File: "/src/two/two.go", Line: 8, Column: 1, Message: "should use a simple channel send"},
+					Check:      "S1000",
+					Severity:   "ignored",
+				},
+			},
+		},
+		{
+			"staticcheck text",
+			"one/one.go:5:2: this value of x is never used (SA4006)\n",
+			[]Finding{
+				{
+					Diagnostic: gtr.Diagnostic{File: "one/one.go", Line: 5, Column: 2, Message: "this value of x is never used"},
+					Check:      "SA4006",
+				},
+			},
+		},
+		{
+			"vet text",
+			"# package/one\nvet: one/one.go:12:2: unreachable code\n",
+			[]Finding{
+				{Diagnostic: gtr.Diagnostic{File: "one/one.go", Line: 12, Column: 2, Message: "unreachable code"}},
+			},
+		},
+		{"empty", "", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseFindings(strings.NewReader(test.input))
+			if err != nil {
+				t.Fatalf("ParseFindings error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ParseFindings incorrect, diff (-want +got):\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestParseFindingsInvalidJSON(t *testing.T) {
+	if _, err := ParseFindings(strings.NewReader(`{"package/one": [`)); err == nil {
+		t.Errorf("ParseFindings did not return an error for invalid JSON")
+	}
+}
+
+func TestParse(t *testing.T) {
+	input := `{"code":"SA4006","severity":"error","location":{"file":"one.go","line":5,"column":2},"message":"x is never used"}
+{"code":"S1000","severity":"ignored","location":{"file":"two.go","line":8,"column":1},"message":"use a simple channel send"}
+`
+	got, err := NewParser(PackageName("staticcheck")).Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	want := gtr.Report{Packages: []gtr.Package{{
+		Name: "staticcheck",
+		Tests: []gtr.Test{
+			{
+				ID:             1,
+				Name:           "SA4006 one.go:5:2",
+				Result:         gtr.Fail,
+				FailureMessage: "x is never used",
+				FailureType:    "SA4006",
+				Output:         []string{"one.go:5:2: x is never used"},
+				Properties:     []gtr.Property{{Name: "lint.check", Value: "SA4006"}, {Name: "lint.severity", Value: "error"}},
+				Data:           map[string]interface{}{},
+			},
+			{
+				ID:          2,
+				Name:        "S1000 two.go:8:1",
+				Result:      gtr.Skip,
+				SkipMessage: "use a simple channel send",
+				Output:      []string{"two.go:8:1: use a simple channel send"},
+				Properties:  []gtr.Property{{Name: "lint.check", Value: "S1000"}, {Name: "lint.severity", Value: "ignored"}},
+				Data:        map[string]interface{}{},
+			},
+		},
+	}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Parse incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,74 @@
+// Package parser defines the interface of parsers that convert the output of
+// a test tool to a gtr.Report, and a registry of the available parsers.
+//
+// Parsers register themselves by name in an init function, similar to
+// database/sql drivers, so a program only needs to import a parser package to
+// make it available:
+//
+//	import _ "github.com/jstemmer/go-junit-report/v2/parser/gotest"
+//
+//	p, err := parser.New("gotest")
+//
+// The gotest package registers the gotest and gojson parsers, which parse the
+// output of go test and go test -json respectively.
+package parser
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// Parser parses the output of a test tool.
+type Parser interface {
+	// Parse reads all input from r and returns the report it describes.
+	Parse(r io.Reader) (gtr.Report, error)
+}
+
+// Factory creates a new Parser with its default options.
+type Factory func() Parser
+
+var (
+	mu        sync.RWMutex
+	factories = make(map[string]Factory)
+)
+
+// Register makes a parser available by the given name. It panics if Register
+// is called twice with the same name or if factory is nil.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	if factory == nil {
+		panic("parser: Register factory is nil")
+	}
+	if _, dup := factories[name]; dup {
+		panic("parser: Register called twice for parser " + name)
+	}
+	factories[name] = factory
+}
+
+// New returns a new parser of the parser registered by the given name.
+func New(name string) (Parser, error) {
+	mu.RLock()
+	factory, ok := factories[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("parser: unknown parser %q", name)
+	}
+	return factory(), nil
+}
+
+// Names returns the sorted names of all registered parsers.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	var names []string
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type lineParser struct{}
+
+func (lineParser) Parse(r io.Reader) (gtr.Report, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return gtr.Report{}, err
+	}
+	var report gtr.Report
+	for _, name := range strings.Fields(string(data)) {
+		report.Packages = append(report.Packages, gtr.Package{Name: name})
+	}
+	return report, nil
+}
+
+func TestRegister(t *testing.T) {
+	Register("test-lines", func() Parser { return lineParser{} })
+
+	p, err := New("test-lines")
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	report, err := p.Parse(strings.NewReader("one two"))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	want := gtr.Report{Packages: []gtr.Package{{Name: "one"}, {Name: "two"}}}
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Errorf("Parse result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	found := false
+	for _, name := range Names() {
+		found = found || name == "test-lines"
+	}
+	if !found {
+		t.Errorf("Names() = %v, does not contain registered parser", Names())
+	}
+
+	if _, err := New("unknown"); err == nil {
+		t.Errorf("New(%q) did not return an error", "unknown")
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	Register("test-twice", func() Parser { return lineParser{} })
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Register did not panic when called twice with the same name")
+		}
+	}()
+	Register("test-twice", func() Parser { return lineParser{} })
+}
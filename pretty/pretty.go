@@ -0,0 +1,198 @@
+// Package pretty writes a compact, human readable summary of a report to a
+// terminal, for following test results during local development.
+//
+// Every package is written on a single line, starting with a glyph for its
+// result: ✓ for passed packages, ✖ for packages with failures or errors and
+// ∅ for packages without tests. The output of failed tests and of build and
+// runtime errors is grouped at the bottom, followed by a line with the totals
+// of the report. Colors are only used when enabled in the Options, since the
+// output may not be written to a terminal.
+package pretty
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/escape"
+	"github.com/jstemmer/go-junit-report/v2/internal/timefmt"
+)
+
+// Glyphs used for the results of packages.
+const (
+	GlyphPass    = "✓"
+	GlyphFail    = "✖"
+	GlyphNoTests = "∅"
+)
+
+// ANSI escape codes of the colors used in the output.
+const (
+	colorReset  = "\x1b[0m"
+	colorBold   = "\x1b[1m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+)
+
+// Options configures how a report is written.
+type Options struct {
+	// Color enables ANSI colors in the output.
+	Color bool
+}
+
+// Write writes the summary of report r to w.
+func Write(w io.Writer, r gtr.Report, opts Options) error {
+	pw := writer{w: bufio.NewWriter(w), color: opts.Color}
+	for _, pkg := range r.Packages {
+		pw.writePackage(pkg)
+	}
+	pw.writeSkipped(r)
+	pw.writeFailures(r)
+	pw.writeTotals(r.Summary())
+	return pw.w.Flush()
+}
+
+type writer struct {
+	w     *bufio.Writer
+	color bool
+}
+
+// paint returns s in the given color, if colors are enabled.
+func (pw *writer) paint(color, s string) string {
+	if !pw.color || s == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+func (pw *writer) writePackage(pkg gtr.Package) {
+	glyph := pw.paint(colorGreen, GlyphPass)
+	if !isSuccessful(pkg) {
+		glyph = pw.paint(colorRed, GlyphFail)
+	} else if len(pkg.Tests) == 0 {
+		glyph = pw.paint(colorYellow, GlyphNoTests)
+	}
+
+	var details []string
+	switch {
+	case pkg.BuildError.Name != "":
+		details = append(details, pw.paint(colorRed, "build failed"))
+	case pkg.Cached:
+		details = append(details, "cached")
+	case pkg.Duration > 0:
+		details = append(details, timefmt.Readable(pkg.Duration))
+	}
+	if pkg.Coverage > 0 {
+		details = append(details, fmt.Sprintf("coverage: %.1f%%", pkg.Coverage))
+	}
+	line := glyph + "  " + escape.Line(pkg.Name)
+	if len(details) > 0 {
+		line += " (" + strings.Join(details, ", ") + ")"
+	}
+	fmt.Fprintln(pw.w, line)
+}
+
+// writeSkipped writes the skipped tests of report r together with the reason
+// they were skipped, if known.
+func (pw *writer) writeSkipped(r gtr.Report) {
+	header := false
+	for _, pkg := range r.Packages {
+		for _, test := range pkg.Tests {
+			if test.Result.Base() != gtr.Skip {
+				continue
+			}
+			if !header {
+				fmt.Fprintf(pw.w, "\n%s\n", pw.paint(colorYellow, "=== Skipped"))
+				header = true
+			}
+			line := fmt.Sprintf("=== SKIP: %s %s", escape.Line(pkg.Name), escape.Line(test.Name))
+			if test.SkipMessage != "" {
+				line += ": " + escape.Line(test.SkipMessage)
+			}
+			fmt.Fprintln(pw.w, line)
+		}
+	}
+}
+
+// writeFailures writes the output of the failed tests and of the build and
+// runtime errors of report r.
+func (pw *writer) writeFailures(r gtr.Report) {
+	header := false
+	section := func(title string, output []string) {
+		if !header {
+			fmt.Fprintf(pw.w, "\n%s\n", pw.paint(colorRed+colorBold, "=== Failed"))
+			header = true
+		} else {
+			fmt.Fprintln(pw.w)
+		}
+		fmt.Fprintln(pw.w, pw.paint(colorRed, title))
+		for _, line := range output {
+			fmt.Fprintln(pw.w, line)
+		}
+	}
+
+	for _, pkg := range r.Packages {
+		if pkg.BuildError.Name != "" {
+			section("=== Build error: "+escape.Line(pkg.Name), errorOutput(pkg.BuildError))
+		}
+		for _, test := range pkg.Tests {
+			switch test.Result.Base() {
+			case gtr.Fail:
+				section(fmt.Sprintf("=== FAIL: %s %s (%s)", escape.Line(pkg.Name), escape.Line(test.Name), timefmt.Readable(test.Duration)), test.Output)
+			case gtr.Unknown:
+				section(fmt.Sprintf("=== NO RESULT: %s %s", escape.Line(pkg.Name), escape.Line(test.Name)), test.Output)
+			}
+		}
+		if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			section("=== Runtime error: "+escape.Line(pkg.Name), errorOutput(pkg.RunError))
+		}
+	}
+}
+
+func (pw *writer) writeTotals(s gtr.Summary) {
+	counts := []string{fmt.Sprintf("%d tests", s.Tests)}
+	if s.Skipped > s.NotRun {
+		counts = append(counts, pw.paint(colorYellow, fmt.Sprintf("%d skipped", s.Skipped-s.NotRun)))
+	}
+	if s.NotRun > 0 {
+		counts = append(counts, pw.paint(colorYellow, fmt.Sprintf("%d not run", s.NotRun)))
+	}
+	if s.Flaky > 0 {
+		counts = append(counts, pw.paint(colorYellow, fmt.Sprintf("%d flaky", s.Flaky)))
+	}
+	if s.Failed > 0 {
+		counts = append(counts, pw.paint(colorRed, fmt.Sprintf("%d failures", s.Failed)))
+	}
+	if s.Errors > 0 {
+		counts = append(counts, pw.paint(colorRed, fmt.Sprintf("%d errors", s.Errors)))
+	}
+	status := pw.paint(colorGreen+colorBold, "DONE")
+	if s.Failed > 0 || s.Errors > 0 || s.Killed {
+		status = pw.paint(colorRed+colorBold, "FAIL")
+	}
+	fmt.Fprintf(pw.w, "\n%s %s in %s\n", status, strings.Join(counts, ", "), timefmt.Readable(s.Duration))
+	if s.Aborted {
+		line := "aborted after the first failure"
+		if s.TimeToFirstFailure > 0 {
+			line += " at " + timefmt.Readable(s.TimeToFirstFailure)
+		}
+		fmt.Fprintln(pw.w, pw.paint(colorYellow, line))
+	}
+}
+
+// isSuccessful returns true if package pkg has no build or runtime errors and
+// none of its tests failed.
+func isSuccessful(pkg gtr.Package) bool {
+	r := gtr.Report{Packages: []gtr.Package{pkg}}
+	return r.IsSuccessful()
+}
+
+// errorOutput returns the output of error e, or its cause if it has none.
+func errorOutput(e gtr.Error) []string {
+	if len(e.Output) == 0 && e.Cause != "" {
+		return []string{e.Cause}
+	}
+	return e.Output
+}
@@ -0,0 +1,81 @@
+package pretty
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testReport = gtr.Report{Packages: []gtr.Package{
+	{
+		Name:     "package/one",
+		Duration: 1500 * time.Millisecond,
+		Coverage: 75,
+		Tests: []gtr.Test{
+			{Name: "TestOne", Result: gtr.Pass, Duration: time.Second},
+			{Name: "TestTwo", Result: gtr.Fail, Duration: 250 * time.Millisecond, Output: []string{"one_test.go:12: boom"}},
+			{Name: "TestThree", Result: gtr.Skip, SkipMessage: "not on linux"},
+		},
+	},
+	{Name: "package/cached", Cached: true, Tests: []gtr.Test{{Name: "TestCached", Result: gtr.Pass}}},
+	{Name: "package/empty"},
+	{
+		Name:       "package/broken",
+		BuildError: gtr.Error{Name: "package/broken", Output: []string{"broken.go:3:1: syntax error"}},
+	},
+	{
+		Name:     "package/panics",
+		Tests:    []gtr.Test{{Name: "TestPanic", Result: gtr.Pass}},
+		RunError: gtr.Error{Name: "package/panics", Cause: "exit status 2"},
+	},
+}}
+
+func TestWrite(t *testing.T) {
+	want := `✖  package/one (1.500s, coverage: 75.0%)
+✓  package/cached (cached)
+∅  package/empty
+✖  package/broken (build failed)
+✖  package/panics
+
+=== Skipped
+=== SKIP: package/one TestThree: not on linux
+
+=== Failed
+=== FAIL: package/one TestTwo (0.250s)
+one_test.go:12: boom
+
+=== Build error: package/broken
+broken.go:3:1: syntax error
+
+=== Runtime error: package/panics
+exit status 2
+
+FAIL 5 tests, 1 skipped, 1 failures, 2 errors in 1.500s
+`
+	var buf bytes.Buffer
+	if err := Write(&buf, testReport, Options{}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Write output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWriteColor(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{
+		{Name: "package/one", Duration: time.Second, Tests: []gtr.Test{{Name: "TestOne", Result: gtr.Pass}}},
+	}}
+	want := "\x1b[32m✓\x1b[0m  package/one (1.000s)\n\n\x1b[32m\x1b[1mDONE\x1b[0m 1 tests in 1.000s\n"
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report, Options{Color: true}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Write output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,99 @@
+// Package progress shows the progress of a test run while its output is being
+// parsed.
+//
+// A Writer keeps a single status line up to date with the number of tests
+// that passed, failed and were skipped so far, followed by the package that
+// was most recently seen. Its HandleEvent method can be used as the handler of
+// the gotest.EventHandler option, so that the progress is shown while the
+// report is being built.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/v2/parser/gotest"
+)
+
+// Writer writes a progress line to an io.Writer while test events are being
+// parsed. The line is rewritten in place using a carriage return whenever the
+// number of finished tests or the current package changes.
+type Writer struct {
+	w   io.Writer
+	err error
+
+	passed, failed, skipped int
+	pkg                     string
+
+	last string // the most recently written line
+}
+
+// NewWriter returns a new Writer that writes the progress line to w, which is
+// usually os.Stderr.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// HandleEvent updates the progress line for the given event. It can be used
+// as the handler of the gotest.EventHandler option.
+func (pw *Writer) HandleEvent(ev gotest.Event) {
+	if ev.Package != "" {
+		pw.pkg = ev.Package
+	}
+	switch ev.Type {
+	case "end_test", "end_benchmark":
+		switch ev.Result {
+		case "PASS", "BENCH":
+			pw.passed++
+		case "FAIL":
+			pw.failed++
+		case "SKIP":
+			pw.skipped++
+		}
+	case "benchmark":
+		pw.passed++
+	case "summary":
+		// Package names are only known once the package has finished in the
+		// text output of go test.
+		pw.pkg = ev.Name
+	default:
+		return
+	}
+	pw.update()
+}
+
+// Line returns the current progress line, e.g. "3 passed, 1 failed, 0 skipped
+// | package/name".
+func (pw *Writer) Line() string {
+	line := fmt.Sprintf("%d passed, %d failed, %d skipped", pw.passed, pw.failed, pw.skipped)
+	if pw.pkg != "" {
+		line += " | " + pw.pkg
+	}
+	return line
+}
+
+// Finish ends the progress line with a newline, if anything was written. It
+// returns the first error that occurred while writing the progress line.
+func (pw *Writer) Finish() error {
+	if pw.last != "" && pw.err == nil {
+		_, pw.err = io.WriteString(pw.w, "\n")
+	}
+	return pw.err
+}
+
+// update rewrites the progress line if it has changed. Since a carriage
+// return doesn't clear the line, a shorter line is padded with spaces to
+// overwrite the remainder of the previous line.
+func (pw *Writer) update() {
+	line := pw.Line()
+	if line == pw.last || pw.err != nil {
+		return
+	}
+	padding := ""
+	if n := len(pw.last) - len(line); n > 0 {
+		padding = strings.Repeat(" ", n)
+	}
+	_, pw.err = fmt.Fprintf(pw.w, "\r%s%s", line, padding)
+	pw.last = line
+}
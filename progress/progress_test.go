@@ -0,0 +1,55 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/parser/gotest"
+)
+
+func TestWriter(t *testing.T) {
+	input := strings.Join([]string{
+		"=== RUN   TestOne",
+		"--- PASS: TestOne (0.01s)",
+		"=== RUN   TestTwo",
+		"--- FAIL: TestTwo (0.02s)",
+		"FAIL",
+		"FAIL\tpackage/name/one\t0.030s",
+		"=== RUN   TestThree",
+		"--- SKIP: TestThree (0.00s)",
+		"ok  \tpackage/two\t0.001s",
+	}, "\n")
+
+	var buf bytes.Buffer
+	pw := NewWriter(&buf)
+	p := gotest.NewParser(gotest.EventHandler(pw.HandleEvent))
+	if _, err := p.Parse(strings.NewReader(input)); err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if err := pw.Finish(); err != nil {
+		t.Fatalf("Finish error: %v", err)
+	}
+
+	want := "\r1 passed, 0 failed, 0 skipped" +
+		"\r1 passed, 1 failed, 0 skipped" +
+		"\r1 passed, 1 failed, 0 skipped | package/name/one" +
+		"\r1 passed, 1 failed, 1 skipped | package/name/one" +
+		"\r1 passed, 1 failed, 1 skipped | package/two     " +
+		"\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Writer output incorrect\nwant: %q\ngot:  %q", want, got)
+	}
+}
+
+func TestWriterNoEvents(t *testing.T) {
+	var buf bytes.Buffer
+	pw := NewWriter(&buf)
+	pw.HandleEvent(gotest.Event{Type: "output", Data: "hello"})
+	if err := pw.Finish(); err != nil {
+		t.Fatalf("Finish error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Writer wrote %q, want no output", buf.String())
+	}
+}
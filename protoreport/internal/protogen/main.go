@@ -0,0 +1,329 @@
+// Protogen generates the Go bindings of the protoreport package from
+// report.proto.
+//
+// Only the subset of the protocol buffer language used by report.proto is
+// supported: proto3 messages and enums that aren't nested, and fields of type
+// string, bool, int64, double, enums and messages, which may be repeated
+// except for enums and scalars other than string. Each message is generated
+// as a struct with Marshal and Unmarshal methods, which use the wire format
+// helpers of the protoreport package.
+//
+// Usage:
+//
+//	go run ./internal/protogen -in report.proto -out report.pb.go
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	in  = flag.String("in", "report.proto", "read the schema from this file")
+	out = flag.String("out", "report.pb.go", "write the generated code to this file")
+)
+
+func main() {
+	flag.Parse()
+	if err := run(*in, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "protogen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out string) error {
+	f, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s, err := parse(f)
+	if err != nil {
+		return fmt.Errorf("%s: %v", in, err)
+	}
+	code, err := generate(s, in)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(out, code, 0644)
+}
+
+// schema is a parsed .proto file.
+type schema struct {
+	messages []*message
+	enums    []*enum
+}
+
+type message struct {
+	name   string
+	doc    []string
+	fields []field
+}
+
+type field struct {
+	name     string
+	typ      string
+	number   int
+	repeated bool
+	comment  string
+}
+
+type enum struct {
+	name   string
+	doc    []string
+	values []enumValue
+}
+
+type enumValue struct {
+	name   string
+	number int
+}
+
+var (
+	messageRe = regexp.MustCompile(`^message\s+(\w+)\s*\{$`)
+	enumRe    = regexp.MustCompile(`^enum\s+(\w+)\s*\{$`)
+	fieldRe   = regexp.MustCompile(`^(repeated\s+)?(\w+)\s+(\w+)\s*=\s*(\d+)\s*;\s*(?://\s*(.*))?$`)
+	valueRe   = regexp.MustCompile(`^(\w+)\s*=\s*(-?\d+)\s*;\s*(?://.*)?$`)
+)
+
+// parse parses the schema in r, which must declare one statement per line.
+// Comments directly preceding a message or enum are kept as its doc comment.
+func parse(r io.Reader) (*schema, error) {
+	var (
+		s    schema
+		doc  []string
+		msg  *message
+		enm  *enum
+		line int
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(text, "//"):
+			doc = append(doc, strings.TrimSpace(strings.TrimPrefix(text, "//")))
+			continue
+		case text == "":
+		case msg != nil || enm != nil:
+			if text == "}" {
+				msg, enm = nil, nil
+			} else if err := parseMember(msg, enm, text); err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+		case messageRe.MatchString(text):
+			msg = &message{name: messageRe.FindStringSubmatch(text)[1], doc: doc}
+			s.messages = append(s.messages, msg)
+		case enumRe.MatchString(text):
+			enm = &enum{name: enumRe.FindStringSubmatch(text)[1], doc: doc}
+			s.enums = append(s.enums, enm)
+		case text == `syntax = "proto3";`, strings.HasPrefix(text, "package "), strings.HasPrefix(text, "option "):
+		default:
+			return nil, fmt.Errorf("line %d: unsupported statement: %s", line, text)
+		}
+		doc = nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if msg != nil || enm != nil {
+		return nil, fmt.Errorf("unexpected end of file")
+	}
+	return &s, nil
+}
+
+// parseMember parses a statement in the body of either message msg or enum
+// enm.
+func parseMember(msg *message, enm *enum, text string) error {
+	if strings.HasPrefix(text, "reserved ") {
+		return nil
+	}
+	if enm != nil {
+		m := valueRe.FindStringSubmatch(text)
+		if m == nil {
+			return fmt.Errorf("invalid enum value: %s", text)
+		}
+		number, err := strconv.Atoi(m[2])
+		if err != nil {
+			return err
+		}
+		enm.values = append(enm.values, enumValue{m[1], number})
+		return nil
+	}
+	m := fieldRe.FindStringSubmatch(text)
+	if m == nil {
+		return fmt.Errorf("invalid field: %s", text)
+	}
+	number, err := strconv.Atoi(m[4])
+	if err != nil {
+		return err
+	}
+	msg.fields = append(msg.fields, field{name: m[3], typ: m[2], number: number, repeated: m[1] != "", comment: m[5]})
+	return nil
+}
+
+// generate returns the formatted Go code for schema s read from file src.
+func generate(s *schema, src string) ([]byte, error) {
+	kinds := make(map[string]string)
+	for _, m := range s.messages {
+		kinds[m.name] = "message"
+	}
+	for _, e := range s.enums {
+		kinds[e.name] = "enum"
+	}
+	for _, typ := range []string{"string", "bool", "int64", "double"} {
+		kinds[typ] = typ
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by protogen from %s. DO NOT EDIT.\n\n", src)
+	fmt.Fprintf(&buf, "package protoreport\n\n")
+	if usesType(s, "double") {
+		fmt.Fprintf(&buf, "import \"math\"\n\n")
+	}
+
+	for _, e := range s.enums {
+		writeDoc(&buf, e.doc)
+		fmt.Fprintf(&buf, "type %s int32\n\n", e.name)
+		fmt.Fprintf(&buf, "// Values of %s.\nconst (\n", e.name)
+		for _, v := range e.values {
+			fmt.Fprintf(&buf, "\t%s_%s %s = %d\n", e.name, v.name, e.name, v.number)
+		}
+		fmt.Fprintf(&buf, ")\n\n")
+	}
+
+	for _, m := range s.messages {
+		for _, f := range m.fields {
+			kind := kinds[f.typ]
+			if kind == "" {
+				return nil, fmt.Errorf("%s.%s: unknown type %s", m.name, f.name, f.typ)
+			}
+			if f.repeated && kind != "string" && kind != "message" {
+				return nil, fmt.Errorf("%s.%s: repeated %s fields are not supported", m.name, f.name, f.typ)
+			}
+		}
+		writeMessage(&buf, m, kinds)
+	}
+	return format.Source(buf.Bytes())
+}
+
+func usesType(s *schema, typ string) bool {
+	for _, m := range s.messages {
+		for _, f := range m.fields {
+			if f.typ == typ {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func writeDoc(w io.Writer, doc []string) {
+	for _, line := range doc {
+		fmt.Fprintf(w, "// %s\n", line)
+	}
+}
+
+// goTypes are the Go types of the scalar protocol buffer types.
+var goTypes = map[string]string{
+	"string": "string",
+	"bool":   "bool",
+	"int64":  "int64",
+	"double": "float64",
+}
+
+func writeMessage(w io.Writer, m *message, kinds map[string]string) {
+	writeDoc(w, m.doc)
+	fmt.Fprintf(w, "type %s struct {\n", m.name)
+	for _, f := range m.fields {
+		typ := goTypes[f.typ]
+		switch kinds[f.typ] {
+		case "message":
+			typ = "*" + f.typ
+		case "enum":
+			typ = f.typ
+		}
+		if f.repeated {
+			typ = "[]" + typ
+		}
+		fmt.Fprintf(w, "\t%s %s", goName(f.name), typ)
+		if f.comment != "" {
+			fmt.Fprintf(w, " // %s", f.comment)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "}\n\n")
+
+	fmt.Fprintf(w, "// Marshal returns the protocol buffer encoding of m.\n")
+	fmt.Fprintf(w, "func (m *%s) Marshal() []byte {\n\tvar e encoder\n", m.name)
+	for _, f := range m.fields {
+		name := "m." + goName(f.name)
+		switch kind := kinds[f.typ]; {
+		case kind == "message" && f.repeated:
+			fmt.Fprintf(w, "\tfor _, v := range %s {\n\t\te.message(%d, v.Marshal())\n\t}\n", name, f.number)
+		case kind == "message":
+			fmt.Fprintf(w, "\tif %s != nil {\n\t\te.message(%d, %s.Marshal())\n\t}\n", name, f.number, name)
+		case kind == "string" && f.repeated:
+			fmt.Fprintf(w, "\tfor _, v := range %s {\n\t\te.repeatedString(%d, v)\n\t}\n", name, f.number)
+		case kind == "enum":
+			fmt.Fprintf(w, "\te.int64(%d, int64(%s))\n", f.number, name)
+		default:
+			fmt.Fprintf(w, "\te.%s(%d, %s)\n", kind, f.number, name)
+		}
+	}
+	fmt.Fprintf(w, "\treturn e.buf\n}\n\n")
+
+	fmt.Fprintf(w, "// Unmarshal sets the fields of m that are encoded in data. Unknown fields\n// are ignored.\n")
+	fmt.Fprintf(w, "func (m *%s) Unmarshal(data []byte) error {\n", m.name)
+	fmt.Fprintf(w, "\treturn decode(data, func(field int, v value) error {\n\t\tswitch field {\n")
+	for _, f := range m.fields {
+		name := "m." + goName(f.name)
+		fmt.Fprintf(w, "\t\tcase %d:\n", f.number)
+		switch kinds[f.typ] {
+		case "message":
+			fmt.Fprintf(w, "\t\t\tx := &%s{}\n\t\t\tif err := x.Unmarshal(v.bytes); err != nil {\n\t\t\t\treturn err\n\t\t\t}\n", f.typ)
+			if f.repeated {
+				fmt.Fprintf(w, "\t\t\t%s = append(%s, x)\n", name, name)
+			} else {
+				fmt.Fprintf(w, "\t\t\t%s = x\n", name)
+			}
+		case "string":
+			if f.repeated {
+				fmt.Fprintf(w, "\t\t\t%s = append(%s, string(v.bytes))\n", name, name)
+			} else {
+				fmt.Fprintf(w, "\t\t\t%s = string(v.bytes)\n", name)
+			}
+		case "bool":
+			fmt.Fprintf(w, "\t\t\t%s = v.varint != 0\n", name)
+		case "int64":
+			fmt.Fprintf(w, "\t\t\t%s = int64(v.varint)\n", name)
+		case "double":
+			fmt.Fprintf(w, "\t\t\t%s = math.Float64frombits(v.varint)\n", name)
+		case "enum":
+			fmt.Fprintf(w, "\t\t\t%s = %s(v.varint)\n", name, f.typ)
+		}
+	}
+	fmt.Fprintf(w, "\t\t}\n\t\treturn nil\n\t})\n}\n\n")
+}
+
+// goName returns the Go name of the field with the given snake case name,
+// e.g. ShardIndex for shard_index.
+func goName(name string) string {
+	parts := strings.Split(name, "_")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerated(t *testing.T) {
+	f, err := os.Open("../../report.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	s, err := parse(f)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	got, err := generate(s, "report.proto")
+	if err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	want, err := ioutil.ReadFile("../../report.pb.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("report.pb.go is out of date, run go generate in the protoreport directory")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, input := range []string{
+		"message A {\n  string a;\n}",
+		"message A {\n  string a = 1;",
+		"service S {\n}",
+		"enum E {\n  A;\n}",
+	} {
+		if _, err := parse(strings.NewReader(input)); err == nil {
+			t.Errorf("parse(%q) did not return an error", input)
+		}
+	}
+}
+
+func TestGenerateUnsupported(t *testing.T) {
+	for _, input := range []string{
+		"message A {\n  uint32 a = 1;\n}",
+		"message A {\n  repeated int64 a = 1;\n}",
+	} {
+		s, err := parse(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("parse(%q) failed: %v", input, err)
+		}
+		if _, err := generate(s, "test.proto"); err == nil {
+			t.Errorf("generate(%q) did not return an error", input)
+		}
+	}
+}
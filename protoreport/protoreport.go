@@ -0,0 +1,325 @@
+// Package protoreport writes and reads gtr.Reports in the protocol buffer
+// binary format.
+//
+// The schema is defined in report.proto. Each gtr type maps to the message of
+// the same name. Durations are stored as nanoseconds and timestamps as
+// nanoseconds since the Unix epoch, where 0 means the timestamp is unknown;
+// the time zones of timestamps are not stored. The Data field of gtr.Test is
+// not stored. Results that are not defined in report.proto, e.g. those
+// written by a newer version, are read as gtr.Unknown.
+//
+// The Go bindings of the messages in report.pb.go are generated from
+// report.proto by internal/protogen, which uses the wire format helpers of
+// this package instead of the protobuf runtime to avoid depending on it. Run
+// go generate after changing report.proto. Bindings for other languages can be
+// generated from report.proto using protoc.
+package protoreport
+
+//go:generate go run ./internal/protogen -in report.proto -out report.pb.go
+
+import (
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// Marshal returns the protocol buffer encoding of report r.
+func Marshal(r gtr.Report) ([]byte, error) {
+	m := &Report{
+		Hostname:   r.Hostname,
+		ShardIndex: int64(r.ShardIndex),
+		ShardCount: int64(r.ShardCount),
+		Aborted:    r.Aborted,
+	}
+	for _, pkg := range r.Packages {
+		m.Packages = append(m.Packages, fromPackage(pkg))
+	}
+	if r.RunMeta != nil {
+		m.RunMeta = &RunMeta{ExitCode: int64(r.RunMeta.ExitCode), Signal: r.RunMeta.Signal}
+	}
+	return m.Marshal(), nil
+}
+
+// Unmarshal parses the protocol buffer encoded data and returns the report it
+// contains. Unknown fields are ignored.
+func Unmarshal(data []byte) (gtr.Report, error) {
+	var m Report
+	if err := m.Unmarshal(data); err != nil {
+		return gtr.Report{}, err
+	}
+	report := gtr.Report{
+		Hostname:   m.Hostname,
+		ShardIndex: int(m.ShardIndex),
+		ShardCount: int(m.ShardCount),
+		Aborted:    m.Aborted,
+	}
+	for _, pkg := range m.Packages {
+		report.Packages = append(report.Packages, toPackage(pkg))
+	}
+	if m.RunMeta != nil {
+		report.RunMeta = &gtr.RunMeta{ExitCode: int(m.RunMeta.ExitCode), Signal: m.RunMeta.Signal}
+	}
+	return report, nil
+}
+
+func fromPackage(pkg gtr.Package) *Package {
+	m := &Package{
+		Name:               pkg.Name,
+		TimestampUnixNano:  unixNano(pkg.Timestamp),
+		DurationNanos:      int64(pkg.Duration),
+		Coverage:           pkg.Coverage,
+		Output:             pkg.Output,
+		BuildDurationNanos: int64(pkg.BuildDuration),
+		StartTimeUnixNano:  unixNano(pkg.StartTime),
+		EndTimeUnixNano:    unixNano(pkg.EndTime),
+		MaxParallel:        int64(pkg.MaxParallel),
+		Hostname:           pkg.Hostname,
+		ShardIndex:         int64(pkg.ShardIndex),
+		ShardCount:         int64(pkg.ShardCount),
+		NoTestFiles:        pkg.NoTestFiles,
+		Cached:             pkg.Cached,
+		Stderr:             pkg.Stderr,
+		Properties:         fromProperties(pkg.Properties),
+		Attachments:        fromAttachments(pkg.Attachments),
+	}
+	for _, test := range pkg.Tests {
+		m.Tests = append(m.Tests, fromTest(test))
+	}
+	if !isZeroError(pkg.BuildError) {
+		m.BuildError = fromError(pkg.BuildError)
+	}
+	if !isZeroError(pkg.RunError) {
+		m.RunError = fromError(pkg.RunError)
+	}
+	return m
+}
+
+func toPackage(m *Package) gtr.Package {
+	pkg := gtr.Package{
+		Name:          m.Name,
+		Timestamp:     fromUnixNano(m.TimestampUnixNano),
+		Duration:      time.Duration(m.DurationNanos),
+		Coverage:      m.Coverage,
+		Output:        m.Output,
+		BuildDuration: time.Duration(m.BuildDurationNanos),
+		StartTime:     fromUnixNano(m.StartTimeUnixNano),
+		EndTime:       fromUnixNano(m.EndTimeUnixNano),
+		MaxParallel:   int(m.MaxParallel),
+		Hostname:      m.Hostname,
+		ShardIndex:    int(m.ShardIndex),
+		ShardCount:    int(m.ShardCount),
+		NoTestFiles:   m.NoTestFiles,
+		Cached:        m.Cached,
+		Stderr:        m.Stderr,
+		Properties:    toProperties(m.Properties),
+		Attachments:   toAttachments(m.Attachments),
+	}
+	for _, test := range m.Tests {
+		pkg.Tests = append(pkg.Tests, toTest(test))
+	}
+	if m.BuildError != nil {
+		pkg.BuildError = toError(m.BuildError)
+	}
+	if m.RunError != nil {
+		pkg.RunError = toError(m.RunError)
+	}
+	return pkg
+}
+
+func fromProperties(props []gtr.Property) []*Property {
+	var m []*Property
+	for _, prop := range props {
+		m = append(m, &Property{Name: prop.Name, Value: prop.Value})
+	}
+	return m
+}
+
+func toProperties(m []*Property) []gtr.Property {
+	var props []gtr.Property
+	for _, prop := range m {
+		props = append(props, gtr.Property{Name: prop.Name, Value: prop.Value})
+	}
+	return props
+}
+
+func fromAttachments(attachments []gtr.Attachment) []*Attachment {
+	var m []*Attachment
+	for _, a := range attachments {
+		m = append(m, &Attachment{Name: a.Name, Path: a.Path, Mime: a.MIME})
+	}
+	return m
+}
+
+func toAttachments(m []*Attachment) []gtr.Attachment {
+	var attachments []gtr.Attachment
+	for _, a := range m {
+		attachments = append(attachments, gtr.Attachment{Name: a.Name, Path: a.Path, MIME: a.Mime})
+	}
+	return attachments
+}
+
+// fromResult returns the result to store for result r. Results registered
+// using gtr.RegisterResult are stored as their base result, since their values
+// depend on the order in which they were registered.
+func fromResult(r gtr.Result) Result {
+	if r == gtr.Inconclusive {
+		return Result_RESULT_INCONCLUSIVE
+	}
+	return Result(r.Base())
+}
+
+// toResult returns the result stored as r, or gtr.Unknown if r is not one of
+// the results defined in report.proto.
+func toResult(r Result) gtr.Result {
+	switch r {
+	case Result_RESULT_PASS:
+		return gtr.Pass
+	case Result_RESULT_FAIL:
+		return gtr.Fail
+	case Result_RESULT_SKIP:
+		return gtr.Skip
+	case Result_RESULT_FLAKY:
+		return gtr.Flaky
+	case Result_RESULT_INCONCLUSIVE:
+		return gtr.Inconclusive
+	}
+	return gtr.Unknown
+}
+
+func fromTest(test gtr.Test) *Test {
+	m := &Test{
+		Id:                int64(test.ID),
+		Name:              test.Name,
+		DurationNanos:     int64(test.Duration),
+		Result:            fromResult(test.Result),
+		Level:             int64(test.Level),
+		Output:            test.Output,
+		Properties:        fromProperties(test.Properties),
+		StartTimeUnixNano: unixNano(test.StartTime),
+		EndTimeUnixNano:   unixNano(test.EndTime),
+		SkipMessage:       test.SkipMessage,
+		FailureMessage:    test.FailureMessage,
+		FailureType:       test.FailureType,
+		Attachments:       fromAttachments(test.Attachments),
+		RunDurationNanos:  int64(test.RunDuration),
+		WallDurationNanos: int64(test.WallDuration),
+		Kind:              string(test.Kind),
+		File:              test.File,
+		Line:              int64(test.Line),
+		Stderr:            test.Stderr,
+	}
+	if test.Panic != nil {
+		m.Panic = fromPanic(*test.Panic)
+	}
+	for _, a := range test.Attempts {
+		m.Attempts = append(m.Attempts, &TestAttempt{
+			Result:        fromResult(a.Result),
+			DurationNanos: int64(a.Duration),
+			Output:        a.Output,
+		})
+	}
+	return m
+}
+
+func toTest(m *Test) gtr.Test {
+	test := gtr.Test{
+		ID:             int(m.Id),
+		Name:           m.Name,
+		Duration:       time.Duration(m.DurationNanos),
+		Result:         toResult(m.Result),
+		Level:          int(m.Level),
+		Output:         m.Output,
+		Properties:     toProperties(m.Properties),
+		StartTime:      fromUnixNano(m.StartTimeUnixNano),
+		EndTime:        fromUnixNano(m.EndTimeUnixNano),
+		SkipMessage:    m.SkipMessage,
+		FailureMessage: m.FailureMessage,
+		FailureType:    m.FailureType,
+		Attachments:    toAttachments(m.Attachments),
+		RunDuration:    time.Duration(m.RunDurationNanos),
+		WallDuration:   time.Duration(m.WallDurationNanos),
+		Kind:           gtr.TestKind(m.Kind),
+		File:           m.File,
+		Line:           int(m.Line),
+		Stderr:         m.Stderr,
+	}
+	if m.Panic != nil {
+		p := toPanic(m.Panic)
+		test.Panic = &p
+	}
+	for _, a := range m.Attempts {
+		test.Attempts = append(test.Attempts, gtr.TestAttempt{
+			Result:   toResult(a.Result),
+			Duration: time.Duration(a.DurationNanos),
+			Output:   a.Output,
+		})
+	}
+	return test
+}
+
+func fromError(e gtr.Error) *Error {
+	m := &Error{
+		Id:            int64(e.ID),
+		Name:          e.Name,
+		DurationNanos: int64(e.Duration),
+		Cause:         e.Cause,
+		Output:        e.Output,
+		Kind:          e.Kind,
+	}
+	if e.Panic != nil {
+		m.Panic = fromPanic(*e.Panic)
+	}
+	for _, d := range e.Diagnostics {
+		m.Diagnostics = append(m.Diagnostics, &Diagnostic{File: d.File, Line: int64(d.Line), Column: int64(d.Column), Message: d.Message})
+	}
+	return m
+}
+
+func toError(m *Error) gtr.Error {
+	e := gtr.Error{
+		ID:       int(m.Id),
+		Name:     m.Name,
+		Duration: time.Duration(m.DurationNanos),
+		Cause:    m.Cause,
+		Output:   m.Output,
+		Kind:     m.Kind,
+	}
+	if m.Panic != nil {
+		p := toPanic(m.Panic)
+		e.Panic = &p
+	}
+	for _, d := range m.Diagnostics {
+		e.Diagnostics = append(e.Diagnostics, gtr.Diagnostic{File: d.File, Line: int(d.Line), Column: int(d.Column), Message: d.Message})
+	}
+	return e
+}
+
+func fromPanic(p gtr.PanicInfo) *PanicInfo {
+	return &PanicInfo{Message: p.Message, Test: p.Test, Stack: p.Stack}
+}
+
+func toPanic(m *PanicInfo) gtr.PanicInfo {
+	return gtr.PanicInfo{Message: m.Message, Test: m.Test, Stack: m.Stack}
+}
+
+func isZeroError(e gtr.Error) bool {
+	return e.ID == 0 && e.Name == "" && e.Kind == "" && e.Duration == 0 && e.Cause == "" && len(e.Output) == 0 && e.Panic == nil && len(e.Diagnostics) == 0
+}
+
+// unixNano returns t as nanoseconds since the Unix epoch, or 0 if t is the
+// zero time.
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// fromUnixNano returns the time of the given nanoseconds since the Unix epoch,
+// or the zero time if nanos is 0.
+func fromUnixNano(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
@@ -0,0 +1,97 @@
+package protoreport
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/gtrjson"
+	"github.com/jstemmer/go-junit-report/v2/gtrtest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarshal(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{{Name: "a"}}}
+	want := []byte{0x0a, 0x03, 0x0a, 0x01, 'a'}
+
+	got, err := Marshal(report)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Marshal result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	want := gtrtest.FullReport()
+
+	data, err := Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	got, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unmarshal result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestJSONParity(t *testing.T) {
+	report := gtrtest.FullReport()
+
+	var buf bytes.Buffer
+	if err := gtrjson.Write(&buf, report); err != nil {
+		t.Fatalf("gtrjson.Write failed: %v", err)
+	}
+	want, err := gtrjson.Read(&buf)
+	if err != nil {
+		t.Fatalf("gtrjson.Read failed: %v", err)
+	}
+
+	data, err := Marshal(report)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	got, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unmarshal result differs from the JSON round trip, diff (-json +proto):\n%s\n", diff)
+	}
+}
+
+func TestUnmarshalTruncated(t *testing.T) {
+	data, err := Marshal(gtr.Report{Packages: []gtr.Package{{Name: "package/name"}}})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	if _, err := Unmarshal(data[:len(data)-1]); err == nil {
+		t.Errorf("Unmarshal of truncated data did not return an error")
+	}
+}
+
+func TestUnmarshalUnknownResult(t *testing.T) {
+	for _, result := range []int64{5, 6, 8, 1000, -1} {
+		var test, pkg, report encoder
+		test.string(2, "TestNewer")
+		test.int64(4, result)
+		pkg.string(1, "package/name")
+		pkg.message(7, test.buf)
+		report.message(1, pkg.buf)
+
+		got, err := Unmarshal(report.buf)
+		if err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if r := got.Packages[0].Tests[0].Result; r != gtr.Unknown {
+			t.Errorf("Unmarshal of result %d = %v, want %v", result, r, gtr.Unknown)
+		}
+	}
+}
@@ -0,0 +1,598 @@
+// Code generated by protogen from report.proto. DO NOT EDIT.
+
+package protoreport
+
+import "math"
+
+// Result corresponds to gtr.Result.
+type Result int32
+
+// Values of Result.
+const (
+	Result_RESULT_UNKNOWN      Result = 0
+	Result_RESULT_PASS         Result = 1
+	Result_RESULT_FAIL         Result = 2
+	Result_RESULT_SKIP         Result = 3
+	Result_RESULT_FLAKY        Result = 4
+	Result_RESULT_INCONCLUSIVE Result = 7
+)
+
+// Report corresponds to gtr.Report.
+type Report struct {
+	Packages   []*Package
+	Hostname   string   // empty if unknown
+	ShardIndex int64    // zero-based
+	ShardCount int64    // 0 if the shard is unknown
+	RunMeta    *RunMeta // not set if unknown
+	Aborted    bool     // the run stopped early after the first failed test
+}
+
+// Marshal returns the protocol buffer encoding of m.
+func (m *Report) Marshal() []byte {
+	var e encoder
+	for _, v := range m.Packages {
+		e.message(1, v.Marshal())
+	}
+	e.string(2, m.Hostname)
+	e.int64(3, m.ShardIndex)
+	e.int64(4, m.ShardCount)
+	if m.RunMeta != nil {
+		e.message(5, m.RunMeta.Marshal())
+	}
+	e.bool(6, m.Aborted)
+	return e.buf
+}
+
+// Unmarshal sets the fields of m that are encoded in data. Unknown fields
+// are ignored.
+func (m *Report) Unmarshal(data []byte) error {
+	return decode(data, func(field int, v value) error {
+		switch field {
+		case 1:
+			x := &Package{}
+			if err := x.Unmarshal(v.bytes); err != nil {
+				return err
+			}
+			m.Packages = append(m.Packages, x)
+		case 2:
+			m.Hostname = string(v.bytes)
+		case 3:
+			m.ShardIndex = int64(v.varint)
+		case 4:
+			m.ShardCount = int64(v.varint)
+		case 5:
+			x := &RunMeta{}
+			if err := x.Unmarshal(v.bytes); err != nil {
+				return err
+			}
+			m.RunMeta = x
+		case 6:
+			m.Aborted = v.varint != 0
+		}
+		return nil
+	})
+}
+
+// RunMeta corresponds to gtr.RunMeta.
+type RunMeta struct {
+	ExitCode int64 // -1 if terminated by a signal
+	Signal   string
+}
+
+// Marshal returns the protocol buffer encoding of m.
+func (m *RunMeta) Marshal() []byte {
+	var e encoder
+	e.int64(1, m.ExitCode)
+	e.string(2, m.Signal)
+	return e.buf
+}
+
+// Unmarshal sets the fields of m that are encoded in data. Unknown fields
+// are ignored.
+func (m *RunMeta) Unmarshal(data []byte) error {
+	return decode(data, func(field int, v value) error {
+		switch field {
+		case 1:
+			m.ExitCode = int64(v.varint)
+		case 2:
+			m.Signal = string(v.bytes)
+		}
+		return nil
+	})
+}
+
+// Package corresponds to gtr.Package.
+type Package struct {
+	Name               string
+	TimestampUnixNano  int64 // 0 if the timestamp is unknown
+	DurationNanos      int64
+	Coverage           float64
+	Output             []string
+	Properties         []*Property
+	Tests              []*Test
+	BuildError         *Error
+	RunError           *Error
+	BuildDurationNanos int64
+	StartTimeUnixNano  int64 // 0 if the start time is unknown
+	EndTimeUnixNano    int64 // 0 if the end time is unknown
+	Attachments        []*Attachment
+	MaxParallel        int64  // 0 if unknown
+	Hostname           string // empty if unknown
+	ShardIndex         int64  // zero-based
+	ShardCount         int64  // 0 if the shard is unknown
+	NoTestFiles        bool
+	Cached             bool
+	Stderr             []string
+}
+
+// Marshal returns the protocol buffer encoding of m.
+func (m *Package) Marshal() []byte {
+	var e encoder
+	e.string(1, m.Name)
+	e.int64(2, m.TimestampUnixNano)
+	e.int64(3, m.DurationNanos)
+	e.double(4, m.Coverage)
+	for _, v := range m.Output {
+		e.repeatedString(5, v)
+	}
+	for _, v := range m.Properties {
+		e.message(6, v.Marshal())
+	}
+	for _, v := range m.Tests {
+		e.message(7, v.Marshal())
+	}
+	if m.BuildError != nil {
+		e.message(8, m.BuildError.Marshal())
+	}
+	if m.RunError != nil {
+		e.message(9, m.RunError.Marshal())
+	}
+	e.int64(10, m.BuildDurationNanos)
+	e.int64(11, m.StartTimeUnixNano)
+	e.int64(12, m.EndTimeUnixNano)
+	for _, v := range m.Attachments {
+		e.message(13, v.Marshal())
+	}
+	e.int64(14, m.MaxParallel)
+	e.string(15, m.Hostname)
+	e.int64(16, m.ShardIndex)
+	e.int64(17, m.ShardCount)
+	e.bool(18, m.NoTestFiles)
+	e.bool(19, m.Cached)
+	for _, v := range m.Stderr {
+		e.repeatedString(20, v)
+	}
+	return e.buf
+}
+
+// Unmarshal sets the fields of m that are encoded in data. Unknown fields
+// are ignored.
+func (m *Package) Unmarshal(data []byte) error {
+	return decode(data, func(field int, v value) error {
+		switch field {
+		case 1:
+			m.Name = string(v.bytes)
+		case 2:
+			m.TimestampUnixNano = int64(v.varint)
+		case 3:
+			m.DurationNanos = int64(v.varint)
+		case 4:
+			m.Coverage = math.Float64frombits(v.varint)
+		case 5:
+			m.Output = append(m.Output, string(v.bytes))
+		case 6:
+			x := &Property{}
+			if err := x.Unmarshal(v.bytes); err != nil {
+				return err
+			}
+			m.Properties = append(m.Properties, x)
+		case 7:
+			x := &Test{}
+			if err := x.Unmarshal(v.bytes); err != nil {
+				return err
+			}
+			m.Tests = append(m.Tests, x)
+		case 8:
+			x := &Error{}
+			if err := x.Unmarshal(v.bytes); err != nil {
+				return err
+			}
+			m.BuildError = x
+		case 9:
+			x := &Error{}
+			if err := x.Unmarshal(v.bytes); err != nil {
+				return err
+			}
+			m.RunError = x
+		case 10:
+			m.BuildDurationNanos = int64(v.varint)
+		case 11:
+			m.StartTimeUnixNano = int64(v.varint)
+		case 12:
+			m.EndTimeUnixNano = int64(v.varint)
+		case 13:
+			x := &Attachment{}
+			if err := x.Unmarshal(v.bytes); err != nil {
+				return err
+			}
+			m.Attachments = append(m.Attachments, x)
+		case 14:
+			m.MaxParallel = int64(v.varint)
+		case 15:
+			m.Hostname = string(v.bytes)
+		case 16:
+			m.ShardIndex = int64(v.varint)
+		case 17:
+			m.ShardCount = int64(v.varint)
+		case 18:
+			m.NoTestFiles = v.varint != 0
+		case 19:
+			m.Cached = v.varint != 0
+		case 20:
+			m.Stderr = append(m.Stderr, string(v.bytes))
+		}
+		return nil
+	})
+}
+
+// Property corresponds to gtr.Property.
+type Property struct {
+	Name  string
+	Value string
+}
+
+// Marshal returns the protocol buffer encoding of m.
+func (m *Property) Marshal() []byte {
+	var e encoder
+	e.string(1, m.Name)
+	e.string(2, m.Value)
+	return e.buf
+}
+
+// Unmarshal sets the fields of m that are encoded in data. Unknown fields
+// are ignored.
+func (m *Property) Unmarshal(data []byte) error {
+	return decode(data, func(field int, v value) error {
+		switch field {
+		case 1:
+			m.Name = string(v.bytes)
+		case 2:
+			m.Value = string(v.bytes)
+		}
+		return nil
+	})
+}
+
+// Attachment corresponds to gtr.Attachment.
+type Attachment struct {
+	Name string
+	Path string
+	Mime string
+}
+
+// Marshal returns the protocol buffer encoding of m.
+func (m *Attachment) Marshal() []byte {
+	var e encoder
+	e.string(1, m.Name)
+	e.string(2, m.Path)
+	e.string(3, m.Mime)
+	return e.buf
+}
+
+// Unmarshal sets the fields of m that are encoded in data. Unknown fields
+// are ignored.
+func (m *Attachment) Unmarshal(data []byte) error {
+	return decode(data, func(field int, v value) error {
+		switch field {
+		case 1:
+			m.Name = string(v.bytes)
+		case 2:
+			m.Path = string(v.bytes)
+		case 3:
+			m.Mime = string(v.bytes)
+		}
+		return nil
+	})
+}
+
+// Test corresponds to gtr.Test. The Data field of gtr.Test is not included.
+type Test struct {
+	Id                int64
+	Name              string
+	DurationNanos     int64
+	Result            Result
+	Level             int64
+	Output            []string
+	Properties        []*Property
+	StartTimeUnixNano int64 // 0 if the start time is unknown
+	EndTimeUnixNano   int64 // 0 if the end time is unknown
+	Panic             *PanicInfo
+	SkipMessage       string
+	FailureMessage    string
+	FailureType       string
+	Attachments       []*Attachment
+	RunDurationNanos  int64  // 0 if unknown
+	WallDurationNanos int64  // 0 if unknown
+	Kind              string // empty for regular tests, "example" for examples
+	File              string // empty if unknown
+	Line              int64  // 0 if unknown
+	Stderr            []string
+	Attempts          []*TestAttempt
+}
+
+// Marshal returns the protocol buffer encoding of m.
+func (m *Test) Marshal() []byte {
+	var e encoder
+	e.int64(1, m.Id)
+	e.string(2, m.Name)
+	e.int64(3, m.DurationNanos)
+	e.int64(4, int64(m.Result))
+	e.int64(5, m.Level)
+	for _, v := range m.Output {
+		e.repeatedString(6, v)
+	}
+	for _, v := range m.Properties {
+		e.message(7, v.Marshal())
+	}
+	e.int64(8, m.StartTimeUnixNano)
+	e.int64(9, m.EndTimeUnixNano)
+	if m.Panic != nil {
+		e.message(10, m.Panic.Marshal())
+	}
+	e.string(11, m.SkipMessage)
+	e.string(12, m.FailureMessage)
+	e.string(13, m.FailureType)
+	for _, v := range m.Attachments {
+		e.message(14, v.Marshal())
+	}
+	e.int64(15, m.RunDurationNanos)
+	e.int64(16, m.WallDurationNanos)
+	e.string(17, m.Kind)
+	e.string(18, m.File)
+	e.int64(19, m.Line)
+	for _, v := range m.Stderr {
+		e.repeatedString(20, v)
+	}
+	for _, v := range m.Attempts {
+		e.message(21, v.Marshal())
+	}
+	return e.buf
+}
+
+// Unmarshal sets the fields of m that are encoded in data. Unknown fields
+// are ignored.
+func (m *Test) Unmarshal(data []byte) error {
+	return decode(data, func(field int, v value) error {
+		switch field {
+		case 1:
+			m.Id = int64(v.varint)
+		case 2:
+			m.Name = string(v.bytes)
+		case 3:
+			m.DurationNanos = int64(v.varint)
+		case 4:
+			m.Result = Result(v.varint)
+		case 5:
+			m.Level = int64(v.varint)
+		case 6:
+			m.Output = append(m.Output, string(v.bytes))
+		case 7:
+			x := &Property{}
+			if err := x.Unmarshal(v.bytes); err != nil {
+				return err
+			}
+			m.Properties = append(m.Properties, x)
+		case 8:
+			m.StartTimeUnixNano = int64(v.varint)
+		case 9:
+			m.EndTimeUnixNano = int64(v.varint)
+		case 10:
+			x := &PanicInfo{}
+			if err := x.Unmarshal(v.bytes); err != nil {
+				return err
+			}
+			m.Panic = x
+		case 11:
+			m.SkipMessage = string(v.bytes)
+		case 12:
+			m.FailureMessage = string(v.bytes)
+		case 13:
+			m.FailureType = string(v.bytes)
+		case 14:
+			x := &Attachment{}
+			if err := x.Unmarshal(v.bytes); err != nil {
+				return err
+			}
+			m.Attachments = append(m.Attachments, x)
+		case 15:
+			m.RunDurationNanos = int64(v.varint)
+		case 16:
+			m.WallDurationNanos = int64(v.varint)
+		case 17:
+			m.Kind = string(v.bytes)
+		case 18:
+			m.File = string(v.bytes)
+		case 19:
+			m.Line = int64(v.varint)
+		case 20:
+			m.Stderr = append(m.Stderr, string(v.bytes))
+		case 21:
+			x := &TestAttempt{}
+			if err := x.Unmarshal(v.bytes); err != nil {
+				return err
+			}
+			m.Attempts = append(m.Attempts, x)
+		}
+		return nil
+	})
+}
+
+// TestAttempt corresponds to gtr.TestAttempt.
+type TestAttempt struct {
+	Result        Result
+	DurationNanos int64
+	Output        []string
+}
+
+// Marshal returns the protocol buffer encoding of m.
+func (m *TestAttempt) Marshal() []byte {
+	var e encoder
+	e.int64(1, int64(m.Result))
+	e.int64(2, m.DurationNanos)
+	for _, v := range m.Output {
+		e.repeatedString(3, v)
+	}
+	return e.buf
+}
+
+// Unmarshal sets the fields of m that are encoded in data. Unknown fields
+// are ignored.
+func (m *TestAttempt) Unmarshal(data []byte) error {
+	return decode(data, func(field int, v value) error {
+		switch field {
+		case 1:
+			m.Result = Result(v.varint)
+		case 2:
+			m.DurationNanos = int64(v.varint)
+		case 3:
+			m.Output = append(m.Output, string(v.bytes))
+		}
+		return nil
+	})
+}
+
+// Error corresponds to gtr.Error.
+type Error struct {
+	Id            int64
+	Name          string
+	DurationNanos int64
+	Cause         string
+	Output        []string
+	Kind          string
+	Panic         *PanicInfo
+	Diagnostics   []*Diagnostic
+}
+
+// Marshal returns the protocol buffer encoding of m.
+func (m *Error) Marshal() []byte {
+	var e encoder
+	e.int64(1, m.Id)
+	e.string(2, m.Name)
+	e.int64(3, m.DurationNanos)
+	e.string(4, m.Cause)
+	for _, v := range m.Output {
+		e.repeatedString(5, v)
+	}
+	e.string(6, m.Kind)
+	if m.Panic != nil {
+		e.message(7, m.Panic.Marshal())
+	}
+	for _, v := range m.Diagnostics {
+		e.message(8, v.Marshal())
+	}
+	return e.buf
+}
+
+// Unmarshal sets the fields of m that are encoded in data. Unknown fields
+// are ignored.
+func (m *Error) Unmarshal(data []byte) error {
+	return decode(data, func(field int, v value) error {
+		switch field {
+		case 1:
+			m.Id = int64(v.varint)
+		case 2:
+			m.Name = string(v.bytes)
+		case 3:
+			m.DurationNanos = int64(v.varint)
+		case 4:
+			m.Cause = string(v.bytes)
+		case 5:
+			m.Output = append(m.Output, string(v.bytes))
+		case 6:
+			m.Kind = string(v.bytes)
+		case 7:
+			x := &PanicInfo{}
+			if err := x.Unmarshal(v.bytes); err != nil {
+				return err
+			}
+			m.Panic = x
+		case 8:
+			x := &Diagnostic{}
+			if err := x.Unmarshal(v.bytes); err != nil {
+				return err
+			}
+			m.Diagnostics = append(m.Diagnostics, x)
+		}
+		return nil
+	})
+}
+
+// Diagnostic corresponds to gtr.Diagnostic.
+type Diagnostic struct {
+	File    string
+	Line    int64
+	Column  int64 // 0 if unknown
+	Message string
+}
+
+// Marshal returns the protocol buffer encoding of m.
+func (m *Diagnostic) Marshal() []byte {
+	var e encoder
+	e.string(1, m.File)
+	e.int64(2, m.Line)
+	e.int64(3, m.Column)
+	e.string(4, m.Message)
+	return e.buf
+}
+
+// Unmarshal sets the fields of m that are encoded in data. Unknown fields
+// are ignored.
+func (m *Diagnostic) Unmarshal(data []byte) error {
+	return decode(data, func(field int, v value) error {
+		switch field {
+		case 1:
+			m.File = string(v.bytes)
+		case 2:
+			m.Line = int64(v.varint)
+		case 3:
+			m.Column = int64(v.varint)
+		case 4:
+			m.Message = string(v.bytes)
+		}
+		return nil
+	})
+}
+
+// PanicInfo corresponds to gtr.PanicInfo.
+type PanicInfo struct {
+	Message string
+	Test    string
+	Stack   []string
+}
+
+// Marshal returns the protocol buffer encoding of m.
+func (m *PanicInfo) Marshal() []byte {
+	var e encoder
+	e.string(1, m.Message)
+	e.string(2, m.Test)
+	for _, v := range m.Stack {
+		e.repeatedString(3, v)
+	}
+	return e.buf
+}
+
+// Unmarshal sets the fields of m that are encoded in data. Unknown fields
+// are ignored.
+func (m *PanicInfo) Unmarshal(data []byte) error {
+	return decode(data, func(field int, v value) error {
+		switch field {
+		case 1:
+			m.Message = string(v.bytes)
+		case 2:
+			m.Test = string(v.bytes)
+		case 3:
+			m.Stack = append(m.Stack, string(v.bytes))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,130 @@
+package protoreport
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Protocol buffer wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// encoder writes protocol buffer encoded fields. Fields with default values
+// are not written, except for repeated fields.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	e.buf = append(e.buf, b[:n]...)
+}
+
+func (e *encoder) tag(field, wireType int) {
+	e.uvarint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (e *encoder) int64(field int, v int64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.uvarint(uint64(v))
+}
+
+func (e *encoder) bool(field int, v bool) {
+	if v {
+		e.int64(field, 1)
+	}
+}
+
+func (e *encoder) double(field int, v float64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireFixed64)
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(v))
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *encoder) string(field int, s string) {
+	if s == "" {
+		return
+	}
+	e.repeatedString(field, s)
+}
+
+func (e *encoder) repeatedString(field int, s string) {
+	e.tag(field, wireBytes)
+	e.uvarint(uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) message(field int, data []byte) {
+	e.tag(field, wireBytes)
+	e.uvarint(uint64(len(data)))
+	e.buf = append(e.buf, data...)
+}
+
+// value is a decoded field value. Depending on the wire type, either varint
+// or bytes is set. Fixed size values are stored in varint.
+type value struct {
+	varint uint64
+	bytes  []byte
+}
+
+var errTruncated = errors.New("protoreport: unexpected end of data")
+
+// decode reads all fields in data and calls f for each of them.
+func decode(data []byte, f func(field int, v value) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errTruncated
+		}
+		data = data[n:]
+
+		var v value
+		switch wireType := int(key & 7); wireType {
+		case wireVarint:
+			if v.varint, n = binary.Uvarint(data); n <= 0 {
+				return errTruncated
+			}
+			data = data[n:]
+		case wireFixed64:
+			if len(data) < 8 {
+				return errTruncated
+			}
+			v.varint = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return errTruncated
+			}
+			v.bytes = data[n : n+int(length)]
+			data = data[n+int(length):]
+		case wireFixed32:
+			if len(data) < 4 {
+				return errTruncated
+			}
+			v.varint = uint64(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		default:
+			return fmt.Errorf("protoreport: unsupported wire type %d", wireType)
+		}
+
+		if err := f(int(key>>3), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
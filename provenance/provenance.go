@@ -0,0 +1,210 @@
+// Package provenance records where a report came from, and lets downstream
+// systems verify that a published report wasn't modified after it was
+// generated.
+//
+// Info describes the tool that generated a report, the digest of the input it
+// was generated from and the time it was generated. Annotate adds it to every
+// package of a report as properties, so it's embedded in the formats that
+// write package properties, such as the testsuite properties of JUnit XML.
+//
+// A Writer computes the SHA-256 digest, and optionally the Ed25519 signature,
+// of a report while it's written. WriteDigest writes the digest in the format
+// of sha256sum, so the report can be checked with sha256sum -c, and
+// WriteSignature writes the base64 encoded signature, which can be checked
+// with Verify.
+package provenance
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// Package properties added by Annotate.
+const (
+	PropertyTool        = "provenance.tool"
+	PropertyInputDigest = "provenance.input_digest"
+	PropertyGenerated   = "provenance.generated"
+)
+
+// DigestPrefix is the prefix of the digests returned by this package, which
+// names the hash function used.
+const DigestPrefix = "sha256:"
+
+// Info describes how a report was generated.
+type Info struct {
+	Tool        string    // name and version of the tool, e.g. go-junit-report v2.1.0
+	InputDigest string    // digest of the input, see Digest
+	Generated   time.Time // time the report was generated
+}
+
+// Annotate returns a copy of report r in which every package has the
+// properties describing info. Empty fields of info are left out.
+func Annotate(r gtr.Report, info Info) gtr.Report {
+	var props []gtr.Property
+	if info.Tool != "" {
+		props = append(props, gtr.Property{Name: PropertyTool, Value: info.Tool})
+	}
+	if info.InputDigest != "" {
+		props = append(props, gtr.Property{Name: PropertyInputDigest, Value: info.InputDigest})
+	}
+	if !info.Generated.IsZero() {
+		props = append(props, gtr.Property{Name: PropertyGenerated, Value: info.Generated.UTC().Format(time.RFC3339)})
+	}
+	if len(props) == 0 {
+		return r
+	}
+
+	annotated := r
+	annotated.Packages = make([]gtr.Package, len(r.Packages))
+	for i, pkg := range r.Packages {
+		pkg.Properties = append([]gtr.Property(nil), pkg.Properties...)
+		for _, prop := range props {
+			pkg.SetProperty(prop.Name, prop.Value)
+		}
+		annotated.Packages[i] = pkg
+	}
+	return annotated
+}
+
+// Digest returns the SHA-256 digest of everything read from r, formatted as
+// DigestPrefix followed by the hex encoded digest.
+func Digest(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return formatDigest(h), nil
+}
+
+func formatDigest(h hash.Hash) string {
+	return DigestPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// Writer is an io.Writer that computes the digest and signature of
+// everything written to it, while passing it on to the underlying writer.
+type Writer struct {
+	w   io.Writer
+	h   hash.Hash
+	key ed25519.PrivateKey
+	buf bytes.Buffer // everything written, only kept when signing
+}
+
+// NewWriter returns a Writer that writes to w. If key is not nil, the
+// written data is signed using key.
+func NewWriter(w io.Writer, key ed25519.PrivateKey) *Writer {
+	return &Writer{w: w, h: sha256.New(), key: key}
+}
+
+// Write writes p to the underlying writer, and adds it to the digest and
+// signature.
+func (w *Writer) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.h.Write(p[:n])
+	if w.key != nil {
+		w.buf.Write(p[:n])
+	}
+	return n, err
+}
+
+// Digest returns the digest of everything written so far, formatted like the
+// digests returned by Digest.
+func (w *Writer) Digest() string {
+	return formatDigest(w.h)
+}
+
+// Signature returns the Ed25519 signature of everything written so far, or
+// nil if w doesn't have a key.
+func (w *Writer) Signature() []byte {
+	if w.key == nil {
+		return nil
+	}
+	return ed25519.Sign(w.key, w.buf.Bytes())
+}
+
+// WriteDigest writes a line in the format of sha256sum to w, containing
+// digest and the name of the file it's the digest of.
+func WriteDigest(w io.Writer, digest, name string) error {
+	if !strings.HasPrefix(digest, DigestPrefix) {
+		return fmt.Errorf("unsupported digest: %s", digest)
+	}
+	_, err := fmt.Fprintf(w, "%s  %s\n", strings.TrimPrefix(digest, DigestPrefix), name)
+	return err
+}
+
+// WriteSignature writes the base64 encoding of signature sig to w.
+func WriteSignature(w io.Writer, sig []byte) error {
+	_, err := fmt.Fprintln(w, base64.StdEncoding.EncodeToString(sig))
+	return err
+}
+
+// Verify reports whether signature, as written by WriteSignature, is a valid
+// signature of report by the owner of the public key.
+func Verify(key ed25519.PublicKey, report, signature []byte) bool {
+	sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(key, report, sig)
+}
+
+// ParsePrivateKey parses a PEM encoded PKCS #8 Ed25519 private key, such as
+// those generated by openssl genpkey -algorithm ed25519.
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	der, err := decodePEM(data, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	if k, ok := key.(ed25519.PrivateKey); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unsupported private key type %T, want an Ed25519 key", key)
+}
+
+// ParsePublicKey parses a PEM encoded PKIX Ed25519 public key, such as
+// those written by openssl pkey -pubout.
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	der, err := decodePEM(data, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	if k, ok := key.(ed25519.PublicKey); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T, want an Ed25519 key", key)
+}
+
+// decodePEM returns the bytes of the first PEM block of the given type in
+// data.
+func decodePEM(data []byte, typ string) ([]byte, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("no " + typ + " PEM block found")
+		}
+		if block.Type == typ {
+			return block.Bytes, nil
+		}
+	}
+}
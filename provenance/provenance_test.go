@@ -0,0 +1,133 @@
+package provenance
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAnnotate(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{
+		{Name: "package/one", Properties: []gtr.Property{{Name: "go.version", Value: "1.13"}}},
+		{Name: "package/two", Properties: []gtr.Property{{Name: PropertyTool, Value: "other"}}},
+	}}
+	info := Info{
+		Tool:        "go-junit-report v2.0.0",
+		InputDigest: "sha256:abc",
+		Generated:   time.Date(2022, 1, 1, 1, 0, 0, 0, time.FixedZone("CET", 3600)),
+	}
+	want := gtr.Report{Packages: []gtr.Package{
+		{Name: "package/one", Properties: []gtr.Property{
+			{Name: "go.version", Value: "1.13"},
+			{Name: PropertyTool, Value: "go-junit-report v2.0.0"},
+			{Name: PropertyInputDigest, Value: "sha256:abc"},
+			{Name: PropertyGenerated, Value: "2022-01-01T00:00:00Z"},
+		}},
+		{Name: "package/two", Properties: []gtr.Property{
+			{Name: PropertyTool, Value: "go-junit-report v2.0.0"},
+			{Name: PropertyInputDigest, Value: "sha256:abc"},
+			{Name: PropertyGenerated, Value: "2022-01-01T00:00:00Z"},
+		}},
+	}}
+	got := Annotate(report, info)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Annotate incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if len(report.Packages[0].Properties) != 1 {
+		t.Errorf("Annotate modified the properties of the original report: %v", report.Packages[0].Properties)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+	report := "<testsuites></testsuites>\n"
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, key)
+	for _, part := range []string{report[:12], report[12:]} {
+		if _, err := w.Write([]byte(part)); err != nil {
+			t.Fatalf("Write error: %v", err)
+		}
+	}
+	if buf.String() != report {
+		t.Errorf("Writer wrote %q, want %q", buf.String(), report)
+	}
+
+	digest, err := Digest(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("Digest error: %v", err)
+	}
+	if w.Digest() != digest {
+		t.Errorf("Writer.Digest() = %q, want %q", w.Digest(), digest)
+	}
+
+	var digestOut bytes.Buffer
+	if err := WriteDigest(&digestOut, w.Digest(), "report.xml"); err != nil {
+		t.Fatalf("WriteDigest error: %v", err)
+	}
+	if want := strings.TrimPrefix(digest, DigestPrefix) + "  report.xml\n"; digestOut.String() != want {
+		t.Errorf("WriteDigest wrote %q, want %q", digestOut.String(), want)
+	}
+
+	var sig bytes.Buffer
+	if err := WriteSignature(&sig, w.Signature()); err != nil {
+		t.Fatalf("WriteSignature error: %v", err)
+	}
+	if !Verify(pub, []byte(report), sig.Bytes()) {
+		t.Errorf("Verify(%q) = false, want true", sig.String())
+	}
+	if Verify(pub, []byte(report+" "), sig.Bytes()) {
+		t.Errorf("Verify of modified report = true, want false")
+	}
+
+	if sig := NewWriter(&buf, nil).Signature(); sig != nil {
+		t.Errorf("Signature of Writer without key = %v, want nil", sig)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("error marshaling private key: %v", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("error marshaling public key: %v", err)
+	}
+	data := append(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})...)
+
+	gotKey, err := ParsePrivateKey(data)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey error: %v", err)
+	}
+	if !bytes.Equal(gotKey, key) {
+		t.Errorf("ParsePrivateKey returned a different key")
+	}
+	gotPub, err := ParsePublicKey(data)
+	if err != nil {
+		t.Fatalf("ParsePublicKey error: %v", err)
+	}
+	if !bytes.Equal(gotPub, pub) {
+		t.Errorf("ParsePublicKey returned a different key")
+	}
+
+	if _, err := ParsePrivateKey([]byte("not a key")); err == nil {
+		t.Errorf("ParsePrivateKey of invalid data did not return an error")
+	}
+}
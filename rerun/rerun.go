@@ -0,0 +1,158 @@
+// Package rerun writes the failed tests of a report as `go test -run`
+// patterns, so that only the failed tests need to be run again.
+//
+// Each line of the output contains a package name, followed by a tab and a
+// regular expression that matches the failed tests in that package, for
+// example:
+//
+//	example.com/mod/pkg	^TestFoo$|^TestBar$
+//
+// Only the names of top-level tests are used, since a failed subtest can only
+// be rerun by running its parent test. Packages that failed to build or that
+// failed outside of a test, for example because TestMain exited, are written
+// without a pattern, meaning all their tests should be run again. Benchmarks
+// are not included, as they're not selected by the -run flag.
+//
+// WriteFailures writes the failed tests in the format of the rerun fails
+// report of gotestsum instead, and WriteCommands writes the go test command
+// that reproduces the failures of each package.
+package rerun
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/escape"
+)
+
+// Package contains the tests to rerun in a single package. When Tests is
+// empty, all tests in the package should be run again. ShuffleSeed is the seed
+// that was used to shuffle the order of the tests, if any.
+type Package struct {
+	Name        string
+	Tests       []string
+	ShuffleSeed string
+}
+
+// Pattern returns the pattern for the -run flag of go test that matches
+// exactly the tests in p, or an empty string if all tests should be run.
+func (p Package) Pattern() string {
+	return Pattern(p.Tests)
+}
+
+// Pattern returns a regular expression that matches exactly the given test
+// names. Regular expression metacharacters in the names are escaped. An
+// empty string is returned if names is empty.
+func Pattern(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "^" + escape.Regexp(name) + "$"
+	}
+	return strings.Join(quoted, "|")
+}
+
+// Command returns the go test command that runs the tests in p again. When the
+// tests of p were shuffled, the command runs all tests of the package with the
+// same -shuffle seed instead, since running only some of them would change
+// their order and may not reproduce a failure that depends on it.
+func (p Package) Command() string {
+	args := []string{"go", "test", "-count=1"}
+	if p.ShuffleSeed != "" {
+		args = append(args, "-shuffle="+p.ShuffleSeed)
+	} else if pattern := p.Pattern(); pattern != "" {
+		args = append(args, "-run", "'"+pattern+"'")
+	}
+	return strings.Join(append(args, p.Name), " ")
+}
+
+// Failed returns the packages in report r containing tests that failed or
+// didn't report a result, in report order. Flaky tests eventually passed and
+// are not included.
+func Failed(r gtr.Report) []Package {
+	var pkgs []Package
+	for _, pkg := range r.Packages {
+		if pkg.BuildError.Name != "" || pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			pkgs = append(pkgs, Package{Name: pkg.Name, ShuffleSeed: pkg.ShuffleSeed()})
+			continue
+		}
+
+		p := Package{Name: pkg.Name, ShuffleSeed: pkg.ShuffleSeed()}
+		seen := make(map[string]bool)
+		for _, t := range pkg.Tests {
+			if t.Result.Base() != gtr.Fail && t.Result != gtr.Unknown {
+				continue
+			}
+			name := t.Name
+			if idx := strings.IndexByte(name, '/'); idx >= 0 {
+				name = name[:idx]
+			}
+			if strings.HasPrefix(name, "Benchmark") || seen[name] {
+				continue
+			}
+			seen[name] = true
+			p.Tests = append(p.Tests, name)
+		}
+		if len(p.Tests) > 0 {
+			pkgs = append(pkgs, p)
+		}
+	}
+	return pkgs
+}
+
+// Write writes a line containing the package name and -run pattern for each
+// package with failed tests in report r to w.
+func Write(w io.Writer, r gtr.Report) error {
+	bw := bufio.NewWriter(w)
+	for _, p := range Failed(r) {
+		if pattern := p.Pattern(); pattern != "" {
+			fmt.Fprintf(bw, "%s\t%s\n", p.Name, pattern)
+		} else {
+			fmt.Fprintf(bw, "%s\n", p.Name)
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteCommands writes the go test command that reproduces the failures of
+// each package with failed tests in report r to w, one per line, see
+// Package.Command.
+func WriteCommands(w io.Writer, r gtr.Report) error {
+	bw := bufio.NewWriter(w)
+	for _, p := range Failed(r) {
+		fmt.Fprintln(bw, p.Command())
+	}
+	return bw.Flush()
+}
+
+// WriteFailures writes a line containing the package name and the full name
+// of each failed test in report r to w, separated by a space, in the format
+// of the --rerun-fails-report file of gotestsum, for example:
+//
+//	example.com/mod/pkg TestFoo
+//	example.com/mod/pkg TestFoo/sub
+//
+// Like gotestsum, packages are sorted by name, subtests are included and
+// tests without a result are considered failed. Packages that failed outside
+// of their tests are not included, use Write to rerun those as well.
+func WriteFailures(w io.Writer, r gtr.Report) error {
+	pkgs := make([]gtr.Package, len(r.Packages))
+	copy(pkgs, r.Packages)
+	sort.SliceStable(pkgs, func(i, j int) bool { return pkgs[i].Name < pkgs[j].Name })
+
+	bw := bufio.NewWriter(w)
+	for _, pkg := range pkgs {
+		for _, t := range pkg.Tests {
+			if t.Result.Base() == gtr.Fail || t.Result == gtr.Unknown {
+				fmt.Fprintf(bw, "%s %s\n", pkg.Name, t.Name)
+			}
+		}
+	}
+	return bw.Flush()
+}
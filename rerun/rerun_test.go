@@ -0,0 +1,126 @@
+package rerun
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPattern(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"TestOne"}, "^TestOne$"},
+		{[]string{"TestOne", "TestTwo"}, "^TestOne$|^TestTwo$"},
+		{[]string{"Test.Dot", "Test(Paren)", "Test+Plus"}, `^Test\.Dot$|^Test\(Paren\)$|^Test\+Plus$`},
+	}
+	for _, test := range tests {
+		if got := Pattern(test.names); got != test.want {
+			t.Errorf("Pattern(%q) = %q, want %q", test.names, got, test.want)
+		}
+	}
+
+	re := regexp.MustCompile(Pattern([]string{"TestA", "Test.B"}))
+	for name, want := range map[string]bool{"TestA": true, "Test.B": true, "TestXB": false, "TestAB": false, "XTestA": false} {
+		if got := re.MatchString(name); got != want {
+			t.Errorf("pattern %q matches %q = %v, want %v", re, name, got, want)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{
+				Name: "package/one",
+				Tests: []gtr.Test{
+					{Name: "TestPass", Result: gtr.Pass},
+					{Name: "TestFail", Result: gtr.Fail},
+					{Name: "TestParent", Result: gtr.Pass},
+					{Name: "TestParent/sub", Result: gtr.Fail},
+					{Name: "TestParent/other", Result: gtr.Fail},
+					{Name: "TestCrash", Result: gtr.Unknown},
+					{Name: "TestFlaky", Result: gtr.Flaky},
+					{Name: "BenchmarkFail", Result: gtr.Fail},
+				},
+			},
+			{Name: "package/passed", Tests: []gtr.Test{{Name: "TestPass", Result: gtr.Pass}}},
+			{Name: "package/broken", BuildError: gtr.Error{Name: "package/broken"}},
+		},
+	}
+
+	want := "package/one\t^TestFail$|^TestParent$|^TestCrash$\npackage/broken\n"
+	var buf bytes.Buffer
+	if err := Write(&buf, report); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Write output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWriteFailures(t *testing.T) {
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{
+				Name: "package/two",
+				Tests: []gtr.Test{
+					{Name: "TestPass", Result: gtr.Pass},
+					{Name: "TestParent", Result: gtr.Fail},
+					{Name: "TestParent/sub", Result: gtr.Fail},
+					{Name: "TestFlaky", Result: gtr.Flaky},
+				},
+			},
+			{
+				Name: "package/one",
+				Tests: []gtr.Test{
+					{Name: "TestFail", Result: gtr.Fail},
+					{Name: "TestCrash", Result: gtr.Unknown},
+					{Name: "TestSkip", Result: gtr.Skip},
+				},
+			},
+			{Name: "package/broken", BuildError: gtr.Error{Name: "package/broken"}},
+		},
+	}
+
+	want := "package/one TestFail\npackage/one TestCrash\npackage/two TestParent\npackage/two TestParent/sub\n"
+	var buf bytes.Buffer
+	if err := WriteFailures(&buf, report); err != nil {
+		t.Fatalf("WriteFailures error: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("WriteFailures output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWriteCommands(t *testing.T) {
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{Name: "package/one", Tests: []gtr.Test{{Name: "TestFail", Result: gtr.Fail}, {Name: "TestCrash", Result: gtr.Unknown}}},
+			{
+				Name:       "package/shuffled",
+				Properties: []gtr.Property{{Name: gtr.ShuffleSeedProperty, Value: "1652986251466377000"}},
+				Tests:      []gtr.Test{{Name: "TestPass", Result: gtr.Pass}, {Name: "TestFail", Result: gtr.Fail}},
+			},
+			{Name: "package/passed", Tests: []gtr.Test{{Name: "TestPass", Result: gtr.Pass}}},
+			{Name: "package/broken", BuildError: gtr.Error{Name: "package/broken"}},
+		},
+	}
+
+	want := "go test -count=1 -run '^TestFail$|^TestCrash$' package/one\n" +
+		"go test -count=1 -shuffle=1652986251466377000 package/shuffled\n" +
+		"go test -count=1 package/broken\n"
+	var buf bytes.Buffer
+	if err := WriteCommands(&buf, report); err != nil {
+		t.Fatalf("WriteCommands error: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("WriteCommands output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
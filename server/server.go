@@ -0,0 +1,268 @@
+// Package server implements a small HTTP service that converts test output to
+// reports, stores them and serves them in different formats, so that pipelines
+// only need to upload their go test output instead of converting it
+// themselves.
+//
+// The service has the following endpoints:
+//
+//	POST /reports       convert the test output in the request body and store the report
+//	GET  /reports       list the ids of the stored reports
+//	GET  /reports/{id}  get the stored report with the given id
+//
+// The parser used to convert the request body of a POST request is set by
+// the parser query parameter, which defaults to gotest. Any parser in the
+// parser registry can be used, as well as gtrjson for reports written by
+// package gtrjson. The report is stored under the id given by the id query
+// parameter, replacing any report with the same id, or under a new id. The
+// response contains the id and a summary of the report.
+//
+// The format of the report returned by a GET request is set by the format
+// query parameter: junit (the default), html or json, the latter being the
+// format of package gtrjson.
+package server
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/gtrjson"
+	"github.com/jstemmer/go-junit-report/v2/html"
+	"github.com/jstemmer/go-junit-report/v2/junit"
+	"github.com/jstemmer/go-junit-report/v2/parser"
+)
+
+// DefaultMaxBodySize is the default maximum size of a request body.
+const DefaultMaxBodySize = 64 << 20
+
+// regexID matches valid report ids.
+var regexID = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ConvertFunc converts the test output read from r using the parser with the
+// given name to a report.
+type ConvertFunc func(r io.Reader, parserName string) (gtr.Report, error)
+
+// Convert is the default ConvertFunc. It uses the parsers in the parser
+// registry, or gtrjson.Read for the gtrjson parser.
+func Convert(r io.Reader, parserName string) (gtr.Report, error) {
+	if parserName == "gtrjson" {
+		return gtrjson.Read(r)
+	}
+	p, err := parser.New(parserName)
+	if err != nil {
+		return gtr.Report{}, err
+	}
+	return p.Parse(r)
+}
+
+// format is an output format in which reports are served.
+type format struct {
+	contentType string
+	write       func(w io.Writer, r gtr.Report) error
+}
+
+var formats = map[string]format{
+	"junit": {"application/xml; charset=utf-8", writeJUnit},
+	"html":  {"text/html; charset=utf-8", html.Write},
+	"json":  {"application/json", gtrjson.Write},
+}
+
+func writeJUnit(w io.Writer, r gtr.Report) error {
+	suites := junit.CreateFromReport(r, "")
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return suites.WriteXML(w)
+}
+
+// Server is an http.Handler serving the report endpoints.
+type Server struct {
+	// Store contains the reports. If it's nil, a MemoryStore is used.
+	Store Store
+
+	// Convert converts the request body of POST requests. If it's nil, the
+	// Convert function of this package is used.
+	Convert ConvertFunc
+
+	// MaxBodySize is the maximum size of a request body in bytes. If it's
+	// not positive, DefaultMaxBodySize is used.
+	MaxBodySize int64
+
+	once sync.Once
+	mu   sync.Mutex // serializes the creation of new ids
+	mux  *http.ServeMux
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.once.Do(func() {
+		if s.Store == nil {
+			s.Store = NewMemoryStore()
+		}
+		if s.Convert == nil {
+			s.Convert = Convert
+		}
+		s.mux = http.NewServeMux()
+		s.mux.HandleFunc("/reports", s.handleReports)
+		s.mux.HandleFunc("/reports/", s.handleReport)
+	})
+	s.mux.ServeHTTP(w, r)
+}
+
+// uploadResponse is the response to a POST request.
+type uploadResponse struct {
+	ID      string  `json:"id"`
+	URL     string  `json:"url"`
+	Summary summary `json:"summary"`
+}
+
+type summary struct {
+	Tests   int `json:"tests"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+	Flaky   int `json:"flaky"`
+	Errors  int `json:"errors"`
+}
+
+func (s *Server) handleReports(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		ids, err := s.Store.List()
+		if err != nil {
+			httpError(w, http.StatusInternalServerError, "error listing reports: %v", err)
+			return
+		}
+		if ids == nil {
+			ids = []string{}
+		}
+		writeJSON(w, http.StatusOK, ids)
+	case http.MethodPost:
+		s.upload(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		httpError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+	}
+}
+
+func (s *Server) upload(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	id := query.Get("id")
+	if id != "" && !regexID.MatchString(id) {
+		httpError(w, http.StatusBadRequest, "invalid id: %q", id)
+		return
+	}
+	parserName := query.Get("parser")
+	if parserName == "" {
+		parserName = "gotest"
+	}
+
+	limit := s.MaxBodySize
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+	report, err := s.Convert(http.MaxBytesReader(w, r.Body, limit), parserName)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, "error converting report: %v", err)
+		return
+	}
+
+	if id == "" {
+		if id, err = s.put(report); err != nil {
+			httpError(w, http.StatusInternalServerError, "error storing report: %v", err)
+			return
+		}
+	} else if err := s.Store.Put(id, report); err != nil {
+		httpError(w, http.StatusInternalServerError, "error storing report: %v", err)
+		return
+	}
+
+	sum := report.Summary()
+	url := "/reports/" + id
+	w.Header().Set("Location", url)
+	writeJSON(w, http.StatusCreated, uploadResponse{
+		ID:  id,
+		URL: url,
+		Summary: summary{
+			Tests:   sum.Tests,
+			Passed:  sum.Passed,
+			Failed:  sum.Failed,
+			Skipped: sum.Skipped,
+			Flaky:   sum.Flaky,
+			Errors:  sum.Errors,
+		},
+	})
+}
+
+// put stores report under a new id, derived from the current time, and
+// returns the id.
+func (s *Server) put(report gtr.Report) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	base := time.Now().UTC().Format("20060102-150405")
+	id := base
+	for n := 2; ; n++ {
+		_, ok, err := s.Store.Get(id)
+		if err != nil {
+			return "", err
+		} else if !ok {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	return id, s.Store.Put(id, report)
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		httpError(w, http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/reports/")
+	if !regexID.MatchString(id) {
+		httpError(w, http.StatusNotFound, "report %q not found", id)
+		return
+	}
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = "junit"
+	}
+	f, ok := formats[name]
+	if !ok {
+		httpError(w, http.StatusBadRequest, "invalid format: %q", name)
+		return
+	}
+
+	report, ok, err := s.Store.Get(id)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, "error reading report: %v", err)
+		return
+	} else if !ok {
+		httpError(w, http.StatusNotFound, "report %q not found", id)
+		return
+	}
+	w.Header().Set("Content-Type", f.contentType)
+	if err := f.write(w, report); err != nil {
+		// The response has already been started, so the status can no
+		// longer be changed.
+		return
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func httpError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	http.Error(w, fmt.Sprintf(format, args...), status)
+}
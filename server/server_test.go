@@ -0,0 +1,154 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/gtrjson"
+
+	_ "github.com/jstemmer/go-junit-report/v2/parser/gotest"
+)
+
+const testOutput = `=== RUN   TestOne
+--- PASS: TestOne (0.01s)
+=== RUN   TestTwo
+--- FAIL: TestTwo (0.02s)
+FAIL
+FAIL	package/name	0.030s
+`
+
+func do(t *testing.T, h http.Handler, method, url, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, url, strings.NewReader(body)))
+	return rec
+}
+
+func TestServer(t *testing.T) {
+	srv := &Server{}
+
+	rec := do(t, srv, "POST", "/reports?id=run-1", testOutput)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST status = %d, want %d, body: %s", rec.Code, http.StatusCreated, rec.Body)
+	}
+	if got := rec.Header().Get("Location"); got != "/reports/run-1" {
+		t.Errorf("POST Location = %q, want %q", got, "/reports/run-1")
+	}
+	var got uploadResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("error decoding response: %v", err)
+	}
+	want := uploadResponse{ID: "run-1", URL: "/reports/run-1", Summary: summary{Tests: 2, Passed: 1, Failed: 1}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("POST response incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	rec = do(t, srv, "GET", "/reports/run-1", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `<testcase name="TestTwo"`) {
+		t.Errorf("GET junit report = %d %s, want a report containing TestTwo", rec.Code, rec.Body)
+	}
+
+	rec = do(t, srv, "GET", "/reports/run-1?format=json", "")
+	report, err := gtrjson.Read(rec.Body)
+	if err != nil {
+		t.Fatalf("error reading json report: %v", err)
+	}
+	if s := report.Summary(); s.Tests != 2 || s.Failed != 1 {
+		t.Errorf("GET json report summary = %+v, want 2 tests and 1 failure", s)
+	}
+
+	rec = do(t, srv, "GET", "/reports/run-1?format=html", "")
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("GET html report Content-Type = %q", ct)
+	}
+
+	// Reports in the gtrjson format are stored as is.
+	rec = do(t, srv, "POST", "/reports?parser=gtrjson", `{"version":1,"packages":[{"name":"package/other","tests":[{"name":"TestA","result":"skip"}]}]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST gtrjson status = %d, body: %s", rec.Code, rec.Body)
+	}
+	var second uploadResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &second); err != nil {
+		t.Fatalf("error decoding response: %v", err)
+	}
+	if second.ID == "" || second.Summary.Skipped != 1 {
+		t.Errorf("POST gtrjson response = %+v, want a generated id and 1 skipped test", second)
+	}
+
+	rec = do(t, srv, "GET", "/reports", "")
+	var ids []string
+	if err := json.Unmarshal(rec.Body.Bytes(), &ids); err != nil {
+		t.Fatalf("error decoding list: %v", err)
+	}
+	if diff := cmp.Diff([]string{second.ID, "run-1"}, ids); diff != "" {
+		t.Errorf("GET /reports incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	srv := &Server{MaxBodySize: 16}
+	srv.Store = NewMemoryStore()
+	srv.Store.Put("existing", gtr.Report{})
+
+	tests := []struct {
+		method, url, body string
+		want              int
+	}{
+		{"POST", "/reports?id=../escape", "", http.StatusBadRequest},
+		{"POST", "/reports?parser=unknown", "", http.StatusBadRequest},
+		{"POST", "/reports", testOutput, http.StatusBadRequest},
+		{"DELETE", "/reports", "", http.StatusMethodNotAllowed},
+		{"GET", "/reports/missing", "", http.StatusNotFound},
+		{"GET", "/reports/existing?format=pdf", "", http.StatusBadRequest},
+		{"POST", "/reports/existing", "", http.StatusMethodNotAllowed},
+	}
+	for _, test := range tests {
+		if rec := do(t, srv, test.method, test.url, test.body); rec.Code != test.want {
+			t.Errorf("%s %s status = %d, want %d", test.method, test.url, rec.Code, test.want)
+		}
+	}
+}
+
+func TestDirStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := DirStore{Dir: dir}
+	if ids, err := s.List(); err != nil || len(ids) != 0 {
+		t.Fatalf("List of empty store = %v, %v", ids, err)
+	}
+	if _, ok, err := s.Get("missing"); ok || err != nil {
+		t.Errorf("Get(missing) = %v, %v, want not found", ok, err)
+	}
+
+	want := gtr.Report{Packages: []gtr.Package{{Name: "package/name", Tests: []gtr.Test{{Name: "TestA", Result: gtr.Pass}}}}}
+	for _, id := range []string{"b", "a"} {
+		if err := s.Put(id, want); err != nil {
+			t.Fatalf("Put(%s) error: %v", id, err)
+		}
+	}
+	got, ok, err := s.Get("a")
+	if err != nil || !ok {
+		t.Fatalf("Get(a) = %v, %v", ok, err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Get result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	ids, err := s.List()
+	if err != nil {
+		t.Fatalf("List error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"a", "b"}, ids); diff != "" {
+		t.Errorf("List result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/gtrjson"
+)
+
+// Store stores reports by id. It must be safe for concurrent use.
+type Store interface {
+	// Put stores report r under id, replacing any report stored under the
+	// same id.
+	Put(id string, r gtr.Report) error
+
+	// Get returns the report stored under id, and whether it exists.
+	Get(id string) (gtr.Report, bool, error)
+
+	// List returns the ids of all stored reports in sorted order.
+	List() ([]string, error)
+}
+
+// MemoryStore is a Store that keeps reports in memory.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	reports map[string]gtr.Report
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{reports: make(map[string]gtr.Report)}
+}
+
+// Put implements Store.
+func (s *MemoryStore) Put(id string, r gtr.Report) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reports[id] = r
+	return nil
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(id string) (gtr.Report, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.reports[id]
+	return r, ok, nil
+}
+
+// List implements Store.
+func (s *MemoryStore) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.reports))
+	for id := range s.reports {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// DirStore is a Store that keeps every report in a file named <id>.json in
+// directory Dir, in the format of package gtrjson.
+type DirStore struct {
+	Dir string
+}
+
+// Put implements Store. The report is written to a temporary file first, so
+// that readers never see partially written reports.
+func (s DirStore) Put(id string, r gtr.Report) error {
+	data, err := gtrjson.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(s.Dir, "."+id+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.path(id))
+}
+
+// Get implements Store.
+func (s DirStore) Get(id string) (gtr.Report, bool, error) {
+	data, err := ioutil.ReadFile(s.path(id))
+	if os.IsNotExist(err) {
+		return gtr.Report{}, false, nil
+	} else if err != nil {
+		return gtr.Report{}, false, err
+	}
+	r, err := gtrjson.Unmarshal(data)
+	if err != nil {
+		return gtr.Report{}, false, err
+	}
+	return r, true, nil
+}
+
+// List implements Store.
+func (s DirStore) List() ([]string, error) {
+	infos, err := ioutil.ReadDir(s.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, ".json"))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (s DirStore) path(id string) string {
+	return filepath.Join(s.Dir, id+".json")
+}
@@ -0,0 +1,119 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DefaultMetadataTokenURL is the URL of the access token of the default
+// service account on the metadata server of Google Cloud environments.
+const DefaultMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// GCS uploads the data to gs://bucket/object URLs using the XML API of Google
+// Cloud Storage.
+type GCS struct {
+	// Client is the HTTP client used to send the requests,
+	// http.DefaultClient if nil.
+	Client *http.Client
+
+	// Endpoint is the base URL of the storage service, such as an emulator.
+	// If it's empty, https://storage.googleapis.com is used.
+	Endpoint string
+
+	// Token is the OAuth 2.0 access token sent with the request. If it's
+	// empty, the token of the default service account is requested from the
+	// metadata server at MetadataTokenURL.
+	Token string
+
+	// MetadataTokenURL is the URL from which the access token is requested
+	// if Token is empty, DefaultMetadataTokenURL if empty.
+	MetadataTokenURL string
+}
+
+// GCSFromEnv returns a GCS configured by the environment variables
+// GOOGLE_OAUTH_ACCESS_TOKEN, for the access token, and STORAGE_EMULATOR_HOST,
+// for the endpoint of a storage emulator. Without an access token, uploads
+// only succeed when running in a Google Cloud environment with a metadata
+// server, such as Cloud Build or GKE.
+func GCSFromEnv() GCS {
+	g := GCS{Token: os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")}
+	if host := os.Getenv("STORAGE_EMULATOR_HOST"); host != "" {
+		if !strings.Contains(host, "://") {
+			host = "http://" + host
+		}
+		g.Endpoint = host
+	}
+	return g
+}
+
+// Open implements Opener.
+func (g GCS) Open(u *url.URL, contentType string) (Writer, error) {
+	bucket, object := u.Host, strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || object == "" {
+		return nil, fmt.Errorf("sink: invalid Google Cloud Storage URL %q, want gs://bucket/object", u)
+	}
+	endpoint := g.Endpoint
+	if endpoint == "" {
+		endpoint = "https://storage.googleapis.com"
+	}
+	target := strings.TrimSuffix(endpoint, "/") + "/" + bucket + "/" + escapePath(object)
+
+	return &upload{send: func(body []byte) error {
+		token := g.Token
+		if token == "" {
+			var err error
+			if token, err = g.metadataToken(); err != nil {
+				return fmt.Errorf("sink: no Google Cloud access token found for %s, set GOOGLE_OAUTH_ACCESS_TOKEN: %v", u, err)
+			}
+		}
+		req, err := http.NewRequest(http.MethodPut, target, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return do(g.Client, req)
+	}}, nil
+}
+
+// metadataToken requests an access token from the metadata server.
+func (g GCS) metadataToken() (string, error) {
+	tokenURL := g.MetadataTokenURL
+	if tokenURL == "" {
+		tokenURL = DefaultMetadataTokenURL
+	}
+	req, err := http.NewRequest(http.MethodGet, tokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata server returned %s", resp.Status)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("metadata server returned no access token")
+	}
+	return token.AccessToken, nil
+}
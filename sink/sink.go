@@ -0,0 +1,197 @@
+// Package sink writes reports to destinations addressed by URL, such as
+// s3://bucket/key or gs://bucket/object, so that CI jobs don't need a
+// separate step to upload them.
+//
+// A destination is opened with Create, which returns a Writer. Nothing is
+// sent until the Writer is committed, so a report that couldn't be generated
+// never replaces an earlier upload:
+//
+//	w, err := sink.Create("s3://bucket/reports/report.xml", "application/xml")
+//	if err != nil {
+//		return err
+//	}
+//	if err := write(w); err != nil {
+//		w.Abort()
+//		return err
+//	}
+//	return w.Commit()
+//
+// The following URL schemes are registered by default:
+//
+//   - s3: uploads to Amazon S3, or the S3 compatible service set by the
+//     AWS_ENDPOINT_URL_S3 or AWS_ENDPOINT_URL environment variable, see S3
+//   - gs: uploads to Google Cloud Storage, see GCS
+//   - http and https: sends a PUT request to the URL, e.g. a presigned URL,
+//     see HTTP
+//
+// Other schemes can be made available using Register.
+package sink
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Writer writes a report to a destination.
+type Writer interface {
+	io.Writer
+
+	// Commit sends everything written so far to the destination.
+	Commit() error
+
+	// Abort discards everything written so far, leaving the destination
+	// untouched.
+	Abort()
+}
+
+// Opener opens the destination at u. The contentType is the media type of
+// the data that will be written, such as application/xml.
+type Opener func(u *url.URL, contentType string) (Writer, error)
+
+var (
+	mu      sync.RWMutex
+	openers = make(map[string]Opener)
+)
+
+func init() {
+	// The environment is read when a destination is opened, rather than
+	// once, so that it can still be changed after the package is loaded.
+	Register("s3", func(u *url.URL, contentType string) (Writer, error) {
+		return S3FromEnv().Open(u, contentType)
+	})
+	Register("gs", func(u *url.URL, contentType string) (Writer, error) {
+		return GCSFromEnv().Open(u, contentType)
+	})
+	Register("http", HTTP{}.Open)
+	Register("https", HTTP{}.Open)
+}
+
+// Register makes destinations with the given URL scheme available. It panics
+// if Register is called twice with the same scheme or if open is nil.
+func Register(scheme string, open Opener) {
+	mu.Lock()
+	defer mu.Unlock()
+	if open == nil {
+		panic("sink: Register opener is nil")
+	}
+	if _, dup := openers[scheme]; dup {
+		panic("sink: Register called twice for scheme " + scheme)
+	}
+	openers[scheme] = open
+}
+
+// Schemes returns the sorted URL schemes of all registered destinations.
+func Schemes() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	var schemes []string
+	for scheme := range openers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// IsURL returns true if name is a URL with a registered scheme. Other names,
+// including file paths such as C:\report.xml, are not handled by this package.
+func IsURL(name string) bool {
+	_, ok := opener(name)
+	return ok
+}
+
+// Create opens the destination at URL name, with the data written to it
+// having the given contentType.
+func Create(name, contentType string) (Writer, error) {
+	open, ok := opener(name)
+	if !ok {
+		return nil, fmt.Errorf("sink: unsupported URL %q", name)
+	}
+	u, err := url.Parse(name)
+	if err != nil {
+		return nil, err
+	}
+	return open(u, contentType)
+}
+
+func opener(name string) (Opener, bool) {
+	i := strings.Index(name, "://")
+	if i <= 0 {
+		return nil, false
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	open, ok := openers[strings.ToLower(name[:i])]
+	return open, ok
+}
+
+// upload is a Writer that buffers the data written to it and sends it in a
+// single request when it's committed.
+type upload struct {
+	bytes.Buffer
+	send func(body []byte) error
+}
+
+func (u *upload) Commit() error {
+	return u.send(u.Bytes())
+}
+
+func (u *upload) Abort() {
+	u.Reset()
+}
+
+// do sends req using client, and returns an error if the response doesn't
+// have a 2xx status.
+func do(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		// The query of presigned URLs contains credentials.
+		target := *req.URL
+		target.User, target.RawQuery = nil, ""
+		return fmt.Errorf("sink: uploading to %s failed: %s: %s", target.String(), resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// HTTP sends the data to an http or https URL in a PUT request, such as a
+// presigned S3 or Google Cloud Storage URL.
+type HTTP struct {
+	// Client is the HTTP client used to send the request, http.DefaultClient
+	// if nil.
+	Client *http.Client
+
+	// Header contains additional headers to send with the request.
+	Header http.Header
+}
+
+// Open implements Opener.
+func (h HTTP) Open(u *url.URL, contentType string) (Writer, error) {
+	target := u.String()
+	return &upload{send: func(body []byte) error {
+		req, err := http.NewRequest(http.MethodPut, target, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		for k, v := range h.Header {
+			req.Header[k] = v
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		return do(h.Client, req)
+	}}, nil
+}
@@ -0,0 +1,172 @@
+// Package sonarqube defines the SonarQube Generic Test Execution XML format
+// and includes convenience methods to create these reports from a gtr.Report.
+//
+// SonarQube groups test cases by the source file that contains them. Since a
+// gtr.Report only contains package and test names, a Mapping is used to
+// determine the path of each test file relative to the project root.
+package sonarqube
+
+import (
+	"encoding/xml"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/timefmt"
+)
+
+// regexTestFile matches a file:line reference to a test file in test output.
+var regexTestFile = regexp.MustCompile(`^\s*([^\s:/]+_test\.go):\d+: `)
+
+// TestExecutions is the root element of a SonarQube generic test execution
+// report.
+type TestExecutions struct {
+	XMLName xml.Name `xml:"testExecutions"`
+	Version int      `xml:"version,attr"`
+	Files   []File   `xml:"file"`
+}
+
+// File contains the test cases of a single source file.
+type File struct {
+	Path      string     `xml:"path,attr"`
+	TestCases []TestCase `xml:"testCase"`
+}
+
+// TestCase contains the result of a single test. A test case without any of
+// Skipped, Failure or Error passed.
+type TestCase struct {
+	Name     string  `xml:"name,attr"`
+	Duration int64   `xml:"duration,attr"` // in milliseconds
+	Skipped  *Result `xml:"skipped,omitempty"`
+	Failure  *Result `xml:"failure,omitempty"`
+	Error    *Result `xml:"error,omitempty"`
+}
+
+// Result contains the message and output of a test that didn't pass.
+type Result struct {
+	Message string `xml:"message,attr"`
+	Data    string `xml:",chardata"`
+}
+
+// Mapping maps package and test names to the location of their source file,
+// relative to the project root. Keys are either a package import path followed
+// by a dot and a test name, which map to the file containing that test, or an
+// import path, which maps to the directory of that package and all packages
+// below it. For example, mapping a module path to "." maps all packages in the
+// module to their directory relative to the module root.
+//
+// When a test is not mapped to a file directly, its source file is used if
+// it's known, see gtr.Test.File. Otherwise the file name is taken from the
+// first file:line reference to a _test.go file in its output. If that's
+// not possible either, the test is reported in its package directory. Tests
+// of packages that are not mapped at all are reported using the import path
+// of their package as directory.
+type Mapping map[string]string
+
+// File returns the path of the file containing test name in package pkg.
+func (m Mapping) File(pkg string, test gtr.Test) string {
+	if file, ok := m[pkg+"."+test.Name]; ok {
+		return file
+	}
+	if test.File != "" {
+		return test.File
+	}
+	dir := m.dir(pkg)
+	for _, line := range test.Output {
+		if matches := regexTestFile.FindStringSubmatch(line); matches != nil {
+			return path.Join(dir, matches[1])
+		}
+	}
+	return dir
+}
+
+// dir returns the directory of package pkg, using the mapping with the longest
+// matching import path.
+func (m Mapping) dir(pkg string) string {
+	for prefix := pkg; prefix != "." && prefix != "/" && prefix != ""; prefix = path.Dir(prefix) {
+		if dir, ok := m[prefix]; ok {
+			return path.Join(dir, strings.TrimPrefix(pkg, prefix))
+		}
+	}
+	return pkg
+}
+
+// CreateFromReport creates a SonarQube test execution report from the given
+// gtr.Report, using mapping m to determine the file of each test. Build and
+// runtime errors are reported as a test case with an error in the package
+// directory.
+func CreateFromReport(report gtr.Report, m Mapping) TestExecutions {
+	execs := TestExecutions{Version: 1}
+	index := make(map[string]int) // index in execs.Files by path
+	add := func(file string, tc TestCase) {
+		i, ok := index[file]
+		if !ok {
+			i = len(execs.Files)
+			index[file] = i
+			execs.Files = append(execs.Files, File{Path: file})
+		}
+		execs.Files[i].TestCases = append(execs.Files[i].TestCases, tc)
+	}
+
+	for _, pkg := range report.Packages {
+		for _, test := range pkg.Tests {
+			add(m.File(pkg.Name, test), createTestCase(test))
+		}
+		if pkg.BuildError.Name != "" {
+			add(m.dir(pkg.Name), TestCase{
+				Name:     pkg.BuildError.Name,
+				Duration: timefmt.Millis(pkg.BuildError.Duration),
+				Error:    &Result{Message: pkg.BuildError.Cause, Data: strings.Join(pkg.BuildError.Output, "\n")},
+			})
+		}
+		if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			add(m.dir(pkg.Name), TestCase{
+				Name:  "Failure",
+				Error: &Result{Message: "Runtime error", Data: strings.Join(pkg.RunError.Output, "\n")},
+			})
+		}
+	}
+	return execs
+}
+
+func createTestCase(test gtr.Test) TestCase {
+	tc := TestCase{Name: test.Name, Duration: timefmt.Millis(test.Duration)}
+	output := strings.Join(test.Output, "\n")
+	switch test.Result.Base() {
+	case gtr.Pass, gtr.Flaky:
+	case gtr.Skip:
+		message := "Skipped"
+		if test.SkipMessage != "" {
+			message = test.SkipMessage
+		}
+		tc.Skipped = &Result{Message: message, Data: output}
+	case gtr.Fail:
+		message := test.FailureReason()
+		if message == "" {
+			message = "Failed"
+		}
+		tc.Failure = &Result{Message: message, Data: output}
+	default:
+		tc.Error = &Result{Message: "No test result found", Data: output}
+	}
+	return tc
+}
+
+// WriteXML writes the XML representation of TestExecutions t to writer w.
+func (t *TestExecutions) WriteXML(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(t); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,120 @@
+package sonarqube
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMappingFile(t *testing.T) {
+	m := Mapping{
+		"example.com/mod":              ".",
+		"example.com/mod/pkg.TestName": "pkg/name_test.go",
+		"example.com/mod/internal":     "src/internal",
+	}
+
+	tests := []struct {
+		pkg  string
+		test gtr.Test
+		want string
+	}{
+		{"example.com/mod/pkg", gtr.Test{Name: "TestName"}, "pkg/name_test.go"},
+		{"example.com/mod/pkg", gtr.Test{Name: "TestOther", Output: []string{"    other_test.go:12: failed"}}, "pkg/other_test.go"},
+		{"example.com/mod/pkg", gtr.Test{Name: "TestNoOutput"}, "pkg"},
+		{"example.com/mod/pkg", gtr.Test{Name: "TestLocated", File: "pkg/located_test.go", Output: []string{"    other_test.go:12: failed"}}, "pkg/located_test.go"},
+		{"example.com/mod", gtr.Test{Name: "TestRoot", Output: []string{"root_test.go:3: x"}}, "root_test.go"},
+		{"example.com/mod/internal/sub", gtr.Test{Name: "TestSub"}, "src/internal/sub"},
+		{"example.com/other", gtr.Test{Name: "TestOther"}, "example.com/other"},
+	}
+	for _, test := range tests {
+		if got := m.File(test.pkg, test.test); got != test.want {
+			t.Errorf("File(%q, %q) = %q, want %q", test.pkg, test.test.Name, got, test.want)
+		}
+	}
+}
+
+func TestCreateFromReport(t *testing.T) {
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{
+				Name: "example.com/mod/pkg",
+				Tests: []gtr.Test{
+					{Name: "TestPass", Result: gtr.Pass, Duration: 12 * time.Millisecond, Output: []string{"    pass_test.go:5: log"}},
+					{Name: "TestFail", Result: gtr.Fail, FailureMessage: "boom", Output: []string{"    pass_test.go:9: boom"}},
+					{Name: "TestSkip", Result: gtr.Skip, SkipMessage: "not supported"},
+					{Name: "TestUnknown", Result: gtr.Unknown},
+				},
+			},
+			{
+				Name:       "example.com/mod/broken",
+				BuildError: gtr.Error{Name: "example.com/mod/broken", Cause: "[build failed]", Output: []string{"undefined: x"}},
+			},
+		},
+	}
+
+	want := TestExecutions{
+		Version: 1,
+		Files: []File{
+			{
+				Path: "pkg/pass_test.go",
+				TestCases: []TestCase{
+					{Name: "TestPass", Duration: 12},
+					{Name: "TestFail", Failure: &Result{Message: "boom", Data: "    pass_test.go:9: boom"}},
+				},
+			},
+			{
+				Path: "pkg",
+				TestCases: []TestCase{
+					{Name: "TestSkip", Skipped: &Result{Message: "not supported"}},
+					{Name: "TestUnknown", Error: &Result{Message: "No test result found"}},
+				},
+			},
+			{
+				Path: "broken",
+				TestCases: []TestCase{
+					{Name: "example.com/mod/broken", Error: &Result{Message: "[build failed]", Data: "undefined: x"}},
+				},
+			},
+		},
+	}
+
+	got := CreateFromReport(report, Mapping{"example.com/mod": "."})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CreateFromReport result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWriteXML(t *testing.T) {
+	execs := TestExecutions{
+		Version: 1,
+		Files: []File{{
+			Path: "pkg/pass_test.go",
+			TestCases: []TestCase{
+				{Name: "TestPass", Duration: 12},
+				{Name: "TestFail", Failure: &Result{Message: "boom", Data: "got <nil>"}},
+			},
+		}},
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testExecutions version="1">
+	<file path="pkg/pass_test.go">
+		<testCase name="TestPass" duration="12"></testCase>
+		<testCase name="TestFail" duration="0">
+			<failure message="boom">got &lt;nil&gt;</failure>
+		</testCase>
+	</file>
+</testExecutions>
+`
+	var buf bytes.Buffer
+	if err := execs.WriteXML(&buf); err != nil {
+		t.Fatalf("WriteXML failed: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("WriteXML output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,137 @@
+// Package srcloc finds the source locations of tests by parsing the test
+// files of their packages, so that reports can refer to the file and line of
+// each test function even if its output doesn't mention them.
+package srcloc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+// Package describes the source files of a Go package.
+type Package struct {
+	Dir       string   // directory containing the package sources
+	TestFiles []string // test files of the package, relative to Dir
+}
+
+// Sources maps package import paths to their source files.
+type Sources map[string]Package
+
+// ListSources uses `go list` to find the source files of the given packages.
+// Packages that can't be found are left out of the result.
+func ListSources(packages ...string) (Sources, error) {
+	src := make(Sources)
+	if len(packages) == 0 {
+		return src, nil
+	}
+	args := append([]string{"list", "-e", "-json"}, packages...)
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var pkg struct {
+			ImportPath   string
+			Dir          string
+			TestGoFiles  []string
+			XTestGoFiles []string
+		}
+		if err := dec.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("go list: %w", err)
+		}
+		if pkg.Dir == "" {
+			continue
+		}
+		src[pkg.ImportPath] = Package{
+			Dir:       pkg.Dir,
+			TestFiles: append(pkg.TestGoFiles, pkg.XTestGoFiles...),
+		}
+	}
+	return src, nil
+}
+
+// Location is the position of a function declaration.
+type Location struct {
+	File string
+	Line int
+}
+
+// Funcs returns the location of each top-level function declared in the test
+// files of pkg, by function name. Files that can't be parsed are skipped.
+func Funcs(pkg Package) map[string]Location {
+	funcs := make(map[string]Location)
+	fset := token.NewFileSet()
+	for _, name := range pkg.TestFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, 0)
+		if err != nil {
+			continue
+		}
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+				pos := fset.Position(fn.Pos())
+				funcs[fn.Name.Name] = Location{File: pos.Filename, Line: pos.Line}
+			}
+		}
+	}
+	return funcs
+}
+
+// Annotate returns a copy of report r in which the File and Line of every
+// test whose location is unknown are set to the declaration of its test
+// function, using the source files in src, see ListSources.
+// Subtests get the location of their top-level test. File names are made
+// relative to root if they're inside it, and use slashes as separator.
+// Packages missing from src are not annotated.
+func Annotate(r gtr.Report, root string, src Sources) gtr.Report {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return r
+	}
+	annotated := r
+	annotated.Packages = make([]gtr.Package, len(r.Packages))
+	for i, pkg := range r.Packages {
+		source, ok := src[pkg.Name]
+		if !ok || len(pkg.Tests) == 0 {
+			annotated.Packages[i] = pkg
+			continue
+		}
+		funcs := Funcs(source)
+		tests := make([]gtr.Test, len(pkg.Tests))
+		for j, test := range pkg.Tests {
+			if loc, ok := funcs[gtr.TopLevelName(test.Name)]; ok && test.File == "" {
+				test.File, test.Line = relPath(absRoot, loc.File), loc.Line
+			}
+			tests[j] = test
+		}
+		pkg.Tests = tests
+		annotated.Packages[i] = pkg
+	}
+	return annotated
+}
+
+// relPath returns path relative to root if it's inside root, or else path
+// itself, using slashes as separator.
+func relPath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,95 @@
+package srcloc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestListSources(t *testing.T) {
+	const pkg = "github.com/jstemmer/go-junit-report/v2/srcloc"
+	src, err := ListSources(pkg, "example.com/does/not/exist")
+	if err != nil {
+		t.Fatalf("ListSources error: %v", err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := src[pkg].Dir; got != wd {
+		t.Errorf("ListSources package dir = %q, want %q", got, wd)
+	}
+	if got := src[pkg].TestFiles; len(got) == 0 {
+		t.Errorf("ListSources returned no test files")
+	}
+	if len(src) != 1 {
+		t.Errorf("ListSources returned %d packages, want 1", len(src))
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	root, err := ioutil.TempDir("", "srcloc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	dir := filepath.Join(root, "pkg", "db")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"db_test.go":      "package db\n\nimport \"testing\"\n\n// TestQuery tests queries.\nfunc TestQuery(t *testing.T) {\n\tt.Run(\"sub\", func(t *testing.T) {})\n}\n\nfunc (s suite) TestMethod(t *testing.T) {}\n",
+		"example_test.go": "package db_test\n\nfunc ExampleQuery() {}\n\n// func TestCommented(t *testing.T) {}\n",
+		"broken_test.go":  "package db\n\nfunc TestBroken(",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	src := Sources{"example.com/pkg/db": {Dir: dir, TestFiles: []string{"db_test.go", "example_test.go", "broken_test.go"}}}
+
+	report := gtr.Report{Packages: []gtr.Package{
+		{Name: "example.com/pkg/db", Tests: []gtr.Test{
+			{Name: "TestQuery", Result: gtr.Fail},
+			{Name: "TestQuery/sub", Result: gtr.Fail},
+			{Name: "ExampleQuery", Result: gtr.Pass},
+			{Name: "TestKnown", Result: gtr.Pass, File: "known_test.go", Line: 3},
+			{Name: "TestMethod", Result: gtr.Pass},
+			{Name: "TestCommented", Result: gtr.Pass},
+			{Name: "TestBroken", Result: gtr.Pass},
+		}},
+		{Name: "example.com/other", Tests: []gtr.Test{{Name: "TestOther", Result: gtr.Pass}}},
+	}}
+	got := Annotate(report, root, src)
+
+	want := gtr.Report{Packages: []gtr.Package{
+		{Name: "example.com/pkg/db", Tests: []gtr.Test{
+			{Name: "TestQuery", Result: gtr.Fail, File: "pkg/db/db_test.go", Line: 6},
+			{Name: "TestQuery/sub", Result: gtr.Fail, File: "pkg/db/db_test.go", Line: 6},
+			{Name: "ExampleQuery", Result: gtr.Pass, File: "pkg/db/example_test.go", Line: 3},
+			{Name: "TestKnown", Result: gtr.Pass, File: "known_test.go", Line: 3},
+			{Name: "TestMethod", Result: gtr.Pass},
+			{Name: "TestCommented", Result: gtr.Pass},
+			{Name: "TestBroken", Result: gtr.Pass},
+		}},
+		{Name: "example.com/other", Tests: []gtr.Test{{Name: "TestOther", Result: gtr.Pass}}},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Annotate result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if report.Packages[0].Tests[0].File != "" {
+		t.Errorf("Annotate modified its input report")
+	}
+
+	outside := Annotate(report, filepath.Join(root, "other"), src)
+	if got, want := outside.Packages[0].Tests[0].File, filepath.ToSlash(filepath.Join(dir, "db_test.go")); got != want {
+		t.Errorf("Annotate file outside of root = %q, want %q", got, want)
+	}
+}
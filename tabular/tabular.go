@@ -0,0 +1,128 @@
+// Package tabular writes reports as CSV or TSV tables, with one row per test,
+// for import into spreadsheets, databases or data analysis tools.
+//
+// The first row contains the column names. Each test, including subtests, is
+// written as a row. Packages that failed to build or failed outside of a test
+// are written as a row without a test name and with a fail result. Durations
+// are in seconds and coverage is the percentage of covered statements of the
+// package, or empty if unknown.
+package tabular
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/timefmt"
+)
+
+// DefaultColumns are the columns written when no columns are given.
+var DefaultColumns = []string{"package", "test", "result", "duration", "coverage"}
+
+// columns contains the available columns and how their values are obtained.
+var columns = map[string]func(pkg gtr.Package, t gtr.Test) string{
+	"package":  func(pkg gtr.Package, t gtr.Test) string { return pkg.Name },
+	"test":     func(pkg gtr.Package, t gtr.Test) string { return t.Name },
+	"result":   func(pkg gtr.Package, t gtr.Test) string { return strings.ToLower(t.Result.String()) },
+	"duration": func(pkg gtr.Package, t gtr.Test) string { return timefmt.Seconds(t.Duration, -1) },
+	"coverage": func(pkg gtr.Package, t gtr.Test) string {
+		if pkg.Coverage == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(pkg.Coverage, 'f', -1, 64)
+	},
+	"level":           func(pkg gtr.Package, t gtr.Test) string { return strconv.Itoa(t.Level) },
+	"start_time":      func(pkg gtr.Package, t gtr.Test) string { return formatTime(t.StartTime) },
+	"end_time":        func(pkg gtr.Package, t gtr.Test) string { return formatTime(t.EndTime) },
+	"failure_message": func(pkg gtr.Package, t gtr.Test) string { return t.FailureMessage },
+	"skip_message":    func(pkg gtr.Package, t gtr.Test) string { return t.SkipMessage },
+	"output":          func(pkg gtr.Package, t gtr.Test) string { return strings.Join(t.Output, "\n") },
+}
+
+// Columns returns the names of all available columns: package, test, result,
+// duration, coverage, level, start_time, end_time, failure_message,
+// skip_message and output.
+func Columns() []string {
+	return []string{"package", "test", "result", "duration", "coverage", "level",
+		"start_time", "end_time", "failure_message", "skip_message", "output"}
+}
+
+// Options configures how a report is written.
+type Options struct {
+	// Columns are the names of the columns to write, in order. If empty,
+	// DefaultColumns are written.
+	Columns []string
+
+	// Comma is the field separator, such as ',' for CSV or '\t' for TSV.
+	// It's ',' if 0.
+	Comma rune
+}
+
+// Write writes report r as a table to w.
+func Write(w io.Writer, r gtr.Report, opts Options) error {
+	names := opts.Columns
+	if len(names) == 0 {
+		names = DefaultColumns
+	}
+	values := make([]func(gtr.Package, gtr.Test) string, len(names))
+	for i, name := range names {
+		value, ok := columns[name]
+		if !ok {
+			return fmt.Errorf("unknown column %q, available columns: %s", name, strings.Join(Columns(), ", "))
+		}
+		values[i] = value
+	}
+
+	cw := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		cw.Comma = opts.Comma
+	}
+	if err := cw.Write(names); err != nil {
+		return err
+	}
+	row := make([]string, len(names))
+	writeRow := func(pkg gtr.Package, t gtr.Test) error {
+		for i, value := range values {
+			row[i] = value(pkg, t)
+		}
+		return cw.Write(row)
+	}
+	for _, pkg := range r.Packages {
+		if pkg.BuildError.Name != "" {
+			if err := writeRow(pkg, errorTest(pkg.BuildError, "build failed")); err != nil {
+				return err
+			}
+		}
+		if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			if err := writeRow(pkg, errorTest(pkg.RunError, "run failed")); err != nil {
+				return err
+			}
+		}
+		for _, t := range pkg.Tests {
+			if err := writeRow(pkg, t); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// errorTest returns the unnamed failed test representing error e.
+func errorTest(e gtr.Error, msg string) gtr.Test {
+	if reason := e.FailureReason(); reason != "" {
+		msg = reason
+	}
+	return gtr.Test{Result: gtr.Fail, Duration: e.Duration, FailureMessage: msg, Output: e.Output}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
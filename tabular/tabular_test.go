@@ -0,0 +1,88 @@
+package tabular
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+var testReport = gtr.Report{Packages: []gtr.Package{
+	{
+		Name:     "package/one",
+		Coverage: 75.5,
+		Tests: []gtr.Test{
+			{Name: "TestPass", Result: gtr.Pass, Duration: 1500 * time.Millisecond},
+			{Name: "TestPass/sub", Level: 1, Result: gtr.Pass, Duration: time.Second},
+			{Name: "TestFail", Result: gtr.Fail, Duration: 10 * time.Millisecond, FailureMessage: "want 1, got 2", Output: []string{"one_test.go:10: want 1, got 2"}},
+			{Name: "TestSkip", Result: gtr.Skip, SkipMessage: "not today", StartTime: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	},
+	{
+		Name:       "package/two",
+		BuildError: gtr.Error{Name: "package/two", Output: []string{"two.go:1: syntax error"}},
+	},
+}}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			"default",
+			Options{},
+			"package,test,result,duration,coverage\n" +
+				"package/one,TestPass,pass,1.5,75.5\n" +
+				"package/one,TestPass/sub,pass,1,75.5\n" +
+				"package/one,TestFail,fail,0.01,75.5\n" +
+				"package/one,TestSkip,skip,0,75.5\n" +
+				"package/two,,fail,0,\n",
+		},
+		{
+			"tsv with columns",
+			Options{Columns: []string{"test", "level", "start_time", "failure_message", "skip_message", "output"}, Comma: '\t'},
+			"test\tlevel\tstart_time\tfailure_message\tskip_message\toutput\n" +
+				"TestPass\t0\t\t\t\t\n" +
+				"TestPass/sub\t1\t\t\t\t\n" +
+				"TestFail\t0\t\twant 1, got 2\t\tone_test.go:10: want 1, got 2\n" +
+				"TestSkip\t0\t2022-01-01T00:00:00Z\t\tnot today\t\n" +
+				"\t0\t\tbuild failed\t\ttwo.go:1: syntax error\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, testReport, test.opts); err != nil {
+				t.Fatalf("Write error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, buf.String()); diff != "" {
+				t.Errorf("Write output incorrect, diff (-want +got):\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestWriteUnknownColumn(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testReport, Options{Columns: []string{"package", "owner"}}); err == nil {
+		t.Errorf("Write did not return an error for an unknown column")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Write wrote output for an unknown column: %q", buf.String())
+	}
+}
+
+func TestColumns(t *testing.T) {
+	for _, name := range Columns() {
+		if _, ok := columns[name]; !ok {
+			t.Errorf("Columns() contains unknown column %q", name)
+		}
+	}
+	if got, want := len(Columns()), len(columns); got != want {
+		t.Errorf("Columns() returned %d columns, want %d", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+// Package tap writes reports in the Test Anything Protocol (TAP) version 13
+// format.
+//
+// Each package is written as a test point whose subtests are the tests in the
+// package. Subtests of Go tests are nested below their parent test, where
+// every level of nesting is indented by 4 spaces. Skipped tests use the SKIP
+// directive. Known failures, see gtr.MarkKnownFailures, are not ok test points
+// with the TODO directive, so they don't fail the package. Output of failed
+// tests and packages is included as a YAML diagnostic block. Flaky tests,
+// which passed after failing in another attempt, are ok test points with a
+// YAML diagnostic block saying so.
+package tap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/escape"
+)
+
+// node is a test point with its subtests.
+type node struct {
+	name     string
+	ok       bool
+	todo     bool // not ok, but expected to fail
+	children []*node
+
+	directive string
+	message   string
+	attempts  int
+	output    []string
+}
+
+// Write writes the TAP representation of report r to writer w.
+func Write(w io.Writer, r gtr.Report) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "TAP version 13")
+	var root node
+	for _, pkg := range r.Packages {
+		root.children = append(root.children, packageNode(pkg))
+	}
+	writeChildren(bw, &root, "")
+	return bw.Flush()
+}
+
+// packageNode returns the node for package pkg containing all its tests.
+func packageNode(pkg gtr.Package) *node {
+	n := &node{name: pkg.Name, ok: true}
+	for _, tn := range pkg.TestTree() {
+		n.children = append(n.children, treeNode(tn))
+	}
+	for _, test := range pkg.Tests {
+		tn := testNode(test)
+		n.ok = n.ok && (tn.ok || tn.todo)
+	}
+
+	if pkg.BuildError.Name != "" {
+		n.children = append(n.children, &node{name: "build", message: "Build error", output: pkg.BuildError.Output})
+		n.ok = false
+	}
+	if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+		n.children = append(n.children, &node{name: "run", message: "Runtime error", output: pkg.RunError.Output})
+		n.ok = false
+	}
+	return n
+}
+
+// treeNode returns the node for test tn and all its subtests.
+func treeNode(tn *gtr.TestNode) *node {
+	n := testNode(tn.Test)
+	for _, child := range tn.Children {
+		n.children = append(n.children, treeNode(child))
+	}
+	return n
+}
+
+// testNode returns the node for the given test, without any subtests.
+func testNode(test gtr.Test) *node {
+	n := &node{name: test.Name}
+	if knownFailure(test) {
+		n.todo = true
+		n.directive = "TODO"
+		if test.SkipMessage != "" {
+			n.directive += " " + strings.Replace(test.SkipMessage, "\n", " ", -1)
+		}
+		return n
+	}
+	switch test.Result.Base() {
+	case gtr.Pass:
+		n.ok = true
+	case gtr.Skip:
+		n.ok = true
+		n.directive = "SKIP"
+		if test.SkipMessage != "" {
+			n.directive += " " + strings.Replace(test.SkipMessage, "\n", " ", -1)
+		}
+	case gtr.Flaky:
+		n.ok = true
+		n.message = "Flaky: passed after failing in another attempt"
+		n.attempts = len(test.Attempts)
+	case gtr.Fail:
+		n.message = "Failed"
+		if test.FailureMessage != "" {
+			n.message = test.FailureMessage
+		}
+		n.output = test.Output
+	default:
+		n.message = "No test result found"
+		n.output = test.Output
+	}
+	return n
+}
+
+// knownFailure returns true if test was marked as a known failure by
+// gtr.MarkKnownFailures.
+func knownFailure(test gtr.Test) bool {
+	for _, prop := range test.Properties {
+		if prop.Name == "known-failure" && prop.Value == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeChildren writes the subtests of n and the plan, using the given
+// indentation.
+func writeChildren(w io.Writer, n *node, indent string) {
+	for i, child := range n.children {
+		if len(child.children) > 0 {
+			fmt.Fprintf(w, "%s# Subtest: %s\n", indent, escape.Line(child.name))
+			writeChildren(w, child, indent+"    ")
+		}
+		writeTestPoint(w, i+1, child, indent)
+	}
+	fmt.Fprintf(w, "%s1..%d\n", indent, len(n.children))
+}
+
+// writeTestPoint writes the test point line for n, followed by a YAML
+// diagnostic block if n has a message.
+func writeTestPoint(w io.Writer, num int, n *node, indent string) {
+	status := "ok"
+	if !n.ok {
+		status = "not ok"
+	}
+	fmt.Fprintf(w, "%s%s %d - %s", indent, status, num, escape.TAP(n.name))
+	if n.directive != "" {
+		fmt.Fprintf(w, " # %s", n.directive)
+	}
+	fmt.Fprintln(w)
+
+	if n.message == "" {
+		return
+	}
+	fmt.Fprintf(w, "%s  ---\n", indent)
+	fmt.Fprintf(w, "%s  message: %q\n", indent, n.message)
+	if n.attempts > 0 {
+		fmt.Fprintf(w, "%s  attempts: %d\n", indent, n.attempts)
+	}
+	if len(n.output) > 0 {
+		fmt.Fprintf(w, "%s  output: |\n", indent)
+		for _, line := range n.output {
+			fmt.Fprintf(w, "%s    %s\n", indent, line)
+		}
+	}
+	fmt.Fprintf(w, "%s  ...\n", indent)
+}
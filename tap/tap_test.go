@@ -0,0 +1,95 @@
+package tap
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/gtrtest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrite(t *testing.T) {
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{
+				Name: "package/one",
+				Tests: []gtr.Test{
+					{Name: "TestPass", Result: gtr.Pass},
+					{Name: "TestFail", Result: gtr.Fail, Output: []string{"fail_test.go:10: failed"}},
+					{Name: "TestFail/sub#1", Result: gtr.Pass, Level: 1},
+					{Name: "TestFail/sub#2", Result: gtr.Fail, Level: 1},
+					{Name: "TestSkip", Result: gtr.Skip},
+					{Name: "TestFlaky", Result: gtr.Flaky},
+				},
+			},
+			{
+				Name: "package/known",
+				Tests: []gtr.Test{
+					{
+						Name:        "TestKnown",
+						Result:      gtr.Skip,
+						SkipMessage: "known failure: also failed in the baseline",
+						Properties:  []gtr.Property{{Name: "known-failure", Value: "true"}},
+					},
+				},
+			},
+			{
+				Name:       "package/two",
+				BuildError: gtr.Error{Name: "package/two", Output: []string{"undefined: x"}},
+			},
+		},
+	}
+
+	want := `TAP version 13
+# Subtest: package/one
+    ok 1 - TestPass
+    # Subtest: TestFail
+        ok 1 - TestFail/sub\#1
+        not ok 2 - TestFail/sub\#2
+          ---
+          message: "Failed"
+          ...
+        1..2
+    not ok 2 - TestFail
+      ---
+      message: "Failed"
+      output: |
+        fail_test.go:10: failed
+      ...
+    ok 3 - TestSkip # SKIP
+    ok 4 - TestFlaky
+      ---
+      message: "Flaky: passed after failing in another attempt"
+      ...
+    1..4
+not ok 1 - package/one
+# Subtest: package/known
+    not ok 1 - TestKnown # TODO known failure: also failed in the baseline
+    1..1
+ok 2 - package/known
+# Subtest: package/two
+    not ok 1 - build
+      ---
+      message: "Build error"
+      output: |
+        undefined: x
+      ...
+    1..1
+not ok 3 - package/two
+1..3
+`
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Write output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWriteGolden(t *testing.T) {
+	gtrtest.RunGolden(t, "testdata", Write)
+}
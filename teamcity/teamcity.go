@@ -0,0 +1,240 @@
+// Package teamcity writes test results as TeamCity service messages.
+//
+// TeamCity reads service messages such as ##teamcity[testStarted name='...']
+// from the build log to report test results. Write writes the messages for a
+// complete gtr.Report. To show test progress while the tests are still
+// running, an EventWriter writes the messages for each event as soon as it's
+// parsed, see gotest.EventHandler.
+//
+// TeamCity only accepts a flat list of tests, so subtests are reported as
+// separate tests using their full name. Build and runtime errors are reported
+// as failed tests named after the package, with a failed test for each
+// compiler diagnostic of a build error.
+package teamcity
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/escape"
+	"github.com/jstemmer/go-junit-report/v2/internal/timefmt"
+	"github.com/jstemmer/go-junit-report/v2/parser/gotest"
+)
+
+// Write writes the service messages for report r to writer w. Each package is
+// written as a test suite.
+func Write(w io.Writer, r gtr.Report) error {
+	bw := bufio.NewWriter(w)
+	for _, pkg := range r.Packages {
+		writeMessage(bw, "testSuiteStarted", "name", pkg.Name)
+		for _, test := range pkg.Tests {
+			writeMessage(bw, "testStarted", "name", test.Name)
+			switch test.Result.Base() {
+			case gtr.Fail:
+				writeMessage(bw, "testFailed", "name", test.Name, "message", test.FailureReason(), "details", strings.Join(test.Output, "\n"))
+			case gtr.Skip:
+				writeMessage(bw, "testIgnored", "name", test.Name, "message", test.SkipMessage)
+			case gtr.Unknown:
+				writeMessage(bw, "testFailed", "name", test.Name, "message", "No test result found", "details", strings.Join(test.Output, "\n"))
+			}
+			writeMessage(bw, "testFinished", "name", test.Name, "duration", millis(test.Duration))
+		}
+		if pkg.BuildError.Name != "" {
+			writeError(bw, pkg.Name, "Build error", pkg.BuildError)
+		}
+		if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			writeError(bw, pkg.Name, "Runtime error", pkg.RunError)
+		}
+		writeMessage(bw, "testSuiteFinished", "name", pkg.Name)
+	}
+	return bw.Flush()
+}
+
+func writeError(w io.Writer, pkgName, name string, e gtr.Error) {
+	message := e.FailureReason()
+	if len(e.Diagnostics) > 0 {
+		for _, d := range e.Diagnostics {
+			name := pkgName + ": " + d.Location()
+			writeMessage(w, "testStarted", "name", name)
+			writeMessage(w, "testFailed", "name", name, "message", message, "details", d.String())
+			writeMessage(w, "testFinished", "name", name, "duration", millis(0))
+		}
+		return
+	}
+	name = pkgName + ": " + name
+	writeMessage(w, "testStarted", "name", name)
+	writeMessage(w, "testFailed", "name", name, "message", message, "details", strings.Join(e.Output, "\n"))
+	writeMessage(w, "testFinished", "name", name, "duration", millis(e.Duration))
+}
+
+// EventWriter writes service messages for test events while they're being
+// parsed. Tests are started when their first event is seen and finished when
+// their result is known. Output is attributed to the test that most recently
+// started or continued running, and included in the details of failed tests.
+//
+// Only the events themselves are used, so options that change the report after
+// parsing, such as overriding test results, don't affect the messages written
+// by an EventWriter.
+type EventWriter struct {
+	w   io.Writer
+	err error
+
+	running map[string]*runningTest // running tests by package and name
+	order   []string                // keys of running tests in the order they started
+	active  map[string]string       // key of the active test by package
+}
+
+type runningTest struct {
+	name   string
+	output []string
+}
+
+// NewEventWriter returns a new EventWriter that writes service messages to w.
+func NewEventWriter(w io.Writer) *EventWriter {
+	return &EventWriter{
+		w:       w,
+		running: make(map[string]*runningTest),
+		active:  make(map[string]string),
+	}
+}
+
+// HandleEvent writes the service messages for the given event. It can be used
+// as the handler of the gotest.EventHandler option.
+func (ew *EventWriter) HandleEvent(ev gotest.Event) {
+	switch ev.Type {
+	case "run_test", "run_benchmark":
+		ew.start(ev.Package, ev.Name)
+	case "cont_test":
+		if _, ok := ew.running[key(ev.Package, ev.Name)]; ok {
+			ew.active[ev.Package] = key(ev.Package, ev.Name)
+		}
+	case "end_test", "end_benchmark":
+		t := ew.start(ev.Package, ev.Name)
+		if ev.Data != "" {
+			t.output = append(t.output, ev.Data)
+		}
+		switch parseResult(ev.Result) {
+		case gtr.Fail:
+			ew.message("testFailed", "name", t.name, "message", failure(t.output), "details", strings.Join(t.output, "\n"), "flowId", t.name)
+		case gtr.Skip:
+			ew.message("testIgnored", "name", t.name, "message", strings.Join(t.output, "\n"), "flowId", t.name)
+		}
+		ew.finish(ev.Package, ev.Name, ev.Duration)
+	case "benchmark":
+		// A benchmark prints its results once it has finished successfully.
+		ew.start(ev.Package, ev.Name)
+		ew.finish(ev.Package, ev.Name, 0)
+	case "output":
+		if t, ok := ew.running[ew.active[ev.Package]]; ok {
+			t.output = append(t.output, ev.Data)
+		}
+	case "build_output":
+		ew.message("buildProblem", "description", ev.Name+": build failed")
+	}
+}
+
+// Finish fails and finishes all tests that were started but never finished,
+// for example because the test binary crashed. It returns the first error
+// that occurred while writing service messages.
+func (ew *EventWriter) Finish() error {
+	for _, k := range ew.order {
+		t, ok := ew.running[k]
+		if !ok {
+			continue
+		}
+		ew.message("testFailed", "name", t.name, "message", "No test result found", "details", strings.Join(t.output, "\n"), "flowId", t.name)
+		ew.message("testFinished", "name", t.name, "flowId", t.name)
+		delete(ew.running, k)
+	}
+	ew.order = nil
+	return ew.err
+}
+
+// start starts the test with the given name, unless it's already running, and
+// makes it the active test of its package.
+func (ew *EventWriter) start(pkg, name string) *runningTest {
+	k := key(pkg, name)
+	t, ok := ew.running[k]
+	if !ok {
+		t = &runningTest{name: name}
+		if pkg != "" {
+			t.name = pkg + "." + name
+		}
+		ew.running[k] = t
+		ew.order = append(ew.order, k)
+		ew.message("testStarted", "name", t.name, "captureStandardOutput", "false", "flowId", t.name)
+	}
+	ew.active[pkg] = k
+	return t
+}
+
+func (ew *EventWriter) finish(pkg, name string, d time.Duration) {
+	k := key(pkg, name)
+	t, ok := ew.running[k]
+	if !ok {
+		return
+	}
+	ew.message("testFinished", "name", t.name, "duration", millis(d), "flowId", t.name)
+	delete(ew.running, k)
+	if ew.active[pkg] == k {
+		delete(ew.active, pkg)
+	}
+}
+
+func (ew *EventWriter) message(name string, attrs ...string) {
+	if ew.err == nil {
+		ew.err = writeMessage(ew.w, name, attrs...)
+	}
+}
+
+func key(pkg, name string) string {
+	return pkg + "\x00" + name
+}
+
+// failure returns the failure message in the output of a failed test.
+func failure(output []string) string {
+	for _, e := range []gotest.FailureExtractor{gotest.TestifyExtractor, gotest.LogLineExtractor} {
+		if message, _, ok := e.ExtractFailure(output); ok {
+			return message
+		}
+	}
+	return ""
+}
+
+// parseResult returns the result of a test result string as printed by go
+// test.
+func parseResult(r string) gtr.Result {
+	switch r {
+	case "PASS":
+		return gtr.Pass
+	case "FAIL":
+		return gtr.Fail
+	case "SKIP":
+		return gtr.Skip
+	default:
+		return gtr.Unknown
+	}
+}
+
+func millis(d time.Duration) string {
+	return strconv.FormatInt(timefmt.Millis(d), 10)
+}
+
+// writeMessage writes a service message with the given name and attributes,
+// which are given as name, value pairs.
+func writeMessage(w io.Writer, name string, attrs ...string) error {
+	var sb strings.Builder
+	sb.WriteString("##teamcity[")
+	sb.WriteString(name)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		fmt.Fprintf(&sb, " %s='%s'", attrs[i], escape.TeamCity(attrs[i+1]))
+	}
+	sb.WriteString("]\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
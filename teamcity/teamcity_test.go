@@ -0,0 +1,150 @@
+package teamcity
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/parser/gotest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrite(t *testing.T) {
+	report := gtr.Report{
+		Packages: []gtr.Package{
+			{
+				Name: "package/name",
+				Tests: []gtr.Test{
+					{Name: "TestPass", Result: gtr.Pass, Duration: 12 * time.Millisecond},
+					{Name: "TestFail", Result: gtr.Fail, FailureMessage: "it's broken", Output: []string{"    fail_test.go:3: it's broken"}},
+					{Name: "TestSkip", Result: gtr.Skip, SkipMessage: "[not supported]"},
+				},
+			},
+			{
+				Name:       "package/broken",
+				BuildError: gtr.Error{Name: "package/broken", Cause: "[build failed]", Output: []string{"undefined: x"}},
+			},
+			{
+				Name: "package/typo",
+				BuildError: gtr.Error{
+					Name:        "package/typo",
+					Cause:       "[build failed]",
+					Output:      []string{"typo.go:3:5: undefined: y"},
+					Diagnostics: []gtr.Diagnostic{{File: "typo.go", Line: 3, Column: 5, Message: "undefined: y"}},
+				},
+			},
+		},
+	}
+
+	want := strings.Join([]string{
+		"##teamcity[testSuiteStarted name='package/name']",
+		"##teamcity[testStarted name='TestPass']",
+		"##teamcity[testFinished name='TestPass' duration='12']",
+		"##teamcity[testStarted name='TestFail']",
+		"##teamcity[testFailed name='TestFail' message='it|'s broken' details='    fail_test.go:3: it|'s broken']",
+		"##teamcity[testFinished name='TestFail' duration='0']",
+		"##teamcity[testStarted name='TestSkip']",
+		"##teamcity[testIgnored name='TestSkip' message='|[not supported|]']",
+		"##teamcity[testFinished name='TestSkip' duration='0']",
+		"##teamcity[testSuiteFinished name='package/name']",
+		"##teamcity[testSuiteStarted name='package/broken']",
+		"##teamcity[testStarted name='package/broken: Build error']",
+		"##teamcity[testFailed name='package/broken: Build error' message='|[build failed|]' details='undefined: x']",
+		"##teamcity[testFinished name='package/broken: Build error' duration='0']",
+		"##teamcity[testSuiteFinished name='package/broken']",
+		"##teamcity[testSuiteStarted name='package/typo']",
+		"##teamcity[testStarted name='package/typo: typo.go:3:5']",
+		"##teamcity[testFailed name='package/typo: typo.go:3:5' message='|[build failed|]' details='typo.go:3:5: undefined: y']",
+		"##teamcity[testFinished name='package/typo: typo.go:3:5' duration='0']",
+		"##teamcity[testSuiteFinished name='package/typo']",
+		"",
+	}, "\n")
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Write output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestEventWriter(t *testing.T) {
+	input := strings.Join([]string{
+		"=== RUN   TestOne",
+		"=== PAUSE TestOne",
+		"=== RUN   TestTwo",
+		"    two_test.go:5: broken",
+		"--- FAIL: TestTwo (0.02s)",
+		"=== CONT  TestOne",
+		"    one_test.go:3: log",
+		"--- PASS: TestOne (0.01s)",
+		"=== RUN   TestCrash",
+		"FAIL\tpackage/name\t0.030s",
+	}, "\n")
+
+	var buf bytes.Buffer
+	ew := NewEventWriter(&buf)
+	var lines []int
+	p := gotest.NewParser(gotest.EventHandler(func(ev gotest.Event) {
+		ew.HandleEvent(ev)
+		lines = append(lines, strings.Count(buf.String(), "\n"))
+	}))
+	for _, line := range strings.Split(input, "\n") {
+		p.ParseLine(line)
+	}
+	p.Flush()
+	if err := ew.Finish(); err != nil {
+		t.Fatalf("Finish error: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"##teamcity[testStarted name='TestOne' captureStandardOutput='false' flowId='TestOne']",
+		"##teamcity[testStarted name='TestTwo' captureStandardOutput='false' flowId='TestTwo']",
+		"##teamcity[testFailed name='TestTwo' message='broken' details='    two_test.go:5: broken' flowId='TestTwo']",
+		"##teamcity[testFinished name='TestTwo' duration='20' flowId='TestTwo']",
+		"##teamcity[testFinished name='TestOne' duration='10' flowId='TestOne']",
+		"##teamcity[testStarted name='TestCrash' captureStandardOutput='false' flowId='TestCrash']",
+		"##teamcity[testFailed name='TestCrash' message='No test result found' details='' flowId='TestCrash']",
+		"##teamcity[testFinished name='TestCrash' flowId='TestCrash']",
+		"",
+	}, "\n")
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("EventWriter output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	// Messages must be written as soon as the test starts, not once the input
+	// has been parsed completely.
+	if len(lines) == 0 || lines[0] != 1 {
+		t.Errorf("EventWriter did not write testStarted after the first event, lines written after each event: %v", lines)
+	}
+}
+
+func TestEventWriterPackages(t *testing.T) {
+	var buf bytes.Buffer
+	ew := NewEventWriter(&buf)
+	ew.HandleEvent(gotest.Event{Type: "run_test", Package: "package/one", Name: "TestOne"})
+	ew.HandleEvent(gotest.Event{Type: "run_test", Package: "package/two", Name: "TestOne"})
+	ew.HandleEvent(gotest.Event{Type: "end_test", Package: "package/one", Name: "TestOne", Result: "SKIP", Data: "not now"})
+	ew.HandleEvent(gotest.Event{Type: "end_test", Package: "package/two", Name: "TestOne", Result: "PASS"})
+	ew.HandleEvent(gotest.Event{Type: "build_output", Name: "package/three"})
+	if err := ew.Finish(); err != nil {
+		t.Fatalf("Finish error: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"##teamcity[testStarted name='package/one.TestOne' captureStandardOutput='false' flowId='package/one.TestOne']",
+		"##teamcity[testStarted name='package/two.TestOne' captureStandardOutput='false' flowId='package/two.TestOne']",
+		"##teamcity[testIgnored name='package/one.TestOne' message='not now' flowId='package/one.TestOne']",
+		"##teamcity[testFinished name='package/one.TestOne' duration='0' flowId='package/one.TestOne']",
+		"##teamcity[testFinished name='package/two.TestOne' duration='0' flowId='package/two.TestOne']",
+		"##teamcity[buildProblem description='package/three: build failed']",
+		"",
+	}, "\n")
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("EventWriter output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -11,16 +11,27 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jstemmer/go-junit-report/v2/gtr"
 	"github.com/jstemmer/go-junit-report/v2/internal/gojunitreport"
 )
 
 var verbose bool
 
 var configs = map[string]gojunitreport.Config{
-	"005-no-xml-header.txt": {SkipXMLHeader: true},
-	"006-mixed.txt":         {SkipXMLHeader: true},
-	"007-compiled_test.txt": {PackageName: "test/package"},
-	"039-no-properties.txt": {Properties: make(map[string]string)},
+	"005-no-xml-header.txt":       {SkipXMLHeader: true},
+	"006-mixed.txt":               {SkipXMLHeader: true},
+	"007-compiled_test.txt":       {PackageName: "test/package"},
+	"039-no-properties.txt":       {Properties: make(map[string]string)},
+	"040-override.txt":            {Overrides: map[string]gtr.Result{"TestBroken": gtr.Skip}},
+	"042-xml-stylesheet.txt":      {XMLStylesheet: "report.xsl"},
+	"043-sort-failures-first.txt": {Sort: gojunitreport.SortFailuresFirst},
+	"044-output-size.txt":         {EmitOutputSize: true},
+	"049-emit-ids.txt":            {EmitIDs: true},
+	"052-deep-subtests.txt":       {MaxSubtestDepth: 2},
+	"053-failfast.txt":            {Failfast: true},
+	"054-wall-duration.txt":       {WallDuration: 2500 * time.Millisecond},
+	"056-flaky.txt":               {GroupAttempts: true},
+	"061-slow-tests.txt":          {SlowThreshold: 30 * time.Second, FailSlowTests: true},
 }
 
 func main() {
@@ -99,6 +110,8 @@ func createReportFromInput(inputFile, outputFile string, write bool) error {
 	config.Parser = "gotest"
 	if strings.HasSuffix(inputFile, ".gojson.txt") {
 		config.Parser = "gojson"
+	} else if strings.HasSuffix(inputFile, ".ginkgo.txt") {
+		config.Parser = "ginkgo"
 	}
 
 	config.Hostname = "hostname"
@@ -0,0 +1,227 @@
+// Package timing stores the durations of tests, and uses them to split tests
+// into shards that take about the same time to run.
+//
+// Timing data only contains top-level tests, since those are the tests that
+// can be selected using the -run flag of go test. It's stored as JSON in a
+// stable format, see Data, so it can be kept as a CI artifact or cache and be
+// updated after every run, see Update.
+package timing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/rerun"
+)
+
+// Version is the version of the format written by Data.WriteJSON.
+const Version = 1
+
+// Data contains the durations of tests, sorted by package and test name.
+type Data struct {
+	Version int    `json:"version"`
+	Tests   []Test `json:"tests"`
+}
+
+// Test is the duration of a single top-level test.
+type Test struct {
+	Package  string        `json:"package"`
+	Test     string        `json:"test"`
+	Duration time.Duration `json:"duration_nanos"`
+}
+
+// Ref returns the package and name of the test.
+func (t Test) Ref() gtr.TestRef {
+	return gtr.TestRef{Package: t.Package, Test: t.Test}
+}
+
+// FromReport returns the timing data of the top-level tests in report r.
+// Benchmarks are not included, as they're not selected by the -run flag, and
+// neither are tests without a result.
+func FromReport(r gtr.Report) Data {
+	d := Data{Version: Version}
+	for _, pkg := range r.Packages {
+		for _, t := range pkg.Tests {
+			if strings.IndexByte(t.Name, '/') >= 0 || strings.HasPrefix(t.Name, "Benchmark") || t.Result == gtr.Unknown {
+				continue
+			}
+			d.Tests = append(d.Tests, Test{Package: pkg.Name, Test: t.Name, Duration: t.Duration})
+		}
+	}
+	d.sort()
+	return d
+}
+
+func (d *Data) sort() {
+	sort.SliceStable(d.Tests, func(i, j int) bool {
+		if d.Tests[i].Package != d.Tests[j].Package {
+			return d.Tests[i].Package < d.Tests[j].Package
+		}
+		return d.Tests[i].Test < d.Tests[j].Test
+	})
+}
+
+// Update returns the timing data in base updated with the durations in
+// update. Tests that are only in base keep their duration, so that timing data
+// can be updated after runs of a subset of the tests.
+func Update(base, update Data) Data {
+	index := make(map[gtr.TestRef]bool)
+	d := Data{Version: Version}
+	for _, t := range update.Tests {
+		index[t.Ref()] = true
+		d.Tests = append(d.Tests, t)
+	}
+	for _, t := range base.Tests {
+		if !index[t.Ref()] {
+			d.Tests = append(d.Tests, t)
+		}
+	}
+	d.sort()
+	return d
+}
+
+// Durations returns the duration of each test in d.
+func (d Data) Durations() map[gtr.TestRef]time.Duration {
+	durations := make(map[gtr.TestRef]time.Duration, len(d.Tests))
+	for _, t := range d.Tests {
+		durations[t.Ref()] = t.Duration
+	}
+	return durations
+}
+
+// ReadJSON reads timing data written by Data.WriteJSON from r.
+func ReadJSON(r io.Reader) (Data, error) {
+	var d Data
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return Data{}, err
+	}
+	if d.Version > Version {
+		return Data{}, fmt.Errorf("unsupported timing data version %d", d.Version)
+	}
+	return d, nil
+}
+
+// WriteJSON writes d as indented JSON to w.
+func (d Data) WriteJSON(w io.Writer) error {
+	d.Version = Version
+	if d.Tests == nil {
+		d.Tests = []Test{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(d)
+}
+
+// Shard is a subset of tests that are run together.
+type Shard struct {
+	Tests    []gtr.TestRef // sorted by package and test name
+	Duration time.Duration // expected duration of the tests
+}
+
+// Packages returns the tests in shard s grouped by package, for example to
+// write the -run patterns of each package, see rerun.Package.Pattern.
+func (s Shard) Packages() []rerun.Package {
+	var pkgs []rerun.Package
+	for _, t := range s.Tests {
+		if len(pkgs) == 0 || pkgs[len(pkgs)-1].Name != t.Package {
+			pkgs = append(pkgs, rerun.Package{Name: t.Package})
+		}
+		p := &pkgs[len(pkgs)-1]
+		p.Tests = append(p.Tests, t.Test)
+	}
+	return pkgs
+}
+
+// Write writes a line containing the package name and -run pattern for each
+// package in shard s to w, in the format used by package rerun.
+func (s Shard) Write(w io.Writer) error {
+	for _, p := range s.Packages() {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", p.Name, p.Pattern()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Split partitions the given tests into n shards with about the same
+// expected duration, using the durations in d. Tests that are missing from d
+// are assumed to take the average duration of the tests in d. If tests is
+// nil, all tests in d are split.
+//
+// Tests are assigned longest first to the shard with the lowest expected
+// duration so far, so the result is deterministic for the same timing data.
+// Shards may be empty when there are fewer tests than shards.
+func Split(d Data, tests []gtr.TestRef, n int) []Shard {
+	if n < 1 {
+		n = 1
+	}
+	durations := d.Durations()
+	if tests == nil {
+		for _, t := range d.Tests {
+			tests = append(tests, t.Ref())
+		}
+	}
+
+	var avg time.Duration
+	if len(d.Tests) > 0 {
+		var total time.Duration
+		for _, t := range d.Tests {
+			total += t.Duration
+		}
+		avg = total / time.Duration(len(d.Tests))
+	}
+
+	type item struct {
+		ref      gtr.TestRef
+		duration time.Duration
+	}
+	seen := make(map[gtr.TestRef]bool)
+	var items []item
+	for _, ref := range tests {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		duration, ok := durations[ref]
+		if !ok {
+			duration = avg
+		}
+		items = append(items, item{ref, duration})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].duration != items[j].duration {
+			return items[i].duration > items[j].duration
+		}
+		if items[i].ref.Package != items[j].ref.Package {
+			return items[i].ref.Package < items[j].ref.Package
+		}
+		return items[i].ref.Test < items[j].ref.Test
+	})
+
+	shards := make([]Shard, n)
+	for _, it := range items {
+		min := 0
+		for i := 1; i < n; i++ {
+			if shards[i].Duration < shards[min].Duration {
+				min = i
+			}
+		}
+		shards[min].Tests = append(shards[min].Tests, it.ref)
+		shards[min].Duration += it.duration
+	}
+	for i := range shards {
+		tests := shards[i].Tests
+		sort.Slice(tests, func(a, b int) bool {
+			if tests[a].Package != tests[b].Package {
+				return tests[a].Package < tests[b].Package
+			}
+			return tests[a].Test < tests[b].Test
+		})
+	}
+	return shards
+}
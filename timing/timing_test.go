@@ -0,0 +1,156 @@
+package timing
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+)
+
+func TestFromReport(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{
+		{
+			Name: "package/two",
+			Tests: []gtr.Test{
+				{Name: "TestB", Result: gtr.Pass, Duration: 2 * time.Second},
+				{Name: "TestA", Result: gtr.Fail, Duration: time.Second},
+				{Name: "TestA/sub", Result: gtr.Fail, Duration: time.Second},
+				{Name: "TestTimeout", Result: gtr.Unknown, Duration: time.Minute},
+				{Name: "BenchmarkA", Result: gtr.Pass, Duration: time.Second},
+			},
+		},
+		{
+			Name:  "package/one",
+			Tests: []gtr.Test{{Name: "TestC", Result: gtr.Skip}},
+		},
+	}}
+
+	want := Data{Version: Version, Tests: []Test{
+		{Package: "package/one", Test: "TestC"},
+		{Package: "package/two", Test: "TestA", Duration: time.Second},
+		{Package: "package/two", Test: "TestB", Duration: 2 * time.Second},
+	}}
+	got := FromReport(report)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("FromReport result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	old := Data{Version: Version, Tests: []Test{
+		{Package: "package/one", Test: "TestA", Duration: time.Second},
+		{Package: "package/one", Test: "TestB", Duration: time.Second},
+	}}
+	update := Data{Version: Version, Tests: []Test{
+		{Package: "package/one", Test: "TestB", Duration: 3 * time.Second},
+		{Package: "package/one", Test: "TestAB", Duration: 2 * time.Second},
+	}}
+
+	want := Data{Version: Version, Tests: []Test{
+		{Package: "package/one", Test: "TestA", Duration: time.Second},
+		{Package: "package/one", Test: "TestAB", Duration: 2 * time.Second},
+		{Package: "package/one", Test: "TestB", Duration: 3 * time.Second},
+	}}
+	got := Update(old, update)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Update result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data := Data{Version: Version, Tests: []Test{{Package: "package/one", Test: "TestA", Duration: 1500 * time.Millisecond}}}
+
+	var buf bytes.Buffer
+	if err := data.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON error: %v", err)
+	}
+	want := `{
+	"version": 1,
+	"tests": [
+		{
+			"package": "package/one",
+			"test": "TestA",
+			"duration_nanos": 1500000000
+		}
+	]
+}
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("WriteJSON output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	got, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSON error: %v", err)
+	}
+	if diff := cmp.Diff(data, got); diff != "" {
+		t.Errorf("ReadJSON result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	if _, err := ReadJSON(strings.NewReader(`{"version": 2}`)); err == nil {
+		t.Errorf("ReadJSON did not return an error for an unsupported version")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	data := Data{Version: Version, Tests: []Test{
+		{Package: "package/one", Test: "TestA", Duration: 8 * time.Second},
+		{Package: "package/one", Test: "TestB", Duration: 4 * time.Second},
+		{Package: "package/one", Test: "TestC", Duration: 3 * time.Second},
+		{Package: "package/two", Test: "TestD", Duration: 3 * time.Second},
+		{Package: "package/two", Test: "TestE", Duration: 2 * time.Second},
+	}}
+
+	want := []Shard{
+		{
+			Tests:    []gtr.TestRef{{Package: "package/one", Test: "TestA"}, {Package: "package/two", Test: "TestE"}},
+			Duration: 10 * time.Second,
+		},
+		{
+			Tests:    []gtr.TestRef{{Package: "package/one", Test: "TestB"}, {Package: "package/one", Test: "TestC"}, {Package: "package/two", Test: "TestD"}},
+			Duration: 10 * time.Second,
+		},
+	}
+	got := Split(data, nil, 2)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Split result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	// TestNew is missing from the timing data and takes the average of 4s.
+	tests := []gtr.TestRef{
+		{Package: "package/one", Test: "TestA"},
+		{Package: "package/new", Test: "TestNew"},
+		{Package: "package/two", Test: "TestE"},
+		{Package: "package/one", Test: "TestA"},
+	}
+	want = []Shard{
+		{Tests: []gtr.TestRef{{Package: "package/one", Test: "TestA"}}, Duration: 8 * time.Second},
+		{Tests: []gtr.TestRef{{Package: "package/new", Test: "TestNew"}}, Duration: 4 * time.Second},
+		{Tests: []gtr.TestRef{{Package: "package/two", Test: "TestE"}}, Duration: 2 * time.Second},
+		{},
+	}
+	got = Split(data, tests, 4)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Split with tests result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestShardWrite(t *testing.T) {
+	shard := Shard{Tests: []gtr.TestRef{
+		{Package: "package/one", Test: "TestA"},
+		{Package: "package/one", Test: "TestB"},
+		{Package: "package/two", Test: "TestC"},
+	}}
+
+	var buf bytes.Buffer
+	if err := shard.Write(&buf); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	want := "package/one\t^TestA$|^TestB$\npackage/two\t^TestC$\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("Write output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
@@ -0,0 +1,196 @@
+// Package tmpl writes reports using custom Go templates, for formats that
+// aren't supported by go-junit-report itself.
+//
+// Templates are executed with a Context, which contains the report, its
+// summary and its failed tests. Templates parsed by Parse and ParseFile can
+// use the functions in Funcs in addition to the predefined template
+// functions. For example, the following template writes a line for each
+// failed test:
+//
+//	{{range .Failures}}{{.Package}}.{{.Test.Name}} failed after {{duration .Test.Duration}}
+//	{{end}}{{.Summary.Failed}} of {{.Summary.Tests}} tests failed
+package tmpl
+
+import (
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/timefmt"
+)
+
+// Template is a parsed template, such as a *text/template.Template or a
+// *html/template.Template.
+type Template interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// Context is the data a template is executed with.
+type Context struct {
+	Report    gtr.Report
+	Summary   gtr.Summary
+	Failures  []Failure // failed tests and tests without a result, in report order
+	Timestamp time.Time // time the report was created
+}
+
+// Failure is a failed test of a package.
+type Failure struct {
+	Package string
+	Test    gtr.Test
+}
+
+// NewContext returns the context of report r created at the given time.
+func NewContext(r gtr.Report, timestamp time.Time) Context {
+	c := Context{Report: r, Summary: r.Summary(), Timestamp: timestamp}
+	for _, pkg := range r.Packages {
+		for _, t := range pkg.Tests {
+			if t.Result.Base() == gtr.Fail || t.Result == gtr.Unknown {
+				c.Failures = append(c.Failures, Failure{Package: pkg.Name, Test: t})
+			}
+		}
+	}
+	return c
+}
+
+// Write executes template t with the context of report r created at the
+// given time, and writes the result to w.
+func Write(w io.Writer, t Template, r gtr.Report, timestamp time.Time) error {
+	return t.Execute(w, NewContext(r, timestamp))
+}
+
+// Parse parses the template with the given name and text. If html is set,
+// the template is parsed by html/template, which escapes the values it writes
+// for use in HTML documents, instead of text/template.
+func Parse(name, text string, html bool) (Template, error) {
+	if html {
+		return htmltemplate.New(name).Funcs(htmltemplate.FuncMap(Funcs())).Parse(text)
+	}
+	return template.New(name).Funcs(Funcs()).Parse(text)
+}
+
+// ParseFile parses the template in the file with the given filename. Files
+// with an .html or .htm extension are parsed by html/template.
+func ParseFile(filename string) (Template, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	return Parse(filepath.Base(filename), string(data), ext == ".html" || ext == ".htm")
+}
+
+// Funcs returns the functions that can be used in templates parsed by Parse:
+//
+//	duration d           d rounded to milliseconds, e.g. 1.5s
+//	seconds d            d in seconds with 3 decimals, e.g. 1.500
+//	percent n total      n as a percentage of total with 1 decimal, e.g. 12.5%
+//	sortTests by tests   a copy of tests sorted by name, duration (longest first) or result (worst first)
+//	sortPackages by pkgs a copy of pkgs sorted by name, duration (longest first) or coverage (lowest first)
+//	withResult r tests   the tests whose result, or base result, is r, e.g. fail
+//	json v               v encoded as JSON
+//	join lines sep       the lines joined by sep
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"duration":     formatDuration,
+		"seconds":      formatSeconds,
+		"percent":      percent,
+		"sortTests":    sortTests,
+		"sortPackages": sortPackages,
+		"withResult":   withResult,
+		"json":         jsonString,
+		"join":         strings.Join,
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
+
+func formatSeconds(d time.Duration) string {
+	return timefmt.Seconds(d, 3)
+}
+
+func percent(n, total int) string {
+	if total == 0 {
+		return "0.0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(n)*100/float64(total))
+}
+
+func sortTests(by string, tests []gtr.Test) ([]gtr.Test, error) {
+	var less func(a, b gtr.Test) bool
+	switch by {
+	case "name":
+		less = func(a, b gtr.Test) bool { return a.Name < b.Name }
+	case "duration":
+		less = func(a, b gtr.Test) bool { return a.Duration > b.Duration }
+	case "result":
+		less = func(a, b gtr.Test) bool { return resultRank(a.Result) < resultRank(b.Result) }
+	default:
+		return nil, fmt.Errorf("sortTests: unknown order %q", by)
+	}
+	sorted := append([]gtr.Test(nil), tests...)
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	return sorted, nil
+}
+
+func sortPackages(by string, pkgs []gtr.Package) ([]gtr.Package, error) {
+	var less func(a, b gtr.Package) bool
+	switch by {
+	case "name":
+		less = func(a, b gtr.Package) bool { return a.Name < b.Name }
+	case "duration":
+		less = func(a, b gtr.Package) bool { return a.Duration > b.Duration }
+	case "coverage":
+		less = func(a, b gtr.Package) bool { return a.Coverage < b.Coverage }
+	default:
+		return nil, fmt.Errorf("sortPackages: unknown order %q", by)
+	}
+	sorted := append([]gtr.Package(nil), pkgs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	return sorted, nil
+}
+
+// resultRank returns the position of result r when sorting tests by result,
+// the worst results first.
+func resultRank(r gtr.Result) int {
+	switch r.Base() {
+	case gtr.Fail:
+		return 0
+	case gtr.Unknown:
+		return 1
+	case gtr.Flaky:
+		return 2
+	case gtr.Pass:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func withResult(name string, tests []gtr.Test) ([]gtr.Test, error) {
+	result, err := gtr.ParseResult(name)
+	if err != nil {
+		return nil, err
+	}
+	var matched []gtr.Test
+	for _, t := range tests {
+		if t.Result == result || t.Result.Base() == result {
+			matched = append(matched, t)
+		}
+	}
+	return matched, nil
+}
+
+func jsonString(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
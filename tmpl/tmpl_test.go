@@ -0,0 +1,106 @@
+package tmpl
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// resultTimedOut is a result with base result gtr.Fail used to test withResult.
+var resultTimedOut = gtr.RegisterResult("timedout", gtr.Fail)
+
+var testReport = gtr.Report{Packages: []gtr.Package{
+	{Name: "package/one", Duration: 2 * time.Second, Coverage: 80, Tests: []gtr.Test{
+		{Name: "TestB", Result: gtr.Pass, Duration: 1500 * time.Millisecond},
+		{Name: "TestA", Result: gtr.Fail, Duration: 250 * time.Millisecond},
+		{Name: "TestC", Result: resultTimedOut, Duration: 2 * time.Second},
+	}},
+	{Name: "package/two", Duration: 5 * time.Second, Coverage: 20, Tests: []gtr.Test{
+		{Name: "TestD", Result: gtr.Skip},
+	}},
+}}
+
+func TestWrite(t *testing.T) {
+	timestamp := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		text string
+		html bool
+		want string
+	}{
+		{"failures", "{{range .Failures}}{{.Package}}.{{.Test.Name}} {{duration .Test.Duration}}\n{{end}}", false,
+			"package/one.TestA 250ms\npackage/one.TestC 2s\n"},
+		{"summary", `{{.Timestamp.Format "2006-01-02"}}: {{percent .Summary.Passed .Summary.Tests}} passed in {{seconds .Summary.Duration}}s`, false,
+			"2022-01-01: 25.0% passed in 7.000s"},
+		{"sort-tests", `{{range sortTests "duration" (index .Report.Packages 0).Tests}}{{.Name}} {{end}}` +
+			`{{range sortTests "name" (index .Report.Packages 0).Tests}}{{.Name}} {{end}}` +
+			`{{range sortTests "result" (index .Report.Packages 0).Tests}}{{.Name}} {{end}}`, false,
+			"TestC TestB TestA TestA TestB TestC TestA TestC TestB "},
+		{"sort-packages", `{{range sortPackages "coverage" .Report.Packages}}{{.Name}} {{end}}{{range sortPackages "duration" .Report.Packages}}{{.Name}} {{end}}`, false,
+			"package/two package/one package/two package/one "},
+		{"with-result", `{{range withResult "fail" (index .Report.Packages 0).Tests}}{{.Name}} {{end}}{{len (withResult "timedout" (index .Report.Packages 0).Tests)}}`, false,
+			"TestA TestC 1"},
+		{"json", `{{json (index .Report.Packages 0).Name}}`, false, `"package/one"`},
+		{"html", `<td>{{(index .Report.Packages 0).Name}} {{"a<b"}}</td>`, true, `<td>package/one a&lt;b</td>`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tmpl, err := Parse(test.name, test.text, test.html)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := Write(&buf, tmpl, testReport, timestamp); err != nil {
+				t.Fatalf("Write error: %v", err)
+			}
+			if diff := cmp.Diff(test.want, buf.String()); diff != "" {
+				t.Errorf("Write output incorrect, diff (-want +got):\n%s\n", diff)
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	for _, text := range []string{`{{sortTests "random" .Report.Packages}}`, `{{withResult "broken" nil}}`} {
+		tmpl, err := Parse("error", text, false)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", text, err)
+		}
+		if err := Write(ioutil.Discard, tmpl, testReport, time.Time{}); err == nil {
+			t.Errorf("Write(%q) did not return an error", text)
+		}
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, want := range map[string]string{"report.txt": "<b>", "report.HTML": "&lt;b&gt;"} {
+		filename := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(filename, []byte(`{{"<b>"}}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		tmpl, err := ParseFile(filename)
+		if err != nil {
+			t.Fatalf("ParseFile error: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := Write(&buf, tmpl, testReport, time.Time{}); err != nil {
+			t.Fatalf("Write error: %v", err)
+		}
+		if got := buf.String(); got != want {
+			t.Errorf("ParseFile(%q) output = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package watch detects changes to files by periodically scanning a directory
+// tree, and is used to rerun tests and regenerate reports while developing.
+//
+// Files are compared by their size and modification time, so no platform
+// specific file notification API is needed. Directories whose name starts
+// with a dot or an underscore are skipped, like the go tool ignores them.
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultInterval is the default time between two scans of a Watcher.
+const DefaultInterval = time.Second
+
+// fileState is the state of a file used to detect changes.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// Snapshot contains the state of a set of files at some point in time.
+type Snapshot map[string]fileState
+
+// Changed returns the sorted names of the files that were added, removed or
+// modified in s compared to the older snapshot old.
+func (s Snapshot) Changed(old Snapshot) []string {
+	var changed []string
+	for name, state := range s {
+		if prev, ok := old[name]; !ok || prev != state {
+			changed = append(changed, name)
+		}
+	}
+	for name := range old {
+		if _, ok := s[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Scan returns a snapshot of the files in the directory tree rooted at root
+// for which match returns true. If match is nil, all files are included. If
+// root is a file instead of a directory, the snapshot only contains root.
+func Scan(root string, match func(path string) bool) (Snapshot, error) {
+	s := make(Snapshot)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// Files may be removed while scanning.
+			if os.IsNotExist(err) && path != root {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			if name := info.Name(); path != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if match == nil || match(path) {
+			s[path] = fileState{info.Size(), info.ModTime()}
+		}
+		return nil
+	})
+	return s, err
+}
+
+// GoFiles matches the files that affect the result of go test: Go source
+// files, go.mod and go.sum, and any file in a testdata directory.
+func GoFiles(path string) bool {
+	switch filepath.Base(path) {
+	case "go.mod", "go.sum":
+		return true
+	}
+	if strings.HasSuffix(path, ".go") {
+		return true
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if dir == "testdata" {
+			return true
+		}
+	}
+	return false
+}
+
+// PackageDirs returns the go package patterns of the directories containing
+// the given files, such as ./parser/gotest, for files relative to the current
+// directory. Files in testdata directories belong to the package containing
+// the testdata directory. Since changes to go.mod or go.sum may affect any
+// package, only ./... is returned if either changed. The result is sorted and
+// contains each directory only once.
+func PackageDirs(files []string) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, file := range files {
+		switch filepath.Base(file) {
+		case "go.mod", "go.sum":
+			return []string{"./..."}
+		}
+		parts := strings.Split(filepath.ToSlash(filepath.Dir(file)), "/")
+		for i, part := range parts {
+			if part == "testdata" {
+				parts = parts[:i]
+				break
+			}
+		}
+		dir := "./" + strings.TrimPrefix(strings.Join(parts, "/"), "./")
+		if dir == "./" || dir == "./." {
+			dir = "."
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// Watcher calls a function whenever the files matched in a directory tree
+// change.
+type Watcher struct {
+	Root     string                 // file or directory to watch
+	Match    func(path string) bool // files to watch, or nil to watch all files
+	Interval time.Duration          // time between scans, DefaultInterval if zero
+}
+
+// Watch scans the files of w every Interval, and calls fn with the changed
+// files whenever any of them changed since the previous call. Changes made
+// while fn is running are reported in the next call. Watch returns when stop
+// is closed, or when scanning or calling fn returns an error.
+func (w Watcher) Watch(stop <-chan struct{}, fn func(changed []string) error) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	prev, err := Scan(w.Root, w.Match)
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+		cur, err := Scan(w.Root, w.Match)
+		if err != nil {
+			return err
+		}
+		changed := cur.Changed(prev)
+		if len(changed) == 0 {
+			continue
+		}
+		// Take the snapshot to compare against before calling fn, so changes
+		// made while it runs aren't missed.
+		prev = cur
+		if err := fn(changed); err != nil {
+			return err
+		}
+	}
+}
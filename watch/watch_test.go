@@ -0,0 +1,152 @@
+package watch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSnapshotChanged(t *testing.T) {
+	now := time.Now()
+	old := Snapshot{
+		"a.go": {10, now},
+		"b.go": {20, now},
+		"c.go": {30, now},
+	}
+	cur := Snapshot{
+		"a.go": {10, now},
+		"b.go": {20, now.Add(time.Second)},
+		"d.go": {40, now},
+	}
+
+	want := []string{"b.go", "c.go", "d.go"}
+	if diff := cmp.Diff(want, cur.Changed(old)); diff != "" {
+		t.Errorf("Changed result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+	if got := old.Changed(old); len(got) != 0 {
+		t.Errorf("Changed for unchanged snapshot = %v, want none", got)
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := []string{
+		"go.mod",
+		"main.go",
+		"README.md",
+		"pkg/pkg.go",
+		"pkg/testdata/input.txt",
+		".git/config.go",
+		"_build/gen.go",
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := Scan(dir, GoFiles)
+	if err != nil {
+		t.Fatalf("Scan error: %v", err)
+	}
+	var got []string
+	for path := range s {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, filepath.ToSlash(rel))
+	}
+	sort.Strings(got)
+	want := []string{"go.mod", "main.go", "pkg/pkg.go", "pkg/testdata/input.txt"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Scan result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+
+	file := filepath.Join(dir, "README.md")
+	s, err = Scan(file, nil)
+	if err != nil {
+		t.Fatalf("Scan error: %v", err)
+	}
+	if _, ok := s[file]; !ok || len(s) != 1 {
+		t.Errorf("Scan(%q) = %v, want only %q", file, s, file)
+	}
+}
+
+func TestPackageDirs(t *testing.T) {
+	tests := []struct {
+		files []string
+		want  []string
+	}{
+		{[]string{"main.go"}, []string{"."}},
+		{[]string{"./main.go", "main_test.go"}, []string{"."}},
+		{[]string{"parser/gotest/event.go", "gtr/gtr.go", "gtr/merge.go"}, []string{"./gtr", "./parser/gotest"}},
+		{[]string{"parser/gotest/testdata/in/001-pass.txt"}, []string{"./parser/gotest"}},
+		{[]string{"testdata/001-report.xml"}, []string{"."}},
+		{[]string{"gtr/gtr.go", "go.mod"}, []string{"./..."}},
+	}
+	for _, test := range tests {
+		got := PackageDirs(test.files)
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("PackageDirs(%q) incorrect, diff (-want +got):\n%s\n", test.files, diff)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "main.go")
+	if err := ioutil.WriteFile(file, []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	changes := make(chan []string, 1)
+	done := make(chan error, 1)
+	w := Watcher{Root: dir, Match: GoFiles, Interval: 10 * time.Millisecond}
+	go func() {
+		done <- w.Watch(stop, func(changed []string) error {
+			changes <- changed
+			return nil
+		})
+	}()
+
+	// Wait for the initial scan before changing the file.
+	time.Sleep(50 * time.Millisecond)
+	if err := ioutil.WriteFile(file, []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case changed := <-changes:
+		if diff := cmp.Diff([]string{file}, changed); diff != "" {
+			t.Errorf("Watch changed files incorrect, diff (-want +got):\n%s\n", diff)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for change")
+	}
+
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("Watch error: %v", err)
+	}
+}
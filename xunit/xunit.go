@@ -0,0 +1,248 @@
+// Package xunit defines the xUnit.net v2 XML format and includes convenience
+// methods to create these reports from a gtr.Report.
+//
+// A report is converted into a single assembly, in which each package is a
+// collection of tests. Test and package properties are added to each test as
+// traits. Build and runtime errors, which don't belong to any test, are
+// reported as errors of the assembly.
+package xunit
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+	"github.com/jstemmer/go-junit-report/v2/internal/timefmt"
+)
+
+// AssemblyName is the name of the assembly containing the report.
+const AssemblyName = "go test"
+
+// Test results.
+const (
+	ResultPass = "Pass"
+	ResultFail = "Fail"
+	ResultSkip = "Skip"
+)
+
+// Assemblies is the root element of an xUnit.net v2 report.
+type Assemblies struct {
+	XMLName    xml.Name   `xml:"assemblies"`
+	Timestamp  string     `xml:"timestamp,attr,omitempty"`
+	Assemblies []Assembly `xml:"assembly"`
+}
+
+// Assembly contains the collections of tests of a single test run.
+type Assembly struct {
+	Name          string `xml:"name,attr"`
+	TestFramework string `xml:"test-framework,attr"`
+	RunDate       string `xml:"run-date,attr,omitempty"` // yyyy-mm-dd
+	RunTime       string `xml:"run-time,attr,omitempty"` // hh:mm:ss
+	Time          string `xml:"time,attr"`               // duration in seconds
+	Total         int    `xml:"total,attr"`
+	Passed        int    `xml:"passed,attr"`
+	Failed        int    `xml:"failed,attr"`
+	Skipped       int    `xml:"skipped,attr"`
+	ErrorCount    int    `xml:"errors,attr"`
+
+	Errors      Errors       `xml:"errors"`
+	Collections []Collection `xml:"collection"`
+}
+
+// Errors contains the errors that happened outside of tests.
+type Errors struct {
+	Errors []Error `xml:"error"`
+}
+
+// Error is an error that happened outside of a test, such as a build error.
+type Error struct {
+	Type    string  `xml:"type,attr"`
+	Name    string  `xml:"name,attr,omitempty"`
+	Failure Failure `xml:"failure"`
+}
+
+// Collection contains the tests of a single package.
+type Collection struct {
+	Name    string `xml:"name,attr"`
+	Time    string `xml:"time,attr"` // duration in seconds
+	Total   int    `xml:"total,attr"`
+	Passed  int    `xml:"passed,attr"`
+	Failed  int    `xml:"failed,attr"`
+	Skipped int    `xml:"skipped,attr"`
+	Tests   []Test `xml:"test"`
+}
+
+// add adds test t to collection c and updates its totals.
+func (c *Collection) add(t Test) {
+	c.Tests = append(c.Tests, t)
+	c.Total++
+	switch t.Result {
+	case ResultPass:
+		c.Passed++
+	case ResultFail:
+		c.Failed++
+	case ResultSkip:
+		c.Skipped++
+	}
+}
+
+// Test contains the result of a single test.
+type Test struct {
+	Name    string   `xml:"name,attr"`
+	Type    string   `xml:"type,attr"`
+	Method  string   `xml:"method,attr"`
+	Time    string   `xml:"time,attr"` // duration in seconds
+	Result  string   `xml:"result,attr"`
+	Traits  *Traits  `xml:"traits,omitempty"`
+	Output  string   `xml:"output,omitempty"`
+	Reason  string   `xml:"reason,omitempty"`
+	Failure *Failure `xml:"failure,omitempty"`
+}
+
+// Traits contains the traits of a test.
+type Traits struct {
+	Traits []Trait `xml:"trait"`
+}
+
+// Trait is a name/value pair describing a test.
+type Trait struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// Failure describes why a test failed or an error occurred.
+type Failure struct {
+	ExceptionType string `xml:"exception-type,attr,omitempty"`
+	Message       string `xml:"message"`
+	StackTrace    string `xml:"stack-trace,omitempty"`
+}
+
+// CreateFromReport creates an xUnit.net v2 representation of the given
+// gtr.Report. The run date and time of the assembly are those of the earliest
+// package timestamp, if any.
+func CreateFromReport(report gtr.Report) Assemblies {
+	assembly := Assembly{Name: AssemblyName, TestFramework: "go test"}
+	var start time.Time
+	var duration time.Duration
+	for _, pkg := range report.Packages {
+		if !pkg.Timestamp.IsZero() && (start.IsZero() || pkg.Timestamp.Before(start)) {
+			start = pkg.Timestamp
+		}
+
+		c := Collection{Name: pkg.Name}
+		var testDuration time.Duration
+		for _, test := range pkg.Tests {
+			testDuration += test.Duration
+			c.add(createTest(pkg, test))
+		}
+		if pkg.Duration == 0 {
+			pkg.Duration = testDuration
+		}
+		c.Time = timefmt.Seconds(pkg.Duration, 3)
+		duration += pkg.Duration
+
+		if pkg.BuildError.Name != "" {
+			assembly.Errors.Errors = append(assembly.Errors.Errors, Error{
+				Type:    "fatal",
+				Name:    pkg.Name,
+				Failure: Failure{ExceptionType: "BuildError", Message: pkg.BuildError.Cause, StackTrace: strings.Join(pkg.BuildError.Output, "\n")},
+			})
+		}
+		if pkg.RunError.Name != "" || pkg.RunError.Kind != "" {
+			message := pkg.RunError.FailureReason()
+			if message == "" {
+				message = "Runtime error"
+			}
+			assembly.Errors.Errors = append(assembly.Errors.Errors, Error{
+				Type:    "fatal",
+				Name:    pkg.Name,
+				Failure: Failure{ExceptionType: "RuntimeError", Message: message, StackTrace: strings.Join(pkg.RunError.Output, "\n")},
+			})
+		}
+
+		assembly.Total += c.Total
+		assembly.Passed += c.Passed
+		assembly.Failed += c.Failed
+		assembly.Skipped += c.Skipped
+		assembly.Collections = append(assembly.Collections, c)
+	}
+	assembly.ErrorCount = len(assembly.Errors.Errors)
+	assembly.Time = timefmt.Seconds(duration, 3)
+
+	var assemblies Assemblies
+	if !start.IsZero() {
+		assembly.RunDate = start.Format("2006-01-02")
+		assembly.RunTime = start.Format("15:04:05")
+		assemblies.Timestamp = start.Format("01/02/2006 15:04:05")
+	}
+	assemblies.Assemblies = []Assembly{assembly}
+	return assemblies
+}
+
+func createTest(pkg gtr.Package, test gtr.Test) Test {
+	t := Test{
+		Name:   test.Name,
+		Type:   pkg.Name,
+		Method: test.Name,
+		Time:   timefmt.Seconds(test.Duration, 3),
+		Output: strings.Join(test.Output, "\n"),
+	}
+
+	var traits []Trait
+	for _, p := range pkg.Properties {
+		traits = append(traits, Trait{Name: p.Name, Value: p.Value})
+	}
+	for _, p := range test.Properties {
+		traits = append(traits, Trait{Name: p.Name, Value: p.Value})
+	}
+	if test.Result == gtr.Flaky {
+		traits = append(traits, Trait{Name: "flaky", Value: "true"})
+	}
+	if len(traits) > 0 {
+		t.Traits = &Traits{Traits: traits}
+	}
+
+	switch test.Result.Base() {
+	case gtr.Pass, gtr.Flaky:
+		t.Result = ResultPass
+	case gtr.Skip:
+		t.Result = ResultSkip
+		t.Reason = "Skipped"
+		if test.SkipMessage != "" {
+			t.Reason = test.SkipMessage
+		}
+	case gtr.Fail:
+		t.Result = ResultFail
+		message := test.FailureReason()
+		if message == "" {
+			message = "Failed"
+		}
+		t.Failure = &Failure{ExceptionType: test.FailureType, Message: message}
+		if test.Panic != nil {
+			t.Failure.StackTrace = strings.Join(test.Panic.Stack, "\n")
+		}
+	default:
+		t.Result = ResultFail
+		t.Failure = &Failure{Message: "No test result found"}
+	}
+	return t
+}
+
+// WriteXML writes the XML representation of Assemblies a to writer w.
+func (a *Assemblies) WriteXML(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(a); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
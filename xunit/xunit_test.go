@@ -0,0 +1,126 @@
+package xunit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/jstemmer/go-junit-report/v2/gtr"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCreateFromReport(t *testing.T) {
+	report := gtr.Report{Packages: []gtr.Package{
+		{
+			Name:       "package/one",
+			Timestamp:  time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+			Duration:   time.Second,
+			Properties: []gtr.Property{{Name: "go.version", Value: "1.18"}},
+			Tests: []gtr.Test{
+				{Name: "TestPass", Result: gtr.Pass, Duration: 10 * time.Millisecond, Output: []string{"ok"}},
+				{Name: "TestFail", Result: gtr.Fail, FailureMessage: "boom", FailureType: "assert", Properties: []gtr.Property{{Name: "owner", Value: "team"}}},
+				{Name: "TestSkip", Result: gtr.Skip, SkipMessage: "not today"},
+				{Name: "TestFlaky", Result: gtr.Flaky},
+				{Name: "TestUnknown", Result: gtr.Unknown},
+			},
+		},
+		{
+			Name:       "package/two",
+			BuildError: gtr.Error{Name: "package/two", Cause: "[build failed]", Output: []string{"undefined: x"}},
+		},
+	}}
+
+	goVersion := Trait{Name: "go.version", Value: "1.18"}
+	want := Assemblies{
+		Timestamp: "01/02/2022 03:04:05",
+		Assemblies: []Assembly{{
+			Name:          AssemblyName,
+			TestFramework: "go test",
+			RunDate:       "2022-01-02",
+			RunTime:       "03:04:05",
+			Time:          "1.000",
+			Total:         5,
+			Passed:        2,
+			Failed:        2,
+			Skipped:       1,
+			ErrorCount:    1,
+			Errors: Errors{Errors: []Error{{
+				Type:    "fatal",
+				Name:    "package/two",
+				Failure: Failure{ExceptionType: "BuildError", Message: "[build failed]", StackTrace: "undefined: x"},
+			}}},
+			Collections: []Collection{
+				{
+					Name: "package/one", Time: "1.000", Total: 5, Passed: 2, Failed: 2, Skipped: 1,
+					Tests: []Test{
+						{Name: "TestPass", Type: "package/one", Method: "TestPass", Time: "0.010", Result: ResultPass, Traits: &Traits{[]Trait{goVersion}}, Output: "ok"},
+						{
+							Name: "TestFail", Type: "package/one", Method: "TestFail", Time: "0.000", Result: ResultFail,
+							Traits:  &Traits{[]Trait{goVersion, {Name: "owner", Value: "team"}}},
+							Failure: &Failure{ExceptionType: "assert", Message: "boom"},
+						},
+						{Name: "TestSkip", Type: "package/one", Method: "TestSkip", Time: "0.000", Result: ResultSkip, Traits: &Traits{[]Trait{goVersion}}, Reason: "not today"},
+						{Name: "TestFlaky", Type: "package/one", Method: "TestFlaky", Time: "0.000", Result: ResultPass, Traits: &Traits{[]Trait{goVersion, {Name: "flaky", Value: "true"}}}},
+						{
+							Name: "TestUnknown", Type: "package/one", Method: "TestUnknown", Time: "0.000", Result: ResultFail,
+							Traits:  &Traits{[]Trait{goVersion}},
+							Failure: &Failure{Message: "No test result found"},
+						},
+					},
+				},
+				{Name: "package/two", Time: "0.000"},
+			},
+		}},
+	}
+
+	got := CreateFromReport(report)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CreateFromReport result incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}
+
+func TestWriteXML(t *testing.T) {
+	assemblies := Assemblies{Assemblies: []Assembly{{
+		Name:          AssemblyName,
+		TestFramework: "go test",
+		Time:          "0.012",
+		Total:         2,
+		Passed:        1,
+		Failed:        1,
+		Collections: []Collection{{
+			Name: "package/one", Time: "0.012", Total: 2, Passed: 1, Failed: 1,
+			Tests: []Test{
+				{Name: "TestPass", Type: "package/one", Method: "TestPass", Time: "0.012", Result: ResultPass, Traits: &Traits{[]Trait{{Name: "key", Value: "value"}}}},
+				{Name: "TestFail", Type: "package/one", Method: "TestFail", Time: "0.000", Result: ResultFail, Failure: &Failure{Message: "got <nil>"}},
+			},
+		}},
+	}}}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<assemblies>
+	<assembly name="go test" test-framework="go test" time="0.012" total="2" passed="1" failed="1" skipped="0" errors="0">
+		<errors></errors>
+		<collection name="package/one" time="0.012" total="2" passed="1" failed="1" skipped="0">
+			<test name="TestPass" type="package/one" method="TestPass" time="0.012" result="Pass">
+				<traits>
+					<trait name="key" value="value"></trait>
+				</traits>
+			</test>
+			<test name="TestFail" type="package/one" method="TestFail" time="0.000" result="Fail">
+				<failure>
+					<message>got &lt;nil&gt;</message>
+				</failure>
+			</test>
+		</collection>
+	</assembly>
+</assemblies>
+`
+	var buf bytes.Buffer
+	if err := assemblies.WriteXML(&buf); err != nil {
+		t.Fatalf("WriteXML failed: %v", err)
+	}
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("WriteXML output incorrect, diff (-want +got):\n%s\n", diff)
+	}
+}